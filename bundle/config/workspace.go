@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 
@@ -164,6 +165,9 @@ func (w *Workspace) Client() (*databricks.WorkspaceClient, error) {
 
 	cfg := w.Config()
 
+	// Our loader of the config file also loads the profiles of included files.
+	cfg.Loaders = databrickscfg.Loaders()
+
 	// If only the host is configured, we try and unambiguously match it to
 	// a profile in the user's databrickscfg file. Override the default loaders.
 	if w.Host != "" && w.Profile == "" {
@@ -187,7 +191,7 @@ func (w *Workspace) Client() (*databricks.WorkspaceClient, error) {
 	// Now that the configuration is resolved, we can verify that the host in the bundle configuration
 	// is identical to the host associated with the selected profile.
 	if w.Host != "" && w.Profile != "" {
-		err := databrickscfg.ValidateConfigAndProfileHost(cfg, w.Profile)
+		err := databrickscfg.ValidateConfigAndProfileHost(context.Background(), cfg, w.Profile) //nolint:gocritic // Client does not accept context.
 		if err != nil {
 			return nil, err
 		}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/databricks/cli/cmd/root"
 	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
 	"github.com/databricks/cli/libs/flags"
 	"github.com/databricks/databricks-sdk-go/client"
 	"github.com/databricks/databricks-sdk-go/config"
@@ -47,7 +48,7 @@ func makeCommand(method string) *cobra.Command {
 				return diags.Error()
 			}
 
-			cfg := &config.Config{}
+			cfg := &config.Config{Loaders: databrickscfg.Loaders()}
 
 			// command-line flag can specify the profile in use
 			profileFlag := cmd.Flag("profile")
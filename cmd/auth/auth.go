@@ -38,6 +38,7 @@ GCP: https://docs.gcp.databricks.com/dev-tools/auth/index.html`,
 	cmd.AddCommand(newTokenCommand(&authArguments))
 	cmd.AddCommand(newDescribeCommand())
 	cmd.AddCommand(newSwitchCommand())
+	cmd.AddCommand(newCacheCommand())
 	return cmd
 }
 
@@ -88,6 +89,22 @@ func validateProfileHostConflict(ctx context.Context, profileName, host string,
 	return nil
 }
 
+// validateAuthFlags is a PreRunE function that validates the --account-id and
+// --workspace-id flags and that --profile and --host don't conflict.
+func validateAuthFlags(cmd *cobra.Command, args []string) error {
+	if f := cmd.Flag("account-id"); f != nil && f.Changed {
+		if err := auth.ValidateAccountID(f.Value.String()); err != nil {
+			return err
+		}
+	}
+	if f := cmd.Flag("workspace-id"); f != nil && f.Changed {
+		if err := auth.ValidateWorkspaceID(f.Value.String()); err != nil {
+			return err
+		}
+	}
+	return profileHostConflictCheck(cmd, args)
+}
+
 // profileHostConflictCheck is a PreRunE function that validates
 // --profile and --host don't conflict.
 func profileHostConflictCheck(cmd *cobra.Command, args []string) error {
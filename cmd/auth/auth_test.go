@@ -132,3 +132,43 @@ func TestProfileHostCompatibleViaCobra(t *testing.T) {
 		assert.NotContains(t, err.Error(), "conflicts with --host")
 	}
 }
+
+// TestIDFlagsValidatedViaCobra verifies that malformed --account-id and
+// --workspace-id flags are rejected before login or token run.
+func TestIDFlagsValidatedViaCobra(t *testing.T) {
+	cases := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{
+			name:    "login with org ID as account ID",
+			args:    []string{"auth", "login", "--host", "https://accounts.cloud.databricks.com", "--account-id", "1234567890"},
+			wantErr: `invalid account ID "1234567890": this looks like a workspace ID or org ID`,
+		},
+		{
+			name:    "token with malformed account ID",
+			args:    []string{"auth", "token", "--host", "https://accounts.cloud.databricks.com", "--account-id", "my-account"},
+			wantErr: `invalid account ID "my-account": expected a UUID`,
+		},
+		{
+			name:    "login with account ID as workspace ID",
+			args:    []string{"auth", "login", "--host", "https://unified.cloud.databricks.com", "--account-id", "01234567-89ab-cdef-0123-456789abcdef", "--workspace-id", "01234567-89ab-cdef-0123-456789abcdef"},
+			wantErr: `invalid workspace ID "01234567-89ab-cdef-0123-456789abcdef": this looks like an account ID`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DATABRICKS_CONFIG_FILE", "./testdata/.databrickscfg")
+
+			ctx := cmdctx.GenerateExecId(t.Context())
+			cli := root.New(ctx)
+			cli.AddCommand(New())
+			cli.SetArgs(tc.args)
+
+			_, err := cli.ExecuteContextC(ctx)
+			assert.ErrorContains(t, err, tc.wantErr)
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage local authentication caches",
+		Args:  cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newCacheClearDiscoveryCommand())
+	return cmd
+}
+
+func newCacheClearDiscoveryCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "clear-discovery",
+		Short: "Forget hosts whose OAuth endpoints could not be discovered",
+		Long: `Forget hosts whose OAuth endpoints could not be discovered.
+
+When a host does not support Databricks OAuth or its name does not exist, the
+failed discovery of its OAuth endpoints is remembered for a few minutes in
+~/.databricks/discovery-cache.json so that other authentication methods are
+tried without waiting for the discovery to fail again. Run this command to
+retry the discovery immediately.`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		c, err := auth.DefaultDiscoveryCache()
+		if err != nil {
+			return err
+		}
+		err = c.Clear()
+		if err != nil {
+			return err
+		}
+		cmdio.LogString(ctx, "Cleared the OAuth endpoint discovery cache.")
+		return nil
+	}
+
+	return cmd
+}
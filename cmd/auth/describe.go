@@ -4,12 +4,19 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/libs/auth"
 	"github.com/databricks/cli/libs/cmdctx"
 	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
 	"github.com/databricks/cli/libs/flags"
+	"github.com/databricks/cli/libs/log"
 	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
 	"github.com/spf13/cobra"
 )
 
@@ -21,12 +28,34 @@ var authTemplate = `{{"Host:" | bold}} {{.Status.Details.Host}}
 {{"User:" | bold}} {{.Status.Username}}
 {{- end}}
 {{"Authenticated with:" | bold}} {{.Status.Details.AuthType}}
------
-` + configurationTemplate
+` + tokenLifetimeTemplate + `-----
+` + configurationTemplate + hostResolutionTemplate + authTraceTemplate + metadataServiceTemplate
 
 var errorTemplate = `Unable to authenticate: {{.Status.Error}}
------
-` + configurationTemplate
+` + tokenLifetimeTemplate + `-----
+` + configurationTemplate + hostResolutionTemplate + authTraceTemplate + metadataServiceTemplate
+
+var offlineTemplate = `{{"Host:" | bold}} {{.Status.Details.Host}}
+{{- if .Status.AccountID}}
+{{"Account ID:" | bold}} {{.Status.AccountID}}{{.Status.AsOfText}}
+{{- end}}
+{{- if .Status.Username}}
+{{"User:" | bold}} {{.Status.Username}}{{.Status.AsOfText}}
+{{- else}}
+{{"User:" | bold}} unknown (offline)
+{{- end}}
+{{"Authenticated with:" | bold}} {{.Status.Details.AuthType}}
+{{- if .Status.Error}}
+{{"Offline because:" | bold}} {{.Status.Error}}
+{{- end}}
+` + tokenLifetimeTemplate + `-----
+` + configurationTemplate + hostResolutionTemplate + metadataServiceTemplate
+
+const tokenLifetimeTemplate = `{{with .Status.TokenLifetime -}}
+{{"Access token expires:" | bold}} {{.ExpiryText}}
+{{"Last refreshed:" | bold}} {{.LastRefreshText}}
+{{"Logged in:" | bold}} {{.LoginText}}
+{{end}}`
 
 const configurationTemplate = `Current configuration:
   {{- $details := .Status.Details}}
@@ -43,6 +72,46 @@ const configurationTemplate = `Current configuration:
   {{- end}}
 `
 
+const hostResolutionTemplate = `
+{{- with .Status.HostResolution}}
+-----
+Profile resolution from host:
+  {{"Config file:" | bold}} {{.ConfigFile}}
+  {{- if .Host}}
+  {{"Host:" | bold}} {{.Host}}
+  {{"Profiles scanned:" | bold}} {{.ProfilesScanned}}
+  {{"Matched:" | bold}} {{if .Matched}}{{join .Matched ", "}}{{else}}none{{end}}
+  {{- end}}
+  {{"Decision:" | bold}} {{.Decision}}{{if .Profile}} ({{.Profile}}){{end}}
+{{- end}}
+`
+
+const authTraceTemplate = `
+{{- with .Status.AuthTrace}}
+-----
+Credential strategies evaluated:
+  {{- range .}}
+  {{if .Selected}}✓{{else}}-{{end}} {{.Name | bold}}: {{.Outcome}}{{with .Detail}} ({{.}}){{end}}
+  {{- end}}
+{{- end}}
+`
+
+const metadataServiceTemplate = `
+{{- with .Status.MetadataService}}
+-----
+Metadata service:
+  {{"URL:" | bold}} {{.URL}}
+  {{"Host:" | bold}} {{.Host}}
+  {{"Status:" | bold}} {{if .StatusCode}}{{.StatusCode}}{{else}}no response{{end}}
+  {{- if not .Expiry.IsZero}}
+  {{"Token expires:" | bold}} {{.ExpiryText}}
+  {{- end}}
+  {{- if .Error}}
+  {{"Error:" | bold}} {{.Error}}
+  {{- end}}
+{{- end}}
+`
+
 func newDescribeCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "describe",
@@ -52,18 +121,100 @@ func newDescribeCommand() *cobra.Command {
 	var showSensitive bool
 	cmd.Flags().BoolVar(&showSensitive, "sensitive", false, "Include sensitive fields like passwords and tokens in the output")
 
+	var showSources bool
+	cmd.Flags().BoolVar(&showSources, "show-sources", false, "Show how a profile is resolved from the configured host")
+
+	var showAuthTrace bool
+	cmd.Flags().BoolVar(&showAuthTrace, "show-auth-trace", false, "Show the credential strategies that were evaluated, in order")
+
+	var offline bool
+	cmd.Flags().BoolVar(&offline, "offline", false, "Describe the credentials from local configuration and cached data without calling the API")
+
+	var probeMetadataService bool
+	cmd.Flags().BoolVar(&probeMetadataService, "probe-metadata-service", false, "Request a token from the configured metadata service and report its response")
+
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
-		var status *authStatus
-		var err error
-		status, err = getAuthStatus(cmd, args, showSensitive, func(cmd *cobra.Command, args []string) (*config.Config, bool, error) {
-			isAccount, err := root.MustAnyClient(cmd, args)
-			return cmdctx.ConfigUsed(cmd.Context()), isAccount, err
-		})
+		// The identity cache is best effort; describe works without it.
+		identities, err := auth.DefaultIdentityStore()
 		if err != nil {
-			return err
+			log.Debugf(ctx, "Cannot open identity cache: %v", err)
 		}
 
+		var status *authStatus
+		var cfg *config.Config
+		if offline {
+			cfg, err = offlineConfig(cmd)
+			if err != nil {
+				return err
+			}
+			status = getOfflineAuthStatus(cmd, cfg, showSensitive, identities, nil)
+		} else {
+			status, err = getAuthStatus(cmd, args, showSensitive, func(cmd *cobra.Command, args []string) (*config.Config, bool, error) {
+				isAccount, err := root.MustAnyClient(cmd, args)
+				return cmdctx.ConfigUsed(cmd.Context()), isAccount, err
+			})
+			if err != nil {
+				return err
+			}
+
+			// The config used is stored in the command context by MustAnyClient.
+			if cmdctx.HasConfigUsed(cmd.Context()) {
+				cfg = cmdctx.ConfigUsed(cmd.Context())
+			}
+			if cfg != nil {
+				if status.Error != nil && auth.IsNetworkError(status.Error) {
+					status = getOfflineAuthStatus(cmd, cfg, showSensitive, identities, status.Error)
+				} else if status.Status == "success" {
+					cacheIdentity(ctx, identities, cfg, status, time.Now())
+				}
+			}
+		}
+
+		if showSources {
+			status.HostResolution = getHostResolution(cmd)
+		}
+
+		if showAuthTrace && cfg != nil {
+			status.AuthTrace = auth.CredentialsTrace(cfg)
+		}
+
+		if probeMetadataService {
+			// Credentials from a failing metadata service cannot be resolved,
+			// so the configuration may not be recorded.
+			if cfg == nil {
+				cfg, err = offlineConfig(cmd)
+				if err != nil {
+					return err
+				}
+			}
+			probe, err := auth.ProbeMetadataService(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			status.MetadataService = &metadataServiceStatus{MetadataServiceProbe: probe, now: time.Now()}
+		}
+
+		if cfg != nil && status.Details.AuthType == authTypeDatabricksCLI {
+			tokenCache, err := cache.NewFileTokenCache()
+			if err != nil {
+				log.Debugf(ctx, "Cannot open token cache: %v", err)
+			} else {
+				status.TokenLifetime = getTokenLifetime(ctx, cfg, tokenCache, nil, time.Now())
+			}
+		}
+
+		// The config file often holds tokens, so point out when other users
+		// can read it.
+		configFile := env.Get(ctx, "DATABRICKS_CONFIG_FILE")
+		if cfg != nil && cfg.ConfigFile != "" {
+			configFile = cfg.ConfigFile
+		}
+		databrickscfg.WarnInsecurePermissions(ctx, configFile)
+
+		if status.Status == "offline" {
+			return render(ctx, cmd, status, offlineTemplate)
+		}
 		if status.Error != nil {
 			return render(ctx, cmd, status, errorTemplate)
 		}
@@ -111,7 +262,7 @@ func getAuthStatus(cmd *cobra.Command, args []string, showSensitive bool, fn try
 	}
 
 	w := cmdctx.WorkspaceClient(ctx)
-	me, err := w.CurrentUser.Me(ctx)
+	id, err := auth.WorkspaceClientWhoAmI(ctx, w)
 	if err != nil {
 		return &authStatus{
 			Status:  "error",
@@ -120,10 +271,20 @@ func getAuthStatus(cmd *cobra.Command, args []string, showSensitive bool, fn try
 		}, nil
 	}
 
+	// Profiles pinned with "auth login --pin-workspace" must still point at the same workspace.
+	err = auth.VerifyProfileWorkspacePin(ctx, w, w.Config)
+	if err != nil {
+		return &authStatus{
+			Status:  "error",
+			Error:   err,
+			Details: getAuthDetails(cmd, w.Config, showSensitive),
+		}, nil
+	}
+
 	status := authStatus{
 		Status:   "success",
 		Details:  getAuthDetails(cmd, w.Config, showSensitive),
-		Username: me.UserName,
+		Username: id.UserName,
 	}
 
 	return &status, nil
@@ -158,6 +319,232 @@ type authStatus struct {
 	Username  string             `json:"username,omitempty"`
 	AccountID string             `json:"account_id,omitempty"`
 	Details   config.AuthDetails `json:"details"`
+
+	// IdentityAsOf is when the cached identity reported in offline mode was
+	// last confirmed by the API.
+	IdentityAsOf time.Time `json:"identity_as_of,omitzero"`
+
+	HostResolution *databrickscfg.HostResolutionTrace `json:"host_resolution,omitempty"`
+	TokenLifetime  *tokenLifetime                     `json:"token_lifetime,omitempty"`
+	AuthTrace      []auth.StrategyEvaluation          `json:"auth_trace,omitempty"`
+
+	MetadataService *metadataServiceStatus `json:"metadata_service,omitempty"`
+}
+
+// metadataServiceStatus is the response of the metadata service to the probe
+// of "auth describe --probe-metadata-service".
+type metadataServiceStatus struct {
+	*auth.MetadataServiceProbe
+
+	now time.Time
+}
+
+func (s *metadataServiceStatus) ExpiryText() string {
+	l := tokenLifetime{AccessTokenExpiry: s.Expiry, now: s.now}
+	return l.ExpiryText()
+}
+
+// AsOfText annotates an identity that was read from the cache.
+func (s *authStatus) AsOfText() string {
+	if s.IdentityAsOf.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" (as of %s (offline))", s.IdentityAsOf.Format(time.RFC3339))
+}
+
+// offlineConfig resolves the configuration from the command flags, the
+// environment, and the config file without making network requests. It uses
+// the loaders of [root.MustWorkspaceClient], so that the offline description
+// matches the one of the online check.
+func offlineConfig(cmd *cobra.Command) (*config.Config, error) {
+	ctx := cmd.Context()
+	cfg := &config.Config{}
+	if f := cmd.Flag("host"); f != nil && f.Changed {
+		cfg.Host = f.Value.String()
+	}
+	if f := cmd.Flag("profile"); f != nil && f.Changed {
+		cfg.Profile = f.Value.String()
+	}
+	if cfg.Profile == "" && env.Get(ctx, "DATABRICKS_CONFIG_PROFILE") == "" {
+		defaultProfile, err := databrickscfg.GetConfiguredDefaultProfile(ctx, env.Get(ctx, "DATABRICKS_CONFIG_FILE"))
+		if err != nil {
+			log.Debugf(ctx, "Failed to load default profile: %v", err)
+		}
+		cfg.Profile = defaultProfile
+	}
+	// Run the loaders directly. EnsureResolved also fetches host metadata.
+	for _, loader := range databrickscfg.Loaders() {
+		err := loader.Configure(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// getOfflineAuthStatus describes cfg with the identity that was cached the
+// last time the credentials were verified. cause is the network error that
+// prevented verification, or nil if the API was not called.
+func getOfflineAuthStatus(cmd *cobra.Command, cfg *config.Config, showSensitive bool, identities *auth.IdentityStore, cause error) *authStatus {
+	status := &authStatus{
+		Status:  "offline",
+		Error:   cause,
+		Details: getAuthDetails(cmd, cfg, showSensitive),
+	}
+	if identities == nil {
+		return status
+	}
+	id, err := identities.Lookup(auth.IdentityCacheKey(cfg))
+	if err != nil {
+		log.Debugf(cmd.Context(), "Cannot read cached identity: %v", err)
+		return status
+	}
+	if id != nil {
+		status.Username = id.Username
+		status.AccountID = id.AccountID
+		status.IdentityAsOf = id.Time
+	}
+	return status
+}
+
+// cacheIdentity records the verified identity for use in offline mode.
+func cacheIdentity(ctx context.Context, identities *auth.IdentityStore, cfg *config.Config, status *authStatus, now time.Time) {
+	if identities == nil {
+		return
+	}
+	err := identities.Store(auth.IdentityCacheKey(cfg), auth.CachedIdentity{
+		Username:  status.Username,
+		AccountID: status.AccountID,
+		Time:      now,
+	})
+	if err != nil {
+		log.Debugf(ctx, "Cannot cache identity: %v", err)
+	}
+}
+
+// tokenLifetime describes the cached OAuth token of a profile. It helps to
+// explain why a user is asked to log in again.
+type tokenLifetime struct {
+	// AccessTokenExpiry is when the cached access token expires.
+	AccessTokenExpiry time.Time `json:"access_token_expiry,omitzero"`
+
+	// LastRefresh is when the refresh token was last used successfully.
+	LastRefresh time.Time `json:"last_refresh,omitzero"`
+
+	// Login is when the user last logged in interactively.
+	Login time.Time `json:"login,omitzero"`
+
+	now time.Time
+}
+
+func (l *tokenLifetime) ExpiryText() string {
+	if l.AccessTokenExpiry.IsZero() {
+		return "unknown"
+	}
+	d := l.AccessTokenExpiry.Sub(l.now)
+	if d <= 0 {
+		return fmt.Sprintf("%s (expired %s ago)", l.AccessTokenExpiry.Format(time.RFC3339), roundDuration(-d))
+	}
+	return fmt.Sprintf("%s (in %s)", l.AccessTokenExpiry.Format(time.RFC3339), roundDuration(d))
+}
+
+func (l *tokenLifetime) LastRefreshText() string {
+	return l.agoText(l.LastRefresh)
+}
+
+func (l *tokenLifetime) LoginText() string {
+	return l.agoText(l.Login)
+}
+
+func (l *tokenLifetime) agoText(t time.Time) string {
+	if t.IsZero() {
+		return "not recorded"
+	}
+	return fmt.Sprintf("%s (%s ago)", t.Format(time.RFC3339), roundDuration(l.now.Sub(t)))
+}
+
+// roundDuration rounds d to a precision that is useful to display.
+func roundDuration(d time.Duration) time.Duration {
+	if d < time.Minute {
+		return d.Round(time.Second)
+	}
+	return d.Round(time.Minute)
+}
+
+// getTokenLifetime looks up the cached OAuth token for cfg and the times
+// recorded for it. It returns nil if no token is cached. A nil times store
+// is replaced by the default store.
+func getTokenLifetime(ctx context.Context, cfg *config.Config, tokenCache cache.TokenCache, times *auth.TokenTimesStore, now time.Time) *tokenLifetime {
+	authArguments := auth.AuthArguments{
+		Host:          cfg.Host,
+		AccountID:     cfg.AccountID,
+		WorkspaceID:   cfg.WorkspaceID,
+		IsUnifiedHost: cfg.Experimental_IsUnifiedHost,
+		Profile:       cfg.Profile,
+		DiscoveryURL:  cfg.DiscoveryURL,
+	}
+	oauthArgument, err := authArguments.ToOAuthArgument()
+	if err != nil {
+		log.Debugf(ctx, "Cannot determine token cache key: %v", err)
+		return nil
+	}
+
+	// Mirror the SDK lookup: the profile key first, then the legacy host key.
+	keys := []string{oauthArgument.GetCacheKey()}
+	if hcp, ok := oauthArgument.(u2m.HostCacheKeyProvider); ok {
+		keys = append(keys, hcp.GetHostCacheKey())
+	}
+	for _, key := range keys {
+		t, err := tokenCache.Lookup(key)
+		if err != nil {
+			continue
+		}
+		lifetime := &tokenLifetime{AccessTokenExpiry: t.Expiry, now: now}
+		if times == nil {
+			times, err = auth.DefaultTokenTimesStore()
+			if err != nil {
+				log.Debugf(ctx, "Cannot read token times: %v", err)
+				return lifetime
+			}
+		}
+		recorded, err := times.Lookup(key)
+		if err != nil {
+			log.Debugf(ctx, "Cannot read token times: %v", err)
+			return lifetime
+		}
+		lifetime.LastRefresh = recorded.LastRefresh
+		lifetime.Login = recorded.Login
+		return lifetime
+	}
+	return nil
+}
+
+// getHostResolution traces how a profile is resolved from the host when
+// no profile is selected explicitly. It returns nil if a profile is set
+// through the --profile flag or the environment, since no host-based
+// resolution takes place in that case.
+func getHostResolution(cmd *cobra.Command) *databrickscfg.HostResolutionTrace {
+	cfg := &config.Config{}
+	if f := cmd.Flag("host"); f != nil && f.Changed {
+		cfg.Host = f.Value.String()
+	}
+	if f := cmd.Flag("profile"); f != nil && f.Changed {
+		cfg.Profile = f.Value.String()
+	}
+
+	// Only pick up environment variables; the config file is consulted by the trace itself.
+	err := config.ConfigAttributes.Configure(cfg)
+	if err != nil {
+		log.Debugf(cmd.Context(), "Failed to read configuration from environment: %v", err)
+		return nil
+	}
+	if cfg.Profile != "" {
+		return nil
+	}
+
+	// The trace records the decision even when resolution fails, e.g. on multiple matches.
+	trace, _ := databrickscfg.TraceProfileFromHost(cfg)
+	return trace
 }
 
 func getAuthDetails(cmd *cobra.Command, cfg *config.Config, showSensitive bool) config.AuthDetails {
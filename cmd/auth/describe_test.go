@@ -1,16 +1,32 @@
 package auth
 
 import (
+	"context"
 	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/internal/testutil"
+	"github.com/databricks/cli/libs/auth"
 	"github.com/databricks/cli/libs/cmdctx"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
 	"github.com/databricks/databricks-sdk-go/config"
+	sdkauth "github.com/databricks/databricks-sdk-go/config/experimental/auth"
 	"github.com/databricks/databricks-sdk-go/experimental/mocks"
 	"github.com/databricks/databricks-sdk-go/service/iam"
 	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
 )
 
 func TestGetWorkspaceAuthStatus(t *testing.T) {
@@ -34,8 +50,16 @@ func TestGetWorkspaceAuthStatus(t *testing.T) {
 	require.NoError(t, err)
 	cmd.Flag("profile").Changed = true
 
+	// The identity is memoized per host and credentials, so the config must
+	// authenticate without calling the Azure CLI. A known workspace ID skips
+	// its lookup, which the mock client doesn't support.
 	cfg := &config.Config{
-		Profile: "my-profile",
+		Profile:     "my-profile",
+		WorkspaceID: "123",
+		Loaders:     []config.Loader{config.ConfigAttributes},
+		Credentials: config.NewTokenSourceStrategy("azure-cli", sdkauth.TokenSourceFn(func(context.Context) (*oauth2.Token, error) {
+			return &oauth2.Token{AccessToken: "test-token"}, nil
+		})),
 	}
 	m.WorkspaceClient.Config = cfg
 	t.Setenv("DATABRICKS_AUTH_TYPE", "azure-cli")
@@ -223,3 +247,233 @@ func TestGetAccountAuthStatus(t *testing.T) {
 	require.Equal(t, "--profile flag", status.Details.Configuration["profile"].Source.String())
 	require.False(t, status.Details.Configuration["profile"].AuthTypeMismatch)
 }
+
+func TestGetHostResolution(t *testing.T) {
+	testutil.CleanupEnvironment(t)
+
+	configFile := filepath.Join(t.TempDir(), ".databrickscfg")
+	err := os.WriteFile(configFile, []byte(`[dev]
+host = https://dev.cloud.databricks.com
+
+[prod]
+host = https://prod.cloud.databricks.com
+`), 0o600)
+	require.NoError(t, err)
+	t.Setenv("DATABRICKS_CONFIG_FILE", configFile)
+
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{}
+		cmd.SetContext(t.Context())
+		cmd.Flags().String("host", "", "")
+		cmd.Flags().String("profile", "", "")
+		return cmd
+	}
+
+	t.Run("host flag selects profile", func(t *testing.T) {
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("host", "https://prod.cloud.databricks.com"))
+
+		trace := getHostResolution(cmd)
+		require.NotNil(t, trace)
+		require.Equal(t, databrickscfg.HostResolutionSelected, trace.Decision)
+		require.Equal(t, "prod", trace.Profile)
+		// The implicit DEFAULT section is scanned as well.
+		require.Equal(t, 3, trace.ProfilesScanned)
+		require.Equal(t, []string{"prod"}, trace.Matched)
+	})
+
+	t.Run("host from environment", func(t *testing.T) {
+		t.Setenv("DATABRICKS_HOST", "https://dev.cloud.databricks.com")
+
+		trace := getHostResolution(newCmd())
+		require.NotNil(t, trace)
+		require.Equal(t, databrickscfg.HostResolutionSelected, trace.Decision)
+		require.Equal(t, "dev", trace.Profile)
+	})
+
+	t.Run("auth configured in environment", func(t *testing.T) {
+		t.Setenv("DATABRICKS_HOST", "https://dev.cloud.databricks.com")
+		t.Setenv("DATABRICKS_TOKEN", "token")
+
+		trace := getHostResolution(newCmd())
+		require.NotNil(t, trace)
+		require.Equal(t, databrickscfg.HostResolutionSkippedAuthConfigured, trace.Decision)
+	})
+
+	t.Run("explicit profile skips resolution", func(t *testing.T) {
+		cmd := newCmd()
+		require.NoError(t, cmd.Flags().Set("host", "https://prod.cloud.databricks.com"))
+		require.NoError(t, cmd.Flags().Set("profile", "prod"))
+
+		require.Nil(t, getHostResolution(cmd))
+	})
+}
+
+func TestOfflineConfigMatchesOnlineConfig(t *testing.T) {
+	// The host metadata requests of the online check are answered locally.
+	server := httptest.NewServer(http.NotFoundHandler())
+	t.Cleanup(server.Close)
+
+	configFile := filepath.Join(t.TempDir(), ".databrickscfg")
+	err := os.WriteFile(configFile, []byte(`[DEFAULT]
+host = https://default.cloud.databricks.com
+token = default-token
+
+[prod]
+host = `+server.URL+`
+token = prod-token
+`), 0o600)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		profile string
+	}{
+		{
+			name: "host from environment",
+			env:  map[string]string{"DATABRICKS_HOST": server.URL},
+		},
+		{
+			name: "host and token from environment",
+			env:  map[string]string{"DATABRICKS_HOST": server.URL, "DATABRICKS_TOKEN": "env-token"},
+		},
+		{
+			name:    "profile flag",
+			profile: "prod",
+		},
+		{
+			name: "default profile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testutil.CleanupEnvironment(t)
+			t.Setenv("HOME", t.TempDir())
+			t.Setenv("PATH", "")
+			t.Setenv("DATABRICKS_CONFIG_FILE", configFile)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			newCmd := func() *cobra.Command {
+				ctx := root.SkipPrompt(root.SkipLoadBundle(cmdio.MockDiscard(t.Context())))
+				cmd := root.New(ctx)
+				if tt.profile != "" {
+					require.NoError(t, cmd.PersistentFlags().Set("profile", tt.profile))
+				}
+				return cmd
+			}
+
+			// The online check may fail to authenticate; only the credentials
+			// it resolved are compared.
+			cmd := newCmd()
+			_ = root.MustWorkspaceClient(cmd, nil)
+			online := cmdctx.ConfigUsed(cmd.Context())
+
+			offline, err := offlineConfig(newCmd())
+			require.NoError(t, err)
+			assert.Equal(t, online.Profile, offline.Profile)
+			assert.Equal(t, online.Host, offline.Host)
+			assert.Equal(t, online.Token, offline.Token)
+		})
+	}
+}
+
+func TestGetTokenLifetime(t *testing.T) {
+	now := time.Date(2025, 1, 2, 12, 0, 0, 0, time.UTC)
+	login := now.Add(-72 * time.Hour)
+	refresh := now.Add(-10 * time.Minute)
+
+	tokenCache := &inMemoryTokenCache{
+		Tokens: map[string]*oauth2.Token{
+			"with-times":                            {AccessToken: "a", Expiry: now.Add(45 * time.Minute)},
+			"legacy":                                {AccessToken: "b", Expiry: now.Add(-3 * time.Minute)},
+			"https://host-key.cloud.databricks.com": {AccessToken: "c", Expiry: now.Add(time.Hour)},
+		},
+	}
+	timesPath := filepath.Join(t.TempDir(), "token-cache-times.json")
+	times := auth.NewTokenTimesStore(timesPath)
+	err := os.WriteFile(timesPath, []byte(`{
+  "version": 1,
+  "tokens": {
+    "with-times": {"login": "2024-12-30T12:00:00Z", "last_refresh": "2025-01-02T11:50:00Z"}
+  }
+}`), 0o600)
+	require.NoError(t, err)
+
+	newConfig := func(host, profile string) *config.Config {
+		// A discovery URL avoids host metadata resolution over the network.
+		return &config.Config{Host: host, Profile: profile, DiscoveryURL: host + "/oidc/.well-known/oauth-authorization-server"}
+	}
+
+	ctx := t.Context()
+	lifetime := getTokenLifetime(ctx, newConfig("https://ws.cloud.databricks.com", "with-times"), tokenCache, times, now)
+	require.NotNil(t, lifetime)
+	assert.Equal(t, login, lifetime.Login)
+	assert.Equal(t, refresh, lifetime.LastRefresh)
+	assert.Equal(t, "2025-01-02T12:45:00Z (in 45m0s)", lifetime.ExpiryText())
+	assert.Equal(t, "2025-01-02T11:50:00Z (10m0s ago)", lifetime.LastRefreshText())
+	assert.Equal(t, "2024-12-30T12:00:00Z (72h0m0s ago)", lifetime.LoginText())
+
+	// Entries cached before timestamps were recorded.
+	lifetime = getTokenLifetime(ctx, newConfig("https://ws.cloud.databricks.com", "legacy"), tokenCache, times, now)
+	require.NotNil(t, lifetime)
+	assert.Equal(t, "2025-01-02T11:57:00Z (expired 3m0s ago)", lifetime.ExpiryText())
+	assert.Equal(t, "not recorded", lifetime.LastRefreshText())
+	assert.Equal(t, "not recorded", lifetime.LoginText())
+
+	// Fall back to the legacy host key.
+	lifetime = getTokenLifetime(ctx, newConfig("https://host-key.cloud.databricks.com", "other"), tokenCache, times, now)
+	require.NotNil(t, lifetime)
+	assert.Equal(t, now.Add(time.Hour), lifetime.AccessTokenExpiry)
+
+	// No cached token.
+	lifetime = getTokenLifetime(ctx, newConfig("https://missing.cloud.databricks.com", "missing"), tokenCache, times, now)
+	assert.Nil(t, lifetime)
+}
+
+func TestGetOfflineAuthStatus(t *testing.T) {
+	asOf := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	identities := auth.NewIdentityStore(filepath.Join(t.TempDir(), "identity-cache.json"))
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(t.Context())
+	cmd.Flags().String("host", "", "")
+	cmd.Flags().String("profile", "", "")
+
+	cfg := &config.Config{
+		Host:     "https://test.com",
+		Profile:  "my-profile",
+		AuthType: "databricks-cli",
+	}
+
+	// Without a cached identity.
+	status := getOfflineAuthStatus(cmd, cfg, false, identities, nil)
+	assert.Equal(t, "offline", status.Status)
+	assert.Empty(t, status.Username)
+	assert.Empty(t, status.AsOfText())
+	assert.Equal(t, "https://test.com", status.Details.Host)
+
+	// A successful describe caches the identity.
+	cacheIdentity(t.Context(), identities, cfg, &authStatus{Status: "success", Username: "test-user"}, asOf)
+
+	// A network failure falls back to the cached identity.
+	cause := &url.Error{Op: "Get", URL: "https://test.com", Err: &net.OpError{Op: "dial", Err: errors.New("no route to host")}}
+	status = getOfflineAuthStatus(cmd, cfg, false, identities, cause)
+	assert.Equal(t, "offline", status.Status)
+	assert.Equal(t, "test-user", status.Username)
+	assert.Equal(t, asOf, status.IdentityAsOf)
+	assert.Equal(t, " (as of 2025-01-02T03:04:05Z (offline))", status.AsOfText())
+	assert.Equal(t, cause, status.Error)
+
+	// Identities are cached per profile.
+	status = getOfflineAuthStatus(cmd, &config.Config{Host: "https://test.com", Profile: "other"}, false, identities, nil)
+	assert.Empty(t, status.Username)
+
+	// The identity cache is optional.
+	status = getOfflineAuthStatus(cmd, cfg, false, nil, nil)
+	assert.Equal(t, "offline", status.Status)
+	assert.Empty(t, status.Username)
+}
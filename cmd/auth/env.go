@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/databricks/cli/libs/databrickscfg"
 	"github.com/databricks/cli/libs/databrickscfg/profile"
 	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/spf13/cobra"
@@ -106,6 +107,7 @@ func newEnvCommand() *cobra.Command {
 		cfg := &config.Config{
 			Host:    host,
 			Profile: profile,
+			Loaders: databrickscfg.Loaders(),
 		}
 		if profile != "" {
 			cfg.Profile = profile
@@ -1,12 +1,16 @@
 package auth
 
 import (
+	"sync"
+
 	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
 	"golang.org/x/oauth2"
 )
 
 type inMemoryTokenCache struct {
 	Tokens map[string]*oauth2.Token
+
+	mu sync.Mutex
 }
 
 // Lookup implements TokenCache.
@@ -15,6 +19,8 @@ type inMemoryTokenCache struct {
 // mutate the returned token (e.g. clearing RefreshToken) would corrupt
 // entries shared across test cases.
 func (i *inMemoryTokenCache) Lookup(key string) (*oauth2.Token, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	token, ok := i.Tokens[key]
 	if !ok {
 		return nil, cache.ErrNotFound
@@ -27,6 +33,8 @@ func (i *inMemoryTokenCache) Lookup(key string) (*oauth2.Token, error) {
 // Stores a copy to prevent callers from mutating cached entries after Store
 // returns (mirrors file-backed cache semantics).
 func (i *inMemoryTokenCache) Store(key string, t *oauth2.Token) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	if t == nil {
 		delete(i.Tokens, key)
 	} else {
@@ -4,7 +4,6 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"runtime"
 	"strconv"
 	"strings"
@@ -16,13 +15,11 @@ import (
 	"github.com/databricks/cli/libs/databrickscfg/cfgpickers"
 	"github.com/databricks/cli/libs/databrickscfg/profile"
 	"github.com/databricks/cli/libs/env"
-	"github.com/databricks/cli/libs/exec"
 	"github.com/databricks/cli/libs/log"
 	"github.com/databricks/databricks-sdk-go"
 	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/databricks/databricks-sdk-go/config/experimental/auth/authconv"
 	"github.com/databricks/databricks-sdk-go/credentials/u2m"
-	browserpkg "github.com/pkg/browser"
 	"github.com/spf13/cobra"
 	"golang.org/x/oauth2"
 )
@@ -122,13 +119,20 @@ Note: URLs containing "?" must be quoted to prevent shell interpretation.
 
 If a profile with the given name already exists, it is updated. Otherwise
 a new profile is created.
+
+Use --warm-cache to refresh the cached tokens of all profiles instead of
+logging in. Profiles without a cached login are skipped; no browser login
+is started.
 `, defaultConfigPath),
 	}
 
 	var loginTimeout time.Duration
 	var configureCluster bool
 	var configureServerless bool
+	var configureWarehouse bool
 	var skipWorkspace bool
+	var pinWorkspace bool
+	var warmCache bool
 	var scopes string
 	cmd.Flags().DurationVar(&loginTimeout, "timeout", defaultTimeout,
 		"Timeout for completing login challenge in the browser")
@@ -136,22 +140,45 @@ a new profile is created.
 		"Prompts to configure cluster")
 	cmd.Flags().BoolVar(&configureServerless, "configure-serverless", false,
 		"Prompts to configure serverless")
+	cmd.Flags().BoolVar(&configureWarehouse, "configure-warehouse", false,
+		"Prompts to configure SQL warehouse")
 	cmd.Flags().BoolVar(&skipWorkspace, "skip-workspace", false,
 		"Skip workspace selection for account-level access")
+	cmd.Flags().BoolVar(&pinWorkspace, "pin-workspace", false,
+		"Pin the workspace ID to the profile so that later commands can detect if the workspace behind the host changes")
+	cmd.Flags().BoolVar(&warmCache, "warm-cache", false,
+		"Refresh the cached tokens of all profiles without logging in")
 	cmd.Flags().StringVar(&scopes, "scopes", "",
 		"Comma-separated list of OAuth scopes to request (defaults to 'all-apis')")
 
-	cmd.PreRunE = profileHostConflictCheck
+	cmd.PreRunE = validateAuthFlags
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		profileName := cmd.Flag("profile").Value.String()
 
+		if warmCache {
+			if len(args) > 0 || profileName != "" || authArguments.Host != "" {
+				return errors.New("--warm-cache refreshes all profiles and cannot be combined with a profile or host")
+			}
+			return runWarmCache(ctx, warmCacheArgs{
+				profiler:       profile.DefaultProfiler,
+				concurrency:    warmCacheConcurrency,
+				profileTimeout: warmCacheProfileTimeout,
+			})
+		}
+
 		// Cluster and Serverless are mutually exclusive.
 		if configureCluster && configureServerless {
 			return errors.New("please either configure serverless or cluster, not both")
 		}
 
+		// Pickers run after the browser login, so fail before starting it
+		// if they cannot be shown.
+		if err := validatePickerFlags(ctx, configureCluster, configureWarehouse); err != nil {
+			return err
+		}
+
 		// The positional argument is a shorthand that resolves to either a
 		// profile or a host. It cannot be combined with explicit flags.
 		// Use "databricks auth login --host X --profile Y" instead.
@@ -190,6 +217,10 @@ a new profile is created.
 		if err != nil {
 			return err
 		}
+		if existingProfile != nil {
+			// Update the existing profile if its name differs in case only.
+			profileName = existingProfile.Name
+		}
 
 		// If no host is available from any source, use the discovery flow
 		// via login.databricks.com.
@@ -197,7 +228,7 @@ a new profile is created.
 			if err := validateDiscoveryFlagCompatibility(cmd); err != nil {
 				return err
 			}
-			return discoveryLogin(ctx, &defaultDiscoveryClient{}, profileName, loginTimeout, scopes, existingProfile, getBrowserFunc(cmd))
+			return discoveryLogin(ctx, &defaultDiscoveryClient{}, profileName, loginTimeout, scopes, existingProfile, auth.BrowserFunc(ctx))
 		}
 
 		// Load unified host flag from the profile if not explicitly set via CLI flag.
@@ -231,7 +262,8 @@ a new profile is created.
 		}
 		persistentAuthOpts := []u2m.PersistentAuthOption{
 			u2m.WithOAuthArgument(oauthArgument),
-			u2m.WithBrowser(getBrowserFunc(cmd)),
+			u2m.WithBrowser(auth.BrowserFunc(ctx)),
+			auth.WithTokenTimes(ctx, auth.TokenLogin),
 		}
 		if len(scopesList) > 0 {
 			persistentAuthOpts = append(persistentAuthOpts, u2m.WithScopes(scopesList))
@@ -246,7 +278,14 @@ a new profile is created.
 		defer cancel()
 
 		if err = persistentAuth.Challenge(); err != nil {
-			return err
+			return auth.DiagnoseHostError(ctx, authArguments.Host, err)
+		}
+		// Login does not consult the discovery cache. Now that discovery
+		// succeeded, other commands must not skip the host either.
+		if c, err := auth.DefaultDiscoveryCache(); err == nil {
+			if err := c.Forget(authArguments.Host); err != nil {
+				log.Debugf(ctx, "Failed to update the discovery cache: %v", err)
+			}
 		}
 		// At this point, an OAuth token has been successfully minted and stored
 		// in the CLI cache. The rest of the command focuses on:
@@ -293,16 +332,7 @@ a new profile is created.
 
 		switch {
 		case configureCluster:
-			// Create a workspace client to list clusters for interactive selection.
-			// We use a custom CredentialsStrategy that wraps the token we just minted,
-			// avoiding the need to spawn a child CLI process (which AuthType "databricks-cli" does).
-			w, err := databricks.NewWorkspaceClient(&databricks.Config{
-				Host:                       authArguments.Host,
-				AccountID:                  authArguments.AccountID,
-				WorkspaceID:                authArguments.WorkspaceID,
-				Experimental_IsUnifiedHost: authArguments.IsUnifiedHost,
-				Credentials:                config.NewTokenSourceStrategy("login-token", authconv.AuthTokenSource(persistentAuth)),
-			})
+			w, err := newLoginWorkspaceClient(authArguments, persistentAuth)
 			if err != nil {
 				return err
 			}
@@ -321,6 +351,18 @@ a new profile is created.
 			// Neither flag: preserve both from existing profile via merge semantics.
 		}
 
+		var warehouseID string
+		if configureWarehouse {
+			w, err := newLoginWorkspaceClient(authArguments, persistentAuth)
+			if err != nil {
+				return err
+			}
+			warehouseID, err = cfgpickers.AskForWarehouse(ctx, w)
+			if err != nil {
+				return err
+			}
+		}
+
 		if profileName != "" {
 			err := databrickscfg.SaveToProfile(ctx, &config.Config{
 				Profile:                    profileName,
@@ -330,6 +372,7 @@ a new profile is created.
 				WorkspaceID:                authArguments.WorkspaceID,
 				Experimental_IsUnifiedHost: authArguments.IsUnifiedHost,
 				ClusterID:                  clusterID,
+				WarehouseID:                warehouseID,
 				ConfigFile:                 env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
 				ServerlessComputeID:        serverlessComputeID,
 				Scopes:                     scopesList,
@@ -338,7 +381,15 @@ a new profile is created.
 				return err
 			}
 
+			if pinWorkspace {
+				err = pinProfileWorkspace(ctx, profileName, authArguments, persistentAuth)
+				if err != nil {
+					return err
+				}
+			}
+
 			cmdio.LogString(ctx, fmt.Sprintf("Profile %s was successfully saved", profileName))
+			auth.WarnShadowingEnvVars(ctx, profileName, &config.Config{Host: authArguments.Host})
 		}
 
 		return nil
@@ -347,6 +398,54 @@ a new profile is created.
 	return cmd
 }
 
+// validatePickerFlags returns an error if a cluster or warehouse picker is
+// requested but prompts are not supported.
+func validatePickerFlags(ctx context.Context, configureCluster, configureWarehouse bool) error {
+	if cmdio.IsPromptSupported(ctx) {
+		return nil
+	}
+	if configureCluster {
+		return errors.New("--configure-cluster requires an interactive terminal to select a cluster. Set cluster_id in the profile instead")
+	}
+	if configureWarehouse {
+		return errors.New("--configure-warehouse requires an interactive terminal to select a SQL warehouse. Set warehouse_id in the profile instead")
+	}
+	return nil
+}
+
+// newLoginWorkspaceClient creates a workspace client that authenticates with
+// the token minted during login. We use a custom CredentialsStrategy that
+// wraps the token, avoiding the need to spawn a child CLI process (which
+// AuthType "databricks-cli" does).
+func newLoginWorkspaceClient(authArguments *auth.AuthArguments, persistentAuth *u2m.PersistentAuth) (*databricks.WorkspaceClient, error) {
+	return databricks.NewWorkspaceClient(&databricks.Config{
+		Host:                       authArguments.Host,
+		AccountID:                  authArguments.AccountID,
+		WorkspaceID:                authArguments.WorkspaceID,
+		Experimental_IsUnifiedHost: authArguments.IsUnifiedHost,
+		Credentials:                config.NewTokenSourceStrategy("login-token", authconv.AuthTokenSource(persistentAuth)),
+	})
+}
+
+// pinProfileWorkspace records the ID of the workspace behind the host in the
+// profile, so that a later change of the workspace can be detected.
+func pinProfileWorkspace(ctx context.Context, profileName string, authArguments *auth.AuthArguments, persistentAuth *u2m.PersistentAuth) error {
+	w, err := newLoginWorkspaceClient(authArguments, persistentAuth)
+	if err != nil {
+		return err
+	}
+	workspaceID, err := auth.FetchWorkspacePin(ctx, w)
+	if err != nil {
+		return fmt.Errorf("cannot pin workspace for profile %q: %w", profileName, err)
+	}
+	err = databrickscfg.SetProfileKey(ctx, env.Get(ctx, "DATABRICKS_CONFIG_FILE"), profileName, auth.PinnedWorkspaceIDKey, workspaceID)
+	if err != nil {
+		return err
+	}
+	cmdio.LogString(ctx, fmt.Sprintf("Pinned profile %s to workspace ID %s", profileName, workspaceID))
+	return nil
+}
+
 // Sets the host in the persistentAuth object based on the provided arguments and flags.
 // Follows the following precedence:
 // 1. [HOST] (first positional argument) or --host flag. Error if both are specified.
@@ -517,11 +616,8 @@ func loadProfileByName(ctx context.Context, profileName string, profiler profile
 		return nil, err
 	}
 
-	if len(profiles) > 0 {
-		// LoadProfiles returns only one profile per name, even with multiple profiles in the config file with the same name.
-		return &profiles[0], nil
-	}
-	return nil, nil
+	// LoadProfiles returns only one profile per name, even with multiple profiles in the config file with the same name.
+	return profiles.FindByName(ctx, profileName)
 }
 
 // shouldUseDiscovery returns true if the discovery flow should be used
@@ -547,6 +643,8 @@ var discoveryIncompatibleFlags = []string{
 	"experimental-is-unified-host",
 	"configure-cluster",
 	"configure-serverless",
+	"configure-warehouse",
+	"pin-workspace",
 }
 
 // validateDiscoveryFlagCompatibility returns an error if any flags that require
@@ -561,22 +659,6 @@ func validateDiscoveryFlagCompatibility(cmd *cobra.Command) error {
 	return nil
 }
 
-// openURLSuppressingStderr opens a URL in the browser while suppressing stderr output.
-// This prevents xdg-open error messages from being displayed to the user.
-func openURLSuppressingStderr(url string) error {
-	// Save the original stderr from the browser package
-	originalStderr := browserpkg.Stderr
-	defer func() {
-		browserpkg.Stderr = originalStderr
-	}()
-
-	// Redirect stderr to discard to suppress xdg-open errors
-	browserpkg.Stderr = io.Discard
-
-	// Call the browser open function
-	return browserpkg.OpenURL(url)
-}
-
 // discoveryLogin runs the login.databricks.com discovery flow. The user
 // authenticates in the browser, selects a workspace, and the CLI receives
 // the workspace host from the OAuth callback's iss parameter.
@@ -595,6 +677,7 @@ func discoveryLogin(ctx context.Context, dc discoveryClient, profileName string,
 		u2m.WithOAuthArgument(arg),
 		u2m.WithBrowser(browserFunc),
 		u2m.WithDiscoveryLogin(),
+		auth.WithTokenTimes(ctx, auth.TokenLogin),
 	}
 	if len(scopesList) > 0 {
 		opts = append(opts, u2m.WithScopes(scopesList))
@@ -685,6 +768,7 @@ func discoveryLogin(ctx context.Context, dc discoveryClient, profileName string,
 	}
 
 	cmdio.LogString(ctx, fmt.Sprintf("Profile %s was successfully saved", profileName))
+	auth.WarnShadowingEnvVars(ctx, profileName, &config.Config{Host: discoveredHost})
 	return nil
 }
 
@@ -705,10 +789,11 @@ func splitScopes(scopes string) []string {
 }
 
 // oauthLoginClearKeys returns profile keys that should be explicitly removed
-// when performing an OAuth login. Derives auth credential fields dynamically
-// from the SDK's ConfigAttributes to stay in sync as new auth methods are added.
+// when performing an OAuth login: the credentials of all other auth types.
+// They are derived from the SDK's ConfigAttributes to stay in sync as new auth
+// methods are added.
 func oauthLoginClearKeys() []string {
-	return databrickscfg.AuthCredentialKeys()
+	return databrickscfg.OtherCredentialKeys(authTypeDatabricksCLI)
 }
 
 // promptForWorkspaceSelection lists workspaces for a SPOG account and lets the
@@ -784,38 +869,3 @@ func promptForWorkspaceID(ctx context.Context) (string, error) {
 	}
 	return strings.TrimSpace(result), nil
 }
-
-// getBrowserFunc returns a function that opens the given URL in the browser.
-// It respects the BROWSER environment variable:
-// - empty string: uses the default browser
-// - "none": prints the URL to stdout without opening a browser
-// - custom command: executes the specified command with the URL as argument
-func getBrowserFunc(cmd *cobra.Command) func(url string) error {
-	browser := env.Get(cmd.Context(), "BROWSER")
-	switch browser {
-	case "":
-		return openURLSuppressingStderr
-	case "none":
-		return func(url string) error {
-			cmdio.LogString(cmd.Context(), "Please complete authentication by opening this link in your browser:\n"+url)
-			return nil
-		}
-	default:
-		return func(url string) error {
-			// Run the browser command via a shell.
-			// It can be a script or a binary and scripts cannot be executed directly on Windows.
-			e, err := exec.NewCommandExecutor(".")
-			if err != nil {
-				return err
-			}
-
-			e.WithInheritOutput()
-			cmd, err := e.StartCommand(cmd.Context(), fmt.Sprintf("%q %q", browser, url))
-			if err != nil {
-				return err
-			}
-
-			return cmd.Wait()
-		}
-	}
-}
@@ -576,6 +576,18 @@ func TestValidateDiscoveryFlagCompatibility(t *testing.T) {
 			flagVal: "true",
 			wantErr: "--configure-serverless requires --host to be specified",
 		},
+		{
+			name:    "pin-workspace is incompatible",
+			setFlag: "pin-workspace",
+			flagVal: "true",
+			wantErr: "--pin-workspace requires --host to be specified",
+		},
+		{
+			name:    "configure-warehouse is incompatible",
+			setFlag: "configure-warehouse",
+			flagVal: "true",
+			wantErr: "--configure-warehouse requires --host to be specified",
+		},
 		{
 			name: "no flags set is ok",
 		},
@@ -588,6 +600,8 @@ func TestValidateDiscoveryFlagCompatibility(t *testing.T) {
 			cmd.Flags().Bool("experimental-is-unified-host", false, "")
 			cmd.Flags().Bool("configure-cluster", false, "")
 			cmd.Flags().Bool("configure-serverless", false, "")
+			cmd.Flags().Bool("configure-warehouse", false, "")
+			cmd.Flags().Bool("pin-workspace", false, "")
 
 			if tt.setFlag != "" {
 				require.NoError(t, cmd.Flags().Set(tt.setFlag, tt.flagVal))
@@ -603,6 +617,16 @@ func TestValidateDiscoveryFlagCompatibility(t *testing.T) {
 	}
 }
 
+func TestValidatePickerFlags(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	assert.NoError(t, validatePickerFlags(ctx, false, false))
+	assert.ErrorContains(t, validatePickerFlags(ctx, true, false), "--configure-cluster requires an interactive terminal")
+	assert.ErrorContains(t, validatePickerFlags(ctx, false, true), "--configure-warehouse requires an interactive terminal")
+
+	ctx, _ = cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+	assert.NoError(t, validatePickerFlags(ctx, true, true))
+}
+
 func TestDiscoveryLogin_IntrospectionFailureStillSavesProfile(t *testing.T) {
 	tmpDir := t.TempDir()
 	configPath := filepath.Join(tmpDir, ".databrickscfg")
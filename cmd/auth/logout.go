@@ -31,15 +31,17 @@ import (
 	"github.com/spf13/cobra"
 )
 
-const logoutWarningTemplate = `{{ "Warning" | yellow }}: This will {{ if .DeleteProfile }}log out of and delete profile {{ .ProfileName | bold }}{{ else }}log out of profile {{ .ProfileName | bold }}{{ end }}.
+const logoutWarningTemplate = `{{ "Warning" | yellow }}: This will {{ if not .ClearTokens }}delete profile {{ .ProfileName | bold }}{{ else if .DeleteProfile }}log out of and delete profile {{ .ProfileName | bold }}{{ else }}log out of profile {{ .ProfileName | bold }}{{ end }}.
 
 The following changes will be made:
 {{- if .DeleteProfile }}
   - Remove profile {{ .ProfileName | bold }} from {{ .ConfigPath }}
 {{- end }}
+{{- if .ClearTokens }}
   - Delete any cached OAuth tokens for this profile
 
 You will need to run {{ "databricks auth login" | bold }} to re-authenticate.
+{{- end }}
 `
 
 func newLogoutCommand() *cobra.Command {
@@ -142,7 +144,7 @@ to specify it explicitly.
 			autoApprove:    autoApprove,
 			deleteProfile:  deleteProfile,
 			profiler:       profiler,
-			tokenCache:     tokenCache,
+			tokenCache:     auth.RecordTokenTimes(ctx, tokenCache, nil, auth.TokenRefresh),
 			configFilePath: env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
 		})
 	}
@@ -151,9 +153,12 @@ to specify it explicitly.
 }
 
 type logoutArgs struct {
-	profileName    string
-	autoApprove    bool
-	deleteProfile  bool
+	profileName   string
+	autoApprove   bool
+	deleteProfile bool
+	// keepTokens keeps the cached OAuth tokens, so that only the profile is
+	// deleted. It is only set by "auth profiles delete".
+	keepTokens     bool
 	profiler       profile.Profiler
 	tokenCache     cache.TokenCache
 	configFilePath string
@@ -164,6 +169,7 @@ func runLogout(ctx context.Context, args logoutArgs) error {
 	if err != nil {
 		return err
 	}
+	args.profileName = matchedProfile.Name
 
 	if !args.autoApprove {
 		if !cmdio.IsPromptSupported(ctx) {
@@ -178,6 +184,7 @@ func runLogout(ctx context.Context, args logoutArgs) error {
 			"ProfileName":   args.profileName,
 			"ConfigPath":    configPath,
 			"DeleteProfile": args.deleteProfile,
+			"ClearTokens":   !args.keepTokens,
 		}, "", logoutWarningTemplate)
 		if err != nil {
 			return err
@@ -188,7 +195,7 @@ func runLogout(ctx context.Context, args logoutArgs) error {
 			return err
 		}
 		if !approved {
-			cmdio.LogString(ctx, "Aborting logout... No changes were made.")
+			cmdio.LogString(ctx, "Aborting... No changes were made.")
 			return nil
 		}
 	}
@@ -203,7 +210,8 @@ func runLogout(ctx context.Context, args logoutArgs) error {
 	// want to clear the token cache if the profile was created by the login command.
 	// Otherwise, we could be deleting profiles that were created by other means (e.g. manually).
 	isCreatedByLogin := matchedProfile.AuthType == "databricks-cli"
-	if isCreatedByLogin {
+	clearTokens := isCreatedByLogin && !args.keepTokens
+	if clearTokens {
 		err = clearTokenCache(ctx, *matchedProfile, args.profiler, args.tokenCache)
 		if err != nil {
 			return fmt.Errorf("failed to clear token cache: %w", err)
@@ -213,7 +221,7 @@ func runLogout(ctx context.Context, args logoutArgs) error {
 	if args.deleteProfile {
 		err = databrickscfg.DeleteProfile(ctx, args.profileName, args.configFilePath)
 		if err != nil {
-			if isCreatedByLogin {
+			if clearTokens {
 				return fmt.Errorf("token cache cleared, but failed to delete profile. Re-run with --delete to retry. If this error persists, please check the state of the config file: %w", err)
 			}
 
@@ -227,11 +235,13 @@ func runLogout(ctx context.Context, args logoutArgs) error {
 		}
 	}
 
-	if isCreatedByLogin && args.deleteProfile {
+	if clearTokens && args.deleteProfile {
 		cmdio.LogString(ctx, fmt.Sprintf("Logged out of and deleted profile %q.", args.profileName))
-	} else if isCreatedByLogin && !args.deleteProfile {
+	} else if clearTokens && !args.deleteProfile {
 		cmdio.LogString(ctx, fmt.Sprintf("Logged out of profile %q. Use --delete to also remove it from the config file.", args.profileName))
-	} else if !isCreatedByLogin && args.deleteProfile {
+	} else if isCreatedByLogin && args.deleteProfile {
+		cmdio.LogString(ctx, fmt.Sprintf("Deleted profile %q and kept its cached OAuth tokens.", args.profileName))
+	} else if args.deleteProfile {
 		cmdio.LogString(ctx, fmt.Sprintf("Deleted profile %q with no tokens to clear.", args.profileName))
 	} else {
 		cmdio.LogString(ctx, fmt.Sprintf("No tokens to clear for profile %q. Use --delete to remove it from the config file.", args.profileName))
@@ -247,7 +257,11 @@ func getMatchingProfile(ctx context.Context, profileName string, profiler profil
 		return nil, err
 	}
 
-	if len(profiles) == 0 {
+	p, err := profiles.FindByName(ctx, profileName)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
 		allProfiles, err := profiler.LoadProfiles(ctx, profile.MatchAllProfiles)
 		if err != nil {
 			return nil, fmt.Errorf("profile %q not found", profileName)
@@ -257,7 +271,7 @@ func getMatchingProfile(ctx context.Context, profileName string, profiler profil
 		return nil, fmt.Errorf("profile %q not found. Available profiles: %s", profileName, names)
 	}
 
-	return &profiles[0], nil
+	return p, nil
 }
 
 // clearTokenCache removes cached OAuth tokens for the given profile from the
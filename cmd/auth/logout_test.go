@@ -32,17 +32,17 @@ auth_type  = databricks-cli
 
 [my-workspace-stale-account]
 host = https://stale-account.cloud.databricks.com
-account_id = stale-account
+account_id = 11111111-1111-1111-1111-111111111111
 auth_type  = databricks-cli
 
 [my-account]
 host = https://accounts.cloud.databricks.com
-account_id = abc123
+account_id = 22222222-2222-2222-2222-222222222222
 auth_type  = databricks-cli
 
 [my-unified]
 host = https://unified.cloud.databricks.com
-account_id = def456
+account_id = 33333333-3333-3333-3333-333333333333
 experimental_is_unified_host = true
 auth_type  = databricks-cli
 
@@ -58,11 +58,11 @@ var logoutTestTokensCacheConfig = map[string]*oauth2.Token{
 	"my-workspace-stale-account": {AccessToken: "stale-account-token"},
 	"my-account":                 {AccessToken: "my-account-token"},
 	"my-unified":                 {AccessToken: "my-unified-token"},
-	"https://my-workspace.cloud.databricks.com":                  {AccessToken: "shared-workspace-host-token"},
-	"https://my-unique-workspace.cloud.databricks.com":           {AccessToken: "unique-workspace-host-token"},
-	"https://stale-account.cloud.databricks.com":                 {AccessToken: "stale-account-host-token"},
-	"https://accounts.cloud.databricks.com/oidc/accounts/abc123": {AccessToken: "account-host-token"},
-	"https://unified.cloud.databricks.com/oidc/accounts/def456":  {AccessToken: "unified-host-token"},
+	"https://my-workspace.cloud.databricks.com":                                                {AccessToken: "shared-workspace-host-token"},
+	"https://my-unique-workspace.cloud.databricks.com":                                         {AccessToken: "unique-workspace-host-token"},
+	"https://stale-account.cloud.databricks.com":                                               {AccessToken: "stale-account-host-token"},
+	"https://accounts.cloud.databricks.com/oidc/accounts/22222222-2222-2222-2222-222222222222": {AccessToken: "account-host-token"},
+	"https://unified.cloud.databricks.com/oidc/accounts/33333333-3333-3333-3333-333333333333":  {AccessToken: "unified-host-token"},
 	"my-m2m":                              {AccessToken: "m2m-service-token"},
 	"https://my-m2m.cloud.databricks.com": {AccessToken: "m2m-host-token"},
 }
@@ -117,14 +117,14 @@ func TestLogout(t *testing.T) {
 		{
 			name:         "existing account profile",
 			profileName:  "my-account",
-			hostBasedKey: "https://accounts.cloud.databricks.com/oidc/accounts/abc123",
+			hostBasedKey: "https://accounts.cloud.databricks.com/oidc/accounts/22222222-2222-2222-2222-222222222222",
 			isSharedKey:  false,
 			autoApprove:  true,
 		},
 		{
 			name:         "existing unified profile",
 			profileName:  "my-unified",
-			hostBasedKey: "https://unified.cloud.databricks.com/oidc/accounts/def456",
+			hostBasedKey: "https://unified.cloud.databricks.com/oidc/accounts/33333333-3333-3333-3333-333333333333",
 			isSharedKey:  false,
 			autoApprove:  true,
 		},
@@ -159,7 +159,7 @@ func TestLogout(t *testing.T) {
 		{
 			name:          "delete account profile",
 			profileName:   "my-account",
-			hostBasedKey:  "https://accounts.cloud.databricks.com/oidc/accounts/abc123",
+			hostBasedKey:  "https://accounts.cloud.databricks.com/oidc/accounts/22222222-2222-2222-2222-222222222222",
 			isSharedKey:   false,
 			autoApprove:   true,
 			deleteProfile: true,
@@ -167,7 +167,7 @@ func TestLogout(t *testing.T) {
 		{
 			name:          "delete unified profile",
 			profileName:   "my-unified",
-			hostBasedKey:  "https://unified.cloud.databricks.com/oidc/accounts/def456",
+			hostBasedKey:  "https://unified.cloud.databricks.com/oidc/accounts/33333333-3333-3333-3333-333333333333",
 			isSharedKey:   false,
 			autoApprove:   true,
 			deleteProfile: true,
@@ -371,19 +371,19 @@ func newWellKnownServer(t *testing.T, accountScoped bool, accountID string) *htt
 }
 
 func TestLogoutSPOGProfile(t *testing.T) {
-	spogServer := newWellKnownServer(t, true, "spog-acct")
+	spogServer := newWellKnownServer(t, true, "44444444-4444-4444-4444-444444444444")
 
 	ctx := cmdio.MockDiscard(t.Context())
 	configPath := writeTempConfig(t, `[DEFAULT]
 [spog-profile]
 host = `+spogServer.URL+`
-account_id = spog-acct
+account_id = 44444444-4444-4444-4444-444444444444
 workspace_id = spog-ws
 auth_type = databricks-cli
 `)
 	t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
 
-	hostKey := spogServer.URL + "/oidc/accounts/spog-acct"
+	hostKey := spogServer.URL + "/oidc/accounts/44444444-4444-4444-4444-444444444444"
 	tokenCache := &inMemoryTokenCache{
 		Tokens: map[string]*oauth2.Token{
 			"spog-profile": {AccessToken: "spog-profile-token"},
@@ -406,7 +406,7 @@ auth_type = databricks-cli
 
 func TestHostCacheKeyAndMatchFn(t *testing.T) {
 	wsServer := newWellKnownServer(t, false, "ws-account")
-	spogServer := newWellKnownServer(t, true, "spog-account")
+	spogServer := newWellKnownServer(t, true, "55555555-5555-5555-5555-555555555555")
 
 	cases := []struct {
 		name         string
@@ -427,7 +427,7 @@ func TestHostCacheKeyAndMatchFn(t *testing.T) {
 			profile: profile.Profile{
 				Name:      "stale",
 				Host:      wsServer.URL,
-				AccountID: "stale-account",
+				AccountID: "11111111-1111-1111-1111-111111111111",
 			},
 			wantKey: wsServer.URL,
 		},
@@ -436,28 +436,28 @@ func TestHostCacheKeyAndMatchFn(t *testing.T) {
 			profile: profile.Profile{
 				Name:      "acct",
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "abc123",
+				AccountID: "22222222-2222-2222-2222-222222222222",
 			},
-			wantKey: "https://accounts.cloud.databricks.com/oidc/accounts/abc123",
+			wantKey: "https://accounts.cloud.databricks.com/oidc/accounts/22222222-2222-2222-2222-222222222222",
 		},
 		{
 			name: "unified host with flag",
 			profile: profile.Profile{
 				Name:          "unified",
 				Host:          wsServer.URL,
-				AccountID:     "def456",
+				AccountID:     "33333333-3333-3333-3333-333333333333",
 				IsUnifiedHost: true,
 			},
-			wantKey: wsServer.URL + "/oidc/accounts/def456",
+			wantKey: wsServer.URL + "/oidc/accounts/33333333-3333-3333-3333-333333333333",
 		},
 		{
 			name: "SPOG profile routes to account key via discovery",
 			profile: profile.Profile{
 				Name:      "spog",
 				Host:      spogServer.URL,
-				AccountID: "spog-account",
+				AccountID: "55555555-5555-5555-5555-555555555555",
 			},
-			wantKey: spogServer.URL + "/oidc/accounts/spog-account",
+			wantKey: spogServer.URL + "/oidc/accounts/55555555-5555-5555-5555-555555555555",
 		},
 		{
 			name: "empty host returns empty",
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"slices"
 	"sync"
 	"time"
 
@@ -21,14 +22,18 @@ import (
 )
 
 type profileMetadata struct {
-	Name        string `json:"name"`
-	Host        string `json:"host,omitempty"`
-	AccountID   string `json:"account_id,omitempty"`
-	WorkspaceID string `json:"workspace_id,omitempty"`
-	Cloud       string `json:"cloud"`
-	AuthType    string `json:"auth_type"`
-	Valid       bool   `json:"valid"`
-	Default     bool   `json:"default,omitempty"`
+	Name                string    `json:"name"`
+	Host                string    `json:"host,omitempty"`
+	AccountID           string    `json:"account_id,omitempty"`
+	WorkspaceID         string    `json:"workspace_id,omitempty"`
+	ClusterID           string    `json:"cluster_id,omitempty"`
+	WarehouseID         string    `json:"warehouse_id,omitempty"`
+	ServerlessComputeID string    `json:"serverless_compute_id,omitempty"`
+	Cloud               string    `json:"cloud"`
+	AuthType            string    `json:"auth_type"`
+	Valid               bool      `json:"valid"`
+	Default             bool      `json:"default,omitempty"`
+	LastUsed            time.Time `json:"last_used,omitzero"`
 }
 
 func (c *profileMetadata) IsEmpty() bool {
@@ -37,7 +42,7 @@ func (c *profileMetadata) IsEmpty() bool {
 
 func (c *profileMetadata) Load(ctx context.Context, configFilePath string, skipValidate bool) {
 	cfg := &config.Config{
-		Loaders:           []config.Loader{config.ConfigFile},
+		Loaders:           []config.Loader{databrickscfg.ConfigFile},
 		ConfigFile:        configFilePath,
 		Profile:           c.Name,
 		DatabricksCliPath: env.Get(ctx, "DATABRICKS_CLI_PATH"),
@@ -93,22 +98,89 @@ func (c *profileMetadata) Load(ctx context.Context, configFilePath string, skipV
 	}
 }
 
+// sortLastUsed sorts profiles by when they were last used, most recent first.
+const sortLastUsed = "last-used"
+
+// profileFilter selects the profiles to list. Filters that are set are
+// combined; a profile is listed if it matches all of them.
+type profileFilter struct {
+	hostPattern   string
+	accountID     string
+	workspaceOnly bool
+	accountOnly   bool
+}
+
+func (f profileFilter) isSet() bool {
+	return f.hostPattern != "" || f.accountID != "" || f.workspaceOnly || f.accountOnly
+}
+
+func (f profileFilter) matchFunction() (profile.ProfileMatchFunction, error) {
+	var fns []profile.ProfileMatchFunction
+	if f.hostPattern != "" {
+		fn, err := profile.WithHostPattern(f.hostPattern)
+		if err != nil {
+			return nil, err
+		}
+		fns = append(fns, fn)
+	}
+	if f.accountID != "" {
+		fns = append(fns, profile.WithAccountID(f.accountID))
+	}
+	if f.workspaceOnly {
+		fns = append(fns, profile.MatchWorkspaceProfiles)
+	}
+	if f.accountOnly {
+		fns = append(fns, profile.MatchAccountProfiles)
+	}
+	return profile.MatchAll(fns...), nil
+}
+
+// matchingProfileNames returns the names of the profiles of profiler that
+// match the filter.
+func matchingProfileNames(ctx context.Context, profiler profile.Profiler, filter profileFilter) (map[string]bool, error) {
+	fn, err := filter.matchFunction()
+	if err != nil {
+		return nil, err
+	}
+	profiles, err := profiler.LoadProfiles(ctx, fn)
+	if err != nil && !errors.Is(err, profile.ErrNoConfiguration) {
+		return nil, err
+	}
+	names := map[string]bool{}
+	for _, p := range profiles {
+		names[p.Name] = true
+	}
+	return names, nil
+}
+
 func newProfilesCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "profiles",
 		Short: "Lists profiles from ~/.databrickscfg",
 		Annotations: map[string]string{
 			"template": cmdio.Heredoc(`
-			{{header "Name"}}	{{header "Host"}}	{{header "Valid"}}
-			{{range .Profiles}}{{.Name | green}}{{if .Default}} (Default){{end}}	{{.Host|cyan}}	{{bool .Valid}}
+			{{header "Name"}}	{{header "Host"}}	{{header "Valid"}}	{{header "Last used"}}
+			{{range .Profiles}}{{.Name | green}}{{if .Default}} (Default){{end}}	{{.Host|cyan}}	{{bool .Valid}}	{{if .LastUsed.IsZero}}-{{else}}{{pretty_date .LastUsed}}{{end}}
 			{{end}}`),
 		},
 	}
 
 	var skipValidate bool
+	var sortBy string
+	var filter profileFilter
 	cmd.Flags().BoolVar(&skipValidate, "skip-validate", false, "Whether to skip validating the profiles")
+	cmd.Flags().StringVar(&sortBy, "sort", "", "Sort profiles by the given key. Supported values: last-used")
+	cmd.Flags().StringVar(&filter.hostPattern, "host", "", "Only list profiles whose host matches the glob pattern, for example '*.azuredatabricks.net'")
+	cmd.Flags().StringVar(&filter.accountID, "account-id", "", "Only list profiles with the given account ID")
+	cmd.Flags().BoolVar(&filter.workspaceOnly, "workspace-only", false, "Only list workspace profiles")
+	cmd.Flags().BoolVar(&filter.accountOnly, "account-only", false, "Only list account profiles")
+	cmd.MarkFlagsMutuallyExclusive("workspace-only", "account-only")
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		if sortBy != "" && sortBy != sortLastUsed {
+			return fmt.Errorf("unsupported value %q for --sort, supported values: %s", sortBy, sortLastUsed)
+		}
+
 		var profiles []*profileMetadata
 		iniFile, err := profile.DefaultProfiler.Get(cmd.Context())
 		if errors.Is(err, fs.ErrNotExist) {
@@ -120,17 +192,37 @@ func newProfilesCommand() *cobra.Command {
 			return fmt.Errorf("cannot parse config file: %w", err)
 		}
 
+		var matching map[string]bool
+		if filter.isSet() {
+			matching, err = matchingProfileNames(cmd.Context(), profile.GetProfiler(cmd.Context()), filter)
+			if err != nil {
+				return err
+			}
+		}
+
 		defaultProfile := databrickscfg.GetConfiguredDefaultProfileFrom(iniFile)
+		lastUsed := auth.ProfilesLastUsed(cmd.Context())
 
 		var wg sync.WaitGroup
 		for _, v := range iniFile.Sections() {
-			hash := v.KeysHash()
+			if matching != nil && !matching[v.Name()] {
+				continue
+			}
+			hash, err := databrickscfg.ExpandEnv(cmd.Context(), v)
+			if err != nil {
+				log.Warnf(cmd.Context(), "%v", err)
+				hash = v.KeysHash()
+			}
 			profile := &profileMetadata{
-				Name:        v.Name(),
-				Host:        hash["host"],
-				AccountID:   hash["account_id"],
-				WorkspaceID: hash["workspace_id"],
-				Default:     v.Name() == defaultProfile,
+				Name:                v.Name(),
+				Host:                hash["host"],
+				AccountID:           hash["account_id"],
+				WorkspaceID:         hash["workspace_id"],
+				ClusterID:           hash["cluster_id"],
+				WarehouseID:         hash["warehouse_id"],
+				ServerlessComputeID: hash["serverless_compute_id"],
+				Default:             v.Name() == defaultProfile,
+				LastUsed:            lastUsed[v.Name()],
 			}
 			if profile.IsEmpty() {
 				continue
@@ -144,10 +236,17 @@ func newProfilesCommand() *cobra.Command {
 			profiles = append(profiles, profile)
 		}
 		wg.Wait()
+		if sortBy == sortLastUsed {
+			slices.SortStableFunc(profiles, func(a, b *profileMetadata) int {
+				return b.LastUsed.Compare(a.LastUsed)
+			})
+		}
 		return cmdio.Render(cmd.Context(), struct {
 			Profiles []*profileMetadata `json:"profiles"`
 		}{profiles})
 	}
 
+	cmd.AddCommand(newProfilesDeleteCommand())
+	cmd.AddCommand(newProfilesRenameCommand())
 	return cmd
 }
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
+	"github.com/spf13/cobra"
+	"gopkg.in/ini.v1"
+)
+
+func newProfilesDeleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "delete PROFILE",
+		Short: "Delete a profile from ~/.databrickscfg",
+		Args:  cobra.ExactArgs(1),
+		Long: `Delete a profile from ~/.databrickscfg.
+
+The section of the profile is removed from the configuration file. Comments
+and the other profiles in the file are left as they are. If the profile was
+created with "databricks auth login", its cached OAuth tokens are deleted as
+well, unless --keep-tokens is specified.
+
+In an interactive terminal you'll be asked to confirm unless --auto-approve
+is set. In a non-interactive environment --auto-approve is required.
+
+The DEFAULT profile is only deleted if --force is also specified. Its keys are
+cleared, but the section itself is kept at the top of the file.`,
+		ValidArgsFunction: profile.ProfileCompletion,
+	}
+
+	var autoApprove bool
+	var force bool
+	var keepTokens bool
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&force, "force", false, "Allow deleting the DEFAULT profile")
+	cmd.Flags().BoolVar(&keepTokens, "keep-tokens", false, "Keep the cached OAuth tokens of the profile")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		tokenCache, err := cache.NewFileTokenCache()
+		if err != nil {
+			return fmt.Errorf("failed to open token cache, please check if the file version is up-to-date and that the file is not corrupted: %w", err)
+		}
+
+		return runProfilesDelete(ctx, profilesDeleteArgs{
+			profileName:    args[0],
+			autoApprove:    autoApprove,
+			force:          force,
+			keepTokens:     keepTokens,
+			profiler:       profile.DefaultProfiler,
+			tokenCache:     auth.RecordTokenTimes(ctx, tokenCache, nil, auth.TokenRefresh),
+			configFilePath: env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
+		})
+	}
+
+	return cmd
+}
+
+type profilesDeleteArgs struct {
+	profileName    string
+	autoApprove    bool
+	force          bool
+	keepTokens     bool
+	profiler       profile.Profiler
+	tokenCache     cache.TokenCache
+	configFilePath string
+}
+
+// runProfilesDelete deletes a profile like "auth logout --delete". The
+// DEFAULT profile is only deleted with --force.
+func runProfilesDelete(ctx context.Context, args profilesDeleteArgs) error {
+	matchedProfile, err := getMatchingProfile(ctx, args.profileName, args.profiler)
+	if err != nil {
+		return err
+	}
+	if matchedProfile.Name == ini.DefaultSection && !args.force {
+		return fmt.Errorf("profile %q is used when no profile is specified, please specify --force to delete it", ini.DefaultSection)
+	}
+
+	return runLogout(ctx, logoutArgs{
+		profileName:    matchedProfile.Name,
+		autoApprove:    args.autoApprove,
+		deleteProfile:  true,
+		keepTokens:     args.keepTokens,
+		profiler:       args.profiler,
+		tokenCache:     args.tokenCache,
+		configFilePath: args.configFilePath,
+	})
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"os"
+	"testing"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+	"gopkg.in/ini.v1"
+)
+
+func TestProfilesDelete(t *testing.T) {
+	cases := []struct {
+		name        string
+		profileName string
+		keepTokens  bool
+		wantRemoved []string
+		wantKept    []string
+	}{
+		{
+			name:        "login profile with unique host",
+			profileName: "my-unique-workspace",
+			wantRemoved: []string{"my-unique-workspace", "https://my-unique-workspace.cloud.databricks.com"},
+		},
+		{
+			name:        "login profile with shared host",
+			profileName: "my-workspace",
+			wantRemoved: []string{"my-workspace"},
+			wantKept:    []string{"https://my-workspace.cloud.databricks.com"},
+		},
+		{
+			name:        "account profile",
+			profileName: "my-account",
+			wantRemoved: []string{"my-account", "https://accounts.cloud.databricks.com/oidc/accounts/22222222-2222-2222-2222-222222222222"},
+		},
+		{
+			name:        "keep tokens",
+			profileName: "my-unique-workspace",
+			keepTokens:  true,
+			wantKept:    []string{"my-unique-workspace", "https://my-unique-workspace.cloud.databricks.com"},
+		},
+		{
+			name:        "m2m profile",
+			profileName: "my-m2m",
+			wantKept:    []string{"my-m2m", "https://my-m2m.cloud.databricks.com"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := cmdio.MockDiscard(t.Context())
+			configPath := writeTempConfig(t, logoutTestConfig)
+			t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
+
+			tokenCache := &inMemoryTokenCache{
+				Tokens: copyTokens(logoutTestTokensCacheConfig),
+			}
+
+			err := runProfilesDelete(ctx, profilesDeleteArgs{
+				profileName:    tc.profileName,
+				autoApprove:    true,
+				keepTokens:     tc.keepTokens,
+				profiler:       profile.DefaultProfiler,
+				tokenCache:     tokenCache,
+				configFilePath: configPath,
+			})
+			require.NoError(t, err)
+
+			profiles, err := profile.DefaultProfiler.LoadProfiles(ctx, profile.WithName(tc.profileName))
+			require.NoError(t, err)
+			assert.Empty(t, profiles)
+
+			for _, key := range tc.wantRemoved {
+				assert.Nil(t, tokenCache.Tokens[key], "expected token %q to be removed", key)
+			}
+			for _, key := range tc.wantKept {
+				assert.NotNil(t, tokenCache.Tokens[key], "expected token %q to be preserved", key)
+			}
+		})
+	}
+}
+
+func TestProfilesDeleteNotFound(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	configPath := writeTempConfig(t, logoutTestConfig)
+	t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
+
+	err := runProfilesDelete(ctx, profilesDeleteArgs{
+		profileName:    "nonexistent",
+		autoApprove:    true,
+		profiler:       profile.DefaultProfiler,
+		tokenCache:     &inMemoryTokenCache{Tokens: copyTokens(logoutTestTokensCacheConfig)},
+		configFilePath: configPath,
+	})
+	assert.ErrorContains(t, err, `profile "nonexistent" not found. Available profiles: my-workspace, shared-workspace`)
+
+	contents, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, logoutTestConfig, string(contents))
+}
+
+func TestProfilesDeleteRequiresAutoApprove(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	configPath := writeTempConfig(t, logoutTestConfig)
+	t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
+
+	err := runProfilesDelete(ctx, profilesDeleteArgs{
+		profileName:    "my-workspace",
+		profiler:       profile.DefaultProfiler,
+		tokenCache:     &inMemoryTokenCache{Tokens: copyTokens(logoutTestTokensCacheConfig)},
+		configFilePath: configPath,
+	})
+	assert.ErrorContains(t, err, "please specify --auto-approve to skip confirmation in non-interactive mode")
+}
+
+func TestProfilesDeleteDefault(t *testing.T) {
+	const configWithDefault = `[DEFAULT]
+host = https://default.cloud.databricks.com
+auth_type = databricks-cli
+
+[other]
+host = https://other.cloud.databricks.com
+`
+
+	ctx := cmdio.MockDiscard(t.Context())
+	configPath := writeTempConfig(t, configWithDefault)
+	t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
+
+	tokenCache := &inMemoryTokenCache{
+		Tokens: map[string]*oauth2.Token{
+			"DEFAULT":                              {AccessToken: "default-token"},
+			"https://default.cloud.databricks.com": {AccessToken: "default-host-token"},
+		},
+	}
+	args := profilesDeleteArgs{
+		profileName:    "DEFAULT",
+		autoApprove:    true,
+		profiler:       profile.DefaultProfiler,
+		tokenCache:     tokenCache,
+		configFilePath: configPath,
+	}
+
+	// Without --force, the DEFAULT profile is left untouched.
+	err := runProfilesDelete(ctx, args)
+	assert.ErrorContains(t, err, `profile "DEFAULT" is used when no profile is specified, please specify --force to delete it`)
+	contents, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, configWithDefault, string(contents))
+	assert.Len(t, tokenCache.Tokens, 2)
+
+	args.force = true
+	err = runProfilesDelete(ctx, args)
+	require.NoError(t, err)
+
+	file, err := config.LoadFile(configPath)
+	require.NoError(t, err)
+	assert.Empty(t, file.Section(ini.DefaultSection).Keys())
+	assert.True(t, file.HasSection("other"))
+	assert.Empty(t, tokenCache.Tokens)
+}
@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
+	"github.com/spf13/cobra"
+)
+
+func newProfilesRenameCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rename OLD NEW",
+		Short: "Rename a profile in ~/.databrickscfg",
+		Args:  cobra.ExactArgs(2),
+		Long: `Rename a profile in ~/.databrickscfg.
+
+The section of the profile is renamed in place, so that its position and
+comments in the configuration file are preserved. If the profile is the
+configured default profile, the default is renamed as well.
+
+Cached OAuth tokens of the profile are moved to the new name, so that you
+don't need to run "databricks auth login" again.
+
+The new name must not be used by another profile. The DEFAULT section stays
+at the top of the file: renaming it moves its keys to a new profile, and a
+profile can only be renamed to DEFAULT if the DEFAULT section is empty.`,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return profile.ProfileCompletion(cmd, args, toComplete)
+		},
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		tokenCache, err := cache.NewFileTokenCache()
+		if err != nil {
+			return fmt.Errorf("failed to open token cache, please check if the file version is up-to-date and that the file is not corrupted: %w", err)
+		}
+
+		return runProfilesRename(ctx, profilesRenameArgs{
+			oldName:        args[0],
+			newName:        args[1],
+			tokenCache:     auth.RecordTokenTimes(ctx, tokenCache, nil, auth.TokenRefresh),
+			configFilePath: env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
+		})
+	}
+
+	return cmd
+}
+
+type profilesRenameArgs struct {
+	oldName        string
+	newName        string
+	tokenCache     cache.TokenCache
+	configFilePath string
+}
+
+func runProfilesRename(ctx context.Context, args profilesRenameArgs) error {
+	err := databrickscfg.RenameProfile(ctx, args.oldName, args.newName, args.configFilePath)
+	if err != nil {
+		return err
+	}
+
+	// The token cache is keyed by the profile name for tokens obtained with
+	// "databricks auth login --profile". Host-keyed tokens don't depend on
+	// the name of the profile and are left as they are.
+	token, err := args.tokenCache.Lookup(args.oldName)
+	if errors.Is(err, cache.ErrNotFound) {
+		cmdio.LogString(ctx, fmt.Sprintf("Renamed profile %q to %q.", args.oldName, args.newName))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("profile renamed, but failed to read its cached token: %w", err)
+	}
+	if err := args.tokenCache.Store(args.newName, token); err != nil {
+		return fmt.Errorf("profile renamed, but failed to move its cached token: %w", err)
+	}
+	if err := args.tokenCache.Store(args.oldName, nil); err != nil {
+		return fmt.Errorf("profile renamed, but failed to delete its cached token under the old name: %w", err)
+	}
+
+	cmdio.LogString(ctx, fmt.Sprintf("Renamed profile %q to %q and moved its cached OAuth token.", args.oldName, args.newName))
+	return nil
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"os"
+	"testing"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfilesRename(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	configPath := writeTempConfig(t, logoutTestConfig)
+	t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
+
+	tokenCache := &inMemoryTokenCache{
+		Tokens: copyTokens(logoutTestTokensCacheConfig),
+	}
+
+	err := runProfilesRename(ctx, profilesRenameArgs{
+		oldName:        "my-unique-workspace",
+		newName:        "renamed",
+		tokenCache:     tokenCache,
+		configFilePath: configPath,
+	})
+	require.NoError(t, err)
+
+	profiles, err := profile.DefaultProfiler.LoadProfiles(ctx, profile.WithName("renamed"))
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "https://my-unique-workspace.cloud.databricks.com", profiles[0].Host)
+
+	// The profile-keyed token moves to the new name. The host-keyed token
+	// is unaffected.
+	assert.Nil(t, tokenCache.Tokens["my-unique-workspace"])
+	assert.Equal(t, "my-unique-workspace-token", tokenCache.Tokens["renamed"].AccessToken)
+	assert.Equal(t, "unique-workspace-host-token", tokenCache.Tokens["https://my-unique-workspace.cloud.databricks.com"].AccessToken)
+}
+
+func TestProfilesRenameWithoutToken(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	configPath := writeTempConfig(t, logoutTestConfig)
+	t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
+
+	tokenCache := &inMemoryTokenCache{
+		Tokens: copyTokens(logoutTestTokensCacheConfig),
+	}
+	delete(tokenCache.Tokens, "my-unique-workspace")
+
+	err := runProfilesRename(ctx, profilesRenameArgs{
+		oldName:        "my-unique-workspace",
+		newName:        "renamed",
+		tokenCache:     tokenCache,
+		configFilePath: configPath,
+	})
+	require.NoError(t, err)
+	assert.NotContains(t, tokenCache.Tokens, "renamed")
+}
+
+func TestProfilesRenameCollision(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	configPath := writeTempConfig(t, logoutTestConfig)
+	t.Setenv("DATABRICKS_CONFIG_FILE", configPath)
+
+	tokenCache := &inMemoryTokenCache{
+		Tokens: copyTokens(logoutTestTokensCacheConfig),
+	}
+
+	err := runProfilesRename(ctx, profilesRenameArgs{
+		oldName:        "my-workspace",
+		newName:        "my-unique-workspace",
+		tokenCache:     tokenCache,
+		configFilePath: configPath,
+	})
+	assert.ErrorContains(t, err, `profile "my-unique-workspace" already exists`)
+
+	// Neither the config file nor the token cache are modified.
+	contents, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	assert.Equal(t, logoutTestConfig, string(contents))
+	assert.Equal(t, logoutTestTokensCacheConfig, tokenCache.Tokens)
+}
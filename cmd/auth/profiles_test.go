@@ -2,14 +2,17 @@ package auth
 
 import (
 	"encoding/json"
+	"maps"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"testing"
 
 	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
 	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -293,3 +296,68 @@ func TestProfileLoadNoDiscoveryStaysWorkspace(t *testing.T) {
 	assert.NotEmpty(t, p.Host)
 	assert.Equal(t, "pat", p.AuthType)
 }
+
+func TestMatchingProfileNames(t *testing.T) {
+	profiler := profile.InMemoryProfiler{Profiles: profile.Profiles{
+		{Name: "azure-dev", Host: "https://adb-1.1.azuredatabricks.net"},
+		{Name: "azure-prod", Host: "https://adb-2.2.azuredatabricks.net/"},
+		{Name: "aws-dev", Host: "https://dev.cloud.databricks.com"},
+		{Name: "aws-account", Host: "https://accounts.cloud.databricks.com", AccountID: "abc"},
+		{Name: "azure-account", Host: "https://accounts.azuredatabricks.net", AccountID: "def"},
+		{Name: "spog-workspace", Host: "https://spog.cloud.databricks.com", AccountID: "abc", WorkspaceID: "123"},
+	}}
+
+	cases := []struct {
+		name   string
+		filter profileFilter
+		want   []string
+	}{
+		{
+			name:   "host pattern",
+			filter: profileFilter{hostPattern: "*.azuredatabricks.net"},
+			want:   []string{"azure-dev", "azure-prod", "azure-account"},
+		},
+		{
+			name:   "account ID",
+			filter: profileFilter{accountID: "abc"},
+			want:   []string{"aws-account", "spog-workspace"},
+		},
+		{
+			name:   "workspace only",
+			filter: profileFilter{workspaceOnly: true},
+			want:   []string{"azure-dev", "azure-prod", "aws-dev", "spog-workspace"},
+		},
+		{
+			name:   "account only",
+			filter: profileFilter{accountOnly: true},
+			want:   []string{"aws-account", "azure-account"},
+		},
+		{
+			name:   "host pattern and account only",
+			filter: profileFilter{hostPattern: "https://*.azuredatabricks.net/", accountOnly: true},
+			want:   []string{"azure-account"},
+		},
+		{
+			name:   "account ID and workspace only",
+			filter: profileFilter{accountID: "abc", workspaceOnly: true},
+			want:   []string{"spog-workspace"},
+		},
+		{
+			name:   "no match",
+			filter: profileFilter{hostPattern: "*.gcp.databricks.com"},
+			want:   []string{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			names, err := matchingProfileNames(t.Context(), profiler, c.filter)
+			require.NoError(t, err)
+			assert.ElementsMatch(t, c.want, slices.Collect(maps.Keys(names)))
+		})
+	}
+}
+
+func TestMatchingProfileNamesInvalidPattern(t *testing.T) {
+	_, err := matchingProfileNames(t.Context(), profile.InMemoryProfiler{}, profileFilter{hostPattern: "[abc"})
+	assert.ErrorContains(t, err, "invalid host pattern")
+}
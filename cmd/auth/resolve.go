@@ -4,15 +4,25 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/cli/libs/databrickscfg/profile"
 	"github.com/databricks/databricks-sdk-go/config"
 )
 
-var errNoProfileFound = errors.New("no matching profile found")
+var (
+	errNoProfileFound = errors.New("no matching profile found")
+	errMalformedHost  = errors.New("malformed host")
+)
+
+// malformedSchemeRe matches an http(s) scheme followed by a separator other
+// than "://", e.g. "http//", "https:/", "https:" or "https:///".
+var malformedSchemeRe = regexp.MustCompile(`^(?i)(https?)(?::/?|//?|:///+)([^/].*)$`)
 
 // looksLikeHost returns true if the argument looks like a host URL rather than
 // a profile name. Profile names are short identifiers (e.g., "logfood",
@@ -41,16 +51,70 @@ func resolvePositionalArg(ctx context.Context, arg string, profiler profile.Prof
 		return "", "", err
 	}
 	if candidateProfile != nil {
-		return arg, "", nil
+		return candidateProfile.Name, "", nil
 	}
 
 	if looksLikeHost(arg) {
+		if err := checkHostArg(arg); err != nil {
+			return "", "", err
+		}
 		return "", arg, nil
 	}
 
 	return "", "", fmt.Errorf("%w: %q", errNoProfileFound, arg)
 }
 
+// checkHostArg catches common typos in a host passed as a positional
+// argument, such as a malformed scheme or an empty domain label. Without this
+// check the mangled host is only rejected later with an unrelated OAuth error.
+// Scheme-less hosts, host:port pairs and single-label hosts such as
+// "https://dev" on internal DNS are accepted.
+func checkHostArg(arg string) error {
+	if strings.IndexFunc(arg, unicode.IsSpace) >= 0 {
+		return malformedHostError(arg, "it contains whitespace", strings.Join(strings.Fields(arg), ""))
+	}
+
+	if m := malformedSchemeRe.FindStringSubmatch(arg); m != nil {
+		return malformedHostError(arg, "the scheme must be followed by \"://\"", strings.ToLower(m[1])+"://"+m[2])
+	}
+
+	rest := arg
+	if scheme, after, ok := strings.Cut(arg, "://"); ok {
+		if s := strings.ToLower(scheme); s != "http" && s != "https" {
+			return malformedHostError(arg, fmt.Sprintf("scheme %q is not supported", scheme), "https://"+after)
+		}
+		rest = after
+	}
+
+	hostname, _, _ := strings.Cut(rest, "/")
+	if strings.HasPrefix(hostname, "[") {
+		// Leave IPv6 literals to the URL parser.
+		return nil
+	}
+	hostname, _, _ = strings.Cut(hostname, ":")
+	if hostname == "" {
+		return malformedHostError(arg, "the hostname is empty", "")
+	}
+
+	labels := strings.Split(hostname, ".")
+	if slices.Contains(labels, "") {
+		fixed := strings.Join(slices.DeleteFunc(labels, func(l string) bool { return l == "" }), ".")
+		suggestion := ""
+		if strings.Contains(fixed, ".") {
+			suggestion = strings.Replace(arg, hostname, fixed, 1)
+		}
+		return malformedHostError(arg, "the hostname contains an empty domain label", suggestion)
+	}
+	return nil
+}
+
+func malformedHostError(arg, problem, suggestion string) error {
+	if suggestion == "" {
+		return fmt.Errorf("%w %q: %s", errMalformedHost, arg, problem)
+	}
+	return fmt.Errorf("%w %q: %s. Did you mean %q?", errMalformedHost, arg, problem, suggestion)
+}
+
 // resolveHostToProfile resolves a host URL to a profile name. If multiple
 // profiles match the host, it prompts the user to select one (or errors in
 // non-interactive mode). If no profiles match, it returns an error.
@@ -98,6 +98,84 @@ func TestResolvePositionalArg(t *testing.T) {
 	}
 }
 
+func TestCheckHostArg(t *testing.T) {
+	cases := []struct {
+		arg     string
+		wantErr string
+	}{
+		// Valid hosts, including all host inputs from the existing tests.
+		{arg: "https://other.cloud.databricks.com"},
+		{arg: "my-workspace.cloud.databricks.com"},
+		{arg: "workspace-a.cloud.databricks.com"},
+		{arg: "nonexistent.cloud.databricks.com"},
+		{arg: "https://accounts.cloud.databricks.com/"},
+		{arg: "http://localhost:8080"},
+		{arg: "localhost:8080"},
+		{arg: "HTTPS://dev.cloud.databricks.com"},
+		{arg: "httpbin.example.com"},
+		{arg: "http://[::1]:8080"},
+		{arg: "https://dev"},
+		{arg: "http://workspace/"},
+
+		// Malformed hosts.
+		{
+			arg:     "http//workspace.cloud.databricks.com",
+			wantErr: `malformed host "http//workspace.cloud.databricks.com": the scheme must be followed by "://". Did you mean "http://workspace.cloud.databricks.com"?`,
+		},
+		{
+			arg:     "https:/workspace.cloud.databricks.com",
+			wantErr: `malformed host "https:/workspace.cloud.databricks.com": the scheme must be followed by "://". Did you mean "https://workspace.cloud.databricks.com"?`,
+		},
+		{
+			arg:     "https:workspace.cloud.databricks.com",
+			wantErr: `malformed host "https:workspace.cloud.databricks.com": the scheme must be followed by "://". Did you mean "https://workspace.cloud.databricks.com"?`,
+		},
+		{
+			arg:     "https:///workspace.cloud.databricks.com",
+			wantErr: `malformed host "https:///workspace.cloud.databricks.com": the scheme must be followed by "://". Did you mean "https://workspace.cloud.databricks.com"?`,
+		},
+		{
+			arg:     "htps://workspace.cloud.databricks.com",
+			wantErr: `malformed host "htps://workspace.cloud.databricks.com": scheme "htps" is not supported. Did you mean "https://workspace.cloud.databricks.com"?`,
+		},
+		{
+			arg:     "https://workspace.cloud .databricks.com",
+			wantErr: `malformed host "https://workspace.cloud .databricks.com": it contains whitespace. Did you mean "https://workspace.cloud.databricks.com"?`,
+		},
+		{
+			arg:     "https://workspace..cloud.databricks.com",
+			wantErr: `malformed host "https://workspace..cloud.databricks.com": the hostname contains an empty domain label. Did you mean "https://workspace.cloud.databricks.com"?`,
+		},
+		{
+			arg:     "https://workspace.",
+			wantErr: `malformed host "https://workspace.": the hostname contains an empty domain label`,
+		},
+		{
+			arg:     "https://",
+			wantErr: `malformed host "https://": the hostname is empty`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.arg, func(t *testing.T) {
+			err := checkHostArg(tc.arg)
+			if tc.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, errMalformedHost)
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestResolvePositionalArgMalformedHost(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	profiler := profile.InMemoryProfiler{}
+	_, _, err := resolvePositionalArg(ctx, "http//workspace.cloud.databricks.com", profiler)
+	assert.ErrorIs(t, err, errMalformedHost)
+}
+
 func TestResolveHostToProfileMatchesOneProfile(t *testing.T) {
 	ctx := cmdio.MockDiscard(t.Context())
 	profiler := profile.InMemoryProfiler{
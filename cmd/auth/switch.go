@@ -4,8 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"slices"
 
+	"github.com/databricks/cli/libs/auth"
 	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/cli/libs/databrickscfg"
 	"github.com/databricks/cli/libs/databrickscfg/profile"
@@ -57,9 +58,14 @@ to see which profile is currently the default.`,
 			if err != nil {
 				return err
 			}
-			if len(profiles) == 0 {
+			p, err := profiles.FindByName(ctx, profileName)
+			if err != nil {
+				return err
+			}
+			if p == nil {
 				return fmt.Errorf("profile %q not found", profileName)
 			}
+			profileName = p.Name
 		}
 
 		err := databrickscfg.SetDefaultProfile(ctx, profileName, configFile)
@@ -77,6 +83,10 @@ to see which profile is currently the default.`,
 // promptForSwitchProfile shows an interactive profile picker for the switch command.
 // Reuses profileSelectItem from token.go for consistent display.
 func promptForSwitchProfile(ctx context.Context, profiles profile.Profiles, currentDefault string) (string, error) {
+	// Recently used profiles float to the top.
+	profiles = slices.Clone(profiles)
+	profiles.SortByLastUsed(auth.ProfilesLastUsed(ctx))
+
 	items := make([]profileSelectItem, 0, len(profiles))
 	for _, p := range profiles {
 		items = append(items, profileSelectItem{Name: p.Name, Host: p.Host})
@@ -91,12 +101,7 @@ func promptForSwitchProfile(ctx context.Context, profiles profile.Profiles, curr
 		Label:             label,
 		Items:             items,
 		StartInSearchMode: len(profiles) > 5,
-		Searcher: func(input string, index int) bool {
-			input = strings.ToLower(input)
-			name := strings.ToLower(items[index].Name)
-			host := strings.ToLower(items[index].Host)
-			return strings.Contains(name, input) || strings.Contains(host, input)
-		},
+		Searcher:          profiles.SearchCaseInsensitive,
 		Templates: &promptui.SelectTemplates{
 			Label:    "{{ . | faint }}",
 			Active:   `{{.Name | bold}}{{if .Host}} ({{.Host|faint}}){{end}}`,
@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"slices"
 	"strings"
 	"time"
 
@@ -24,8 +26,8 @@ import (
 	"golang.org/x/oauth2"
 )
 
-func helpfulError(ctx context.Context, profile string, persistentAuth u2m.OAuthArgument) string {
-	loginMsg := auth.BuildLoginCommand(ctx, profile, persistentAuth)
+func helpfulError(ctx context.Context, profile string, persistentAuth u2m.OAuthArgument, opts *auth.LoginCommandOptions) string {
+	loginMsg := auth.BuildLoginCommand(ctx, profile, persistentAuth, opts)
 	return fmt.Sprintf("Try logging in again with `%s` before retrying. If this fails, please report this issue to the Databricks CLI maintainers at https://github.com/databricks/cli/issues/new", loginMsg)
 }
 
@@ -60,7 +62,24 @@ func newTokenCommand(authArguments *auth.AuthArguments) *cobra.Command {
 Refresh the access token if it is expired or close to expiry. Use --force-refresh
 to bypass expiry checks. Note: This command only works with U2M authentication
 (using the 'databricks auth login' command). M2M authentication using a client ID
-and secret is not supported.`,
+and secret is not supported.
+
+Use --quiet to print only the access token, for example in scripts that build
+an Authorization header. With --quiet, interactive prompts are disabled and any
+other messages are written to stderr. --quiet cannot be combined with --output.
+
+Use --output kube-exec to print the token as a Kubernetes ExecCredential. This
+allows the CLI to be used as a credential plugin in the users.exec block of a
+kubeconfig:
+
+  users:
+  - name: databricks
+    user:
+      exec:
+        apiVersion: client.authentication.k8s.io/v1
+        command: databricks
+        args: ["auth", "token", "--profile", "<profile>", "--output", "kube-exec"]
+        interactiveMode: Never`,
 	}
 
 	var tokenTimeout time.Duration
@@ -71,34 +90,150 @@ and secret is not supported.`,
 	cmd.Flags().BoolVar(&forceRefresh, "force-refresh", false,
 		"Force a token refresh even if the cached token is still valid.")
 
-	cmd.PreRunE = profileHostConflictCheck
+	var offline bool
+	cmd.Flags().BoolVar(&offline, "offline", false,
+		"Return the cached token without refreshing it. Fails if the cached token has expired.")
+
+	var quiet bool
+	cmd.Flags().BoolVarP(&quiet, "quiet", "q", false,
+		"Print only the access token. Interactive prompts are disabled and other messages go to stderr.")
+
+	// This shadows the global --output flag to add the kube-exec output type.
+	output := tokenOutput(flags.OutputJSON)
+	cmd.Flags().VarP(&output, "output", "o", "output type: text, json or kube-exec")
+	_ = root.RegisterEnumCompletion(cmd, "output", []string{string(flags.OutputJSON), string(flags.OutputText), string(tokenOutputKubeExec)})
+
+	cmd.PreRunE = validateAuthFlags
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		profileName := cmd.Flag("profile").Value.String()
 
+		if offline && forceRefresh {
+			return errors.New("--force-refresh cannot be combined with --offline")
+		}
+		if quiet && cmd.Flag("output").Changed {
+			return errors.New("--quiet cannot be combined with --output")
+		}
+		if quiet {
+			ctx = quietContext(ctx, cmd.ErrOrStderr())
+		}
+
 		t, err := loadToken(ctx, loadTokenArgs{
 			authArguments:      authArguments,
 			profileName:        profileName,
 			args:               args,
 			tokenTimeout:       tokenTimeout,
 			forceRefresh:       forceRefresh,
+			offline:            offline,
 			profiler:           profile.DefaultProfiler,
 			persistentAuthOpts: nil,
 		})
 		if err != nil {
 			return err
 		}
-		// Only honor the explicit --output text flag, not implicit text mode
-		// (e.g. from DATABRICKS_OUTPUT_FORMAT). auth token defaults to JSON,
-		// and changing that implicitly would break scripts that parse JSON output.
-		textMode := cmd.Flag("output").Changed && root.OutputType(cmd) == flags.OutputText
+		if quiet {
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), t.AccessToken)
+			return err
+		}
+		if output == tokenOutputKubeExec {
+			return writeKubeExecCredential(cmd.OutOrStdout(), t, time.Now())
+		}
+		// The shadowing flag only honors the explicit --output text flag, not
+		// implicit text mode (e.g. from DATABRICKS_OUTPUT_FORMAT). auth token
+		// defaults to JSON, and changing that implicitly would break scripts
+		// that parse JSON output.
+		textMode := output == tokenOutput(flags.OutputText)
 		return writeTokenOutput(cmd.OutOrStdout(), t, textMode)
 	}
 
 	return cmd
 }
 
+// tokenOutput is the value of the --output flag of the token command. It
+// accepts the global output types and the Kubernetes ExecCredential format.
+type tokenOutput string
+
+const tokenOutputKubeExec tokenOutput = "kube-exec"
+
+func (o *tokenOutput) String() string {
+	return string(*o)
+}
+
+func (o *tokenOutput) Set(s string) error {
+	lower := tokenOutput(strings.ToLower(s))
+	switch lower {
+	case tokenOutput(flags.OutputText), tokenOutput(flags.OutputJSON), tokenOutputKubeExec:
+		*o = lower
+	default:
+		return errors.New("accepted arguments are json, text and kube-exec")
+	}
+	return nil
+}
+
+func (o *tokenOutput) Type() string {
+	return "type"
+}
+
+// kubeExecExpiryMargin is subtracted from the token expiry when reporting
+// the expiration to Kubernetes, so that kubectl requests a new token before
+// the current one expires in flight.
+const kubeExecExpiryMargin = 5 * time.Minute
+
+// execCredential is the client.authentication.k8s.io/v1 ExecCredential
+// object read by kubectl from the output of an exec credential plugin.
+type execCredential struct {
+	APIVersion string               `json:"apiVersion"`
+	Kind       string               `json:"kind"`
+	Status     execCredentialStatus `json:"status"`
+}
+
+type execCredentialStatus struct {
+	Token               string `json:"token"`
+	ExpirationTimestamp string `json:"expirationTimestamp,omitempty"`
+}
+
+// kubeExecExpiration returns the expiration to report for a token that
+// expires at expiry. The margin is capped at half of the remaining lifetime,
+// so a token close to expiry is still reported as valid for a short while
+// instead of as already expired, which would make kubectl call the plugin
+// for every request.
+func kubeExecExpiration(expiry, now time.Time) time.Time {
+	margin := kubeExecExpiryMargin
+	if remaining := expiry.Sub(now); remaining < 2*margin {
+		margin = max(remaining/2, 0)
+	}
+	return expiry.Add(-margin)
+}
+
+func writeKubeExecCredential(w io.Writer, t *oauth2.Token, now time.Time) error {
+	cred := execCredential{
+		APIVersion: "client.authentication.k8s.io/v1",
+		Kind:       "ExecCredential",
+		Status: execCredentialStatus{
+			Token: t.AccessToken,
+		},
+	}
+	if !t.Expiry.IsZero() {
+		cred.Status.ExpirationTimestamp = kubeExecExpiration(t.Expiry, now).UTC().Format(time.RFC3339)
+	}
+
+	raw, err := json.MarshalIndent(cred, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, string(raw))
+	return err
+}
+
+// quietContext returns a context whose cmdio discards stdout and cannot prompt.
+// Scripts consume the output of `auth token --quiet` verbatim, so nothing but
+// the token may reach stdout and a prompt would block or corrupt the output.
+// Messages that would otherwise be logged are written to stderr.
+func quietContext(ctx context.Context, stderr io.Writer) context.Context {
+	return cmdio.InContext(ctx, cmdio.NewIO(ctx, flags.OutputText, nil, io.Discard, stderr, "", ""))
+}
+
 func writeTokenOutput(w io.Writer, t *oauth2.Token, textMode bool) error {
 	if textMode {
 		_, err := fmt.Fprintln(w, t.AccessToken)
@@ -129,6 +264,12 @@ type loadTokenArgs struct {
 	// forceRefresh forces a token refresh even if the cached token is still valid.
 	forceRefresh bool
 
+	// offline returns the cached token without refreshing it. No network requests are made.
+	offline bool
+
+	// tokenCache is the token cache used in offline mode. If nil, the default file-based cache is used.
+	tokenCache cache.TokenCache
+
 	// profiler is the profiler to use for reading the host and account ID from the .databrickscfg file.
 	profiler profile.Profiler
 
@@ -176,6 +317,10 @@ func loadToken(ctx context.Context, args loadTokenArgs) (*oauth2.Token, error) {
 	if err != nil {
 		return nil, err
 	}
+	if existingProfile != nil {
+		// Tokens are cached under the exact profile name.
+		args.profileName = existingProfile.Name
+	}
 
 	applyUnifiedHostFlags(existingProfile, args.authArguments)
 
@@ -227,7 +372,7 @@ func loadToken(ctx context.Context, args loadTokenArgs) (*oauth2.Token, error) {
 				panic("configPath is empty but LoadProfiles returned multiple profiles")
 			}
 			if !cmdio.IsPromptSupported(ctx) {
-				names := strings.Join(matchingProfiles.Names(), " and ")
+				names := strings.Join(matchingProfiles.NamesWithPaths(), " and ")
 				return nil, fmt.Errorf("%s match %s in %s. Use --profile to specify which profile to use",
 					names, args.authArguments.Host, configPath)
 			}
@@ -266,16 +411,44 @@ func loadToken(ctx context.Context, args loadTokenArgs) (*oauth2.Token, error) {
 
 	args.authArguments.Profile = args.profileName
 
+	// The suggested login commands preserve the scopes of the profile and
+	// the config file it was read from.
+	loginOpts := &auth.LoginCommandOptions{
+		ConfigFile: env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
+	}
+	if existingProfile != nil {
+		loginOpts.Scopes = splitScopes(existingProfile.Scopes)
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, args.tokenTimeout)
 	defer cancel()
 	oauthArgument, err := args.authArguments.ToOAuthArgument()
 	if err != nil {
 		return nil, err
 	}
-	allArgs := append(args.persistentAuthOpts, u2m.WithOAuthArgument(oauthArgument))
+	// The retrying HTTP client and the timestamp-recording token cache go
+	// first so that options passed by the caller (e.g. an HTTP client or a
+	// token cache in tests) take precedence.
+	allArgs := []u2m.PersistentAuthOption{
+		u2m.WithHttpClient(auth.NewRefreshHTTPClient()),
+		auth.WithTokenTimes(ctx, auth.TokenRefresh),
+	}
+	allArgs = append(allArgs, args.persistentAuthOpts...)
+	allArgs = append(allArgs, u2m.WithOAuthArgument(oauthArgument))
+	var offlineCache *recordingTokenCache
+	if args.offline {
+		offlineCache, err = newRecordingTokenCache(args.tokenCache)
+		if err != nil {
+			return nil, err
+		}
+		allArgs = append(allArgs,
+			u2m.WithTokenCache(offlineCache),
+			u2m.WithHttpClient(&http.Client{Transport: offlineTransport{}}),
+			u2m.WithOAuthEndpointSupplier(offlineEndpointSupplier{}))
+	}
 	persistentAuth, err := u2m.NewPersistentAuth(ctx, allArgs...)
 	if err != nil {
-		helpMsg := helpfulError(ctx, args.profileName, oauthArgument)
+		helpMsg := helpfulError(ctx, args.profileName, oauthArgument, loginOpts)
 		return nil, fmt.Errorf("%w. %s", err, helpMsg)
 	}
 	var t *oauth2.Token
@@ -285,6 +458,10 @@ func loadToken(ctx context.Context, args loadTokenArgs) (*oauth2.Token, error) {
 		t, err = persistentAuth.Token()
 	}
 	if err != nil {
+		if errors.Is(err, errOffline) && offlineCache.last != nil {
+			return nil, fmt.Errorf("cached token expired at %s; run `%s` when online",
+				offlineCache.last.Expiry.Format(time.RFC3339), auth.BuildLoginCommand(ctx, args.profileName, oauthArgument, loginOpts))
+		}
 		if errors.Is(err, cache.ErrNotFound) {
 			// The error returned by the SDK when the token cache doesn't exist or doesn't contain a token
 			// for the given host changed in SDK v0.77.0: https://github.com/databricks/databricks-sdk-go/pull/1250.
@@ -297,15 +474,72 @@ func loadToken(ctx context.Context, args loadTokenArgs) (*oauth2.Token, error) {
 			// This is captured in an acceptance test under "cmd/auth/token".
 			err = errors.New("cache: databricks OAuth is not configured for this host")
 		}
-		if rewritten, rewrittenErr := auth.RewriteAuthError(ctx, args.authArguments.Host, args.authArguments.AccountID, args.profileName, err); rewritten {
+		if rewritten, rewrittenErr := auth.RewriteAuthError(ctx, args.authArguments.Host, args.authArguments.AccountID, args.profileName, loginOpts, err); rewritten {
 			return nil, rewrittenErr
 		}
-		helpMsg := helpfulError(ctx, args.profileName, oauthArgument)
-		return nil, fmt.Errorf("%w. %s", err, helpMsg)
+		helpMsg := helpfulError(ctx, args.profileName, oauthArgument, loginOpts)
+		return nil, auth.DiagnoseHostError(ctx, args.authArguments.Host, fmt.Errorf("%w. %s", err, helpMsg))
 	}
 	return t, nil
 }
 
+// errOffline is returned by [offlineTransport] for every request.
+var errOffline = errors.New("network access is disabled in offline mode")
+
+// offlineTransport fails all requests, guaranteeing that offline mode does
+// not touch the network.
+type offlineTransport struct{}
+
+func (offlineTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errOffline
+}
+
+// offlineEndpointSupplier fails all OAuth endpoint lookups. The default
+// supplier fetches the endpoints over the network before a refresh.
+type offlineEndpointSupplier struct{}
+
+func (offlineEndpointSupplier) GetAccountOAuthEndpoints(context.Context, string, string) (*u2m.OAuthAuthorizationServer, error) {
+	return nil, errOffline
+}
+
+func (offlineEndpointSupplier) GetWorkspaceOAuthEndpoints(context.Context, string) (*u2m.OAuthAuthorizationServer, error) {
+	return nil, errOffline
+}
+
+func (offlineEndpointSupplier) GetUnifiedOAuthEndpoints(context.Context, string, string) (*u2m.OAuthAuthorizationServer, error) {
+	return nil, errOffline
+}
+
+func (offlineEndpointSupplier) GetEndpointsFromURL(context.Context, string) (*u2m.OAuthAuthorizationServer, error) {
+	return nil, errOffline
+}
+
+// recordingTokenCache remembers the last token found in the cache, so that
+// offline mode can report when the cached token expired.
+type recordingTokenCache struct {
+	cache.TokenCache
+	last *oauth2.Token
+}
+
+func newRecordingTokenCache(c cache.TokenCache) (*recordingTokenCache, error) {
+	if c == nil {
+		var err error
+		c, err = cache.NewFileTokenCache()
+		if err != nil {
+			return nil, fmt.Errorf("cache: %w", err)
+		}
+	}
+	return &recordingTokenCache{TokenCache: c}, nil
+}
+
+func (c *recordingTokenCache) Lookup(key string) (*oauth2.Token, error) {
+	t, err := c.TokenCache.Lookup(key)
+	if err == nil {
+		c.last = t
+	}
+	return t, err
+}
+
 // resolveNoArgsToken resolves a profile or host when `auth token` is invoked
 // with no explicit profile, host, or positional arguments. It checks environment
 // variables first, then falls back to interactive profile selection or a clear
@@ -335,6 +569,9 @@ func resolveNoArgsToken(ctx context.Context, profiler profile.Profiler, authArgs
 		if err != nil {
 			return "", nil, err
 		}
+		if p != nil {
+			return p.Name, p, nil
+		}
 		return envProfile, p, nil
 	}
 
@@ -383,6 +620,10 @@ type profileSelectItem struct {
 // profiles plus "Enter a host URL" and "Create a new profile" options.
 // Returns the selection type and, when a profile is selected, its name.
 func promptForProfileSelection(ctx context.Context, profiles profile.Profiles) (profileSelectionResult, string, error) {
+	// Recently used profiles float to the top.
+	profiles = slices.Clone(profiles)
+	profiles.SortByLastUsed(auth.ProfilesLastUsed(ctx))
+
 	items := make([]profileSelectItem, 0, len(profiles)+2)
 	for _, p := range profiles {
 		items = append(items, profileSelectItem{Name: p.Name, Host: p.Host})
@@ -397,10 +638,10 @@ func promptForProfileSelection(ctx context.Context, profiles profile.Profiles) (
 		Items:             items,
 		StartInSearchMode: len(profiles) > 5,
 		Searcher: func(input string, index int) bool {
-			input = strings.ToLower(input)
-			name := strings.ToLower(items[index].Name)
-			host := strings.ToLower(items[index].Host)
-			return strings.Contains(name, input) || strings.Contains(host, input)
+			if index < len(profiles) {
+				return profiles.SearchCaseInsensitive(input, index)
+			}
+			return strings.Contains(strings.ToLower(items[index].Name), strings.ToLower(input))
 		},
 		Templates: &promptui.SelectTemplates{
 			Label:    "{{ . | faint }}",
@@ -436,6 +677,9 @@ func runInlineLogin(ctx context.Context, profiler profile.Profiler) (string, *pr
 	if err != nil {
 		return "", nil, err
 	}
+	if existingProfile != nil {
+		profileName = existingProfile.Name
+	}
 
 	loginArgs := &auth.AuthArguments{}
 	applyUnifiedHostFlags(existingProfile, loginArgs)
@@ -460,7 +704,7 @@ func runInlineLogin(ctx context.Context, profiler profile.Profiler) (string, *pr
 	}
 	persistentAuthOpts := []u2m.PersistentAuthOption{
 		u2m.WithOAuthArgument(oauthArgument),
-		u2m.WithBrowser(openURLSuppressingStderr),
+		u2m.WithBrowser(auth.OpenURLSuppressingStderr),
 	}
 	if len(scopesList) > 0 {
 		persistentAuthOpts = append(persistentAuthOpts, u2m.WithScopes(scopesList))
@@ -16,6 +16,7 @@ import (
 	"github.com/databricks/databricks-sdk-go/credentials/u2m"
 	"github.com/databricks/databricks-sdk-go/httpclient/fixtures"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2"
 )
 
@@ -49,6 +50,15 @@ var refreshFailureOtherError = fixtures.HTTPFixture{
 	},
 }
 
+var refreshTransientFailure = fixtures.HTTPFixture{
+	MatchAny: true,
+	Status:   502,
+	Response: map[string]string{
+		"error":             "temporarily_unavailable",
+		"error_description": "Bad gateway",
+	},
+}
+
 var refreshSuccessTokenResponse = fixtures.HTTPFixture{
 	MatchAny: true,
 	Status:   200,
@@ -98,12 +108,12 @@ func TestToken_loadToken(t *testing.T) {
 			{
 				Name:      "expired",
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "expired",
+				AccountID: "11111111-1111-1111-1111-111111111111",
 			},
 			{
 				Name:      "active",
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "active",
+				AccountID: "22222222-2222-2222-2222-222222222222",
 			},
 			{
 				Name: "workspace-a",
@@ -120,12 +130,12 @@ func TestToken_loadToken(t *testing.T) {
 			{
 				Name:      "acct-dup1",
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "same-account",
+				AccountID: "33333333-3333-3333-3333-333333333333",
 			},
 			{
 				Name:      "acct-dup2",
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "same-account",
+				AccountID: "33333333-3333-3333-3333-333333333333",
 			},
 			{
 				Name: "default.dev",
@@ -148,14 +158,31 @@ func TestToken_loadToken(t *testing.T) {
 				Name: "valid-token",
 				Host: "https://valid-token.cloud.databricks.com",
 			},
+			{
+				Name: "transient",
+				Host: "https://transient.cloud.databricks.com",
+			},
+			{
+				Name:   "expired-scopes",
+				Host:   "https://expired-scopes.cloud.databricks.com",
+				Scopes: "sql, clusters",
+			},
+			{
+				Name: "Staging",
+				Host: "https://staging-a.cloud.databricks.com",
+			},
+			{
+				Name: "STAGING",
+				Host: "https://staging-b.cloud.databricks.com",
+			},
 		},
 	}
 	tokenCache := &inMemoryTokenCache{
 		Tokens: map[string]*oauth2.Token{
-			"https://accounts.cloud.databricks.com/oidc/accounts/expired": {
+			"https://accounts.cloud.databricks.com/oidc/accounts/11111111-1111-1111-1111-111111111111": {
 				RefreshToken: "expired",
 			},
-			"https://accounts.cloud.databricks.com/oidc/accounts/active": {
+			"https://accounts.cloud.databricks.com/oidc/accounts/22222222-2222-2222-2222-222222222222": {
 				RefreshToken: "active",
 				Expiry:       time.Now().Add(1 * time.Hour), // Hopefully unit tests don't take an hour to run
 			},
@@ -199,8 +226,15 @@ func TestToken_loadToken(t *testing.T) {
 				RefreshToken: "valid-token",
 				Expiry:       time.Now().Add(1 * time.Hour),
 			},
+			"transient": {
+				RefreshToken: "transient",
+			},
+			"expired-scopes": {
+				RefreshToken: "expired-scopes",
+			},
 		},
 	}
+	noSleep := func(context.Context, time.Duration) error { return nil }
 	validateToken := func(got *oauth2.Token) {
 		assert.Equal(t, "new-access-token", got.AccessToken)
 		assert.Equal(t, "Bearer", got.TokenType)
@@ -229,13 +263,30 @@ func TestToken_loadToken(t *testing.T) {
 			},
 			wantErr: `A new access token could not be retrieved because the refresh token is invalid. To reauthenticate, run the following command:
   $ databricks auth login --profile expired`,
+		},
+		{
+			name: "prints login message with the scopes of the profile on refresh failure",
+			args: loadTokenArgs{
+				authArguments: &auth.AuthArguments{},
+				profileName:   "expired-scopes",
+				args:          []string{},
+				tokenTimeout:  1 * time.Hour,
+				profiler:      profiler,
+				persistentAuthOpts: []u2m.PersistentAuthOption{
+					u2m.WithTokenCache(tokenCache),
+					u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+					u2m.WithHttpClient(&http.Client{Transport: fixtures.SliceTransport{refreshFailureTokenResponse}}),
+				},
+			},
+			wantErr: `A new access token could not be retrieved because the refresh token is invalid. To reauthenticate, run the following command:
+  $ databricks auth login --profile expired-scopes --scopes sql,clusters`,
 		},
 		{
 			name: "prints helpful login message on refresh failure when host is specified",
 			args: loadTokenArgs{
 				authArguments: &auth.AuthArguments{
 					Host:      "https://accounts.cloud.databricks.com",
-					AccountID: "expired",
+					AccountID: "11111111-1111-1111-1111-111111111111",
 				},
 				profileName:  "",
 				args:         []string{},
@@ -303,7 +354,7 @@ func TestToken_loadToken(t *testing.T) {
 		{
 			name: "succeeds with host",
 			args: loadTokenArgs{
-				authArguments: &auth.AuthArguments{Host: "https://accounts.cloud.databricks.com", AccountID: "active"},
+				authArguments: &auth.AuthArguments{Host: "https://accounts.cloud.databricks.com", AccountID: "22222222-2222-2222-2222-222222222222"},
 				profileName:   "",
 				args:          []string{},
 				tokenTimeout:  1 * time.Hour,
@@ -319,7 +370,7 @@ func TestToken_loadToken(t *testing.T) {
 		{
 			name: "host with trailing slash is stripped",
 			args: loadTokenArgs{
-				authArguments: &auth.AuthArguments{Host: "https://accounts.cloud.databricks.com/", AccountID: "active"},
+				authArguments: &auth.AuthArguments{Host: "https://accounts.cloud.databricks.com/", AccountID: "22222222-2222-2222-2222-222222222222"},
 				profileName:   "",
 				args:          []string{},
 				tokenTimeout:  1 * time.Hour,
@@ -413,7 +464,7 @@ func TestToken_loadToken(t *testing.T) {
 			args: loadTokenArgs{
 				authArguments: &auth.AuthArguments{
 					Host:      "accounts.cloud.databricks.com",
-					AccountID: "same-account",
+					AccountID: "33333333-3333-3333-3333-333333333333",
 				},
 				profileName:  "",
 				args:         []string{},
@@ -448,7 +499,7 @@ func TestToken_loadToken(t *testing.T) {
 			args: loadTokenArgs{
 				authArguments: &auth.AuthArguments{
 					Host:      "https://accounts.cloud.databricks.com",
-					AccountID: "active",
+					AccountID: "22222222-2222-2222-2222-222222222222",
 				},
 				profileName:  "",
 				args:         []string{},
@@ -467,7 +518,7 @@ func TestToken_loadToken(t *testing.T) {
 			args: loadTokenArgs{
 				authArguments: &auth.AuthArguments{
 					Host:      "https://accounts.cloud.databricks.com",
-					AccountID: "same-account",
+					AccountID: "33333333-3333-3333-3333-333333333333",
 				},
 				profileName:  "",
 				args:         []string{},
@@ -760,6 +811,53 @@ func TestToken_loadToken(t *testing.T) {
 				assert.Equal(t, "cached-access-token", got.AccessToken)
 			},
 		},
+		{
+			name: "profile name that differs in case resolves to the profile",
+			args: loadTokenArgs{
+				authArguments: &auth.AuthArguments{},
+				profileName:   "Valid-Token",
+				args:          []string{},
+				tokenTimeout:  1 * time.Hour,
+				profiler:      profiler,
+				persistentAuthOpts: []u2m.PersistentAuthOption{
+					u2m.WithTokenCache(tokenCache),
+					u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+					u2m.WithHttpClient(&http.Client{Transport: failOnCallTransport{}}),
+				},
+			},
+			validateToken: func(got *oauth2.Token) {
+				assert.Equal(t, "cached-access-token", got.AccessToken)
+			},
+		},
+		{
+			name: "positional arg that differs in case resolves to the profile",
+			args: loadTokenArgs{
+				authArguments: &auth.AuthArguments{},
+				profileName:   "",
+				args:          []string{"VALID-TOKEN"},
+				tokenTimeout:  1 * time.Hour,
+				profiler:      profiler,
+				persistentAuthOpts: []u2m.PersistentAuthOption{
+					u2m.WithTokenCache(tokenCache),
+					u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+					u2m.WithHttpClient(&http.Client{Transport: failOnCallTransport{}}),
+				},
+			},
+			validateToken: func(got *oauth2.Token) {
+				assert.Equal(t, "cached-access-token", got.AccessToken)
+			},
+		},
+		{
+			name: "positional arg that matches multiple profiles in different case",
+			args: loadTokenArgs{
+				authArguments: &auth.AuthArguments{},
+				profileName:   "",
+				args:          []string{"staging"},
+				tokenTimeout:  1 * time.Hour,
+				profiler:      profiler,
+			},
+			wantErr: `profile "staging" matches multiple profiles that differ in case only: Staging, STAGING. Use the exact profile name`,
+		},
 		{
 			name: "force refresh refreshes valid cached token",
 			args: loadTokenArgs{
@@ -795,6 +893,47 @@ func TestToken_loadToken(t *testing.T) {
 			wantErr: `A new access token could not be retrieved because the refresh token is invalid. To reauthenticate, run the following command:
   $ databricks auth login --profile valid-token`,
 		},
+		{
+			name: "surfaces transient refresh failure after retries",
+			args: loadTokenArgs{
+				authArguments: &auth.AuthArguments{},
+				profileName:   "transient",
+				args:          []string{},
+				tokenTimeout:  1 * time.Hour,
+				profiler:      profiler,
+				persistentAuthOpts: []u2m.PersistentAuthOption{
+					u2m.WithTokenCache(tokenCache),
+					u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+					u2m.WithHttpClient(&http.Client{Transport: auth.NewRefreshRetryTransport(fixtures.SliceTransport{
+						refreshTransientFailure,
+						refreshTransientFailure,
+						refreshTransientFailure,
+						refreshSuccessTokenResponse,
+					}, noSleep)}),
+				},
+			},
+			wantErr: "token refresh: Bad gateway (error code: temporarily_unavailable). Try logging in again with " +
+				"`databricks auth login --profile transient` before retrying. If this fails, please report this issue to the Databricks CLI maintainers at https://github.com/databricks/cli/issues/new",
+		},
+		{
+			name: "retries transient refresh failure",
+			args: loadTokenArgs{
+				authArguments: &auth.AuthArguments{},
+				profileName:   "transient",
+				args:          []string{},
+				tokenTimeout:  1 * time.Hour,
+				profiler:      profiler,
+				persistentAuthOpts: []u2m.PersistentAuthOption{
+					u2m.WithTokenCache(tokenCache),
+					u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+					u2m.WithHttpClient(&http.Client{Transport: auth.NewRefreshRetryTransport(fixtures.SliceTransport{
+						refreshTransientFailure,
+						refreshSuccessTokenResponse,
+					}, noSleep)}),
+				},
+			},
+			validateToken: validateToken,
+		},
 	}
 	for _, c := range cases {
 		t.Run(c.name, func(t *testing.T) {
@@ -813,6 +952,40 @@ func TestToken_loadToken(t *testing.T) {
 	}
 }
 
+func TestToken_loadTokenReauthRequired(t *testing.T) {
+	profiler := profile.InMemoryProfiler{
+		Profiles: profile.Profiles{
+			{Name: "expired", Host: "https://accounts.cloud.databricks.com", AccountID: "11111111-1111-1111-1111-111111111111"},
+		},
+	}
+	tokenCache := &inMemoryTokenCache{
+		Tokens: map[string]*oauth2.Token{
+			"expired": {RefreshToken: "expired"},
+		},
+	}
+
+	ctx := cmdio.MockDiscard(t.Context())
+	_, err := loadToken(ctx, loadTokenArgs{
+		authArguments: &auth.AuthArguments{},
+		profileName:   "expired",
+		args:          []string{},
+		tokenTimeout:  1 * time.Hour,
+		profiler:      profiler,
+		persistentAuthOpts: []u2m.PersistentAuthOption{
+			u2m.WithTokenCache(tokenCache),
+			u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+			u2m.WithHttpClient(&http.Client{Transport: fixtures.SliceTransport{refreshFailureTokenResponse}}),
+		},
+	})
+
+	var reauthErr *auth.ReauthRequiredError
+	require.ErrorAs(t, err, &reauthErr)
+	assert.Equal(t, "https://accounts.cloud.databricks.com", reauthErr.Host)
+	assert.Equal(t, "11111111-1111-1111-1111-111111111111", reauthErr.AccountID)
+	assert.Equal(t, "expired", reauthErr.Profile)
+	assert.Equal(t, "databricks auth login --profile expired", reauthErr.LoginCommand)
+}
+
 // errProfiler is a Profiler that always returns the configured error.
 type errProfiler struct {
 	err error
@@ -849,3 +1022,178 @@ func TestWriteTokenOutput(t *testing.T) {
 		assert.Equal(t, "my-access-token\n", buf.String())
 	})
 }
+
+func TestQuietContext(t *testing.T) {
+	ctx, _ := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+	assert.True(t, cmdio.IsPromptSupported(ctx))
+
+	var stderr bytes.Buffer
+	ctx = quietContext(ctx, &stderr)
+	assert.False(t, cmdio.IsPromptSupported(ctx))
+
+	cmdio.LogString(ctx, "Profile test was successfully saved")
+	assert.Equal(t, "Profile test was successfully saved\n", stderr.String())
+}
+
+func TestWriteKubeExecCredential(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	t.Run("with expiry", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := writeKubeExecCredential(&buf, &oauth2.Token{
+			AccessToken: "my-access-token",
+			Expiry:      now.Add(time.Hour),
+		}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, `{
+  "apiVersion": "client.authentication.k8s.io/v1",
+  "kind": "ExecCredential",
+  "status": {
+    "token": "my-access-token",
+    "expirationTimestamp": "2025-01-02T03:59:05Z"
+  }
+}
+`, buf.String())
+	})
+
+	t.Run("without expiry", func(t *testing.T) {
+		var buf bytes.Buffer
+		err := writeKubeExecCredential(&buf, &oauth2.Token{AccessToken: "my-access-token"}, now)
+		assert.NoError(t, err)
+		assert.Equal(t, `{
+  "apiVersion": "client.authentication.k8s.io/v1",
+  "kind": "ExecCredential",
+  "status": {
+    "token": "my-access-token"
+  }
+}
+`, buf.String())
+	})
+}
+
+func TestKubeExecExpiration(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		name      string
+		remaining time.Duration
+		want      time.Duration
+	}{
+		{name: "full margin", remaining: time.Hour, want: 55 * time.Minute},
+		{name: "exactly twice the margin", remaining: 10 * time.Minute, want: 5 * time.Minute},
+		{name: "near expiry", remaining: 4 * time.Minute, want: 2 * time.Minute},
+		{name: "almost expired", remaining: 10 * time.Second, want: 5 * time.Second},
+		{name: "expired", remaining: -time.Minute, want: -time.Minute},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := kubeExecExpiration(now.Add(tc.remaining), now)
+			assert.Equal(t, now.Add(tc.want), got)
+		})
+	}
+}
+
+func TestTokenOutputFlag(t *testing.T) {
+	var o tokenOutput
+	assert.NoError(t, o.Set("KUBE-EXEC"))
+	assert.Equal(t, tokenOutputKubeExec, o)
+	assert.NoError(t, o.Set("text"))
+	assert.Equal(t, tokenOutput("text"), o)
+	assert.EqualError(t, o.Set("yaml"), "accepted arguments are json, text and kube-exec")
+}
+
+// countingTransport counts the requests that reach it and replies with a
+// successful token response.
+type countingTransport struct {
+	calls int
+}
+
+func (c *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	c.calls++
+	return refreshSuccessTokenResponse.Reply(req)
+}
+
+func TestToken_loadTokenOffline(t *testing.T) {
+	expiry := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	profiler := profile.InMemoryProfiler{
+		Profiles: profile.Profiles{
+			{Name: "valid", Host: "https://valid.cloud.databricks.com"},
+			{Name: "near-expiry", Host: "https://near-expiry.cloud.databricks.com"},
+			{Name: "expired", Host: "https://expired.cloud.databricks.com"},
+			{Name: "missing", Host: "https://missing.cloud.databricks.com"},
+		},
+	}
+	tokenCache := &inMemoryTokenCache{
+		Tokens: map[string]*oauth2.Token{
+			"valid": {
+				AccessToken:  "valid-access-token",
+				RefreshToken: "valid",
+				Expiry:       time.Now().Add(time.Hour),
+			},
+			"near-expiry": {
+				AccessToken:  "near-expiry-access-token",
+				RefreshToken: "near-expiry",
+				Expiry:       time.Now().Add(time.Minute),
+			},
+			"expired": {
+				AccessToken:  "expired-access-token",
+				RefreshToken: "expired",
+				Expiry:       expiry,
+			},
+		},
+	}
+
+	cases := []struct {
+		name        string
+		profileName string
+		wantToken   string
+		wantErr     string
+	}{
+		{
+			name:        "returns unexpired cached token",
+			profileName: "valid",
+			wantToken:   "valid-access-token",
+		},
+		{
+			name:        "returns cached token close to expiry without refreshing",
+			profileName: "near-expiry",
+			wantToken:   "near-expiry-access-token",
+		},
+		{
+			name:        "fails for expired cached token",
+			profileName: "expired",
+			wantErr:     "cached token expired at 2025-01-02T03:04:05Z; run `databricks auth login --profile expired` when online",
+		},
+		{
+			name:        "fails without cached token",
+			profileName: "missing",
+			wantErr:     "cache: databricks OAuth is not configured for this host",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := cmdio.MockDiscard(t.Context())
+			transport := &countingTransport{}
+			got, err := loadToken(ctx, loadTokenArgs{
+				authArguments: &auth.AuthArguments{},
+				profileName:   c.profileName,
+				args:          []string{},
+				tokenTimeout:  1 * time.Hour,
+				offline:       true,
+				tokenCache:    tokenCache,
+				profiler:      profiler,
+				persistentAuthOpts: []u2m.PersistentAuthOption{
+					u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+					u2m.WithHttpClient(&http.Client{Transport: transport}),
+				},
+			})
+			if c.wantErr != "" {
+				assert.ErrorContains(t, err, c.wantErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, c.wantToken, got.AccessToken)
+			}
+			assert.Equal(t, 0, transport.calls, "no request may be made in offline mode")
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
+)
+
+const (
+	// warmCacheConcurrency is the maximum number of profiles refreshed at
+	// the same time.
+	warmCacheConcurrency = 4
+
+	// warmCacheProfileTimeout bounds the time spent refreshing one profile.
+	warmCacheProfileTimeout = 30 * time.Second
+)
+
+type warmCacheStatus string
+
+const (
+	warmCacheRefreshed warmCacheStatus = "refreshed"
+	warmCacheFailed    warmCacheStatus = "failed"
+	warmCacheSkipped   warmCacheStatus = "skipped"
+)
+
+type warmCacheResult struct {
+	Profile string          `json:"profile"`
+	Host    string          `json:"host,omitempty"`
+	Status  warmCacheStatus `json:"status"`
+	Reason  string          `json:"reason,omitempty"`
+}
+
+const warmCacheTemplate = `{{header "Profile"}}	{{header "Host"}}	{{header "Status"}}	{{header "Reason"}}
+{{range .Results}}{{.Profile | green}}	{{.Host | cyan}}	{{.Status}}	{{.Reason}}
+{{end}}`
+
+type warmCacheArgs struct {
+	// profiler is used to list the profiles to refresh.
+	profiler profile.Profiler
+
+	// tokenCache is the token cache to refresh. If nil, the default file-based cache is used
+	// and refresh times are recorded.
+	tokenCache cache.TokenCache
+
+	// concurrency is the maximum number of concurrent refreshes.
+	concurrency int
+
+	// profileTimeout is the timeout for refreshing a single profile.
+	profileTimeout time.Duration
+
+	// persistentAuthOpts are the options to pass to the persistent auth client.
+	persistentAuthOpts []u2m.PersistentAuthOption
+}
+
+// warmTokenCache refreshes the cached token of every profile that has one.
+// Profiles are refreshed concurrently and results are returned in the order
+// of the profiles in the config file.
+//
+// Only refresh tokens are used; this never starts a browser login. Profiles
+// without a cached login or with a non-OAuth auth type are skipped.
+func warmTokenCache(ctx context.Context, args warmCacheArgs) ([]warmCacheResult, error) {
+	profiles, err := args.profiler.LoadProfiles(ctx, profile.MatchAllProfiles)
+	if errors.Is(err, profile.ErrNoConfiguration) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokenCache := args.tokenCache
+	if tokenCache == nil {
+		tokenCache = auth.RecordTokenTimes(ctx, nil, nil, auth.TokenRefresh)
+	}
+
+	results := make([]warmCacheResult, len(profiles))
+	sem := make(chan struct{}, max(args.concurrency, 1))
+	var wg sync.WaitGroup
+	for i, p := range profiles {
+		results[i] = warmCacheResult{Profile: p.Name, Host: p.Host}
+		if reason := warmCacheSkipReason(p); reason != "" {
+			results[i].Status = warmCacheSkipped
+			results[i].Reason = reason
+			continue
+		}
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			t := time.Now()
+			results[i].Status, results[i].Reason = warmProfileToken(ctx, p, tokenCache, args)
+			log.Debugf(ctx, "Profile %q took %s to refresh", p.Name, time.Since(t))
+		})
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// warmCacheSkipReason returns why a profile cannot be refreshed, or an empty
+// string if it can.
+func warmCacheSkipReason(p profile.Profile) string {
+	switch {
+	case p.Host == "":
+		return "no host"
+	case p.HasClientCredentials:
+		return "uses M2M authentication"
+	case p.AuthType != "" && p.AuthType != authTypeDatabricksCLI:
+		return fmt.Sprintf("uses %s authentication", p.AuthType)
+	}
+	return ""
+}
+
+// warmProfileToken refreshes the cached token of a single profile.
+func warmProfileToken(ctx context.Context, p profile.Profile, tokenCache cache.TokenCache, args warmCacheArgs) (warmCacheStatus, string) {
+	ctx, cancel := context.WithTimeout(ctx, args.profileTimeout)
+	defer cancel()
+
+	authArguments := &auth.AuthArguments{
+		Host:          p.Host,
+		AccountID:     p.AccountID,
+		WorkspaceID:   p.WorkspaceID,
+		IsUnifiedHost: p.IsUnifiedHost,
+		Profile:       p.Name,
+	}
+	oauthArgument, err := authArguments.ToOAuthArgument()
+	if err != nil {
+		return warmCacheFailed, err.Error()
+	}
+
+	// The retrying HTTP client goes first so that options passed by the
+	// caller (e.g. an HTTP client in tests) take precedence.
+	opts := []u2m.PersistentAuthOption{u2m.WithHttpClient(auth.NewRefreshHTTPClient())}
+	opts = append(opts, args.persistentAuthOpts...)
+	opts = append(opts, u2m.WithTokenCache(tokenCache), u2m.WithOAuthArgument(oauthArgument))
+	persistentAuth, err := u2m.NewPersistentAuth(ctx, opts...)
+	if err != nil {
+		return warmCacheFailed, err.Error()
+	}
+	defer persistentAuth.Close()
+
+	_, err = persistentAuth.ForceRefreshToken()
+	switch {
+	case err == nil:
+		return warmCacheRefreshed, ""
+	case errors.Is(err, cache.ErrNotFound), errors.Is(err, u2m.ErrMissingRefreshToken):
+		return warmCacheSkipped, "no cached login"
+	case errors.Is(err, context.DeadlineExceeded):
+		return warmCacheFailed, fmt.Sprintf("timed out after %s", args.profileTimeout)
+	default:
+		return warmCacheFailed, err.Error()
+	}
+}
+
+// runWarmCache refreshes all profiles and renders a summary. It returns an
+// error if any profile failed to refresh.
+func runWarmCache(ctx context.Context, args warmCacheArgs) error {
+	results, err := warmTokenCache(ctx, args)
+	if err != nil {
+		return err
+	}
+
+	err = cmdio.RenderWithTemplate(ctx, struct {
+		Results []warmCacheResult `json:"results"`
+	}{results}, "", warmCacheTemplate)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for _, r := range results {
+		if r.Status == warmCacheFailed {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("failed to refresh %d of %d profiles", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+// warmCacheTransport rejects refresh tokens for hosts starting with
+// "invalid" and accepts all others.
+type warmCacheTransport struct{}
+
+func (warmCacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.HasPrefix(req.URL.Host, "invalid") {
+		return refreshFailureTokenResponse.Reply(req)
+	}
+	return refreshSuccessTokenResponse.Reply(req)
+}
+
+// blockingTransport blocks until the request context is done.
+type blockingTransport struct{}
+
+func (blockingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	<-req.Context().Done()
+	return nil, req.Context().Err()
+}
+
+func TestWarmTokenCache(t *testing.T) {
+	profiler := profile.InMemoryProfiler{
+		Profiles: profile.Profiles{
+			{Name: "refreshable", Host: "https://refreshable.cloud.databricks.com", AuthType: "databricks-cli"},
+			{Name: "account", Host: "https://accounts.cloud.databricks.com", AccountID: "11111111-1111-1111-1111-111111111111", AuthType: "databricks-cli"},
+			{Name: "invalid", Host: "https://invalid.cloud.databricks.com", AuthType: "databricks-cli"},
+			{Name: "absent", Host: "https://absent.cloud.databricks.com", AuthType: "databricks-cli"},
+			{Name: "no-refresh-token", Host: "https://no-refresh-token.cloud.databricks.com"},
+			{Name: "m2m", Host: "https://m2m.cloud.databricks.com", HasClientCredentials: true},
+			{Name: "pat", Host: "https://pat.cloud.databricks.com", AuthType: "pat"},
+			{Name: "no-host"},
+		},
+	}
+	tokenCache := &inMemoryTokenCache{
+		Tokens: map[string]*oauth2.Token{
+			"refreshable": {
+				AccessToken:  "old-access-token",
+				RefreshToken: "refreshable",
+				Expiry:       time.Now().Add(time.Hour),
+			},
+			"account": {
+				AccessToken:  "old-access-token",
+				RefreshToken: "account",
+			},
+			"invalid": {
+				AccessToken:  "old-access-token",
+				RefreshToken: "invalid",
+			},
+			"no-refresh-token": {
+				AccessToken: "old-access-token",
+			},
+		},
+	}
+
+	ctx := cmdio.MockDiscard(t.Context())
+	results, err := warmTokenCache(ctx, warmCacheArgs{
+		profiler:       profiler,
+		tokenCache:     tokenCache,
+		concurrency:    2,
+		profileTimeout: time.Minute,
+		persistentAuthOpts: []u2m.PersistentAuthOption{
+			u2m.WithHttpClient(&http.Client{Transport: warmCacheTransport{}}),
+			u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+		},
+	})
+	require.NoError(t, err)
+
+	// Results keep the order of the profiles.
+	require.Len(t, results, 8)
+	assert.Equal(t, warmCacheResult{Profile: "refreshable", Host: "https://refreshable.cloud.databricks.com", Status: warmCacheRefreshed}, results[0])
+	assert.Equal(t, warmCacheResult{Profile: "account", Host: "https://accounts.cloud.databricks.com", Status: warmCacheRefreshed}, results[1])
+	assert.Equal(t, "invalid", results[2].Profile)
+	assert.Equal(t, warmCacheFailed, results[2].Status)
+	assert.Contains(t, results[2].Reason, "Refresh token is invalid")
+	assert.Equal(t, warmCacheResult{Profile: "absent", Host: "https://absent.cloud.databricks.com", Status: warmCacheSkipped, Reason: "no cached login"}, results[3])
+	assert.Equal(t, warmCacheResult{Profile: "no-refresh-token", Host: "https://no-refresh-token.cloud.databricks.com", Status: warmCacheSkipped, Reason: "no cached login"}, results[4])
+	assert.Equal(t, warmCacheResult{Profile: "m2m", Host: "https://m2m.cloud.databricks.com", Status: warmCacheSkipped, Reason: "uses M2M authentication"}, results[5])
+	assert.Equal(t, warmCacheResult{Profile: "pat", Host: "https://pat.cloud.databricks.com", Status: warmCacheSkipped, Reason: "uses pat authentication"}, results[6])
+	assert.Equal(t, warmCacheResult{Profile: "no-host", Status: warmCacheSkipped, Reason: "no host"}, results[7])
+
+	// Refreshed tokens are written back to the cache.
+	assert.Equal(t, "new-access-token", tokenCache.Tokens["refreshable"].AccessToken)
+	assert.Equal(t, "new-access-token", tokenCache.Tokens["account"].AccessToken)
+	assert.Equal(t, "old-access-token", tokenCache.Tokens["invalid"].AccessToken)
+}
+
+func TestWarmTokenCache_ProfileTimeout(t *testing.T) {
+	profiler := profile.InMemoryProfiler{
+		Profiles: profile.Profiles{
+			{Name: "slow", Host: "https://slow.cloud.databricks.com"},
+		},
+	}
+	tokenCache := &inMemoryTokenCache{
+		Tokens: map[string]*oauth2.Token{
+			"slow": {RefreshToken: "slow"},
+		},
+	}
+
+	ctx := cmdio.MockDiscard(t.Context())
+	results, err := warmTokenCache(ctx, warmCacheArgs{
+		profiler:       profiler,
+		tokenCache:     tokenCache,
+		concurrency:    1,
+		profileTimeout: 10 * time.Millisecond,
+		persistentAuthOpts: []u2m.PersistentAuthOption{
+			u2m.WithHttpClient(&http.Client{Transport: blockingTransport{}}),
+			u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, warmCacheFailed, results[0].Status)
+	assert.Equal(t, "timed out after 10ms", results[0].Reason)
+}
+
+func TestWarmTokenCache_NoConfiguration(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	results, err := warmTokenCache(ctx, warmCacheArgs{
+		profiler:   errProfiler{err: profile.ErrNoConfiguration},
+		tokenCache: &inMemoryTokenCache{Tokens: map[string]*oauth2.Token{}},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestRunWarmCache_ReportsFailures(t *testing.T) {
+	profiler := profile.InMemoryProfiler{
+		Profiles: profile.Profiles{
+			{Name: "refreshable", Host: "https://refreshable.cloud.databricks.com"},
+			{Name: "invalid", Host: "https://invalid.cloud.databricks.com"},
+		},
+	}
+	tokenCache := &inMemoryTokenCache{
+		Tokens: map[string]*oauth2.Token{
+			"refreshable": {RefreshToken: "refreshable"},
+			"invalid":     {RefreshToken: "invalid"},
+		},
+	}
+
+	ctx := cmdio.MockDiscard(t.Context())
+	err := runWarmCache(ctx, warmCacheArgs{
+		profiler:       profiler,
+		tokenCache:     tokenCache,
+		concurrency:    warmCacheConcurrency,
+		profileTimeout: time.Minute,
+		persistentAuthOpts: []u2m.PersistentAuthOption{
+			u2m.WithHttpClient(&http.Client{Transport: warmCacheTransport{}}),
+			u2m.WithOAuthEndpointSupplier(&MockApiClient{}),
+		},
+	})
+	assert.EqualError(t, err, "failed to refresh 1 of 2 profiles")
+}
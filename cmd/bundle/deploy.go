@@ -3,9 +3,12 @@
 package bundle
 
 import (
+	"context"
+
 	"github.com/databricks/cli/bundle"
 	"github.com/databricks/cli/cmd/bundle/utils"
 	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/libs/auth"
 	"github.com/spf13/cobra"
 )
 
@@ -31,6 +34,7 @@ See https://docs.databricks.com/en/dev-tools/bundles/index.html for more informa
 	var autoApprove bool
 	var verbose bool
 	var readPlanPath string
+	var verifyWorkspace bool
 	cmd.Flags().BoolVar(&force, "force", false, "Force-override Git branch validation.")
 	cmd.Flags().BoolVar(&forceLock, "force-lock", false, "Force acquisition of deployment lock.")
 	cmd.Flags().BoolVar(&failOnActiveRuns, "fail-on-active-runs", false, "Fail if there are running jobs or pipelines in the deployment.")
@@ -40,6 +44,7 @@ See https://docs.databricks.com/en/dev-tools/bundles/index.html for more informa
 	cmd.Flags().MarkDeprecated("compute-id", "use --cluster-id instead")
 	cmd.Flags().BoolVar(&verbose, "verbose", false, "Enable verbose output.")
 	cmd.Flags().StringVar(&readPlanPath, "plan", "", "Path to a JSON plan file to apply instead of planning (direct engine only).")
+	cmd.Flags().BoolVar(&verifyWorkspace, "verify-workspace", false, "Verify that the workspace matches the workspace ID pinned to the profile before deploying.")
 	// Verbose flag currently only affects file sync output, it's used by the vscode extension
 	cmd.Flags().MarkHidden("verbose")
 
@@ -61,6 +66,16 @@ See https://docs.databricks.com/en/dev-tools/bundles/index.html for more informa
 					b.Config.Bundle.Deployment.FailOnActiveRuns = failOnActiveRuns
 				}
 			},
+			PostInitFunc: func(ctx context.Context, b *bundle.Bundle) error {
+				if !verifyWorkspace {
+					return nil
+				}
+				w, err := b.WorkspaceClientE()
+				if err != nil {
+					return err
+				}
+				return auth.VerifyProfileWorkspacePin(ctx, w, w.Config)
+			},
 			Verbose:      verbose,
 			AlwaysPull:   true,
 			FastValidate: true,
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"os"
 	"strings"
 
 	"github.com/databricks/cli/cmd/psql"
@@ -108,6 +109,16 @@ func New(ctx context.Context) *cobra.Command {
 	cli.AddCommand(selftest.New())
 	cli.AddCommand(ssh.New())
 
+	// Registering the completions walks the whole command tree, so it is only
+	// done when a completion script requests completions.
+	if isCompletionRequest(os.Args[1:]) {
+		// Complete resource IDs on all commands that take them.
+		completion.RegisterResourceCompletions(cli)
+
+		// Complete the values of enum flags on all commands.
+		root.RegisterEnumCompletions(cli)
+	}
+
 	// Add workspace command groups, filtering out empty groups or groups with only hidden commands.
 	configureGroups(cli, append(workspace.Groups(), cobra.Group{
 		ID:    "development",
@@ -116,3 +127,9 @@ func New(ctx context.Context) *cobra.Command {
 
 	return cli
 }
+
+// isCompletionRequest returns true if args invoke Cobra's hidden command that
+// completion scripts call to request completions.
+func isCompletionRequest(args []string) bool {
+	return len(args) > 0 && (args[0] == cobra.ShellCompRequestCmd || args[0] == cobra.ShellCompNoDescRequestCmd)
+}
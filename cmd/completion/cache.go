@@ -0,0 +1,108 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/databricks/cli/internal/build"
+	libcompletion "github.com/databricks/cli/libs/completion"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
+	"github.com/spf13/cobra"
+)
+
+func newCacheCmd() *cobra.Command {
+	var shellFlag string
+	var force bool
+	var noDesc bool
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Refresh the cached completion script",
+		Long: `Refresh the cached completion script for your shell.
+
+The completions installed by 'databricks completion install' source a cached
+completion script, so that the CLI doesn't run on every shell start. They run
+this command in the background to regenerate the script after the CLI has
+been upgraded. The script is only regenerated if it was generated by another
+version of the CLI or with other options, unless --force is specified.`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			shell, err := libcompletion.DetectShell(ctx, shellFlag)
+			if err != nil {
+				return err
+			}
+
+			home, err := env.UserHomeDir(ctx)
+			if err != nil {
+				return err
+			}
+
+			if !force && !libcompletion.CacheStale(shell, home, build.GetInfo().Version, !noDesc, executable(ctx)) {
+				log.Debugf(ctx, "Cached completion script for %s is up to date", shell)
+				return nil
+			}
+			return writeCache(cmd, shell, home, !noDesc)
+		},
+	}
+	cmd.Flags().BoolVar(&force, "force", false, "Regenerate the script even if it is up to date")
+	cmd.Flags().BoolVar(&noDesc, "no-descriptions", false, "disable completion descriptions")
+	addShellFlag(cmd, &shellFlag)
+	return cmd
+}
+
+// writeCache caches the completion script for shell if its shim uses one.
+func writeCache(cmd *cobra.Command, shell libcompletion.Shell, home string, includeDesc bool) error {
+	if !shell.UsesCache() {
+		return nil
+	}
+	return libcompletion.WriteCache(shell, home, build.GetInfo().Version, includeDesc, executable(cmd.Context()), func(w io.Writer) error {
+		return generateScript(cmd.Root(), shell, includeDesc, w)
+	})
+}
+
+// executable returns the path of the CLI binary. The shim compares its
+// modification time with the one recorded for the cached script to tell
+// whether the script must be regenerated. It returns an empty string if the
+// path is unknown.
+func executable(ctx context.Context) string {
+	path, err := os.Executable()
+	if err != nil {
+		log.Debugf(ctx, "Failed to find the CLI binary: %v", err)
+		return ""
+	}
+	return path
+}
+
+// generateScript writes the completion script for shell, with or without
+// descriptions.
+func generateScript(root *cobra.Command, shell libcompletion.Shell, includeDesc bool, w io.Writer) error {
+	switch shell {
+	case libcompletion.Bash:
+		return root.GenBashCompletionV2(w, includeDesc)
+	case libcompletion.Zsh:
+		if !includeDesc {
+			return root.GenZshCompletionNoDesc(w)
+		}
+		return root.GenZshCompletion(w)
+	case libcompletion.Fish:
+		return root.GenFishCompletion(w, includeDesc)
+	case libcompletion.PowerShell, libcompletion.PowerShell5:
+		if !includeDesc {
+			return root.GenPowerShellCompletion(w)
+		}
+		return root.GenPowerShellCompletionWithDesc(w)
+	case libcompletion.Nushell:
+		_, err := io.WriteString(w, libcompletion.NushellScript(includeDesc))
+		return err
+	case libcompletion.Elvish:
+		_, err := io.WriteString(w, libcompletion.ElvishScript(includeDesc))
+		return err
+	default:
+		return fmt.Errorf("unsupported shell %q", shell)
+	}
+}
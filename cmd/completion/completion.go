@@ -2,9 +2,8 @@ package completion
 
 import (
 	"context"
-	"os"
+	"io"
 	"path/filepath"
-	"strings"
 
 	"github.com/databricks/cli/cmd/root"
 	"github.com/databricks/cli/libs/cmdio"
@@ -34,9 +33,14 @@ See each sub-command's help for details on how to use the generated script.
 		newZshCmd(),
 		newFishCmd(),
 		newPowershellCmd(),
+		newNushellCmd(),
+		newElvishCmd(),
 		newInstallCmd(),
 		newUninstallCmd(),
 		newStatusCmd(),
+		newDoctorCmd(),
+		newCacheCmd(),
+		newRefreshResourcesCmd(),
 	)
 
 	return cmd
@@ -174,19 +178,71 @@ to your powershell profile.
 	return cmd
 }
 
+func newNushellCmd() *cobra.Command {
+	var noDesc bool
+	cmd := &cobra.Command{
+		Use:   "nushell",
+		Short: "Generate the autocompletion script for nushell",
+		Long: `Generate the autocompletion script for nushell.
+
+The script registers an external completer for databricks. Completers for
+other commands that were configured before it keep working.
+
+To load completions for every new session, execute once:
+
+	databricks completion nushell | save -f ($nu.default-config-dir | path join autoload databricks.nu)
+
+You will need to start a new shell for this setup to take effect.
+`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := io.WriteString(cmd.OutOrStdout(), libcompletion.NushellScript(!noDesc))
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&noDesc, "no-descriptions", false, "disable completion descriptions")
+	return cmd
+}
+
+func newElvishCmd() *cobra.Command {
+	var noDesc bool
+	cmd := &cobra.Command{
+		Use:   "elvish",
+		Short: "Generate the autocompletion script for elvish",
+		Long: `Generate the autocompletion script for elvish.
+
+To load completions in your current shell session:
+
+	eval (databricks completion elvish | slurp)
+
+To load completions for every new session, execute once:
+
+	databricks completion elvish > ~/.elvish/lib/databricks.elv
+
+and add the following to your rc.elv:
+
+	use databricks
+
+You will need to start a new shell for this setup to take effect.
+`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_, err := io.WriteString(cmd.OutOrStdout(), libcompletion.ElvishScript(!noDesc))
+			return err
+		},
+	}
+	cmd.Flags().BoolVar(&noDesc, "no-descriptions", false, "disable completion descriptions")
+	return cmd
+}
+
 // warnIfCompinitMissing prints a warning when zsh completions are present but
 // the user's .zshrc does not call compinit. Without compinit, neither our eval
 // shim nor Homebrew's _databricks file will be loaded.
 func warnIfCompinitMissing(ctx context.Context, shell libcompletion.Shell, home string) {
-	if shell != libcompletion.Zsh {
-		return
-	}
-	rcPath := libcompletion.TargetFilePath(shell, home)
-	content, err := os.ReadFile(rcPath)
-	if err != nil {
-		return
-	}
-	if strings.Contains(string(content), "compinit") {
+	rcPath, missing := libcompletion.CompinitMissing(ctx, shell, home)
+	if !missing {
 		return
 	}
 	cmdio.LogString(ctx, "")
@@ -197,8 +253,8 @@ func warnIfCompinitMissing(ctx context.Context, shell libcompletion.Shell, home
 
 // addShellFlag registers the --shell flag and its completion function on cmd.
 func addShellFlag(cmd *cobra.Command, target *string) {
-	cmd.Flags().StringVar(target, "shell", "", "Shell type: bash, zsh, fish, powershell, powershell5")
+	cmd.Flags().StringVar(target, "shell", "", "Shell type: bash, zsh, fish, powershell, powershell5, nushell, elvish")
 	cmd.RegisterFlagCompletionFunc("shell", func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
-		return []cobra.Completion{"bash", "zsh", "fish", "powershell", "powershell5"}, cobra.ShellCompDirectiveNoFileComp
+		return []cobra.Completion{"bash", "zsh", "fish", "powershell", "powershell5", "nushell", "elvish"}, cobra.ShellCompDirectiveNoFileComp
 	})
 }
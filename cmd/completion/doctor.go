@@ -0,0 +1,112 @@
+package completion
+
+import (
+	"fmt"
+
+	"github.com/databricks/cli/internal/build"
+	"github.com/databricks/cli/libs/cmdio"
+	libcompletion "github.com/databricks/cli/libs/completion"
+	"github.com/databricks/cli/libs/env"
+	"github.com/spf13/cobra"
+)
+
+// checkOutput is the outcome of a doctor check.
+type checkOutput struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Problem string `json:"problem,omitempty"`
+	Fix     string `json:"fix,omitempty"`
+}
+
+const checksTemplate = `{{range .}}{{if .Passed}}[PASS]{{else}}[FAIL]{{end}} {{.Name}}
+{{- if not .Passed}}
+       {{.Problem}}
+       Fix: {{.Fix}}
+{{- end}}
+{{end}}`
+
+func newDoctorCmd() *cobra.Command {
+	var shellFlag string
+	var fix bool
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose problems with shell completions",
+		Long: `Diagnose why Databricks CLI tab completions don't work in your shell.
+
+The command checks that completions are installed, that the shell
+configuration contains a single completion block, that the zsh completion
+system is initialized and its dump is up to date, that the bash-completion
+package is installed, and that Homebrew doesn't provide conflicting
+completions. It suggests a fix for each failed check.
+
+With --fix, the problems that can be fixed safely are fixed automatically,
+and the checks are run again.`,
+		Args:              cobra.NoArgs,
+		ValidArgsFunction: cobra.NoFileCompletions,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			shell, err := libcompletion.DetectShell(ctx, shellFlag)
+			if err != nil {
+				return err
+			}
+
+			home, err := env.UserHomeDir(ctx)
+			if err != nil {
+				return err
+			}
+
+			if fix {
+				fixed := false
+				for _, check := range libcompletion.Checks {
+					result := check.Run(ctx, shell, home)
+					if result == nil || result.Passed || result.Apply == nil {
+						continue
+					}
+					if err := result.Apply(); err != nil {
+						cmdio.LogString(ctx, fmt.Sprintf("Failed to fix %q: %s", check.Name, err))
+						continue
+					}
+					cmdio.LogString(ctx, "Fixed: "+check.Name)
+					fixed = true
+				}
+				// Installing the shim doesn't generate the script it sources.
+				if fixed && libcompletion.CacheStale(shell, home, build.GetInfo().Version, true, executable(ctx)) {
+					if err := writeCache(cmd, shell, home, true); err != nil {
+						return err
+					}
+				}
+			}
+
+			var outputs []checkOutput
+			failed := 0
+			for _, check := range libcompletion.Checks {
+				result := check.Run(ctx, shell, home)
+				if result == nil {
+					continue
+				}
+				outputs = append(outputs, checkOutput{
+					Name:    check.Name,
+					Passed:  result.Passed,
+					Problem: result.Problem,
+					Fix:     result.Fix,
+				})
+				if !result.Passed {
+					failed++
+				}
+			}
+
+			cmdio.LogString(ctx, "Shell: "+shell.DisplayName())
+			if err := cmdio.RenderWithTemplate(ctx, outputs, "", checksTemplate); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d checks failed", failed, len(outputs))
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVar(&fix, "fix", false, "Fix the problems that can be fixed safely")
+	addShellFlag(cmd, &shellFlag)
+	return cmd
+}
@@ -14,26 +14,69 @@ import (
 func newInstallCmd() *cobra.Command {
 	var shellFlag string
 	var autoApprove bool
+	var all bool
+	var noDesc bool
+	var force bool
+	var followSymlinks bool
+	var system systemFlags
 	cmd := &cobra.Command{
-		Use:               "install",
-		Short:             "Install shell completions",
-		Long:              "Install Databricks CLI tab completions into your shell configuration file.",
+		Use:   "install",
+		Short: "Install shell completions",
+		Long: `Install Databricks CLI tab completions into your shell configuration file.
+
+With --all, completions are installed for every shell that is detected: the
+shell from $SHELL, shells with a configuration file in your home directory,
+and pwsh if it is on PATH.
+
+With --system, the completion script for bash or zsh is written to the system
+completion directory instead, so that it is available to every user. The
+directory is detected from Homebrew or the operating system and can be set
+with --dir. Writing to it usually requires sudo.
+
+With --no-descriptions, completions don't include descriptions of commands
+and flags. The choice is recorded in the installed completions; run install
+again without it to enable descriptions again.
+
+For fish, nushell and elvish, completions are installed as a file of their own.
+If that file was installed by another tool, such as a package manager, it may
+be outdated. Use --force to back it up and replace it.
+
+If the file to modify is a symlink, for example into the repository of a
+dotfiles manager such as chezmoi or stow, the file it points to is modified
+after confirmation. With --follow-symlinks=false, nothing is modified and the
+completions to add by hand are printed instead.`,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
+			if all {
+				home, err := env.UserHomeDir(ctx)
+				if err != nil {
+					return err
+				}
+				return installAll(cmd, home, !noDesc, followSymlinks, autoApprove)
+			}
+
 			shell, err := libcompletion.DetectShell(ctx, shellFlag)
 			if err != nil {
 				return err
 			}
 
+			systemDir, err := system.validate(cmd, shell)
+			if err != nil {
+				return err
+			}
+			if systemDir != "" {
+				return installSystem(cmd, shell, systemDir, !noDesc, autoApprove)
+			}
+
 			home, err := env.UserHomeDir(ctx)
 			if err != nil {
 				return err
 			}
 
-			filePath := libcompletion.TargetFilePath(shell, home)
+			filePath := libcompletion.TargetFilePath(ctx, shell, home)
 			displayPath := filepath.ToSlash(filePath)
 
 			// Check if already installed — no confirmation needed.
@@ -44,8 +87,35 @@ func newInstallCmd() *cobra.Command {
 			if result.Installed {
 				switch result.Method {
 				case "marker":
-					// Our shim is already in the RC file — nothing to do.
-					cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions are already installed for %s in %s.", shell, displayPath))
+					// Our shim is already installed. Install updates it if it
+					// differs, for example with --no-descriptions, and removes
+					// duplicated blocks.
+					if result.LinkTarget != "" && !libcompletion.UpToDate(ctx, shell, home, !noDesc) {
+						if !followSymlinks {
+							return printShim(cmd, shell, home, result, !noDesc)
+						}
+						if !autoApprove {
+							if !cmdio.IsPromptSupported(ctx) {
+								return errors.New("use --auto-approve to skip the confirmation prompt, or run 'databricks completion status' to preview the detected shell and target file")
+							}
+							confirmed, err := cmdio.AskYesOrNo(ctx, fmt.Sprintf("%s is a symlink. Update the completions in %s?", displayPath, filepath.ToSlash(result.LinkTarget)))
+							if err != nil || !confirmed {
+								return err
+							}
+						}
+					}
+					_, alreadyInstalled, err := libcompletion.Install(ctx, shell, home, !noDesc)
+					if err != nil {
+						return err
+					}
+					if alreadyInstalled {
+						cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions are already installed for %s in %s.", shell, displayPath))
+					} else {
+						if err := writeCache(cmd, shell, home, !noDesc); err != nil {
+							return err
+						}
+						cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions updated for %s in %s.\nRestart your shell to activate.", shell, displayPath))
+					}
 					warnIfCompinitMissing(ctx, shell, home)
 					return nil
 				case "homebrew":
@@ -53,22 +123,29 @@ func newInstallCmd() *cobra.Command {
 					// may still want a CLI-managed shim in .zshrc (e.g. for a
 					// newer binary). Inform them and proceed with install.
 					cmdio.LogString(ctx, fmt.Sprintf("Note: Databricks CLI completions for %s are also provided by Homebrew.", shell))
+				case "file":
+					// External file (e.g. fish installed by package manager) —
+					// it may be stale, so offer to replace it.
+					return replaceForeign(cmd, shell, home, result.FilePath, !noDesc, force, autoApprove)
 				default:
-					// External file (e.g. fish installed by package manager) — we
-					// can't overwrite it, so report and exit.
-					cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions for %s are already present in %s.", shell, displayPath))
+					// Installed by other means (e.g. system-wide) — report and exit.
+					cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions for %s are already present in %s.", shell, filepath.ToSlash(result.FilePath)))
 					warnIfCompinitMissing(ctx, shell, home)
 					return nil
 				}
 			}
 
+			if result.LinkTarget != "" && !followSymlinks {
+				return printShim(cmd, shell, home, result, !noDesc)
+			}
+
 			// Confirm before writing.
 			if !autoApprove {
 				if !cmdio.IsPromptSupported(ctx) {
 					return errors.New("use --auto-approve to skip the confirmation prompt, or run 'databricks completion status' to preview the detected shell and target file")
 				}
 				cmdio.LogString(ctx, "Shell: "+shell.DisplayName())
-				cmdio.LogString(ctx, "File:  "+displayPath)
+				cmdio.LogString(ctx, "File:  "+displayFile(filePath, result.LinkTarget))
 				confirmed, err := cmdio.AskYesOrNo(ctx, "Proceed?")
 				if err != nil {
 					return err
@@ -78,7 +155,10 @@ func newInstallCmd() *cobra.Command {
 				}
 			}
 
-			_, alreadyInstalled, err := libcompletion.Install(ctx, shell, home)
+			if err := writeCache(cmd, shell, home, !noDesc); err != nil {
+				return err
+			}
+			_, alreadyInstalled, err := libcompletion.Install(ctx, shell, home, !noDesc)
 			if err != nil {
 				return err
 			}
@@ -91,6 +171,18 @@ func newInstallCmd() *cobra.Command {
 			switch shell {
 			case libcompletion.PowerShell, libcompletion.PowerShell5:
 				msg += "Restart your shell to activate."
+			case libcompletion.Elvish:
+				msg += "Add 'use databricks' to your rc.elv and restart your shell to activate."
+			case libcompletion.Nushell:
+				msg += "Restart your shell to activate."
+			case libcompletion.Zsh:
+				if libcompletion.OhMyZshDir(ctx, home) == "" {
+					msg += fmt.Sprintf("Restart your shell or run 'source %s' to activate.", displayPath)
+				} else if !libcompletion.OhMyZshPluginEnabled(ctx, home) {
+					msg += "Add databricks to the plugins=(...) array in your .zshrc and restart your shell to activate."
+				} else {
+					msg += "Restart your shell to activate."
+				}
 			default:
 				msg += fmt.Sprintf("Restart your shell or run 'source %s' to activate.", displayPath)
 			}
@@ -100,6 +192,65 @@ func newInstallCmd() *cobra.Command {
 		},
 	}
 	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&force, "force", false, "Back up and replace a completion file that was installed by another tool")
+	cmd.Flags().BoolVar(&all, "all", false, "Install completions for every detected shell")
+	cmd.Flags().BoolVar(&noDesc, "no-descriptions", false, "disable completion descriptions")
+	cmd.Flags().BoolVar(&followSymlinks, "follow-symlinks", true, "Modify the file that a symlinked shell configuration file points to")
 	addShellFlag(cmd, &shellFlag)
+	system.register(cmd, "Install")
+	cmd.MarkFlagsMutuallyExclusive("all", "shell")
+	cmd.MarkFlagsMutuallyExclusive("all", "system")
 	return cmd
 }
+
+// replaceForeign backs up and replaces a completion file that was installed by
+// another tool, if --force is set or the user confirms it.
+func replaceForeign(cmd *cobra.Command, shell libcompletion.Shell, home, filePath string, includeDesc, force, autoApprove bool) error {
+	ctx := cmd.Context()
+	displayPath := filepath.ToSlash(filePath)
+
+	if !force {
+		if autoApprove || !cmdio.IsPromptSupported(ctx) {
+			cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions for %s are already present in %s.", shell, displayPath))
+			cmdio.LogString(ctx, "The file was not installed by the Databricks CLI. Use --force to back it up and replace it.")
+			return nil
+		}
+		confirmed, err := cmdio.AskYesOrNo(ctx, fmt.Sprintf("%s was not installed by the Databricks CLI and may be outdated. Back it up and replace it?", displayPath))
+		if err != nil || !confirmed {
+			return err
+		}
+	}
+
+	if err := writeCache(cmd, shell, home, includeDesc); err != nil {
+		return err
+	}
+	_, backupPath, err := libcompletion.ReplaceForeign(ctx, shell, home, includeDesc)
+	if err != nil {
+		return err
+	}
+	cmdio.LogString(ctx, fmt.Sprintf("Backed up %s to %s.", displayPath, filepath.ToSlash(backupPath)))
+	cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions installed for %s.\nRestart your shell to activate.", shell))
+	return nil
+}
+
+// printShim prints the completions to add by hand to a file that is a symlink,
+// for users who don't want the CLI to modify the file it points to.
+func printShim(cmd *cobra.Command, shell libcompletion.Shell, home string, result *libcompletion.StatusResult, includeDesc bool) error {
+	ctx := cmd.Context()
+	cmdio.LogString(ctx, fmt.Sprintf(
+		"%s is a symlink to %s, which is not modified with --follow-symlinks=false.",
+		filepath.ToSlash(result.FilePath), filepath.ToSlash(result.LinkTarget),
+	))
+	cmdio.LogString(ctx, "Add the following to it to install the completions:\n")
+	_, err := fmt.Fprint(cmd.OutOrStdout(), libcompletion.InstallContent(ctx, shell, home, includeDesc))
+	return err
+}
+
+// displayFile returns the path of a file to modify for display, along with the
+// file it points to if it is a symlink.
+func displayFile(filePath, linkTarget string) string {
+	if linkTarget == "" {
+		return filepath.ToSlash(filePath)
+	}
+	return fmt.Sprintf("%s (symlink to %s)", filepath.ToSlash(filePath), filepath.ToSlash(linkTarget))
+}
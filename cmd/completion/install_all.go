@@ -0,0 +1,139 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/databricks/cli/libs/cmdio"
+	libcompletion "github.com/databricks/cli/libs/completion"
+	"github.com/spf13/cobra"
+)
+
+// installResult is the outcome of installing completions for one shell.
+type installResult struct {
+	Shell  libcompletion.Shell `json:"shell"`
+	File   string              `json:"file"`
+	Result string              `json:"result"`
+}
+
+const installResultsTemplate = `{{range .}}{{.Shell}}	{{.File}}	{{.Result}}
+{{end}}`
+
+// installAll installs completions for every shell returned by
+// [libcompletion.DetectShells]. It confirms once for all shells and keeps
+// going if the installation for one of them fails.
+func installAll(cmd *cobra.Command, home string, includeDesc, followSymlinks, autoApprove bool) error {
+	ctx := cmd.Context()
+	shells := libcompletion.DetectShells(ctx, home)
+	if len(shells) == 0 {
+		return errors.New("could not detect any supported shell. Use --shell to specify your shell")
+	}
+
+	var results []*installResult
+	var pending []*installResult
+	linkTargets := map[libcompletion.Shell]string{}
+	for _, shell := range shells {
+		r := &installResult{
+			Shell: shell,
+			File:  filepath.ToSlash(libcompletion.TargetFilePath(ctx, shell, home)),
+		}
+		results = append(results, r)
+
+		status, err := libcompletion.Status(ctx, shell, home)
+		if err != nil {
+			r.Result = "failed: " + err.Error()
+			continue
+		}
+		linkTargets[shell] = status.LinkTarget
+		if status.LinkTarget != "" && !followSymlinks && !libcompletion.UpToDate(ctx, shell, home, includeDesc) {
+			r.Result = "skipped (symlink)"
+			continue
+		}
+		if status.Installed {
+			switch status.Method {
+			case "marker":
+				// Install updates the shim if it differs and removes
+				// duplicated blocks, if any.
+				r.Result = "already installed"
+				_, alreadyInstalled, err := libcompletion.Install(ctx, shell, home, includeDesc)
+				if err == nil && !alreadyInstalled {
+					r.Result = "updated"
+					err = writeCache(cmd, shell, home, includeDesc)
+				}
+				if err != nil {
+					r.Result = "failed: " + err.Error()
+				}
+				continue
+			case "homebrew":
+				// Homebrew's completions don't prevent installing the shim,
+				// same as for a single shell.
+			default:
+				r.Result = "skipped (external)"
+				continue
+			}
+		}
+		pending = append(pending, r)
+	}
+
+	if len(pending) > 0 && !autoApprove {
+		if !cmdio.IsPromptSupported(ctx) {
+			return errors.New("use --auto-approve to skip the confirmation prompt, or run 'databricks completion status' to preview the detected shell and target file")
+		}
+		cmdio.LogString(ctx, "Completions will be installed for:")
+		for _, r := range pending {
+			cmdio.LogString(ctx, fmt.Sprintf("  %s: %s", r.Shell.DisplayName(), displayFile(r.File, linkTargets[r.Shell])))
+		}
+		confirmed, err := cmdio.AskYesOrNo(ctx, "Proceed?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	for _, r := range pending {
+		if err := writeCache(cmd, r.Shell, home, includeDesc); err != nil {
+			r.Result = "failed: " + err.Error()
+			continue
+		}
+		_, alreadyInstalled, err := libcompletion.Install(ctx, r.Shell, home, includeDesc)
+		switch {
+		case err != nil:
+			r.Result = "failed: " + err.Error()
+		case alreadyInstalled:
+			r.Result = "already installed"
+		default:
+			r.Result = "installed"
+		}
+	}
+
+	err := cmdio.RenderWithTemplate(ctx, results, "Shell	File	Result", installResultsTemplate)
+	if err != nil {
+		return err
+	}
+
+	var failed []string
+	for _, r := range results {
+		if strings.HasPrefix(r.Result, "failed") {
+			failed = append(failed, string(r.Shell))
+			continue
+		}
+		warnIfCompinitMissing(ctx, r.Shell, home)
+		if r.Shell == libcompletion.Elvish && r.Result == "installed" {
+			cmdio.LogString(ctx, "Add 'use databricks' to your rc.elv to activate the completions for elvish.")
+		}
+		if r.Shell == libcompletion.Zsh && r.Result == "installed" && libcompletion.OhMyZshDir(ctx, home) != "" && !libcompletion.OhMyZshPluginEnabled(ctx, home) {
+			cmdio.LogString(ctx, "Add databricks to the plugins=(...) array in your .zshrc to activate the completions for zsh.")
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to install completions for %s", strings.Join(failed, ", "))
+	}
+	if len(pending) > 0 {
+		cmdio.LogString(ctx, "Restart your shells to activate the completions.")
+	}
+	return nil
+}
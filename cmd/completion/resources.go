@@ -0,0 +1,236 @@
+package completion
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	libcompletion "github.com/databricks/cli/libs/completion"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+)
+
+// resourceCompletionTimeout bounds how long a completion waits for resources
+// that aren't cached yet. Completions that take longer are dropped, and the
+// resources are fetched in the background for the next attempt.
+const resourceCompletionTimeout = time.Second
+
+// resourceRefreshTimeout bounds how long a background refresh runs.
+const resourceRefreshTimeout = 30 * time.Second
+
+// RegisterResourceCompletions registers completions for resource IDs on all
+// flags and positional arguments of cmd and its subcommands that take them.
+// Flags that already have a completion function are left as-is.
+func RegisterResourceCompletions(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		RegisterResourceCompletions(sub)
+	}
+
+	for _, kind := range libcompletion.ResourceKinds {
+		if cmd.Flags().Lookup(kind.Flag) != nil {
+			if _, ok := cmd.GetFlagCompletionFunc(kind.Flag); !ok {
+				_ = cmd.RegisterFlagCompletionFunc(kind.Flag, completeResources(kind))
+			}
+		}
+		if firstArg(cmd) == kind.Arg || (firstArg(cmd) == "ID" && cmd.HasParent() && cmd.Parent().Name() == kind.Name) {
+			complete := completeResources(kind)
+			cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+				if len(args) > 0 {
+					return nil, cobra.ShellCompDirectiveNoFileComp
+				}
+				return complete(cmd, args, toComplete)
+			}
+		}
+	}
+}
+
+// firstArg returns the name of the first positional argument in the usage
+// line of cmd, for example CLUSTER_ID for "get CLUSTER_ID".
+func firstArg(cmd *cobra.Command) string {
+	fields := strings.Fields(cmd.Use)
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// completeResources returns a completion function for IDs of the given kind.
+// It never fails: if the resources can't be listed, there are no completions.
+func completeResources(kind libcompletion.ResourceKind) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		var completions []cobra.Completion
+		for _, r := range cachedResources(cmd, kind) {
+			if strings.HasPrefix(r.ID, toComplete) {
+				completions = append(completions, cobra.CompletionWithDesc(r.ID, r.Name))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// cachedResources returns the resources of the given kind from the cache. If
+// they are stale, they are refreshed in the background. If nothing is cached,
+// they are fetched, unless that takes longer than resourceCompletionTimeout.
+func cachedResources(cmd *cobra.Command, kind libcompletion.ResourceKind) []libcompletion.Resource {
+	ctx := cmd.Context()
+	cfg, path, err := resourceCache(cmd, kind)
+	if err != nil {
+		log.Debugf(ctx, "Not completing %s: %s", kind.Name, err)
+		return nil
+	}
+
+	resources, fresh, ok := libcompletion.ReadResourceCache(path, time.Now())
+	if ok {
+		if !fresh {
+			refreshInBackground(ctx, cfg, path, kind)
+		}
+		return resources
+	}
+
+	type result struct {
+		resources []libcompletion.Resource
+		err       error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		resources, err := fetchResources(ctx, cfg, kind)
+		ch <- result{resources, err}
+	}()
+
+	select {
+	case res := <-ch:
+		if res.err != nil {
+			log.Debugf(ctx, "Failed to list %s: %s", kind.Name, res.err)
+			return nil
+		}
+		if err := libcompletion.WriteResourceCache(path, res.resources, time.Now()); err != nil {
+			log.Debugf(ctx, "Failed to cache %s: %s", kind.Name, err)
+		}
+		return res.resources
+	case <-time.After(resourceCompletionTimeout):
+		log.Debugf(ctx, "Listing %s took too long, continuing in the background", kind.Name)
+		refreshInBackground(ctx, cfg, path, kind)
+		return nil
+	}
+}
+
+// resourceCache resolves the workspace configuration for cmd without
+// authenticating or making network requests, and returns it with the cache
+// file for the given kind. The client is only built when the resources are
+// fetched, so that a cache hit is returned right away.
+func resourceCache(cmd *cobra.Command, kind libcompletion.ResourceKind) (*config.Config, string, error) {
+	ctx := cmd.Context()
+	cfg := &config.Config{Loaders: databrickscfg.Loaders()}
+	if f := cmd.Flag("profile"); f != nil && f.Changed {
+		cfg.Profile = f.Value.String()
+	}
+	if cfg.Profile == "" && env.Get(ctx, "DATABRICKS_CONFIG_PROFILE") == "" {
+		profile, err := databrickscfg.GetConfiguredDefaultProfile(ctx, env.Get(ctx, "DATABRICKS_CONFIG_FILE"))
+		if err == nil {
+			cfg.Profile = profile
+		}
+	}
+	// Run the loaders directly. EnsureResolved also fetches host metadata.
+	for _, loader := range cfg.Loaders {
+		if err := loader.Configure(cfg); err != nil {
+			return nil, "", err
+		}
+	}
+	if cfg.Host == "" {
+		return nil, "", errors.New("no workspace host is configured")
+	}
+
+	home, err := env.UserHomeDir(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	return cfg, libcompletion.ResourceCachePath(home, cfg.CanonicalHostName(), kind.Name), nil
+}
+
+// fetchResources lists the resources of the given kind in the workspace.
+func fetchResources(ctx context.Context, cfg *config.Config, kind libcompletion.ResourceKind) ([]libcompletion.Resource, error) {
+	w, err := databricks.NewWorkspaceClient((*databricks.Config)(cfg))
+	if err != nil {
+		return nil, err
+	}
+	return kind.List(ctx, w)
+}
+
+// refreshInBackground starts a detached "databricks completion
+// refresh-resources" process to refresh the cache at path, unless one is
+// already running.
+func refreshInBackground(ctx context.Context, cfg *config.Config, path string, kind libcompletion.ResourceKind) {
+	if !libcompletion.ClaimResourceRefresh(path, time.Now()) {
+		return
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		log.Debugf(ctx, "Failed to refresh %s: %s", kind.Name, err)
+		_ = libcompletion.ReleaseResourceRefresh(path)
+		return
+	}
+	args := []string{"completion", "refresh-resources", kind.Name}
+	if cfg.Profile != "" {
+		args = append(args, "--profile", cfg.Profile)
+	}
+	c := exec.Command(exe, args...)
+	if err := c.Start(); err != nil {
+		log.Debugf(ctx, "Failed to refresh %s: %s", kind.Name, err)
+		_ = libcompletion.ReleaseResourceRefresh(path)
+		return
+	}
+	_ = c.Process.Release()
+}
+
+func newRefreshResourcesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh-resources KIND",
+		Short: "Refresh the cached resources used for completions",
+		Long: `Refresh the cached resources used to complete resource IDs.
+
+Completions for cluster, warehouse and job IDs are served from a cache that
+is considered fresh for five minutes. Completions run this command in the
+background to refresh the cache when it is stale.`,
+		Args:   cobra.ExactArgs(1),
+		Hidden: true,
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			var names []cobra.Completion
+			for _, kind := range libcompletion.ResourceKinds {
+				names = append(names, kind.Name)
+			}
+			return names, cobra.ShellCompDirectiveNoFileComp
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind, ok := libcompletion.LookupResourceKind(args[0])
+			if !ok {
+				return errors.New("unknown resource kind " + args[0])
+			}
+
+			cfg, path, err := resourceCache(cmd, kind)
+			if err != nil {
+				return err
+			}
+			defer libcompletion.ReleaseResourceRefresh(path)
+
+			ctx, cancel := context.WithTimeout(cmd.Context(), resourceRefreshTimeout)
+			defer cancel()
+			resources, err := fetchResources(ctx, cfg, kind)
+			if err != nil {
+				return err
+			}
+			return libcompletion.WriteResourceCache(path, resources, time.Now())
+		},
+	}
+	return cmd
+}
@@ -2,20 +2,50 @@ package completion
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 
+	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/internal/build"
 	"github.com/databricks/cli/libs/cmdio"
 	libcompletion "github.com/databricks/cli/libs/completion"
 	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/flags"
 	"github.com/spf13/cobra"
 )
 
+// exitCodeNotInstalled is the exit code of "status --check" when completions
+// are not installed. It differs from 1 so that scripts can tell it apart from
+// errors such as a shell that can't be detected.
+const exitCodeNotInstalled = 3
+
+// statusOutput is the JSON representation of the completion status.
+type statusOutput struct {
+	Shell libcompletion.Shell `json:"shell"`
+	File  string              `json:"file"`
+
+	// LinkTarget is the file that File points to if it is a symlink.
+	LinkTarget string `json:"link_target,omitempty"`
+
+	Installed bool     `json:"installed"`
+	Method    string   `json:"method"`
+	Warnings  []string `json:"warnings"`
+
+	// Descriptions is only set for completions installed by the CLI.
+	Descriptions *bool `json:"descriptions,omitempty"`
+}
+
 func newStatusCmd() *cobra.Command {
 	var shellFlag string
+	var check bool
 	cmd := &cobra.Command{
-		Use:               "status",
-		Short:             "Show shell completion status",
-		Long:              "Show whether Databricks CLI tab completions are installed for your shell.",
+		Use:   "status",
+		Short: "Show shell completion status",
+		Long: `Show whether Databricks CLI tab completions are installed for your shell.
+
+With --check, the command exits with code 0 if completions are installed and
+with code 3 if they are not. Other non-zero exit codes indicate an error, for
+example if the shell could not be detected.`,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -36,25 +66,99 @@ func newStatusCmd() *cobra.Command {
 				return err
 			}
 
-			statusStr := "not installed"
-			if result.Installed {
-				statusStr = "installed"
-				if result.Method != "" && result.Method != "marker" {
-					statusStr = fmt.Sprintf("installed (via %s)", result.Method)
+			if root.OutputType(cmd) == flags.OutputJSON {
+				out := statusOutput{
+					Shell:      shell,
+					File:       filepath.ToSlash(result.FilePath),
+					LinkTarget: filepath.ToSlash(result.LinkTarget),
+					Installed:  result.Installed,
+					Method:     result.Method,
+					Warnings:   []string{},
+				}
+				if result.Method == "marker" {
+					out.Descriptions = &result.Descriptions
+				}
+				if warning := homebrewMismatch(result); warning != "" {
+					out.Warnings = append(out.Warnings, warning)
+				}
+				if rcPath, missing := libcompletion.CompinitMissing(ctx, shell, home); result.Installed && missing {
+					out.Warnings = append(out.Warnings, fmt.Sprintf(
+						"zsh completions require the completion system to be initialized. Add the following to your %s: autoload -U compinit; compinit",
+						filepath.ToSlash(rcPath),
+					))
+				}
+				if err := cmdio.Render(ctx, out); err != nil {
+					return err
+				}
+			} else {
+				statusStr := "not installed"
+				if result.Installed {
+					statusStr = "installed"
+					if result.Method == "file" {
+						statusStr = "installed (foreign file, not managed by the CLI)"
+					} else if result.Method != "" && result.Method != "marker" {
+						statusStr = fmt.Sprintf("installed (via %s)", result.Method)
+					}
 				}
-			}
 
-			cmdio.LogString(ctx, fmt.Sprintf("%-8s %s", "Shell:", shell.DisplayName()))
-			cmdio.LogString(ctx, fmt.Sprintf("%-8s %s", "File:", filepath.ToSlash(result.FilePath)))
-			cmdio.LogString(ctx, fmt.Sprintf("%-8s %s", "Status:", statusStr))
+				cmdio.LogString(ctx, fmt.Sprintf("%-13s %s", "Shell:", shell.DisplayName()))
+				cmdio.LogString(ctx, fmt.Sprintf("%-13s %s", "File:", filepath.ToSlash(result.FilePath)))
+				if result.LinkTarget != "" {
+					cmdio.LogString(ctx, fmt.Sprintf("%-13s %s", "Link target:", filepath.ToSlash(result.LinkTarget)))
+				}
+				cmdio.LogString(ctx, fmt.Sprintf("%-13s %s", "Status:", statusStr))
+				if result.Method == "marker" {
+					descriptions := "enabled"
+					if !result.Descriptions {
+						descriptions = "disabled"
+					}
+					cmdio.LogString(ctx, fmt.Sprintf("%-13s %s", "Descriptions:", descriptions))
+				}
 
-			if result.Installed {
-				warnIfCompinitMissing(ctx, shell, home)
+				if warning := homebrewMismatch(result); warning != "" {
+					cmdio.LogString(ctx, "")
+					cmdio.LogString(ctx, "Warning: "+warning)
+				}
+				if result.Installed {
+					warnIfCompinitMissing(ctx, shell, home)
+				}
 			}
 
+			if check && !result.Installed {
+				return &root.ExitCodeError{Code: exitCodeNotInstalled}
+			}
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&check, "check", false, "Exit with code 3 if completions are not installed")
 	addShellFlag(cmd, &shellFlag)
 	return cmd
 }
+
+// homebrewMismatch returns a warning if completions are provided by Homebrew
+// for a databricks binary other than the one that is running. The completions
+// may then not match the commands of the running binary.
+func homebrewMismatch(result *libcompletion.StatusResult) string {
+	if result.Method != "homebrew" || result.HomebrewBinary == "" {
+		return ""
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	if exe == result.HomebrewBinary {
+		return ""
+	}
+
+	brewVersion := result.HomebrewVersion
+	if brewVersion == "" {
+		brewVersion = "unknown"
+	}
+	return fmt.Sprintf(
+		"The completions from Homebrew are for the CLI at %s (version %s), but you are running %s (version %s). They may not match the commands of this version.",
+		filepath.ToSlash(result.HomebrewBinary), brewVersion, filepath.ToSlash(exe), build.GetInfo().Version,
+	)
+}
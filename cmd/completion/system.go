@@ -0,0 +1,117 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/databricks/cli/libs/cmdio"
+	libcompletion "github.com/databricks/cli/libs/completion"
+	"github.com/spf13/cobra"
+)
+
+// systemFlags are the flags for installing completions system-wide.
+type systemFlags struct {
+	system bool
+	dir    string
+}
+
+func (f *systemFlags) register(cmd *cobra.Command, verb string) {
+	cmd.Flags().BoolVar(&f.system, "system", false, verb+" the completion script in the system completion directory (bash and zsh only)")
+	cmd.Flags().StringVar(&f.dir, "dir", "", "System completion directory to use with --system")
+}
+
+// validate checks the flags and returns the system completion directory for
+// shell, or an empty string if --system isn't set.
+func (f *systemFlags) validate(cmd *cobra.Command, shell libcompletion.Shell) (string, error) {
+	if !f.system {
+		if f.dir != "" {
+			return "", errors.New("--dir can only be used with --system")
+		}
+		return "", nil
+	}
+	if !libcompletion.SupportsSystemInstall(shell) {
+		return "", fmt.Errorf("--system is only supported for bash and zsh, not %s", shell)
+	}
+	dir := f.dir
+	if dir == "" {
+		dir = libcompletion.SystemDir(cmd.Context(), shell)
+	}
+	return dir, nil
+}
+
+// confirmSystem asks for confirmation before writing to the system file.
+func confirmSystem(cmd *cobra.Command, shell libcompletion.Shell, filePath string, autoApprove bool) (bool, error) {
+	ctx := cmd.Context()
+	if autoApprove {
+		return true, nil
+	}
+	if !cmdio.IsPromptSupported(ctx) {
+		return false, errors.New("use --auto-approve to skip the confirmation prompt")
+	}
+	cmdio.LogString(ctx, "Shell: "+shell.DisplayName())
+	cmdio.LogString(ctx, "File:  "+filepath.ToSlash(filePath))
+	return cmdio.AskYesOrNo(ctx, "Proceed?")
+}
+
+// installSystem writes the fully generated completion script for shell to
+// the system completion directory dir.
+func installSystem(cmd *cobra.Command, shell libcompletion.Shell, dir string, includeDesc, autoApprove bool) error {
+	ctx := cmd.Context()
+	filePath := libcompletion.SystemFilePath(shell, dir)
+	confirmed, err := confirmSystem(cmd, shell, filePath, autoApprove)
+	if err != nil || !confirmed {
+		return err
+	}
+
+	_, alreadyInstalled, err := libcompletion.InstallSystem(shell, dir, func(w io.Writer) error {
+		return generateScript(cmd.Root(), shell, includeDesc, w)
+	})
+	if err != nil {
+		return err
+	}
+
+	displayPath := filepath.ToSlash(filePath)
+	if alreadyInstalled {
+		cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions updated for %s in %s.", shell, displayPath))
+		return nil
+	}
+	msg := fmt.Sprintf("Databricks CLI completions installed for %s in %s.\n", shell, displayPath)
+	if shell == libcompletion.Bash {
+		msg += "They are loaded by the bash-completion package in new shells."
+	} else {
+		msg += "They are loaded in new shells that have the completion system initialized."
+	}
+	cmdio.LogString(ctx, msg)
+	return nil
+}
+
+// uninstallSystem removes the completion script for shell from the system
+// completion directory dir, if it was installed with --system.
+func uninstallSystem(cmd *cobra.Command, shell libcompletion.Shell, dir string, autoApprove bool) error {
+	ctx := cmd.Context()
+	filePath := libcompletion.SystemFilePath(shell, dir)
+	displayPath := filepath.ToSlash(filePath)
+
+	if ok, _ := libcompletion.IsSystemFile(filePath); !ok {
+		cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions were not installed for %s in %s.", shell, displayPath))
+		return nil
+	}
+
+	confirmed, err := confirmSystem(cmd, shell, filePath, autoApprove)
+	if err != nil || !confirmed {
+		return err
+	}
+
+	_, wasInstalled, err := libcompletion.UninstallSystem(shell, dir)
+	if err != nil {
+		return err
+	}
+	if !wasInstalled {
+		cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions were not installed for %s in %s.", shell, displayPath))
+		return nil
+	}
+	cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions removed for %s from %s.", shell, displayPath))
+	return nil
+}
@@ -14,26 +14,52 @@ import (
 func newUninstallCmd() *cobra.Command {
 	var shellFlag string
 	var autoApprove bool
+	var all bool
+	var system systemFlags
 	cmd := &cobra.Command{
-		Use:               "uninstall",
-		Short:             "Uninstall shell completions",
-		Long:              "Remove Databricks CLI tab completions from your shell configuration file.",
+		Use:   "uninstall",
+		Short: "Uninstall shell completions",
+		Long: `Remove Databricks CLI tab completions from your shell configuration file.
+
+With --all, completions are removed for every supported shell, whether or not
+it is detected, along with the completion caches. Run this before
+removing the CLI, or afterwards with another copy of it, to clean up the
+completions it installed.
+
+With --system, the completion script for bash or zsh is removed from the
+system completion directory instead, if it was installed with --system.`,
 		Args:              cobra.NoArgs,
 		ValidArgsFunction: cobra.NoFileCompletions,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 
+			if all {
+				home, err := env.UserHomeDir(ctx)
+				if err != nil {
+					return err
+				}
+				return uninstallAll(cmd, home, autoApprove)
+			}
+
 			shell, err := libcompletion.DetectShell(ctx, shellFlag)
 			if err != nil {
 				return err
 			}
 
+			systemDir, err := system.validate(cmd, shell)
+			if err != nil {
+				return err
+			}
+			if systemDir != "" {
+				return uninstallSystem(cmd, shell, systemDir, autoApprove)
+			}
+
 			home, err := env.UserHomeDir(ctx)
 			if err != nil {
 				return err
 			}
 
-			filePath := libcompletion.TargetFilePath(shell, home)
+			filePath := libcompletion.TargetFilePath(ctx, shell, home)
 			displayPath := filepath.ToSlash(filePath)
 
 			// Check current status to avoid a useless prompt.
@@ -52,6 +78,12 @@ func newUninstallCmd() *cobra.Command {
 				switch result.Method {
 				case "homebrew":
 					cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions for %s are provided by Homebrew. Nothing to uninstall.", shell))
+				case "system":
+					cmdio.LogString(ctx, fmt.Sprintf(
+						"Databricks CLI completions for %s are installed system-wide in %s. Use --system to uninstall them.",
+						shell,
+						filepath.ToSlash(result.FilePath),
+					))
 				default:
 					cmdio.LogString(ctx, fmt.Sprintf(
 						"Databricks CLI completions for %s appear to be installed externally in %s. Nothing to uninstall.",
@@ -69,7 +101,7 @@ func newUninstallCmd() *cobra.Command {
 					return errors.New("use --auto-approve to skip the confirmation prompt, or run 'databricks completion status' to preview the detected shell and target file")
 				}
 				cmdio.LogString(ctx, "Shell: "+shell.DisplayName())
-				cmdio.LogString(ctx, "File:  "+displayPath)
+				cmdio.LogString(ctx, "File:  "+displayFile(filePath, result.LinkTarget))
 				confirmed, err := cmdio.AskYesOrNo(ctx, "Proceed?")
 				if err != nil {
 					return err
@@ -79,7 +111,7 @@ func newUninstallCmd() *cobra.Command {
 				}
 			}
 
-			_, wasInstalled, err := libcompletion.Uninstall(shell, home)
+			_, wasInstalled, err := libcompletion.Uninstall(ctx, shell, home)
 			if err != nil {
 				return err
 			}
@@ -91,10 +123,17 @@ func newUninstallCmd() *cobra.Command {
 			}
 
 			cmdio.LogString(ctx, fmt.Sprintf("Databricks CLI completions removed for %s from %s.", shell, displayPath))
+			if shell == libcompletion.Zsh && libcompletion.OhMyZshPluginEnabled(ctx, home) {
+				cmdio.LogString(ctx, "Remove databricks from the plugins=(...) array in your .zshrc.")
+			}
 			return nil
 		},
 	}
 	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Skip confirmation prompt")
+	cmd.Flags().BoolVar(&all, "all", false, "Uninstall completions for every supported shell")
 	addShellFlag(cmd, &shellFlag)
+	system.register(cmd, "Remove")
+	cmd.MarkFlagsMutuallyExclusive("all", "shell")
+	cmd.MarkFlagsMutuallyExclusive("all", "system")
 	return cmd
 }
@@ -0,0 +1,99 @@
+package completion
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/databricks/cli/libs/cmdio"
+	libcompletion "github.com/databricks/cli/libs/completion"
+	"github.com/spf13/cobra"
+)
+
+// uninstallAll removes the completions installed by the CLI for every
+// supported shell, whether or not the shell is detected, and the cached
+// completion scripts. It confirms once for all shells and keeps going if
+// removing the completions for one of them fails.
+func uninstallAll(cmd *cobra.Command, home string, autoApprove bool) error {
+	ctx := cmd.Context()
+
+	var results []*installResult
+	var pending []*installResult
+	for _, shell := range libcompletion.AllShells {
+		r := &installResult{
+			Shell: shell,
+			File:  filepath.ToSlash(libcompletion.TargetFilePath(ctx, shell, home)),
+		}
+		results = append(results, r)
+
+		status, err := libcompletion.Status(ctx, shell, home)
+		switch {
+		case err != nil:
+			r.Result = "failed: " + err.Error()
+		case status.Method == "marker":
+			pending = append(pending, r)
+		case status.Installed:
+			r.Result = "skipped (external)"
+		default:
+			r.Result = "not installed"
+		}
+	}
+
+	if len(pending) > 0 && !autoApprove {
+		if !cmdio.IsPromptSupported(ctx) {
+			return errors.New("use --auto-approve to skip the confirmation prompt, or run 'databricks completion status' to preview the detected shell and target file")
+		}
+		cmdio.LogString(ctx, "Completions will be removed for:")
+		for _, r := range pending {
+			cmdio.LogString(ctx, fmt.Sprintf("  %s: %s", r.Shell.DisplayName(), r.File))
+		}
+		confirmed, err := cmdio.AskYesOrNo(ctx, "Proceed?")
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	for _, r := range pending {
+		_, wasInstalled, err := libcompletion.Uninstall(ctx, r.Shell, home)
+		switch {
+		case err != nil:
+			r.Result = "failed: " + err.Error()
+		case wasInstalled:
+			r.Result = "removed"
+		default:
+			r.Result = "not installed"
+		}
+	}
+
+	// Caches can be left behind by shims that were removed by hand.
+	removedCaches, err := libcompletion.RemoveCaches(home)
+	if err != nil {
+		return err
+	}
+
+	err = cmdio.RenderWithTemplate(ctx, results, "Shell	File	Result", installResultsTemplate)
+	if err != nil {
+		return err
+	}
+	if removedCaches {
+		cmdio.LogString(ctx, "Removed the completion caches.")
+	}
+
+	var failed []string
+	for _, r := range results {
+		if strings.HasPrefix(r.Result, "failed") {
+			failed = append(failed, string(r.Shell))
+		}
+		if r.Shell == libcompletion.Zsh && r.Result == "removed" && libcompletion.OhMyZshPluginEnabled(ctx, home) {
+			cmdio.LogString(ctx, "Remove databricks from the plugins=(...) array in your .zshrc.")
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to uninstall completions for %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
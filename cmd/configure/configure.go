@@ -1,25 +1,89 @@
 package configure
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/databricks/cli/libs/auth"
 	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/cli/libs/databrickscfg"
 	"github.com/databricks/cli/libs/databrickscfg/cfgpickers"
 	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
 	"github.com/databricks/databricks-sdk-go"
 	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/config/experimental/auth/authconv"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
 	"github.com/spf13/cobra"
 )
 
-// patConfigureExtraClearKeys lists non-credential profile keys that should also
-// be cleared when saving a PAT-based profile. Auth credential keys are derived
-// dynamically from config.ConfigAttributes via databrickscfg.AuthCredentialKeys().
-var patConfigureExtraClearKeys = []string{
-	"auth_type",
-	"scopes",
-	"databricks_cli_path",
+// authTypeDatabricksCLI is the auth type of profiles that use the OAuth token
+// of a browser login.
+const authTypeDatabricksCLI = "databricks-cli"
+
+// oauthTimeout is how long to wait for the user to log in in the browser.
+const oauthTimeout = 1 * time.Hour
+
+// promptForHost asks the user for the host of the workspace and sets it in
+// cfg. The host may be entered as any URL of the workspace.
+func promptForHost(ctx context.Context, flags *configureFlags, cfg *config.Config) error {
+	prompt := cmdio.Prompt(ctx)
+	prompt.Label = "Databricks workspace host (https://...)"
+	prompt.AllowEdit = true
+	prompt.Validate = func(input string) error {
+		u, err := auth.ParseHostURL(input)
+		if err != nil {
+			return err
+		}
+		return validateHost(u.Host)
+	}
+	out, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+	u, err := auth.ParseHostURL(out)
+	if err != nil {
+		return err
+	}
+	cfg.Host = u.Host
+	if u.AccountID != "" {
+		cfg.AccountID = u.AccountID
+	}
+	return checkWorkspaceHost(ctx, flags, cfg)
+}
+
+// askForCluster asks the user to pick a cluster of the workspace.
+func askForCluster(ctx context.Context, flags *configureFlags, w *databricks.WorkspaceClient) (string, error) {
+	return cfgpickers.AskForCluster(ctx, w,
+		cfgpickers.WithoutSystemClusters(),
+		cfgpickers.WithSearch(flags.ClusterFilter))
+}
+
+// serverlessComputeID returns the serverless_compute_id to save in the profile.
+func serverlessComputeID(flags *configureFlags) string {
+	if flags.Serverless {
+		return "auto"
+	}
+	return ""
+}
+
+// computeClearKeys returns the compute keys to clear from the profile.
+// Cluster and serverless are mutually exclusive, so setting one of them
+// clears the other. If neither is set, both are preserved.
+func computeClearKeys(flags *configureFlags, cfg *config.Config) []string {
+	switch {
+	case flags.Serverless:
+		return []string{"cluster_id"}
+	case cfg.ClusterID != "":
+		// The cluster is set with --configure-cluster or in the environment.
+		return []string{"serverless_compute_id"}
+	default:
+		return nil
+	}
 }
 
 func configureInteractive(cmd *cobra.Command, flags *configureFlags, cfg *config.Config) error {
@@ -27,20 +91,23 @@ func configureInteractive(cmd *cobra.Command, flags *configureFlags, cfg *config
 
 	// Ask user to specify the host if not already set.
 	if cfg.Host == "" {
-		prompt := cmdio.Prompt(ctx)
-		prompt.Label = "Databricks workspace host (https://...)"
-		prompt.AllowEdit = true
-		prompt.Validate = func(input string) error {
-			normalized := normalizeHost(input)
-			return validateHost(normalized)
-		}
-		out, err := prompt.Run()
+		err := promptForHost(ctx, flags, cfg)
 		if err != nil {
 			return err
 		}
-		cfg.Host = normalizeHost(out)
 	}
 
+	err := offerExistingProfile(cmd, cfg)
+	if err != nil {
+		return err
+	}
+
+	err = confirmOverwrite(cmd, flags, cfg)
+	if err != nil {
+		return err
+	}
+	ctx = cmd.Context()
+
 	// Ask user to specify the token is not already set.
 	if cfg.Token == "" {
 		prompt := cmdio.Prompt(ctx)
@@ -50,7 +117,12 @@ func configureInteractive(cmd *cobra.Command, flags *configureFlags, cfg *config
 		if err != nil {
 			return err
 		}
-		cfg.Token = out
+		cfg.Token = strings.TrimSpace(out)
+	}
+
+	err = checkToken(ctx, flags, cfg)
+	if err != nil {
+		return err
 	}
 
 	// Ask user to specify a cluster if not already set.
@@ -63,7 +135,7 @@ func configureInteractive(cmd *cobra.Command, flags *configureFlags, cfg *config
 		if err != nil {
 			return err
 		}
-		clusterID, err := cfgpickers.AskForCluster(cmd.Context(), w, cfgpickers.WithoutSystemClusters())
+		clusterID, err := askForCluster(ctx, flags, w)
 		if err != nil {
 			return err
 		}
@@ -78,20 +150,114 @@ func configureNonInteractive(cmd *cobra.Command, flags *configureFlags, cfg *con
 		return errors.New("host must be set in non-interactive mode")
 	}
 
+	err := confirmOverwrite(cmd, flags, cfg)
+	if err != nil {
+		return err
+	}
+
 	// Check presence of cluster ID before reading token to fail fast.
 	if flags.ConfigureCluster && cfg.ClusterID == "" {
 		return errors.New("cluster ID must be set in non-interactive mode")
 	}
 
-	// Read token from stdin if not already set.
+	// Read token from stdin if not already set with --token-file or the
+	// DATABRICKS_TOKEN environment variable.
 	if cfg.Token == "" {
-		_, err := fmt.Fscanf(cmd.InOrStdin(), "%s\n", &cfg.Token)
+		token, err := readToken(cmd.InOrStdin(), "stdin")
 		if err != nil {
 			return err
 		}
+		cfg.Token = token
 	}
 
-	return nil
+	return checkToken(cmd.Context(), flags, cfg)
+}
+
+// configureOAuth logs the user in to the workspace in the browser and saves a
+// profile that authenticates with the OAuth token of the login, like
+// "databricks auth login" does.
+func configureOAuth(cmd *cobra.Command, flags *configureFlags, cfg *config.Config) error {
+	ctx := cmd.Context()
+	if !cmdio.IsPromptSupported(ctx) {
+		return errors.New("--oauth requires an interactive terminal to log in with a browser. " +
+			"To use OAuth in non-interactive mode, authenticate as a service principal by setting " +
+			"the DATABRICKS_HOST, DATABRICKS_CLIENT_ID and DATABRICKS_CLIENT_SECRET environment variables")
+	}
+
+	if cfg.Host == "" {
+		err := promptForHost(ctx, flags, cfg)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := confirmOverwrite(cmd, flags, cfg)
+	if err != nil {
+		return err
+	}
+	ctx = cmd.Context()
+
+	authArguments := &auth.AuthArguments{
+		Host:    cfg.Host,
+		Profile: cfg.Profile,
+	}
+	oauthArgument, err := authArguments.ToOAuthArgument()
+	if err != nil {
+		return err
+	}
+	persistentAuth, err := u2m.NewPersistentAuth(ctx,
+		u2m.WithOAuthArgument(oauthArgument),
+		u2m.WithBrowser(auth.BrowserFunc(ctx)),
+		auth.WithTokenTimes(ctx, auth.TokenLogin),
+	)
+	if err != nil {
+		return err
+	}
+	defer persistentAuth.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, oauthTimeout)
+	defer cancel()
+
+	if err := persistentAuth.Challenge(); err != nil {
+		return auth.DiagnoseHostError(ctx, cfg.Host, err)
+	}
+
+	// Clear the credentials of other auth types, such as a personal access
+	// token, to prevent multi-auth conflicts in the profile.
+	clearKeys := databrickscfg.OtherCredentialKeys(authTypeDatabricksCLI)
+	clearKeys = append(clearKeys, "experimental_is_unified_host")
+
+	// The profile isn't saved yet, so list the clusters with the token of
+	// the login.
+	if flags.ConfigureCluster && cfg.ClusterID == "" {
+		w, err := databricks.NewWorkspaceClient(&databricks.Config{
+			Host:        cfg.Host,
+			Credentials: config.NewTokenSourceStrategy("login-token", authconv.AuthTokenSource(persistentAuth)),
+		})
+		if err != nil {
+			return err
+		}
+		cfg.ClusterID, err = askForCluster(ctx, flags, w)
+		if err != nil {
+			return err
+		}
+	}
+	clearKeys = append(clearKeys, computeClearKeys(flags, cfg)...)
+
+	profileCfg := &config.Config{
+		Profile:             cfg.Profile,
+		Host:                cfg.Host,
+		AuthType:            authTypeDatabricksCLI,
+		ClusterID:           cfg.ClusterID,
+		ServerlessComputeID: serverlessComputeID(flags),
+		ConfigFile:          env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
+	}
+	saved, err := saveProfile(ctx, profileCfg, clearKeys...)
+	if err != nil {
+		return err
+	}
+	auth.WarnShadowingEnvVars(ctx, saved.Profile, profileCfg)
+	return renderSavedProfile(ctx, flags, saved)
 }
 
 func newConfigureCommand() *cobra.Command {
@@ -103,8 +269,34 @@ func newConfigureCommand() *cobra.Command {
 This command adds a profile to your ~/.databrickscfg file.
 You can write to a different file by setting the DATABRICKS_CONFIG_FILE environment variable.
 
-If this command is invoked in non-interactive mode, it will read the token from stdin.
+If this command is invoked in non-interactive mode, it will read the token from
+the DATABRICKS_TOKEN environment variable or, if that isn't set, from stdin.
+Use --token-file to read the token from a file instead. Use "--token-file -" to
+read it from stdin explicitly.
 The host must be specified with the --host flag or the DATABRICKS_HOST environment variable.
+Use --from-url instead of --host to specify the host with a workspace URL that is copied
+from the browser, such as https://adb-1234.5.azuredatabricks.net/?o=1234#job/567.
+
+Before saving the profile, this command checks that the workspace accepts the token.
+Use --skip-validation to save the token without checking it.
+
+Account console hosts are rejected, because account-level APIs require OAuth.
+Use "databricks auth login" to log in to an account instead.
+
+If the profile already exists and points at a different host, you are asked to
+confirm that it should be overwritten, or to save under a different profile name
+instead. Use --no-overwrite to fail instead of updating such a profile.
+
+In interactive mode without --profile, if other profiles already point at the
+host, you are asked whether to update one of them instead of creating a new one.
+
+After saving, this command prints the profile, the config file and the keys that
+were written and cleared. Use "--output json" to print them as JSON, and --quiet
+to print nothing but errors.
+
+With --oauth, this command logs you in with your browser instead of asking for a
+personal access token, and configures the profile to use OAuth. This requires an
+interactive terminal.
 		`,
 	}
 
@@ -112,6 +304,11 @@ The host must be specified with the --host flag or the DATABRICKS_HOST environme
 	flags.Register(cmd)
 
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		// Cluster and serverless are mutually exclusive.
+		if flags.ConfigureCluster && flags.Serverless {
+			return errors.New("please either configure serverless or cluster, not both")
+		}
+
 		var cfg config.Config
 
 		// Load environment variables, possibly the DEFAULT profile.
@@ -122,7 +319,18 @@ The host must be specified with the --host flag or the DATABRICKS_HOST environme
 
 		// Populate configuration from flags (if set).
 		if flags.Host != "" {
-			cfg.Host = normalizeHost(flags.Host)
+			cfg.Host = databrickscfg.NormalizeHost(flags.Host)
+		}
+		if flags.FromURL != "" {
+			u, err := auth.ParseHostURL(flags.FromURL)
+			if err != nil {
+				return err
+			}
+			cfg.Host = u.Host
+			if u.AccountID != "" {
+				cfg.AccountID = u.AccountID
+			}
+			log.Debugf(cmd.Context(), "Parsed %s as a host on %s", cfg.Host, u.Cloud)
 		}
 		if flags.Profile != "" {
 			cfg.Profile = flags.Profile
@@ -130,15 +338,36 @@ The host must be specified with the --host flag or the DATABRICKS_HOST environme
 
 		// Normalize and verify that the host is valid (if set).
 		if cfg.Host != "" {
-			cfg.Host = normalizeHost(cfg.Host)
+			cfg.Host = databrickscfg.NormalizeHost(cfg.Host)
 			err = validateHost(cfg.Host)
 			if err != nil {
 				return err
 			}
+			err = checkWorkspaceHost(cmd.Context(), &flags, &cfg)
+			if err != nil {
+				return err
+			}
 		}
 
-		ctx := cmd.Context()
-		if cmdio.IsPromptSupported(ctx) {
+		// A token file takes precedence over the DATABRICKS_TOKEN environment
+		// variable and means that there is no need to ask for the token.
+		if flags.TokenFile != "" {
+			cfg.Token, err = readTokenFile(cmd, flags.TokenFile)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Serverless replaces a cluster that is set in the environment.
+		if flags.Serverless {
+			cfg.ClusterID = ""
+		}
+
+		if flags.OAuth {
+			return configureOAuth(cmd, &flags, &cfg)
+		}
+
+		if cmdio.IsPromptSupported(cmd.Context()) {
 			err = configureInteractive(cmd, &flags, &cfg)
 		} else {
 			err = configureNonInteractive(cmd, &flags, &cfg)
@@ -147,35 +376,50 @@ The host must be specified with the --host flag or the DATABRICKS_HOST environme
 			return err
 		}
 
+		// The context is read after configuring, because confirming an
+		// overwrite updates it.
+		ctx := cmd.Context()
+
 		// Clear the Databricks CLI path in token mode.
 		// This is relevant for OAuth only.
 		cfg.DatabricksCliPath = ""
 
-		// Save profile to config file. PAT-based configure clears all
-		// non-PAT auth credentials and OAuth metadata to prevent
+		// Save profile to config file. PAT-based configure clears the
+		// credentials and settings of all other auth types to prevent
 		// multi-auth conflicts in the profile.
-		clearKeys := append(databrickscfg.AuthCredentialKeys(), patConfigureExtraClearKeys...)
+		patKeys := databrickscfg.CredentialKeysFor("pat")
+		clearKeys := slices.DeleteFunc(databrickscfg.AllAuthRelatedKeys(), func(key string) bool {
+			return slices.Contains(patKeys, key)
+		})
 
-		// Cluster and serverless are mutually exclusive. Clear serverless
-		// when a cluster is being set (via flag or env var).
-		if cfg.ClusterID != "" {
-			clearKeys = append(clearKeys, "serverless_compute_id")
-		}
+		clearKeys = append(clearKeys, computeClearKeys(&flags, &cfg)...)
 
 		// Clear stale unified-host metadata — PAT profiles don't use it,
 		// and leaving it can change HostType() routing.
 		clearKeys = append(clearKeys, "experimental_is_unified_host")
 
-		err = databrickscfg.SaveToProfile(ctx, &config.Config{
-			Profile:    cfg.Profile,
-			Host:       cfg.Host,
-			Token:      cfg.Token,
-			ClusterID:  cfg.ClusterID,
-			ConfigFile: env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
-		}, clearKeys...)
-		return err
+		profileCfg := &config.Config{
+			Profile:             cfg.Profile,
+			Host:                cfg.Host,
+			Token:               cfg.Token,
+			ClusterID:           cfg.ClusterID,
+			ServerlessComputeID: serverlessComputeID(&flags),
+			ConfigFile:          env.Get(ctx, "DATABRICKS_CONFIG_FILE"),
+		}
+		saved, err := saveProfile(ctx, profileCfg, clearKeys...)
+		if err != nil {
+			return err
+		}
+		auth.WarnShadowingEnvVars(ctx, saved.Profile, profileCfg)
+		return renderSavedProfile(ctx, &flags, saved)
 	}
 
+	cmd.AddCommand(newValidateCommand())
+	cmd.AddCommand(newDedupeCommand())
+	cmd.AddCommand(newFixPermissionsCommand())
+	cmd.AddCommand(newSetCommand())
+	cmd.AddCommand(newUnsetCommand())
+	cmd.AddCommand(newListKeysCommand())
 	return cmd
 }
 
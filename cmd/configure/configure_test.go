@@ -8,7 +8,11 @@ import (
 
 	"github.com/databricks/cli/cmd"
 	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"gopkg.in/ini.v1"
 )
 
@@ -55,7 +59,7 @@ func TestDefaultConfigureNoInteractive(t *testing.T) {
 	os.Stdin = inp
 
 	cmd := cmd.New(ctx)
-	cmd.SetArgs([]string{"configure", "--token", "--host", "https://host"})
+	cmd.SetArgs([]string{"configure", "--token", "--skip-validation", "--host", "https://host"})
 
 	err := root.Execute(ctx, cmd)
 	assert.NoError(t, err)
@@ -89,7 +93,7 @@ func TestConfigFileFromEnvNoInteractive(t *testing.T) {
 	os.Stdin = inp
 
 	cmd := cmd.New(ctx)
-	cmd.SetArgs([]string{"configure", "--token", "--host", "https://host"})
+	cmd.SetArgs([]string{"configure", "--token", "--skip-validation", "--host", "https://host"})
 
 	err := root.Execute(ctx, cmd)
 	assert.NoError(t, err)
@@ -129,7 +133,7 @@ func TestEnvVarsConfigureNoInteractive(t *testing.T) {
 	t.Setenv("DATABRICKS_METADATA_SERVICE_URL", "https://metadata")
 
 	cmd := cmd.New(ctx)
-	cmd.SetArgs([]string{"configure", "--token"})
+	cmd.SetArgs([]string{"configure", "--token", "--skip-validation"})
 
 	err := root.Execute(ctx, cmd)
 	assert.NoError(t, err)
@@ -162,7 +166,7 @@ func TestEnvVarsConfigureNoArgsNoInteractive(t *testing.T) {
 	t.Setenv("DATABRICKS_TOKEN", "secret")
 
 	cmd := cmd.New(ctx)
-	cmd.SetArgs([]string{"configure"})
+	cmd.SetArgs([]string{"configure", "--skip-validation"})
 
 	err := root.Execute(ctx, cmd)
 	assert.NoError(t, err)
@@ -191,7 +195,7 @@ func TestCustomProfileConfigureNoInteractive(t *testing.T) {
 	os.Stdin = inp
 
 	cmd := cmd.New(ctx)
-	cmd.SetArgs([]string{"configure", "--token", "--host", "https://host", "--profile", "CUSTOM"})
+	cmd.SetArgs([]string{"configure", "--token", "--skip-validation", "--host", "https://host", "--profile", "CUSTOM"})
 
 	err := root.Execute(ctx, cmd)
 	assert.NoError(t, err)
@@ -208,3 +212,288 @@ func TestCustomProfileConfigureNoInteractive(t *testing.T) {
 	assertKeyValueInSection(t, defaultSection, "host", "https://host")
 	assertKeyValueInSection(t, defaultSection, "token", "token")
 }
+
+func TestOAuthConfigureNoInteractive(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--oauth", "--host", "https://host"})
+
+	err := root.Execute(ctx, cmd)
+	assert.ErrorContains(t, err, "--oauth requires an interactive terminal")
+	assert.ErrorContains(t, err, "DATABRICKS_CLIENT_ID and DATABRICKS_CLIENT_SECRET")
+
+	_, err = os.Stat(cfgPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestServerlessConfigureNoInteractive(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+	inp := getTempFileWithContent(t, tempHomeDir, "token\n")
+	defer inp.Close()
+	oldStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+	os.Stdin = inp
+
+	t.Setenv("DATABRICKS_CLUSTER_ID", "env-cluster")
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--token", "--skip-validation", "--host", "https://host", "--serverless"})
+
+	err := root.Execute(ctx, cmd)
+	assert.NoError(t, err)
+
+	cfg, err := ini.Load(cfgPath)
+	assert.NoError(t, err)
+
+	defaultSection, err := cfg.GetSection("DEFAULT")
+	assert.NoError(t, err)
+
+	assertKeyValueInSection(t, defaultSection, "host", "https://host")
+	assertKeyValueInSection(t, defaultSection, "serverless_compute_id", "auto")
+
+	// Serverless replaces the cluster from the environment.
+	_, err = defaultSection.GetKey("cluster_id")
+	assert.Error(t, err)
+}
+
+func TestServerlessAndClusterConfigure(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--host", "https://host", "--serverless", "--configure-cluster"})
+
+	err := root.Execute(ctx, cmd)
+	assert.EqualError(t, err, "please either configure serverless or cluster, not both")
+
+	_, err = os.Stat(cfgPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestTokenFileConfigureNoInteractive(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+	tokenPath := filepath.Join(tempHomeDir, "token")
+	assert.NoError(t, os.WriteFile(tokenPath, []byte("file-token\r\n"), 0o600))
+
+	// The token file takes precedence over the environment.
+	t.Setenv("DATABRICKS_TOKEN", "env-token")
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--skip-validation", "--host", "https://host", "--token-file", tokenPath})
+
+	err := root.Execute(ctx, cmd)
+	assert.NoError(t, err)
+
+	cfg, err := ini.Load(cfgPath)
+	assert.NoError(t, err)
+
+	defaultSection, err := cfg.GetSection("DEFAULT")
+	assert.NoError(t, err)
+
+	assertKeyValueInSection(t, defaultSection, "host", "https://host")
+	assertKeyValueInSection(t, defaultSection, "token", "file-token")
+}
+
+func TestTokenFileNotFoundConfigureNoInteractive(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	tokenPath := filepath.Join(tempHomeDir, "token")
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--skip-validation", "--host", "https://host", "--token-file", tokenPath})
+
+	err := root.Execute(ctx, cmd)
+	assert.ErrorContains(t, err, "unable to read the token from "+tokenPath)
+}
+
+func TestStdinTokenConfigureNoInteractive(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+	}{
+		{name: "no trailing newline", input: "token"},
+		{name: "CRLF", input: "token\r\n"},
+		{name: "explicit stdin", args: []string{"--token-file", "-"}, input: "token\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			tempHomeDir := setup(t)
+			cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+			inp := getTempFileWithContent(t, tempHomeDir, tt.input)
+			defer inp.Close()
+			oldStdin := os.Stdin
+			t.Cleanup(func() { os.Stdin = oldStdin })
+			os.Stdin = inp
+
+			cmd := cmd.New(ctx)
+			cmd.SetArgs(append([]string{"configure", "--skip-validation", "--host", "https://host"}, tt.args...))
+
+			err := root.Execute(ctx, cmd)
+			assert.NoError(t, err)
+
+			cfg, err := ini.Load(cfgPath)
+			assert.NoError(t, err)
+
+			defaultSection, err := cfg.GetSection("DEFAULT")
+			assert.NoError(t, err)
+
+			assertKeyValueInSection(t, defaultSection, "token", "token")
+		})
+	}
+}
+
+func TestEmptyStdinConfigureNoInteractive(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	inp := getTempFileWithContent(t, tempHomeDir, "")
+	defer inp.Close()
+	oldStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+	os.Stdin = inp
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--skip-validation", "--host", "https://host"})
+
+	err := root.Execute(ctx, cmd)
+	assert.EqualError(t, err, "no token found in stdin")
+}
+
+func TestFromURLConfigureNoInteractive(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+	inp := getTempFileWithContent(t, tempHomeDir, "token\n")
+	defer inp.Close()
+	oldStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+	os.Stdin = inp
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--skip-validation", "--from-url", "https://adb-1234.5.azuredatabricks.net/?o=1234#job/567"})
+
+	err := root.Execute(ctx, cmd)
+	assert.NoError(t, err)
+
+	cfg, err := ini.Load(cfgPath)
+	assert.NoError(t, err)
+
+	defaultSection, err := cfg.GetSection("DEFAULT")
+	assert.NoError(t, err)
+
+	assertKeyValueInSection(t, defaultSection, "host", "https://adb-1234.5.azuredatabricks.net")
+	assertKeyValueInSection(t, defaultSection, "token", "token")
+}
+
+func TestFromURLAccountConsoleConfigure(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--from-url", "https://accounts.cloud.databricks.com/?account_id=abc-123"})
+
+	err := root.Execute(ctx, cmd)
+	assert.ErrorContains(t, err, "databricks auth login --host https://accounts.cloud.databricks.com --account-id abc-123")
+
+	_, err = os.Stat(cfgPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestAccountHostConfigure(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--host", "https://accounts.cloud.databricks.com"})
+
+	err := root.Execute(ctx, cmd)
+	assert.ErrorContains(t, err, "databricks auth login --host https://accounts.cloud.databricks.com --account-id <account-id>")
+
+	_, err = os.Stat(cfgPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestAccountHostConfigureIKnowWhatImDoing(t *testing.T) {
+	ctx := t.Context()
+	tempHomeDir := setup(t)
+	cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+	inp := getTempFileWithContent(t, tempHomeDir, "token\n")
+	defer inp.Close()
+	oldStdin := os.Stdin
+	t.Cleanup(func() { os.Stdin = oldStdin })
+	os.Stdin = inp
+
+	cmd := cmd.New(ctx)
+	cmd.SetArgs([]string{"configure", "--skip-validation", "--i-know-what-im-doing", "--host", "https://accounts.cloud.databricks.com"})
+
+	err := root.Execute(ctx, cmd)
+	assert.NoError(t, err)
+
+	cfg, err := ini.Load(cfgPath)
+	assert.NoError(t, err)
+
+	defaultSection, err := cfg.GetSection("DEFAULT")
+	assert.NoError(t, err)
+
+	assertKeyValueInSection(t, defaultSection, "host", "https://accounts.cloud.databricks.com")
+}
+
+func TestConfiguredHostMatchesProfile(t *testing.T) {
+	tests := []struct {
+		input string
+		host  string
+	}{
+		{"HTTPS://DBC-1234.Cloud.Databricks.com", "https://dbc-1234.cloud.databricks.com"},
+		{"https://adb-1234.5.azuredatabricks.net/aad/auth", "https://adb-1234.5.azuredatabricks.net"},
+		{"  adb-1234.5.azuredatabricks.net/?o=1234  ", "https://adb-1234.5.azuredatabricks.net"},
+		{"https://1234.5.gcp.databricks.com:443", "https://1234.5.gcp.databricks.com"},
+		{"1234.5.GCP.databricks.com:443/", "https://1234.5.gcp.databricks.com"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			ctx := t.Context()
+			tempHomeDir := setup(t)
+			cfgPath := filepath.Join(tempHomeDir, ".databrickscfg")
+			inp := getTempFileWithContent(t, tempHomeDir, "token\n")
+			defer inp.Close()
+			oldStdin := os.Stdin
+			t.Cleanup(func() { os.Stdin = oldStdin })
+			os.Stdin = inp
+
+			cmd := cmd.New(ctx)
+			cmd.SetArgs([]string{"configure", "--skip-validation", "--host", test.input})
+
+			err := root.Execute(ctx, cmd)
+			require.NoError(t, err)
+
+			// The host that configure saves.
+			cfg, err := ini.Load(cfgPath)
+			require.NoError(t, err)
+			assertKeyValueInSection(t, cfg.Section("DEFAULT"), "host", test.host)
+
+			// Resolving a profile from the host in libs/databrickscfg.
+			trace, err := databrickscfg.TraceProfileFromHost(&config.Config{Host: test.input, ConfigFile: cfgPath})
+			require.NoError(t, err)
+			assert.Equal(t, test.host, trace.Host)
+			assert.Equal(t, "DEFAULT", trace.Profile)
+
+			// Matching profiles by host in libs/databrickscfg/profile.
+			profiles, err := profile.FileProfilerImpl{}.LoadProfiles(ctx, profile.WithHost(test.input))
+			require.NoError(t, err)
+			assert.Equal(t, []string{"DEFAULT"}, profiles.Names())
+		})
+	}
+}
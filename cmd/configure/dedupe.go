@@ -0,0 +1,284 @@
+package configure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
+	"github.com/spf13/cobra"
+	"gopkg.in/ini.v1"
+)
+
+// duplicateGroup is a set of profiles that point at the same workspace or
+// account.
+type duplicateGroup struct {
+	// Host is the canonical host of the profiles, followed by the account ID
+	// for account profiles.
+	Host string
+
+	// Profiles holds the names of the profiles in file order.
+	Profiles []string
+
+	// ConflictingKeys lists the credential keys whose values differ between
+	// the profiles. Such profiles cannot be merged without losing credentials.
+	ConflictingKeys []string
+}
+
+// findDuplicateProfiles groups the profiles in file by canonical host and
+// account ID and returns the groups with more than one profile.
+func findDuplicateProfiles(ctx context.Context, file *config.File) []duplicateGroup {
+	var groups []*duplicateGroup
+	byHost := map[string]*duplicateGroup{}
+	sections := map[string]map[string]string{}
+	for _, section := range file.Sections() {
+		name := section.Name()
+		if name == databrickscfg.SettingsSection {
+			continue
+		}
+		values, err := databrickscfg.ExpandEnv(ctx, section)
+		if err != nil || values["host"] == "" {
+			continue
+		}
+		sections[name] = values
+
+		host := databrickscfg.NormalizeHost(values["host"])
+		if values["account_id"] != "" {
+			host += " (account " + values["account_id"] + ")"
+		}
+		g, ok := byHost[host]
+		if !ok {
+			g = &duplicateGroup{Host: host}
+			byHost[host] = g
+			groups = append(groups, g)
+		}
+		g.Profiles = append(g.Profiles, name)
+	}
+
+	credentialKeys := append(databrickscfg.AuthCredentialKeys(), "auth_type")
+	var result []duplicateGroup
+	for _, g := range groups {
+		if len(g.Profiles) < 2 {
+			continue
+		}
+		first := sections[g.Profiles[0]]
+		for _, key := range credentialKeys {
+			for _, name := range g.Profiles[1:] {
+				if sections[name][key] != first[key] {
+					g.ConflictingKeys = append(g.ConflictingKeys, key)
+					break
+				}
+			}
+		}
+		result = append(result, *g)
+	}
+	return result
+}
+
+// defaultSurvivor returns the profile of g that is kept unless the user picks
+// another one: the default profile if it is part of the group, or the first
+// profile in the file otherwise. The DEFAULT section is always kept, because
+// removing it would clear the keys that other profiles fall back to.
+func defaultSurvivor(g duplicateGroup, defaultProfile string) string {
+	if slices.Contains(g.Profiles, ini.DefaultSection) {
+		return ini.DefaultSection
+	}
+	if slices.Contains(g.Profiles, defaultProfile) {
+		return defaultProfile
+	}
+	return g.Profiles[0]
+}
+
+func quoteNames(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func newDedupeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dedupe",
+		Short: "Merge profiles in ~/.databrickscfg that point at the same workspace",
+		Long: `Merge profiles in ~/.databrickscfg that point at the same workspace.
+
+Profiles are grouped by host, and by account ID for account profiles. For
+every group with more than one profile you pick the profile to keep; the
+other profiles are deleted, or commented out if --soft is specified. Cached
+OAuth tokens of the removed profiles are moved to the profile that is kept,
+unless it has a cached token of its own.
+
+Profiles whose credentials differ are never merged, because the credentials
+of the removed profiles would be lost. The DEFAULT section is never removed:
+if it is part of a group, it is the profile that is kept.
+
+Use --dry-run to see the changes without making them. In a non-interactive
+environment --auto-approve is required; it keeps the default profile of each
+group, or the first profile in the file if the default is not part of it.
+
+You can use a different file by setting the DATABRICKS_CONFIG_FILE environment variable.`,
+		Args: root.NoArgs,
+	}
+
+	var autoApprove bool
+	var dryRun bool
+	var soft bool
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Keep the default profile of each group without prompting")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the changes without making them")
+	cmd.Flags().BoolVar(&soft, "soft", false, "Comment out the removed profiles instead of deleting them")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		path, err := configFilePath(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Opening the token cache creates it, so don't open it for a dry run.
+		var tokenCache cache.TokenCache
+		if !dryRun {
+			fileCache, err := cache.NewFileTokenCache()
+			if err != nil {
+				return fmt.Errorf("failed to open token cache, please check if the file version is up-to-date and that the file is not corrupted: %w", err)
+			}
+			tokenCache = auth.RecordTokenTimes(ctx, fileCache, nil, auth.TokenRefresh)
+		}
+
+		return runDedupe(ctx, dedupeArgs{
+			autoApprove:    autoApprove,
+			dryRun:         dryRun,
+			soft:           soft,
+			tokenCache:     tokenCache,
+			configFilePath: path,
+		})
+	}
+
+	return cmd
+}
+
+type dedupeArgs struct {
+	autoApprove    bool
+	dryRun         bool
+	soft           bool
+	tokenCache     cache.TokenCache
+	configFilePath string
+}
+
+func runDedupe(ctx context.Context, args dedupeArgs) error {
+	file, err := config.LoadFile(args.configFilePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w at %s", profile.ErrNoConfiguration, args.configFilePath)
+	}
+	if err != nil {
+		return fmt.Errorf("cannot load config file %s: %w", args.configFilePath, err)
+	}
+
+	groups := findDuplicateProfiles(ctx, file)
+	if len(groups) == 0 {
+		cmdio.LogString(ctx, "No duplicate profiles found in "+args.configFilePath)
+		return nil
+	}
+
+	interactive := !args.dryRun && !args.autoApprove
+	if interactive && !cmdio.IsPromptSupported(ctx) {
+		return errors.New("please specify --auto-approve to keep the default profile of each group in non-interactive mode")
+	}
+
+	verb, done := "delete", "deleted"
+	if args.soft {
+		verb, done = "comment out", "commented out"
+	}
+	defaultProfile := databrickscfg.GetDefaultProfileFrom(file)
+	for _, g := range groups {
+		if len(g.ConflictingKeys) > 0 {
+			cmdio.LogString(ctx, fmt.Sprintf("Profiles %s point at %s but cannot be merged because their credentials differ (%s).", quoteNames(g.Profiles), g.Host, strings.Join(g.ConflictingKeys, ", ")))
+			continue
+		}
+
+		survivor := defaultSurvivor(g, defaultProfile)
+		if interactive && survivor != ini.DefaultSection {
+			survivor, err = selectSurvivor(ctx, g)
+			if err != nil {
+				return err
+			}
+		}
+		removed := slices.DeleteFunc(slices.Clone(g.Profiles), func(name string) bool { return name == survivor })
+
+		if args.dryRun {
+			cmdio.LogString(ctx, fmt.Sprintf("Would keep %q and %s %s (%s).", survivor, verb, quoteNames(removed), g.Host))
+			continue
+		}
+
+		err = mergeProfiles(ctx, args, survivor, removed)
+		if err != nil {
+			return err
+		}
+		if slices.Contains(removed, databrickscfg.GetConfiguredDefaultProfileFrom(file)) {
+			err = databrickscfg.SetDefaultProfile(ctx, survivor, args.configFilePath)
+			if err != nil {
+				return fmt.Errorf("profiles merged, but failed to make %q the default profile: %w", survivor, err)
+			}
+		}
+		cmdio.LogString(ctx, fmt.Sprintf("Kept %q and %s %s (%s).", survivor, done, quoteNames(removed), g.Host))
+	}
+	return nil
+}
+
+// selectSurvivor asks the user which profile of g to keep.
+func selectSurvivor(ctx context.Context, g duplicateGroup) (string, error) {
+	profiles := make(profile.Profiles, len(g.Profiles))
+	for i, name := range g.Profiles {
+		profiles[i] = profile.Profile{Name: name, Host: g.Host}
+	}
+	return profile.SelectProfile(ctx, profile.SelectConfig{
+		Label:            "Select the profile to keep for " + g.Host,
+		Profiles:         profiles,
+		ActiveTemplate:   `▸ {{.PaddedName | bold}}`,
+		InactiveTemplate: `  {{.PaddedName}}`,
+		SelectedTemplate: `{{ "Keeping profile" | faint }}: {{ .Name | bold }}`,
+	})
+}
+
+// mergeProfiles removes the profiles in removed from the config file and
+// moves their cached OAuth tokens to survivor. Host-keyed tokens are shared by
+// all profiles of the group and are left as they are.
+func mergeProfiles(ctx context.Context, args dedupeArgs, survivor string, removed []string) error {
+	for _, name := range removed {
+		token, err := args.tokenCache.Lookup(name)
+		if err != nil && !errors.Is(err, cache.ErrNotFound) {
+			return fmt.Errorf("failed to read the cached token of profile %q: %w", name, err)
+		}
+		if token != nil {
+			_, err := args.tokenCache.Lookup(survivor)
+			if errors.Is(err, cache.ErrNotFound) {
+				err = args.tokenCache.Store(survivor, token)
+			}
+			if err != nil {
+				return fmt.Errorf("failed to move the cached token of profile %q to %q: %w", name, survivor, err)
+			}
+			if err := args.tokenCache.Store(name, nil); err != nil {
+				return fmt.Errorf("failed to delete the cached token of profile %q: %w", name, err)
+			}
+		}
+
+		if args.soft {
+			err = databrickscfg.CommentOutProfile(ctx, name, args.configFilePath)
+		} else {
+			err = databrickscfg.DeleteProfile(ctx, name, args.configFilePath)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to remove profile %q: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package configure
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+type mapTokenCache map[string]*oauth2.Token
+
+func (c mapTokenCache) Lookup(key string) (*oauth2.Token, error) {
+	token, ok := c[key]
+	if !ok {
+		return nil, cache.ErrNotFound
+	}
+	return token, nil
+}
+
+func (c mapTokenCache) Store(key string, t *oauth2.Token) error {
+	if t == nil {
+		delete(c, key)
+	} else {
+		c[key] = t
+	}
+	return nil
+}
+
+const dedupeTestConfig = `[dev]
+host      = https://dev.cloud.databricks.com
+auth_type = databricks-cli
+
+[dev2]
+host      = https://DEV.cloud.databricks.com/
+auth_type = databricks-cli
+
+[prod]
+host  = https://prod.cloud.databricks.com
+token = prod-token
+
+[prod-old]
+host  = https://prod.cloud.databricks.com
+token = other-token
+
+[account]
+host       = https://accounts.cloud.databricks.com
+account_id = abc
+
+[other-account]
+host       = https://accounts.cloud.databricks.com
+account_id = def
+
+[dev-old]
+host      = https://dev.cloud.databricks.com
+auth_type = databricks-cli
+
+[__settings__]
+default_profile = dev2
+`
+
+func writeDedupeTestConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(dedupeTestConfig), 0o600))
+	return path
+}
+
+func TestFindDuplicateProfiles(t *testing.T) {
+	file, err := config.LoadFile(writeDedupeTestConfig(t))
+	require.NoError(t, err)
+
+	groups := findDuplicateProfiles(t.Context(), file)
+	assert.Equal(t, []duplicateGroup{
+		{
+			Host:     "https://dev.cloud.databricks.com",
+			Profiles: []string{"dev", "dev2", "dev-old"},
+		},
+		{
+			Host:            "https://prod.cloud.databricks.com",
+			Profiles:        []string{"prod", "prod-old"},
+			ConflictingKeys: []string{"token"},
+		},
+	}, groups)
+}
+
+func TestRunDedupe(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	path := writeDedupeTestConfig(t)
+	tokenCache := mapTokenCache{
+		"dev":                              {AccessToken: "dev-token"},
+		"dev-old":                          {AccessToken: "dev-old-token"},
+		"https://dev.cloud.databricks.com": {AccessToken: "host-token"},
+	}
+
+	err := runDedupe(ctx, dedupeArgs{
+		autoApprove:    true,
+		tokenCache:     tokenCache,
+		configFilePath: path,
+	})
+	require.NoError(t, err)
+
+	// The configured default profile is kept and the conflicting profiles
+	// are left alone.
+	file, err := config.LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEFAULT", "dev2", "prod", "prod-old", "account", "other-account", "__settings__"}, file.SectionStrings())
+
+	// The token of the first removed profile moves to the survivor. Host-keyed
+	// tokens are left as they are.
+	assert.Equal(t, mapTokenCache{
+		"dev2":                             {AccessToken: "dev-token"},
+		"https://dev.cloud.databricks.com": {AccessToken: "host-token"},
+	}, tokenCache)
+}
+
+func TestRunDedupeKeepsDefaultSection(t *testing.T) {
+	ctx, tst := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+	defer tst.Done()
+	go func() { _, _ = io.Copy(io.Discard, tst.Stderr) }()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`[DEFAULT]
+host = https://dev.cloud.databricks.com
+
+[dev]
+host = https://dev.cloud.databricks.com
+
+[__settings__]
+default_profile = dev
+`), 0o600))
+
+	// The DEFAULT section is kept without prompting, even though another
+	// profile of the group is the default.
+	err := runDedupe(ctx, dedupeArgs{
+		tokenCache:     mapTokenCache{},
+		configFilePath: path,
+	})
+	require.NoError(t, err)
+
+	file, err := config.LoadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEFAULT", "__settings__"}, file.SectionStrings())
+	assert.Equal(t, "https://dev.cloud.databricks.com", file.Section("DEFAULT").Key("host").Value())
+	assert.Equal(t, "DEFAULT", file.Section("__settings__").Key("default_profile").Value())
+}
+
+func TestRunDedupeDryRun(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	path := writeDedupeTestConfig(t)
+
+	err := runDedupe(ctx, dedupeArgs{
+		dryRun:         true,
+		configFilePath: path,
+	})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, dedupeTestConfig, string(contents))
+}
+
+func TestRunDedupeNonInteractive(t *testing.T) {
+	ctx := cmdio.MockDiscard(t.Context())
+	path := writeDedupeTestConfig(t)
+
+	err := runDedupe(ctx, dedupeArgs{
+		tokenCache:     mapTokenCache{},
+		configFilePath: path,
+	})
+	assert.ErrorContains(t, err, "please specify --auto-approve")
+}
@@ -0,0 +1,45 @@
+package configure
+
+import (
+	"fmt"
+
+	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/spf13/cobra"
+)
+
+func newFixPermissionsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fix-permissions",
+		Short: "Restrict access to ~/.databrickscfg to its owner",
+		Long: `Restrict access to ~/.databrickscfg to its owner.
+
+The config file often contains personal access tokens and other secrets. This
+command removes the permissions that let other users read or modify it. On
+Windows, where access to files is not controlled by mode bits, it does nothing.
+
+You can use a different file by setting the DATABRICKS_CONFIG_FILE environment variable.`,
+		Args: root.NoArgs,
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		path, err := configFilePath(ctx)
+		if err != nil {
+			return err
+		}
+		path, changed, err := databrickscfg.FixPermissions(ctx, path)
+		if err != nil {
+			return err
+		}
+		if !changed {
+			cmdio.LogString(ctx, fmt.Sprintf("Access to %s is already restricted to its owner.", path))
+			return nil
+		}
+		cmdio.LogString(ctx, fmt.Sprintf("Restricted access to %s to its owner.", path))
+		return nil
+	}
+
+	return cmd
+}
@@ -8,18 +8,56 @@ type configureFlags struct {
 	Host    string
 	Profile string
 
+	// Workspace URL to extract the host from.
+	FromURL string
+
 	// Flag to request a prompt for cluster configuration.
 	ConfigureCluster bool
+
+	// Text to narrow down the clusters to choose from.
+	ClusterFilter string
+
+	// Flag to configure the profile to use serverless compute.
+	Serverless bool
+
+	// Flag to log in with OAuth in the browser instead of using a token.
+	OAuth bool
+
+	// Path of a file to read the token from, or "-" for stdin.
+	TokenFile string
+
+	// Flag to fail instead of updating a profile that points at another host.
+	NoOverwrite bool
+
+	// Flag to accept account console hosts, for example for basic auth.
+	IKnowWhatImDoing bool
+
+	// Flag to save the token without checking it against the workspace.
+	SkipValidation bool
+
+	// Flag to print nothing but errors.
+	Quiet bool
 }
 
 // Register flags with command.
 func (f *configureFlags) Register(cmd *cobra.Command) {
 	cmd.Flags().StringVar(&f.Host, "host", "", "Databricks workspace host.")
+	cmd.Flags().StringVar(&f.FromURL, "from-url", "", "Workspace URL copied from the browser to extract the host from.")
 	cmd.Flags().StringVar(&f.Profile, "profile", "DEFAULT", "Name for the connection profile to configure.")
 	cmd.Flags().BoolVar(&f.ConfigureCluster, "configure-cluster", false, "Prompts to configure cluster")
+	cmd.Flags().BoolVar(&f.Serverless, "serverless", false, "Configure the profile to use serverless compute")
+	cmd.Flags().StringVar(&f.ClusterFilter, "cluster-filter", "", "Only list clusters whose name contains this text when prompting for a cluster")
+	cmd.Flags().BoolVar(&f.NoOverwrite, "no-overwrite", false, "Fail instead of updating an existing profile that points at a different host")
+	cmd.Flags().StringVar(&f.TokenFile, "token-file", "", "Read the token from this file, or from stdin if it is -")
+	cmd.Flags().BoolVar(&f.IKnowWhatImDoing, "i-know-what-im-doing", false, "Configure the profile even if the host is an account console")
+	cmd.Flags().BoolVar(&f.SkipValidation, "skip-validation", false, "Save the token without checking that the workspace accepts it")
+	cmd.Flags().BoolVarP(&f.Quiet, "quiet", "q", false, "Don't print anything but errors")
+	cmd.Flags().BoolVar(&f.OAuth, "oauth", false, "Log in with OAuth in the browser instead of using a personal access token")
 
 	// Include token flag for compatibility with the legacy CLI.
-	// It doesn't actually do anything because we always use PATs.
+	// It doesn't actually do anything because PATs are the default.
 	cmd.Flags().Bool("token", true, "Configure using Databricks Personal Access Token")
 	cmd.Flags().MarkHidden("token")
+
+	cmd.MarkFlagsMutuallyExclusive("host", "from-url")
 }
@@ -1,33 +1,15 @@
 package configure
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"net/url"
-	"strings"
-)
-
-// normalizeHost ensures a https:// scheme is present and returns only scheme
-// and host, consistent with the normalizeHost in libs/databrickscfg/host.go.
-func normalizeHost(input string) string {
-	input = strings.TrimSpace(input)
-	u, err := url.Parse(input)
-	if err != nil {
-		return input
-	}
-
-	if u.Scheme != "https" && u.Scheme != "http" {
-		u, err = url.Parse("https://" + input)
-		if err != nil {
-			return input
-		}
-	}
 
-	if u.Host == "" {
-		return input
-	}
-
-	return (&url.URL{Scheme: u.Scheme, Host: u.Host}).String()
-}
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
+)
 
 func validateHost(s string) error {
 	u, err := url.Parse(s)
@@ -42,3 +24,41 @@ func validateHost(s string) error {
 	}
 	return nil
 }
+
+// checkWorkspaceHost returns an error if the host of cfg is an account
+// console or a unified host. Account-level APIs don't accept personal access
+// tokens, and --oauth only logs in to workspaces, so the error shows the
+// command to log in to the account with OAuth instead. With
+// --i-know-what-im-doing, for example to set up basic auth for an account, any
+// host is accepted.
+func checkWorkspaceHost(ctx context.Context, flags *configureFlags, cfg *config.Config) error {
+	if flags.IKnowWhatImDoing {
+		return nil
+	}
+
+	accountID := cfg.AccountID
+	if accountID == "" {
+		accountID = "<account-id>"
+	}
+
+	var arg u2m.OAuthArgument
+	var err error
+	switch cfg.HostType() {
+	case config.AccountHost:
+		arg, err = u2m.NewProfileAccountOAuthArgument(cfg.Host, accountID, "")
+	case config.UnifiedHost:
+		arg, err = u2m.NewProfileUnifiedOAuthArgument(cfg.Host, accountID, "")
+	default:
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	login := auth.BuildLoginCommand(ctx, "", arg, nil)
+	if flags.OAuth {
+		return fmt.Errorf("%s is an account host. databricks configure --oauth only logs in to workspaces. "+
+			"To log in to the account, run: %s", cfg.Host, login)
+	}
+	return fmt.Errorf("%s is an account host. Account-level APIs don't accept personal access tokens "+
+		"and require OAuth. To log in to the account, run: %s", cfg.Host, login)
+}
@@ -3,49 +3,10 @@ package configure
 import (
 	"testing"
 
+	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestNormalizeHost(t *testing.T) {
-	tests := []struct {
-		input    string
-		expected string
-	}{
-		// Empty input
-		{"", ""},
-		{"   ", ""},
-
-		// Already has https://
-		{"https://example.databricks.com", "https://example.databricks.com"},
-		{"https://example.databricks.com/", "https://example.databricks.com"},
-
-		// Missing protocol (should add https://)
-		{"example.databricks.com", "https://example.databricks.com"},
-		{"  example.databricks.com  ", "https://example.databricks.com"},
-		{"subdomain.example.databricks.com", "https://subdomain.example.databricks.com"},
-		{"example.databricks.com/", "https://example.databricks.com"},
-
-		// Paths, query strings, and anchors are stripped
-		{"https://example.databricks.com/path", "https://example.databricks.com"},
-		{"https://example.databricks.com/path/", "https://example.databricks.com"},
-		{"https://example.databricks.com?query", "https://example.databricks.com"},
-		{"https://example.databricks.com#anchor", "https://example.databricks.com"},
-
-		// Edge cases
-		{"https://", "https://"},
-		{"example.com", "https://example.com"},
-		{"http://localhost:8080", "http://localhost:8080"},
-		{"http://localhost:8080/", "http://localhost:8080"},
-	}
-
-	for _, test := range tests {
-		t.Run(test.input, func(t *testing.T) {
-			result := normalizeHost(test.input)
-			assert.Equal(t, test.expected, result)
-		})
-	}
-}
-
 func TestValidateHost(t *testing.T) {
 	var err error
 
@@ -65,3 +26,67 @@ func TestValidateHost(t *testing.T) {
 	err = validateHost("https://host")
 	assert.NoError(t, err)
 }
+
+func TestCheckWorkspaceHost(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		wantErr string
+	}{
+		{
+			name: "AWS workspace",
+			cfg:  &config.Config{Host: "https://dbc-1234.cloud.databricks.com"},
+		},
+		{
+			name: "Azure workspace",
+			cfg:  &config.Config{Host: "https://adb-1234.5.azuredatabricks.net"},
+		},
+		{
+			name:    "AWS account console",
+			cfg:     &config.Config{Host: "https://accounts.cloud.databricks.com"},
+			wantErr: "databricks auth login --host https://accounts.cloud.databricks.com --account-id <account-id>",
+		},
+		{
+			name:    "Azure account console",
+			cfg:     &config.Config{Host: "https://accounts.azuredatabricks.net", AccountID: "abc-123"},
+			wantErr: "databricks auth login --host https://accounts.azuredatabricks.net --account-id abc-123",
+		},
+		{
+			name:    "GCP account console",
+			cfg:     &config.Config{Host: "https://accounts.gcp.databricks.com"},
+			wantErr: "databricks auth login --host https://accounts.gcp.databricks.com --account-id <account-id>",
+		},
+		{
+			name:    "AWS GovCloud account console",
+			cfg:     &config.Config{Host: "https://accounts-dod.cloud.databricks.us"},
+			wantErr: "databricks auth login --host https://accounts-dod.cloud.databricks.us --account-id <account-id>",
+		},
+		{
+			name:    "unified host",
+			cfg:     &config.Config{Host: "https://unified.cloud.databricks.com", AccountID: "abc-123", Experimental_IsUnifiedHost: true},
+			wantErr: "databricks auth login --host https://unified.cloud.databricks.com --account-id abc-123 --experimental-is-unified-host",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkWorkspaceHost(t.Context(), &configureFlags{}, tt.cfg)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.cfg.Host+" is an account host. Account-level APIs don't accept personal access tokens and require OAuth.")
+			assert.ErrorContains(t, err, "To log in to the account, run: "+tt.wantErr)
+
+			// With --oauth, the error doesn't mention personal access tokens.
+			err = checkWorkspaceHost(t.Context(), &configureFlags{OAuth: true}, tt.cfg)
+			assert.ErrorContains(t, err, tt.cfg.Host+" is an account host. databricks configure --oauth only logs in to workspaces.")
+			assert.NotContains(t, err.Error(), "personal access tokens")
+			assert.ErrorContains(t, err, "To log in to the account, run: "+tt.wantErr)
+
+			// The escape hatch accepts any host.
+			err = checkWorkspaceHost(t.Context(), &configureFlags{IKnowWhatImDoing: true}, tt.cfg)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package configure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+
+	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/ini.v1"
+)
+
+// maskedValue replaces the values of sensitive keys in the output of list-keys.
+const maskedValue = "********"
+
+// settableKeys returns the keys that set and unset accept without --force.
+// The profile and config_file attributes select a profile and can't be set
+// in one.
+func settableKeys() []string {
+	keys := slices.DeleteFunc(profileKeys(), func(key string) bool {
+		return key == "profile" || key == "config_file"
+	})
+	return slices.Concat(keys, extraProfileKeys)
+}
+
+// checkSettableKeys returns an error for the first key that isn't in
+// [settableKeys], unless force is set.
+func checkSettableKeys(keys []string, force bool) error {
+	if force {
+		return nil
+	}
+	known := settableKeys()
+	for _, key := range keys {
+		if !slices.Contains(known, key) {
+			return fmt.Errorf("unknown key %q; use --force to change it anyway", key)
+		}
+	}
+	return nil
+}
+
+// sdkAttribute returns the attribute of [config.ConfigAttributes] with the
+// given name, or nil if there is none.
+func sdkAttribute(name string) *config.ConfigAttribute {
+	for i := range config.ConfigAttributes {
+		if config.ConfigAttributes[i].Name == name {
+			return &config.ConfigAttributes[i]
+		}
+	}
+	return nil
+}
+
+// loadProfileSection loads the named profile from the config file at path,
+// including the profiles of included files. It returns nil if the file or
+// the profile doesn't exist.
+func loadProfileSection(ctx context.Context, path, name string) (*databrickscfg.File, *ini.Section, error) {
+	file, err := databrickscfg.LoadFile(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	section, err := file.GetSection(name)
+	if err != nil {
+		return file, nil, nil
+	}
+	return file, section, nil
+}
+
+// checkEditableProfile returns an error if the named profile is the settings
+// section or is defined in an included file, which configure never changes.
+func checkEditableProfile(ctx context.Context, path, name string) error {
+	if name == databrickscfg.SettingsSection {
+		return fmt.Errorf("profile name %q is reserved for internal use", name)
+	}
+	file, section, err := loadProfileSection(ctx, path, name)
+	if err != nil || section == nil {
+		return err
+	}
+	if source := file.Source(name); source != file.Path() {
+		return fmt.Errorf("profile %s is defined in %s, which is included by %s; edit that file instead", name, source, file.Path())
+	}
+	return nil
+}
+
+func newSetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "set KEY VALUE",
+		Short: "Set a key of a profile",
+		Long: `Set a key of a profile in ~/.databrickscfg.
+
+The other keys of the profile are kept. The profile is created if it doesn't
+exist yet. Only the keys that the CLI knows are accepted; use --force to set
+other keys.
+
+You can write to a different file by setting the DATABRICKS_CONFIG_FILE environment variable.`,
+		Args: root.ExactArgs(2),
+	}
+
+	var profileName string
+	var force bool
+	cmd.Flags().StringVar(&profileName, "profile", "DEFAULT", "Name of the profile to change.")
+	cmd.Flags().BoolVar(&force, "force", false, "Set the key even if the CLI doesn't know it.")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		key, value := args[0], args[1]
+		if err := checkSettableKeys([]string{key}, force); err != nil {
+			return err
+		}
+		if value == "" {
+			return fmt.Errorf("the value of %s is empty; use \"databricks configure unset\" to remove the key", key)
+		}
+
+		path, err := configFilePath(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkEditableProfile(ctx, path, profileName); err != nil {
+			return err
+		}
+
+		// Keys that are not SDK attributes can't be written through
+		// SaveToProfile and are only set in existing profiles.
+		attr := sdkAttribute(key)
+		if attr == nil || key == "profile" || key == "config_file" {
+			return databrickscfg.SetProfileKey(ctx, path, profileName, key, value)
+		}
+
+		cfg := &config.Config{}
+		if err := attr.SetS(cfg, value); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", key, err)
+		}
+		cfg.Profile = profileName
+		cfg.ConfigFile = path
+		return databrickscfg.SaveToProfile(ctx, cfg)
+	}
+
+	return cmd
+}
+
+func newUnsetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset KEY...",
+		Short: "Remove keys from a profile",
+		Long: `Remove keys from a profile in ~/.databrickscfg.
+
+The other keys of the profile are kept. Keys that the profile doesn't have are
+ignored. Only the keys that the CLI knows are accepted; use --force to remove
+other keys.
+
+You can write to a different file by setting the DATABRICKS_CONFIG_FILE environment variable.`,
+		Args: cobra.MinimumNArgs(1),
+	}
+
+	var profileName string
+	var force bool
+	cmd.Flags().StringVar(&profileName, "profile", "DEFAULT", "Name of the profile to change.")
+	cmd.Flags().BoolVar(&force, "force", false, "Remove the keys even if the CLI doesn't know them.")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		if err := checkSettableKeys(args, force); err != nil {
+			return err
+		}
+
+		path, err := configFilePath(ctx)
+		if err != nil {
+			return err
+		}
+		if err := checkEditableProfile(ctx, path, profileName); err != nil {
+			return err
+		}
+		_, section, err := loadProfileSection(ctx, path, profileName)
+		if err != nil {
+			return err
+		}
+		if section == nil {
+			return fmt.Errorf("profile %s not found in %s", profileName, path)
+		}
+
+		return databrickscfg.SaveToProfile(ctx, &config.Config{
+			Profile:    profileName,
+			ConfigFile: path,
+		}, args...)
+	}
+
+	return cmd
+}
+
+type profileKey struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// listProfileKeys returns the keys of the named profile in file order, with
+// references to environment variables expanded and the values of sensitive
+// keys masked.
+func listProfileKeys(ctx context.Context, path, name string) ([]profileKey, error) {
+	_, section, err := loadProfileSection(ctx, path, name)
+	if err != nil {
+		return nil, err
+	}
+	if section == nil {
+		return nil, fmt.Errorf("profile %s not found in %s", name, path)
+	}
+	values, err := databrickscfg.ExpandEnv(ctx, section)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []profileKey{}
+	for _, key := range section.KeyStrings() {
+		value := values[key]
+		if attr := sdkAttribute(key); attr != nil && attr.Sensitive {
+			value = maskedValue
+		}
+		keys = append(keys, profileKey{Key: key, Value: value})
+	}
+	return keys, nil
+}
+
+func newListKeysCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list-keys",
+		Short: "List the keys of a profile",
+		Long: `List the keys of a profile in ~/.databrickscfg.
+
+References to environment variables are expanded. The values of sensitive keys,
+such as tokens and client secrets, are masked.
+
+You can read a different file by setting the DATABRICKS_CONFIG_FILE environment variable.`,
+		Args: root.NoArgs,
+		Annotations: map[string]string{
+			"template": cmdio.Heredoc(`
+			{{range .Keys}}{{.Key}} = {{.Value}}
+			{{end}}`),
+		},
+	}
+
+	var profileName string
+	cmd.Flags().StringVar(&profileName, "profile", "DEFAULT", "Name of the profile to list the keys of.")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		path, err := configFilePath(ctx)
+		if err != nil {
+			return err
+		}
+		keys, err := listProfileKeys(ctx, path, profileName)
+		if err != nil {
+			return err
+		}
+		return cmdio.Render(ctx, struct {
+			Profile string       `json:"profile"`
+			Keys    []profileKey `json:"keys"`
+		}{profileName, keys})
+	}
+
+	return cmd
+}
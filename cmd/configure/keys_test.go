@@ -0,0 +1,134 @@
+package configure
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+// setupKeysConfig writes contents to a config file that the commands use and
+// returns its path.
+func setupKeysConfig(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	t.Setenv("DATABRICKS_CONFIG_FILE", path)
+	return path
+}
+
+func runKeysCommand(t *testing.T, cmd *cobra.Command, args ...string) error {
+	cmd.SetArgs(args)
+	cmd.SetOut(io.Discard)
+	cmd.SetErr(io.Discard)
+	return cmd.ExecuteContext(cmdio.MockDiscard(t.Context()))
+}
+
+func readProfile(t *testing.T, path, name string) map[string]string {
+	file, err := ini.Load(path)
+	require.NoError(t, err)
+	return file.Section(name).KeysHash()
+}
+
+func TestSetKey(t *testing.T) {
+	path := setupKeysConfig(t, "[ci]\nhost = https://ci.cloud.databricks.com\ntoken = abc\n")
+
+	err := runKeysCommand(t, newSetCommand(), "--profile", "ci", "warehouse_id", "1234")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "https://ci.cloud.databricks.com", "token": "abc", "warehouse_id": "1234"}, readProfile(t, path, "ci"))
+}
+
+func TestSetKeyInvalidValue(t *testing.T) {
+	setupKeysConfig(t, "[ci]\nhost = https://ci.cloud.databricks.com\n")
+
+	err := runKeysCommand(t, newSetCommand(), "--profile", "ci", "http_timeout_seconds", "soon")
+	assert.ErrorContains(t, err, "invalid value for http_timeout_seconds")
+}
+
+func TestSetKeyUnknown(t *testing.T) {
+	path := setupKeysConfig(t, "[ci]\nhost = https://ci.cloud.databricks.com\n")
+
+	err := runKeysCommand(t, newSetCommand(), "--profile", "ci", "hots", "https://other")
+	assert.EqualError(t, err, `unknown key "hots"; use --force to change it anyway`)
+
+	err = runKeysCommand(t, newSetCommand(), "--profile", "ci", "--force", "team", "data")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "https://ci.cloud.databricks.com", "team": "data"}, readProfile(t, path, "ci"))
+}
+
+func TestSetKeyIncludedProfile(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "team.cfg"), []byte("[ci]\nhost = https://ci.cloud.databricks.com\n"), 0o600))
+	path := filepath.Join(dir, ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[DEFAULT]\ninclude = team.cfg\n"), 0o600))
+	t.Setenv("DATABRICKS_CONFIG_FILE", path)
+
+	err := runKeysCommand(t, newSetCommand(), "--profile", "ci", "warehouse_id", "1234")
+	assert.ErrorContains(t, err, "edit that file instead")
+}
+
+func TestUnsetKeys(t *testing.T) {
+	path := setupKeysConfig(t, "[ci]\nhost = https://ci.cloud.databricks.com\ntoken = abc\ncluster_id = 123\n")
+
+	err := runKeysCommand(t, newUnsetCommand(), "--profile", "ci", "cluster_id", "warehouse_id")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "https://ci.cloud.databricks.com", "token": "abc"}, readProfile(t, path, "ci"))
+}
+
+func TestUnsetNonexistentKeyIsNoop(t *testing.T) {
+	contents := "[ci]\nhost = https://ci.cloud.databricks.com\n"
+	path := setupKeysConfig(t, contents)
+
+	err := runKeysCommand(t, newUnsetCommand(), "--profile", "ci", "warehouse_id")
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, contents, string(data))
+}
+
+func TestUnsetKeysUnknown(t *testing.T) {
+	path := setupKeysConfig(t, "[ci]\nhost = https://ci.cloud.databricks.com\nteam = data\n")
+
+	err := runKeysCommand(t, newUnsetCommand(), "--profile", "ci", "team")
+	assert.EqualError(t, err, `unknown key "team"; use --force to change it anyway`)
+
+	err = runKeysCommand(t, newUnsetCommand(), "--profile", "ci", "--force", "team")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "https://ci.cloud.databricks.com"}, readProfile(t, path, "ci"))
+}
+
+func TestUnsetKeysProfileNotFound(t *testing.T) {
+	path := setupKeysConfig(t, "[ci]\nhost = https://ci.cloud.databricks.com\n")
+
+	err := runKeysCommand(t, newUnsetCommand(), "--profile", "dev", "warehouse_id")
+	assert.EqualError(t, err, "profile dev not found in "+path)
+}
+
+func TestListProfileKeys(t *testing.T) {
+	path := setupKeysConfig(t, `[ci]
+host          = https://${env:CI_HOST}
+token         = abc
+client_secret = def
+warehouse_id  = 1234
+team          = data
+`)
+	t.Setenv("CI_HOST", "ci.cloud.databricks.com")
+
+	keys, err := listProfileKeys(t.Context(), path, "ci")
+	require.NoError(t, err)
+	assert.Equal(t, []profileKey{
+		{Key: "host", Value: "https://ci.cloud.databricks.com"},
+		{Key: "token", Value: maskedValue},
+		{Key: "client_secret", Value: maskedValue},
+		{Key: "warehouse_id", Value: "1234"},
+		{Key: "team", Value: "data"},
+	}, keys)
+
+	_, err = listProfileKeys(t.Context(), path, "dev")
+	assert.EqualError(t, err, "profile dev not found in "+path)
+}
@@ -0,0 +1,60 @@
+package configure
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+)
+
+// confirmOverwrite checks whether the profile to configure already exists
+// and points at a different host. With --no-overwrite, this is an error.
+// Otherwise interactive sessions are asked to confirm the overwrite or to
+// save under a different profile name instead, and non-interactive sessions
+// update the profile.
+func confirmOverwrite(cmd *cobra.Command, flags *configureFlags, cfg *config.Config) error {
+	ctx := cmd.Context()
+	configFile := env.Get(ctx, "DATABRICKS_CONFIG_FILE")
+	for cfg.Profile != "" {
+		existingHost, err := databrickscfg.GetProfileHost(ctx, cfg.Profile, configFile)
+		if err != nil {
+			return err
+		}
+		if existingHost == "" || databrickscfg.NormalizeHost(existingHost) == cfg.Host {
+			return nil
+		}
+		if flags.NoOverwrite {
+			return fmt.Errorf("profile %s already exists and points at %s; "+
+				"choose another name with --profile or remove --no-overwrite to update it", cfg.Profile, existingHost)
+		}
+		if !cmdio.IsPromptSupported(ctx) {
+			return nil
+		}
+
+		question := fmt.Sprintf("Profile %s already exists and points at %s. Overwrite it with %s?", cfg.Profile, existingHost, cfg.Host)
+		overwrite, err := cmdio.AskYesOrNo(ctx, question)
+		if err != nil {
+			return err
+		}
+		if overwrite {
+			// Don't ask again when the profile is saved.
+			cmd.SetContext(databrickscfg.WithHostChangeConfirmed(ctx))
+			return nil
+		}
+
+		name, err := cmdio.Ask(ctx, "Profile name to save under instead", "")
+		if err != nil {
+			return err
+		}
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return fmt.Errorf("profile %s was not updated: it still points at %s", cfg.Profile, existingHost)
+		}
+		cfg.Profile = name
+	}
+	return nil
+}
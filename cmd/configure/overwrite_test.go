@@ -0,0 +1,102 @@
+package configure
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runConfirmOverwrite runs confirmOverwrite for the profile prod of a config
+// file where prod points at https://old-host. It answers the prompts with
+// input and returns the prompts that were shown.
+func runConfirmOverwrite(t *testing.T, flags *configureFlags, cfg *config.Config, promptSupported bool, input string) (string, error) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[prod]\nhost = https://old-host\ntoken = abc\n"), 0o600))
+	t.Setenv("DATABRICKS_CONFIG_FILE", path)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 3*time.Second)
+	defer cancel()
+	ctx, testIO := cmdio.SetupTest(ctx, cmdio.TestOptions{PromptSupported: promptSupported})
+
+	var prompts bytes.Buffer
+	drained := make(chan struct{})
+	go func() {
+		_, _ = prompts.ReadFrom(testIO.Stderr)
+		close(drained)
+	}()
+	go func() {
+		_, _ = testIO.Stdin.WriteString(input)
+		_ = testIO.Stdin.Flush()
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	err := confirmOverwrite(cmd, flags, cfg)
+
+	testIO.Done()
+	<-drained
+	return prompts.String(), err
+}
+
+func TestConfirmOverwriteSameHost(t *testing.T) {
+	cfg := &config.Config{Profile: "prod", Host: "https://old-host"}
+	prompts, err := runConfirmOverwrite(t, &configureFlags{NoOverwrite: true}, cfg, true, "")
+	require.NoError(t, err)
+	assert.Empty(t, prompts)
+	assert.Equal(t, "prod", cfg.Profile)
+}
+
+func TestConfirmOverwriteNewProfile(t *testing.T) {
+	cfg := &config.Config{Profile: "dev", Host: "https://new-host"}
+	prompts, err := runConfirmOverwrite(t, &configureFlags{NoOverwrite: true}, cfg, true, "")
+	require.NoError(t, err)
+	assert.Empty(t, prompts)
+	assert.Equal(t, "dev", cfg.Profile)
+}
+
+func TestConfirmOverwriteNonInteractive(t *testing.T) {
+	cfg := &config.Config{Profile: "prod", Host: "https://new-host"}
+	prompts, err := runConfirmOverwrite(t, &configureFlags{}, cfg, false, "")
+	require.NoError(t, err)
+	assert.Empty(t, prompts)
+	assert.Equal(t, "prod", cfg.Profile)
+}
+
+func TestConfirmOverwriteNoOverwrite(t *testing.T) {
+	cfg := &config.Config{Profile: "prod", Host: "https://new-host"}
+	_, err := runConfirmOverwrite(t, &configureFlags{NoOverwrite: true}, cfg, false, "")
+	assert.EqualError(t, err, "profile prod already exists and points at https://old-host; "+
+		"choose another name with --profile or remove --no-overwrite to update it")
+}
+
+func TestConfirmOverwriteApproved(t *testing.T) {
+	cfg := &config.Config{Profile: "prod", Host: "https://new-host"}
+	prompts, err := runConfirmOverwrite(t, &configureFlags{}, cfg, true, "y\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Profile prod already exists and points at https://old-host. Overwrite it with https://new-host? [y/n]: ", prompts)
+	assert.Equal(t, "prod", cfg.Profile)
+}
+
+func TestConfirmOverwriteDifferentName(t *testing.T) {
+	cfg := &config.Config{Profile: "prod", Host: "https://new-host"}
+	prompts, err := runConfirmOverwrite(t, &configureFlags{}, cfg, true, "n\nstaging\n")
+	require.NoError(t, err)
+	assert.Equal(t, "Profile prod already exists and points at https://old-host. Overwrite it with https://new-host? [y/n]: "+
+		"Profile name to save under instead: ", prompts)
+	assert.Equal(t, "staging", cfg.Profile)
+}
+
+func TestConfirmOverwriteDeclined(t *testing.T) {
+	cfg := &config.Config{Profile: "prod", Host: "https://new-host"}
+	_, err := runConfirmOverwrite(t, &configureFlags{}, cfg, true, "n\n\n")
+	assert.EqualError(t, err, "profile prod was not updated: it still points at https://old-host")
+}
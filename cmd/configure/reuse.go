@@ -0,0 +1,74 @@
+package configure
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+)
+
+// offerExistingProfile looks for profiles that already point at the host of
+// cfg and asks whether to update one of them instead of cfg.Profile, so that
+// configuring the same workspace twice doesn't create a duplicate profile.
+// Choosing a profile sets cfg.Profile to its name; the keys of the profile
+// other than its credentials, such as cluster_id, are kept when it's saved.
+//
+// Nothing is asked if the profile name was passed with --profile or if
+// cfg.Profile is one of the matches. Profiles of included config files are
+// never offered, because configure only writes to the primary config file.
+func offerExistingProfile(cmd *cobra.Command, cfg *config.Config) error {
+	ctx := cmd.Context()
+	if cmd.Flags().Changed("profile") {
+		return nil
+	}
+
+	profiles, err := profile.GetProfiler(ctx).LoadProfiles(ctx, profile.WithHost(cfg.Host))
+	if errors.Is(err, profile.ErrNoConfiguration) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	profiles = slices.DeleteFunc(profiles, func(p profile.Profile) bool {
+		return p.Path != ""
+	})
+	names := profiles.Names()
+	if len(names) == 0 || slices.Contains(names, cfg.Profile) {
+		return nil
+	}
+
+	if len(profiles) == 1 {
+		question := fmt.Sprintf("Profile %s already points at %s. Update it instead of %s?", names[0], cfg.Host, cfg.Profile)
+		ok, err := cmdio.AskYesOrNo(ctx, question)
+		if err != nil {
+			return err
+		}
+		if ok {
+			cfg.Profile = names[0]
+		}
+		return nil
+	}
+
+	cmdio.LogString(ctx, fmt.Sprintf("Profiles %s already point at %s.", strings.Join(names, ", "), cfg.Host))
+	ok, err := cmdio.AskYesOrNo(ctx, fmt.Sprintf("Update one of them instead of %s?", cfg.Profile))
+	if err != nil || !ok {
+		return err
+	}
+	name, err := profile.SelectProfile(ctx, profile.SelectConfig{
+		Label:            "Select the profile to update",
+		Profiles:         profiles,
+		ActiveTemplate:   `▸ {{.PaddedName | bold}}`,
+		InactiveTemplate: `  {{.PaddedName}}`,
+		SelectedTemplate: `{{ "Updating profile" | faint }}: {{ .Name | bold }}`,
+	})
+	if err != nil {
+		return err
+	}
+	cfg.Profile = name
+	return nil
+}
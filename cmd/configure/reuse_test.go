@@ -0,0 +1,168 @@
+package configure
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runPrompted calls run with cmd in a context that supports prompts and
+// answers them with input. It returns the prompts that were shown.
+func runPrompted(t *testing.T, cmd *cobra.Command, input string, run func(*cobra.Command) error) (string, error) {
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = t.Context()
+	}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+	ctx, testIO := cmdio.SetupTest(ctx, cmdio.TestOptions{PromptSupported: true})
+
+	var prompts bytes.Buffer
+	drained := make(chan struct{})
+	go func() {
+		_, _ = prompts.ReadFrom(testIO.Stderr)
+		close(drained)
+	}()
+	go func() {
+		_, _ = testIO.Stdin.WriteString(input)
+		_ = testIO.Stdin.Flush()
+	}()
+
+	cmd.SetContext(ctx)
+	err := run(cmd)
+
+	testIO.Done()
+	<-drained
+	return prompts.String(), err
+}
+
+// runOfferExistingProfile runs offerExistingProfile against the profiles of
+// an in-memory config file and answers the prompts with input.
+func runOfferExistingProfile(t *testing.T, args []string, cfg *config.Config, input string) (string, error) {
+	ctx := profile.WithProfiler(t.Context(), profile.InMemoryProfiler{
+		Profiles: profile.Profiles{
+			{Name: "dev", Host: "https://dev.cloud.databricks.com", ClusterID: "123"},
+			{Name: "prod", Host: "https://prod.cloud.databricks.com"},
+			{Name: "staging", Host: "https://staging.cloud.databricks.com"},
+			{Name: "staging-old", Host: "https://staging.cloud.databricks.com/"},
+			{Name: "team", Host: "https://team.cloud.databricks.com", Path: "/etc/databricks/team.cfg"},
+		},
+	})
+
+	cmd := &cobra.Command{}
+	var flags configureFlags
+	flags.Register(cmd)
+	require.NoError(t, cmd.ParseFlags(args))
+	cmd.SetContext(ctx)
+	return runPrompted(t, cmd, input, func(cmd *cobra.Command) error {
+		return offerExistingProfile(cmd, cfg)
+	})
+}
+
+func TestOfferExistingProfileAccepted(t *testing.T) {
+	cfg := &config.Config{Profile: "DEFAULT", Host: "https://dev.cloud.databricks.com"}
+	prompts, err := runOfferExistingProfile(t, nil, cfg, "y\n")
+	require.NoError(t, err)
+	assert.Contains(t, prompts, "Profile dev already points at https://dev.cloud.databricks.com. Update it instead of DEFAULT?")
+	assert.Equal(t, "dev", cfg.Profile)
+}
+
+func TestOfferExistingProfileDeclined(t *testing.T) {
+	cfg := &config.Config{Profile: "DEFAULT", Host: "https://dev.cloud.databricks.com"}
+	prompts, err := runOfferExistingProfile(t, nil, cfg, "n\n")
+	require.NoError(t, err)
+	assert.Contains(t, prompts, "Update it instead of DEFAULT?")
+	assert.Equal(t, "DEFAULT", cfg.Profile)
+}
+
+func TestOfferExistingProfileMultipleDeclined(t *testing.T) {
+	cfg := &config.Config{Profile: "DEFAULT", Host: "https://staging.cloud.databricks.com"}
+	prompts, err := runOfferExistingProfile(t, nil, cfg, "n\n")
+	require.NoError(t, err)
+	assert.Contains(t, prompts, "Profiles staging, staging-old already point at https://staging.cloud.databricks.com.")
+	assert.Contains(t, prompts, "Update one of them instead of DEFAULT?")
+	assert.Equal(t, "DEFAULT", cfg.Profile)
+}
+
+func TestOfferExistingProfileNotAsked(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		profile string
+		host    string
+	}{
+		{name: "no match", profile: "DEFAULT", host: "https://other.cloud.databricks.com"},
+		{name: "profile matches", profile: "dev", host: "https://dev.cloud.databricks.com"},
+		{name: "profile flag", args: []string{"--profile", "DEFAULT"}, profile: "DEFAULT", host: "https://dev.cloud.databricks.com"},
+		{name: "included profile", profile: "DEFAULT", host: "https://team.cloud.databricks.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{Profile: tt.profile, Host: tt.host}
+			prompts, err := runOfferExistingProfile(t, tt.args, cfg, "")
+			require.NoError(t, err)
+			assert.Empty(t, prompts)
+			assert.Equal(t, tt.profile, cfg.Profile)
+		})
+	}
+}
+
+func TestConfigureReusesExistingProfile(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  map[string]map[string]string
+	}{
+		{
+			name:  "accepted",
+			input: "y\n",
+			want: map[string]map[string]string{
+				"dev": {"host": "https://dev.cloud.databricks.com", "token": "new-token", "cluster_id": "123"},
+			},
+		},
+		{
+			name:  "declined",
+			input: "n\n",
+			want: map[string]map[string]string{
+				"DEFAULT": {"host": "https://dev.cloud.databricks.com", "token": "new-token"},
+				"dev":     {"host": "https://dev.cloud.databricks.com", "token": "old-token", "cluster_id": "123"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".databrickscfg")
+			contents := "[dev]\nhost = https://dev.cloud.databricks.com\ntoken = old-token\ncluster_id = 123\n"
+			require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+			t.Setenv("DATABRICKS_CONFIG_FILE", path)
+			t.Setenv("DATABRICKS_TOKEN", "new-token")
+
+			cmd := newConfigureCommand()
+			cmd.SetArgs([]string{"--quiet", "--skip-validation", "--host", "https://DEV.cloud.databricks.com/"})
+			_, err := runPrompted(t, cmd, tt.input, func(cmd *cobra.Command) error {
+				return cmd.ExecuteContext(cmd.Context())
+			})
+			require.NoError(t, err)
+
+			got := map[string]map[string]string{}
+			for _, name := range []string{"DEFAULT", "dev"} {
+				if keys := readProfile(t, path, name); len(keys) > 0 {
+					got[name] = keys
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package configure
+
+import (
+	"context"
+	"slices"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// savedProfile describes what configure wrote to the config file.
+type savedProfile struct {
+	ConfigFile  string   `json:"config_file"`
+	Profile     string   `json:"profile"`
+	Host        string   `json:"host"`
+	KeysWritten []string `json:"keys_written"`
+	KeysCleared []string `json:"keys_cleared"`
+}
+
+// savedProfileTemplate renders a [savedProfile] in text output.
+var savedProfileTemplate = cmdio.Heredoc(`
+	Profile {{.Profile}} for {{.Host}} was saved to {{.ConfigFile}}
+	Keys written: {{join .KeysWritten ", "}}
+	{{if .KeysCleared}}Keys cleared: {{join .KeysCleared ", "}}
+	{{end}}`)
+
+// saveProfile saves cfg to its profile with [databrickscfg.SaveToProfile],
+// removing clearKeys from it, and returns what was saved. The keys that are
+// reported as cleared are those that the profile had before and no longer
+// has, so that keys in clearKeys that weren't set are left out.
+func saveProfile(ctx context.Context, cfg *config.Config, clearKeys ...string) (*savedProfile, error) {
+	path, err := configFilePath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, before, err := loadProfileSection(ctx, path, cfg.Profile)
+	if err != nil {
+		return nil, err
+	}
+
+	saved := &savedProfile{
+		ConfigFile:  path,
+		Profile:     cfg.Profile,
+		Host:        cfg.Host,
+		KeysWritten: []string{},
+		KeysCleared: []string{},
+	}
+	for _, attr := range config.ConfigAttributes {
+		if attr.Name == "profile" || attr.Name == "config_file" || attr.IsZero(cfg) {
+			continue
+		}
+		saved.KeysWritten = append(saved.KeysWritten, attr.Name)
+	}
+
+	// SaveToProfile resets the profile name of cfg.
+	err = databrickscfg.SaveToProfile(ctx, cfg, clearKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	_, after, err := loadProfileSection(ctx, path, saved.Profile)
+	if err != nil {
+		return nil, err
+	}
+	if before != nil && after != nil {
+		for _, key := range before.KeyStrings() {
+			if !slices.Contains(after.KeyStrings(), key) {
+				saved.KeysCleared = append(saved.KeysCleared, key)
+			}
+		}
+	}
+	return saved, nil
+}
+
+// renderSavedProfile prints what was saved, unless --quiet is set.
+func renderSavedProfile(ctx context.Context, flags *configureFlags, saved *savedProfile) error {
+	if flags.Quiet {
+		return nil
+	}
+	return cmdio.RenderWithTemplate(ctx, saved, "", savedProfileTemplate)
+}
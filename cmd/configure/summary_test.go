@@ -0,0 +1,104 @@
+package configure
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/flags"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// runConfigureWithOutput runs configure non-interactively against a config
+// file with contents and returns what it printed to stdout.
+func runConfigureWithOutput(t *testing.T, output flags.Output, contents string, args ...string) (string, string) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	if contents != "" {
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	}
+	t.Setenv("DATABRICKS_CONFIG_FILE", path)
+	t.Setenv("DATABRICKS_TOKEN", "new-token")
+
+	var stdout bytes.Buffer
+	ctx := cmdio.InContext(t.Context(), cmdio.NewIO(t.Context(), output, nil, &stdout, io.Discard, "", ""))
+	cmd := newConfigureCommand()
+	cmd.SetArgs(args)
+	require.NoError(t, cmd.ExecuteContext(ctx))
+	return stdout.String(), path
+}
+
+func TestConfigureSummaryJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		env      map[string]string
+		args     []string
+		want     map[string]any
+	}{
+		{
+			name:     "pat",
+			contents: "[dev]\nhost = https://dev.cloud.databricks.com\nauth_type = databricks-cli\ncluster_id = 123\n",
+			args:     []string{"--skip-validation", "--profile", "dev", "--host", "https://dev.cloud.databricks.com"},
+			want: map[string]any{
+				"profile":      "dev",
+				"host":         "https://dev.cloud.databricks.com",
+				"keys_written": []any{"host", "token"},
+				"keys_cleared": []any{"auth_type"},
+			},
+		},
+		{
+			name:     "cluster",
+			contents: "[DEFAULT]\nhost = https://dev.cloud.databricks.com\nserverless_compute_id = auto\n",
+			env:      map[string]string{"DATABRICKS_CLUSTER_ID": "456"},
+			args:     []string{"--skip-validation", "--host", "https://dev.cloud.databricks.com"},
+			want: map[string]any{
+				"profile":      "DEFAULT",
+				"host":         "https://dev.cloud.databricks.com",
+				"keys_written": []any{"host", "cluster_id", "token"},
+				"keys_cleared": []any{"serverless_compute_id"},
+			},
+		},
+		{
+			name: "new profile",
+			args: []string{"--skip-validation", "--host", "https://dev.cloud.databricks.com"},
+			want: map[string]any{
+				"profile":      "DEFAULT",
+				"host":         "https://dev.cloud.databricks.com",
+				"keys_written": []any{"host", "token"},
+				"keys_cleared": []any{},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			out, path := runConfigureWithOutput(t, flags.OutputJSON, tt.contents, tt.args...)
+
+			var got map[string]any
+			require.NoError(t, json.Unmarshal([]byte(out), &got))
+			tt.want["config_file"] = path
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConfigureSummaryText(t *testing.T) {
+	out, path := runConfigureWithOutput(t, flags.OutputText,
+		"[DEFAULT]\nhost = https://dev.cloud.databricks.com\nserverless_compute_id = auto\n",
+		"--skip-validation", "--serverless", "--host", "https://dev.cloud.databricks.com")
+	assert.Equal(t, "Profile DEFAULT for https://dev.cloud.databricks.com was saved to "+path+"\n"+
+		"Keys written: host, serverless_compute_id, token\n", out)
+}
+
+func TestConfigureQuiet(t *testing.T) {
+	out, _ := runConfigureWithOutput(t, flags.OutputJSON, "", "--quiet", "--skip-validation", "--host", "https://dev.cloud.databricks.com")
+	assert.Empty(t, out)
+}
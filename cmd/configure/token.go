@@ -0,0 +1,98 @@
+package configure
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+)
+
+// errTokenValidationFailed is wrapped by errors that are not caused by an
+// invalid token, such as a workspace that can't be reached.
+var errTokenValidationFailed = errors.New("unable to validate the token")
+
+// validateToken checks that the workspace at the host of cfg accepts the
+// token of cfg and returns the name of the authenticated user.
+//
+// A rejected token is returned as an error enriched with remediation steps.
+// Other errors wrap [errTokenValidationFailed].
+func validateToken(ctx context.Context, cfg *config.Config) (string, error) {
+	id, err := auth.WhoAmI(ctx, &config.Config{
+		Profile:  cfg.Profile,
+		Host:     cfg.Host,
+		Token:    cfg.Token,
+		AuthType: auth.AuthTypePat,
+
+		// The profile is not saved yet, so it must not be loaded.
+		Loaders: []config.Loader{config.ConfigAttributes},
+	})
+	var enriched *auth.EnrichedAuthError
+	if errors.As(err, &enriched) && enriched.StatusCode == http.StatusUnauthorized {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("%w: %w", errTokenValidationFailed, err)
+	}
+	return id.UserName, nil
+}
+
+// checkToken validates the token of cfg unless validation is skipped. It
+// refuses a token that the workspace rejects, and only warns if the token
+// can't be validated for another reason, such as a network error.
+func checkToken(ctx context.Context, flags *configureFlags, cfg *config.Config) error {
+	if flags.SkipValidation {
+		return nil
+	}
+
+	user, err := validateToken(ctx, cfg)
+	if errors.Is(err, errTokenValidationFailed) {
+		log.Warnf(ctx, "%v. Saving the profile anyway", err)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if !flags.Quiet {
+		cmdio.LogString(ctx, "Authenticated as "+user)
+	}
+	return nil
+}
+
+// readToken reads a token from the first line of r. The line may end with
+// LF, CRLF or the end of the input. The source describes r in errors.
+func readToken(r io.Reader, source string) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", fmt.Errorf("unable to read the token from %s: %w", source, err)
+	}
+	token := strings.TrimSpace(line)
+	if token == "" {
+		return "", fmt.Errorf("no token found in %s", source)
+	}
+	return token, nil
+}
+
+// readTokenFile reads a token from the file at path, or from stdin if path
+// is "-".
+func readTokenFile(cmd *cobra.Command, path string) (string, error) {
+	if path == "-" {
+		return readToken(cmd.InOrStdin(), "stdin")
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the token from %s: %w", path, err)
+	}
+	defer f.Close()
+	return readToken(f, path)
+}
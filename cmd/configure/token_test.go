@@ -0,0 +1,134 @@
+package configure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMeServer(t *testing.T, status int, body string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/2.0/preview/scim/v2/Me" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Equal(t, "Bearer dapi123", r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(body))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestValidateToken(t *testing.T) {
+	server := newMeServer(t, http.StatusOK, `{"userName": "user@example.com"}`)
+
+	user, err := validateToken(t.Context(), &config.Config{
+		Profile: "dev",
+		Host:    server.URL,
+		Token:   "dapi123",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "user@example.com", user)
+}
+
+func TestValidateTokenUnauthorized(t *testing.T) {
+	server := newMeServer(t, http.StatusUnauthorized, `{"error_code": "UNAUTHENTICATED", "message": "Invalid access token."}`)
+
+	_, err := validateToken(t.Context(), &config.Config{
+		Profile: "dev",
+		Host:    server.URL,
+		Token:   "dapi123",
+	})
+	assert.NotErrorIs(t, err, errTokenValidationFailed)
+
+	var enriched *auth.EnrichedAuthError
+	require.ErrorAs(t, err, &enriched)
+	assert.Equal(t, http.StatusUnauthorized, enriched.StatusCode)
+	assert.Equal(t, "dev", enriched.Profile)
+	assert.Equal(t, auth.AuthTypePat, enriched.AuthType)
+	assert.Contains(t, enriched.NextSteps, "Regenerate your access token or run: databricks auth login --profile dev")
+}
+
+func TestValidateTokenServerError(t *testing.T) {
+	server := newMeServer(t, http.StatusBadRequest, `{"error_code": "BAD_REQUEST", "message": "Something went wrong."}`)
+
+	_, err := validateToken(t.Context(), &config.Config{
+		Host:  server.URL,
+		Token: "dapi123",
+	})
+	assert.ErrorIs(t, err, errTokenValidationFailed)
+	assert.ErrorContains(t, err, "Something went wrong.")
+}
+
+func TestCheckToken(t *testing.T) {
+	server := newMeServer(t, http.StatusOK, `{"userName": "user@example.com"}`)
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+
+	err := checkToken(ctx, &configureFlags{}, &config.Config{Host: server.URL, Token: "dapi123"})
+	require.NoError(t, err)
+	assert.Equal(t, "Authenticated as user@example.com\n", stderr.String())
+}
+
+func TestCheckTokenUnauthorized(t *testing.T) {
+	server := newMeServer(t, http.StatusUnauthorized, `{"error_code": "UNAUTHENTICATED", "message": "Invalid access token."}`)
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+
+	err := checkToken(ctx, &configureFlags{}, &config.Config{Host: server.URL, Token: "dapi123"})
+	assert.ErrorContains(t, err, "Invalid access token.")
+	assert.Empty(t, stderr.String())
+}
+
+func TestCheckTokenSkipValidation(t *testing.T) {
+	server := newMeServer(t, http.StatusUnauthorized, `{"error_code": "UNAUTHENTICATED", "message": "Invalid access token."}`)
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+
+	err := checkToken(ctx, &configureFlags{SkipValidation: true}, &config.Config{Host: server.URL, Token: "dapi123"})
+	require.NoError(t, err)
+	assert.Empty(t, stderr.String())
+}
+
+func TestReadToken(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "newline", input: "dapi123\n", want: "dapi123"},
+		{name: "no newline", input: "dapi123", want: "dapi123"},
+		{name: "CRLF", input: "dapi123\r\n", want: "dapi123"},
+		{name: "surrounding whitespace", input: "  dapi123 \t\n", want: "dapi123"},
+		{name: "only the first line", input: "dapi123\nsomething else\n", want: "dapi123"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readToken(strings.NewReader(tt.input), "stdin")
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestReadTokenEmpty(t *testing.T) {
+	_, err := readToken(strings.NewReader("\r\n"), "stdin")
+	assert.EqualError(t, err, "no token found in stdin")
+}
+
+func TestReadTokenFileNotFound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token")
+	_, err := readTokenFile(&cobra.Command{}, path)
+	assert.ErrorContains(t, err, "unable to read the token from "+path)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
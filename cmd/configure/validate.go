@@ -0,0 +1,266 @@
+package configure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/libs/auth"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/databrickscfg/profile"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/ini.v1"
+)
+
+const (
+	severityError   = "error"
+	severityWarning = "warning"
+)
+
+// extraProfileKeys lists keys that the CLI stores in profiles in addition to
+// the attributes of [config.ConfigAttributes].
+var extraProfileKeys = []string{
+	auth.PinnedWorkspaceIDKey,
+	databrickscfg.IncludeKey,
+}
+
+type problem struct {
+	Severity string `json:"severity"`
+	Profile  string `json:"profile,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+}
+
+// configLines numbers the lines of a config file from 1, like editors do.
+// Zero means that the line is unknown.
+type configLines struct {
+	*databrickscfg.ConfigLines
+}
+
+// sections returns the lines of the headers of the named section.
+func (l configLines) sections(name string) []int {
+	s, ok := l.Sections[name]
+	if !ok {
+		return nil
+	}
+	var lines []int
+	for _, i := range s.Headers() {
+		lines = append(lines, i+1)
+	}
+	return lines
+}
+
+// section returns the line of the first header of the named section.
+func (l configLines) section(name string) int {
+	if lines := l.sections(name); len(lines) > 0 {
+		return lines[0]
+	}
+	return 0
+}
+
+// key returns the first line that sets key in the named section.
+func (l configLines) key(section, key string) int {
+	if s, ok := l.Sections[section]; ok && len(s.Keys[key]) > 0 {
+		return s.Keys[key][0] + 1
+	}
+	return 0
+}
+
+// validateConfigFile checks the contents of the config file at path for
+// problems that otherwise only surface when a command uses the broken part.
+func validateConfigFile(ctx context.Context, path string, data []byte) []problem {
+	data = databrickscfg.StripBOM(data)
+	lines := configLines{databrickscfg.ScanConfigLines(strings.Split(string(data), "\n"))}
+	var problems []problem
+	for _, e := range lines.Errors {
+		problems = append(problems, problem{Severity: severityError, Profile: e.Section, Line: e.Line + 1, Message: e.Message})
+	}
+
+	file, err := ini.LoadSources(ini.LoadOptions{SpaceBeforeInlineComment: true}, data)
+	if err != nil {
+		if len(problems) == 0 {
+			problems = append(problems, problem{Severity: severityError, Message: err.Error()})
+		}
+		return problems
+	}
+
+	// Duplicate sections are merged by the parser, so keys of one definition
+	// can silently override keys of another.
+	for _, section := range file.Sections() {
+		name := section.Name()
+		defined := lines.sections(name)
+		for _, n := range defined[min(1, len(defined)):] {
+			problems = append(problems, problem{
+				Severity: severityError,
+				Profile:  name,
+				Line:     n,
+				Message:  fmt.Sprintf("profile is already defined on line %d; the keys of both definitions are merged", defined[0]),
+			})
+		}
+	}
+
+	known := slices.Concat(extraProfileKeys, profileKeys())
+	hosts := map[string]string{}
+	for _, section := range file.Sections() {
+		name := section.Name()
+		if name == databrickscfg.SettingsSection {
+			continue
+		}
+
+		for _, key := range section.KeyStrings() {
+			if slices.Contains(known, key) {
+				continue
+			}
+			problems = append(problems, problem{
+				Severity: severityWarning,
+				Profile:  name,
+				Line:     lines.key(name, key),
+				Message:  fmt.Sprintf("unknown key %q", key),
+			})
+		}
+
+		values, err := databrickscfg.ExpandEnv(ctx, section)
+		if err != nil {
+			problems = append(problems, problem{Severity: severityWarning, Profile: name, Line: lines.section(name), Message: err.Error()})
+			values = section.KeysHash()
+		}
+
+		cfg := &config.Config{Profile: name, ConfigFile: path}
+		for _, attr := range config.ConfigAttributes {
+			v := values[attr.Name]
+			if v == "" {
+				continue
+			}
+			err := attr.SetS(cfg, v)
+			if err != nil {
+				problems = append(problems, problem{
+					Severity: severityError,
+					Profile:  name,
+					Line:     lines.key(name, attr.Name),
+					Message:  fmt.Sprintf("invalid value for %s: %v", attr.Name, err),
+				})
+			}
+		}
+
+		var conflict *auth.ConflictingCredentialsError
+		if errors.As(auth.CheckConflictingCredentials(ctx, cfg), &conflict) {
+			sets := make([]string, len(conflict.Sets))
+			for i, s := range conflict.Sets {
+				sets[i] = fmt.Sprintf("%s (%s)", strings.Join(s.Keys, " and "), s.AuthType)
+			}
+			problems = append(problems, problem{
+				Severity: severityError,
+				Profile:  name,
+				Line:     lines.section(name),
+				Message:  "credentials for multiple auth types: " + strings.Join(sets, ", "),
+			})
+		}
+
+		// Profiles are looked up by host (and account ID) when no profile is
+		// specified, for example by bundles and when saving credentials.
+		if cfg.Host == "" {
+			continue
+		}
+		host := cfg.CanonicalHostName()
+		if cfg.AccountID != "" {
+			host += " (account " + cfg.AccountID + ")"
+		}
+		if other, ok := hosts[host]; ok {
+			problems = append(problems, problem{
+				Severity: severityWarning,
+				Profile:  name,
+				Line:     lines.key(name, "host"),
+				Message:  fmt.Sprintf("profile %q has the same host %s; lookups by host cannot tell them apart", other, host),
+			})
+			continue
+		}
+		hosts[host] = name
+	}
+
+	if warning := databrickscfg.PermissionsWarning(ctx, path); warning != "" {
+		problems = append(problems, problem{Severity: severityWarning, Message: warning})
+	}
+	return problems
+}
+
+// profileKeys returns the names of the attributes that can be set in a profile.
+func profileKeys() []string {
+	var keys []string
+	for _, attr := range config.ConfigAttributes {
+		keys = append(keys, attr.Name)
+	}
+	return keys
+}
+
+// configFilePath returns the path to the config file with ~ and environment
+// variables expanded.
+func configFilePath(ctx context.Context) (string, error) {
+	path, err := profile.DefaultProfiler.GetPath(ctx)
+	if err != nil {
+		return "", err
+	}
+	return databrickscfg.ExpandConfigFilePath(ctx, path)
+}
+
+func newValidateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Check ~/.databrickscfg for problems",
+		Long: `Check ~/.databrickscfg for problems.
+
+This command reports syntax errors, profiles that are defined more than once,
+profiles with credentials for multiple auth types, unknown keys, profiles
+that share the same host and config files that other users can read. It exits
+with a non-zero status if it finds errors.
+
+You can check a different file by setting the DATABRICKS_CONFIG_FILE environment variable.`,
+		Args: root.NoArgs,
+		Annotations: map[string]string{
+			"template": cmdio.Heredoc(`
+			{{if .Problems}}{{header "Severity"}}	{{header "Profile"}}	{{header "Line"}}	{{header "Message"}}
+			{{range .Problems}}{{if eq .Severity "error"}}{{red .Severity}}{{else}}{{yellow .Severity}}{{end}}	{{.Profile}}	{{if .Line}}{{.Line}}{{end}}	{{.Message}}
+			{{end}}{{else}}No problems found in {{.Path}}
+			{{end}}`),
+		},
+	}
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+		path, err := configFilePath(ctx)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("%w at %s", profile.ErrNoConfiguration, path)
+		}
+		if err != nil {
+			return err
+		}
+
+		problems := validateConfigFile(ctx, path, data)
+		if problems == nil {
+			problems = []problem{}
+		}
+		err = cmdio.Render(ctx, struct {
+			Path     string    `json:"path"`
+			Problems []problem `json:"problems"`
+		}{path, problems})
+		if err != nil {
+			return err
+		}
+		if slices.ContainsFunc(problems, func(p problem) bool { return p.Severity == severityError }) {
+			return root.ErrAlreadyPrinted
+		}
+		return nil
+	}
+
+	return cmd
+}
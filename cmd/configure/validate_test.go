@@ -0,0 +1,172 @@
+package configure
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		want     []problem
+	}{
+		{
+			name: "valid",
+			contents: `[DEFAULT]
+include = managed.cfg
+host = https://a.cloud.databricks.com
+token = a
+
+[b]
+host = https://b.cloud.databricks.com
+auth_type = databricks-cli
+pinned_workspace_id = 123
+
+[__settings__]
+default_profile = b
+`,
+		},
+		{
+			name: "syntax errors",
+			contents: `[a
+host = https://a.cloud.databricks.com
+hots
+
+[]
+= x
+`,
+			want: []problem{
+				{Severity: "error", Line: 1, Message: "unclosed section header [a"},
+				{Severity: "error", Profile: "DEFAULT", Line: 3, Message: `line "hots" is not a section header, key = value pair or comment`},
+				{Severity: "error", Line: 5, Message: "empty section name"},
+				{Severity: "error", Profile: "DEFAULT", Line: 6, Message: "key name is empty"},
+			},
+		},
+		{
+			name: "multi-line values",
+			contents: `[a]
+host = https://a.cloud.databricks.com
+token = """
+not a key
+"""
+client_id = abc \
+not a key either
+`,
+		},
+		{
+			name: "unclosed multi-line value",
+			contents: `[a]
+token = """abc
+`,
+			want: []problem{
+				{Severity: "error", Profile: "a", Message: `multi-line value is not closed with """`},
+			},
+		},
+		{
+			name: "duplicate profiles",
+			contents: `[a]
+host = https://a.cloud.databricks.com
+
+[a]
+token = a
+`,
+			want: []problem{
+				{Severity: "error", Profile: "a", Line: 4, Message: "profile is already defined on line 1; the keys of both definitions are merged"},
+			},
+		},
+		{
+			name: "unknown keys",
+			contents: `[a]
+hots = https://a.cloud.databricks.com
+token = a
+`,
+			want: []problem{
+				{Severity: "warning", Profile: "a", Line: 2, Message: `unknown key "hots"`},
+			},
+		},
+		{
+			name: "conflicting credentials",
+			contents: `[a]
+host = https://a.cloud.databricks.com
+token = a
+client_id = b
+client_secret = c
+
+[b]
+host = https://b.cloud.databricks.com
+auth_type = pat
+token = a
+client_id = b
+client_secret = c
+`,
+			want: []problem{
+				{Severity: "error", Profile: "a", Line: 1, Message: "credentials for multiple auth types: token (pat), client_id and client_secret (oauth-m2m)"},
+			},
+		},
+		{
+			name: "same host",
+			contents: `[a]
+host = https://a.cloud.databricks.com
+token = a
+
+[b]
+host = https://a.cloud.databricks.com/?o=123
+token = b
+
+[acc1]
+host = https://accounts.cloud.databricks.com
+account_id = 1
+
+[acc2]
+host = https://accounts.cloud.databricks.com
+account_id = 2
+`,
+			want: []problem{
+				{Severity: "warning", Profile: "b", Line: 6, Message: `profile "a" has the same host https://a.cloud.databricks.com; lookups by host cannot tell them apart`},
+			},
+		},
+		{
+			name:     "byte order mark and CRLF line endings",
+			contents: "\xef\xbb\xbf[a]\r\nhost = https://a.cloud.databricks.com\r\ntoken = a\r\n",
+		},
+		{
+			name: "invalid value",
+			contents: `[a]
+host = https://a.cloud.databricks.com
+http_timeout_seconds = soon
+`,
+			want: []problem{
+				{Severity: "error", Profile: "a", Line: 3, Message: `invalid value for http_timeout_seconds: strconv.Atoi: parsing "soon": invalid syntax`},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateConfigFile(t.Context(), filepath.Join(t.TempDir(), ".databrickscfg"), []byte(tt.contents))
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestValidateConfigFilePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+
+	contents := []byte("[a]\nhost = https://a.cloud.databricks.com\ntoken = a\n")
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, contents, 0o600))
+	require.NoError(t, os.Chmod(path, 0o644))
+
+	got := validateConfigFile(t.Context(), path, contents)
+	assert.Equal(t, []problem{
+		{Severity: "warning", Message: path + ` can be accessed by other users (mode 0644). Run "databricks configure fix-permissions" to restrict access to its owner`},
+	}, got)
+}
@@ -40,6 +40,51 @@ func initProfileFlag(cmd *cobra.Command) {
 	cmd.RegisterFlagCompletionFunc("profile", profile.ProfileCompletion)
 }
 
+func initStrictAuthFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool("strict-auth", false, "fail if environment variables conflict with the auth settings of the profile")
+}
+
+func initNoNetworkDiagnosticsFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().Bool("no-network-diagnostics", false, "do not make network requests to diagnose errors, such as looking up your public IP address")
+}
+
+func noNetworkDiagnosticsFlagValue(cmd *cobra.Command) bool {
+	flag := cmd.Flag("no-network-diagnostics")
+	return flag != nil && flag.Value.String() == "true"
+}
+
+func strictAuthFlagValue(cmd *cobra.Command) bool {
+	strictAuthFlag := cmd.Flag("strict-auth")
+	return strictAuthFlag != nil && strictAuthFlag.Value.String() == "true"
+}
+
+type envConflictsCheckedKey struct{}
+
+// checkEnvConflicts warns once per command if environment variables conflict
+// with the auth settings of the selected profile. With --strict-auth, the
+// conflict is returned as an error instead.
+func checkEnvConflicts(ctx context.Context, cmd *cobra.Command, cfg *config.Config) (context.Context, error) {
+	if ctx.Value(envConflictsCheckedKey{}) != nil {
+		return ctx, nil
+	}
+	ctx = context.WithValue(ctx, envConflictsCheckedKey{}, true)
+	cmd.SetContext(ctx)
+
+	conflict, err := auth.DetectEnvConflicts(ctx, cfg)
+	if err != nil {
+		log.Debugf(ctx, "Failed to check environment variables for auth conflicts: %v", err)
+		return ctx, nil
+	}
+	if conflict == nil {
+		return ctx, nil
+	}
+	if strictAuthFlagValue(cmd) {
+		return ctx, conflict
+	}
+	cmdio.LogString(ctx, "Warning: "+conflict.Error())
+	return ctx, nil
+}
+
 func profileFlagValue(cmd *cobra.Command) (string, bool) {
 	profileFlag := cmd.Flag("profile")
 	if profileFlag == nil {
@@ -97,7 +142,7 @@ func accountClientOrPrompt(ctx context.Context, cfg *config.Config, allowPrompt
 	if err != nil {
 		return nil, err
 	}
-	a, err = databricks.NewAccountClient(&databricks.Config{Profile: profile})
+	a, err = databricks.NewAccountClient(&databricks.Config{Profile: profile, Loaders: databrickscfg.Loaders()})
 	if err == nil {
 		err = a.Config.Authenticate(emptyHttpRequest(ctx))
 		if err != nil {
@@ -131,7 +176,7 @@ func MustAnyClient(cmd *cobra.Command, args []string) (bool, error) {
 }
 
 func MustAccountClient(cmd *cobra.Command, args []string) error {
-	cfg := &config.Config{}
+	cfg := &config.Config{Loaders: databrickscfg.Loaders()}
 
 	// The command-line profile flag takes precedence over DATABRICKS_CONFIG_PROFILE.
 	pr, hasProfileFlag := profileFlagValue(cmd)
@@ -163,12 +208,18 @@ func MustAccountClient(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	ctx, err := checkEnvConflicts(ctx, cmd, cfg)
+	if err != nil {
+		return err
+	}
+
 	allowPrompt := !hasProfileFlag && !shouldSkipPrompt(cmd.Context())
 	a, err := accountClientOrPrompt(cmd.Context(), cfg, allowPrompt)
 	if err != nil {
 		return renderError(ctx, cfg, err)
 	}
 
+	auth.RecordProfileUsage(ctx, a.Config.Profile)
 	ctx = cmdctx.SetAccountClient(ctx, a)
 	cmd.SetContext(ctx)
 	return nil
@@ -216,7 +267,7 @@ func workspaceClientOrPrompt(ctx context.Context, cfg *config.Config, allowPromp
 	if err != nil {
 		return nil, err
 	}
-	w, err = databricks.NewWorkspaceClient(&databricks.Config{Profile: profile})
+	w, err = databricks.NewWorkspaceClient(&databricks.Config{Profile: profile, Loaders: databrickscfg.Loaders()})
 	if err == nil {
 		err = w.Config.Authenticate(emptyHttpRequest(ctx))
 		if err != nil {
@@ -230,7 +281,7 @@ func MustWorkspaceClient(cmd *cobra.Command, args []string) error {
 	ctx := logdiag.InitContext(cmd.Context())
 	cmd.SetContext(ctx)
 
-	cfg := &config.Config{}
+	cfg := &config.Config{Loaders: databrickscfg.Loaders()}
 
 	// The command-line profile flag takes precedence over DATABRICKS_CONFIG_PROFILE.
 	profile, hasProfileFlag := profileFlagValue(cmd)
@@ -269,12 +320,18 @@ func MustWorkspaceClient(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	ctx, err := checkEnvConflicts(ctx, cmd, cfg)
+	if err != nil {
+		return err
+	}
+
 	allowPrompt := !hasProfileFlag && !shouldSkipPrompt(cmd.Context())
 	w, err := workspaceClientOrPrompt(cmd.Context(), cfg, allowPrompt)
 	if err != nil {
 		return renderError(ctx, cfg, err)
 	}
 
+	auth.RecordProfileUsage(ctx, w.Config.Profile)
 	ctx = cmdctx.SetWorkspaceClient(ctx, w)
 	cmd.SetContext(ctx)
 	return nil
@@ -317,6 +374,7 @@ func AskForWorkspaceProfile(ctx context.Context) (string, error) {
 		Label:             "Workspace profiles defined in " + path,
 		Profiles:          profiles,
 		StartInSearchMode: true,
+		FuzzySearch:       true,
 		ActiveTemplate:    `{{.Name | bold}} ({{.Host|faint}})`,
 		InactiveTemplate:  `{{.Name}}`,
 		SelectedTemplate:  `{{ "Using workspace profile" | faint }}: {{ .Name | bold }}`,
@@ -343,6 +401,7 @@ func AskForAccountProfile(ctx context.Context) (string, error) {
 		Label:             "Account profiles defined in " + path,
 		Profiles:          profiles,
 		StartInSearchMode: true,
+		FuzzySearch:       true,
 		ActiveTemplate:    `{{.Name | bold}} ({{.AccountID|faint}} {{.Cloud|faint}})`,
 		InactiveTemplate:  `{{.Name}}`,
 		SelectedTemplate:  `{{ "Using account profile" | faint }}: {{ .Name | bold }}`,
@@ -361,7 +420,12 @@ func emptyHttpRequest(ctx context.Context) *http.Request {
 }
 
 func renderError(ctx context.Context, cfg *config.Config, err error) error {
-	if rewritten, newErr := auth.RewriteAuthError(ctx, cfg.Host, cfg.AccountID, cfg.Profile, err); rewritten {
+	// The SDK rejects credentials for multiple auth types without naming the
+	// profile or how to fix it.
+	if conflict := auth.CheckConflictingCredentials(ctx, cfg); conflict != nil {
+		return conflict
+	}
+	if rewritten, newErr := auth.RewriteAuthError(ctx, cfg.Host, cfg.AccountID, cfg.Profile, auth.LoginCommandOptionsFromConfig(cfg), err); rewritten {
 		return newErr
 	}
 	return err
@@ -92,6 +92,8 @@ func TestAccountClientOrPrompt(t *testing.T) {
 
 	dir := t.TempDir()
 	configFile := filepath.Join(dir, ".databrickscfg")
+	// Keep profile usage recorded by the client out of the source tree.
+	t.Setenv("HOME", dir)
 	err := os.WriteFile(
 		configFile,
 		[]byte(`
@@ -167,6 +169,7 @@ func TestWorkspaceClientOrPrompt(t *testing.T) {
 
 	dir := t.TempDir()
 	configFile := filepath.Join(dir, ".databrickscfg")
+	t.Setenv("HOME", dir)
 	err := os.WriteFile(
 		configFile,
 		[]byte(`
@@ -231,6 +234,7 @@ func TestMustAccountClientWorksWithDatabricksCfg(t *testing.T) {
 
 	dir := t.TempDir()
 	configFile := filepath.Join(dir, ".databrickscfg")
+	t.Setenv("HOME", dir)
 	err := os.WriteFile(
 		configFile,
 		[]byte(`
@@ -281,6 +285,7 @@ func TestMustAnyClientCanCreateWorkspaceClient(t *testing.T) {
 
 	dir := t.TempDir()
 	configFile := filepath.Join(dir, ".databrickscfg")
+	t.Setenv("HOME", dir)
 	err := os.WriteFile(
 		configFile,
 		[]byte(`
@@ -311,6 +316,7 @@ func TestMustAnyClientCanCreateAccountClient(t *testing.T) {
 
 	dir := t.TempDir()
 	configFile := filepath.Join(dir, ".databrickscfg")
+	t.Setenv("HOME", dir)
 	err := os.WriteFile(
 		configFile,
 		[]byte(`
@@ -342,6 +348,7 @@ func TestMustAnyClientWithEmptyDatabricksCfg(t *testing.T) {
 
 	dir := t.TempDir()
 	configFile := filepath.Join(dir, ".databrickscfg")
+	t.Setenv("HOME", dir)
 	err := os.WriteFile(
 		configFile,
 		[]byte(""), // empty file
@@ -11,6 +11,7 @@ import (
 	"github.com/databricks/cli/bundle"
 	"github.com/databricks/cli/bundle/env"
 	"github.com/databricks/cli/bundle/phases"
+	"github.com/databricks/cli/libs/auth"
 	"github.com/databricks/cli/libs/cmdctx"
 	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/cli/libs/databrickscfg"
@@ -134,8 +135,8 @@ func resolveProfileAmbiguity(cmd *cobra.Command, b *bundle.Bundle, originalErr e
 		Label:             "Multiple profiles match host " + b.Config.Workspace.Host,
 		Profiles:          profiles,
 		StartInSearchMode: true,
-		ActiveTemplate:    `{{.Name | bold}}{{if .AccountID}} (account: {{.AccountID|faint}}){{end}}{{if .WorkspaceID}} (workspace: {{.WorkspaceID|faint}}){{end}}`,
-		InactiveTemplate:  `{{.Name}}{{if .AccountID}} (account: {{.AccountID}}){{end}}{{if .WorkspaceID}} (workspace: {{.WorkspaceID}}){{end}}`,
+		ActiveTemplate:    `{{.Name | bold}}{{if .AccountID}} (account: {{.AccountID|faint}}){{end}}{{if .WorkspaceID}} (workspace: {{.WorkspaceID|faint}}){{end}}{{if .AuthType}} (auth: {{.AuthType|faint}}){{end}}`,
+		InactiveTemplate:  `{{.Name}}{{if .AccountID}} (account: {{.AccountID}}){{end}}{{if .WorkspaceID}} (workspace: {{.WorkspaceID}}){{end}}{{if .AuthType}} (auth: {{.AuthType}}){{end}}`,
 		SelectedTemplate:  `{{ "Using profile" | faint }}: {{ .Name | bold }}`,
 	})
 }
@@ -185,6 +186,7 @@ func configureBundle(cmd *cobra.Command, b *bundle.Bundle) {
 		}
 	}
 
+	auth.RecordProfileUsage(ctx, client.Config.Profile)
 	ctx = cmdctx.SetConfigUsed(ctx, client.Config)
 	cmd.SetContext(ctx)
 }
@@ -3,6 +3,7 @@ package root
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -276,6 +277,57 @@ workspace:
 	<-done
 }
 
+func TestBundleConfigureMultiMatchInteractiveSelection(t *testing.T) {
+	testutil.CleanupEnvironment(t)
+
+	setupDatabricksCfg(t)
+
+	rootPath := t.TempDir()
+	t.Chdir(rootPath)
+
+	contents := `
+workspace:
+  host: "https://a.com"
+`
+	err := os.WriteFile(filepath.Join(rootPath, "databricks.yml"), []byte(contents), 0o644)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 3*time.Second)
+	defer cancel()
+
+	ctx, testIO := cmdio.SetupTest(ctx, cmdio.TestOptions{PromptSupported: true})
+	defer testIO.Done()
+
+	// Drain the prompt output so that rendering it doesn't block.
+	go func() {
+		_, _ = testIO.Stderr.WriteTo(io.Discard)
+	}()
+
+	cmd := &cobra.Command{}
+	cmd.SetContext(ctx)
+	initProfileFlag(cmd)
+
+	diags := make(chan []diag.Diagnostic)
+	go func() {
+		ctx := logdiag.InitContext(cmd.Context())
+		logdiag.SetCollect(ctx, true)
+		cmd.SetContext(ctx)
+		_ = MustConfigureBundle(cmd)
+		diags <- logdiag.FlushCollected(ctx)
+	}()
+
+	// Search for the second profile and select it.
+	_, err = testIO.Stdin.WriteString("PROFILE-2\r")
+	require.NoError(t, err)
+	require.NoError(t, testIO.Stdin.Flush())
+
+	// The command continues with the selected profile.
+	assert.Empty(t, <-diags)
+	cfg := cmdctx.ConfigUsed(cmd.Context())
+	assert.Equal(t, "PROFILE-2", cfg.Profile)
+	assert.Equal(t, "b", cfg.Token)
+}
+
 func TestTargetFlagFull(t *testing.T) {
 	cmd := emptyCommand(t)
 	initTargetFlag(cmd)
@@ -0,0 +1,68 @@
+package root
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// RegisterEnumCompletion registers a completion function for the flag with
+// the given name that completes the given values.
+func RegisterEnumCompletion(cmd *cobra.Command, name string, values []string) error {
+	return cmd.RegisterFlagCompletionFunc(name, completeEnum(values))
+}
+
+// RegisterEnumCompletions registers completions for all flags of cmd and its
+// subcommands whose set of values is known. This is the case for flags of
+// SDK enum types, which list their values with a Values method.
+// Flags that already have a completion function are left as-is.
+func RegisterEnumCompletions(cmd *cobra.Command) {
+	for _, sub := range cmd.Commands() {
+		RegisterEnumCompletions(sub)
+	}
+
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if _, ok := cmd.GetFlagCompletionFunc(f.Name); ok {
+			return
+		}
+		if values := enumValues(f.Value); len(values) > 0 {
+			_ = RegisterEnumCompletion(cmd, f.Name, values)
+		}
+	})
+}
+
+// enumValues returns the values listed by the Values method of v, such as
+// the one on SDK enum types. It returns nil if v has no such method or if
+// its values aren't strings.
+func enumValues(v pflag.Value) []string {
+	m := reflect.ValueOf(v).MethodByName("Values")
+	if !m.IsValid() {
+		return nil
+	}
+	t := m.Type()
+	if t.NumIn() != 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.Slice || t.Out(0).Elem().Kind() != reflect.String {
+		return nil
+	}
+
+	out := m.Call(nil)[0]
+	values := make([]string, 0, out.Len())
+	for i := range out.Len() {
+		values = append(values, out.Index(i).String())
+	}
+	return values
+}
+
+// completeEnum returns a completion function for the given values.
+func completeEnum(values []string) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		var completions []cobra.Completion
+		for _, v := range values {
+			if strings.HasPrefix(v, toComplete) {
+				completions = append(completions, v)
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
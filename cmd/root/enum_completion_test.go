@@ -0,0 +1,74 @@
+package root
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go/service/jobs"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// complete runs Cobra's hidden __complete command with args and returns the
+// completion candidates, without the trailing directive line.
+func complete(t *testing.T, cmd *cobra.Command, args ...string) []string {
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	require.NoError(t, cmd.Execute())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.NotEmpty(t, lines)
+	assert.Equal(t, ":4", lines[len(lines)-1], "expected ShellCompDirectiveNoFileComp")
+	return lines[:len(lines)-1]
+}
+
+func newEnumTestCommand() *cobra.Command {
+	cmd := &cobra.Command{Use: "root"}
+	cmd.PersistentFlags().String("output", "text", "output type")
+
+	var format jobs.Format
+	sub := &cobra.Command{Use: "sub", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	sub.Flags().Var(&format, "format", "job format")
+	sub.Flags().String("name", "", "a flag without known values")
+	cmd.AddCommand(sub)
+	return cmd
+}
+
+func TestRegisterEnumCompletion(t *testing.T) {
+	cmd := newEnumTestCommand()
+	require.NoError(t, RegisterEnumCompletion(cmd, "output", []string{"json", "text"}))
+
+	assert.Equal(t, []string{"json", "text"}, complete(t, cmd, "sub", "--output", ""))
+	assert.Equal(t, []string{"json"}, complete(t, cmd, "sub", "--output", "j"))
+}
+
+func TestRegisterEnumCompletionsFromValues(t *testing.T) {
+	cmd := newEnumTestCommand()
+	RegisterEnumCompletions(cmd)
+
+	assert.Equal(t, []string{"MULTI_TASK", "SINGLE_TASK"}, complete(t, cmd, "sub", "--format", ""))
+	assert.Equal(t, []string{"SINGLE_TASK"}, complete(t, cmd, "sub", "--format", "S"))
+
+	_, ok := cmd.Commands()[0].GetFlagCompletionFunc("name")
+	assert.False(t, ok)
+}
+
+func TestRegisterEnumCompletionsKeepsExisting(t *testing.T) {
+	cmd := newEnumTestCommand()
+	sub := cmd.Commands()[0]
+	require.NoError(t, sub.RegisterFlagCompletionFunc("format", cobra.FixedCompletions([]string{"custom"}, cobra.ShellCompDirectiveNoFileComp)))
+	RegisterEnumCompletions(cmd)
+
+	assert.Equal(t, []string{"custom"}, complete(t, cmd, "sub", "--format", ""))
+}
+
+func TestOutputFlagCompletion(t *testing.T) {
+	cmd := &cobra.Command{Use: "root", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+	initOutputFlag(cmd)
+
+	assert.Equal(t, []string{"json", "text"}, complete(t, cmd, "--output", ""))
+}
@@ -27,6 +27,7 @@ func initOutputFlag(cmd *cobra.Command) *outputFlag {
 	}
 
 	cmd.PersistentFlags().VarP(&f.output, "output", "o", "output type: text or json")
+	_ = RegisterEnumCompletion(cmd, "output", []string{string(flags.OutputJSON), string(flags.OutputText)})
 	return &f
 }
 
@@ -18,6 +18,10 @@ const (
 	envLogFile   = "DATABRICKS_LOG_FILE"
 	envLogLevel  = "DATABRICKS_LOG_LEVEL"
 	envLogFormat = "DATABRICKS_LOG_FORMAT"
+
+	// envDebugAuth enables debug logging like --debug, to trace the
+	// evaluation of the credential strategies.
+	envDebugAuth = "DATABRICKS_DEBUG_AUTH"
 )
 
 type logFlags struct {
@@ -92,6 +96,9 @@ func initLogFlags(cmd *cobra.Command) *logFlags {
 	if v, ok := env.Lookup(cmd.Context(), envLogFormat); ok {
 		f.output.Set(v) //nolint:errcheck
 	}
+	if v, _ := env.GetBool(cmd.Context(), envDebugAuth); v {
+		f.level.Set("debug") //nolint:errcheck
+	}
 
 	flags := cmd.PersistentFlags()
 	flags.BoolVar(&f.debug, "debug", false, "enable debug logging")
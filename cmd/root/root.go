@@ -2,12 +2,14 @@ package root
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 	"runtime"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"time"
 
@@ -16,6 +18,7 @@ import (
 	"github.com/databricks/cli/libs/cmdctx"
 	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/cli/libs/dbr"
+	"github.com/databricks/cli/libs/flags"
 	"github.com/databricks/cli/libs/log"
 	"github.com/databricks/cli/libs/telemetry"
 	"github.com/databricks/cli/libs/telemetry/protos"
@@ -46,6 +49,8 @@ func New(ctx context.Context) *cobra.Command {
 	logFlags := initLogFlags(cmd)
 	outputFlag := initOutputFlag(cmd)
 	initProfileFlag(cmd)
+	initStrictAuthFlag(cmd)
+	initNoNetworkDiagnosticsFlag(cmd)
 	initEnvironmentFlag(cmd)
 	initTargetFlag(cmd)
 
@@ -80,6 +85,10 @@ func New(ctx context.Context) *cobra.Command {
 		ctx = withUpstreamInUserAgent(ctx)
 		ctx = withInteractiveModeInUserAgent(ctx)
 		ctx = InjectTestPidToUserAgent(ctx)
+
+		if noNetworkDiagnosticsFlagValue(cmd) {
+			ctx = auth.WithoutNetworkDiagnostics(ctx)
+		}
 		cmd.SetContext(ctx)
 		return nil
 	}
@@ -149,7 +158,7 @@ Stack Trace:
 			cfg := cmdctx.ConfigUsed(cmd.Context())
 			err = auth.EnrichAuthError(cmd.Context(), cfg, err)
 		}
-		fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s\n", err.Error())
+		printError(cmd, err)
 	}
 
 	// Log exit status and error
@@ -161,7 +170,7 @@ Stack Trace:
 				slog.String("exit_code", "0"))
 		} else if errors.Is(err, ErrAlreadyPrinted) {
 			logger.Debug("failed execution",
-				slog.String("exit_code", "1"),
+				slog.String("exit_code", strconv.Itoa(ExitCode(err))),
 			)
 		} else {
 			logger.Info("failed execution",
@@ -171,10 +180,7 @@ Stack Trace:
 		}
 	}
 
-	exitCode := 0
-	if err != nil {
-		exitCode = 1
-	}
+	exitCode := ExitCode(err)
 
 	commandStr := commandString(cmd)
 	ctx = cmd.Context()
@@ -195,6 +201,21 @@ Stack Trace:
 	return err
 }
 
+// printError writes err to stderr. With --output json, enriched auth errors
+// are written as a JSON object so that scripts can parse the cause.
+func printError(cmd *cobra.Command, err error) {
+	var enriched *auth.EnrichedAuthError
+	if f := cmd.Flag("output"); f != nil && f.Value.String() == string(flags.OutputJSON) && errors.As(err, &enriched) {
+		enc := json.NewEncoder(cmd.ErrOrStderr())
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if enc.Encode(enriched) == nil {
+			return
+		}
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "Error: %s\n", err.Error())
+}
+
 // This function is used to report an unknown subcommand.
 // It is used in the [cobra.Command.RunE] field of commands that have subcommands.
 // If user provided a valid subcommand, RunE for the
@@ -2,6 +2,8 @@ package root
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/databricks/cli/libs/cmdctx"
@@ -50,6 +52,52 @@ func TestExecuteEnrichesAuthErrors(t *testing.T) {
 	assert.Contains(t, output, "Next steps:")
 }
 
+func TestExecuteRendersEnrichedAuthErrorsAsJSON(t *testing.T) {
+	ctx := t.Context()
+	stderr := &bytes.Buffer{}
+
+	cmd := &cobra.Command{
+		Use:           "test",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg := &config.Config{
+				Host:     "https://test.cloud.databricks.com",
+				Profile:  "test-profile",
+				AuthType: "pat",
+			}
+			cmd.SetContext(cmdctx.SetConfigUsed(cmd.Context(), cfg))
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return &apierr.APIError{
+				StatusCode: 401,
+				ErrorCode:  "UNAUTHENTICATED",
+				Message:    "invalid token",
+			}
+		},
+	}
+	initOutputFlag(cmd)
+	cmd.SetErr(stderr)
+	cmd.SetArgs([]string{"--output", "json"})
+
+	err := Execute(ctx, cmd)
+	require.Error(t, err)
+
+	assert.JSONEq(t, `{
+		"error_code": "UNAUTHENTICATED",
+		"status_code": 401,
+		"message": "invalid token",
+		"profile": "test-profile",
+		"host": "https://test.cloud.databricks.com",
+		"auth_type": "pat",
+		"next_steps": [
+			"Regenerate your access token or run: databricks auth login --profile test-profile",
+			"Check your identity: databricks auth describe --profile test-profile"
+		]
+	}`, stderr.String())
+}
+
 func TestExecuteNoEnrichmentWithoutConfigUsed(t *testing.T) {
 	ctx := t.Context()
 	stderr := &bytes.Buffer{}
@@ -95,3 +143,30 @@ func TestExecuteErrAlreadyPrintedNotEnriched(t *testing.T) {
 	require.Error(t, err)
 	assert.Empty(t, stderr.String())
 }
+
+func TestExecuteExitCodeError(t *testing.T) {
+	ctx := t.Context()
+	stderr := &bytes.Buffer{}
+
+	cmd := &cobra.Command{
+		Use:           "test",
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return &ExitCodeError{Code: 3}
+		},
+	}
+	cmd.SetErr(stderr)
+
+	err := Execute(ctx, cmd)
+	require.Error(t, err)
+	assert.Empty(t, stderr.String())
+	assert.Equal(t, 3, ExitCode(err))
+}
+
+func TestExitCode(t *testing.T) {
+	assert.Equal(t, 0, ExitCode(nil))
+	assert.Equal(t, 1, ExitCode(errors.New("failed")))
+	assert.Equal(t, 1, ExitCode(ErrAlreadyPrinted))
+	assert.Equal(t, 3, ExitCode(fmt.Errorf("wrapped: %w", &ExitCodeError{Code: 3})))
+}
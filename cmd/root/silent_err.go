@@ -1,7 +1,37 @@
 package root
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 // ErrAlreadyPrinted is not printed to the user. It's used to signal that the command should exit with an error,
 // but the error message was already printed.
 var ErrAlreadyPrinted = errors.New("AlreadyPrinted")
+
+// ExitCodeError is not printed to the user. It's used to signal that the command should exit with a specific
+// non-zero exit code that scripts can branch on, for example to report the result of a check.
+type ExitCodeError struct {
+	Code int
+}
+
+func (e *ExitCodeError) Error() string {
+	return fmt.Sprintf("exit code %d", e.Code)
+}
+
+// Is makes the error match [ErrAlreadyPrinted] so that it is handled the same way.
+func (e *ExitCodeError) Is(target error) bool {
+	return target == ErrAlreadyPrinted
+}
+
+// ExitCode returns the exit code for an error returned by [Execute].
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *ExitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.Code
+	}
+	return 1
+}
@@ -82,11 +82,16 @@ func getDefaultWarehouse(ctx context.Context) (*sql.EndpointInfo, error) {
 
 // resolveWarehouse selects a warehouse using the following priority:
 // 1. DATABRICKS_WAREHOUSE_ID env var
-// 2. User's default warehouse override (CUSTOM type only)
-// 3. Server-side default / first usable warehouse by state
+// 2. warehouse_id of the profile
+// 3. User's default warehouse override (CUSTOM type only)
+// 4. Server-side default / first usable warehouse by state
 func resolveWarehouse(ctx context.Context, w *databricks.WorkspaceClient) (*sql.EndpointInfo, error) {
-	// first resolve DATABRICKS_WAREHOUSE_ID env variable
+	// first resolve DATABRICKS_WAREHOUSE_ID env variable, then the warehouse_id
+	// of the profile, which the SDK loads into the client config
 	warehouseID := env.Get(ctx, "DATABRICKS_WAREHOUSE_ID")
+	if warehouseID == "" && w.Config != nil {
+		warehouseID = w.Config.WarehouseID
+	}
 	if warehouseID != "" {
 		warehouse, err := w.Warehouses.Get(ctx, sql.GetWarehouseRequest{
 			Id: warehouseID,
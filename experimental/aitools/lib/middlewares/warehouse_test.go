@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"testing"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/experimental/mocks"
+	"github.com/databricks/databricks-sdk-go/service/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveWarehouseFromProfile(t *testing.T) {
+	ctx := t.Context()
+	m := mocks.NewMockWorkspaceClient(t)
+	m.WorkspaceClient.Config = &config.Config{WarehouseID: "profile-warehouse"}
+
+	m.GetMockWarehousesAPI().EXPECT().Get(ctx, sql.GetWarehouseRequest{Id: "profile-warehouse"}).Return(&sql.GetWarehouseResponse{
+		Id:    "profile-warehouse",
+		Name:  "Profile Warehouse",
+		State: sql.StateRunning,
+	}, nil)
+
+	warehouse, err := resolveWarehouse(ctx, m.WorkspaceClient)
+	require.NoError(t, err)
+	assert.Equal(t, &sql.EndpointInfo{
+		Id:    "profile-warehouse",
+		Name:  "Profile Warehouse",
+		State: sql.StateRunning,
+	}, warehouse)
+}
+
+func TestResolveWarehouseEnvOverridesProfile(t *testing.T) {
+	ctx := env.Set(t.Context(), "DATABRICKS_WAREHOUSE_ID", "env-warehouse")
+	m := mocks.NewMockWorkspaceClient(t)
+	m.WorkspaceClient.Config = &config.Config{WarehouseID: "profile-warehouse"}
+
+	m.GetMockWarehousesAPI().EXPECT().Get(ctx, sql.GetWarehouseRequest{Id: "env-warehouse"}).Return(&sql.GetWarehouseResponse{
+		Id:    "env-warehouse",
+		State: sql.StateStopped,
+	}, nil)
+
+	warehouse, err := resolveWarehouse(ctx, m.WorkspaceClient)
+	require.NoError(t, err)
+	assert.Equal(t, "env-warehouse", warehouse.Id)
+}
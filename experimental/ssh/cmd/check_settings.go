@@ -0,0 +1,99 @@
+package ssh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/databricks/cli/experimental/ssh/internal/sshconfig"
+	"github.com/databricks/cli/experimental/ssh/internal/vscode"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/spf13/cobra"
+)
+
+const checkSettingsTemplate = `{{.IDE}} settings: {{.SettingsPath}}{{if not .Exists}} (not found){{end}}
+
+{{header "Setting"}}	{{header "Current"}}	{{header "Expected"}}	{{header "Status"}}
+{{range .Settings}}{{.Key}}	{{if .Current}}{{.Current}}{{else}}-{{end}}	{{.Expected}}	{{if .OK}}{{green "OK"}}{{else if .Current}}{{red "INCORRECT"}}{{else}}{{red "MISSING"}}{{end}}
+{{end}}
+{{if .Backups}}Backups:
+{{range .Backups}}  {{.Path}}	{{pretty_date .Modified}}
+{{end}}{{else}}No backups
+{{end}}`
+
+func newCheckSettingsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "check-settings",
+		Short: "Check the IDE settings of an SSH connection",
+		Long: `Check the IDE settings of an SSH connection.
+
+This command compares the settings of your IDE with the settings that the
+connect command applies for the connection, and lists the current and the
+expected value of each of them. It also lists the backups of the settings file
+that were written before it was updated. The settings file is not modified.
+
+The command fails if any of the settings is missing or incorrect.
+
+` + disclaimer,
+		Args: cobra.NoArgs,
+	}
+
+	var name string
+	var ide string
+	var portRange string
+	var settingsPath string
+	var ideExtensions []string
+
+	cmd.Flags().StringVar(&name, "name", "", "Name of the connection to check")
+	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVar(&ide, "ide", vscode.AutoOption, "IDE to check the settings of (vscode, vscode-insiders, cursor, vscodium, windsurf, or auto to detect an IDE on PATH)")
+	cmd.Flags().StringVar(&portRange, "port-range", "", "Range of ports for the IDE server on serverless compute (format: N-M). By default, any range not used by other connections is accepted")
+	cmd.Flags().MarkHidden("port-range")
+	cmd.Flags().StringVar(&settingsPath, "settings-path", "", "Path to the IDE settings file, if it is not in the default location")
+	cmd.Flags().MarkHidden("settings-path")
+	cmd.Flags().StringSliceVar(&ideExtensions, "ide-extensions", nil, "Extensions that the IDE installs on serverless compute (default "+strings.Join(vscode.DefaultExtensions, ",")+", or ide_extensions of ~/.databricks/ssh-tunnel.json). Set to an empty value to not check them")
+	cmd.Flags().MarkHidden("ide-extensions")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if !vscode.IsValidIDEOption(ide) {
+			return fmt.Errorf("invalid IDE value: %q, expected one of: %s", ide, strings.Join(vscode.IDEOptions(), ", "))
+		}
+		if portRange != "" {
+			if err := vscode.ValidatePortRange(portRange); err != nil {
+				return err
+			}
+		}
+		if !cmd.Flags().Changed("ide-extensions") {
+			settings, err := sshconfig.LoadSettings(ctx)
+			if err != nil {
+				return err
+			}
+			ideExtensions = settings.IDEExtensions
+		}
+		if err := vscode.ValidateExtensions(ideExtensions); err != nil {
+			return err
+		}
+		if ide == vscode.AutoOption {
+			var err error
+			ide, err = vscode.DetectIDE(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		report, err := vscode.CheckSettings(ctx, ide, name, portRange, settingsPath, ideExtensions)
+		if err != nil {
+			return err
+		}
+		if err := cmdio.RenderWithTemplate(ctx, report, "", checkSettingsTemplate); err != nil {
+			return err
+		}
+		if missing := report.Missing(); missing > 0 {
+			return fmt.Errorf("%d of %d settings are missing or incorrect", missing, len(report.Settings))
+		}
+		return nil
+	}
+
+	return cmd
+}
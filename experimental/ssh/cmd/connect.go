@@ -1,10 +1,13 @@
 package ssh
 
 import (
+	"strings"
 	"time"
 
 	"github.com/databricks/cli/cmd/root"
 	"github.com/databricks/cli/experimental/ssh/internal/client"
+	"github.com/databricks/cli/experimental/ssh/internal/sshconfig"
+	"github.com/databricks/cli/experimental/ssh/internal/vscode"
 	"github.com/databricks/cli/libs/cmdctx"
 	"github.com/spf13/cobra"
 )
@@ -36,6 +39,12 @@ the SSH server and handling the connection proxy.
 	var liteswap string
 	var skipSettingsCheck bool
 	var environmentVersion int
+	var portRange string
+	var settingsPath string
+	var autoApproveIDESettings bool
+	var ideExtensions []string
+	var inlineSSHConfig bool
+	var autoApproveSSHConfig bool
 
 	cmd.Flags().StringVar(&clusterID, "cluster", "", "Databricks cluster ID (for dedicated clusters)")
 	cmd.Flags().DurationVar(&shutdownDelay, "shutdown-delay", defaultShutdownDelay, "Delay before shutting down the server after the last client disconnects")
@@ -46,7 +55,7 @@ the SSH server and handling the connection proxy.
 	cmd.Flags().MarkHidden("name")
 	cmd.Flags().StringVar(&accelerator, "accelerator", "", "GPU accelerator type (GPU_1xA10 or GPU_8xH100)")
 	cmd.Flags().MarkHidden("accelerator")
-	cmd.Flags().StringVar(&ide, "ide", "", "Open remote IDE window (vscode or cursor)")
+	cmd.Flags().StringVar(&ide, "ide", "", "Open remote IDE window (vscode, vscode-insiders, cursor, vscodium, windsurf, or auto to detect an IDE on PATH)")
 	cmd.Flags().MarkHidden("ide")
 
 	cmd.Flags().BoolVar(&proxyMode, "proxy", false, "ProxyCommand mode")
@@ -71,6 +80,24 @@ the SSH server and handling the connection proxy.
 	cmd.Flags().IntVar(&environmentVersion, "environment-version", defaultEnvironmentVersion, "Environment version for serverless compute")
 	cmd.Flags().MarkHidden("environment-version")
 
+	cmd.Flags().StringVar(&portRange, "port-range", "", "Range of ports for the IDE server on serverless compute (format: N-M). By default, a range not used by other connections is picked")
+	cmd.Flags().MarkHidden("port-range")
+
+	cmd.Flags().StringVar(&settingsPath, "settings-path", "", "Path to the IDE settings file, if it is not in the default location")
+	cmd.Flags().MarkHidden("settings-path")
+
+	cmd.Flags().BoolVar(&autoApproveIDESettings, "auto-approve-ide-settings", false, "Apply missing IDE settings without a prompt")
+	cmd.Flags().MarkHidden("auto-approve-ide-settings")
+
+	cmd.Flags().StringSliceVar(&ideExtensions, "ide-extensions", nil, "Extensions that the IDE installs on serverless compute (default "+strings.Join(vscode.DefaultExtensions, ",")+", or ide_extensions of ~/.databricks/ssh-tunnel.json). Set to an empty value to not manage them")
+	cmd.Flags().MarkHidden("ide-extensions")
+
+	cmd.Flags().BoolVar(&inlineSSHConfig, "inline-ssh-config", false, "Write the SSH config of the IDE connection into ~/.ssh/config instead of including it from a separate file, and remove it when the session disconnects")
+	cmd.Flags().MarkHidden("inline-ssh-config")
+
+	cmd.Flags().BoolVar(&autoApproveSSHConfig, "auto-approve-ssh-config", false, "Write the SSH config of the IDE connection into ~/.ssh/config without a prompt (with --inline-ssh-config)")
+	cmd.Flags().MarkHidden("auto-approve-ssh-config")
+
 	cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
 		// CLI in the proxy mode is executed by the ssh client and can't prompt for input
 		if proxyMode {
@@ -85,28 +112,42 @@ the SSH server and handling the connection proxy.
 	cmd.RunE = func(cmd *cobra.Command, args []string) error {
 		ctx := cmd.Context()
 		wsClient := cmdctx.WorkspaceClient(ctx)
+		// The IDE settings are not checked in the proxy mode, so the settings file is not needed there.
+		if !proxyMode && !cmd.Flags().Changed("ide-extensions") {
+			settings, err := sshconfig.LoadSettings(ctx)
+			if err != nil {
+				return err
+			}
+			ideExtensions = settings.IDEExtensions
+		}
 		opts := client.ClientOptions{
-			Profile:              wsClient.Config.Profile,
-			ClusterID:            clusterID,
-			ConnectionName:       connectionName,
-			Accelerator:          accelerator,
-			ProxyMode:            proxyMode,
-			IDE:                  ide,
-			ServerMetadata:       serverMetadata,
-			ShutdownDelay:        shutdownDelay,
-			MaxClients:           maxClients,
-			HandoverTimeout:      handoverTimeout,
-			ReleasesDir:          releasesDir,
-			ServerTimeout:        max(serverTimeout, shutdownDelay),
-			TaskStartupTimeout:   taskStartupTimeout,
-			AutoStartCluster:     autoStartCluster,
-			ClientPublicKeyName:  clientPublicKeyName,
-			ClientPrivateKeyName: clientPrivateKeyName,
-			UserKnownHostsFile:   userKnownHostsFile,
-			Liteswap:             liteswap,
-			SkipSettingsCheck:    skipSettingsCheck,
-			EnvironmentVersion:   environmentVersion,
-			AdditionalArgs:       args,
+			Profile:                wsClient.Config.Profile,
+			ClusterID:              clusterID,
+			ConnectionName:         connectionName,
+			Accelerator:            accelerator,
+			ProxyMode:              proxyMode,
+			IDE:                    ide,
+			ServerMetadata:         serverMetadata,
+			ShutdownDelay:          shutdownDelay,
+			MaxClients:             maxClients,
+			HandoverTimeout:        handoverTimeout,
+			ReleasesDir:            releasesDir,
+			ServerTimeout:          max(serverTimeout, shutdownDelay),
+			TaskStartupTimeout:     taskStartupTimeout,
+			AutoStartCluster:       autoStartCluster,
+			ClientPublicKeyName:    clientPublicKeyName,
+			ClientPrivateKeyName:   clientPrivateKeyName,
+			UserKnownHostsFile:     userKnownHostsFile,
+			Liteswap:               liteswap,
+			SkipSettingsCheck:      skipSettingsCheck,
+			EnvironmentVersion:     environmentVersion,
+			PortRange:              portRange,
+			SettingsPath:           settingsPath,
+			AutoApproveIDESettings: autoApproveIDESettings,
+			IDEExtensions:          ideExtensions,
+			InlineSSHConfig:        inlineSSHConfig,
+			AutoApproveSSHConfig:   autoApproveSSHConfig,
+			AdditionalArgs:         args,
 		}
 		if err := opts.Validate(); err != nil {
 			return err
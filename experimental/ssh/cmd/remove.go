@@ -0,0 +1,139 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/experimental/ssh/internal/sshconfig"
+	"github.com/databricks/cli/experimental/ssh/internal/vscode"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/spf13/cobra"
+)
+
+func newRemoveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "remove",
+		Short: "Remove the configuration of an SSH connection",
+		Long: `Remove the configuration of an SSH connection.
+
+This command removes the SSH host configuration that was added by the setup
+command or written into ~/.ssh/config by the connect command, and the
+settings of the connection that were added to the settings of your IDE by
+the connect command. Settings that are shared by all connections, such as
+the default extensions, are kept.
+
+` + disclaimer,
+		Args: cobra.NoArgs,
+	}
+
+	var name string
+	var ide string
+	var settingsPath string
+	var autoApprove bool
+
+	cmd.Flags().StringVar(&name, "name", "", "Name of the connection to remove")
+	cmd.MarkFlagRequired("name")
+	cmd.Flags().StringVar(&ide, "ide", "", "Only remove the settings of this IDE (vscode, vscode-insiders, cursor, vscodium or windsurf)")
+	cmd.Flags().MarkHidden("ide")
+	cmd.Flags().StringVar(&settingsPath, "settings-path", "", "Path to the IDE settings file, if it is not in the default location")
+	cmd.Flags().MarkHidden("settings-path")
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Skip confirmation prompt")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		// Without --ide, the settings of every supported IDE are checked.
+		ides := slices.DeleteFunc(vscode.IDEOptions(), func(o string) bool { return o == vscode.AutoOption })
+		if ide != "" {
+			if !slices.Contains(ides, ide) {
+				return fmt.Errorf("invalid IDE value: %q, expected one of: %s", ide, strings.Join(ides, ", "))
+			}
+			ides = []string{ide}
+		} else if settingsPath != "" {
+			return errors.New("--settings-path flag can only be used with --ide flag")
+		}
+
+		// Collect what there is to remove, to not prompt for nothing.
+		hostConfig, err := sshconfig.HostConfigExists(ctx, name)
+		if err != nil {
+			return err
+		}
+		mainConfigPath, err := sshconfig.GetMainConfigPath(ctx)
+		if err != nil {
+			return err
+		}
+		hostBlock, err := sshconfig.HostBlockExists(mainConfigPath, name)
+		if err != nil {
+			return err
+		}
+		settingsPaths := map[string]string{}
+		var settingsIDEs []string
+		for _, option := range ides {
+			path, found, err := vscode.FindConnectionSettings(ctx, option, name, settingsPath)
+			if err != nil {
+				return err
+			}
+			if found {
+				settingsPaths[option] = path
+				settingsIDEs = append(settingsIDEs, option)
+			}
+		}
+
+		if !hostConfig && !hostBlock && len(settingsIDEs) == 0 {
+			cmdio.LogString(ctx, fmt.Sprintf("Nothing to remove for '%s'", name))
+			return nil
+		}
+
+		if !autoApprove {
+			if !cmdio.IsPromptSupported(ctx) {
+				return errors.New("use --auto-approve to skip the confirmation prompt")
+			}
+			cmdio.LogString(ctx, fmt.Sprintf("The following configuration of '%s' will be removed:", name))
+			if hostConfig {
+				hostConfigPath, err := sshconfig.GetHostConfigPath(ctx, name)
+				if err != nil {
+					return err
+				}
+				cmdio.LogString(ctx, "  SSH config: "+filepath.ToSlash(hostConfigPath))
+			}
+			if hostBlock {
+				cmdio.LogString(ctx, "  SSH config block: "+filepath.ToSlash(mainConfigPath))
+			}
+			for _, option := range settingsIDEs {
+				cmdio.LogString(ctx, fmt.Sprintf("  %s settings: %s", vscode.IDEName(option), filepath.ToSlash(settingsPaths[option])))
+			}
+			confirmed, err := cmdio.AskYesOrNo(ctx, "Proceed?")
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return nil
+			}
+		}
+
+		if hostConfig {
+			if _, err := sshconfig.RemoveHostConfig(ctx, name); err != nil {
+				return err
+			}
+			cmdio.LogString(ctx, fmt.Sprintf("Removed SSH config for '%s'", name))
+		}
+		if hostBlock {
+			if _, err := sshconfig.RemoveHostBlock(ctx, mainConfigPath, name); err != nil {
+				return err
+			}
+			cmdio.LogString(ctx, fmt.Sprintf("Removed SSH config block for '%s'", name))
+		}
+		for _, option := range settingsIDEs {
+			if _, err := vscode.RemoveConnectionSettings(ctx, option, name, settingsPaths[option]); err != nil {
+				return err
+			}
+			cmdio.LogString(ctx, fmt.Sprintf("Removed %s settings for '%s'", vscode.IDEName(option), name))
+		}
+		return nil
+	}
+
+	return cmd
+}
@@ -0,0 +1,105 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/cmd/root"
+	"github.com/databricks/cli/experimental/ssh/internal/vscode"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/spf13/cobra"
+)
+
+func newRestoreIDESettingsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "restore-ide-settings",
+		Short: "Restore the IDE settings from a backup",
+		Long: `Restore the IDE settings from a backup.
+
+The connect and remove commands back up the settings file of your IDE before
+they update it. The original backup is the settings file before it was updated
+for the first time, and the latest backup is the settings file before its
+latest update.
+
+This command shows the top-level settings that restoring the backup changes,
+and replaces the settings file with the backup after a confirmation. If the
+settings file is the same as the backup, there is nothing to restore.
+
+` + disclaimer,
+		Args: cobra.NoArgs,
+	}
+
+	var from string
+	var ide string
+	var settingsPath string
+	var autoApprove bool
+
+	cmd.Flags().StringVar(&from, "from", "", "Backup to restore (original or latest)")
+	cmd.MarkFlagRequired("from")
+	_ = root.RegisterEnumCompletion(cmd, "from", vscode.BackupOptions())
+	cmd.Flags().StringVar(&ide, "ide", vscode.AutoOption, "IDE to restore the settings of (vscode, vscode-insiders, cursor, vscodium, windsurf, or auto to detect an IDE on PATH)")
+	cmd.Flags().StringVar(&settingsPath, "settings-path", "", "Path to the IDE settings file, if it is not in the default location")
+	cmd.Flags().MarkHidden("settings-path")
+	cmd.Flags().BoolVar(&autoApprove, "auto-approve", false, "Skip confirmation prompt")
+
+	cmd.RunE = func(cmd *cobra.Command, args []string) error {
+		ctx := cmd.Context()
+
+		if !slices.Contains(vscode.BackupOptions(), from) {
+			return fmt.Errorf("invalid --from value: %q, expected one of: %s", from, strings.Join(vscode.BackupOptions(), ", "))
+		}
+		if !vscode.IsValidIDEOption(ide) {
+			return fmt.Errorf("invalid IDE value: %q, expected one of: %s", ide, strings.Join(vscode.IDEOptions(), ", "))
+		}
+		if ide == vscode.AutoOption {
+			var err error
+			ide, err = vscode.DetectIDE(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		plan, err := vscode.PlanRestore(ctx, ide, from, settingsPath)
+		if err != nil {
+			return err
+		}
+		if plan.IsEmpty() {
+			cmdio.LogString(ctx, fmt.Sprintf("The settings in %s are the same as in the %s backup, nothing to restore", filepath.ToSlash(plan.SettingsPath), from))
+			return nil
+		}
+		cmdio.LogString(ctx, fmt.Sprintf("Restoring %s from %s changes these settings:", filepath.ToSlash(plan.SettingsPath), filepath.ToSlash(plan.BackupPath)))
+		for _, key := range plan.Added {
+			cmdio.LogString(ctx, "  + "+key)
+		}
+		for _, key := range plan.Removed {
+			cmdio.LogString(ctx, "  - "+key)
+		}
+		for _, key := range plan.Changed {
+			cmdio.LogString(ctx, "  ~ "+key)
+		}
+
+		if !autoApprove {
+			if !cmdio.IsPromptSupported(ctx) {
+				return errors.New("use --auto-approve to skip the confirmation prompt")
+			}
+			confirmed, err := cmdio.AskYesOrNo(ctx, "Proceed?")
+			if err != nil {
+				return err
+			}
+			if !confirmed {
+				return nil
+			}
+		}
+
+		if err := plan.Apply(ctx); err != nil {
+			return err
+		}
+		cmdio.LogString(ctx, fmt.Sprintf("Restored %s settings from the %s backup", vscode.IDEName(ide), from))
+		return nil
+	}
+
+	return cmd
+}
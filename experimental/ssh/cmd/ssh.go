@@ -23,6 +23,8 @@ Common workflows:
   databricks ssh connect --cluster=<cluster-id> --profile=<profile-name>  # connect to a cluster without any setup
   databricks ssh setup --name=my-compute --cluster=<cluster-id>           # update local ssh config
   ssh my-compute                                                          # connect to the compute using ssh client
+  databricks ssh check-settings --name=my-compute --ide=vscode            # check the IDE settings of the connection
+  databricks ssh remove --name=my-compute                                 # remove the ssh config and IDE settings
 
 ` + disclaimer,
 	}
@@ -30,6 +32,9 @@ Common workflows:
 	cmd.AddCommand(newSetupCommand())
 	cmd.AddCommand(newConnectCommand())
 	cmd.AddCommand(newServerCommand())
+	cmd.AddCommand(newRemoveCommand())
+	cmd.AddCommand(newCheckSettingsCommand())
+	cmd.AddCommand(newRestoreIDESettingsCommand())
 
 	return cmd
 }
@@ -63,7 +63,7 @@ type ClientOptions struct {
 	// to the cluster and proxy all traffic through stdin/stdout.
 	// In the non proxy mode the CLI spawns an ssh client with the ProxyCommand config.
 	ProxyMode bool
-	// Open remote IDE window with a specific ssh config (empty, 'vscode', or 'cursor')
+	// Open remote IDE window with a specific ssh config (empty, 'auto', or a registered IDE such as 'vscode' or 'cursor')
 	IDE string
 	// Expected format: "<user_name>,<port>,<cluster_id>".
 	// If present, the CLI won't attempt to start the server.
@@ -97,6 +97,21 @@ type ClientOptions struct {
 	SkipSettingsCheck bool
 	// Environment version for serverless compute.
 	EnvironmentVersion int
+	// Range of ports the IDE server picks from on the remote side, in the format "N-M".
+	PortRange string
+	// Optional path to the IDE settings file, for IDEs whose settings are not in the default location.
+	SettingsPath string
+	// If true, missing IDE settings are applied without a prompt.
+	AutoApproveIDESettings bool
+	// Extensions that the IDE installs on the remote side. If nil, the default extensions are used,
+	// and if empty, the extensions are not checked.
+	IDEExtensions []string
+	// If true, the SSH config of the IDE connection is written into a managed block in ~/.ssh/config,
+	// instead of a separate file that ~/.ssh/config includes.
+	// In the proxy mode, the block is removed when the SSH session disconnects.
+	InlineSSHConfig bool
+	// If true, the managed block of InlineSSHConfig is added to ~/.ssh/config without a prompt.
+	AutoApproveSSHConfig bool
 }
 
 func (o *ClientOptions) Validate() error {
@@ -112,8 +127,19 @@ func (o *ClientOptions) Validate() error {
 	if o.ConnectionName != "" && !connectionNameRegex.MatchString(o.ConnectionName) {
 		return fmt.Errorf("connection name %q must consist of letters, numbers, dashes, and underscores", o.ConnectionName)
 	}
-	if o.IDE != "" && o.IDE != vscode.VSCodeOption && o.IDE != vscode.CursorOption {
-		return fmt.Errorf("invalid IDE value: %q, expected %q or %q", o.IDE, vscode.VSCodeOption, vscode.CursorOption)
+	if o.IDE != "" && !vscode.IsValidIDEOption(o.IDE) {
+		return fmt.Errorf("invalid IDE value: %q, expected one of: %s", o.IDE, strings.Join(vscode.IDEOptions(), ", "))
+	}
+	if o.SettingsPath != "" && o.IDE == "" {
+		return errors.New("--settings-path flag can only be used with --ide flag")
+	}
+	if o.PortRange != "" {
+		if err := vscode.ValidatePortRange(o.PortRange); err != nil {
+			return err
+		}
+	}
+	if err := vscode.ValidateExtensions(o.IDEExtensions); err != nil {
+		return err
 	}
 	if o.EnvironmentVersion > 0 && o.EnvironmentVersion < minEnvironmentVersion {
 		return fmt.Errorf("environment version must be >= %d, got %d", minEnvironmentVersion, o.EnvironmentVersion)
@@ -187,6 +213,10 @@ func (o *ClientOptions) ToProxyCommand() (string, error) {
 		proxyCommand += " --environment-version=" + strconv.Itoa(o.EnvironmentVersion)
 	}
 
+	if o.InlineSSHConfig {
+		proxyCommand += " --inline-ssh-config"
+	}
+
 	return proxyCommand, nil
 }
 
@@ -214,11 +244,16 @@ func Run(ctx context.Context, client *databricks.WorkspaceClient, opts ClientOpt
 		}
 	}
 
-	if opts.IDE != "" && !opts.ProxyMode {
-		if err := vscode.CheckIDECommand(opts.IDE); err != nil {
+	if opts.IDE == vscode.AutoOption && !opts.ProxyMode {
+		ide, err := vscode.DetectIDE(ctx)
+		if err != nil {
 			return err
 		}
-		if err := vscode.CheckIDESSHExtension(ctx, opts.IDE); err != nil {
+		opts.IDE = ide
+	}
+
+	if opts.IDE != "" && !opts.ProxyMode {
+		if err := checkIDE(ctx, opts.IDE); err != nil {
 			return err
 		}
 	}
@@ -226,12 +261,15 @@ func Run(ctx context.Context, client *databricks.WorkspaceClient, opts ClientOpt
 	// Check and update IDE settings for serverless mode, where we must set up
 	// desired server ports (or socket connection mode) for the connection to go through
 	// (as the majority of the localhost ports on the remote side are blocked by iptable rules).
-	// Plus the platform (always linux), and extensions (python and jupyter), to make the initial experience smoother.
-	if opts.IDE != "" && opts.IsServerlessMode() && !opts.ProxyMode && !opts.SkipSettingsCheck && cmdio.IsPromptSupported(ctx) {
-		err := vscode.CheckAndUpdateSettings(ctx, opts.IDE, opts.ConnectionName)
+	// Plus the platform (always linux), and extensions (python and jupyter by default), to make the initial experience smoother.
+	if opts.IDE != "" && opts.IsServerlessMode() && !opts.ProxyMode && !opts.SkipSettingsCheck {
+		err := vscode.CheckAndUpdateSettings(ctx, opts.IDE, opts.ConnectionName, opts.PortRange, opts.SettingsPath, opts.IDEExtensions, opts.AutoApproveIDESettings)
 		if err != nil {
 			cmdio.LogString(ctx, fmt.Sprintf("Failed to update IDE settings: %v", err))
-			cmdio.LogString(ctx, vscode.GetManualInstructions(opts.IDE, opts.ConnectionName))
+			cmdio.LogString(ctx, vscode.GetManualInstructions(opts.IDE, opts.ConnectionName, opts.PortRange, opts.SettingsPath, opts.IDEExtensions))
+			if !cmdio.IsPromptSupported(ctx) {
+				return errors.New("aborted: IDE settings need to be updated manually. Use --skip-settings-check to bypass IDE settings verification")
+			}
 			cmdio.LogString(ctx, "Use --skip-settings-check to bypass IDE settings verification.")
 			shouldProceed, promptErr := cmdio.AskYesOrNo(ctx, "Do you want to proceed with the connection?")
 			if promptErr != nil {
@@ -334,7 +372,11 @@ func Run(ctx context.Context, client *databricks.WorkspaceClient, opts ClientOpt
 	}
 
 	if opts.ProxyMode {
-		return runSSHProxy(ctx, client, serverPort, clusterID, opts)
+		err := runSSHProxy(ctx, client, serverPort, clusterID, opts)
+		if opts.InlineSSHConfig {
+			removeSSHConfigBlock(ctx, opts.SessionIdentifier())
+		}
+		return err
 	} else if opts.IDE != "" {
 		return runIDE(ctx, client, userName, keyPath, serverPort, clusterID, opts)
 	} else {
@@ -343,6 +385,17 @@ func Run(ctx context.Context, client *databricks.WorkspaceClient, opts ClientOpt
 	}
 }
 
+// checkIDE verifies that the IDE can be launched with a remote SSH connection.
+// The extension is checked first: without the IDE command on PATH, it is
+// verified from the extensions directory of the IDE, so that a missing or
+// outdated extension is reported along with the missing command.
+func checkIDE(ctx context.Context, ide string) error {
+	if err := vscode.CheckIDESSHExtension(ctx, ide); err != nil {
+		return err
+	}
+	return vscode.CheckIDECommand(ide)
+}
+
 func runIDE(ctx context.Context, client *databricks.WorkspaceClient, userName, keyPath string, serverPort int, clusterID string, opts ClientOptions) error {
 	connectionName := opts.SessionIdentifier()
 	if connectionName == "" {
@@ -370,12 +423,6 @@ func runIDE(ctx context.Context, client *databricks.WorkspaceClient, userName, k
 }
 
 func ensureSSHConfigEntry(ctx context.Context, configPath, hostName, userName, keyPath string, serverPort int, clusterID string, opts ClientOptions) error {
-	// Ensure the Include directive exists in the main SSH config
-	err := sshconfig.EnsureIncludeDirective(ctx, configPath)
-	if err != nil {
-		return err
-	}
-
 	// Generate ProxyCommand with server metadata
 	optsWithMetadata := opts
 	optsWithMetadata.ServerMetadata = FormatMetadata(userName, serverPort, clusterID)
@@ -387,6 +434,20 @@ func ensureSSHConfigEntry(ctx context.Context, configPath, hostName, userName, k
 
 	hostConfig := sshconfig.GenerateHostConfig(hostName, userName, keyPath, proxyCommand)
 
+	if opts.InlineSSHConfig {
+		if _, err := sshconfig.EnsureHostBlock(ctx, configPath, hostName, hostConfig, opts.AutoApproveSSHConfig); err != nil {
+			return err
+		}
+		log.Infof(ctx, "Updated SSH config block for '%s'", hostName)
+		return nil
+	}
+
+	// Ensure the Include directive exists in the main SSH config
+	err = sshconfig.EnsureIncludeDirective(ctx, configPath)
+	if err != nil {
+		return err
+	}
+
 	_, err = sshconfig.CreateOrUpdateHostConfig(ctx, hostName, hostConfig, true)
 	if err != nil {
 		return err
@@ -396,6 +457,25 @@ func ensureSSHConfigEntry(ctx context.Context, configPath, hostName, userName, k
 	return nil
 }
 
+// removeSSHConfigBlock removes the managed block of the host from ~/.ssh/config when the SSH session
+// of the IDE disconnects. The next connect command adds it again. Errors are logged, because the proxy
+// has already finished.
+func removeSSHConfigBlock(ctx context.Context, hostName string) {
+	configPath, err := sshconfig.GetMainConfigPath(ctx)
+	if err != nil {
+		log.Warnf(ctx, "Failed to get SSH config path: %v", err)
+		return
+	}
+	removed, err := sshconfig.RemoveHostBlock(ctx, configPath, hostName)
+	if err != nil {
+		log.Warnf(ctx, "Failed to remove SSH config block for '%s': %v", hostName, err)
+		return
+	}
+	if removed {
+		log.Infof(ctx, "Removed SSH config block for '%s'", hostName)
+	}
+}
+
 // getServerMetadata retrieves the server metadata from the workspace and validates it via Driver Proxy.
 // sessionID is the unique identifier for the session (cluster ID for dedicated clusters, connection name for serverless).
 // For dedicated clusters, clusterID should be the same as sessionID.
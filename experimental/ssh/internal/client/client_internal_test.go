@@ -0,0 +1,40 @@
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/cli/experimental/ssh/internal/vscode"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckIDE_CommandNotOnPath(t *testing.T) {
+	// Neither the IDE command nor any other command is on PATH.
+	t.Setenv("PATH", t.TempDir())
+
+	t.Run("extension installed", func(t *testing.T) {
+		home := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(home, ".vscode", "extensions", "ms-vscode-remote.remote-ssh-0.120.0"), 0o755))
+		ctx := env.Set(t.Context(), env.HomeEnvVar(), home)
+		ctx, _ = cmdio.NewTestContextWithStderr(ctx)
+
+		// The extension is found in the extensions directory, so only the
+		// missing command is reported.
+		err := checkIDE(ctx, vscode.VSCodeOption)
+		assert.ErrorContains(t, err, `"code" command not found on PATH`)
+	})
+
+	t.Run("extension missing", func(t *testing.T) {
+		home := t.TempDir()
+		require.NoError(t, os.MkdirAll(filepath.Join(home, ".vscode", "extensions"), 0o755))
+		ctx := env.Set(t.Context(), env.HomeEnvVar(), home)
+		ctx, _ = cmdio.NewTestContextWithStderr(ctx)
+
+		err := checkIDE(ctx, vscode.VSCodeOption)
+		assert.ErrorContains(t, err, `Required extension "Remote - SSH" is not installed in VS Code.`)
+	})
+}
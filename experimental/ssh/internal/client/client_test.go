@@ -74,7 +74,7 @@ func TestValidate(t *testing.T) {
 		{
 			name:    "invalid IDE value",
 			opts:    client.ClientOptions{ClusterID: "abc-123", IDE: "vim"},
-			wantErr: `invalid IDE value: "vim", expected "vscode" or "cursor"`,
+			wantErr: `invalid IDE value: "vim", expected one of: vscode, vscode-insiders, cursor, vscodium, windsurf, auto`,
 		},
 		{
 			name: "valid IDE vscode",
@@ -84,6 +84,18 @@ func TestValidate(t *testing.T) {
 			name: "valid IDE cursor",
 			opts: client.ClientOptions{ClusterID: "abc-123", IDE: "cursor"},
 		},
+		{
+			name: "valid IDE vscodium",
+			opts: client.ClientOptions{ClusterID: "abc-123", IDE: "vscodium"},
+		},
+		{
+			name: "valid IDE windsurf",
+			opts: client.ClientOptions{ClusterID: "abc-123", IDE: "windsurf"},
+		},
+		{
+			name: "valid IDE auto",
+			opts: client.ClientOptions{ClusterID: "abc-123", IDE: "auto"},
+		},
 		{
 			name:    "environment version too low",
 			opts:    client.ClientOptions{ClusterID: "abc-123", EnvironmentVersion: 3},
@@ -93,6 +105,37 @@ func TestValidate(t *testing.T) {
 			name: "valid environment version",
 			opts: client.ClientOptions{ClusterID: "abc-123", EnvironmentVersion: 4},
 		},
+		{
+			name:    "settings path without IDE",
+			opts:    client.ClientOptions{ConnectionName: "my-conn", SettingsPath: "settings.json"},
+			wantErr: "--settings-path flag can only be used with --ide flag",
+		},
+		{
+			name: "settings path with IDE",
+			opts: client.ClientOptions{ConnectionName: "my-conn", IDE: "vscode", SettingsPath: "settings.json"},
+		},
+		{
+			name: "valid port range",
+			opts: client.ClientOptions{ConnectionName: "my-conn", PortRange: "4000-4005"},
+		},
+		{
+			name:    "invalid port range",
+			opts:    client.ClientOptions{ConnectionName: "my-conn", PortRange: "4005-4000"},
+			wantErr: `invalid port range "4005-4000", the last port must be greater than the first one`,
+		},
+		{
+			name: "custom IDE extensions",
+			opts: client.ClientOptions{ConnectionName: "my-conn", IDEExtensions: []string{"scala-lang.scala"}},
+		},
+		{
+			name: "no IDE extensions",
+			opts: client.ClientOptions{ConnectionName: "my-conn", IDEExtensions: []string{}},
+		},
+		{
+			name:    "invalid IDE extension",
+			opts:    client.ClientOptions{ConnectionName: "my-conn", IDEExtensions: []string{"scala"}},
+			wantErr: `invalid extension ID "scala", expected the format publisher.name, for example ms-python.python`,
+		},
 	}
 
 	for _, tt := range tests {
@@ -162,6 +205,11 @@ func TestToProxyCommand(t *testing.T) {
 			opts: client.ClientOptions{ClusterID: "abc-123", EnvironmentVersion: 4},
 			want: quoted + " ssh connect --proxy --cluster=abc-123 --auto-start-cluster=false --shutdown-delay=0s --environment-version=4",
 		},
+		{
+			name: "with inline SSH config",
+			opts: client.ClientOptions{ConnectionName: "my-conn", InlineSSHConfig: true},
+			want: quoted + " ssh connect --proxy --name=my-conn --shutdown-delay=0s --inline-ssh-config",
+		},
 	}
 
 	for _, tt := range tests {
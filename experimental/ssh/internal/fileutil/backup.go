@@ -13,6 +13,7 @@ import (
 const (
 	SuffixOriginalBak = ".original.bak"
 	SuffixLatestBak   = ".latest.bak"
+	SuffixCorruptBak  = ".corrupt.bak"
 )
 
 // BackupFile saves data to path+".original.bak" on the first call, and
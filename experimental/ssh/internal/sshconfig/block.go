@@ -0,0 +1,164 @@
+package sshconfig
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/databricks/cli/experimental/ssh/internal/fileutil"
+	"github.com/databricks/cli/libs/cmdio"
+	libsfileutil "github.com/databricks/cli/libs/fileutil"
+)
+
+// blockMarkers returns the lines that delimit the managed block of the host in
+// the main SSH config.
+func blockMarkers(hostName string) (string, string) {
+	return "# BEGIN databricks-cli ssh " + hostName, "# END databricks-cli ssh " + hostName
+}
+
+// findBlock returns the start and end offsets of the managed block of the
+// host in content, including the end marker and its line ending. It returns
+// false if content doesn't have a complete block.
+func findBlock(content, hostName string) (int, int, bool) {
+	begin, end := blockMarkers(hostName)
+	start := -1
+	offset := 0
+	for line := range strings.SplitAfterSeq(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if start < 0 && trimmed == begin {
+			start = offset
+		} else if start >= 0 && trimmed == end {
+			return start, offset + len(line), true
+		}
+		offset += len(line)
+	}
+	return 0, 0, false
+}
+
+// GenerateHostBlock wraps the host config in the markers of the managed block.
+func GenerateHostBlock(hostName, hostConfig string) string {
+	begin, end := blockMarkers(hostName)
+	return begin + "\n" + strings.Trim(hostConfig, "\n") + "\n" + end + "\n"
+}
+
+// HostBlockExists reports whether the SSH config has a managed block for the host.
+func HostBlockExists(configPath, hostName string) (bool, error) {
+	content, err := os.ReadFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read SSH config file: %w", err)
+	}
+	_, _, found := findBlock(string(content), hostName)
+	return found, nil
+}
+
+// EnsureHostBlock adds the host config to the SSH config at configPath, in a
+// block delimited by markers, or replaces the block if it exists. A new block
+// is added at the top of the file, like the Include directive, because SSH uses
+// the first value it finds for each option and a "Host *" section further down
+// must not override it. The content outside of the block is left as-is. The
+// SSH config is backed up before it is changed, and its directory is created
+// if it doesn't exist.
+//
+// Unless autoApprove is set, the user is asked for a confirmation before the
+// block is added, and an error is returned if they decline. Updates of an
+// existing block are not confirmed, as the server metadata in the ProxyCommand
+// changes whenever the server restarts. Returns true if the SSH config was
+// changed.
+func EnsureHostBlock(ctx context.Context, configPath, hostName, hostConfig string, autoApprove bool) (bool, error) {
+	content, err := os.ReadFile(configPath)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("failed to read SSH config file: %w", err)
+	}
+
+	block := GenerateHostBlock(hostName, hostConfig)
+	var newContent string
+	start, end, found := findBlock(string(content), hostName)
+	if found {
+		if string(content[start:end]) == block {
+			return false, nil
+		}
+		newContent = string(content[:start]) + block + string(content[end:])
+	} else {
+		newContent = block
+		if len(content) > 0 && !strings.HasPrefix(string(content), "\n") {
+			newContent += "\n"
+		}
+		newContent += string(content)
+	}
+
+	if !autoApprove && !found {
+		if !cmdio.IsPromptSupported(ctx) {
+			return false, fmt.Errorf("SSH config for '%s' needs to be updated in %s, but prompts are not supported. Use --auto-approve-ssh-config to write it without a prompt", hostName, filepath.ToSlash(configPath))
+		}
+		question := fmt.Sprintf("The following SSH config will be written to %s:\n\n%s\nProceed?", filepath.ToSlash(configPath), block)
+		confirmed, err := cmdio.AskYesOrNo(ctx, question)
+		if err != nil {
+			return false, err
+		}
+		if !confirmed {
+			return false, fmt.Errorf("aborted: SSH config for '%s' was not written to %s", hostName, filepath.ToSlash(configPath))
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o700); err != nil {
+		return false, fmt.Errorf("failed to create SSH directory: %w", err)
+	}
+	if err := fileutil.BackupFile(ctx, configPath, content); err != nil {
+		return false, fmt.Errorf("failed to backup SSH config: %w", err)
+	}
+	if err := writeConfig(configPath, newContent); err != nil {
+		return false, fmt.Errorf("failed to write SSH config file: %w", err)
+	}
+	return true, nil
+}
+
+// RemoveHostBlock removes the managed block of the host from the SSH config,
+// after backing it up. Returns true if it was removed, false if there was none.
+func RemoveHostBlock(ctx context.Context, configPath, hostName string) (bool, error) {
+	content, err := os.ReadFile(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read SSH config file: %w", err)
+	}
+
+	start, end, found := findBlock(string(content), hostName)
+	if !found {
+		return false, nil
+	}
+	// Drop the blank line that separates the block from the rest of the file.
+	// EnsureHostBlock adds it after the block, which is at the top of the file,
+	// but a block that was moved further down is preceded by one.
+	before, after := string(content[:start]), string(content[end:])
+	if before == "" {
+		after = strings.TrimPrefix(after, "\n")
+	} else if strings.HasSuffix(before, "\n\n") {
+		before = before[:len(before)-1]
+	}
+
+	if err := fileutil.BackupFile(ctx, configPath, content); err != nil {
+		return false, fmt.Errorf("failed to backup SSH config: %w", err)
+	}
+	if err := writeConfig(configPath, before+after); err != nil {
+		return false, fmt.Errorf("failed to write SSH config file: %w", err)
+	}
+	return true, nil
+}
+
+// writeConfig atomically replaces the SSH config at configPath with content,
+// so that an SSH client that reads it concurrently never sees a partial file.
+func writeConfig(configPath, content string) error {
+	return libsfileutil.WriteFile(configPath, 0o600, func(w io.Writer) error {
+		_, err := io.WriteString(w, content)
+		return err
+	})
+}
@@ -0,0 +1,241 @@
+package sshconfig
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/databricks/cli/experimental/ssh/internal/fileutil"
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testHostConfig = "Host my-conn\n    User root\n    ProxyCommand databricks ssh connect --proxy\n"
+
+func writeTestConfig(t *testing.T, content string) string {
+	configPath := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(configPath, []byte(content), 0o600))
+	return configPath
+}
+
+func readTestConfig(t *testing.T, configPath string) string {
+	content, err := os.ReadFile(configPath)
+	require.NoError(t, err)
+	return string(content)
+}
+
+func TestGenerateHostBlock(t *testing.T) {
+	block := GenerateHostBlock("my-conn", testHostConfig)
+	assert.Equal(t, `# BEGIN databricks-cli ssh my-conn
+Host my-conn
+    User root
+    ProxyCommand databricks ssh connect --proxy
+# END databricks-cli ssh my-conn
+`, block)
+}
+
+func TestEnsureHostBlock_RoundTrip(t *testing.T) {
+	original := "Host other\n    HostName example.com\n"
+	configPath := writeTestConfig(t, original)
+	ctx := t.Context()
+
+	changed, err := EnsureHostBlock(ctx, configPath, "my-conn", testHostConfig, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, GenerateHostBlock("my-conn", testHostConfig)+"\n"+original, readTestConfig(t, configPath))
+
+	exists, err := HostBlockExists(configPath, "my-conn")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	// Writing the same config again is a no-op.
+	changed, err = EnsureHostBlock(ctx, configPath, "my-conn", testHostConfig, true)
+	require.NoError(t, err)
+	assert.False(t, changed)
+
+	// An updated config replaces the block in place.
+	updated := "Host my-conn\n    User admin\n"
+	changed, err = EnsureHostBlock(ctx, configPath, "my-conn", updated, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, GenerateHostBlock("my-conn", updated)+"\n"+original, readTestConfig(t, configPath))
+
+	removed, err := RemoveHostBlock(ctx, configPath, "my-conn")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.Equal(t, original, readTestConfig(t, configPath))
+
+	removed, err = RemoveHostBlock(ctx, configPath, "my-conn")
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestEnsureHostBlock_PreservesSurroundingContent(t *testing.T) {
+	configPath := writeTestConfig(t, `Host before
+    User a
+
+# BEGIN databricks-cli ssh my-conn
+Host my-conn
+    User old
+# END databricks-cli ssh my-conn
+
+# BEGIN databricks-cli ssh other-conn
+Host other-conn
+    User b
+# END databricks-cli ssh other-conn
+
+Host after
+    User c
+`)
+
+	changed, err := EnsureHostBlock(t.Context(), configPath, "my-conn", "Host my-conn\n    User new\n", true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, `Host before
+    User a
+
+# BEGIN databricks-cli ssh my-conn
+Host my-conn
+    User new
+# END databricks-cli ssh my-conn
+
+# BEGIN databricks-cli ssh other-conn
+Host other-conn
+    User b
+# END databricks-cli ssh other-conn
+
+Host after
+    User c
+`, readTestConfig(t, configPath))
+
+	removed, err := RemoveHostBlock(t.Context(), configPath, "other-conn")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.Equal(t, `Host before
+    User a
+
+# BEGIN databricks-cli ssh my-conn
+Host my-conn
+    User new
+# END databricks-cli ssh my-conn
+
+Host after
+    User c
+`, readTestConfig(t, configPath))
+}
+
+func TestEnsureHostBlock_NoTrailingNewline(t *testing.T) {
+	configPath := writeTestConfig(t, "Host other")
+
+	_, err := EnsureHostBlock(t.Context(), configPath, "my-conn", testHostConfig, true)
+	require.NoError(t, err)
+	assert.Equal(t, GenerateHostBlock("my-conn", testHostConfig)+"\nHost other", readTestConfig(t, configPath))
+
+	_, err = RemoveHostBlock(t.Context(), configPath, "my-conn")
+	require.NoError(t, err)
+	assert.Equal(t, "Host other", readTestConfig(t, configPath))
+}
+
+func TestEnsureHostBlock_BeforeHostWildcard(t *testing.T) {
+	original := "Host *\n    User someone\n"
+	configPath := writeTestConfig(t, original)
+
+	_, err := EnsureHostBlock(t.Context(), configPath, "my-conn", testHostConfig, true)
+	require.NoError(t, err)
+
+	// SSH uses the first value of each option, so the block must come first.
+	content := readTestConfig(t, configPath)
+	assert.Less(t, strings.Index(content, "Host my-conn"), strings.Index(content, "Host *"))
+}
+
+func TestEnsureHostBlock_CreatesConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), ".ssh", "config")
+
+	changed, err := EnsureHostBlock(t.Context(), configPath, "my-conn", testHostConfig, true)
+	require.NoError(t, err)
+	assert.True(t, changed)
+	assert.Equal(t, GenerateHostBlock("my-conn", testHostConfig), readTestConfig(t, configPath))
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Dir(configPath))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o700), info.Mode().Perm())
+
+		info, err = os.Stat(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	}
+
+	removed, err := RemoveHostBlock(t.Context(), configPath, "my-conn")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.Empty(t, readTestConfig(t, configPath))
+}
+
+func TestEnsureHostBlock_BacksUpConfig(t *testing.T) {
+	original := "Host other\n"
+	configPath := writeTestConfig(t, original)
+
+	_, err := EnsureHostBlock(t.Context(), configPath, "my-conn", testHostConfig, true)
+	require.NoError(t, err)
+
+	backup, err := os.ReadFile(configPath + fileutil.SuffixOriginalBak)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(backup))
+}
+
+func TestEnsureHostBlock_Prompt(t *testing.T) {
+	original := "Host other\n"
+
+	t.Run("not supported", func(t *testing.T) {
+		configPath := writeTestConfig(t, original)
+		ctx, _ := cmdio.NewTestContextWithStderr(t.Context())
+
+		_, err := EnsureHostBlock(ctx, configPath, "my-conn", testHostConfig, false)
+		assert.ErrorContains(t, err, "prompts are not supported. Use --auto-approve-ssh-config")
+		assert.Equal(t, original, readTestConfig(t, configPath))
+	})
+
+	for _, tt := range []struct {
+		answer  string
+		changed bool
+	}{
+		{"y\n", true},
+		{"n\n", false},
+	} {
+		t.Run(tt.answer[:1], func(t *testing.T) {
+			configPath := writeTestConfig(t, original)
+			ctx, tst := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+			defer tst.Done()
+
+			// Drain stderr (where the prompt is written) and feed the answer to stdin.
+			go func() { _, _ = io.Copy(io.Discard, tst.Stderr) }()
+			go func() {
+				_, _ = tst.Stdin.WriteString(tt.answer)
+				_ = tst.Stdin.Flush()
+			}()
+
+			changed, err := EnsureHostBlock(ctx, configPath, "my-conn", testHostConfig, false)
+			if tt.changed {
+				require.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, "aborted: SSH config for 'my-conn' was not written")
+			}
+			assert.Equal(t, tt.changed, changed)
+
+			exists, err := HostBlockExists(configPath, "my-conn")
+			require.NoError(t, err)
+			assert.Equal(t, tt.changed, exists)
+		})
+	}
+}
+
+func TestHostBlockExists_MissingConfig(t *testing.T) {
+	exists, err := HostBlockExists(filepath.Join(t.TempDir(), "config"), "my-conn")
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,52 @@
+package sshconfig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/databricks/cli/libs/env"
+)
+
+// settingsFileName is the file with the user settings of SSH connections, relative to the user's home directory.
+// It is not in configDirName, because ~/.ssh/config includes every file of that directory.
+const settingsFileName = ".databricks/ssh-tunnel.json"
+
+// Settings are the user settings of SSH connections that apply when the corresponding flags are not set.
+type Settings struct {
+	// Extensions that the IDE installs on the remote side. If nil, the default extensions are used,
+	// and if empty, the extensions are not managed.
+	IDEExtensions []string `json:"ide_extensions"`
+}
+
+func GetSettingsPath(ctx context.Context) (string, error) {
+	homeDir, err := env.UserHomeDir(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, settingsFileName), nil
+}
+
+// LoadSettings reads the settings file. It returns empty settings if the file doesn't exist.
+func LoadSettings(ctx context.Context) (*Settings, error) {
+	path, err := GetSettingsPath(ctx)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SSH settings file: %w", err)
+	}
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse SSH settings file %s: %w", path, err)
+	}
+	return &settings, nil
+}
@@ -0,0 +1,66 @@
+package sshconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeSettings(t *testing.T, content string) {
+	path, err := GetSettingsPath(t.Context())
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
+
+func TestLoadSettingsMissingFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	settings, err := LoadSettings(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, settings.IDEExtensions)
+}
+
+func TestLoadSettingsIDEExtensions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	writeSettings(t, `{"ide_extensions": ["scala-lang.scala", "reditorsupport.r"]}`)
+
+	settings, err := LoadSettings(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"scala-lang.scala", "reditorsupport.r"}, settings.IDEExtensions)
+}
+
+func TestLoadSettingsEmptyIDEExtensions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	writeSettings(t, `{"ide_extensions": []}`)
+
+	settings, err := LoadSettings(t.Context())
+	require.NoError(t, err)
+	assert.NotNil(t, settings.IDEExtensions)
+	assert.Empty(t, settings.IDEExtensions)
+}
+
+func TestLoadSettingsWithoutIDEExtensions(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	writeSettings(t, `{}`)
+
+	settings, err := LoadSettings(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, settings.IDEExtensions)
+}
+
+func TestLoadSettingsInvalidJSON(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	writeSettings(t, `{"ide_extensions": "ms-python.python"}`)
+
+	_, err := LoadSettings(t.Context())
+	assert.ErrorContains(t, err, "failed to parse SSH settings file")
+}
@@ -163,6 +163,23 @@ func HostConfigExists(ctx context.Context, hostName string) (bool, error) {
 	return true, nil
 }
 
+// RemoveHostConfig removes the config file of the host.
+// Returns true if it was removed, false if it didn't exist.
+func RemoveHostConfig(ctx context.Context, hostName string) (bool, error) {
+	configPath, err := GetHostConfigPath(ctx, hostName)
+	if err != nil {
+		return false, err
+	}
+	err = os.Remove(configPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to remove host config file: %w", err)
+	}
+	return true, nil
+}
+
 // Returns true if the config was created/updated, false if it was skipped.
 func CreateOrUpdateHostConfig(ctx context.Context, hostName, hostConfig string, recreate bool) (bool, error) {
 	configPath, err := GetHostConfigPath(ctx, hostName)
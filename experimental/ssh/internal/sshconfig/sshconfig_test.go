@@ -327,6 +327,27 @@ func TestHostConfigExists(t *testing.T) {
 	assert.True(t, exists)
 }
 
+func TestRemoveHostConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("USERPROFILE", tmpDir)
+
+	removed, err := RemoveHostConfig(t.Context(), "nonexistent")
+	assert.NoError(t, err)
+	assert.False(t, removed)
+
+	configDir := filepath.Join(tmpDir, configDirName)
+	err = os.MkdirAll(configDir, 0o700)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(configDir, "existing-host"), []byte("config"), 0o600)
+	require.NoError(t, err)
+
+	removed, err = RemoveHostConfig(t.Context(), "existing-host")
+	assert.NoError(t, err)
+	assert.True(t, removed)
+	assert.NoFileExists(t, filepath.Join(configDir, "existing-host"))
+}
+
 func TestCreateOrUpdateHostConfig_NewConfig(t *testing.T) {
 	ctx := cmdio.MockDiscard(t.Context())
 	tmpDir := t.TempDir()
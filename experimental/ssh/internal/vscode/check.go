@@ -0,0 +1,129 @@
+package vscode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/databricks/cli/experimental/ssh/internal/fileutil"
+	"github.com/tailscale/hujson"
+)
+
+// SettingStatus is the state of a setting that a connection requires.
+type SettingStatus struct {
+	Key      string `json:"key"`
+	Current  string `json:"current"`
+	Expected string `json:"expected"`
+	OK       bool   `json:"ok"`
+}
+
+// SettingsBackup is a backup of the settings file, written before the file
+// was updated.
+type SettingsBackup struct {
+	Path     string    `json:"path"`
+	Modified time.Time `json:"modified"`
+}
+
+// SettingsReport describes how the IDE settings differ from the settings
+// that a connection requires.
+type SettingsReport struct {
+	IDE          string           `json:"ide"`
+	Connection   string           `json:"connection"`
+	SettingsPath string           `json:"settings_path"`
+	Exists       bool             `json:"exists"`
+	Settings     []SettingStatus  `json:"settings"`
+	Backups      []SettingsBackup `json:"backups"`
+}
+
+// Missing returns the number of settings that are missing or incorrect.
+func (r *SettingsReport) Missing() int {
+	n := 0
+	for _, s := range r.Settings {
+		if !s.OK {
+			n++
+		}
+	}
+	return n
+}
+
+// CheckSettings compares the IDE settings with the settings that
+// CheckAndUpdateSettings would apply for the connection, without modifying
+// the settings file. If settingsPath is empty, the default user settings file
+// of the IDE is used. If portRange is empty, any range that isn't used by other
+// connections is accepted. The extensions are interpreted as in
+// CheckAndUpdateSettings.
+func CheckSettings(ctx context.Context, ide, connectionName, portRange, settingsPath string, extensions []string) (*SettingsReport, error) {
+	if settingsPath == "" {
+		var err error
+		settingsPath, err = getDefaultSettingsPath(ctx, ide)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings path: %w", err)
+		}
+	}
+
+	report := &SettingsReport{
+		IDE:          getIDE(ide).Name,
+		Connection:   connectionName,
+		SettingsPath: settingsPath,
+		Exists:       true,
+		Settings:     []SettingStatus{},
+		Backups:      []SettingsBackup{},
+	}
+
+	settings, err := loadSettings(settingsPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		report.Exists = false
+		settings = hujson.Value{Value: &hujson.Object{}}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	portRange = choosePortRange(settings, connectionName, portRange)
+	missing := validateSettings(settings, connectionName, portRange, extensions)
+	report.Settings = append(report.Settings,
+		SettingStatus{
+			Key:      serverPickPortsKey,
+			Current:  missing.currentPortRange,
+			Expected: missing.expectedPortRange,
+			OK:       !missing.portRange,
+		},
+		SettingStatus{
+			Key:      remotePlatformKey,
+			Current:  missing.currentPlatform,
+			Expected: remotePlatform,
+			OK:       !missing.platform,
+		},
+		SettingStatus{
+			Key:      listenOnSocketKey,
+			Current:  missing.currentListenOnSocket,
+			Expected: "true",
+			OK:       !missing.listenOnSocket,
+		},
+	)
+	if required := requiredExtensions(extensions); len(required) > 0 {
+		report.Settings = append(report.Settings, SettingStatus{
+			Key:      defaultExtensionsKey,
+			Current:  strings.Join(missing.currentExtensions, ", "),
+			Expected: strings.Join(required, ", "),
+			OK:       len(missing.extensions) == 0,
+		})
+	}
+
+	for _, suffix := range []string{fileutil.SuffixOriginalBak, fileutil.SuffixLatestBak} {
+		path := settingsPath + suffix
+		info, err := os.Stat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to check backup %s: %w", path, err)
+		}
+		report.Backups = append(report.Backups, SettingsBackup{Path: path, Modified: info.ModTime()})
+	}
+
+	return report, nil
+}
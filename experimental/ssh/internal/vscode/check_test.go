@@ -0,0 +1,120 @@
+package vscode
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/cli/experimental/ssh/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSettings_Correct(t *testing.T) {
+	settingsPath := writeTestSettings(t, `{
+		"remote.SSH.serverPickPortsFromRange": {"my-conn": "29500-29505"},
+		"remote.SSH.remotePlatform": {"my-conn": "linux"},
+		"remote.SSH.remoteServerListenOnSocket": true,
+		"remote.SSH.defaultExtensions": ["ms-python.python", "ms-toolsai.jupyter", "databricks.databricks"]
+	}`)
+
+	report, err := CheckSettings(t.Context(), VSCodeOption, "my-conn", "", settingsPath, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, "VS Code", report.IDE)
+	assert.Equal(t, settingsPath, report.SettingsPath)
+	assert.True(t, report.Exists)
+	assert.Equal(t, 0, report.Missing())
+	assert.Equal(t, []SettingStatus{
+		{Key: serverPickPortsKey, Current: "29500-29505", Expected: "29500-29505", OK: true},
+		{Key: remotePlatformKey, Current: "linux", Expected: "linux", OK: true},
+		{Key: listenOnSocketKey, Current: "true", Expected: "true", OK: true},
+		{
+			Key:      defaultExtensionsKey,
+			Current:  "ms-python.python, ms-toolsai.jupyter, databricks.databricks",
+			Expected: "ms-python.python, ms-toolsai.jupyter, databricks.databricks",
+			OK:       true,
+		},
+	}, report.Settings)
+	assert.Empty(t, report.Backups)
+}
+
+func TestCheckSettings_Drift(t *testing.T) {
+	settingsPath := writeTestSettings(t, `{
+		"remote.SSH.serverPickPortsFromRange": {"my-conn": "4000-4005"},
+		"remote.SSH.remotePlatform": {"my-conn": "windows"},
+		"remote.SSH.remoteServerListenOnSocket": false,
+		"remote.SSH.defaultExtensions": ["ms-python.python"]
+	}`)
+
+	report, err := CheckSettings(t.Context(), VSCodeOption, "my-conn", "29500-29505", settingsPath, nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, report.Missing())
+	assert.Equal(t, []SettingStatus{
+		{Key: serverPickPortsKey, Current: "4000-4005", Expected: "29500-29505", OK: false},
+		{Key: remotePlatformKey, Current: "windows", Expected: "linux", OK: false},
+		{Key: listenOnSocketKey, Current: "false", Expected: "true", OK: false},
+		{
+			Key:      defaultExtensionsKey,
+			Current:  "ms-python.python",
+			Expected: "ms-python.python, ms-toolsai.jupyter, databricks.databricks",
+			OK:       false,
+		},
+	}, report.Settings)
+}
+
+func TestCheckSettings_MissingFile(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+
+	report, err := CheckSettings(t.Context(), VSCodeOption, "my-conn", "", settingsPath, nil)
+	require.NoError(t, err)
+
+	assert.False(t, report.Exists)
+	assert.Equal(t, 4, report.Missing())
+	assert.Equal(t, DefaultPortRange, report.Settings[0].Expected)
+	for _, s := range report.Settings {
+		assert.Empty(t, s.Current, s.Key)
+	}
+
+	// The settings file is not created.
+	_, err = os.Stat(settingsPath)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCheckSettings_DoesNotModifyFile(t *testing.T) {
+	original := `{
+		// My settings
+		"remote.SSH.remotePlatform": {"my-conn": "linux"}
+	}`
+	settingsPath := writeTestSettings(t, original)
+
+	report, err := CheckSettings(t.Context(), VSCodeOption, "my-conn", "", settingsPath, nil)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Missing())
+
+	content, err := os.ReadFile(settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+	_, err = os.Stat(settingsPath + fileutil.SuffixOriginalBak)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestCheckSettings_Backups(t *testing.T) {
+	settingsPath := writeTestSettings(t, `{}`)
+	require.NoError(t, fileutil.BackupFile(t.Context(), settingsPath, []byte(`{}`)))
+
+	report, err := CheckSettings(t.Context(), VSCodeOption, "my-conn", "", settingsPath, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Backups, 1)
+	assert.Equal(t, settingsPath+fileutil.SuffixOriginalBak, report.Backups[0].Path)
+	assert.False(t, report.Backups[0].Modified.IsZero())
+
+	require.NoError(t, fileutil.BackupFile(t.Context(), settingsPath, []byte(`{}`)))
+
+	report, err = CheckSettings(t.Context(), VSCodeOption, "my-conn", "", settingsPath, nil)
+	require.NoError(t, err)
+	require.Len(t, report.Backups, 2)
+	assert.Equal(t, settingsPath+fileutil.SuffixOriginalBak, report.Backups[0].Path)
+	assert.Equal(t, settingsPath+fileutil.SuffixLatestBak, report.Backups[1].Path)
+}
@@ -0,0 +1,160 @@
+package vscode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"slices"
+
+	"github.com/databricks/cli/experimental/ssh/internal/fileutil"
+	"github.com/databricks/cli/libs/log"
+	"github.com/tailscale/hujson"
+)
+
+const (
+	// BackupOriginal is the backup of the settings file written before it was
+	// updated for the first time.
+	BackupOriginal = "original"
+	// BackupLatest is the backup of the settings file written before its
+	// latest update.
+	BackupLatest = "latest"
+)
+
+// BackupOptions returns the valid values of the --from flag of the restore
+// command.
+func BackupOptions() []string {
+	return []string{BackupOriginal, BackupLatest}
+}
+
+// RestorePlan describes how restoring a backup changes the settings file.
+type RestorePlan struct {
+	SettingsPath string
+	BackupPath   string
+	// Top-level keys that the backup adds, removes, or sets to a different
+	// value than the settings file.
+	Added   []string
+	Removed []string
+	Changed []string
+
+	data []byte
+}
+
+// backupPath returns the path of the given backup of the settings file.
+func backupPath(settingsPath, which string) (string, error) {
+	switch which {
+	case BackupOriginal:
+		return settingsPath + fileutil.SuffixOriginalBak, nil
+	case BackupLatest:
+		return settingsPath + fileutil.SuffixLatestBak, nil
+	default:
+		return "", fmt.Errorf("invalid backup: %q, expected one of: %s, %s", which, BackupOriginal, BackupLatest)
+	}
+}
+
+// topLevelValues returns the values of the top-level keys of the settings,
+// without comments and whitespace.
+func topLevelValues(v hujson.Value) map[string]string {
+	values := map[string]string{}
+	obj, ok := v.Value.(*hujson.Object)
+	if !ok {
+		return values
+	}
+	for _, member := range obj.Members {
+		name, ok := member.Name.Value.(hujson.Literal)
+		if !ok {
+			continue
+		}
+		value := member.Value.Clone()
+		value.Minimize()
+		values[name.String()] = string(value.Pack())
+	}
+	return values
+}
+
+// PlanRestore reads the given backup of the settings file of the IDE and
+// compares it with the settings file. If settingsPath is empty, the default
+// user settings file of the IDE is used. It fails if the backup doesn't exist
+// or is not a valid settings file.
+func PlanRestore(ctx context.Context, ide, which, settingsPath string) (*RestorePlan, error) {
+	if settingsPath == "" {
+		var err error
+		settingsPath, err = getDefaultSettingsPath(ctx, ide)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get settings path: %w", err)
+		}
+	}
+	bakPath, err := backupPath(settingsPath, which)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(bakPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("no %s backup of %s exists", which, settingsPath)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+	backup, err := hujson.Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse backup %s: %w", bakPath, err)
+	}
+
+	current, err := loadSettings(settingsPath)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errSettingsNotObject) {
+		current = hujson.Value{Value: &hujson.Object{}}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to load settings: %w", err)
+	}
+
+	plan := &RestorePlan{SettingsPath: settingsPath, BackupPath: bakPath, data: data}
+	currentValues := topLevelValues(current)
+	backupValues := topLevelValues(backup)
+	for key, value := range backupValues {
+		currentValue, ok := currentValues[key]
+		if !ok {
+			plan.Added = append(plan.Added, key)
+		} else if currentValue != value {
+			plan.Changed = append(plan.Changed, key)
+		}
+	}
+	for key := range currentValues {
+		if _, ok := backupValues[key]; !ok {
+			plan.Removed = append(plan.Removed, key)
+		}
+	}
+	slices.Sort(plan.Added)
+	slices.Sort(plan.Removed)
+	slices.Sort(plan.Changed)
+	return plan, nil
+}
+
+// IsEmpty reports whether restoring the backup doesn't change any settings.
+func (p *RestorePlan) IsEmpty() bool {
+	return len(p.Added) == 0 && len(p.Removed) == 0 && len(p.Changed) == 0
+}
+
+// Apply copies the backup over the settings file.
+func (p *RestorePlan) Apply(ctx context.Context) error {
+	if err := os.WriteFile(p.SettingsPath, p.data, 0o600); err != nil {
+		return fmt.Errorf("failed to write settings file: %w", err)
+	}
+	// WriteFile only sets the permissions of new files.
+	if err := os.Chmod(p.SettingsPath, 0o600); err != nil {
+		return fmt.Errorf("failed to set permissions of settings file: %w", err)
+	}
+	log.Infof(ctx, "Restored %s from %s", p.SettingsPath, p.BackupPath)
+	return nil
+}
+
+// RestoreSettings copies the given backup over the settings file of the IDE,
+// without a confirmation. See PlanRestore for the meaning of the arguments.
+func RestoreSettings(ctx context.Context, ide, which, settingsPath string) error {
+	plan, err := PlanRestore(ctx, ide, which, settingsPath)
+	if err != nil {
+		return err
+	}
+	return plan.Apply(ctx)
+}
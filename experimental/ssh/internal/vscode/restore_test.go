@@ -0,0 +1,142 @@
+package vscode
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/databricks/cli/experimental/ssh/internal/fileutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlanRestore(t *testing.T) {
+	original := `{
+		// My settings
+		"editor.fontSize": 14,
+		"workbench.colorTheme": "Default Dark+"
+	}`
+	latest := `{
+		"editor.fontSize": 14,
+		"workbench.colorTheme": "Default Light+",
+		"remote.SSH.remotePlatform": {"conn-a": "linux"}
+	}`
+	settingsPath := writeTestSettings(t, `{
+		"editor.fontSize": 14,
+		"workbench.colorTheme": "Default Light+",
+		"remote.SSH.remotePlatform": {"conn-a": "linux", "conn-b": "linux"},
+		"remote.SSH.remoteServerListenOnSocket": true
+	}`)
+	require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixOriginalBak, []byte(original), 0o600))
+	require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixLatestBak, []byte(latest), 0o600))
+
+	plan, err := PlanRestore(t.Context(), VSCodeOption, BackupOriginal, settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, settingsPath+fileutil.SuffixOriginalBak, plan.BackupPath)
+	assert.Empty(t, plan.Added)
+	assert.Equal(t, []string{remotePlatformKey, listenOnSocketKey}, plan.Removed)
+	assert.Equal(t, []string{"workbench.colorTheme"}, plan.Changed)
+
+	plan, err = PlanRestore(t.Context(), VSCodeOption, BackupLatest, settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, settingsPath+fileutil.SuffixLatestBak, plan.BackupPath)
+	assert.Empty(t, plan.Added)
+	assert.Equal(t, []string{listenOnSocketKey}, plan.Removed)
+	assert.Equal(t, []string{remotePlatformKey}, plan.Changed)
+}
+
+func TestPlanRestore_IgnoresFormatting(t *testing.T) {
+	settingsPath := writeTestSettings(t, `{"a": {"b": 1}, "c": [1, 2]}`)
+	require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixOriginalBak, []byte(`{
+		// Comment
+		"c": [1,2],
+		"a": {
+			"b": 1,
+		},
+	}`), 0o600))
+
+	plan, err := PlanRestore(t.Context(), VSCodeOption, BackupOriginal, settingsPath)
+	require.NoError(t, err)
+	assert.True(t, plan.IsEmpty())
+}
+
+func TestPlanRestore_MissingSettingsFile(t *testing.T) {
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixLatestBak, []byte(`{"a": 1}`), 0o600))
+
+	plan, err := PlanRestore(t.Context(), VSCodeOption, BackupLatest, settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, plan.Added)
+}
+
+func TestPlanRestore_NotObjectSettingsFile(t *testing.T) {
+	settingsPath := writeTestSettings(t, "null")
+	require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixOriginalBak, []byte(`{"a": 1}`), 0o600))
+
+	plan, err := PlanRestore(t.Context(), VSCodeOption, BackupOriginal, settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, plan.Added)
+	assert.Empty(t, plan.Removed)
+}
+
+func TestPlanRestore_MissingBackup(t *testing.T) {
+	settingsPath := writeTestSettings(t, `{}`)
+
+	_, err := PlanRestore(t.Context(), VSCodeOption, BackupOriginal, settingsPath)
+	assert.EqualError(t, err, "no original backup of "+settingsPath+" exists")
+
+	require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixOriginalBak, []byte(`{}`), 0o600))
+	_, err = PlanRestore(t.Context(), VSCodeOption, BackupLatest, settingsPath)
+	assert.EqualError(t, err, "no latest backup of "+settingsPath+" exists")
+}
+
+func TestPlanRestore_InvalidBackup(t *testing.T) {
+	settingsPath := writeTestSettings(t, `{}`)
+	require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixLatestBak, []byte(`{"a": `), 0o600))
+
+	_, err := PlanRestore(t.Context(), VSCodeOption, BackupLatest, settingsPath)
+	assert.ErrorContains(t, err, "failed to parse backup "+settingsPath+fileutil.SuffixLatestBak)
+
+	_, err = PlanRestore(t.Context(), VSCodeOption, "oldest", settingsPath)
+	assert.EqualError(t, err, `invalid backup: "oldest", expected one of: original, latest`)
+}
+
+func TestRestoreSettings(t *testing.T) {
+	original := "{\n\t// My settings\n\t\"editor.fontSize\": 14\n}"
+	latest := `{"editor.fontSize": 14, "remote.SSH.remotePlatform": {"conn-a": "linux"}}`
+
+	for _, tt := range []struct {
+		which string
+		want  string
+	}{
+		{BackupOriginal, original},
+		{BackupLatest, latest},
+	} {
+		t.Run(tt.which, func(t *testing.T) {
+			settingsPath := writeTestSettings(t, `{"editor.fontSize": 16}`)
+			require.NoError(t, os.Chmod(settingsPath, 0o644))
+			require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixOriginalBak, []byte(original), 0o600))
+			require.NoError(t, os.WriteFile(settingsPath+fileutil.SuffixLatestBak, []byte(latest), 0o600))
+
+			err := RestoreSettings(t.Context(), VSCodeOption, tt.which, settingsPath)
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(settingsPath)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(content))
+
+			if runtime.GOOS != "windows" {
+				info, err := os.Stat(settingsPath)
+				require.NoError(t, err)
+				assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+			}
+
+			// The backups are kept.
+			_, err = os.Stat(settingsPath + fileutil.SuffixOriginalBak)
+			assert.NoError(t, err)
+			_, err = os.Stat(settingsPath + fileutil.SuffixLatestBak)
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -5,17 +5,27 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
 	"strings"
 
 	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/env"
 	"github.com/databricks/cli/libs/log"
 	"golang.org/x/mod/semver"
 )
 
 // Options as they can be set via --ide flag.
 const (
-	VSCodeOption = "vscode"
-	CursorOption = "cursor"
+	VSCodeOption         = "vscode"
+	VSCodeInsidersOption = "vscode-insiders"
+	CursorOption         = "cursor"
+	VSCodiumOption       = "vscodium"
+	WindsurfOption       = "windsurf"
+
+	// AutoOption selects the first registered IDE whose command is on PATH.
+	AutoOption = "auto"
 )
 
 type ideDescriptor struct {
@@ -24,6 +34,8 @@ type ideDescriptor struct {
 	Name                   string
 	InstallURL             string
 	AppName                string
+	ServerDataDir          string
+	ExtensionsDir          string
 	SSHExtensionID         string
 	SSHExtensionName       string
 	MinSSHExtensionVersion string
@@ -35,31 +47,162 @@ var vsCodeIDE = ideDescriptor{
 	Name:             "VS Code",
 	InstallURL:       "https://code.visualstudio.com/",
 	AppName:          "Code",
+	ServerDataDir:    ".vscode-server",
+	ExtensionsDir:    ".vscode",
 	SSHExtensionID:   "ms-vscode-remote.remote-ssh",
 	SSHExtensionName: "Remote - SSH",
 	// Earlier versions might work too, 0.120.0 is a safe not-too-old pick
 	MinSSHExtensionVersion: "0.120.0",
 }
 
+var vsCodeInsidersIDE = ideDescriptor{
+	Option:                 VSCodeInsidersOption,
+	Command:                "code-insiders",
+	Name:                   "VS Code Insiders",
+	InstallURL:             "https://code.visualstudio.com/insiders/",
+	AppName:                "Code - Insiders",
+	ServerDataDir:          ".vscode-server-insiders",
+	ExtensionsDir:          ".vscode-insiders",
+	SSHExtensionID:         "ms-vscode-remote.remote-ssh",
+	SSHExtensionName:       "Remote - SSH",
+	MinSSHExtensionVersion: "0.120.0",
+}
+
 var cursorIDE = ideDescriptor{
 	Option:           CursorOption,
 	Command:          "cursor",
 	Name:             "Cursor",
 	InstallURL:       "https://cursor.com/",
 	AppName:          "Cursor",
+	ServerDataDir:    ".cursor-server",
+	ExtensionsDir:    ".cursor",
 	SSHExtensionID:   "anysphere.remote-ssh",
 	SSHExtensionName: "Remote - SSH",
 	// Earlier versions don't support remote.SSH.serverPickPortsFromRange option
 	MinSSHExtensionVersion: "1.0.32",
 }
 
+var vsCodiumIDE = ideDescriptor{
+	Option:           VSCodiumOption,
+	Command:          "codium",
+	Name:             "VSCodium",
+	InstallURL:       "https://vscodium.com/",
+	AppName:          "VSCodium",
+	ServerDataDir:    ".vscodium-server",
+	ExtensionsDir:    ".vscode-oss",
+	SSHExtensionID:   "jeanp413.open-remote-ssh",
+	SSHExtensionName: "Open Remote - SSH",
+	// Earlier versions don't support remote.SSH.serverPickPortsFromRange option
+	MinSSHExtensionVersion: "0.0.45",
+}
+
+var windsurfIDE = ideDescriptor{
+	Option:           WindsurfOption,
+	Command:          "windsurf",
+	Name:             "Windsurf",
+	InstallURL:       "https://windsurf.com/",
+	AppName:          "Windsurf",
+	ServerDataDir:    ".windsurf-server",
+	ExtensionsDir:    ".windsurf",
+	SSHExtensionID:   "codeium.windsurf-remote-openssh",
+	SSHExtensionName: "Remote - SSH",
+	// Earlier versions don't support remote.SSH.serverPickPortsFromRange option
+	MinSSHExtensionVersion: "0.0.30",
+}
+
+// ideRegistry lists the supported IDEs. The order is the order of preference
+// for auto-detection.
+var ideRegistry = []ideDescriptor{
+	vsCodeIDE,
+	vsCodeInsidersIDE,
+	cursorIDE,
+	vsCodiumIDE,
+	windsurfIDE,
+}
+
+// lookupIDE returns the registered IDE for the given --ide option.
+func lookupIDE(option string) (ideDescriptor, bool) {
+	for _, ide := range ideRegistry {
+		if ide.Option == option {
+			return ide, true
+		}
+	}
+	return ideDescriptor{}, false
+}
+
 func getIDE(option string) ideDescriptor {
-	if option == CursorOption {
-		return cursorIDE
+	if ide, ok := lookupIDE(option); ok {
+		return ide
 	}
 	return vsCodeIDE
 }
 
+// IDEName returns the display name of the IDE for the given --ide option.
+func IDEName(option string) string {
+	return getIDE(option).Name
+}
+
+// IDEOptions returns the values accepted by the --ide flag.
+func IDEOptions() []string {
+	options := make([]string, 0, len(ideRegistry)+1)
+	for _, ide := range ideRegistry {
+		options = append(options, ide.Option)
+	}
+	return append(options, AutoOption)
+}
+
+// IsValidIDEOption reports whether the value is accepted by the --ide flag.
+func IsValidIDEOption(option string) bool {
+	_, ok := lookupIDE(option)
+	return ok || option == AutoOption
+}
+
+// DetectIDE returns the option of a registered IDE whose command is available
+// on PATH. If there are several, but only one of them has an application
+// directory with its settings, that one is returned, as it is the one that is
+// actually used. Otherwise, the first one in the registry is returned.
+func DetectIDE(ctx context.Context) (string, error) {
+	var found []ideDescriptor
+	for _, ide := range ideRegistry {
+		if _, err := exec.LookPath(ide.Command); err == nil {
+			found = append(found, ide)
+		}
+	}
+	if len(found) == 0 {
+		commands := make([]string, len(ideRegistry))
+		for i, ide := range ideRegistry {
+			commands[i] = ide.Command
+		}
+		return "", fmt.Errorf("no supported IDE found on PATH, looked for: %s", strings.Join(commands, ", "))
+	}
+
+	var used []ideDescriptor
+	for _, ide := range found {
+		if hasAppDir(ctx, ide) {
+			used = append(used, ide)
+		}
+	}
+	if len(used) == 1 {
+		return used[0].Option, nil
+	}
+	return found[0].Option, nil
+}
+
+// hasAppDir reports whether the application directory of the IDE, which
+// contains its user settings, exists.
+func hasAppDir(ctx context.Context, ide ideDescriptor) bool {
+	home, err := env.UserHomeDir(ctx)
+	if err != nil {
+		return false
+	}
+	settingsPath, err := settingsPathForOS(ctx, ide, runtime.GOOS, home)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(filepath.Dir(filepath.Dir(settingsPath)))
+	return err == nil && info.IsDir()
+}
+
 // CheckIDECommand verifies the IDE CLI command is available on PATH.
 func CheckIDECommand(option string) error {
 	ide := getIDE(option)
@@ -88,6 +231,45 @@ func parseExtensionVersion(output, extensionID string) (string, bool) {
 	return "", false
 }
 
+// extensionDirPattern matches the directories of installed extensions, which
+// are named "<id>-<version>", optionally followed by the target platform.
+var extensionDirPattern = regexp.MustCompile(`^(.+?)-(\d+\.\d+\.\d+)(-.+)?$`)
+
+// scanExtensionsDir lists the extensions installed in dir in the format of
+// "<command> --list-extensions --show-versions".
+func scanExtensionsDir(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	for _, entry := range entries {
+		m := extensionDirPattern.FindStringSubmatch(entry.Name())
+		if entry.IsDir() && m != nil {
+			fmt.Fprintf(&b, "%s@%s\n", m[1], m[2])
+		}
+	}
+	return b.String(), nil
+}
+
+// listExtensions lists the installed extensions of the IDE in the format of
+// "<command> --list-extensions --show-versions". It runs the IDE command if it
+// is on PATH, and otherwise scans the extensions directory of the IDE. It is a
+// variable so that tests can fake it.
+var listExtensions = func(ctx context.Context, ide ideDescriptor) (string, error) {
+	if _, err := exec.LookPath(ide.Command); err == nil {
+		out, err := exec.CommandContext(ctx, ide.Command, "--list-extensions", "--show-versions").Output()
+		return string(out), err
+	}
+	home, err := env.UserHomeDir(ctx)
+	if err != nil {
+		return "", err
+	}
+	dir := filepath.Join(home, ide.ExtensionsDir, "extensions")
+	log.Debugf(ctx, "%q command not found on PATH, scanning %s", ide.Command, dir)
+	return scanExtensionsDir(dir)
+}
+
 func isExtensionVersionAtLeast(version, minVersion string) bool {
 	v := "v" + version
 	return semver.IsValid(v) && semver.Compare(v, "v"+minVersion) >= 0
@@ -98,12 +280,12 @@ func isExtensionVersionAtLeast(version, minVersion string) bool {
 func CheckIDESSHExtension(ctx context.Context, option string) error {
 	ide := getIDE(option)
 
-	out, err := exec.CommandContext(ctx, ide.Command, "--list-extensions", "--show-versions").Output()
+	out, err := listExtensions(ctx, ide)
 	if err != nil {
 		return fmt.Errorf("failed to list %s extensions: %w", ide.Name, err)
 	}
 
-	version, found := parseExtensionVersion(string(out), ide.SSHExtensionID)
+	version, found := parseExtensionVersion(out, ide.SSHExtensionID)
 	if found && isExtensionVersionAtLeast(version, ide.MinSSHExtensionVersion) {
 		return nil
 	}
@@ -116,7 +298,9 @@ func CheckIDESSHExtension(ctx context.Context, option string) error {
 			ide.SSHExtensionName, version, ide.MinSSHExtensionVersion)
 	}
 
-	if !cmdio.IsPromptSupported(ctx) {
+	// The extension can only be installed with the IDE command.
+	_, lookErr := exec.LookPath(ide.Command)
+	if !cmdio.IsPromptSupported(ctx) || lookErr != nil {
 		return fmt.Errorf("%s Install it with: %s --install-extension %s",
 			msg, ide.Command, ide.SSHExtensionID)
 	}
@@ -1,13 +1,18 @@
 package vscode
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/env"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -295,3 +300,164 @@ func TestCheckIDESSHExtension_Cursor(t *testing.T) {
 	err := CheckIDESSHExtension(ctx, CursorOption)
 	assert.NoError(t, err)
 }
+
+func fakeListExtensions(t *testing.T, output string, err error) {
+	t.Helper()
+	orig := listExtensions
+	listExtensions = func(ctx context.Context, ide ideDescriptor) (string, error) {
+		return output, err
+	}
+	t.Cleanup(func() { listExtensions = orig })
+}
+
+func TestCheckIDESSHExtension_FakeLister(t *testing.T) {
+	ctx, _ := cmdio.NewTestContextWithStdout(t.Context())
+
+	fakeListExtensions(t, "jeanp413.open-remote-ssh@0.0.46\n", nil)
+	assert.NoError(t, CheckIDESSHExtension(ctx, VSCodiumOption))
+
+	fakeListExtensions(t, "", errors.New("boom"))
+	assert.EqualError(t, CheckIDESSHExtension(ctx, VSCodiumOption), "failed to list VSCodium extensions: boom")
+}
+
+func TestCheckIDESSHExtension_MissingWithoutCommand(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	ctx, _ := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+	fakeListExtensions(t, "", nil)
+
+	// The extension can't be installed without the command, so there is no prompt.
+	err := CheckIDESSHExtension(ctx, CursorOption)
+	assert.EqualError(t, err, `Required extension "Remote - SSH" is not installed in Cursor. Install it with: cursor --install-extension anysphere.remote-ssh`)
+}
+
+func TestScanExtensionsDir(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{
+		"ms-vscode-remote.remote-ssh-0.120.0",
+		"ms-python.python-2024.1.1-darwin-arm64",
+		"ms-toolsai.jupyter-renderers-1.0.17",
+		".obsolete-dir",
+	} {
+		require.NoError(t, os.Mkdir(filepath.Join(dir, name), 0o755))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extensions.json"), []byte("[]"), 0o600))
+
+	out, err := scanExtensionsDir(dir)
+	require.NoError(t, err)
+
+	version, found := parseExtensionVersion(out, "ms-vscode-remote.remote-ssh")
+	assert.True(t, found)
+	assert.Equal(t, "0.120.0", version)
+	version, found = parseExtensionVersion(out, "ms-python.python")
+	assert.True(t, found)
+	assert.Equal(t, "2024.1.1", version)
+	version, found = parseExtensionVersion(out, "ms-toolsai.jupyter-renderers")
+	assert.True(t, found)
+	assert.Equal(t, "1.0.17", version)
+	assert.Equal(t, 3, strings.Count(out, "\n"))
+
+	_, err = scanExtensionsDir(filepath.Join(dir, "missing"))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestListExtensions_ScansDirWithoutCommand(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	home := t.TempDir()
+	ctx := env.WithUserHomeDir(t.Context(), home)
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".cursor", "extensions", "anysphere.remote-ssh-1.0.32"), 0o755))
+
+	out, err := listExtensions(ctx, getIDE(CursorOption))
+	require.NoError(t, err)
+	assert.Equal(t, "anysphere.remote-ssh@1.0.32\n", out)
+
+	cmdCtx, _ := cmdio.NewTestContextWithStdout(ctx)
+	assert.NoError(t, CheckIDESSHExtension(cmdCtx, CursorOption))
+}
+
+func TestIDERegistry(t *testing.T) {
+	assert.Equal(t, []string{"vscode", "vscode-insiders", "cursor", "vscodium", "windsurf", "auto"}, IDEOptions())
+
+	for _, option := range IDEOptions() {
+		assert.True(t, IsValidIDEOption(option), option)
+	}
+	assert.False(t, IsValidIDEOption("vim"))
+	assert.False(t, IsValidIDEOption(""))
+
+	assert.Equal(t, "VS Code Insiders", getIDE(VSCodeInsidersOption).Name)
+	assert.Equal(t, "code-insiders", getIDE(VSCodeInsidersOption).Command)
+	assert.Equal(t, "VSCodium", getIDE(VSCodiumOption).Name)
+	assert.Equal(t, "codium", getIDE(VSCodiumOption).Command)
+	assert.Equal(t, "Windsurf", getIDE(WindsurfOption).Name)
+	assert.Equal(t, "windsurf", getIDE(WindsurfOption).Command)
+
+	// Unknown options fall back to VS Code.
+	assert.Equal(t, "VS Code", getIDE("vim").Name)
+}
+
+func TestDetectIDE(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("PATH", tmpDir)
+	ctx := env.Set(t.Context(), "HOME", t.TempDir())
+	ctx = env.Set(ctx, "USERPROFILE", env.Get(ctx, "HOME"))
+	ctx = env.Set(ctx, "APPDATA", "")
+
+	_, err := DetectIDE(ctx)
+	assert.EqualError(t, err, "no supported IDE found on PATH, looked for: code, code-insiders, cursor, codium, windsurf")
+
+	writeCommand := func(name string) {
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte("#!/bin/sh\n"), 0o755)
+		require.NoError(t, err)
+	}
+
+	writeCommand("windsurf")
+	ide, err := DetectIDE(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, WindsurfOption, ide)
+
+	// Registry order determines the preference.
+	writeCommand("codium")
+	ide, err = DetectIDE(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, VSCodiumOption, ide)
+}
+
+func TestDetectIDEPrefersIDEWithAppDir(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("PATH", tmpDir)
+	home := t.TempDir()
+	ctx := env.Set(t.Context(), "HOME", home)
+	ctx = env.Set(ctx, "USERPROFILE", home)
+	ctx = env.Set(ctx, "APPDATA", "")
+
+	for _, name := range []string{"code", "code-insiders", "codium"} {
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+		err := os.WriteFile(filepath.Join(tmpDir, name), []byte("#!/bin/sh\n"), 0o755)
+		require.NoError(t, err)
+	}
+
+	// Without any application directory, the registry order decides.
+	ide, err := DetectIDE(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, VSCodeOption, ide)
+
+	// Only VS Code Insiders has been used.
+	settingsPath, err := settingsPathForOS(ctx, getIDE(VSCodeInsidersOption), runtime.GOOS, home)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(settingsPath), 0o755))
+	ide, err = DetectIDE(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, VSCodeInsidersOption, ide)
+
+	// With several application directories, the registry order decides again.
+	settingsPath, err = settingsPathForOS(ctx, getIDE(VSCodiumOption), runtime.GOOS, home)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(settingsPath), 0o755))
+	ide, err = DetectIDE(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, VSCodeOption, ide)
+}
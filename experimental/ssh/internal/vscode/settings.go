@@ -1,6 +1,7 @@
 package vscode
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -8,7 +9,9 @@ import (
 	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/databricks/cli/experimental/ssh/internal/fileutil"
@@ -18,10 +21,33 @@ import (
 	"github.com/tailscale/hujson"
 )
 
+// DefaultPortRange is the range of ports the IDE server picks from on the
+// remote side, unless a different one is configured for the connection. Other
+// ports on the remote side of serverless compute are blocked, so if another
+// connection uses the range already, a smaller range within it is picked
+// instead, see choosePortRange.
+// TODO: change to 4000-4005 when the relevant changes are fully deployed.
+// The ports below can also be used by PyTorch, but we have a bigger range available for it.
+const DefaultPortRange = "29500-29505"
+
+// maxPortRangeSpan is the maximum number of ports in a port range.
+const maxPortRangeSpan = 100
+
+// pickedPortRangeSize is the number of ports in a range that is picked within
+// DefaultPortRange for a connection whose range would overlap with the range
+// of another connection. The IDE server listens on one port; the other one is
+// spare for a server that starts while the previous one is shutting down.
+const pickedPortRangeSize = 2
+
+// DefaultExtensions are the extensions that the IDE installs on the remote
+// side, unless a different list is configured.
+var DefaultExtensions = []string{pythonExtension, jupyterExtension, databricksExtension}
+
+// extensionIDPattern matches extension IDs, which have the format
+// "publisher.name".
+var extensionIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9-]*\.[a-zA-Z0-9][a-zA-Z0-9-]*$`)
+
 const (
-	// TODO: change to 4000-4005 when the relevant changes are fully deployed.
-	// The ports below can also be used by PyTorch, but we have a bigger range available for it.
-	portRange            = "29500-29505"
 	remotePlatform       = "linux"
 	pythonExtension      = "ms-python.python"
 	jupyterExtension     = "ms-toolsai.jupyter"
@@ -37,12 +63,39 @@ type missingSettings struct {
 	platform       bool
 	listenOnSocket bool
 	extensions     []string
+
+	// The values found in the settings file, for display. Values that are not
+	// set, or not set to a literal, are empty. They are only filled in by
+	// validateSettings.
+	expectedPortRange     string
+	currentPortRange      string
+	currentPlatform       string
+	currentListenOnSocket string
+	currentExtensions     []string
 }
 
 func (m *missingSettings) isEmpty() bool {
 	return !m.portRange && !m.platform && !m.listenOnSocket && len(m.extensions) == 0
 }
 
+// keys returns the settings keys that updateSettings writes.
+func (m *missingSettings) keys() []string {
+	var keys []string
+	if m.portRange {
+		keys = append(keys, serverPickPortsKey)
+	}
+	if m.platform {
+		keys = append(keys, remotePlatformKey)
+	}
+	if m.listenOnSocket {
+		keys = append(keys, listenOnSocketKey)
+	}
+	if len(m.extensions) > 0 {
+		keys = append(keys, defaultExtensionsKey)
+	}
+	return keys
+}
+
 // Builds a JSON Pointer (RFC 6901) from path segments to be used in hujson.Value.Find.
 // Escapes "~" → "~0" and "/" → "~1" per spec.
 func jsonPtr(segments ...string) string {
@@ -61,42 +114,185 @@ type patchOp struct {
 	Value any    `json:"value,omitempty"`
 }
 
-func logSkippingSettings(ctx context.Context, msg string) {
-	cmdio.LogString(ctx, msg+"\n\nWARNING: the connection might not work as expected\n")
+// ValidatePortRange checks that portRange has the format "N-M", where N and M
+// are unprivileged ports, M is greater than N, and the range spans at most
+// maxPortRangeSpan ports.
+func ValidatePortRange(portRange string) error {
+	_, _, err := parsePortRange(portRange)
+	return err
 }
 
-func CheckAndUpdateSettings(ctx context.Context, ide, connectionName string) error {
-	if !cmdio.IsPromptSupported(ctx) {
-		logSkippingSettings(ctx, "Skipping IDE settings check: prompts not supported")
+// ValidateExtensions checks that each of the extensions has the format
+// "publisher.name".
+func ValidateExtensions(extensions []string) error {
+	for _, ext := range extensions {
+		if !extensionIDPattern.MatchString(ext) {
+			return fmt.Errorf("invalid extension ID %q, expected the format publisher.name, for example %s", ext, pythonExtension)
+		}
+	}
+	return nil
+}
+
+// requiredExtensions returns the extensions to install on the remote side.
+// A nil list means the default extensions, while an empty list means that
+// the extensions are not managed at all.
+func requiredExtensions(extensions []string) []string {
+	if extensions == nil {
+		return DefaultExtensions
+	}
+	return extensions
+}
+
+// parsePortRange returns the first and the last port of a valid port range.
+func parsePortRange(portRange string) (int, int, error) {
+	start, end, ok := strings.Cut(portRange, "-")
+	n, errStart := strconv.Atoi(start)
+	m, errEnd := strconv.Atoi(end)
+	if !ok || errStart != nil || errEnd != nil {
+		return 0, 0, fmt.Errorf("invalid port range %q, expected the format N-M, for example %s", portRange, DefaultPortRange)
+	}
+	if n < 1024 || m > 65535 {
+		return 0, 0, fmt.Errorf("invalid port range %q, ports must be between 1024 and 65535", portRange)
+	}
+	if m <= n {
+		return 0, 0, fmt.Errorf("invalid port range %q, the last port must be greater than the first one", portRange)
+	}
+	if m-n+1 > maxPortRangeSpan {
+		return 0, 0, fmt.Errorf("invalid port range %q, it must span at most %d ports", portRange, maxPortRangeSpan)
+	}
+	return n, m, nil
+}
+
+// otherPortRanges returns the first and the last ports of the valid port
+// ranges of connections other than the given one.
+func otherPortRanges(v hujson.Value, connectionName string) [][2]int {
+	found := v.Find(jsonPtr(serverPickPortsKey))
+	if found == nil {
 		return nil
 	}
+	obj, ok := found.Value.(*hujson.Object)
+	if !ok {
+		return nil
+	}
+	var ranges [][2]int
+	for _, member := range obj.Members {
+		name, ok := member.Name.Value.(hujson.Literal)
+		if !ok || name.String() == connectionName {
+			continue
+		}
+		value, ok := member.Value.Value.(hujson.Literal)
+		if !ok {
+			continue
+		}
+		if start, end, err := parsePortRange(value.String()); err == nil {
+			ranges = append(ranges, [2]int{start, end})
+		}
+	}
+	return ranges
+}
 
-	settingsPath, err := getDefaultSettingsPath(ctx, ide)
-	if err != nil {
-		return fmt.Errorf("failed to get settings path: %w", err)
+// choosePortRange returns the port range for the connection. If portRange is
+// set explicitly, it is used as-is. Otherwise, the range already configured
+// for the connection is kept if it doesn't overlap with the range of another
+// connection, since the IDE servers of both would compete for the same ports.
+// If it does, or if there is none, DefaultPortRange is used if it doesn't
+// overlap, and the first range of pickedPortRangeSize ports within it that
+// doesn't overlap otherwise. Ranges outside DefaultPortRange are never picked,
+// because the remote side blocks them. If every range within it overlaps,
+// DefaultPortRange is shared with the other connections.
+func choosePortRange(v hujson.Value, connectionName, portRange string) string {
+	if portRange != "" {
+		return portRange
+	}
+
+	others := otherPortRanges(v, connectionName)
+	overlapping := func(start, end int) (int, bool) {
+		for _, r := range others {
+			if start <= r[1] && r[0] <= end {
+				return r[1], true
+			}
+		}
+		return 0, false
+	}
+
+	if found := v.Find(jsonPtr(serverPickPortsKey, connectionName)); found != nil {
+		if lit, ok := found.Value.(hujson.Literal); ok {
+			if start, end, err := parsePortRange(lit.String()); err == nil {
+				if _, ok := overlapping(start, end); !ok {
+					return lit.String()
+				}
+			}
+		}
+	}
+
+	first, last, _ := parsePortRange(DefaultPortRange)
+	if _, ok := overlapping(first, last); !ok {
+		return DefaultPortRange
+	}
+	start := first
+	for end := start + pickedPortRangeSize - 1; end <= last; end = start + pickedPortRangeSize - 1 {
+		taken, ok := overlapping(start, end)
+		if !ok {
+			return fmt.Sprintf("%d-%d", start, end)
+		}
+		start = taken + 1
+	}
+	return DefaultPortRange
+}
+
+func logSkippingSettings(ctx context.Context, msg string) {
+	cmdio.LogString(ctx, msg+"\n\nWARNING: the connection might not work as expected\n")
+}
+
+// CheckAndUpdateSettings checks that the IDE settings for the connection pick
+// server ports from portRange, along with the other required settings, and
+// offers to update them if they don't. Settings of other connections are
+// preserved.
+//
+// If portRange is empty, a range that doesn't overlap with the ranges of other
+// connections is picked automatically.
+//
+// If settingsPath is empty, the default user settings file of the IDE is used.
+// Otherwise, the directory of settingsPath must exist.
+//
+// The extensions are added to the default extensions that the IDE installs on
+// the remote side. If they are nil, DefaultExtensions are used, and if they are
+// empty, the default extensions are not checked.
+//
+// With autoApprove, the settings are updated without a prompt. Without it, if
+// prompts are not supported, instructions to update them manually are printed.
+func CheckAndUpdateSettings(ctx context.Context, ide, connectionName, portRange, settingsPath string, extensions []string, autoApprove bool) error {
+	if settingsPath == "" {
+		var err error
+		settingsPath, err = getDefaultSettingsPath(ctx, ide)
+		if err != nil {
+			return fmt.Errorf("failed to get settings path: %w", err)
+		}
+	} else if err := checkSettingsDir(settingsPath); err != nil {
+		return err
 	}
 
 	settings, err := loadSettings(settingsPath)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return handleMissingFile(ctx, ide, connectionName, settingsPath)
+			return handleMissingFile(ctx, ide, connectionName, portRange, settingsPath, extensions, autoApprove)
+		}
+		if errors.Is(err, errSettingsNotObject) {
+			return handleInvalidFile(ctx, ide, connectionName, portRange, settingsPath, extensions, autoApprove, err)
 		}
 		return fmt.Errorf("failed to load settings: %w", err)
 	}
 
-	missing := validateSettings(settings, connectionName)
+	portRange = choosePortRange(settings, connectionName, portRange)
+	missing := validateSettings(settings, connectionName, portRange, extensions)
 	if missing.isEmpty() {
 		log.Debugf(ctx, "IDE settings already correct for %s", connectionName)
 		return nil
 	}
 
-	shouldUpdate, err := promptUserForUpdate(ctx, ide, connectionName, missing)
-	if err != nil {
-		return fmt.Errorf("failed to prompt user: %w", err)
-	}
-	if !shouldUpdate {
-		logSkippingSettings(ctx, "Skipping IDE settings update")
-		return nil
+	shouldUpdate, err := confirmUpdate(ctx, ide, connectionName, portRange, settingsPath, extensions, missing, autoApprove)
+	if err != nil || !shouldUpdate {
+		return err
 	}
 
 	if data, err := os.ReadFile(settingsPath); err == nil {
@@ -105,7 +301,7 @@ func CheckAndUpdateSettings(ctx context.Context, ide, connectionName string) err
 		}
 	}
 
-	if err := updateSettings(&settings, connectionName, missing); err != nil {
+	if err := updateSettings(&settings, connectionName, portRange, missing); err != nil {
 		return fmt.Errorf("failed to update settings: %w", err)
 	}
 
@@ -113,7 +309,25 @@ func CheckAndUpdateSettings(ctx context.Context, ide, connectionName string) err
 		return fmt.Errorf("failed to save settings: %w", err)
 	}
 
-	cmdio.LogString(ctx, fmt.Sprintf("Updated %s settings for '%s'", getIDE(ide).Name, connectionName))
+	cmdio.LogString(ctx, fmt.Sprintf("Updated %s settings for '%s': %s", getIDE(ide).Name, connectionName, strings.Join(missing.keys(), ", ")))
+	return nil
+}
+
+// checkSettingsDir checks that the directory of a settings file that was
+// set explicitly exists. Unlike the default settings directory, it is not
+// created, because a typo would then go unnoticed.
+func checkSettingsDir(settingsPath string) error {
+	dir := filepath.Dir(settingsPath)
+	info, err := os.Stat(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("the directory of the settings file %s does not exist", filepath.ToSlash(settingsPath))
+	}
+	if err != nil {
+		return fmt.Errorf("failed to check settings directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", filepath.ToSlash(dir))
+	}
 	return nil
 }
 
@@ -123,10 +337,92 @@ func getDefaultSettingsPath(ctx context.Context, ide string) (string, error) {
 		return "", fmt.Errorf("failed to get home directory: %w", err)
 	}
 
-	appName := getIDE(ide).AppName
+	return resolveSettingsPath(ctx, getIDE(ide), runtime.GOOS, home)
+}
+
+// procVersionPath is the file that identifies the Linux kernel. It is a
+// variable so that tests can fake it.
+var procVersionPath = "/proc/version"
+
+// isWSL reports whether the CLI runs in the Windows Subsystem for Linux.
+func isWSL(ctx context.Context) bool {
+	if env.Get(ctx, "WSL_DISTRO_NAME") != "" {
+		return true
+	}
+	version, err := os.ReadFile(procVersionPath)
+	if err != nil {
+		return false
+	}
+	// WSL kernels are named like "5.15.153.1-microsoft-standard-WSL2".
+	return strings.Contains(strings.ToLower(string(version)), "microsoft")
+}
+
+// settingsCandidate is a possible location of the settings file of an IDE.
+type settingsCandidate struct {
+	path        string
+	description string
+	// ifExists makes the candidate only be used if the file exists.
+	ifExists bool
+}
+
+// settingsCandidates returns the possible locations of the settings file of
+// the IDE, in the order of preference.
+//
+// On Linux, the machine settings of the IDE server take precedence over the
+// user settings. The server is installed in the home directory by remote
+// connections and, on Windows, by the WSL backend of the IDE, in which case
+// the user settings file usually doesn't exist in the distro.
+func settingsCandidates(ctx context.Context, ide ideDescriptor, goos, home string) ([]settingsCandidate, error) {
+	userSettings, err := settingsPathForOS(ctx, ide, goos, home)
+	if err != nil {
+		return nil, err
+	}
+	user := settingsCandidate{path: userSettings, description: "user settings"}
+	if goos != "linux" {
+		return []settingsCandidate{user}, nil
+	}
+
+	serverDir := filepath.Join(home, ide.ServerDataDir)
+	machineSettings := filepath.Join(serverDir, "data", "Machine", "settings.json")
+	candidates := []settingsCandidate{
+		{path: machineSettings, description: "remote server machine settings", ifExists: true},
+	}
+	if info, err := os.Stat(serverDir); err == nil && info.IsDir() && isWSL(ctx) {
+		candidates = append(candidates, settingsCandidate{path: machineSettings, description: "WSL remote server machine settings"})
+	}
+	return append(candidates, user), nil
+}
+
+// resolveSettingsPath returns the first candidate settings file that exists.
+// If none of them does, it returns the first one that can be created.
+func resolveSettingsPath(ctx context.Context, ide ideDescriptor, goos, home string) (string, error) {
+	candidates, err := settingsCandidates(ctx, ide, goos, home)
+	if err != nil {
+		return "", err
+	}
+
+	chosen := -1
+	for i, c := range candidates {
+		if _, err := os.Stat(c.path); err == nil {
+			chosen = i
+			break
+		}
+		if chosen < 0 && !c.ifExists {
+			chosen = i
+		}
+	}
+	c := candidates[chosen]
+	log.Infof(ctx, "Using %s %s: %s", ide.Name, c.description, c.path)
+	return c.path, nil
+}
+
+// settingsPathForOS returns the path of the user settings file of the IDE on
+// the given operating system.
+func settingsPathForOS(ctx context.Context, ide ideDescriptor, goos, home string) (string, error) {
+	appName := ide.AppName
 
 	var settingsDir string
-	switch runtime.GOOS {
+	switch goos {
 	case "darwin":
 		settingsDir = filepath.Join(home, "Library", "Application Support", appName, "User")
 	case "windows":
@@ -138,25 +434,52 @@ func getDefaultSettingsPath(ctx context.Context, ide string) (string, error) {
 	case "linux":
 		settingsDir = filepath.Join(home, ".config", appName, "User")
 	default:
-		return "", fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+		return "", fmt.Errorf("unsupported operating system: %s", goos)
 	}
 
 	return filepath.Join(settingsDir, "settings.json"), nil
 }
 
+// errSettingsNotObject is returned by loadSettings if the settings file
+// parses, but doesn't contain a JSON object that settings can be added to.
+var errSettingsNotObject = errors.New("expected a JSON object")
+
 func loadSettings(path string) (hujson.Value, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return hujson.Value{}, err
 	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return hujson.Value{}, fmt.Errorf("the file is empty, %w", errSettingsNotObject)
+	}
 	v, err := hujson.Parse(data)
 	if err != nil {
 		return hujson.Value{}, fmt.Errorf("failed to parse settings JSON: %w", err)
 	}
+	if _, ok := v.Value.(*hujson.Object); !ok {
+		return hujson.Value{}, fmt.Errorf("the top-level value is %s, %w", describeKind(v.Value.Kind()), errSettingsNotObject)
+	}
 	return v, nil
 }
 
-func hasCorrectPortRange(v hujson.Value, connectionName string) bool {
+func describeKind(kind hujson.Kind) string {
+	switch kind {
+	case 'n':
+		return "null"
+	case 't', 'f':
+		return "a boolean"
+	case '"':
+		return "a string"
+	case '0':
+		return "a number"
+	case '[':
+		return "an array"
+	default:
+		return fmt.Sprintf("%q", kind)
+	}
+}
+
+func hasCorrectPortRange(v hujson.Value, connectionName, portRange string) bool {
 	found := v.Find(jsonPtr(serverPickPortsKey, connectionName))
 	if found == nil {
 		return false
@@ -183,8 +506,46 @@ func hasCorrectListenOnSocket(v hujson.Value) bool {
 	return ok && lit.Bool()
 }
 
-func getMissingExtensions(v hujson.Value) []string {
-	required := []string{pythonExtension, jupyterExtension, databricksExtension}
+// literalValue returns the literal at ptr as it is written in the settings
+// file, with strings unquoted, or an empty string if there is none.
+func literalValue(v hujson.Value, ptr string) string {
+	found := v.Find(ptr)
+	if found == nil {
+		return ""
+	}
+	lit, ok := found.Value.(hujson.Literal)
+	if !ok {
+		return ""
+	}
+	if lit.Kind() == '"' {
+		return lit.String()
+	}
+	return string(lit)
+}
+
+// getExtensions returns the string elements of the default extensions.
+func getExtensions(v hujson.Value) []string {
+	found := v.Find(jsonPtr(defaultExtensionsKey))
+	if found == nil {
+		return nil
+	}
+	arr, ok := found.Value.(*hujson.Array)
+	if !ok {
+		return nil
+	}
+	var extensions []string
+	for _, el := range arr.Elements {
+		if lit, ok := el.Value.(hujson.Literal); ok && lit.Kind() == '"' {
+			extensions = append(extensions, lit.String())
+		}
+	}
+	return extensions
+}
+
+func getMissingExtensions(v hujson.Value, required []string) []string {
+	if len(required) == 0 {
+		return nil
+	}
 	found := v.Find(jsonPtr(defaultExtensionsKey))
 	if found == nil {
 		return required
@@ -208,16 +569,25 @@ func getMissingExtensions(v hujson.Value) []string {
 	return missing
 }
 
-func validateSettings(v hujson.Value, connectionName string) *missingSettings {
+// validateSettings returns the settings that are missing for the connection.
+// The port range of the connection must be portRange, as returned by
+// choosePortRange.
+func validateSettings(v hujson.Value, connectionName, portRange string, extensions []string) *missingSettings {
 	return &missingSettings{
-		portRange:      !hasCorrectPortRange(v, connectionName),
+		portRange:      !hasCorrectPortRange(v, connectionName, portRange),
 		platform:       !hasCorrectPlatform(v, connectionName),
 		listenOnSocket: !hasCorrectListenOnSocket(v),
-		extensions:     getMissingExtensions(v),
+		extensions:     getMissingExtensions(v, requiredExtensions(extensions)),
+
+		expectedPortRange:     portRange,
+		currentPortRange:      literalValue(v, jsonPtr(serverPickPortsKey, connectionName)),
+		currentPlatform:       literalValue(v, jsonPtr(remotePlatformKey, connectionName)),
+		currentListenOnSocket: literalValue(v, jsonPtr(listenOnSocketKey)),
+		currentExtensions:     getExtensions(v),
 	}
 }
 
-func settingsMessage(connectionName string, missing *missingSettings) string {
+func settingsMessage(connectionName, portRange string, missing *missingSettings) string {
 	var lines []string
 	if missing.portRange {
 		lines = append(lines, fmt.Sprintf("    \"%s\": {\"%s\": \"%s\"}", serverPickPortsKey, connectionName, portRange))
@@ -238,10 +608,10 @@ func settingsMessage(connectionName string, missing *missingSettings) string {
 	return "  {\n" + strings.Join(lines, ",\n") + "\n  }"
 }
 
-func promptUserForUpdate(ctx context.Context, ide, connectionName string, missing *missingSettings) (bool, error) {
+func promptUserForUpdate(ctx context.Context, ide, connectionName, portRange string, missing *missingSettings) (bool, error) {
 	question := fmt.Sprintf(
 		"The following settings will be applied to %s for '%s':\n\n%s\n\nApply these settings?",
-		getIDE(ide).Name, connectionName, settingsMessage(connectionName, missing))
+		getIDE(ide).Name, connectionName, settingsMessage(connectionName, portRange, missing))
 	ans, err := cmdio.Ask(ctx, question+" [Y/n]", "y")
 	if err != nil {
 		return false, err
@@ -249,20 +619,42 @@ func promptUserForUpdate(ctx context.Context, ide, connectionName string, missin
 	return strings.ToLower(ans) == "y", nil
 }
 
-func handleMissingFile(ctx context.Context, ide, connectionName, settingsPath string) error {
+// confirmUpdate asks whether to apply the missing settings, unless autoApprove
+// is set. If prompts are not supported, it prints instructions to apply them
+// manually instead.
+func confirmUpdate(ctx context.Context, ide, connectionName, portRange, settingsPath string, extensions []string, missing *missingSettings, autoApprove bool) (bool, error) {
+	if autoApprove {
+		return true, nil
+	}
+	if !cmdio.IsPromptSupported(ctx) {
+		logSkippingSettings(ctx, "Skipping IDE settings update: prompts not supported. Use --auto-approve-ide-settings to apply them without a prompt.\n\n"+
+			GetManualInstructions(ide, connectionName, portRange, settingsPath, extensions))
+		return false, nil
+	}
+
+	shouldUpdate, err := promptUserForUpdate(ctx, ide, connectionName, portRange, missing)
+	if err != nil {
+		return false, fmt.Errorf("failed to prompt user: %w", err)
+	}
+	if !shouldUpdate {
+		logSkippingSettings(ctx, "Skipping IDE settings update")
+	}
+	return shouldUpdate, nil
+}
+
+func handleMissingFile(ctx context.Context, ide, connectionName, portRange, settingsPath string, extensions []string, autoApprove bool) error {
+	if portRange == "" {
+		portRange = DefaultPortRange
+	}
 	missing := &missingSettings{
 		portRange:      true,
 		platform:       true,
 		listenOnSocket: true,
-		extensions:     []string{pythonExtension, jupyterExtension, databricksExtension},
-	}
-	shouldCreate, err := promptUserForUpdate(ctx, ide, connectionName, missing)
-	if err != nil {
-		return fmt.Errorf("failed to prompt user: %w", err)
+		extensions:     requiredExtensions(extensions),
 	}
-	if !shouldCreate {
-		logSkippingSettings(ctx, "Skipping IDE settings creation")
-		return nil
+	shouldCreate, err := confirmUpdate(ctx, ide, connectionName, portRange, settingsPath, extensions, missing, autoApprove)
+	if err != nil || !shouldCreate {
+		return err
 	}
 
 	settingsDir := filepath.Dir(settingsPath)
@@ -274,7 +666,7 @@ func handleMissingFile(ctx context.Context, ide, connectionName, settingsPath st
 	if err != nil {
 		return fmt.Errorf("failed to create settings: %w", err)
 	}
-	if err := updateSettings(&v, connectionName, missing); err != nil {
+	if err := updateSettings(&v, connectionName, portRange, missing); err != nil {
 		return fmt.Errorf("failed to update settings: %w", err)
 	}
 
@@ -282,10 +674,42 @@ func handleMissingFile(ctx context.Context, ide, connectionName, settingsPath st
 		return fmt.Errorf("failed to save settings: %w", err)
 	}
 
-	cmdio.LogString(ctx, fmt.Sprintf("Created %s settings at %s", getIDE(ide).Name, filepath.ToSlash(settingsPath)))
+	cmdio.LogString(ctx, fmt.Sprintf("Created %s settings at %s: %s", getIDE(ide).Name, filepath.ToSlash(settingsPath), strings.Join(missing.keys(), ", ")))
 	return nil
 }
 
+// handleInvalidFile moves a settings file that doesn't contain a JSON object
+// aside, after a confirmation, and creates the settings from scratch as if the
+// file didn't exist. The reason is the error that loadSettings returned.
+func handleInvalidFile(ctx context.Context, ide, connectionName, portRange, settingsPath string, extensions []string, autoApprove bool, reason error) error {
+	corruptPath := settingsPath + fileutil.SuffixCorruptBak
+	ideName := getIDE(ide).Name
+	if !autoApprove {
+		if !cmdio.IsPromptSupported(ctx) {
+			return fmt.Errorf("invalid %s settings file %s: %w. Use --auto-approve-ide-settings to move it to %s and create new settings",
+				ideName, filepath.ToSlash(settingsPath), reason, filepath.ToSlash(corruptPath))
+		}
+		question := fmt.Sprintf("The %s settings file %s is invalid: %v.\nMove it to %s and create new settings for '%s'?",
+			ideName, filepath.ToSlash(settingsPath), reason, filepath.ToSlash(corruptPath), connectionName)
+		confirmed, err := cmdio.AskYesOrNo(ctx, question)
+		if err != nil {
+			return fmt.Errorf("failed to prompt user: %w", err)
+		}
+		if !confirmed {
+			logSkippingSettings(ctx, "Skipping IDE settings update")
+			return nil
+		}
+	}
+
+	if err := os.Rename(settingsPath, corruptPath); err != nil {
+		return fmt.Errorf("failed to move invalid settings: %w", err)
+	}
+	cmdio.LogString(ctx, fmt.Sprintf("Moved invalid %s settings to %s", ideName, filepath.ToSlash(corruptPath)))
+
+	// The user already agreed to replace the file.
+	return handleMissingFile(ctx, ide, connectionName, portRange, settingsPath, extensions, true)
+}
+
 // subKeyOp returns a patch op that sets key/subKey=value, creating the parent object if absent.
 func subKeyOp(v *hujson.Value, key, subKey, value string) patchOp {
 	if v.Find(jsonPtr(key)) == nil {
@@ -294,7 +718,7 @@ func subKeyOp(v *hujson.Value, key, subKey, value string) patchOp {
 	return patchOp{"add", jsonPtr(key, subKey), value}
 }
 
-func updateSettings(v *hujson.Value, connectionName string, missing *missingSettings) error {
+func updateSettings(v *hujson.Value, connectionName, portRange string, missing *missingSettings) error {
 	var ops []patchOp
 	if missing.portRange {
 		ops = append(ops, subKeyOp(v, serverPickPortsKey, connectionName, portRange))
@@ -315,6 +739,10 @@ func updateSettings(v *hujson.Value, connectionName string, missing *missingSett
 			}
 		}
 	}
+	return applyPatch(v, ops)
+}
+
+func applyPatch(v *hujson.Value, ops []patchOp) error {
 	if len(ops) == 0 {
 		return nil
 	}
@@ -325,6 +753,76 @@ func updateSettings(v *hujson.Value, connectionName string, missing *missingSett
 	return v.Patch(patchData)
 }
 
+// connectionKeys are the settings that hold an entry per connection.
+var connectionKeys = []string{serverPickPortsKey, remotePlatformKey}
+
+// removeConnectionOps returns the patch ops that remove the entries of the
+// connection from the per-connection settings.
+func removeConnectionOps(v hujson.Value, connectionName string) []patchOp {
+	var ops []patchOp
+	for _, key := range connectionKeys {
+		ptr := jsonPtr(key, connectionName)
+		if v.Find(ptr) != nil {
+			ops = append(ops, patchOp{Op: "remove", Path: ptr})
+		}
+	}
+	return ops
+}
+
+// FindConnectionSettings returns the path of the IDE settings file and whether
+// it has settings for the connection. If settingsPath is empty, the default
+// user settings file of the IDE is used.
+func FindConnectionSettings(ctx context.Context, ide, connectionName, settingsPath string) (string, bool, error) {
+	if settingsPath == "" {
+		var err error
+		settingsPath, err = getDefaultSettingsPath(ctx, ide)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get settings path: %w", err)
+		}
+	}
+
+	settings, err := loadSettings(settingsPath)
+	if errors.Is(err, fs.ErrNotExist) || errors.Is(err, errSettingsNotObject) {
+		return settingsPath, false, nil
+	}
+	if err != nil {
+		return settingsPath, false, fmt.Errorf("failed to load settings: %w", err)
+	}
+	return settingsPath, len(removeConnectionOps(settings, connectionName)) > 0, nil
+}
+
+// RemoveConnectionSettings removes the entries of the connection that
+// CheckAndUpdateSettings added to the per-connection IDE settings, after
+// backing up the settings file. Global settings, such as the default
+// extensions, are left as-is, as other connections rely on them too.
+// It reports whether any settings were removed.
+func RemoveConnectionSettings(ctx context.Context, ide, connectionName, settingsPath string) (bool, error) {
+	settingsPath, found, err := FindConnectionSettings(ctx, ide, connectionName, settingsPath)
+	if err != nil || !found {
+		return false, err
+	}
+
+	data, err := os.ReadFile(settingsPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to read settings: %w", err)
+	}
+	settings, err := hujson.Parse(data)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse settings JSON: %w", err)
+	}
+	if err := fileutil.BackupFile(ctx, settingsPath, data); err != nil {
+		return false, fmt.Errorf("failed to backup settings: %w", err)
+	}
+
+	if err := applyPatch(&settings, removeConnectionOps(settings, connectionName)); err != nil {
+		return false, fmt.Errorf("failed to update settings: %w", err)
+	}
+	if err := saveSettings(settingsPath, &settings); err != nil {
+		return false, fmt.Errorf("failed to save settings: %w", err)
+	}
+	return true, nil
+}
+
 func saveSettings(path string, v *hujson.Value) error {
 	if err := os.WriteFile(path, v.Pack(), 0o600); err != nil {
 		return fmt.Errorf("failed to write settings file: %w", err)
@@ -332,14 +830,25 @@ func saveSettings(path string, v *hujson.Value) error {
 	return nil
 }
 
-func GetManualInstructions(ide, connectionName string) string {
+// GetManualInstructions returns instructions for adding the required settings
+// by hand. If settingsPath is set, they refer to that file. The extensions are
+// interpreted as in CheckAndUpdateSettings.
+func GetManualInstructions(ide, connectionName, portRange, settingsPath string, extensions []string) string {
+	if portRange == "" {
+		portRange = DefaultPortRange
+	}
 	missing := &missingSettings{
 		portRange:      true,
 		platform:       true,
 		listenOnSocket: true,
-		extensions:     []string{pythonExtension, jupyterExtension, databricksExtension},
+		extensions:     requiredExtensions(extensions),
+	}
+	if settingsPath != "" {
+		return fmt.Sprintf(
+			"To ensure the remote connection works as expected, manually add these settings to %s:\n%s",
+			filepath.ToSlash(settingsPath), settingsMessage(connectionName, portRange, missing))
 	}
 	return fmt.Sprintf(
 		"To ensure the remote connection works as expected, manually add these settings to your %s settings.json:\n%s",
-		getIDE(ide).Name, settingsMessage(connectionName, missing))
+		getIDE(ide).Name, settingsMessage(connectionName, portRange, missing))
 }
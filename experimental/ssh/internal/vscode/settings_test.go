@@ -128,6 +128,225 @@ func TestGetDefaultSettingsPath_Cursor_Windows(t *testing.T) {
 	assert.Equal(t, `C:\Users\testuser\AppData\Roaming\Cursor\User\settings.json`, path)
 }
 
+func TestGetDefaultSettingsPath_VSCodeInsiders_Linux(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("Skipping Linux-specific test")
+	}
+
+	ctx := t.Context()
+	ctx = env.Set(ctx, "HOME", "/home/testuser")
+
+	path, err := getDefaultSettingsPath(ctx, VSCodeInsidersOption)
+	require.NoError(t, err)
+	assert.Equal(t, "/home/testuser/.config/Code - Insiders/User/settings.json", path)
+}
+
+func TestGetDefaultSettingsPath_VSCodeInsiders_Darwin(t *testing.T) {
+	if runtime.GOOS != "darwin" {
+		t.Skip("Skipping Darwin-specific test")
+	}
+
+	ctx := t.Context()
+	ctx = env.Set(ctx, "HOME", "/Users/testuser")
+
+	path, err := getDefaultSettingsPath(ctx, VSCodeInsidersOption)
+	require.NoError(t, err)
+	assert.Equal(t, "/Users/testuser/Library/Application Support/Code - Insiders/User/settings.json", path)
+}
+
+func TestGetDefaultSettingsPath_VSCodeInsiders_Windows(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("Skipping Windows-specific test")
+	}
+
+	ctx := t.Context()
+	ctx = env.Set(ctx, "APPDATA", `C:\Users\testuser\AppData\Roaming`)
+
+	path, err := getDefaultSettingsPath(ctx, VSCodeInsidersOption)
+	require.NoError(t, err)
+	assert.Equal(t, `C:\Users\testuser\AppData\Roaming\Code - Insiders\User\settings.json`, path)
+}
+
+func TestSettingsPathForOS(t *testing.T) {
+	ctx := env.Set(t.Context(), "APPDATA", filepath.Join("C:", "AppData", "Roaming"))
+	home := filepath.Join("home", "testuser")
+
+	tests := []struct {
+		ide  string
+		goos string
+		want string
+	}{
+		{VSCodiumOption, "linux", filepath.Join(home, ".config", "VSCodium", "User", "settings.json")},
+		{VSCodiumOption, "darwin", filepath.Join(home, "Library", "Application Support", "VSCodium", "User", "settings.json")},
+		{VSCodiumOption, "windows", filepath.Join("C:", "AppData", "Roaming", "VSCodium", "User", "settings.json")},
+		{WindsurfOption, "linux", filepath.Join(home, ".config", "Windsurf", "User", "settings.json")},
+		{WindsurfOption, "darwin", filepath.Join(home, "Library", "Application Support", "Windsurf", "User", "settings.json")},
+		{WindsurfOption, "windows", filepath.Join("C:", "AppData", "Roaming", "Windsurf", "User", "settings.json")},
+		{VSCodeInsidersOption, "linux", filepath.Join(home, ".config", "Code - Insiders", "User", "settings.json")},
+		{VSCodeInsidersOption, "darwin", filepath.Join(home, "Library", "Application Support", "Code - Insiders", "User", "settings.json")},
+		{VSCodeInsidersOption, "windows", filepath.Join("C:", "AppData", "Roaming", "Code - Insiders", "User", "settings.json")},
+		{VSCodeOption, "linux", filepath.Join(home, ".config", "Code", "User", "settings.json")},
+		{CursorOption, "darwin", filepath.Join(home, "Library", "Application Support", "Cursor", "User", "settings.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ide+"_"+tt.goos, func(t *testing.T) {
+			path, err := settingsPathForOS(ctx, getIDE(tt.ide), tt.goos, home)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, path)
+		})
+	}
+}
+
+func TestSettingsPathForOS_WindowsWithoutAppData(t *testing.T) {
+	ctx := env.Set(t.Context(), "APPDATA", "")
+	home := filepath.Join("home", "testuser")
+
+	path, err := settingsPathForOS(ctx, getIDE(WindsurfOption), "windows", home)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, "AppData", "Roaming", "Windsurf", "User", "settings.json"), path)
+}
+
+func TestSettingsPathForOS_Unsupported(t *testing.T) {
+	_, err := settingsPathForOS(t.Context(), getIDE(VSCodiumOption), "plan9", "/home/testuser")
+	assert.EqualError(t, err, "unsupported operating system: plan9")
+}
+
+func fakeProcVersion(t *testing.T, content string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "version")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	orig := procVersionPath
+	procVersionPath = path
+	t.Cleanup(func() { procVersionPath = orig })
+}
+
+func touchFile(t *testing.T, path string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte("{}"), 0o600))
+}
+
+func TestIsWSL(t *testing.T) {
+	ctx := env.Set(t.Context(), "WSL_DISTRO_NAME", "")
+
+	fakeProcVersion(t, "Linux version 6.8.0-45-generic (buildd@lcy02-amd64-075)")
+	assert.False(t, isWSL(ctx))
+
+	fakeProcVersion(t, "Linux version 5.15.153.1-microsoft-standard-WSL2 (root@941d701f84f1)")
+	assert.True(t, isWSL(ctx))
+
+	procVersionPath = filepath.Join(t.TempDir(), "missing")
+	assert.False(t, isWSL(ctx))
+	assert.True(t, isWSL(env.Set(ctx, "WSL_DISTRO_NAME", "Ubuntu")))
+}
+
+func TestResolveSettingsPath(t *testing.T) {
+	userSettings := filepath.Join(".config", "Code", "User", "settings.json")
+	machineSettings := filepath.Join(".vscode-server", "data", "Machine", "settings.json")
+
+	tests := []struct {
+		name   string
+		goos   string
+		wsl    bool
+		layout []string
+		want   string
+	}{
+		{
+			name: "nothing exists",
+			goos: "linux",
+			want: userSettings,
+		},
+		{
+			name:   "user settings exist",
+			goos:   "linux",
+			layout: []string{userSettings},
+			want:   userSettings,
+		},
+		{
+			name:   "machine settings exist",
+			goos:   "linux",
+			layout: []string{userSettings, machineSettings},
+			want:   machineSettings,
+		},
+		{
+			name:   "server without machine settings",
+			goos:   "linux",
+			layout: []string{filepath.Join(".vscode-server", "bin", "code")},
+			want:   userSettings,
+		},
+		{
+			name:   "WSL with server",
+			goos:   "linux",
+			wsl:    true,
+			layout: []string{filepath.Join(".vscode-server", "bin", "code")},
+			want:   machineSettings,
+		},
+		{
+			name:   "WSL with server and machine settings",
+			goos:   "linux",
+			wsl:    true,
+			layout: []string{machineSettings},
+			want:   machineSettings,
+		},
+		{
+			name:   "WSL with server and user settings",
+			goos:   "linux",
+			wsl:    true,
+			layout: []string{filepath.Join(".vscode-server", "bin", "code"), userSettings},
+			want:   userSettings,
+		},
+		{
+			name: "WSL without server",
+			goos: "linux",
+			wsl:  true,
+			want: userSettings,
+		},
+		{
+			name:   "machine settings are ignored on macOS",
+			goos:   "darwin",
+			layout: []string{machineSettings},
+			want:   filepath.Join("Library", "Application Support", "Code", "User", "settings.json"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			for _, path := range tt.layout {
+				touchFile(t, filepath.Join(home, path))
+			}
+			fakeProcVersion(t, "Linux version 6.8.0-45-generic")
+			ctx := env.Set(t.Context(), "WSL_DISTRO_NAME", "")
+			if tt.wsl {
+				ctx = env.Set(ctx, "WSL_DISTRO_NAME", "Ubuntu")
+			}
+
+			path, err := resolveSettingsPath(ctx, getIDE(VSCodeOption), tt.goos, home)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(home, tt.want), path)
+		})
+	}
+}
+
+func TestResolveSettingsPath_WSLFromProcVersion(t *testing.T) {
+	home := t.TempDir()
+	touchFile(t, filepath.Join(home, ".cursor-server", "bin", "cursor"))
+	fakeProcVersion(t, "Linux version 5.15.153.1-microsoft-standard-WSL2")
+	ctx := env.Set(t.Context(), "WSL_DISTRO_NAME", "")
+
+	path, err := resolveSettingsPath(ctx, getIDE(CursorOption), "linux", home)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(home, ".cursor-server", "data", "Machine", "settings.json"), path)
+}
+
+func TestGetManualInstructions_VSCodium(t *testing.T) {
+	instructions := GetManualInstructions(VSCodiumOption, "my-connection", DefaultPortRange, "", nil)
+
+	assert.Contains(t, instructions, "VSCodium settings.json")
+	assert.Contains(t, instructions, "my-connection")
+}
+
 func TestLoadSettings_Valid(t *testing.T) {
 	tmpDir := t.TempDir()
 	settingsPath := filepath.Join(tmpDir, "settings.json")
@@ -197,6 +416,29 @@ func TestLoadSettings_NotExists(t *testing.T) {
 	assert.ErrorIs(t, err, fs.ErrNotExist)
 }
 
+func TestLoadSettings_NotObject(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"empty", "", "the file is empty, expected a JSON object"},
+		{"whitespace", " \n\t\n", "the file is empty, expected a JSON object"},
+		{"null", "null", "the top-level value is null, expected a JSON object"},
+		{"array", `[{"remote.SSH.remotePlatform": {"my-host": "linux"}}]`, "the top-level value is an array, expected a JSON object"},
+		{"string", `// Comment
+		"settings"`, "the top-level value is a string, expected a JSON object"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			settingsPath := writeTestSettings(t, tt.content)
+
+			_, err := loadSettings(settingsPath)
+			assert.ErrorIs(t, err, errSettingsNotObject)
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
+
 func TestValidateSettings_Complete(t *testing.T) {
 	v := parseTestValue(t, `{
 		"remote.SSH.serverPickPortsFromRange": {"test-conn": "29500-29505"},
@@ -205,14 +447,14 @@ func TestValidateSettings_Complete(t *testing.T) {
 		"remote.SSH.defaultExtensions": ["ms-python.python", "ms-toolsai.jupyter", "databricks.databricks"]
 	}`)
 
-	missing := validateSettings(v, "test-conn")
+	missing := validateSettings(v, "test-conn", DefaultPortRange, nil)
 	assert.True(t, missing.isEmpty())
 }
 
 func TestValidateSettings_Missing(t *testing.T) {
 	v := parseTestValue(t, `{}`)
 
-	missing := validateSettings(v, "test-conn")
+	missing := validateSettings(v, "test-conn", DefaultPortRange, nil)
 	assert.False(t, missing.isEmpty())
 	assert.True(t, missing.portRange)
 	assert.True(t, missing.platform)
@@ -226,7 +468,7 @@ func TestValidateSettings_IncorrectValues(t *testing.T) {
 		"remote.SSH.defaultExtensions": ["ms-python.python"]
 	}`)
 
-	missing := validateSettings(v, "test-conn")
+	missing := validateSettings(v, "test-conn", DefaultPortRange, nil)
 	assert.False(t, missing.isEmpty())
 	assert.True(t, missing.portRange)
 	assert.True(t, missing.platform)
@@ -241,7 +483,7 @@ func TestValidateSettings_DuplicateExtensionsNotReported(t *testing.T) {
 		"remote.SSH.defaultExtensions": ["ms-python.python", "ms-python.python", "ms-toolsai.jupyter", "databricks.databricks"]
 	}`)
 
-	missing := validateSettings(v, "test-conn")
+	missing := validateSettings(v, "test-conn", DefaultPortRange, nil)
 	assert.True(t, missing.isEmpty())
 }
 
@@ -253,13 +495,245 @@ func TestValidateSettings_MissingConnection(t *testing.T) {
 	}`)
 
 	// Validating for a different connection should show port and platform as missing
-	missing := validateSettings(v, "test-conn")
+	missing := validateSettings(v, "test-conn", DefaultPortRange, nil)
 	assert.False(t, missing.isEmpty())
 	assert.True(t, missing.portRange)
 	assert.True(t, missing.platform)
 	assert.Empty(t, missing.extensions) // Extensions are global, so they're present
 }
 
+func TestValidateSettings_CustomPortRange(t *testing.T) {
+	v := parseTestValue(t, `{
+		"remote.SSH.serverPickPortsFromRange": {"test-conn": "4000-4005"},
+		"remote.SSH.remotePlatform": {"test-conn": "linux"},
+		"remote.SSH.remoteServerListenOnSocket": true,
+		"remote.SSH.defaultExtensions": ["ms-python.python", "ms-toolsai.jupyter", "databricks.databricks"]
+	}`)
+
+	assert.True(t, validateSettings(v, "test-conn", "4000-4005", nil).isEmpty())
+
+	// The range differs from the configured one.
+	missing := validateSettings(v, "test-conn", DefaultPortRange, nil)
+	assert.True(t, missing.portRange)
+	assert.False(t, missing.platform)
+}
+
+func TestUpdateSettings_CustomPortRange(t *testing.T) {
+	v := parseTestValue(t, `{
+		"remote.SSH.serverPickPortsFromRange": {
+			"conn-a": "5000-5005",
+			"conn-b": "29500-29505"
+		}
+	}`)
+
+	missing := validateSettings(v, "conn-b", "4000-4010", nil)
+	require.True(t, missing.portRange)
+	err := updateSettings(&v, "conn-b", "4000-4010", missing)
+	require.NoError(t, err)
+
+	val, ok := findString(t, v, jsonPtr(serverPickPortsKey, "conn-b"))
+	assert.True(t, ok)
+	assert.Equal(t, "4000-4010", val)
+
+	// The range of another connection is preserved.
+	val, ok = findString(t, v, jsonPtr(serverPickPortsKey, "conn-a"))
+	assert.True(t, ok)
+	assert.Equal(t, "5000-5005", val)
+}
+
+func TestValidatePortRange(t *testing.T) {
+	for _, portRange := range []string{DefaultPortRange, "4000-4005", "1024-1123", "65500-65535"} {
+		assert.NoError(t, ValidatePortRange(portRange), portRange)
+	}
+
+	tests := []struct {
+		portRange string
+		err       string
+	}{
+		{"", "expected the format N-M"},
+		{"4000", "expected the format N-M"},
+		{"4000-", "expected the format N-M"},
+		{"a-b", "expected the format N-M"},
+		{"4000-4005-4010", "expected the format N-M"},
+		{"80-85", "ports must be between 1024 and 65535"},
+		{"65530-65540", "ports must be between 1024 and 65535"},
+		{"4005-4000", "the last port must be greater than the first one"},
+		{"4000-4000", "the last port must be greater than the first one"},
+		{"4000-5000", "it must span at most 100 ports"},
+	}
+	for _, tt := range tests {
+		assert.ErrorContains(t, ValidatePortRange(tt.portRange), tt.err, tt.portRange)
+	}
+}
+
+func TestChoosePortRange(t *testing.T) {
+	tests := []struct {
+		name      string
+		settings  string
+		portRange string
+		expected  string
+	}{
+		{
+			name:     "no settings",
+			settings: `{}`,
+			expected: DefaultPortRange,
+		},
+		{
+			name:     "default range taken",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29505"}}`,
+			expected: DefaultPortRange,
+		},
+		{
+			name:     "part of the default range taken",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29501"}}`,
+			expected: "29502-29503",
+		},
+		{
+			name: "several ranges taken",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {
+				"conn-a": "29500-29501",
+				"conn-b": "29502-29503",
+				"conn-c": "4000-4005",
+				"conn-d": "invalid"
+			}}`,
+			expected: "29504-29505",
+		},
+		{
+			name:     "gap between taken ranges",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29501", "conn-b": "29504-29505"}}`,
+			expected: "29502-29503",
+		},
+		{
+			name:     "ranges outside the default range are not picked",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29501", "conn-b": "29503-29505"}}`,
+			expected: DefaultPortRange,
+		},
+		{
+			name:     "own range kept",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29505", "my-conn": "4000-4010"}}`,
+			expected: "4000-4010",
+		},
+		{
+			name:     "own default range kept",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"my-conn": "29500-29505", "conn-a": "29506-29511"}}`,
+			expected: DefaultPortRange,
+		},
+		{
+			name:     "own range overlaps",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29501", "my-conn": "29501-29502"}}`,
+			expected: "29502-29503",
+		},
+		{
+			name:     "own range invalid",
+			settings: `{"remote.SSH.serverPickPortsFromRange": {"my-conn": "invalid"}}`,
+			expected: DefaultPortRange,
+		},
+		{
+			name:      "explicit range",
+			settings:  `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29505"}}`,
+			portRange: "29500-29505",
+			expected:  "29500-29505",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := parseTestValue(t, tt.settings)
+			assert.Equal(t, tt.expected, choosePortRange(v, "my-conn", tt.portRange))
+		})
+	}
+}
+
+func TestValidateSettings_AutomaticPortRange(t *testing.T) {
+	v := parseTestValue(t, `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29501", "my-conn": "29500-29505"}}`)
+	assert.True(t, validateSettings(v, "my-conn", choosePortRange(v, "my-conn", ""), nil).portRange)
+
+	v = parseTestValue(t, `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29505", "my-conn": "4000-4005"}}`)
+	assert.False(t, validateSettings(v, "my-conn", choosePortRange(v, "my-conn", ""), nil).portRange)
+}
+
+func TestCheckAndUpdateSettings_AutomaticPortRange(t *testing.T) {
+	ctx, _ := cmdio.NewTestContextWithStderr(t.Context())
+	settingsPath := writeTestSettings(t, `{"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29501"}}`)
+
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", "", settingsPath, nil, true)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	val, ok := findString(t, v, jsonPtr(serverPickPortsKey, "my-host"))
+	assert.True(t, ok)
+	assert.Equal(t, "29502-29503", val)
+	val, ok = findString(t, v, jsonPtr(serverPickPortsKey, "conn-a"))
+	assert.True(t, ok)
+	assert.Equal(t, "29500-29501", val)
+
+	// The range picked for the connection is kept on the next run.
+	assert.True(t, validateSettings(v, "my-host", choosePortRange(v, "my-host", ""), nil).isEmpty())
+}
+
+func TestValidateExtensions(t *testing.T) {
+	assert.NoError(t, ValidateExtensions(nil))
+	assert.NoError(t, ValidateExtensions(DefaultExtensions))
+	assert.NoError(t, ValidateExtensions([]string{"scala-lang.scala", "REditorSupport.r"}))
+
+	for _, ext := range []string{"", "scala", "scala-lang.", ".scala", "a.b.c", "scala lang.scala"} {
+		assert.ErrorContains(t, ValidateExtensions([]string{ext}), "expected the format publisher.name", ext)
+	}
+}
+
+func TestValidateSettings_CustomExtensions(t *testing.T) {
+	v := parseTestValue(t, `{
+		"remote.SSH.defaultExtensions": ["ms-python.python", "ms-toolsai.jupyter"]
+	}`)
+
+	missing := validateSettings(v, "test-conn", DefaultPortRange, nil)
+	assert.Equal(t, []string{databricksExtension}, missing.extensions)
+
+	missing = validateSettings(v, "test-conn", DefaultPortRange, []string{"ms-python.python", "scala-lang.scala"})
+	assert.Equal(t, []string{"scala-lang.scala"}, missing.extensions)
+
+	missing = validateSettings(v, "test-conn", DefaultPortRange, []string{"ms-python.python"})
+	assert.Empty(t, missing.extensions)
+}
+
+func TestValidateSettings_NoExtensions(t *testing.T) {
+	v := parseTestValue(t, `{
+		"remote.SSH.serverPickPortsFromRange": {"test-conn": "29500-29505"},
+		"remote.SSH.remotePlatform": {"test-conn": "linux"},
+		"remote.SSH.remoteServerListenOnSocket": true
+	}`)
+
+	missing := validateSettings(v, "test-conn", DefaultPortRange, []string{})
+	assert.True(t, missing.isEmpty())
+	assert.NotContains(t, missing.keys(), defaultExtensionsKey)
+}
+
+func TestCheckAndUpdateSettings_CustomExtensionsMerge(t *testing.T) {
+	ctx, _ := cmdio.NewTestContextWithStderr(t.Context())
+	settingsPath := writeTestSettings(t, `{"remote.SSH.defaultExtensions": ["ms-python.python", "golang.go"]}`)
+
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", "", settingsPath, []string{"ms-python.python", "scala-lang.scala"}, true)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	// Pre-existing entries are kept, and only the missing extensions are added.
+	assert.Equal(t, []string{"ms-python.python", "golang.go", "scala-lang.scala"}, findStringSlice(t, v, jsonPtr(defaultExtensionsKey)))
+}
+
+func TestCheckAndUpdateSettings_NoExtensions(t *testing.T) {
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", "", settingsPath, []string{}, true)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.Nil(t, v.Find(jsonPtr(defaultExtensionsKey)))
+	assert.NotContains(t, stderr.String(), defaultExtensionsKey)
+}
+
 func TestUpdateSettings_PreserveExistingConnections(t *testing.T) {
 	v := parseTestValue(t, `{
 		"remote.SSH.serverPickPortsFromRange": {
@@ -279,7 +753,7 @@ func TestUpdateSettings_PreserveExistingConnections(t *testing.T) {
 		extensions: []string{"ms-python.python", "ms-toolsai.jupyter", "databricks.databricks"},
 	}
 
-	err := updateSettings(&v, "conn-c", missing)
+	err := updateSettings(&v, "conn-c", DefaultPortRange, missing)
 	require.NoError(t, err)
 
 	// Check that new connection was added
@@ -326,7 +800,7 @@ func TestUpdateSettings_NewConnection(t *testing.T) {
 		extensions: []string{"ms-python.python", "ms-toolsai.jupyter", "databricks.databricks"},
 	}
 
-	err := updateSettings(&v, "new-conn", missing)
+	err := updateSettings(&v, "new-conn", DefaultPortRange, missing)
 	require.NoError(t, err)
 
 	val, ok := findString(t, v, jsonPtr(serverPickPortsKey, "new-conn"))
@@ -354,7 +828,7 @@ func TestUpdateSettings_GlobalExtensions(t *testing.T) {
 		extensions: []string{"ms-toolsai.jupyter"},
 	}
 
-	err := updateSettings(&v, "conn-a", missing)
+	err := updateSettings(&v, "conn-a", DefaultPortRange, missing)
 	require.NoError(t, err)
 
 	exts := findStringSlice(t, v, jsonPtr(defaultExtensionsKey))
@@ -367,7 +841,7 @@ func TestUpdateSettings_GlobalExtensions(t *testing.T) {
 		extensions: []string{"another.extension"},
 	}
 
-	err = updateSettings(&v, "conn-b", missing2)
+	err = updateSettings(&v, "conn-b", DefaultPortRange, missing2)
 	require.NoError(t, err)
 
 	exts = findStringSlice(t, v, jsonPtr(defaultExtensionsKey))
@@ -386,7 +860,7 @@ func TestUpdateSettings_MergeExtensions(t *testing.T) {
 		extensions: []string{"ms-toolsai.jupyter"}, // ms-python.python already present
 	}
 
-	err := updateSettings(&v, "test-conn", missing)
+	err := updateSettings(&v, "test-conn", DefaultPortRange, missing)
 	require.NoError(t, err)
 
 	exts := findStringSlice(t, v, jsonPtr(defaultExtensionsKey))
@@ -409,7 +883,7 @@ func TestUpdateSettings_PartialUpdate(t *testing.T) {
 		extensions: nil,   // Already present
 	}
 
-	err := updateSettings(&v, "test-conn", missing)
+	err := updateSettings(&v, "test-conn", DefaultPortRange, missing)
 	require.NoError(t, err)
 
 	// Port range should not be modified
@@ -457,7 +931,7 @@ func TestCheckAndUpdateSettings_CreatesBackup(t *testing.T) {
 		_ = tst.Stdin.Flush()
 	}()
 
-	err = CheckAndUpdateSettings(ctx, "cursor", "my-host")
+	err = CheckAndUpdateSettings(ctx, "cursor", "my-host", DefaultPortRange, "", nil, false)
 	require.NoError(t, err)
 
 	originalBakContent, err := os.ReadFile(settingsPath + fileutil.SuffixOriginalBak)
@@ -473,7 +947,7 @@ func TestCheckAndUpdateSettings_CreatesBackup(t *testing.T) {
 		_ = tst.Stdin.Flush()
 	}()
 
-	err = CheckAndUpdateSettings(ctx, "cursor", "my-host-2")
+	err = CheckAndUpdateSettings(ctx, "cursor", "my-host-2", DefaultPortRange, "", nil, false)
 	require.NoError(t, err)
 
 	latestBakContent, err := os.ReadFile(settingsPath + fileutil.SuffixLatestBak)
@@ -486,6 +960,65 @@ func TestCheckAndUpdateSettings_CreatesBackup(t *testing.T) {
 	assert.Equal(t, originalContent, originalBakContent2)
 }
 
+func TestCheckAndUpdateSettings_CustomSettingsPath(t *testing.T) {
+	ctx, tst := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+	defer tst.Done()
+	go func() { _, _ = io.Copy(io.Discard, tst.Stderr) }()
+
+	// The file is in a profile directory that isn't the default one.
+	settingsPath := filepath.Join(t.TempDir(), "profiles", "work", "settings.json")
+	require.NoError(t, os.MkdirAll(filepath.Dir(settingsPath), 0o755))
+	originalContent := []byte(`{"remote.SSH.serverPickPortsFromRange": {"other-conn": "5000-5005"}}`)
+	require.NoError(t, os.WriteFile(settingsPath, originalContent, 0o600))
+
+	go func() {
+		_, _ = tst.Stdin.WriteString("y\n")
+		_ = tst.Stdin.Flush()
+	}()
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, false)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.True(t, validateSettings(v, "my-host", DefaultPortRange, nil).isEmpty())
+	val, ok := findString(t, v, jsonPtr(serverPickPortsKey, "other-conn"))
+	assert.True(t, ok)
+	assert.Equal(t, "5000-5005", val)
+
+	backup, err := os.ReadFile(settingsPath + fileutil.SuffixOriginalBak)
+	require.NoError(t, err)
+	assert.Equal(t, originalContent, backup)
+}
+
+func TestCheckAndUpdateSettings_CustomSettingsPathCreatesFile(t *testing.T) {
+	ctx, tst := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+	defer tst.Done()
+	go func() { _, _ = io.Copy(io.Discard, tst.Stderr) }()
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	go func() {
+		_, _ = tst.Stdin.WriteString("y\n")
+		_ = tst.Stdin.Flush()
+	}()
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, false)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.True(t, validateSettings(v, "my-host", DefaultPortRange, nil).isEmpty())
+}
+
+func TestCheckAndUpdateSettings_CustomSettingsPathMissingDir(t *testing.T) {
+	ctx, tst := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+	defer tst.Done()
+
+	dir := filepath.Join(t.TempDir(), "missing")
+	settingsPath := filepath.Join(dir, "settings.json")
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, false)
+	assert.EqualError(t, err, "the directory of the settings file "+filepath.ToSlash(settingsPath)+" does not exist")
+	assert.NoDirExists(t, dir)
+}
+
 func TestSaveSettings_Formatting(t *testing.T) {
 	tmpDir := t.TempDir()
 	settingsPath := filepath.Join(tmpDir, "settings.json")
@@ -532,7 +1065,7 @@ func TestSaveSettings_PreservesComments(t *testing.T) {
 
 	// Add a new setting
 	missing := &missingSettings{listenOnSocket: true}
-	err = updateSettings(&v, "test-conn", missing)
+	err = updateSettings(&v, "test-conn", DefaultPortRange, missing)
 	require.NoError(t, err)
 
 	err = saveSettings(settingsPath, &v)
@@ -555,7 +1088,7 @@ func TestMissingSettings_IsEmpty(t *testing.T) {
 }
 
 func TestGetManualInstructions_VSCode(t *testing.T) {
-	instructions := GetManualInstructions(VSCodeOption, "test-conn")
+	instructions := GetManualInstructions(VSCodeOption, "test-conn", DefaultPortRange, "", nil)
 
 	assert.Contains(t, instructions, "VS Code")
 	assert.Contains(t, instructions, "test-conn")
@@ -570,7 +1103,7 @@ func TestGetManualInstructions_VSCode(t *testing.T) {
 }
 
 func TestGetManualInstructions_Cursor(t *testing.T) {
-	instructions := GetManualInstructions("cursor", "my-connection")
+	instructions := GetManualInstructions("cursor", "my-connection", DefaultPortRange, "", nil)
 
 	assert.Contains(t, instructions, "Cursor")
 	assert.Contains(t, instructions, "my-connection")
@@ -579,3 +1112,293 @@ func TestGetManualInstructions_Cursor(t *testing.T) {
 	assert.Contains(t, instructions, "ms-python.python")
 	assert.Contains(t, instructions, "ms-toolsai.jupyter")
 }
+
+func TestGetManualInstructions_CustomExtensions(t *testing.T) {
+	instructions := GetManualInstructions(VSCodeOption, "test-conn", DefaultPortRange, "", []string{"scala-lang.scala", "REditorSupport.r"})
+	assert.Contains(t, instructions, `"remote.SSH.defaultExtensions": ["scala-lang.scala", "REditorSupport.r"]`)
+	assert.NotContains(t, instructions, pythonExtension)
+
+	instructions = GetManualInstructions(VSCodeOption, "test-conn", DefaultPortRange, "", []string{})
+	assert.NotContains(t, instructions, defaultExtensionsKey)
+	assert.Contains(t, instructions, remotePlatformKey)
+}
+
+func TestGetManualInstructions_CustomPortRange(t *testing.T) {
+	instructions := GetManualInstructions(VSCodeOption, "test-conn", "4000-4005", "", nil)
+	assert.Contains(t, instructions, `"test-conn": "4000-4005"`)
+	assert.NotContains(t, instructions, DefaultPortRange)
+}
+
+func TestGetManualInstructions_CustomSettingsPath(t *testing.T) {
+	settingsPath := filepath.Join("profiles", "work", "settings.json")
+	instructions := GetManualInstructions(VSCodeOption, "test-conn", DefaultPortRange, settingsPath, nil)
+	assert.Contains(t, instructions, "manually add these settings to profiles/work/settings.json:")
+	assert.Contains(t, instructions, `"test-conn": "29500-29505"`)
+}
+
+func writeTestSettings(t *testing.T, content string) string {
+	t.Helper()
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	require.NoError(t, os.WriteFile(settingsPath, []byte(content), 0o600))
+	return settingsPath
+}
+
+func TestFindConnectionSettings_NotObject(t *testing.T) {
+	settingsPath := writeTestSettings(t, "[]")
+
+	path, found, err := FindConnectionSettings(t.Context(), "vscode", "my-host", settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, settingsPath, path)
+	assert.False(t, found)
+}
+
+func TestRemoveConnectionSettings_Present(t *testing.T) {
+	original := `{
+	// My settings
+	"editor.fontSize": 14,
+	"remote.SSH.serverPickPortsFromRange": {
+		"conn-a": "29500-29505",
+		// Keep this connection
+		"conn-b": "4000-4005"
+	},
+	"remote.SSH.remotePlatform": {"conn-a": "linux", "conn-b": "linux"},
+	"remote.SSH.defaultExtensions": ["ms-python.python"]
+}`
+	settingsPath := writeTestSettings(t, original)
+
+	path, found, err := FindConnectionSettings(t.Context(), VSCodeOption, "conn-a", settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, settingsPath, path)
+	assert.True(t, found)
+
+	removed, err := RemoveConnectionSettings(t.Context(), VSCodeOption, "conn-a", settingsPath)
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.Nil(t, v.Find(jsonPtr(serverPickPortsKey, "conn-a")))
+	assert.Nil(t, v.Find(jsonPtr(remotePlatformKey, "conn-a")))
+	val, ok := findString(t, v, jsonPtr(serverPickPortsKey, "conn-b"))
+	assert.True(t, ok)
+	assert.Equal(t, "4000-4005", val)
+	val, ok = findString(t, v, jsonPtr(remotePlatformKey, "conn-b"))
+	assert.True(t, ok)
+	assert.Equal(t, "linux", val)
+	assert.Equal(t, []string{"ms-python.python"}, findStringSlice(t, v, jsonPtr(defaultExtensionsKey)))
+
+	content, err := os.ReadFile(settingsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "// My settings")
+	assert.Contains(t, string(content), "// Keep this connection")
+
+	backup, err := os.ReadFile(settingsPath + fileutil.SuffixOriginalBak)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(backup))
+}
+
+func TestRemoveConnectionSettings_Absent(t *testing.T) {
+	original := `{
+	// My settings
+	"remote.SSH.serverPickPortsFromRange": {"conn-b": "4000-4005"}
+}`
+	settingsPath := writeTestSettings(t, original)
+
+	_, found, err := FindConnectionSettings(t.Context(), VSCodeOption, "conn-a", settingsPath)
+	require.NoError(t, err)
+	assert.False(t, found)
+
+	removed, err := RemoveConnectionSettings(t.Context(), VSCodeOption, "conn-a", settingsPath)
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	// The file is neither modified nor backed up.
+	content, err := os.ReadFile(settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+	assert.NoFileExists(t, settingsPath+fileutil.SuffixOriginalBak)
+
+	// A missing settings file has nothing to remove either.
+	removed, err = RemoveConnectionSettings(t.Context(), VSCodeOption, "conn-a", filepath.Join(t.TempDir(), "settings.json"))
+	require.NoError(t, err)
+	assert.False(t, removed)
+}
+
+func TestRemoveConnectionSettings_LastEntry(t *testing.T) {
+	settingsPath := writeTestSettings(t, `{
+	// My settings
+	"remote.SSH.serverPickPortsFromRange": {"conn-a": "29500-29505"},
+	"remote.SSH.remotePlatform": {"conn-a": "linux"},
+	"remote.SSH.remoteServerListenOnSocket": true
+}`)
+
+	removed, err := RemoveConnectionSettings(t.Context(), VSCodeOption, "conn-a", settingsPath)
+	require.NoError(t, err)
+	assert.True(t, removed)
+
+	// The maps are left empty rather than removed.
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	for _, key := range []string{serverPickPortsKey, remotePlatformKey} {
+		found := v.Find(jsonPtr(key))
+		require.NotNil(t, found, key)
+		obj, ok := found.Value.(*hujson.Object)
+		require.True(t, ok, key)
+		assert.Empty(t, obj.Members, key)
+	}
+	assert.True(t, hasCorrectListenOnSocket(v))
+
+	content, err := os.ReadFile(settingsPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "// My settings")
+}
+
+func TestCheckAndUpdateSettings_NonInteractiveAutoApprove(t *testing.T) {
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+	require.False(t, cmdio.IsPromptSupported(ctx))
+
+	original := `{"remote.SSH.remotePlatform": {"my-host": "linux"}}`
+	settingsPath := writeTestSettings(t, original)
+
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, true)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.True(t, validateSettings(v, "my-host", DefaultPortRange, nil).isEmpty())
+
+	backup, err := os.ReadFile(settingsPath + fileutil.SuffixOriginalBak)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(backup))
+
+	assert.Contains(t, stderr.String(), "Updated VS Code settings for 'my-host': remote.SSH.serverPickPortsFromRange, remote.SSH.remoteServerListenOnSocket, remote.SSH.defaultExtensions")
+}
+
+func TestCheckAndUpdateSettings_NonInteractiveAutoApproveCreatesFile(t *testing.T) {
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+
+	settingsPath := filepath.Join(t.TempDir(), "settings.json")
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, true)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.True(t, validateSettings(v, "my-host", DefaultPortRange, nil).isEmpty())
+	assert.Contains(t, stderr.String(), "Created VS Code settings at "+filepath.ToSlash(settingsPath)+": remote.SSH.serverPickPortsFromRange, remote.SSH.remotePlatform")
+}
+
+func TestCheckAndUpdateSettings_NonInteractiveWithoutAutoApprove(t *testing.T) {
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+
+	original := `{"remote.SSH.remotePlatform": {"my-host": "linux"}}`
+	settingsPath := writeTestSettings(t, original)
+
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, false)
+	require.NoError(t, err)
+
+	// The file is untouched, and the settings to add manually are printed.
+	content, err := os.ReadFile(settingsPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+	assert.NoFileExists(t, settingsPath+fileutil.SuffixOriginalBak)
+	assert.Contains(t, stderr.String(), "Use --auto-approve-ide-settings to apply them without a prompt.")
+	assert.Contains(t, stderr.String(), GetManualInstructions("vscode", "my-host", DefaultPortRange, settingsPath, nil))
+
+	// A missing file isn't created either.
+	missingPath := filepath.Join(t.TempDir(), "settings.json")
+	err = CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, missingPath, nil, false)
+	require.NoError(t, err)
+	assert.NoFileExists(t, missingPath)
+}
+
+func TestCheckAndUpdateSettings_NotObjectNonInteractive(t *testing.T) {
+	for _, content := range []string{"", "null", "[]"} {
+		t.Run(content, func(t *testing.T) {
+			ctx, _ := cmdio.NewTestContextWithStderr(t.Context())
+			settingsPath := writeTestSettings(t, content)
+
+			err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, false)
+			assert.ErrorContains(t, err, "invalid VS Code settings file "+filepath.ToSlash(settingsPath)+": ")
+			assert.ErrorIs(t, err, errSettingsNotObject)
+			assert.ErrorContains(t, err, "Use --auto-approve-ide-settings")
+
+			// The file is untouched.
+			data, err := os.ReadFile(settingsPath)
+			require.NoError(t, err)
+			assert.Equal(t, content, string(data))
+			assert.NoFileExists(t, settingsPath+fileutil.SuffixCorruptBak)
+		})
+	}
+}
+
+func TestCheckAndUpdateSettings_NotObjectAutoApprove(t *testing.T) {
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+	original := `[{"remote.SSH.remotePlatform": {"my-host": "linux"}}]`
+	settingsPath := writeTestSettings(t, original)
+
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, true)
+	require.NoError(t, err)
+
+	v, err := loadSettings(settingsPath)
+	require.NoError(t, err)
+	assert.True(t, validateSettings(v, "my-host", DefaultPortRange, nil).isEmpty())
+
+	corrupt, err := os.ReadFile(settingsPath + fileutil.SuffixCorruptBak)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(corrupt))
+	assert.Contains(t, stderr.String(), "Moved invalid VS Code settings to "+filepath.ToSlash(settingsPath+fileutil.SuffixCorruptBak))
+	assert.Contains(t, stderr.String(), "Created VS Code settings at "+filepath.ToSlash(settingsPath))
+}
+
+func TestCheckAndUpdateSettings_NotObjectPrompt(t *testing.T) {
+	for _, tt := range []struct {
+		answer   string
+		replaced bool
+	}{
+		{"y\n", true},
+		{"n\n", false},
+	} {
+		t.Run(tt.answer[:1], func(t *testing.T) {
+			ctx, tst := cmdio.SetupTest(t.Context(), cmdio.TestOptions{PromptSupported: true})
+			defer tst.Done()
+			settingsPath := writeTestSettings(t, "null")
+
+			// Drain stderr (where the prompt is written) and feed the answer to stdin.
+			go func() { _, _ = io.Copy(io.Discard, tst.Stderr) }()
+			go func() {
+				_, _ = tst.Stdin.WriteString(tt.answer)
+				_ = tst.Stdin.Flush()
+			}()
+
+			err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, false)
+			require.NoError(t, err)
+
+			if tt.replaced {
+				v, err := loadSettings(settingsPath)
+				require.NoError(t, err)
+				assert.True(t, validateSettings(v, "my-host", DefaultPortRange, nil).isEmpty())
+				assert.FileExists(t, settingsPath+fileutil.SuffixCorruptBak)
+			} else {
+				data, err := os.ReadFile(settingsPath)
+				require.NoError(t, err)
+				assert.Equal(t, "null", string(data))
+				assert.NoFileExists(t, settingsPath+fileutil.SuffixCorruptBak)
+			}
+		})
+	}
+}
+
+func TestCheckAndUpdateSettings_NonInteractiveAlreadyCorrect(t *testing.T) {
+	ctx, stderr := cmdio.NewTestContextWithStderr(t.Context())
+
+	settingsPath := writeTestSettings(t, `{
+		"remote.SSH.serverPickPortsFromRange": {"my-host": "29500-29505"},
+		"remote.SSH.remotePlatform": {"my-host": "linux"},
+		"remote.SSH.remoteServerListenOnSocket": true,
+		"remote.SSH.defaultExtensions": ["ms-python.python", "ms-toolsai.jupyter", "databricks.databricks"]
+	}`)
+
+	err := CheckAndUpdateSettings(ctx, "vscode", "my-host", DefaultPortRange, settingsPath, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, stderr.String())
+}
@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/databricks/databricks-sdk-go/config"
@@ -11,6 +13,53 @@ import (
 // user explicitly skips workspace selection for SPOG account-level access.
 const WorkspaceIDNone = "none"
 
+// accountIDRe matches account IDs, which are UUIDs.
+var accountIDRe = regexp.MustCompile(`^(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}$`)
+
+// workspaceIDRe matches workspace IDs, which are numeric.
+var workspaceIDRe = regexp.MustCompile(`^[0-9]+$`)
+
+// idHelp explains the IDs that are commonly confused with each other.
+const idHelp = `The account ID is a UUID such as 01234567-89ab-cdef-0123-456789abcdef. Find it in the account console by clicking your username in the top right.
+The workspace ID is a number. Find it in the URL of the workspace after ?o= or in the account console under Workspaces.
+The org ID in a workspace URL (?o=...) is the workspace ID, not the account ID.`
+
+// ValidateAccountID returns an error if id is set and is not a UUID. Numeric
+// IDs are typically workspace IDs or org IDs passed by mistake.
+func ValidateAccountID(id string) error {
+	if id == "" || accountIDRe.MatchString(id) {
+		return nil
+	}
+	if workspaceIDRe.MatchString(id) {
+		return fmt.Errorf("invalid account ID %q: this looks like a workspace ID or org ID\n\n%s", id, idHelp)
+	}
+	return fmt.Errorf("invalid account ID %q: expected a UUID\n\n%s", id, idHelp)
+}
+
+// ValidateWorkspaceID returns an error if id is set and is neither numeric nor
+// [WorkspaceIDNone].
+func ValidateWorkspaceID(id string) error {
+	if id == "" || id == WorkspaceIDNone || workspaceIDRe.MatchString(id) {
+		return nil
+	}
+	if accountIDRe.MatchString(id) {
+		return fmt.Errorf("invalid workspace ID %q: this looks like an account ID\n\n%s", id, idHelp)
+	}
+	return fmt.Errorf("invalid workspace ID %q: expected a number\n\n%s", id, idHelp)
+}
+
+// Validate returns an error if the account ID or, for unified hosts, the
+// workspace ID of a is malformed.
+func (a AuthArguments) Validate() error {
+	if err := ValidateAccountID(a.AccountID); err != nil {
+		return err
+	}
+	if a.IsUnifiedHost {
+		return ValidateWorkspaceID(a.WorkspaceID)
+	}
+	return nil
+}
+
 // AuthArguments is a struct that contains the common arguments passed to
 // `databricks auth` commands.
 type AuthArguments struct {
@@ -32,6 +81,12 @@ type AuthArguments struct {
 // It calls EnsureResolved() to run host metadata discovery and routes based on
 // the resolved DiscoveryURL rather than the Experimental_IsUnifiedHost flag.
 func (a AuthArguments) ToOAuthArgument() (u2m.OAuthArgument, error) {
+	// Malformed IDs otherwise fail later during endpoint discovery with an
+	// error that does not point at the ID.
+	if err := a.Validate(); err != nil {
+		return nil, err
+	}
+
 	// Strip the "none" sentinel so it is never passed to the SDK.
 	workspaceID := a.WorkspaceID
 	if workspaceID == WorkspaceIDNone {
@@ -48,25 +48,25 @@ func TestToOAuthArgument(t *testing.T) {
 			name: "account with no scheme",
 			args: AuthArguments{
 				Host:      "accounts.cloud.databricks.com",
-				AccountID: "123456789",
+				AccountID: "01234567-89ab-cdef-0123-456789abcdef",
 			},
 			wantHost:     "https://accounts.cloud.databricks.com",
-			wantCacheKey: "https://accounts.cloud.databricks.com/oidc/accounts/123456789",
+			wantCacheKey: "https://accounts.cloud.databricks.com/oidc/accounts/01234567-89ab-cdef-0123-456789abcdef",
 		},
 		{
 			name: "account with https",
 			args: AuthArguments{
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "123456789",
+				AccountID: "01234567-89ab-cdef-0123-456789abcdef",
 			},
 			wantHost:     "https://accounts.cloud.databricks.com",
-			wantCacheKey: "https://accounts.cloud.databricks.com/oidc/accounts/123456789",
+			wantCacheKey: "https://accounts.cloud.databricks.com/oidc/accounts/01234567-89ab-cdef-0123-456789abcdef",
 		},
 		{
 			name: "account with profile uses profile-based cache key",
 			args: AuthArguments{
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "123456789",
+				AccountID: "01234567-89ab-cdef-0123-456789abcdef",
 				Profile:   "my-account-profile",
 			},
 			wantHost:     "https://accounts.cloud.databricks.com",
@@ -92,28 +92,28 @@ func TestToOAuthArgument(t *testing.T) {
 			name: "unified host with account ID only",
 			args: AuthArguments{
 				Host:          "https://unified.cloud.databricks.com",
-				AccountID:     "123456789",
+				AccountID:     "01234567-89ab-cdef-0123-456789abcdef",
 				IsUnifiedHost: true,
 			},
 			wantHost:     "https://unified.cloud.databricks.com",
-			wantCacheKey: "https://unified.cloud.databricks.com/oidc/accounts/123456789",
+			wantCacheKey: "https://unified.cloud.databricks.com/oidc/accounts/01234567-89ab-cdef-0123-456789abcdef",
 		},
 		{
 			name: "unified host with both account ID and workspace ID",
 			args: AuthArguments{
 				Host:          "https://unified.cloud.databricks.com",
-				AccountID:     "123456789",
+				AccountID:     "01234567-89ab-cdef-0123-456789abcdef",
 				WorkspaceID:   "123456789",
 				IsUnifiedHost: true,
 			},
 			wantHost:     "https://unified.cloud.databricks.com",
-			wantCacheKey: "https://unified.cloud.databricks.com/oidc/accounts/123456789",
+			wantCacheKey: "https://unified.cloud.databricks.com/oidc/accounts/01234567-89ab-cdef-0123-456789abcdef",
 		},
 		{
 			name: "unified host with profile uses profile-based cache key",
 			args: AuthArguments{
 				Host:          "https://unified.cloud.databricks.com",
-				AccountID:     "123456789",
+				AccountID:     "01234567-89ab-cdef-0123-456789abcdef",
 				IsUnifiedHost: true,
 				Profile:       "my-unified-profile",
 			},
@@ -124,7 +124,7 @@ func TestToOAuthArgument(t *testing.T) {
 			name: "workspace_id none sentinel is stripped",
 			args: AuthArguments{
 				Host:          "https://unified.cloud.databricks.com",
-				AccountID:     "123456789",
+				AccountID:     "01234567-89ab-cdef-0123-456789abcdef",
 				WorkspaceID:   "none",
 				IsUnifiedHost: true,
 				Profile:       "my-profile",
@@ -132,6 +132,24 @@ func TestToOAuthArgument(t *testing.T) {
 			wantHost:     "https://unified.cloud.databricks.com",
 			wantCacheKey: "my-profile",
 		},
+		{
+			name: "account with org ID as account ID",
+			args: AuthArguments{
+				Host:      "https://accounts.cloud.databricks.com",
+				AccountID: "123456789",
+			},
+			wantError: true,
+		},
+		{
+			name: "unified host with malformed workspace ID",
+			args: AuthArguments{
+				Host:          "https://unified.cloud.databricks.com",
+				AccountID:     "01234567-89ab-cdef-0123-456789abcdef",
+				WorkspaceID:   "my-workspace",
+				IsUnifiedHost: true,
+			},
+			wantError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -168,9 +186,9 @@ func TestToOAuthArgument_SPOGHostRoutesToUnified(t *testing.T) {
 		if r.URL.Path == "/.well-known/databricks-config" {
 			w.Header().Set("Content-Type", "application/json")
 			if err := json.NewEncoder(w).Encode(map[string]any{
-				"account_id":    "spog-account",
+				"account_id":    "01234567-89ab-cdef-0123-456789abcdef",
 				"workspace_id":  "spog-ws",
-				"oidc_endpoint": r.Host + "/oidc/accounts/spog-account",
+				"oidc_endpoint": r.Host + "/oidc/accounts/01234567-89ab-cdef-0123-456789abcdef",
 			}); err != nil {
 				http.Error(w, err.Error(), http.StatusInternalServerError)
 				return
@@ -183,7 +201,7 @@ func TestToOAuthArgument_SPOGHostRoutesToUnified(t *testing.T) {
 
 	args := AuthArguments{
 		Host:      server.URL,
-		AccountID: "spog-account",
+		AccountID: "01234567-89ab-cdef-0123-456789abcdef",
 	}
 	got, err := args.ToOAuthArgument()
 	require.NoError(t, err)
@@ -215,7 +233,7 @@ func TestToOAuthArgument_ClassicWorkspaceNotMisrouted(t *testing.T) {
 	// should NOT be routed to unified OAuth.
 	args := AuthArguments{
 		Host:      server.URL,
-		AccountID: "some-account",
+		AccountID: "01234567-89ab-cdef-0123-456789abcdef",
 	}
 	got, err := args.ToOAuthArgument()
 	require.NoError(t, err)
@@ -255,3 +273,55 @@ func TestToOAuthArgument_NoAccountIDSkipsUnifiedRouting(t *testing.T) {
 	_, ok := got.(u2m.WorkspaceOAuthArgument)
 	assert.True(t, ok, "expected WorkspaceOAuthArgument when no caller AccountID, got %T", got)
 }
+
+func TestValidateAccountID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr string
+	}{
+		{name: "empty", id: ""},
+		{name: "uuid", id: "01234567-89ab-cdef-0123-456789abcdef"},
+		{name: "uppercase uuid", id: "01234567-89AB-CDEF-0123-456789ABCDEF"},
+		{name: "org ID", id: "1234567890123456", wantErr: `invalid account ID "1234567890123456": this looks like a workspace ID or org ID`},
+		{name: "uuid without dashes", id: "0123456789abcdef0123456789abcdef", wantErr: `invalid account ID "0123456789abcdef0123456789abcdef": expected a UUID`},
+		{name: "truncated uuid", id: "01234567-89ab-cdef-0123", wantErr: `invalid account ID "01234567-89ab-cdef-0123": expected a UUID`},
+		{name: "workspace host", id: "my-workspace.cloud.databricks.com", wantErr: `invalid account ID "my-workspace.cloud.databricks.com": expected a UUID`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAccountID(tt.id)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+			assert.ErrorContains(t, err, "The org ID in a workspace URL (?o=...) is the workspace ID, not the account ID.")
+		})
+	}
+}
+
+func TestValidateWorkspaceID(t *testing.T) {
+	tests := []struct {
+		name    string
+		id      string
+		wantErr string
+	}{
+		{name: "empty", id: ""},
+		{name: "numeric", id: "1234567890123456"},
+		{name: "none sentinel", id: WorkspaceIDNone},
+		{name: "account ID", id: "01234567-89ab-cdef-0123-456789abcdef", wantErr: `invalid workspace ID "01234567-89ab-cdef-0123-456789abcdef": this looks like an account ID`},
+		{name: "name", id: "my-workspace", wantErr: `invalid workspace ID "my-workspace": expected a number`},
+		{name: "negative", id: "-1", wantErr: `invalid workspace ID "-1": expected a number`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateWorkspaceID(tt.id)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
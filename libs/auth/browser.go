@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/exec"
+	browserpkg "github.com/pkg/browser"
+)
+
+// OpenURLSuppressingStderr opens a URL in the browser while suppressing stderr output.
+// This prevents xdg-open error messages from being displayed to the user.
+func OpenURLSuppressingStderr(url string) error {
+	// Save the original stderr from the browser package
+	originalStderr := browserpkg.Stderr
+	defer func() {
+		browserpkg.Stderr = originalStderr
+	}()
+
+	// Redirect stderr to discard to suppress xdg-open errors
+	browserpkg.Stderr = io.Discard
+
+	// Call the browser open function
+	return browserpkg.OpenURL(url)
+}
+
+// BrowserFunc returns a function that opens the given URL in the browser.
+// It respects the BROWSER environment variable:
+// - empty string: uses the default browser
+// - "none": prints the URL to stdout without opening a browser
+// - custom command: executes the specified command with the URL as argument
+func BrowserFunc(ctx context.Context) func(url string) error {
+	browser := env.Get(ctx, "BROWSER")
+	switch browser {
+	case "":
+		return OpenURLSuppressingStderr
+	case "none":
+		return func(url string) error {
+			cmdio.LogString(ctx, "Please complete authentication by opening this link in your browser:\n"+url)
+			return nil
+		}
+	default:
+		return func(url string) error {
+			// Run the browser command via a shell.
+			// It can be a script or a binary and scripts cannot be executed directly on Windows.
+			e, err := exec.NewCommandExecutor(".")
+			if err != nil {
+				return err
+			}
+
+			e.WithInheritOutput()
+			cmd, err := e.StartCommand(ctx, fmt.Sprintf("%q %q", browser, url))
+			if err != nil {
+				return err
+			}
+
+			return cmd.Wait()
+		}
+	}
+}
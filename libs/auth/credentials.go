@@ -3,6 +3,10 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
 
 	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/databricks/databricks-sdk-go/config/credentials"
@@ -44,14 +48,34 @@ var credentialChain = []config.CredentialsStrategy{
 func init() {
 	// Sets the credentials chain for the CLI.
 	config.DefaultCredentialStrategyProvider = func() config.CredentialsStrategy {
-		return &defaultCredentials{chain: config.NewCredentialsChain(credentialChain...)}
+		return newDefaultCredentials(credentialChain)
 	}
 }
 
 // defaultCredentials wraps the CLI credential chain and provides "default"
 // as the fallback name, matching the SDK's DefaultCredentials behavior.
+//
+// The strategy is selected by the SDK's credentials chain, which only tries
+// the strategy named by auth_type or DATABRICKS_AUTH_TYPE if it is set. The
+// errors of the chain are annotated with how to fix them.
 type defaultCredentials struct {
-	chain config.CredentialsStrategy
+	strategies []config.CredentialsStrategy
+	chain      config.CredentialsStrategy
+	trace      *credentialsTrace
+}
+
+func newDefaultCredentials(strategies []config.CredentialsStrategy) *defaultCredentials {
+	// Every strategy records its evaluation for [CredentialsTrace].
+	trace := &credentialsTrace{}
+	traced := make([]config.CredentialsStrategy, len(strategies))
+	for i, s := range strategies {
+		traced[i] = &tracingStrategy{inner: s, trace: trace}
+	}
+	return &defaultCredentials{
+		strategies: traced,
+		chain:      config.NewCredentialsChain(traced...),
+		trace:      trace,
+	}
 }
 
 func (d *defaultCredentials) Name() string {
@@ -62,7 +86,40 @@ func (d *defaultCredentials) Name() string {
 }
 
 func (d *defaultCredentials) Configure(ctx context.Context, cfg *config.Config) (credentials.CredentialsProvider, error) {
-	return d.chain.Configure(ctx, cfg)
+	d.trace.reset()
+	err := cfg.EnsureResolved()
+	if err != nil {
+		// The SDK rejects credentials for multiple auth types without naming
+		// the profile or how to fix it.
+		if conflict := CheckConflictingCredentials(ctx, cfg); conflict != nil {
+			return nil, conflict
+		}
+		return nil, err
+	}
+	cp, err := d.chain.Configure(ctx, cfg)
+	if cfg.AuthType == "" {
+		return cp, err
+	}
+
+	// The chain only tries the strategy of the auth type, and returns its
+	// result as-is.
+	if err != nil {
+		known := slices.ContainsFunc(d.strategies, func(s config.CredentialsStrategy) bool {
+			return s.Name() == cfg.AuthType
+		})
+		if !known {
+			names := make([]string, len(d.strategies))
+			for i, s := range d.strategies {
+				names[i] = s.Name()
+			}
+			return nil, fmt.Errorf("%w; valid auth types are: %s", err, strings.Join(names, ", "))
+		}
+		return nil, fmt.Errorf("cannot use the auth type set by auth_type or DATABRICKS_AUTH_TYPE: %w", err)
+	}
+	if cp == nil {
+		return nil, errors.New("the auth type set by auth_type or DATABRICKS_AUTH_TYPE is not configured")
+	}
+	return cp, nil
 }
 
 // CLICredentials is a credentials strategy that reads OAuth tokens directly
@@ -74,6 +131,56 @@ type CLICredentials struct {
 	persistentAuthFn func(ctx context.Context, opts ...u2m.PersistentAuthOption) (auth.TokenSource, error)
 }
 
+// tokenSourceKey identifies the token source of an OAuth argument.
+type tokenSourceKey struct {
+	cacheKey     string
+	host         string
+	accountID    string
+	workspaceID  string
+	asyncRefresh bool
+}
+
+// tokenSourceCache reuses token sources across [CLICredentials.Configure]
+// calls. Commands that create clients repeatedly would otherwise read the
+// token cache and refresh the token for every client.
+//
+// Token sources outlive the call that creates them, so they are created with
+// a context that is not cancelled with the context of that call.
+type tokenSourceCache struct {
+	mu      sync.Mutex
+	sources map[tokenSourceKey]auth.TokenSource
+}
+
+func newTokenSourceCache() *tokenSourceCache {
+	return &tokenSourceCache{sources: map[tokenSourceKey]auth.TokenSource{}}
+}
+
+// cliTokenSources is shared by all [CLICredentials] in the process.
+var cliTokenSources = newTokenSourceCache()
+
+// get returns the token source for key, creating it with fn if there is none.
+// Errors of fn are not cached.
+func (c *tokenSourceCache) get(key tokenSourceKey, fn func() (auth.TokenSource, error)) (auth.TokenSource, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ts, ok := c.sources[key]; ok {
+		return ts, nil
+	}
+	ts, err := fn()
+	if err != nil {
+		return nil, err
+	}
+	c.sources[key] = ts
+	return ts, nil
+}
+
+// reset drops all token sources.
+func (c *tokenSourceCache) reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.sources)
+}
+
 // Name implements [config.CredentialsStrategy].
 func (c CLICredentials) Name() string {
 	return "databricks-cli"
@@ -97,14 +204,24 @@ func (c CLICredentials) Configure(ctx context.Context, cfg *config.Config) (cred
 	if err != nil {
 		return nil, err
 	}
-	ts, err := c.persistentAuth(ctx, u2m.WithOAuthArgument(oauthArg))
+	key := tokenSourceKey{
+		cacheKey:     oauthArg.GetCacheKey(),
+		host:         cfg.CanonicalHostName(),
+		accountID:    cfg.AccountID,
+		workspaceID:  cfg.WorkspaceID,
+		asyncRefresh: !cfg.DisableOAuthRefreshToken,
+	}
+	cts, err := cliTokenSources.get(key, func() (auth.TokenSource, error) {
+		ts, err := c.persistentAuth(context.WithoutCancel(ctx), u2m.WithOAuthArgument(oauthArg))
+		if err != nil {
+			return nil, err
+		}
+		return auth.NewCachedTokenSource(ts, auth.WithAsyncRefresh(key.asyncRefresh)), nil
+	})
 	if err != nil {
 		return nil, err
 	}
-	cp := credentials.NewOAuthCredentialsProviderFromTokenSource(
-		auth.NewCachedTokenSource(ts, auth.WithAsyncRefresh(!cfg.DisableOAuthRefreshToken)),
-	)
-	return cp, nil
+	return credentials.NewOAuthCredentialsProviderFromTokenSource(cts), nil
 }
 
 // persistentAuth returns a token source. It is a convenience function that
@@ -114,6 +231,14 @@ func (c CLICredentials) persistentAuth(ctx context.Context, opts ...u2m.Persiste
 	if c.persistentAuthFn != nil {
 		return c.persistentAuthFn(ctx, opts...)
 	}
+	// Retry transient failures of the token endpoint, record when tokens
+	// are refreshed and skip hosts whose OAuth endpoints recently could not
+	// be discovered. Options passed by the caller take precedence.
+	opts = append([]u2m.PersistentAuthOption{
+		u2m.WithHttpClient(NewRefreshHTTPClient()),
+		WithTokenTimes(ctx, TokenRefresh),
+		WithDiscoveryCache(ctx),
+	}, opts...)
 	ts, err := u2m.NewPersistentAuth(ctx, opts...)
 	if err != nil {
 		return nil, err
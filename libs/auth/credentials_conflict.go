@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// CredentialSet is a set of attributes that configures an auth type.
+type CredentialSet struct {
+	AuthType string
+	Keys     []string
+}
+
+// ConflictingCredentialsError is returned if a configuration without an auth
+// type has credentials for multiple auth types. Such profiles are typically
+// left behind by switching between auth methods.
+type ConflictingCredentialsError struct {
+	Profile    string
+	ConfigFile string
+	Sets       []CredentialSet
+}
+
+func (e *ConflictingCredentialsError) Error() string {
+	sets := make([]string, len(e.Sets))
+	for i, s := range e.Sets {
+		sets[i] = fmt.Sprintf("%s (%s)", strings.Join(s.Keys, " and "), s.AuthType)
+	}
+
+	var b strings.Builder
+	if e.Profile != "" {
+		fmt.Fprintf(&b, "profile %q has credentials for multiple auth types: %s", e.Profile, strings.Join(sets, ", "))
+		fmt.Fprintf(&b, "\n\nRun `databricks configure --profile %s` to replace them with a token, or remove the keys you no longer use from %s.", e.Profile, e.ConfigFile)
+	} else {
+		fmt.Fprintf(&b, "the configuration has credentials for multiple auth types: %s", strings.Join(sets, ", "))
+		fmt.Fprint(&b, "\n\nRemove the credentials you no longer use from your environment or config file.")
+	}
+	fmt.Fprint(&b, " Alternatively, set auth_type to choose one.")
+	return b.String()
+}
+
+// CheckConflictingCredentials returns a [*ConflictingCredentialsError] if cfg
+// has credentials for multiple mutually exclusive auth types and no auth type
+// selects one of them. The attributes of cfg must have been loaded. The check
+// is disabled by DATABRICKS_ALLOW_MULTIPLE_AUTH, with which
+// [databrickscfg.AllowMultipleAuth] selects the auth type.
+func CheckConflictingCredentials(ctx context.Context, cfg *config.Config) error {
+	if allowMultipleAuth(ctx) {
+		return nil
+	}
+	sets := conflictingCredentialSets(cfg)
+	if sets == nil {
+		return nil
+	}
+
+	configFile := cfg.ConfigFile
+	if configFile == "" {
+		configFile = "~/.databrickscfg"
+	}
+	return &ConflictingCredentialsError{
+		Profile:    cfg.Profile,
+		ConfigFile: configFile,
+		Sets:       sets,
+	}
+}
+
+// conflictingCredentialSets returns the credential sets of cfg if it has
+// credentials for multiple mutually exclusive auth types and no auth type
+// selects one of them. It returns nil otherwise.
+func conflictingCredentialSets(cfg *config.Config) []CredentialSet {
+	if cfg.AuthType != "" {
+		return nil
+	}
+
+	authTypes := databrickscfg.ConfiguredExclusiveAuthTypes(cfg)
+	if len(authTypes) < 2 {
+		return nil
+	}
+	sets := make([]CredentialSet, len(authTypes))
+	for i, authType := range authTypes {
+		sets[i] = CredentialSet{AuthType: authType, Keys: databrickscfg.CredentialKeysFor(authType)}
+	}
+	return sets
+}
+
+func allowMultipleAuth(ctx context.Context) bool {
+	allow, _ := env.GetBool(ctx, databrickscfg.AllowMultipleAuthEnv)
+	return allow
+}
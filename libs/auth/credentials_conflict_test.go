@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckConflictingCredentials(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      *config.Config
+		allowEnv string
+		wantSets []CredentialSet
+	}{
+		{
+			name: "pat and m2m",
+			cfg:  &config.Config{Profile: "dev", Token: "dapi123", ClientID: "id", ClientSecret: "secret"},
+			wantSets: []CredentialSet{
+				{AuthType: "pat", Keys: []string{"token"}},
+				{AuthType: "oauth-m2m", Keys: []string{"client_id", "client_secret"}},
+			},
+		},
+		{
+			name: "pat and basic",
+			cfg:  &config.Config{Profile: "dev", Token: "dapi123", Username: "user", Password: "password"},
+			wantSets: []CredentialSet{
+				{AuthType: "pat", Keys: []string{"token"}},
+				{AuthType: "basic", Keys: []string{"username", "password"}},
+			},
+		},
+		{
+			name: "pat only",
+			cfg:  &config.Config{Profile: "dev", Token: "dapi123"},
+		},
+		{
+			name: "pat and incomplete m2m",
+			cfg:  &config.Config{Profile: "dev", Token: "dapi123", ClientID: "id"},
+		},
+		{
+			name: "auth type selects one",
+			cfg:  &config.Config{Profile: "dev", Token: "dapi123", ClientID: "id", ClientSecret: "secret", AuthType: "oauth-m2m"},
+		},
+		{
+			name:     "multiple auth allowed",
+			cfg:      &config.Config{Profile: "dev", Token: "dapi123", ClientID: "id", ClientSecret: "secret"},
+			allowEnv: "1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			if tt.allowEnv != "" {
+				ctx = env.Set(ctx, databrickscfg.AllowMultipleAuthEnv, tt.allowEnv)
+			}
+			err := CheckConflictingCredentials(ctx, tt.cfg)
+			if tt.wantSets == nil {
+				assert.NoError(t, err)
+				return
+			}
+			var conflict *ConflictingCredentialsError
+			require.ErrorAs(t, err, &conflict)
+			assert.Equal(t, tt.wantSets, conflict.Sets)
+			assert.Equal(t, "dev", conflict.Profile)
+		})
+	}
+}
+
+func TestConflictingCredentialsError(t *testing.T) {
+	err := &ConflictingCredentialsError{
+		Profile:    "dev",
+		ConfigFile: "/home/user/.databrickscfg",
+		Sets: []CredentialSet{
+			{AuthType: "pat", Keys: []string{"token"}},
+			{AuthType: "oauth-m2m", Keys: []string{"client_id", "client_secret"}},
+		},
+	}
+	assert.Equal(t, `profile "dev" has credentials for multiple auth types: token (pat), client_id and client_secret (oauth-m2m)
+
+Run `+"`databricks configure --profile dev`"+` to replace them with a token, or remove the keys you no longer use from /home/user/.databrickscfg. Alternatively, set auth_type to choose one.`, err.Error())
+
+	err.Profile = ""
+	assert.Contains(t, err.Error(), "the configuration has credentials for multiple auth types")
+}
+
+func TestDefaultCredentialsConflictingCredentials(t *testing.T) {
+	pat := &fakeStrategy{name: "pat", token: "pat-token"}
+	m2m := &fakeStrategy{name: "oauth-m2m", token: "m2m-token"}
+	d := newDefaultCredentials([]config.CredentialsStrategy{pat, m2m})
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Profile:      "dev",
+			Token:        "dapi123",
+			ClientID:     "id",
+			ClientSecret: "secret",
+			Loaders:      []config.Loader{databrickscfg.AllowMultipleAuth},
+		}
+	}
+
+	_, err := d.Configure(t.Context(), newConfig())
+	var conflict *ConflictingCredentialsError
+	require.ErrorAs(t, err, &conflict)
+	assert.False(t, pat.configured)
+
+	// With the check disabled, the loader selects the first auth type of the
+	// chain before the SDK validates the config.
+	t.Setenv(databrickscfg.AllowMultipleAuthEnv, "1")
+	cfg := newConfig()
+	_, err = d.Configure(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "pat", cfg.AuthType)
+	assert.True(t, pat.configured)
+	assert.False(t, m2m.configured)
+}
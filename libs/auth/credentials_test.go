@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/config/credentials"
 	"github.com/databricks/databricks-sdk-go/config/experimental/auth"
 	"github.com/databricks/databricks-sdk-go/credentials/u2m"
 	"golang.org/x/oauth2"
@@ -40,6 +41,15 @@ func TestCredentialChainOrder(t *testing.T) {
 	if !slices.Equal(names, want) {
 		t.Errorf("credential chain order: want %v, got %v", want, names)
 	}
+
+	// The tracing decorator must not change the order.
+	d := newDefaultCredentials(credentialChain)
+	for i, s := range d.strategies {
+		names[i] = s.Name()
+	}
+	if !slices.Equal(names, want) {
+		t.Errorf("decorated credential chain order: want %v, got %v", want, names)
+	}
 }
 
 func TestCLICredentialsName(t *testing.T) {
@@ -150,7 +160,7 @@ func TestCLICredentialsConfigure(t *testing.T) {
 			name: "account host",
 			cfg: &config.Config{
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "test-account-id",
+				AccountID: "01234567-89ab-cdef-0123-456789abcdef",
 			},
 			persistentAuthFn: func(_ context.Context, _ ...u2m.PersistentAuthOption) (auth.TokenSource, error) {
 				return auth.TokenSourceFn(func(_ context.Context) (*oauth2.Token, error) {
@@ -164,6 +174,7 @@ func TestCLICredentialsConfigure(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ctx := t.Context()
+			resetTokenSources(t)
 			c := CLICredentials{persistentAuthFn: tt.persistentAuthFn}
 
 			got, err := c.Configure(ctx, tt.cfg)
@@ -190,3 +201,246 @@ func TestCLICredentialsConfigure(t *testing.T) {
 		})
 	}
 }
+
+// resetTokenSources drops the token sources shared by all [CLICredentials]
+// before and after the test.
+func resetTokenSources(t *testing.T) {
+	cliTokenSources.reset()
+	t.Cleanup(cliTokenSources.reset)
+}
+
+func TestCLICredentialsConfigureReusesTokenSource(t *testing.T) {
+	resetTokenSources(t)
+	calls := 0
+	c := CLICredentials{
+		persistentAuthFn: func(_ context.Context, _ ...u2m.PersistentAuthOption) (auth.TokenSource, error) {
+			calls++
+			return auth.TokenSourceFn(func(_ context.Context) (*oauth2.Token, error) {
+				return &oauth2.Token{AccessToken: "token"}, nil
+			}), nil
+		},
+	}
+	newConfig := func(host string) *config.Config {
+		// A discovery URL avoids host metadata resolution over the network.
+		return &config.Config{Host: host, DiscoveryURL: host + "/oidc/.well-known/oauth-authorization-server"}
+	}
+
+	for _, host := range []string{
+		"https://myworkspace.cloud.databricks.com",
+		"https://myworkspace.cloud.databricks.com",
+		// Other hosts have their own token source.
+		"https://other.cloud.databricks.com",
+	} {
+		if _, err := c.Configure(t.Context(), newConfig(host)); err != nil {
+			t.Fatalf("Configure(%s): want no error, got %v", host, err)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("persistentAuthFn calls: want 2, got %d", calls)
+	}
+}
+
+func TestCLICredentialsConfigureOutlivesContext(t *testing.T) {
+	resetTokenSources(t)
+	var authCtx context.Context
+	c := CLICredentials{
+		persistentAuthFn: func(ctx context.Context, _ ...u2m.PersistentAuthOption) (auth.TokenSource, error) {
+			authCtx = ctx
+			return auth.TokenSourceFn(func(_ context.Context) (*oauth2.Token, error) {
+				return &oauth2.Token{AccessToken: "token"}, nil
+			}), nil
+		},
+	}
+	host := "https://myworkspace.cloud.databricks.com"
+	cfg := &config.Config{Host: host, DiscoveryURL: host + "/oidc/.well-known/oauth-authorization-server"}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	_, err := c.Configure(ctx, cfg)
+	cancel()
+	if err != nil {
+		t.Fatalf("Configure: want no error, got %v", err)
+	}
+
+	// The shared token source must keep working for later callers.
+	if err := authCtx.Err(); err != nil {
+		t.Errorf("token source context: want not cancelled, got %v", err)
+	}
+}
+
+// fakeStrategy is a credentials strategy that returns a fixed result and
+// records whether it was configured.
+type fakeStrategy struct {
+	name       string
+	err        error
+	token      string
+	headersErr error
+	configured bool
+}
+
+func (f *fakeStrategy) Name() string {
+	return f.name
+}
+
+func (f *fakeStrategy) Configure(context.Context, *config.Config) (credentials.CredentialsProvider, error) {
+	f.configured = true
+	if f.err != nil || f.token == "" {
+		return nil, f.err
+	}
+	return credentials.CredentialsProviderFn(func(r *http.Request) error {
+		if f.headersErr != nil {
+			return f.headersErr
+		}
+		r.Header.Set("Authorization", "Bearer "+f.token)
+		return nil
+	}), nil
+}
+
+// noopLoader keeps tests from loading the environment and config file.
+type noopLoader struct{}
+
+func (noopLoader) Name() string                   { return "noop" }
+func (noopLoader) Configure(*config.Config) error { return nil }
+
+func TestDefaultCredentialsAuthType(t *testing.T) {
+	testErr := errors.New("az login required")
+
+	tests := []struct {
+		name      string
+		authType  string
+		azureErr  error
+		wantName  string
+		wantToken string
+		wantErr   string
+		wantPat   bool
+	}{
+		{
+			name:      "no auth type uses chain order",
+			wantName:  "pat",
+			wantToken: "pat-token",
+			wantPat:   true,
+		},
+		{
+			name:      "forced auth type skips earlier strategies",
+			authType:  "azure-cli",
+			wantName:  "azure-cli",
+			wantToken: "azure-token",
+		},
+		{
+			name:     "forced auth type fails",
+			authType: "azure-cli",
+			azureErr: testErr,
+			wantName: "azure-cli",
+			wantErr:  "cannot use the auth type set by auth_type or DATABRICKS_AUTH_TYPE: az login required",
+		},
+		{
+			name:     "unknown auth type",
+			authType: "kerberos",
+			wantName: "default",
+			wantErr:  `auth type "kerberos" not found, please check https://docs.databricks.com/en/dev-tools/auth.html#databricks-client-unified-authentication for a list of supported auth types; valid auth types are: pat, azure-cli`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pat := &fakeStrategy{name: "pat", token: "pat-token"}
+			azure := &fakeStrategy{name: "azure-cli", token: "azure-token", err: tt.azureErr}
+			d := newDefaultCredentials([]config.CredentialsStrategy{pat, azure})
+			cfg := &config.Config{AuthType: tt.authType, Loaders: []config.Loader{noopLoader{}}}
+
+			cp, err := d.Configure(t.Context(), cfg)
+
+			if got := d.Name(); got != tt.wantName {
+				t.Errorf("Name(): want %q, got %q", tt.wantName, got)
+			}
+			if pat.configured != tt.wantPat {
+				t.Errorf("pat configured: want %v, got %v", tt.wantPat, pat.configured)
+			}
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Fatalf("want error %q, got %v", tt.wantErr, err)
+				}
+				if tt.azureErr != nil && !errors.Is(err, tt.azureErr) {
+					t.Errorf("want error wrapping %v, got %v", tt.azureErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Configure: want no error, got %v", err)
+			}
+
+			req := &http.Request{Header: http.Header{}}
+			if err := cp.SetHeaders(req); err != nil {
+				t.Fatalf("SetHeaders: want no error, got %v", err)
+			}
+			want := "Bearer " + tt.wantToken
+			if got := req.Header.Get("Authorization"); got != want {
+				t.Errorf("Authorization header: want %q, got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestDefaultCredentialsAuthTypeNotConfigured(t *testing.T) {
+	pat := &fakeStrategy{name: "pat"}
+	d := newDefaultCredentials([]config.CredentialsStrategy{pat})
+	cfg := &config.Config{AuthType: "pat", Loaders: []config.Loader{noopLoader{}}}
+
+	_, err := d.Configure(t.Context(), cfg)
+
+	want := "the auth type set by auth_type or DATABRICKS_AUTH_TYPE is not configured"
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+}
+
+func TestCredentialsTrace(t *testing.T) {
+	testErr := errors.New("basic failed")
+	pat := &fakeStrategy{name: "pat"}
+	basic := &fakeStrategy{name: "basic", err: testErr}
+	cli := &fakeStrategy{name: "databricks-cli", token: "cli-token", headersErr: errors.New("no cached token")}
+	azure := &fakeStrategy{name: "azure-cli", token: "azure-token"}
+	google := &fakeStrategy{name: "google-id", token: "google-token"}
+	d := newDefaultCredentials([]config.CredentialsStrategy{pat, basic, cli, azure, google})
+	cfg := &config.Config{
+		Credentials: d,
+		Loaders:     []config.Loader{noopLoader{}},
+	}
+
+	_, err := d.Configure(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("Configure: want no error, got %v", err)
+	}
+	cfg.AuthType = d.Name()
+
+	got := CredentialsTrace(cfg)
+	want := []StrategyEvaluation{
+		{Name: "pat", Outcome: StrategySkipped, Detail: "missing host, token"},
+		{Name: "basic", Outcome: StrategyFailed, Detail: "basic failed"},
+		{Name: "databricks-cli", Outcome: StrategyRejected, Detail: "credentials could not be used"},
+		{Name: "azure-cli", Outcome: StrategyConfigured, Selected: true},
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("CredentialsTrace: want %v, got %v", want, got)
+	}
+	if google.configured {
+		t.Error("strategies after the selected one must not be evaluated")
+	}
+
+	// Every attempt starts a new trace.
+	cfg.AuthType = ""
+	_, err = d.Configure(t.Context(), cfg)
+	if err != nil {
+		t.Fatalf("Configure: want no error, got %v", err)
+	}
+	cfg.AuthType = d.Name()
+	if got := CredentialsTrace(cfg); !slices.Equal(got, want) {
+		t.Errorf("CredentialsTrace after second attempt: want %v, got %v", want, got)
+	}
+}
+
+func TestCredentialsTraceOtherCredentials(t *testing.T) {
+	cfg := &config.Config{Credentials: config.PatCredentials{}}
+	if got := CredentialsTrace(cfg); got != nil {
+		t.Errorf("CredentialsTrace: want nil, got %v", got)
+	}
+}
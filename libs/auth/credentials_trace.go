@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/config/credentials"
+)
+
+// Outcomes of evaluating a credentials strategy.
+const (
+	StrategySkipped    = "skipped"
+	StrategyFailed     = "failed"
+	StrategyConfigured = "configured"
+
+	// StrategyRejected is a strategy that was configured, but whose
+	// credentials failed validation by the credential chain.
+	StrategyRejected = "rejected"
+)
+
+// StrategyEvaluation records the result of evaluating one strategy of the
+// credential chain.
+type StrategyEvaluation struct {
+	Name    string `json:"name"`
+	Outcome string `json:"outcome"`
+
+	// Detail is the error of a failed strategy or the missing configuration
+	// of a skipped strategy.
+	Detail string `json:"detail,omitempty"`
+
+	// Selected is true for the strategy that is used for authentication.
+	Selected bool `json:"selected,omitempty"`
+}
+
+// strategyFields lists the configuration attributes that a strategy needs.
+// They are reported as missing when the strategy is skipped.
+var strategyFields = func() map[string][]string {
+	fields := map[string][]string{
+		// Some of the credential keys of these auth types are optional.
+		"databricks-cli":      {"host"},
+		"azure-msi":           {"azure_use_msi"},
+		"azure-client-secret": {"azure_client_id", "azure_client_secret", "azure_tenant_id"},
+	}
+	for _, name := range []string{"pat", "basic", "oauth-m2m", "metadata-service", "file-oidc", "google-credentials", "google-id"} {
+		fields[name] = append([]string{"host"}, databrickscfg.CredentialKeysFor(name)...)
+	}
+	return fields
+}()
+
+// credentialsTrace collects the evaluations of the strategies of a chain in
+// the order in which they are evaluated.
+type credentialsTrace struct {
+	mu          sync.Mutex
+	evaluations []StrategyEvaluation
+}
+
+func (t *credentialsTrace) add(e StrategyEvaluation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.evaluations = append(t.evaluations, e)
+}
+
+func (t *credentialsTrace) reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.evaluations = nil
+}
+
+func (t *credentialsTrace) list() []StrategyEvaluation {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]StrategyEvaluation(nil), t.evaluations...)
+}
+
+// tracingStrategy decorates a credentials strategy to record and log the
+// result of each evaluation. It does not change the result.
+type tracingStrategy struct {
+	inner config.CredentialsStrategy
+	trace *credentialsTrace
+}
+
+func (s *tracingStrategy) Name() string {
+	return s.inner.Name()
+}
+
+func (s *tracingStrategy) Configure(ctx context.Context, cfg *config.Config) (credentials.CredentialsProvider, error) {
+	cp, err := s.inner.Configure(ctx, cfg)
+
+	e := StrategyEvaluation{Name: s.inner.Name()}
+	switch {
+	case err != nil:
+		e.Outcome = StrategyFailed
+		e.Detail = err.Error()
+	case cp == nil:
+		e.Outcome = StrategySkipped
+		if missing := missingFields(cfg, strategyFields[e.Name]); len(missing) > 0 {
+			e.Detail = "missing " + strings.Join(missing, ", ")
+		}
+	default:
+		e.Outcome = StrategyConfigured
+	}
+	s.trace.add(e)
+
+	msg := fmt.Sprintf("Credentials strategy %q: %s", e.Name, e.Outcome)
+	if e.Detail != "" {
+		msg += " (" + e.Detail + ")"
+	}
+	log.Debugf(ctx, "%s", msg)
+
+	return cp, err
+}
+
+// missingFields returns the names of the attributes in names that are not set
+// in cfg.
+func missingFields(cfg *config.Config, names []string) []string {
+	var missing []string
+	for _, name := range names {
+		for _, attr := range config.ConfigAttributes {
+			if attr.Name == name && attr.IsZero(cfg) {
+				missing = append(missing, name)
+			}
+		}
+	}
+	return missing
+}
+
+// CredentialsTrace returns the strategies of the CLI credential chain that were
+// evaluated by the last attempt to authenticate cfg, in order. It returns nil
+// if cfg was not authenticated with the CLI credential chain.
+func CredentialsTrace(cfg *config.Config) []StrategyEvaluation {
+	d, ok := cfg.Credentials.(*defaultCredentials)
+	if !ok {
+		return nil
+	}
+	evaluations := d.trace.list()
+	for i := range evaluations {
+		e := &evaluations[i]
+		if e.Outcome != StrategyConfigured {
+			continue
+		}
+		// The chain validates the credentials of a configured strategy and
+		// moves on to the next strategy if they cannot be used.
+		if e.Name == cfg.AuthType {
+			e.Selected = true
+		} else {
+			e.Outcome = StrategyRejected
+			e.Detail = "credentials could not be used"
+		}
+	}
+	return evaluations
+}
@@ -0,0 +1,250 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
+	"github.com/databricks/databricks-sdk-go/httpclient"
+)
+
+// discoveryCacheFilePath is the location of the discovery cache relative to
+// the home directory.
+const discoveryCacheFilePath = ".databricks/discovery-cache.json"
+
+const discoveryCacheVersion = 1
+
+// discoveryFailureTTL is how long a failed endpoint discovery is remembered.
+// It is short, so that it only spares rapid successive invocations from
+// waiting for the same failure.
+const discoveryFailureTTL = 5 * time.Minute
+
+// DiscoveryFailure records that the OAuth endpoints of a host could not be
+// discovered.
+type DiscoveryFailure struct {
+	// NotSupported is true if the host does not support Databricks OAuth.
+	NotSupported bool `json:"not_supported,omitempty"`
+
+	// Error is the error of the failed discovery.
+	Error string `json:"error"`
+
+	Time time.Time `json:"time"`
+}
+
+// DiscoveryCache remembers hosts whose OAuth endpoints could not be
+// discovered, in memory and in a file, so that other credentials strategies
+// are tried without waiting for the discovery to fail again.
+type DiscoveryCache struct {
+	store *jsonStore[*DiscoveryFailure]
+	now   func() time.Time
+
+	mu     sync.Mutex
+	memory map[string]*DiscoveryFailure
+}
+
+// NewDiscoveryCache returns a cache backed by the file at path.
+func NewDiscoveryCache(path string) *DiscoveryCache {
+	return &DiscoveryCache{
+		store:  newJSONStore[*DiscoveryFailure](path, discoveryCacheVersion, "failures"),
+		now:    time.Now,
+		memory: map[string]*DiscoveryFailure{},
+	}
+}
+
+var defaultDiscoveryCache = sync.OnceValues(func() (*DiscoveryCache, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed loading home directory: %w", err)
+	}
+	return NewDiscoveryCache(filepath.Join(home, discoveryCacheFilePath)), nil
+})
+
+// DefaultDiscoveryCache returns the cache next to the default token cache. It
+// is shared by all callers in the process.
+func DefaultDiscoveryCache() (*DiscoveryCache, error) {
+	return defaultDiscoveryCache()
+}
+
+// discoveryCacheKey returns the canonical form of host.
+func discoveryCacheKey(host string) string {
+	return (&config.Config{Host: host}).CanonicalHostName()
+}
+
+// Lookup returns the failure recorded for host if it has not expired.
+func (c *DiscoveryCache) Lookup(host string) (*DiscoveryFailure, error) {
+	key := discoveryCacheKey(host)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	f, ok := c.memory[key]
+	if !ok {
+		failures, err := c.store.load()
+		if err != nil {
+			return nil, err
+		}
+		f = failures[key]
+	}
+	if f == nil || c.now().Sub(f.Time) > discoveryFailureTTL {
+		return nil, nil
+	}
+	c.memory[key] = f
+	return f, nil
+}
+
+// Record remembers that the discovery for host failed with err.
+func (c *DiscoveryCache) Record(host string, err error) error {
+	key := discoveryCacheKey(host)
+	f := &DiscoveryFailure{
+		NotSupported: errors.Is(err, u2m.ErrOAuthNotSupported),
+		Error:        err.Error(),
+		Time:         c.now(),
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory[key] = f
+	return c.update(func(failures map[string]*DiscoveryFailure) {
+		failures[key] = f
+	})
+}
+
+// Forget removes the failure recorded for host.
+func (c *DiscoveryCache) Forget(host string) error {
+	key := discoveryCacheKey(host)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.memory, key)
+	failures, err := c.store.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := failures[key]; !ok {
+		return nil
+	}
+	return c.update(func(failures map[string]*DiscoveryFailure) {
+		delete(failures, key)
+	})
+}
+
+// Clear removes all recorded failures.
+func (c *DiscoveryCache) Clear() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.memory = map[string]*DiscoveryFailure{}
+	return c.store.remove()
+}
+
+// update applies fn to the failures of the file and writes the result without
+// expired entries.
+func (c *DiscoveryCache) update(fn func(map[string]*DiscoveryFailure)) error {
+	return c.store.update(func(failures map[string]*DiscoveryFailure) bool {
+		fn(failures)
+		for key, f := range failures {
+			if f == nil || c.now().Sub(f.Time) > discoveryFailureTTL {
+				delete(failures, key)
+			}
+		}
+		return true
+	})
+}
+
+// cachingEndpointSupplier consults a [DiscoveryCache] before discovering the
+// OAuth endpoints of a host and records failed discoveries in it.
+type cachingEndpointSupplier struct {
+	ctx   context.Context
+	inner u2m.OAuthEndpointSupplier
+
+	// openCache returns the cache. It is opened when first used.
+	openCache func() (*DiscoveryCache, error)
+}
+
+// WithDiscoveryCache returns a persistent auth option that remembers failed
+// OAuth endpoint discoveries in the default [DiscoveryCache].
+func WithDiscoveryCache(ctx context.Context) u2m.PersistentAuthOption {
+	return u2m.WithOAuthEndpointSupplier(&cachingEndpointSupplier{
+		ctx:       ctx,
+		inner:     &u2m.BasicOAuthEndpointSupplier{Client: httpclient.NewApiClient(httpclient.ClientConfig{})},
+		openCache: DefaultDiscoveryCache,
+	})
+}
+
+// GetWorkspaceOAuthEndpoints implements [u2m.OAuthEndpointSupplier].
+func (s *cachingEndpointSupplier) GetWorkspaceOAuthEndpoints(ctx context.Context, workspaceHost string) (*u2m.OAuthAuthorizationServer, error) {
+	return s.discover(workspaceHost, func() (*u2m.OAuthAuthorizationServer, error) {
+		return s.inner.GetWorkspaceOAuthEndpoints(ctx, workspaceHost)
+	})
+}
+
+// GetAccountOAuthEndpoints implements [u2m.OAuthEndpointSupplier]. The
+// account endpoints are derived from the host without a request.
+func (s *cachingEndpointSupplier) GetAccountOAuthEndpoints(ctx context.Context, accountHost, accountId string) (*u2m.OAuthAuthorizationServer, error) {
+	return s.inner.GetAccountOAuthEndpoints(ctx, accountHost, accountId)
+}
+
+// GetUnifiedOAuthEndpoints implements [u2m.OAuthEndpointSupplier].
+func (s *cachingEndpointSupplier) GetUnifiedOAuthEndpoints(ctx context.Context, host, accountId string) (*u2m.OAuthAuthorizationServer, error) {
+	return s.discover(host, func() (*u2m.OAuthAuthorizationServer, error) {
+		return s.inner.GetUnifiedOAuthEndpoints(ctx, host, accountId)
+	})
+}
+
+// GetEndpointsFromURL implements [u2m.OAuthEndpointSupplier]. The failure is
+// recorded for the host of the URL, so that login clears it with the host.
+func (s *cachingEndpointSupplier) GetEndpointsFromURL(ctx context.Context, rawURL string) (*u2m.OAuthAuthorizationServer, error) {
+	return s.discover(discoveryCacheKey(rawURL), func() (*u2m.OAuthAuthorizationServer, error) {
+		return s.inner.GetEndpointsFromURL(ctx, rawURL)
+	})
+}
+
+func (s *cachingEndpointSupplier) discover(host string, fn func() (*u2m.OAuthAuthorizationServer, error)) (*u2m.OAuthAuthorizationServer, error) {
+	// The cache only saves time. If it cannot be used, discover the
+	// endpoints as usual.
+	c, err := s.openCache()
+	if err != nil {
+		log.Debugf(s.ctx, "Failed to open the discovery cache: %v", err)
+		return fn()
+	}
+
+	f, err := c.Lookup(host)
+	if err != nil {
+		log.Debugf(s.ctx, "Failed to read the discovery cache: %v", err)
+	}
+	if f != nil {
+		return nil, cachedDiscoveryError(host, f)
+	}
+
+	endpoints, err := fn()
+	if err != nil && isPermanentDiscoveryFailure(err) {
+		if recordErr := c.Record(host, err); recordErr != nil {
+			log.Debugf(s.ctx, "Failed to record the discovery failure for %s: %v", host, recordErr)
+		}
+	}
+	return endpoints, err
+}
+
+// isPermanentDiscoveryFailure reports whether err is not expected to change
+// within [discoveryFailureTTL]: the host does not support Databricks OAuth, or
+// its name does not exist. Other network errors, such as timeouts and reset
+// connections, can be transient and are not cached.
+func isPermanentDiscoveryFailure(err error) bool {
+	if errors.Is(err, u2m.ErrOAuthNotSupported) {
+		return true
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+func cachedDiscoveryError(host string, f *DiscoveryFailure) error {
+	const hint = "run `databricks auth cache clear-discovery` to retry"
+	if f.NotSupported {
+		return fmt.Errorf("%w (cached for %s; %s)", u2m.ErrOAuthNotSupported, host, hint)
+	}
+	return fmt.Errorf("OAuth endpoint discovery for %s failed recently: %s (%s)", host, f.Error, hint)
+}
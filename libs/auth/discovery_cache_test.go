@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryCache(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discovery-cache.json")
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	c := NewDiscoveryCache(path)
+	c.now = func() time.Time { return now }
+
+	f, err := c.Lookup("https://my-workspace.cloud.databricks.com")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	require.NoError(t, c.Record("https://my-workspace.cloud.databricks.com/", u2m.ErrOAuthNotSupported))
+	want := &DiscoveryFailure{NotSupported: true, Error: u2m.ErrOAuthNotSupported.Error(), Time: now}
+
+	f, err = c.Lookup("my-workspace.cloud.databricks.com")
+	require.NoError(t, err)
+	assert.Equal(t, want, f)
+
+	// Another process reads the failure from the file.
+	other := NewDiscoveryCache(path)
+	other.now = c.now
+	f, err = other.Lookup("https://my-workspace.cloud.databricks.com")
+	require.NoError(t, err)
+	assert.Equal(t, want, f)
+
+	// Failures expire.
+	now = now.Add(discoveryFailureTTL + time.Second)
+	f, err = other.Lookup("https://my-workspace.cloud.databricks.com")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+}
+
+func TestDiscoveryCacheForgetAndClear(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "discovery-cache.json")
+	c := NewDiscoveryCache(path)
+	require.NoError(t, c.Record("https://a.cloud.databricks.com", u2m.ErrOAuthNotSupported))
+	require.NoError(t, c.Record("https://b.cloud.databricks.com", u2m.ErrOAuthNotSupported))
+
+	require.NoError(t, c.Forget("https://a.cloud.databricks.com"))
+	f, err := NewDiscoveryCache(path).Lookup("https://a.cloud.databricks.com")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+	f, err = NewDiscoveryCache(path).Lookup("https://b.cloud.databricks.com")
+	require.NoError(t, err)
+	assert.NotNil(t, f)
+
+	require.NoError(t, c.Clear())
+	f, err = c.Lookup("https://b.cloud.databricks.com")
+	require.NoError(t, err)
+	assert.Nil(t, f)
+
+	// Clearing an empty cache succeeds.
+	require.NoError(t, c.Clear())
+}
+
+type countingEndpointSupplier struct {
+	u2m.OAuthEndpointSupplier
+	calls int
+	err   error
+}
+
+func (s *countingEndpointSupplier) GetEndpointsFromURL(ctx context.Context, rawURL string) (*u2m.OAuthAuthorizationServer, error) {
+	s.calls++
+	return nil, s.err
+}
+
+func (s *countingEndpointSupplier) GetWorkspaceOAuthEndpoints(ctx context.Context, workspaceHost string) (*u2m.OAuthAuthorizationServer, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &u2m.OAuthAuthorizationServer{TokenEndpoint: workspaceHost + "/token"}, nil
+}
+
+func TestCachingEndpointSupplier(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCalls int
+		wantErr   string
+	}{
+		{
+			name:      "OAuth not supported",
+			err:       u2m.ErrOAuthNotSupported,
+			wantCalls: 1,
+			wantErr:   "databricks OAuth is not supported for this host (cached for https://my-workspace.cloud.databricks.com; run `databricks auth cache clear-discovery` to retry)",
+		},
+		{
+			name:      "host not found",
+			err:       &net.OpError{Op: "dial", Net: "tcp", Err: &net.DNSError{Err: "no such host", Name: "my-workspace.cloud.databricks.com", IsNotFound: true}},
+			wantCalls: 1,
+			wantErr:   "OAuth endpoint discovery for https://my-workspace.cloud.databricks.com failed recently: dial tcp: lookup my-workspace.cloud.databricks.com: no such host (run `databricks auth cache clear-discovery` to retry)",
+		},
+		{
+			name:      "timeouts are not cached",
+			err:       &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("i/o timeout")},
+			wantCalls: 2,
+			wantErr:   "dial tcp: i/o timeout",
+		},
+		{
+			name:      "temporary DNS failures are not cached",
+			err:       &net.DNSError{Err: "server misbehaving", Name: "my-workspace.cloud.databricks.com", IsTemporary: true},
+			wantCalls: 2,
+			wantErr:   "lookup my-workspace.cloud.databricks.com: server misbehaving",
+		},
+		{
+			name:      "context deadline is not cached",
+			err:       context.DeadlineExceeded,
+			wantCalls: 2,
+			wantErr:   "context deadline exceeded",
+		},
+		{
+			name:      "other errors are not cached",
+			err:       errors.New("unexpected response"),
+			wantCalls: 2,
+			wantErr:   "unexpected response",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewDiscoveryCache(filepath.Join(t.TempDir(), "discovery-cache.json"))
+			inner := &countingEndpointSupplier{err: tt.err}
+			s := &cachingEndpointSupplier{
+				ctx:       t.Context(),
+				inner:     inner,
+				openCache: func() (*DiscoveryCache, error) { return c, nil },
+			}
+
+			_, err := s.GetWorkspaceOAuthEndpoints(t.Context(), "https://my-workspace.cloud.databricks.com")
+			assert.Equal(t, tt.err, err)
+
+			_, err = s.GetWorkspaceOAuthEndpoints(t.Context(), "https://my-workspace.cloud.databricks.com")
+			assert.EqualError(t, err, tt.wantErr)
+			assert.Equal(t, tt.wantCalls, inner.calls)
+		})
+	}
+}
+
+func TestCachingEndpointSupplierKeysURLByHost(t *testing.T) {
+	c := NewDiscoveryCache(filepath.Join(t.TempDir(), "discovery-cache.json"))
+	inner := &countingEndpointSupplier{err: u2m.ErrOAuthNotSupported}
+	s := &cachingEndpointSupplier{
+		ctx:       t.Context(),
+		inner:     inner,
+		openCache: func() (*DiscoveryCache, error) { return c, nil },
+	}
+
+	const discoveryURL = "https://my-workspace.cloud.databricks.com/oidc/.well-known/oauth-authorization-server"
+	_, err := s.GetEndpointsFromURL(t.Context(), discoveryURL)
+	require.ErrorIs(t, err, u2m.ErrOAuthNotSupported)
+
+	f, err := c.Lookup("https://my-workspace.cloud.databricks.com")
+	require.NoError(t, err)
+	require.NotNil(t, f)
+
+	// Login forgets the failure by host.
+	require.NoError(t, c.Forget("https://my-workspace.cloud.databricks.com"))
+	_, err = s.GetEndpointsFromURL(t.Context(), discoveryURL)
+	require.ErrorIs(t, err, u2m.ErrOAuthNotSupported)
+	assert.Equal(t, 2, inner.calls)
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// envCredentialAuthTypes lists the auth types whose credentials are checked
+// for conflicts with a profile, in the order of [credentialChain].
+var envCredentialAuthTypes = []string{AuthTypePat, AuthTypeBasic, AuthTypeOAuthM2M, AuthTypeMetadataService}
+
+// credentialEnvVars returns the environment variables that set the
+// [databrickscfg.CredentialKeysFor] of authType.
+func credentialEnvVars(authType string) []string {
+	var envVars []string
+	for _, key := range databrickscfg.CredentialKeysFor(authType) {
+		for _, attr := range config.ConfigAttributes {
+			if attr.Name == key {
+				envVars = append(envVars, attr.EnvVars...)
+			}
+		}
+	}
+	return envVars
+}
+
+// EnvConflictVariable is an environment variable that selects a different
+// auth type than the profile.
+type EnvConflictVariable struct {
+	Name     string
+	AuthType string
+}
+
+// EnvConflict describes environment variables that conflict with the auth
+// settings of the selected profile.
+type EnvConflict struct {
+	Profile         string
+	ProfileAuthType string
+
+	// EffectiveAuthType is the auth type that is used. It is empty if the
+	// combination is rejected because no auth type is set and credentials for
+	// more than one auth type are configured.
+	EffectiveAuthType string
+
+	Variables []EnvConflictVariable
+}
+
+func (c *EnvConflict) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "environment variables conflict with the auth settings of profile %q (auth type %s):\n", c.Profile, c.ProfileAuthType)
+	for _, v := range c.Variables {
+		state := "ignored"
+		if v.AuthType == c.EffectiveAuthType {
+			state = "takes effect"
+		}
+		fmt.Fprintf(&b, "  %s (%s): %s\n", v.Name, v.AuthType, state)
+	}
+	if c.EffectiveAuthType == "" {
+		b.WriteString("no auth type is set, so credentials for more than one auth type are rejected; set auth_type in the profile or DATABRICKS_AUTH_TYPE to choose one")
+	} else {
+		fmt.Fprintf(&b, "%s authentication takes effect", c.EffectiveAuthType)
+	}
+	return b.String()
+}
+
+// DetectEnvConflicts compares the credentials provided through environment
+// variables with the auth type of the profile selected by cfg or
+// DATABRICKS_CONFIG_PROFILE. It returns nil if no profile is selected, if the
+// profile does not determine an auth type, or if there is no conflict.
+//
+// The profile's auth type is its auth_type setting if present, and is
+// otherwise derived from the credentials it contains.
+func DetectEnvConflicts(ctx context.Context, cfg *config.Config) (*EnvConflict, error) {
+	profileName := cfg.Profile
+	if profileName == "" {
+		profileName = env.Get(ctx, "DATABRICKS_CONFIG_PROFILE")
+	}
+
+	// Without a selected profile the SDK does not load the config file when
+	// environment variables configure authentication, so nothing conflicts.
+	if profileName == "" {
+		return nil, nil
+	}
+
+	configFile := cfg.ConfigFile
+	if configFile == "" {
+		configFile = env.Get(ctx, "DATABRICKS_CONFIG_FILE")
+	}
+	profileCfg := &config.Config{Profile: profileName, ConfigFile: configFile}
+	err := databrickscfg.ConfigFile.Configure(profileCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	profileAuthType := profileCfg.AuthType
+	if profileAuthType == "" {
+		profileAuthType = impliedAuthType(profileCfg)
+	}
+	if profileAuthType == "" {
+		return nil, nil
+	}
+
+	var variables []EnvConflictVariable
+	for _, authType := range envCredentialAuthTypes {
+		if authType == profileAuthType {
+			continue
+		}
+		for _, name := range credentialEnvVars(authType) {
+			if env.Get(ctx, name) != "" {
+				variables = append(variables, EnvConflictVariable{Name: name, AuthType: authType})
+			}
+		}
+	}
+	envAuthType := env.Get(ctx, "DATABRICKS_AUTH_TYPE")
+	if envAuthType != "" && envAuthType != profileAuthType {
+		variables = append(variables, EnvConflictVariable{Name: "DATABRICKS_AUTH_TYPE", AuthType: envAuthType})
+	}
+	if len(variables) == 0 {
+		return nil, nil
+	}
+
+	// Environment variables take precedence over the config file. If an auth
+	// type is set, only the strategy with that name is tried. Otherwise the
+	// SDK rejects credentials for more than one auth type.
+	var effective string
+	switch {
+	case envAuthType != "":
+		effective = envAuthType
+	case profileCfg.AuthType != "":
+		effective = profileCfg.AuthType
+	}
+
+	return &EnvConflict{
+		Profile:           profileName,
+		ProfileAuthType:   profileAuthType,
+		EffectiveAuthType: effective,
+		Variables:         variables,
+	}, nil
+}
+
+// impliedAuthType returns the auth type selected by the credentials in cfg,
+// following the order of [credentialChain].
+func impliedAuthType(cfg *config.Config) string {
+	switch {
+	case cfg.Token != "":
+		return AuthTypePat
+	case cfg.Username != "" && cfg.Password != "":
+		return AuthTypeBasic
+	case cfg.ClientID != "" && cfg.ClientSecret != "":
+		return AuthTypeOAuthM2M
+	case cfg.MetadataServiceURL != "":
+		return AuthTypeMetadataService
+	}
+	return ""
+}
@@ -0,0 +1,172 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/cli/internal/testutil"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envConflictConfigFile = `
+[oauth]
+host = https://oauth.cloud.databricks.com
+auth_type = databricks-cli
+
+[pat]
+host = https://pat.cloud.databricks.com
+token = dapi123
+
+[pat-explicit]
+host = https://pat.cloud.databricks.com
+token = dapi123
+auth_type = pat
+
+[host-only]
+host = https://host-only.cloud.databricks.com
+`
+
+func TestDetectEnvConflicts(t *testing.T) {
+	testutil.CleanupEnvironment(t)
+	configFile := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(configFile, []byte(envConflictConfigFile), 0o600))
+
+	tests := []struct {
+		name    string
+		profile string
+		env     map[string]string
+		want    *EnvConflict
+	}{
+		{
+			name:    "PAT env vs OAuth profile",
+			profile: "oauth",
+			env:     map[string]string{"DATABRICKS_TOKEN": "dapi456"},
+			want: &EnvConflict{
+				Profile:           "oauth",
+				ProfileAuthType:   "databricks-cli",
+				EffectiveAuthType: "databricks-cli",
+				Variables: []EnvConflictVariable{
+					{Name: "DATABRICKS_TOKEN", AuthType: "pat"},
+				},
+			},
+		},
+		{
+			name:    "M2M env vs PAT profile",
+			profile: "pat",
+			env: map[string]string{
+				"DATABRICKS_CLIENT_ID":     "id",
+				"DATABRICKS_CLIENT_SECRET": "secret",
+			},
+			want: &EnvConflict{
+				Profile:         "pat",
+				ProfileAuthType: "pat",
+				Variables: []EnvConflictVariable{
+					{Name: "DATABRICKS_CLIENT_ID", AuthType: "oauth-m2m"},
+					{Name: "DATABRICKS_CLIENT_SECRET", AuthType: "oauth-m2m"},
+				},
+			},
+		},
+		{
+			name:    "M2M env vs PAT profile with auth type",
+			profile: "pat-explicit",
+			env: map[string]string{
+				"DATABRICKS_CLIENT_ID":     "id",
+				"DATABRICKS_CLIENT_SECRET": "secret",
+			},
+			want: &EnvConflict{
+				Profile:           "pat-explicit",
+				ProfileAuthType:   "pat",
+				EffectiveAuthType: "pat",
+				Variables: []EnvConflictVariable{
+					{Name: "DATABRICKS_CLIENT_ID", AuthType: "oauth-m2m"},
+					{Name: "DATABRICKS_CLIENT_SECRET", AuthType: "oauth-m2m"},
+				},
+			},
+		},
+		{
+			name:    "auth type env overrides profile",
+			profile: "oauth",
+			env: map[string]string{
+				"DATABRICKS_TOKEN":     "dapi456",
+				"DATABRICKS_AUTH_TYPE": "pat",
+			},
+			want: &EnvConflict{
+				Profile:           "oauth",
+				ProfileAuthType:   "databricks-cli",
+				EffectiveAuthType: "pat",
+				Variables: []EnvConflictVariable{
+					{Name: "DATABRICKS_TOKEN", AuthType: "pat"},
+					{Name: "DATABRICKS_AUTH_TYPE", AuthType: "pat"},
+				},
+			},
+		},
+		{
+			name:    "matching env",
+			profile: "pat",
+			env:     map[string]string{"DATABRICKS_TOKEN": "dapi456"},
+		},
+		{
+			name:    "no credentials in env",
+			profile: "oauth",
+			env:     map[string]string{"DATABRICKS_HOST": "https://other.cloud.databricks.com"},
+		},
+		{
+			name:    "profile without auth type",
+			profile: "host-only",
+			env:     map[string]string{"DATABRICKS_TOKEN": "dapi456"},
+		},
+		{
+			name: "no profile selected",
+			env:  map[string]string{"DATABRICKS_TOKEN": "dapi456"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			for k, v := range tt.env {
+				ctx = env.Set(ctx, k, v)
+			}
+			got, err := DetectEnvConflicts(ctx, &config.Config{Profile: tt.profile, ConfigFile: configFile})
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectEnvConflicts_ProfileFromEnv(t *testing.T) {
+	testutil.CleanupEnvironment(t)
+	configFile := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(configFile, []byte(envConflictConfigFile), 0o600))
+
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_PROFILE", "oauth")
+	ctx = env.Set(ctx, "DATABRICKS_CONFIG_FILE", configFile)
+	ctx = env.Set(ctx, "DATABRICKS_TOKEN", "dapi456")
+	got, err := DetectEnvConflicts(ctx, &config.Config{})
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "oauth", got.Profile)
+}
+
+func TestEnvConflictError(t *testing.T) {
+	c := &EnvConflict{
+		Profile:           "oauth",
+		ProfileAuthType:   "databricks-cli",
+		EffectiveAuthType: "pat",
+		Variables: []EnvConflictVariable{
+			{Name: "DATABRICKS_CLIENT_ID", AuthType: "oauth-m2m"},
+			{Name: "DATABRICKS_AUTH_TYPE", AuthType: "pat"},
+		},
+	}
+	assert.Equal(t, `environment variables conflict with the auth settings of profile "oauth" (auth type databricks-cli):
+  DATABRICKS_CLIENT_ID (oauth-m2m): ignored
+  DATABRICKS_AUTH_TYPE (pat): takes effect
+pat authentication takes effect`, c.Error())
+
+	c.EffectiveAuthType = ""
+	assert.Contains(t, c.Error(), "no auth type is set")
+}
@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// ShadowingEnvVars returns the names of the environment variables that are set
+// to values that differ from the profile that was just saved with the settings
+// of cfg. Commands that run in the same environment use these values instead
+// of the profile.
+//
+// DATABRICKS_HOST, DATABRICKS_TOKEN and DATABRICKS_CLIENT_ID override the
+// settings of the profile. DATABRICKS_CONFIG_PROFILE selects another profile
+// if it names one other than profileName.
+func ShadowingEnvVars(ctx context.Context, profileName string, cfg *config.Config) []string {
+	var names []string
+	if host := env.Get(ctx, "DATABRICKS_HOST"); host != "" && databrickscfg.NormalizeHost(host) != databrickscfg.NormalizeHost(cfg.Host) {
+		names = append(names, "DATABRICKS_HOST")
+	}
+	if token := env.Get(ctx, "DATABRICKS_TOKEN"); token != "" && token != cfg.Token {
+		names = append(names, "DATABRICKS_TOKEN")
+	}
+	if clientID := env.Get(ctx, "DATABRICKS_CLIENT_ID"); clientID != "" && clientID != cfg.ClientID {
+		names = append(names, "DATABRICKS_CLIENT_ID")
+	}
+	if name := env.Get(ctx, "DATABRICKS_CONFIG_PROFILE"); name != "" && name != profileName {
+		names = append(names, "DATABRICKS_CONFIG_PROFILE")
+	}
+	return names
+}
+
+// WarnShadowingEnvVars warns if environment variables that take precedence
+// over the profile that was just saved are set, as returned by
+// [ShadowingEnvVars]. Without the warning, users see commands fail or use
+// another workspace and don't know why.
+func WarnShadowingEnvVars(ctx context.Context, profileName string, cfg *config.Config) {
+	names := ShadowingEnvVars(ctx, profileName, cfg)
+	if len(names) == 0 {
+		return
+	}
+
+	var b strings.Builder
+	if len(names) == 1 {
+		b.WriteString("The environment variable " + names[0] + " is set to a value that differs from profile " + profileName + ". ")
+	} else {
+		b.WriteString("The environment variables " + strings.Join(names, ", ") + " are set to values that differ from profile " + profileName + ". ")
+	}
+	b.WriteString("Environment variables take precedence over the settings of a profile")
+	if slices.Contains(names, "DATABRICKS_CONFIG_PROFILE") {
+		b.WriteString(", and DATABRICKS_CONFIG_PROFILE selects the profile to use when --profile isn't set")
+	}
+	b.WriteString(". To use profile " + profileName + ", run: unset " + strings.Join(names, " "))
+	log.Warnf(ctx, "%s", b.String())
+}
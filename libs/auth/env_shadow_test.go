@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/databricks/cli/internal/testutil"
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShadowingEnvVars(t *testing.T) {
+	testutil.CleanupEnvironment(t)
+	cfg := &config.Config{
+		Host:  "https://dev.cloud.databricks.com",
+		Token: "dapi123",
+	}
+
+	tests := []struct {
+		name string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "unset",
+		},
+		{
+			name: "matching values",
+			env: map[string]string{
+				"DATABRICKS_HOST":           "https://DEV.cloud.databricks.com/",
+				"DATABRICKS_TOKEN":          "dapi123",
+				"DATABRICKS_CONFIG_PROFILE": "dev",
+			},
+		},
+		{
+			name: "empty values",
+			env: map[string]string{
+				"DATABRICKS_HOST":  "",
+				"DATABRICKS_TOKEN": "",
+			},
+		},
+		{
+			name: "different values",
+			env: map[string]string{
+				"DATABRICKS_HOST":           "https://prod.cloud.databricks.com",
+				"DATABRICKS_TOKEN":          "dapi456",
+				"DATABRICKS_CLIENT_ID":      "client",
+				"DATABRICKS_CONFIG_PROFILE": "prod",
+			},
+			want: []string{"DATABRICKS_HOST", "DATABRICKS_TOKEN", "DATABRICKS_CLIENT_ID", "DATABRICKS_CONFIG_PROFILE"},
+		},
+		{
+			name: "token only",
+			env: map[string]string{
+				"DATABRICKS_HOST":  "https://dev.cloud.databricks.com",
+				"DATABRICKS_TOKEN": "dapi456",
+			},
+			want: []string{"DATABRICKS_TOKEN"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := t.Context()
+			for k, v := range tt.env {
+				ctx = env.Set(ctx, k, v)
+			}
+			assert.Equal(t, tt.want, ShadowingEnvVars(ctx, "dev", cfg))
+		})
+	}
+}
+
+func TestWarnShadowingEnvVars(t *testing.T) {
+	testutil.CleanupEnvironment(t)
+	cfg := &config.Config{Host: "https://dev.cloud.databricks.com"}
+
+	var logs bytes.Buffer
+	ctx := log.NewContext(t.Context(), slog.New(slog.NewTextHandler(&logs, &slog.HandlerOptions{Level: slog.LevelWarn})))
+	WarnShadowingEnvVars(ctx, "dev", cfg)
+	assert.Empty(t, logs.String())
+
+	ctx = env.Set(ctx, "DATABRICKS_TOKEN", "dapi456")
+	WarnShadowingEnvVars(ctx, "dev", cfg)
+	assert.Contains(t, logs.String(), "The environment variable DATABRICKS_TOKEN is set to a value that differs from profile dev. "+
+		"Environment variables take precedence over the settings of a profile. To use profile dev, run: unset DATABRICKS_TOKEN")
+
+	ctx = env.Set(ctx, "DATABRICKS_CONFIG_PROFILE", "prod")
+	WarnShadowingEnvVars(ctx, "dev", cfg)
+	assert.Contains(t, logs.String(), "The environment variables DATABRICKS_TOKEN, DATABRICKS_CONFIG_PROFILE are set to values that differ from profile dev. "+
+		"Environment variables take precedence over the settings of a profile, and DATABRICKS_CONFIG_PROFILE selects the profile to use when --profile isn't set. "+
+		"To use profile dev, run: unset DATABRICKS_TOKEN DATABRICKS_CONFIG_PROFILE")
+}
@@ -1,12 +1,17 @@
 package auth
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
 
+	"github.com/databricks/cli/libs/shellquote"
 	"github.com/databricks/databricks-sdk-go/apierr"
 	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/databricks/databricks-sdk-go/credentials/u2m"
@@ -42,86 +47,436 @@ var authTypeDisplayNames = map[string]string{
 	AuthTypeMetadataService: "Metadata Service (metadata-service)",
 }
 
+// authTypeInfo describes an auth type registered with [RegisterAuthTypeInfo].
+type authTypeInfo struct {
+	displayName string
+	reauthHint  func(cfg *config.Config) string
+}
+
+var (
+	registeredAuthTypesMu sync.RWMutex
+	registeredAuthTypes   = map[string]authTypeInfo{}
+)
+
+// RegisterAuthTypeInfo registers a display name and remediation step for an
+// auth type that is not known to this package, such as a custom credentials
+// strategy. If reauthHint is not nil, the step it returns is suggested for 401
+// errors instead of the generic advice. It is safe to call from init().
+func RegisterAuthTypeInfo(name, displayName string, reauthHint func(cfg *config.Config) string) {
+	registeredAuthTypesMu.Lock()
+	defer registeredAuthTypesMu.Unlock()
+	registeredAuthTypes[strings.ToLower(name)] = authTypeInfo{
+		displayName: displayName,
+		reauthHint:  reauthHint,
+	}
+}
+
+func lookupAuthTypeInfo(authType string) (authTypeInfo, bool) {
+	registeredAuthTypesMu.RLock()
+	defer registeredAuthTypesMu.RUnlock()
+	info, ok := registeredAuthTypes[strings.ToLower(authType)]
+	return info, ok
+}
+
 // AuthTypeDisplayName returns a human-readable name for the given auth type.
 // Falls back to the raw identifier if no display name is registered.
 func AuthTypeDisplayName(authType string) string {
 	if name, ok := authTypeDisplayNames[strings.ToLower(authType)]; ok {
 		return name
 	}
+	if info, ok := lookupAuthTypeInfo(authType); ok && info.displayName != "" {
+		return info.displayName
+	}
 	return authType
 }
 
+// ReauthRequiredError is returned when the cached credentials can no longer
+// be used and the user needs to log in again. Callers such as IDE integrations
+// can detect it with [errors.As] and run LoginCommand.
+type ReauthRequiredError struct {
+	Host      string
+	AccountID string
+	Profile   string
+
+	// LoginCommand is the command that logs in again.
+	LoginCommand string
+
+	// Err is the underlying error.
+	Err error
+}
+
+func (e *ReauthRequiredError) Error() string {
+	return `A new access token could not be retrieved because the refresh token is invalid. To reauthenticate, run the following command:
+  $ ` + e.LoginCommand
+}
+
+func (e *ReauthRequiredError) Unwrap() error {
+	return e.Err
+}
+
 // RewriteAuthError rewrites the error message for invalid refresh token error.
-// It returns whether the error was rewritten and the rewritten error.
-func RewriteAuthError(ctx context.Context, host, accountId, profile string, err error) (bool, error) {
+// It returns whether the error was rewritten and the rewritten error, which is
+// a [*ReauthRequiredError]. The login command in the message preserves the
+// settings in opts, which may be nil.
+func RewriteAuthError(ctx context.Context, host, accountId, profile string, opts *LoginCommandOptions, err error) (bool, error) {
 	target := &u2m.InvalidRefreshTokenError{}
 	if errors.As(err, &target) {
-		oauthArgument, err := AuthArguments{
+		oauthArgument, argErr := AuthArguments{
 			Host:      host,
 			AccountID: accountId,
 		}.ToOAuthArgument()
-		if err != nil {
-			return false, err
+		if argErr != nil {
+			return false, argErr
+		}
+		return true, &ReauthRequiredError{
+			Host:         host,
+			AccountID:    accountId,
+			Profile:      profile,
+			LoginCommand: BuildLoginCommand(ctx, profile, oauthArgument, opts),
+			Err:          err,
 		}
-		msg := `A new access token could not be retrieved because the refresh token is invalid. To reauthenticate, run the following command:
-  $ ` + BuildLoginCommand(ctx, profile, oauthArgument)
-		return true, errors.New(msg)
 	}
 	return false, err
 }
 
+// EnrichedAuthError is an API error with the identity context and the
+// remediation steps added by [EnrichAuthError]. It is rendered as text by
+// Error and as a JSON object by MarshalJSON.
+type EnrichedAuthError struct {
+	// Err is the original error.
+	Err error
+
+	StatusCode int
+	ErrorCode  string
+
+	Profile  string
+	Host     string
+	AuthType string
+
+	// PublicIP is the public IP address of the caller. It is only looked up
+	// if the request was rejected by an IP access list.
+	PublicIP string
+
+	// RequestID and Endpoint identify the failed request.
+	RequestID string
+	Endpoint  string
+
+	// HostMismatch explains why the host type does not match the request.
+	HostMismatch string
+
+	NextSteps []string
+}
+
+func (e *EnrichedAuthError) Error() string {
+	var b strings.Builder
+	b.WriteString(e.Err.Error())
+	b.WriteString("\n")
+
+	// Identity context.
+	if e.Profile != "" {
+		fmt.Fprintf(&b, "\nProfile:    %s", e.Profile)
+	}
+	if e.Host != "" {
+		fmt.Fprintf(&b, "\nHost:       %s", e.Host)
+	}
+	if e.AuthType != "" {
+		fmt.Fprintf(&b, "\nAuth type:  %s", AuthTypeDisplayName(e.AuthType))
+	}
+	if e.PublicIP != "" {
+		fmt.Fprintf(&b, "\nPublic IP:  %s", e.PublicIP)
+	}
+
+	// Request context. The request ID lets support correlate the error with
+	// server-side logs.
+	if e.RequestID != "" {
+		fmt.Fprintf(&b, "\nRequest ID: %s", e.RequestID)
+	}
+	if e.Endpoint != "" {
+		fmt.Fprintf(&b, "\nEndpoint:   %s", e.Endpoint)
+	}
+
+	if e.HostMismatch != "" {
+		fmt.Fprintf(&b, "\n\n%s", e.HostMismatch)
+	}
+
+	fmt.Fprint(&b, "\n\nNext steps:")
+	for _, step := range e.NextSteps {
+		fmt.Fprintf(&b, "\n  - %s", step)
+	}
+	return b.String()
+}
+
+func (e *EnrichedAuthError) Unwrap() error {
+	return e.Err
+}
+
+// MarshalJSON renders the error for machine consumption. The next steps
+// contain shell commands, so HTML characters are not escaped.
+func (e *EnrichedAuthError) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	err := enc.Encode(struct {
+		ErrorCode    string   `json:"error_code,omitempty"`
+		StatusCode   int      `json:"status_code"`
+		Message      string   `json:"message"`
+		Profile      string   `json:"profile,omitempty"`
+		Host         string   `json:"host,omitempty"`
+		AuthType     string   `json:"auth_type,omitempty"`
+		PublicIP     string   `json:"public_ip,omitempty"`
+		RequestID    string   `json:"request_id,omitempty"`
+		Endpoint     string   `json:"endpoint,omitempty"`
+		HostMismatch string   `json:"host_mismatch,omitempty"`
+		NextSteps    []string `json:"next_steps"`
+	}{
+		ErrorCode:    e.ErrorCode,
+		StatusCode:   e.StatusCode,
+		Message:      e.Err.Error(),
+		Profile:      e.Profile,
+		Host:         e.Host,
+		AuthType:     e.AuthType,
+		PublicIP:     e.PublicIP,
+		RequestID:    e.RequestID,
+		Endpoint:     e.Endpoint,
+		HostMismatch: e.HostMismatch,
+		NextSteps:    e.NextSteps,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
 // EnrichAuthError appends identity context and remediation steps to 401/403 API errors.
-// For non-API errors or other status codes, the original error is returned unchanged.
+// 404 API errors are enriched only if the host type does not match the failing
+// request, e.g. a workspace API called on an account console host.
+// For non-API errors, other status codes, or errors that were already enriched,
+// the original error is returned unchanged. Enriched errors are returned as
+// [*EnrichedAuthError].
 func EnrichAuthError(ctx context.Context, cfg *config.Config, err error) error {
 	var apiErr *apierr.APIError
 	if !errors.As(err, &apiErr) {
 		return err
 	}
-	if apiErr.StatusCode != http.StatusUnauthorized && apiErr.StatusCode != http.StatusForbidden {
+	var enriched *EnrichedAuthError
+	if errors.As(err, &enriched) {
 		return err
 	}
 
-	var b strings.Builder
-
-	// Identity context.
-	if cfg.Profile != "" {
-		fmt.Fprintf(&b, "\nProfile:   %s", cfg.Profile)
-	}
-	if cfg.Host != "" {
-		fmt.Fprintf(&b, "\nHost:      %s", cfg.Host)
+	var path string
+	if req := apiErrorRequest(apiErr); req != nil {
+		path = req.URL.Path
 	}
-	if cfg.AuthType != "" {
-		fmt.Fprintf(&b, "\nAuth type: %s", AuthTypeDisplayName(cfg.AuthType))
+	mismatch := hostMismatchHint(cfg, path)
+
+	switch apiErr.StatusCode {
+	case http.StatusUnauthorized, http.StatusForbidden:
+	case http.StatusNotFound:
+		if mismatch == "" {
+			return err
+		}
+	default:
+		return err
 	}
 
-	fmt.Fprint(&b, "\n\nNext steps:")
+	enriched = &EnrichedAuthError{
+		Err:          err,
+		StatusCode:   apiErr.StatusCode,
+		ErrorCode:    apiErr.ErrorCode,
+		Profile:      cfg.Profile,
+		Host:         cfg.Host,
+		AuthType:     cfg.AuthType,
+		RequestID:    apiErrorRequestID(apiErr),
+		Endpoint:     apiErrorEndpoint(apiErr),
+		HostMismatch: mismatch,
+	}
 
-	if apiErr.StatusCode == http.StatusUnauthorized {
-		writeReauthSteps(ctx, cfg, &b)
-	} else {
-		fmt.Fprint(&b, "\n  - Verify you have the required permissions for this operation")
+	// A step for the specific reason of the failure replaces the generic
+	// step for the status code.
+	switch reason := findTokenErrorReason(apiErr); {
+	case reason != nil && reason.ipAccessList:
+		// Requests rejected by an IP access list look like permission
+		// problems. The public IP tells the user which address to allow.
+		enriched.PublicIP = publicIP(ctx)
+		enriched.NextSteps = append(enriched.NextSteps, reason.step, ipAccessListStep(enriched.PublicIP))
+	case reason != nil:
+		enriched.NextSteps = append(enriched.NextSteps, reason.step)
+	case apiErr.StatusCode == http.StatusUnauthorized:
+		enriched.NextSteps = append(enriched.NextSteps, reauthSteps(ctx, cfg)...)
+	case apiErr.StatusCode == http.StatusForbidden:
+		enriched.NextSteps = append(enriched.NextSteps, "Verify you have the required permissions for this operation")
 	}
 
 	// Always suggest checking identity.
-	fmt.Fprintf(&b, "\n  - Check your identity: %s", BuildDescribeCommand(cfg))
+	enriched.NextSteps = append(enriched.NextSteps, "Check your identity: "+BuildDescribeCommand(cfg))
 
 	// Nudge toward profiles when using env-var-based auth.
 	if cfg.Profile == "" {
-		fmt.Fprint(&b, "\n  - Consider setting up a profile: databricks auth login --profile <name>")
+		enriched.NextSteps = append(enriched.NextSteps, "Consider setting up a profile: databricks auth login --profile <name>")
+	}
+
+	return enriched
+}
+
+// tokenErrorReason is a reason for rejecting a token and the step that
+// resolves it. A reason is recognized by its error code, or by a marker in the
+// message for servers that return a generic error code.
+type tokenErrorReason struct {
+	errorCodes []string
+	markers    []string
+	step       string
+
+	// ipAccessList is true if the request was rejected by an IP access list.
+	ipAccessList bool
+}
+
+var tokenErrorReasons = []tokenErrorReason{
+	{
+		errorCodes: []string{"TOKEN_EXPIRED"},
+		markers:    []string{"token is expired", "token has expired", "token expired"},
+		step:       "Your access token has expired; regenerate it",
+	},
+	{
+		errorCodes: []string{"TOKEN_REVOKED"},
+		markers:    []string{"token is revoked", "token has been revoked", "token revoked"},
+		step:       "Your access token has been revoked; contact your workspace administrator",
+	},
+	{
+		// IP access lists reject requests with INVALID_STATE or a generic
+		// error code, so the message is the reliable signal.
+		errorCodes:   []string{"IP_ACCESS_DENIED", "IP_ACCESS_LIST_DENIED"},
+		markers:      []string{"ip acl", "ip access list"},
+		step:         "Your IP is not allowed; check workspace IP access lists",
+		ipAccessList: true,
+	},
+}
+
+// findTokenErrorReason returns the reason apiErr was rejected, or nil if the
+// reason is not known.
+func findTokenErrorReason(apiErr *apierr.APIError) *tokenErrorReason {
+	message := strings.ToLower(apiErr.Message)
+	for i, r := range tokenErrorReasons {
+		if slices.Contains(r.errorCodes, apiErr.ErrorCode) {
+			return &tokenErrorReasons[i]
+		}
+		for _, marker := range r.markers {
+			if strings.Contains(message, marker) {
+				return &tokenErrorReasons[i]
+			}
+		}
+	}
+	return nil
+}
+
+// ipAccessListStep returns the step for a request that was rejected by an IP
+// access list. ip is the public IP of the caller, if known.
+func ipAccessListStep(ip string) string {
+	address := "your IP address"
+	if ip != "" {
+		address = ip
+	}
+	return "Connect from an allowed network such as your VPN, or ask a workspace admin to add " + address + " in Settings > Security > IP access list"
+}
+
+// requestIDHeader is the response header that carries the ID of a request.
+const requestIDHeader = "X-Request-Id"
+
+// apiErrorRequestID returns the request ID of the failed request. It prefers
+// the ID from the error details and falls back to the response header.
+func apiErrorRequestID(apiErr *apierr.APIError) string {
+	if info := apiErr.ErrorDetails().RequestInfo; info != nil && info.RequestID != "" {
+		return info.RequestID
+	}
+	if resp := apiErrorResponse(apiErr); resp != nil {
+		return resp.Header.Get(requestIDHeader)
+	}
+	return ""
+}
+
+// apiErrorEndpoint returns the HTTP method and path of the failed request.
+func apiErrorEndpoint(apiErr *apierr.APIError) string {
+	req := apiErrorRequest(apiErr)
+	if req == nil {
+		return ""
+	}
+	return req.Method + " " + req.URL.Path
+}
+
+// apiErrorRequest returns the failed request, or nil if it is not known.
+func apiErrorRequest(apiErr *apierr.APIError) *http.Request {
+	resp := apiErrorResponse(apiErr)
+	if resp == nil || resp.Request == nil || resp.Request.URL == nil {
+		return nil
+	}
+	return resp.Request
+}
+
+func apiErrorResponse(apiErr *apierr.APIError) *http.Response {
+	if apiErr.ResponseWrapper == nil {
+		return nil
 	}
+	return apiErr.ResponseWrapper.Response
+}
+
+// isAccountAPIPath reports whether path is an account-level API. Account APIs
+// are served under /api/<version>/accounts/.
+func isAccountAPIPath(path string) bool {
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	return len(parts) >= 3 && parts[0] == "api" && parts[2] == "accounts"
+}
 
-	return fmt.Errorf("%w\n%s", err, b.String())
+// hostMismatchHint returns an explanation if the host type of cfg does not
+// match the API at path, and an empty string otherwise. Unified hosts serve
+// both workspace and account APIs and never mismatch.
+func hostMismatchHint(cfg *config.Config, path string) string {
+	if cfg.Host == "" || !strings.HasPrefix(path, "/api/") {
+		return ""
+	}
+	switch cfg.HostType() {
+	case config.AccountHost:
+		if !isAccountAPIPath(path) {
+			return "Your host is an account console URL; workspace commands require a workspace URL (" + exampleWorkspaceURL(cfg) + ")"
+		}
+	case config.WorkspaceHost:
+		if isAccountAPIPath(path) {
+			return "Your host is a workspace URL; account commands require an account console URL (" + accountConsoleURL(cfg) + ")"
+		}
+	}
+	return ""
+}
+
+// exampleWorkspaceURL returns a placeholder workspace URL in the cloud of cfg.
+func exampleWorkspaceURL(cfg *config.Config) string {
+	switch {
+	case cfg.IsAzure():
+		return "https://adb-<workspace-id>.<random>.azuredatabricks.net"
+	case cfg.IsGcp():
+		return "https://<workspace-id>.<random>.gcp.databricks.com"
+	default:
+		return "https://<workspace>.cloud.databricks.com"
+	}
+}
+
+// accountConsoleURL returns the account console URL in the cloud of cfg.
+func accountConsoleURL(cfg *config.Config) string {
+	switch {
+	case cfg.IsAzure():
+		return "https://accounts.azuredatabricks.net"
+	case cfg.IsGcp():
+		return "https://accounts.gcp.databricks.com"
+	default:
+		return "https://accounts.cloud.databricks.com"
+	}
 }
 
-// writeReauthSteps writes auth-type-aware re-authentication suggestions for 401 errors.
-func writeReauthSteps(ctx context.Context, cfg *config.Config, b *strings.Builder) {
+// reauthSteps returns auth-type-aware re-authentication suggestions for 401 errors.
+func reauthSteps(ctx context.Context, cfg *config.Config) []string {
 	switch strings.ToLower(cfg.AuthType) {
 	case AuthTypeDatabricksCli:
 		// When profile is set, BuildLoginCommand uses --profile and ignores
 		// the OAuthArgument, so skip the conversion entirely.
 		if cfg.Profile != "" {
-			fmt.Fprintf(b, "\n  - Re-authenticate: databricks auth login --profile %s", cfg.Profile)
-			return
+			return []string{"Re-authenticate: " + BuildLoginCommand(ctx, cfg.Profile, nil, LoginCommandOptionsFromConfig(cfg))}
 		}
 		oauthArg, argErr := AuthArguments{
 			Host:          cfg.Host,
@@ -130,43 +485,86 @@ func writeReauthSteps(ctx context.Context, cfg *config.Config, b *strings.Builde
 			IsUnifiedHost: cfg.Experimental_IsUnifiedHost,
 		}.ToOAuthArgument()
 		if argErr != nil {
-			fmt.Fprint(b, "\n  - Re-authenticate: databricks auth login")
-			return
+			return []string{"Re-authenticate: databricks auth login"}
 		}
-		fmt.Fprintf(b, "\n  - Re-authenticate: %s", BuildLoginCommand(ctx, "", oauthArg))
+		return []string{"Re-authenticate: " + BuildLoginCommand(ctx, "", oauthArg, LoginCommandOptionsFromConfig(cfg))}
 
 	case AuthTypePat:
 		if cfg.Profile != "" {
-			fmt.Fprintf(b, "\n  - Regenerate your access token or run: databricks auth login --profile %s", cfg.Profile)
-		} else {
-			fmt.Fprint(b, "\n  - Regenerate your access token")
+			return []string{"Regenerate your access token or run: databricks auth login --profile " + cfg.Profile}
 		}
+		return []string{"Regenerate your access token"}
 
 	case AuthTypeBasic:
 		if cfg.Profile != "" {
-			fmt.Fprintf(b, "\n  - Check your username/password or run: databricks auth login --profile %s", cfg.Profile)
-		} else {
-			fmt.Fprint(b, "\n  - Check your username and password")
+			return []string{"Check your username/password or run: databricks auth login --profile " + cfg.Profile}
 		}
+		return []string{"Check your username and password"}
 
 	case AuthTypeAzureCli:
-		fmt.Fprint(b, "\n  - Re-authenticate with Azure: az login")
+		return []string{"Re-authenticate with Azure: az login"}
 
 	case AuthTypeOAuthM2M:
-		fmt.Fprint(b, "\n  - Check your service principal client ID and secret")
+		return []string{"Check your service principal client ID and secret"}
+
+	case AuthTypeAzureMSI:
+		return []string{"Verify that the managed identity of this VM has access to the workspace"}
+
+	case AuthTypeGoogleCreds:
+		return []string{"Check that GOOGLE_APPLICATION_CREDENTIALS points to a valid service account key"}
+
+	case AuthTypeGoogleID:
+		return []string{"Re-authenticate with Google: gcloud auth application-default login"}
+
+	case AuthTypeMetadataService:
+		return []string{
+			fmt.Sprintf("Verify that the metadata service at %s (DATABRICKS_METADATA_SERVICE_URL) is running and returns a token for %s", cfg.MetadataServiceURL, cfg.Host),
+			fmt.Sprintf("Check the response of the metadata service: %s --probe-metadata-service", BuildDescribeCommand(cfg)),
+		}
 
 	default:
-		fmt.Fprint(b, "\n  - Check your authentication credentials")
+		if info, ok := lookupAuthTypeInfo(cfg.AuthType); ok && info.reauthHint != nil {
+			if hint := info.reauthHint(cfg); hint != "" {
+				return []string{hint}
+			}
+		}
+		return []string{"Check your authentication credentials"}
 	}
 }
 
-// BuildLoginCommand builds the login command for the given OAuth argument or profile.
-func BuildLoginCommand(ctx context.Context, profile string, arg u2m.OAuthArgument) string {
-	cmd := []string{
+// LoginCommandOptions are the settings of a profile that a login command must
+// preserve, so that logging in again does not change the behavior of the
+// profile.
+type LoginCommandOptions struct {
+	// Scopes are the OAuth scopes of the profile.
+	Scopes []string
+
+	// ConfigFile is the path of the config file if it is not the default.
+	ConfigFile string
+}
+
+// LoginCommandOptionsFromConfig returns the login command options for the
+// scopes and config file of cfg.
+func LoginCommandOptionsFromConfig(cfg *config.Config) *LoginCommandOptions {
+	return &LoginCommandOptions{
+		Scopes:     cfg.Scopes,
+		ConfigFile: cfg.ConfigFile,
+	}
+}
+
+// BuildLoginCommand builds the login command for the given OAuth argument or
+// profile. If opts is not nil, the command requests its scopes and is prefixed
+// with its config file.
+func BuildLoginCommand(ctx context.Context, profile string, arg u2m.OAuthArgument, opts *LoginCommandOptions) string {
+	var cmd []string
+	if opts != nil && opts.ConfigFile != "" {
+		cmd = append(cmd, "DATABRICKS_CONFIG_FILE="+shellquote.BashArg(opts.ConfigFile))
+	}
+	cmd = append(cmd,
 		"databricks",
 		"auth",
 		"login",
-	}
+	)
 	if profile != "" {
 		cmd = append(cmd, "--profile", profile)
 	} else {
@@ -182,6 +580,9 @@ func BuildLoginCommand(ctx context.Context, profile string, arg u2m.OAuthArgumen
 			cmd = append(cmd, "--host", arg.GetWorkspaceHost())
 		}
 	}
+	if opts != nil && len(opts.Scopes) > 0 {
+		cmd = append(cmd, "--scopes", strings.Join(opts.Scopes, ","))
+	}
 	return strings.Join(cmd, " ")
 }
 
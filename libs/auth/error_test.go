@@ -1,11 +1,18 @@
 package auth
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"io"
+	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/databricks/databricks-sdk-go/apierr"
+	"github.com/databricks/databricks-sdk-go/common"
 	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -62,6 +69,44 @@ func TestEnrichAuthError_PreservesOriginalError(t *testing.T) {
 	assert.Equal(t, "PERMISSION_DENIED", unwrapped.ErrorCode)
 }
 
+func TestEnrichAuthError_AlreadyEnriched(t *testing.T) {
+	cfg := &config.Config{Host: "https://example.com", AuthType: "pat"}
+	original := &apierr.APIError{StatusCode: 403, Message: "forbidden"}
+	enriched := EnrichAuthError(t.Context(), cfg, original)
+	assert.Same(t, enriched, EnrichAuthError(t.Context(), cfg, enriched))
+}
+
+func TestEnrichedAuthErrorJSON(t *testing.T) {
+	cfg := &config.Config{
+		Host:     "https://my-workspace.cloud.databricks.com",
+		Profile:  "dev",
+		AuthType: AuthTypePat,
+	}
+	original := &apierr.APIError{
+		StatusCode: 403,
+		ErrorCode:  "PERMISSION_DENIED",
+		Message:    "no access",
+	}
+	err := EnrichAuthError(t.Context(), cfg, original)
+
+	var enriched *EnrichedAuthError
+	require.ErrorAs(t, err, &enriched)
+	raw, jsonErr := json.Marshal(enriched)
+	require.NoError(t, jsonErr)
+	assert.JSONEq(t, `{
+		"error_code": "PERMISSION_DENIED",
+		"status_code": 403,
+		"message": "no access",
+		"profile": "dev",
+		"host": "https://my-workspace.cloud.databricks.com",
+		"auth_type": "pat",
+		"next_steps": [
+			"Verify you have the required permissions for this operation",
+			"Check your identity: databricks auth describe --profile dev"
+		]
+	}`, string(raw))
+}
+
 func TestEnrichAuthError(t *testing.T) {
 	tests := []struct {
 		name       string
@@ -78,13 +123,31 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nProfile:   dev" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: OAuth (databricks-cli)" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  OAuth (databricks-cli)" +
 				"\n\nNext steps:" +
 				"\n  - Re-authenticate: databricks auth login --profile dev" +
 				"\n  - Check your identity: databricks auth describe --profile dev",
 		},
+		{
+			name: "401 with profile, scopes and config file",
+			cfg: &config.Config{
+				Host:       "https://my-workspace.cloud.databricks.com",
+				Profile:    "dev",
+				AuthType:   AuthTypeDatabricksCli,
+				Scopes:     []string{"clusters", "sql"},
+				ConfigFile: "/tmp/databrickscfg",
+			},
+			statusCode: 401,
+			wantMsg: "test error message\n" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  OAuth (databricks-cli)" +
+				"\n\nNext steps:" +
+				"\n  - Re-authenticate: DATABRICKS_CONFIG_FILE=/tmp/databrickscfg databricks auth login --profile dev --scopes clusters,sql" +
+				"\n  - Check your identity: databricks auth describe --profile dev",
+		},
 		{
 			name: "401 with profile and pat auth",
 			cfg: &config.Config{
@@ -94,9 +157,9 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nProfile:   dev" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: Personal Access Token (pat)" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
 				"\n\nNext steps:" +
 				"\n  - Regenerate your access token or run: databricks auth login --profile dev" +
 				"\n  - Check your identity: databricks auth describe --profile dev",
@@ -110,9 +173,9 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nProfile:   azure" +
-				"\nHost:      https://adb-123.azuredatabricks.net" +
-				"\nAuth type: Azure CLI (azure-cli)" +
+				"\nProfile:    azure" +
+				"\nHost:       https://adb-123.azuredatabricks.net" +
+				"\nAuth type:  Azure CLI (azure-cli)" +
 				"\n\nNext steps:" +
 				"\n  - Re-authenticate with Azure: az login" +
 				"\n  - Check your identity: databricks auth describe --profile azure",
@@ -126,13 +189,78 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nProfile:   sp" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: OAuth Machine-to-Machine (oauth-m2m)" +
+				"\nProfile:    sp" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  OAuth Machine-to-Machine (oauth-m2m)" +
 				"\n\nNext steps:" +
 				"\n  - Check your service principal client ID and secret" +
 				"\n  - Check your identity: databricks auth describe --profile sp",
 		},
+		{
+			name: "401 with profile and azure-msi auth",
+			cfg: &config.Config{
+				Host:     "https://adb-123.azuredatabricks.net",
+				Profile:  "msi",
+				AuthType: AuthTypeAzureMSI,
+			},
+			statusCode: 401,
+			wantMsg: "test error message\n" +
+				"\nProfile:    msi" +
+				"\nHost:       https://adb-123.azuredatabricks.net" +
+				"\nAuth type:  Azure Managed Identity (azure-msi)" +
+				"\n\nNext steps:" +
+				"\n  - Verify that the managed identity of this VM has access to the workspace" +
+				"\n  - Check your identity: databricks auth describe --profile msi",
+		},
+		{
+			name: "401 with profile and google-credentials auth",
+			cfg: &config.Config{
+				Host:     "https://123.4.gcp.databricks.com",
+				Profile:  "gcp",
+				AuthType: AuthTypeGoogleCreds,
+			},
+			statusCode: 401,
+			wantMsg: "test error message\n" +
+				"\nProfile:    gcp" +
+				"\nHost:       https://123.4.gcp.databricks.com" +
+				"\nAuth type:  Google Credentials (google-credentials)" +
+				"\n\nNext steps:" +
+				"\n  - Check that GOOGLE_APPLICATION_CREDENTIALS points to a valid service account key" +
+				"\n  - Check your identity: databricks auth describe --profile gcp",
+		},
+		{
+			name: "401 without profile and google-id auth",
+			cfg: &config.Config{
+				Host:     "https://123.4.gcp.databricks.com",
+				AuthType: AuthTypeGoogleID,
+			},
+			statusCode: 401,
+			wantMsg: "test error message\n" +
+				"\nHost:       https://123.4.gcp.databricks.com" +
+				"\nAuth type:  Google Default Credentials (google-id)" +
+				"\n\nNext steps:" +
+				"\n  - Re-authenticate with Google: gcloud auth application-default login" +
+				"\n  - Check your identity: databricks auth describe" +
+				"\n  - Consider setting up a profile: databricks auth login --profile <name>",
+		},
+		{
+			name: "401 with profile and metadata-service auth",
+			cfg: &config.Config{
+				Host:               "https://my-workspace.cloud.databricks.com",
+				Profile:            "sidecar",
+				AuthType:           AuthTypeMetadataService,
+				MetadataServiceURL: "http://localhost:8080/token",
+			},
+			statusCode: 401,
+			wantMsg: "test error message\n" +
+				"\nProfile:    sidecar" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Metadata Service (metadata-service)" +
+				"\n\nNext steps:" +
+				"\n  - Verify that the metadata service at http://localhost:8080/token (DATABRICKS_METADATA_SERVICE_URL) is running and returns a token for https://my-workspace.cloud.databricks.com" +
+				"\n  - Check the response of the metadata service: databricks auth describe --profile sidecar --probe-metadata-service" +
+				"\n  - Check your identity: databricks auth describe --profile sidecar",
+		},
 		{
 			name: "401 with profile and basic auth",
 			cfg: &config.Config{
@@ -142,9 +270,9 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nProfile:   basic-profile" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: Basic" +
+				"\nProfile:    basic-profile" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Basic" +
 				"\n\nNext steps:" +
 				"\n  - Check your username/password or run: databricks auth login --profile basic-profile" +
 				"\n  - Check your identity: databricks auth describe --profile basic-profile",
@@ -158,9 +286,9 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nProfile:   dev" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: some-future-auth" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  some-future-auth" +
 				"\n\nNext steps:" +
 				"\n  - Check your authentication credentials" +
 				"\n  - Check your identity: databricks auth describe --profile dev",
@@ -174,9 +302,9 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 403,
 			wantMsg: "test error message\n" +
-				"\nProfile:   dev" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: Personal Access Token (pat)" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
 				"\n\nNext steps:" +
 				"\n  - Verify you have the required permissions for this operation" +
 				"\n  - Check your identity: databricks auth describe --profile dev",
@@ -189,8 +317,8 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: Personal Access Token (pat)" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
 				"\n\nNext steps:" +
 				"\n  - Regenerate your access token" +
 				"\n  - Check your identity: databricks auth describe" +
@@ -204,8 +332,8 @@ func TestEnrichAuthError(t *testing.T) {
 			},
 			statusCode: 403,
 			wantMsg: "test error message\n" +
-				"\nHost:      https://my-workspace.cloud.databricks.com" +
-				"\nAuth type: Personal Access Token (pat)" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
 				"\n\nNext steps:" +
 				"\n  - Verify you have the required permissions for this operation" +
 				"\n  - Check your identity: databricks auth describe" +
@@ -215,15 +343,15 @@ func TestEnrichAuthError(t *testing.T) {
 			name: "401 with account host and no profile",
 			cfg: &config.Config{
 				Host:      "https://accounts.cloud.databricks.com",
-				AccountID: "abc123",
+				AccountID: "01234567-89ab-cdef-0123-456789abcdef",
 				AuthType:  AuthTypeDatabricksCli,
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nHost:      https://accounts.cloud.databricks.com" +
-				"\nAuth type: OAuth (databricks-cli)" +
+				"\nHost:       https://accounts.cloud.databricks.com" +
+				"\nAuth type:  OAuth (databricks-cli)" +
 				"\n\nNext steps:" +
-				"\n  - Re-authenticate: databricks auth login --host https://accounts.cloud.databricks.com --account-id abc123" +
+				"\n  - Re-authenticate: databricks auth login --host https://accounts.cloud.databricks.com --account-id 01234567-89ab-cdef-0123-456789abcdef" +
 				"\n  - Check your identity: databricks auth describe" +
 				"\n  - Consider setting up a profile: databricks auth login --profile <name>",
 		},
@@ -231,17 +359,17 @@ func TestEnrichAuthError(t *testing.T) {
 			name: "401 with unified host and no profile",
 			cfg: &config.Config{
 				Host:                       "https://unified.cloud.databricks.com",
-				AccountID:                  "acc-123",
-				WorkspaceID:                "ws-456",
+				AccountID:                  "01234567-89ab-cdef-0123-456789abcdef",
+				WorkspaceID:                "456",
 				AuthType:                   AuthTypeDatabricksCli,
 				Experimental_IsUnifiedHost: true,
 			},
 			statusCode: 401,
 			wantMsg: "test error message\n" +
-				"\nHost:      https://unified.cloud.databricks.com" +
-				"\nAuth type: OAuth (databricks-cli)" +
+				"\nHost:       https://unified.cloud.databricks.com" +
+				"\nAuth type:  OAuth (databricks-cli)" +
 				"\n\nNext steps:" +
-				"\n  - Re-authenticate: databricks auth login --host https://unified.cloud.databricks.com --account-id acc-123 --experimental-is-unified-host" +
+				"\n  - Re-authenticate: databricks auth login --host https://unified.cloud.databricks.com --account-id 01234567-89ab-cdef-0123-456789abcdef --experimental-is-unified-host" +
 				"\n  - Check your identity: databricks auth describe" +
 				"\n  - Consider setting up a profile: databricks auth login --profile <name>",
 		},
@@ -260,3 +388,446 @@ func TestEnrichAuthError(t *testing.T) {
 		})
 	}
 }
+
+func TestEnrichAuthError_TokenErrorReason(t *testing.T) {
+	cfg := &config.Config{
+		Host:     "https://my-workspace.cloud.databricks.com",
+		Profile:  "dev",
+		AuthType: AuthTypePat,
+	}
+	identity := "\nProfile:    dev" +
+		"\nHost:       https://my-workspace.cloud.databricks.com" +
+		"\nAuth type:  Personal Access Token (pat)"
+
+	tests := []struct {
+		name       string
+		statusCode int
+		errorCode  string
+		message    string
+		wantStep   string
+	}{
+		{
+			name:       "expired token error code",
+			statusCode: 403,
+			errorCode:  "TOKEN_EXPIRED",
+			message:    "test error message",
+			wantStep:   "Your access token has expired; regenerate it",
+		},
+		{
+			name:       "expired token message",
+			statusCode: 401,
+			errorCode:  "401",
+			message:    "Token is expired",
+			wantStep:   "Your access token has expired; regenerate it",
+		},
+		{
+			name:       "revoked token error code",
+			statusCode: 403,
+			errorCode:  "TOKEN_REVOKED",
+			message:    "test error message",
+			wantStep:   "Your access token has been revoked; contact your workspace administrator",
+		},
+		{
+			name:       "unknown reason falls back to status code",
+			statusCode: 403,
+			errorCode:  "PERMISSION_DENIED",
+			message:    "test error message",
+			wantStep:   "Verify you have the required permissions for this operation",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := &apierr.APIError{
+				StatusCode: tt.statusCode,
+				ErrorCode:  tt.errorCode,
+				Message:    tt.message,
+			}
+
+			result := EnrichAuthError(t.Context(), cfg, original)
+			assert.Equal(t, tt.message+"\n"+identity+
+				"\n\nNext steps:"+
+				"\n  - "+tt.wantStep+
+				"\n  - Check your identity: databricks auth describe --profile dev", result.Error())
+		})
+	}
+}
+
+func TestEnrichAuthError_IPAccessList(t *testing.T) {
+	cfg := &config.Config{
+		Host:     "https://my-workspace.cloud.databricks.com",
+		Profile:  "dev",
+		AuthType: AuthTypePat,
+	}
+
+	tests := []struct {
+		name      string
+		errorCode string
+		message   string
+		ip        string
+		lookupErr error
+		noNetwork bool
+		wantIP    string
+	}{
+		{
+			name:      "error code",
+			errorCode: "IP_ACCESS_DENIED",
+			message:   "test error message",
+			ip:        "203.0.113.7",
+			wantIP:    "203.0.113.7",
+		},
+		{
+			name:      "invalid state with message",
+			errorCode: "INVALID_STATE",
+			message:   "Source IP address: 203.0.113.7 is blocked by Databricks IP ACL for workspace: 123",
+			ip:        "203.0.113.7",
+			wantIP:    "203.0.113.7",
+		},
+		{
+			name:      "offline",
+			errorCode: "IP_ACCESS_DENIED",
+			message:   "test error message",
+			lookupErr: errors.New("no network"),
+		},
+		{
+			name:      "network diagnostics disabled",
+			errorCode: "IP_ACCESS_DENIED",
+			message:   "test error message",
+			ip:        "203.0.113.7",
+			noNetwork: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lookups := 0
+			defer func(fn func(context.Context) (string, error)) { lookupPublicIP = fn }(lookupPublicIP)
+			lookupPublicIP = func(context.Context) (string, error) {
+				lookups++
+				return tt.ip, tt.lookupErr
+			}
+
+			ctx := t.Context()
+			if tt.noNetwork {
+				ctx = WithoutNetworkDiagnostics(ctx)
+			}
+			original := &apierr.APIError{StatusCode: 403, ErrorCode: tt.errorCode, Message: tt.message}
+			err := EnrichAuthError(ctx, cfg, original)
+
+			var enriched *EnrichedAuthError
+			require.ErrorAs(t, err, &enriched)
+			assert.Equal(t, tt.wantIP, enriched.PublicIP)
+			assert.Equal(t, !tt.noNetwork, lookups == 1)
+
+			address := "your IP address"
+			identity := "\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)"
+			if tt.wantIP != "" {
+				address = tt.wantIP
+				identity += "\nPublic IP:  " + tt.wantIP
+			}
+			assert.Equal(t, tt.message+"\n"+identity+
+				"\n\nNext steps:"+
+				"\n  - Your IP is not allowed; check workspace IP access lists"+
+				"\n  - Connect from an allowed network such as your VPN, or ask a workspace admin to add "+address+" in Settings > Security > IP access list"+
+				"\n  - Check your identity: databricks auth describe --profile dev", err.Error())
+		})
+	}
+}
+
+func TestEnrichAuthError_RequestContext(t *testing.T) {
+	cfg := &config.Config{
+		Host:     "https://my-workspace.cloud.databricks.com",
+		Profile:  "dev",
+		AuthType: AuthTypePat,
+	}
+	newResponse := func(header http.Header, body string) *http.Response {
+		req, err := http.NewRequest(http.MethodGet, "https://my-workspace.cloud.databricks.com/api/2.0/preview/scim/v2/Me?attributes=id", nil)
+		require.NoError(t, err)
+		return &http.Response{
+			StatusCode: http.StatusForbidden,
+			Header:     header,
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    req,
+		}
+	}
+
+	tests := []struct {
+		name     string
+		response *http.Response
+		wantMsg  string
+	}{
+		{
+			name:     "request ID from header",
+			response: newResponse(http.Header{"X-Request-Id": {"req-header-123"}}, `{"error_code": "PERMISSION_DENIED", "message": "test error message"}`),
+			wantMsg: "test error message\n" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
+				"\nRequest ID: req-header-123" +
+				"\nEndpoint:   GET /api/2.0/preview/scim/v2/Me" +
+				"\n\nNext steps:" +
+				"\n  - Verify you have the required permissions for this operation" +
+				"\n  - Check your identity: databricks auth describe --profile dev",
+		},
+		{
+			name: "request ID from error details takes precedence",
+			response: newResponse(http.Header{"X-Request-Id": {"req-header-123"}}, `{
+				"error_code": "PERMISSION_DENIED",
+				"message": "test error message",
+				"details": [{"@type": "type.googleapis.com/google.rpc.RequestInfo", "request_id": "req-details-456"}]
+			}`),
+			wantMsg: "test error message\n" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
+				"\nRequest ID: req-details-456" +
+				"\nEndpoint:   GET /api/2.0/preview/scim/v2/Me" +
+				"\n\nNext steps:" +
+				"\n  - Verify you have the required permissions for this operation" +
+				"\n  - Check your identity: databricks auth describe --profile dev",
+		},
+		{
+			name:     "no request ID",
+			response: newResponse(http.Header{}, `{"error_code": "PERMISSION_DENIED", "message": "test error message"}`),
+			wantMsg: "test error message\n" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
+				"\nEndpoint:   GET /api/2.0/preview/scim/v2/Me" +
+				"\n\nNext steps:" +
+				"\n  - Verify you have the required permissions for this operation" +
+				"\n  - Check your identity: databricks auth describe --profile dev",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := apierr.GetAPIError(t.Context(), common.ResponseWrapper{
+				Response:   tt.response,
+				ReadCloser: tt.response.Body,
+			})
+			require.Error(t, original)
+
+			result := EnrichAuthError(t.Context(), cfg, original)
+			assert.Equal(t, tt.wantMsg, result.Error())
+		})
+	}
+}
+
+func TestEnrichAuthError_HostMismatch(t *testing.T) {
+	newAPIError := func(t *testing.T, status int, url string) error {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		require.NoError(t, err)
+		resp := &http.Response{
+			StatusCode: status,
+			Header:     http.Header{},
+			Body:       io.NopCloser(strings.NewReader(`{"message": "test error message"}`)),
+			Request:    req,
+		}
+		err = apierr.GetAPIError(t.Context(), common.ResponseWrapper{Response: resp, ReadCloser: resp.Body})
+		require.Error(t, err)
+		return err
+	}
+
+	tests := []struct {
+		name    string
+		cfg     *config.Config
+		status  int
+		url     string
+		wantMsg string
+	}{
+		{
+			name:   "workspace API on account host",
+			cfg:    &config.Config{Host: "https://accounts.cloud.databricks.com", AuthType: AuthTypePat},
+			status: http.StatusUnauthorized,
+			url:    "https://accounts.cloud.databricks.com/api/2.0/preview/scim/v2/Me",
+			wantMsg: "test error message\n" +
+				"\nHost:       https://accounts.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
+				"\nEndpoint:   GET /api/2.0/preview/scim/v2/Me" +
+				"\n\nYour host is an account console URL; workspace commands require a workspace URL (https://<workspace>.cloud.databricks.com)" +
+				"\n\nNext steps:" +
+				"\n  - Regenerate your access token" +
+				"\n  - Check your identity: databricks auth describe" +
+				"\n  - Consider setting up a profile: databricks auth login --profile <name>",
+		},
+		{
+			name:   "workspace API on account host returns 404",
+			cfg:    &config.Config{Host: "https://accounts.azuredatabricks.net", Profile: "acc"},
+			status: http.StatusNotFound,
+			url:    "https://accounts.azuredatabricks.net/api/2.1/jobs/list",
+			wantMsg: "test error message\n" +
+				"\nProfile:    acc" +
+				"\nHost:       https://accounts.azuredatabricks.net" +
+				"\nEndpoint:   GET /api/2.1/jobs/list" +
+				"\n\nYour host is an account console URL; workspace commands require a workspace URL (https://adb-<workspace-id>.<random>.azuredatabricks.net)" +
+				"\n\nNext steps:" +
+				"\n  - Check your identity: databricks auth describe --profile acc",
+		},
+		{
+			name:   "account API on workspace host",
+			cfg:    &config.Config{Host: "https://my-workspace.cloud.databricks.com", Profile: "dev", AuthType: AuthTypePat},
+			status: http.StatusNotFound,
+			url:    "https://my-workspace.cloud.databricks.com/api/2.0/accounts/abc/scim/v2/Users",
+			wantMsg: "test error message\n" +
+				"\nProfile:    dev" +
+				"\nHost:       https://my-workspace.cloud.databricks.com" +
+				"\nAuth type:  Personal Access Token (pat)" +
+				"\nEndpoint:   GET /api/2.0/accounts/abc/scim/v2/Users" +
+				"\n\nYour host is a workspace URL; account commands require an account console URL (https://accounts.cloud.databricks.com)" +
+				"\n\nNext steps:" +
+				"\n  - Check your identity: databricks auth describe --profile dev",
+		},
+		{
+			name:    "404 without mismatch is unchanged",
+			cfg:     &config.Config{Host: "https://my-workspace.cloud.databricks.com", Profile: "dev"},
+			status:  http.StatusNotFound,
+			url:     "https://my-workspace.cloud.databricks.com/api/2.1/jobs/get",
+			wantMsg: "test error message",
+		},
+		{
+			name:    "account API on account host returns 404",
+			cfg:     &config.Config{Host: "https://accounts.cloud.databricks.com", Profile: "acc"},
+			status:  http.StatusNotFound,
+			url:     "https://accounts.cloud.databricks.com/api/2.0/accounts/abc/workspaces/123",
+			wantMsg: "test error message",
+		},
+		{
+			name:    "unified host never mismatches",
+			cfg:     &config.Config{Host: "https://unified.cloud.databricks.com", Experimental_IsUnifiedHost: true},
+			status:  http.StatusNotFound,
+			url:     "https://unified.cloud.databricks.com/api/2.0/accounts/abc/scim/v2/Users",
+			wantMsg: "test error message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := EnrichAuthError(t.Context(), tt.cfg, newAPIError(t, tt.status, tt.url))
+			assert.Equal(t, tt.wantMsg, result.Error())
+		})
+	}
+}
+
+func TestRewriteAuthError(t *testing.T) {
+	refreshErr := &u2m.InvalidRefreshTokenError{}
+
+	tests := []struct {
+		name      string
+		host      string
+		accountID string
+		profile   string
+		opts      *LoginCommandOptions
+		want      *ReauthRequiredError
+	}{
+		{
+			name:    "profile",
+			host:    "https://my-workspace.cloud.databricks.com",
+			profile: "dev",
+			want: &ReauthRequiredError{
+				Host:         "https://my-workspace.cloud.databricks.com",
+				Profile:      "dev",
+				LoginCommand: "databricks auth login --profile dev",
+				Err:          refreshErr,
+			},
+		},
+		{
+			name:      "account host",
+			host:      "https://accounts.cloud.databricks.com",
+			accountID: "01234567-89ab-cdef-0123-456789abcdef",
+			want: &ReauthRequiredError{
+				Host:         "https://accounts.cloud.databricks.com",
+				AccountID:    "01234567-89ab-cdef-0123-456789abcdef",
+				LoginCommand: "databricks auth login --host https://accounts.cloud.databricks.com --account-id 01234567-89ab-cdef-0123-456789abcdef",
+				Err:          refreshErr,
+			},
+		},
+		{
+			name:    "profile with scopes and config file",
+			host:    "https://my-workspace.cloud.databricks.com",
+			profile: "dev",
+			opts: &LoginCommandOptions{
+				Scopes:     []string{"sql", "clusters"},
+				ConfigFile: "/tmp/my config",
+			},
+			want: &ReauthRequiredError{
+				Host:         "https://my-workspace.cloud.databricks.com",
+				Profile:      "dev",
+				LoginCommand: "DATABRICKS_CONFIG_FILE='/tmp/my config' databricks auth login --profile dev --scopes sql,clusters",
+				Err:          refreshErr,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rewritten, err := RewriteAuthError(t.Context(), tt.host, tt.accountID, tt.profile, tt.opts, refreshErr)
+			require.True(t, rewritten)
+
+			var reauthErr *ReauthRequiredError
+			require.ErrorAs(t, err, &reauthErr)
+			assert.Equal(t, tt.want, reauthErr)
+			assert.ErrorIs(t, err, refreshErr)
+			assert.Equal(t, "A new access token could not be retrieved because the refresh token is invalid. To reauthenticate, run the following command:\n  $ "+tt.want.LoginCommand, err.Error())
+		})
+	}
+}
+
+func TestRewriteAuthError_OtherErrors(t *testing.T) {
+	original := errors.New("some other error")
+	rewritten, err := RewriteAuthError(t.Context(), "https://my-workspace.cloud.databricks.com", "", "dev", nil, original)
+	assert.False(t, rewritten)
+	assert.Equal(t, original, err)
+
+	var reauthErr *ReauthRequiredError
+	assert.False(t, errors.As(err, &reauthErr))
+}
+
+func registerTestAuthType(t *testing.T, name, displayName string, reauthHint func(cfg *config.Config) string) {
+	RegisterAuthTypeInfo(name, displayName, reauthHint)
+	t.Cleanup(func() {
+		registeredAuthTypesMu.Lock()
+		defer registeredAuthTypesMu.Unlock()
+		delete(registeredAuthTypes, name)
+	})
+}
+
+func TestRegisterAuthTypeInfo(t *testing.T) {
+	registerTestAuthType(t, "fake-broker", "Fake OIDC Broker (fake-broker)", func(cfg *config.Config) string {
+		return "Re-authenticate with the broker: fake-broker login --profile " + cfg.Profile
+	})
+
+	assert.Equal(t, "Fake OIDC Broker (fake-broker)", AuthTypeDisplayName("fake-broker"))
+	assert.Equal(t, "Fake OIDC Broker (fake-broker)", AuthTypeDisplayName("FAKE-BROKER"))
+
+	cfg := &config.Config{
+		Host:     "https://my-workspace.cloud.databricks.com",
+		Profile:  "dev",
+		AuthType: "fake-broker",
+	}
+	err := EnrichAuthError(t.Context(), cfg, &apierr.APIError{StatusCode: http.StatusUnauthorized, Message: "test error message"})
+	assert.Equal(t, "test error message\n"+
+		"\nProfile:    dev"+
+		"\nHost:       https://my-workspace.cloud.databricks.com"+
+		"\nAuth type:  Fake OIDC Broker (fake-broker)"+
+		"\n\nNext steps:"+
+		"\n  - Re-authenticate with the broker: fake-broker login --profile dev"+
+		"\n  - Check your identity: databricks auth describe --profile dev", err.Error())
+}
+
+func TestRegisterAuthTypeInfo_EmptyHintFallsBack(t *testing.T) {
+	registerTestAuthType(t, "fake-silent", "", func(cfg *config.Config) string { return "" })
+
+	assert.Equal(t, "fake-silent", AuthTypeDisplayName("fake-silent"))
+
+	cfg := &config.Config{Profile: "dev", AuthType: "fake-silent"}
+	err := EnrichAuthError(t.Context(), cfg, &apierr.APIError{StatusCode: http.StatusUnauthorized, Message: "test error message"})
+	assert.Contains(t, err.Error(), "\n  - Check your authentication credentials")
+}
+
+func TestRegisterAuthTypeInfo_BuiltinTakesPrecedence(t *testing.T) {
+	registerTestAuthType(t, AuthTypePat, "Something else", nil)
+	assert.Equal(t, "Personal Access Token (pat)", AuthTypeDisplayName(AuthTypePat))
+}
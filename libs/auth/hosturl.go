@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/databricks-sdk-go/common/environment"
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// HostURL is a Databricks URL parsed with [ParseHostURL].
+type HostURL struct {
+	// Host is the canonical host: the scheme and host name of the URL.
+	Host string
+
+	// Cloud is the cloud of the host. Hosts that aren't recognized are
+	// assumed to be on AWS.
+	Cloud environment.Cloud
+
+	// IsAccount is true if the URL points at an account console rather than
+	// at a workspace.
+	IsAccount bool
+
+	// AccountID extracted from ?a= or ?account_id= of an account console URL.
+	AccountID string
+}
+
+// ParseHostURL parses a URL that is copied from the browser, such as
+// https://adb-1234.5.azuredatabricks.net/?o=1234#job/567, into the canonical
+// host of the workspace or account console it points at. The scheme may be
+// omitted, in which case https is assumed. The path, the query parameters,
+// such as the legacy ?o= organization ID, and the fragment are stripped, and
+// the host is normalized with [databrickscfg.NormalizeHost].
+func ParseHostURL(rawURL string) (HostURL, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return HostURL{}, fmt.Errorf("invalid URL %q: %w", rawURL, err)
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return HostURL{}, fmt.Errorf("invalid URL %q: unsupported scheme %s", rawURL, u.Scheme)
+	}
+	if u.Host == "" {
+		return HostURL{}, fmt.Errorf("invalid URL %q: no host", rawURL)
+	}
+
+	host := databrickscfg.NormalizeHost(rawURL)
+	cfg := &config.Config{Host: host}
+	parsed := HostURL{
+		Host:      host,
+		Cloud:     cfg.Environment().Cloud,
+		IsAccount: cfg.HostType() == config.AccountHost,
+	}
+	if parsed.IsAccount {
+		parsed.AccountID = ExtractHostQueryParams(rawURL).AccountID
+	}
+	return parsed, nil
+}
@@ -0,0 +1,117 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go/common/environment"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseHostURL(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want HostURL
+	}{
+		{
+			name: "AWS workspace",
+			url:  "https://dbc-1234.cloud.databricks.com",
+			want: HostURL{Host: "https://dbc-1234.cloud.databricks.com", Cloud: environment.CloudAWS},
+		},
+		{
+			name: "AWS workspace with path",
+			url:  "https://dbc-1234.cloud.databricks.com/compute/clusters/0123-456789-abcdef",
+			want: HostURL{Host: "https://dbc-1234.cloud.databricks.com", Cloud: environment.CloudAWS},
+		},
+		{
+			name: "Azure workspace with org ID and fragment",
+			url:  "https://adb-1234.5.azuredatabricks.net/?o=1234#job/567",
+			want: HostURL{Host: "https://adb-1234.5.azuredatabricks.net", Cloud: environment.CloudAzure},
+		},
+		{
+			name: "Azure workspace without scheme",
+			url:  "  adb-1234.5.azuredatabricks.net/?o=1234  ",
+			want: HostURL{Host: "https://adb-1234.5.azuredatabricks.net", Cloud: environment.CloudAzure},
+		},
+		{
+			name: "Azure workspace with login path",
+			url:  "https://ADB-1234.5.azuredatabricks.net/aad/auth",
+			want: HostURL{Host: "https://adb-1234.5.azuredatabricks.net", Cloud: environment.CloudAzure},
+		},
+		{
+			name: "GCP workspace with default port",
+			url:  "https://1234.5.gcp.databricks.com:443/",
+			want: HostURL{Host: "https://1234.5.gcp.databricks.com", Cloud: environment.CloudGCP},
+		},
+		{
+			name: "GCP workspace",
+			url:  "https://1234.5.gcp.databricks.com/browse?o=1234",
+			want: HostURL{Host: "https://1234.5.gcp.databricks.com", Cloud: environment.CloudGCP},
+		},
+		{
+			name: "upper case host",
+			url:  "HTTPS://DBC-1234.Cloud.Databricks.com/",
+			want: HostURL{Host: "https://dbc-1234.cloud.databricks.com", Cloud: environment.CloudAWS},
+		},
+		{
+			name: "AWS accounts console",
+			url:  "https://accounts.cloud.databricks.com/workspaces?account_id=abc-123",
+			want: HostURL{Host: "https://accounts.cloud.databricks.com", Cloud: environment.CloudAWS, IsAccount: true, AccountID: "abc-123"},
+		},
+		{
+			name: "Azure accounts console",
+			url:  "https://accounts.azuredatabricks.net/",
+			want: HostURL{Host: "https://accounts.azuredatabricks.net", Cloud: environment.CloudAzure, IsAccount: true},
+		},
+		{
+			name: "GCP accounts console",
+			url:  "accounts.gcp.databricks.com/?a=abc-123",
+			want: HostURL{Host: "https://accounts.gcp.databricks.com", Cloud: environment.CloudGCP, IsAccount: true, AccountID: "abc-123"},
+		},
+		{
+			name: "local host",
+			url:  "http://localhost:8080/?o=1234",
+			want: HostURL{Host: "http://localhost:8080", Cloud: environment.CloudAWS},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseHostURL(tt.url)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseHostURLInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		url     string
+		wantErr string
+	}{
+		{
+			name:    "empty",
+			url:     "",
+			wantErr: `invalid URL "https://": no host`,
+		},
+		{
+			name:    "unsupported scheme",
+			url:     "ftp://dbc-1234.cloud.databricks.com",
+			wantErr: `invalid URL "ftp://dbc-1234.cloud.databricks.com": unsupported scheme ftp`,
+		},
+		{
+			name:    "malformed",
+			url:     "https://dbc-1234.cloud.databricks.com:port",
+			wantErr: `invalid URL "https://dbc-1234.cloud.databricks.com:port"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseHostURL(tt.url)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
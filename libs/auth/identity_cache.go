@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// identityCacheFilePath is the location of the identity cache relative to the
+// home directory.
+const identityCacheFilePath = ".databricks/identity-cache.json"
+
+const identityCacheVersion = 1
+
+// CachedIdentity is the identity last returned by the API for a profile or
+// host. It lets commands describe the identity when the API is unreachable.
+type CachedIdentity struct {
+	Username  string    `json:"username,omitempty"`
+	AccountID string    `json:"account_id,omitempty"`
+	Time      time.Time `json:"time"`
+}
+
+// IdentityStore persists [CachedIdentity] by profile name or host.
+type IdentityStore struct {
+	store *jsonStore[*CachedIdentity]
+}
+
+// NewIdentityStore returns a store backed by the file at path.
+func NewIdentityStore(path string) *IdentityStore {
+	return &IdentityStore{store: newJSONStore[*CachedIdentity](path, identityCacheVersion, "identities")}
+}
+
+// DefaultIdentityStore returns the store next to the default token cache.
+func DefaultIdentityStore() (*IdentityStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed loading home directory: %w", err)
+	}
+	return NewIdentityStore(filepath.Join(home, identityCacheFilePath)), nil
+}
+
+// IdentityCacheKey returns the key under which the identity for cfg is
+// cached: the profile name if set, and the host otherwise.
+func IdentityCacheKey(cfg *config.Config) string {
+	if cfg.Profile != "" {
+		return cfg.Profile
+	}
+	return cfg.CanonicalHostName()
+}
+
+// Lookup returns the identity cached under key, or nil if there is none.
+func (s *IdentityStore) Lookup(key string) (*CachedIdentity, error) {
+	identities, err := s.store.load()
+	if err != nil {
+		return nil, err
+	}
+	return identities[key], nil
+}
+
+// Store caches the identity under key.
+func (s *IdentityStore) Store(key string, id CachedIdentity) error {
+	return s.store.update(func(identities map[string]*CachedIdentity) bool {
+		identities[key] = &id
+		return true
+	})
+}
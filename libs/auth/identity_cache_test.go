@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdentityStore(t *testing.T) {
+	store := NewIdentityStore(filepath.Join(t.TempDir(), "identity-cache.json"))
+
+	id, err := store.Lookup("dev")
+	require.NoError(t, err)
+	assert.Nil(t, id)
+
+	asOf := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, store.Store("dev", CachedIdentity{Username: "user@example.com", Time: asOf}))
+	require.NoError(t, store.Store("acct", CachedIdentity{AccountID: "abc", Time: asOf}))
+
+	id, err = store.Lookup("dev")
+	require.NoError(t, err)
+	assert.Equal(t, &CachedIdentity{Username: "user@example.com", Time: asOf}, id)
+
+	id, err = store.Lookup("acct")
+	require.NoError(t, err)
+	assert.Equal(t, &CachedIdentity{AccountID: "abc", Time: asOf}, id)
+}
+
+func TestIdentityStoreUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "identity-cache.json")
+	err := os.WriteFile(path, []byte(`{"version": 2, "identities": {"dev": {"username": "user"}}}`), 0o600)
+	require.NoError(t, err)
+
+	id, err := NewIdentityStore(path).Lookup("dev")
+	require.NoError(t, err)
+	assert.Nil(t, id)
+}
+
+func TestIdentityCacheKey(t *testing.T) {
+	assert.Equal(t, "dev", IdentityCacheKey(&config.Config{Profile: "dev", Host: "https://dev.cloud.databricks.com"}))
+	assert.Equal(t, "https://dev.cloud.databricks.com", IdentityCacheKey(&config.Config{Host: "dev.cloud.databricks.com/"}))
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/databricks/cli/libs/fileutil"
+)
+
+// jsonStoreLockTimeout is how long [jsonStore.update] waits for another
+// process to release the lock on the file. A lock that is older than
+// jsonStoreStaleLock is left behind by a process that didn't finish, and is
+// removed.
+const (
+	jsonStoreLockTimeout = time.Second
+	jsonStoreStaleLock   = 10 * time.Second
+)
+
+// jsonStore is a JSON file of entries by key, next to the token cache. The
+// file holds a version and the entries under a field name of the store:
+//
+//	{
+//	  "version": 1,
+//	  "<field>": {"<key>": <entry>}
+//	}
+//
+// Reads don't lock, because writes replace the file atomically. Updates from
+// concurrent processes are serialized with a lock file next to the file.
+type jsonStore[T any] struct {
+	path    string
+	version int
+	field   string
+
+	mu sync.Mutex
+}
+
+func newJSONStore[T any](path string, version int, field string) *jsonStore[T] {
+	return &jsonStore[T]{path: path, version: version, field: field}
+}
+
+// load reads the entries of the file. A missing file or a file with an
+// unknown version is treated as empty.
+func (s *jsonStore[T]) load() (map[string]T, error) {
+	raw, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return map[string]T{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read: %w", err)
+	}
+	var f map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &f); err != nil {
+		return nil, fmt.Errorf("parse: %w", err)
+	}
+	var version int
+	if v, ok := f["version"]; ok {
+		if err := json.Unmarshal(v, &version); err != nil {
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+	}
+	entries := map[string]T{}
+	if version != s.version {
+		return entries, nil
+	}
+	if v, ok := f[s.field]; ok {
+		if err := json.Unmarshal(v, &entries); err != nil {
+			return nil, fmt.Errorf("parse: %w", err)
+		}
+	}
+	if entries == nil {
+		entries = map[string]T{}
+	}
+	return entries, nil
+}
+
+// update applies fn to the entries of the file while holding the lock, and
+// writes the result if fn returns true.
+func (s *jsonStore[T]) update(fn func(entries map[string]T) bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	entries, err := s.load()
+	if err != nil {
+		return err
+	}
+	if !fn(entries) {
+		return nil
+	}
+	raw, err := json.MarshalIndent(jsonStoreFile[T]{version: s.version, field: s.field, entries: entries}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal: %w", err)
+	}
+	return writeFileAtomic(s.path, raw)
+}
+
+// remove deletes the file while holding the lock. A missing file is not an
+// error.
+func (s *jsonStore[T]) remove() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	unlock, err := s.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = os.Remove(s.path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// lock creates the lock file of the store, waiting up to
+// [jsonStoreLockTimeout] for another process to release it. It returns a
+// function that releases the lock.
+func (s *jsonStore[T]) lock() (func(), error) {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o700); err != nil {
+		return nil, fmt.Errorf("mkdir: %w", err)
+	}
+	path := s.path + ".lock"
+	deadline := time.Now().Add(jsonStoreLockTimeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			f.Close()
+			return func() { _ = os.Remove(path) }, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("lock: %w", err)
+		}
+		if info, err := os.Stat(path); err == nil && time.Since(info.ModTime()) > jsonStoreStaleLock {
+			_ = os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("lock: %s is held by another process", path)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// jsonStoreFile is the content of the file of a [jsonStore]. It writes the
+// version before the entries.
+type jsonStoreFile[T any] struct {
+	version int
+	field   string
+	entries map[string]T
+}
+
+func (f jsonStoreFile[T]) MarshalJSON() ([]byte, error) {
+	field, err := json.Marshal(f.field)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := json.Marshal(f.entries)
+	if err != nil {
+		return nil, err
+	}
+	return fmt.Appendf(nil, `{"version":%d,%s:%s}`, f.version, field, entries), nil
+}
+
+// writeFileAtomic creates the directory of path and replaces the file at path
+// with data, see [fileutil.WriteFile].
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("mkdir: %w", err)
+	}
+	return fileutil.WriteFile(path, 0o600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONStoreFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "store.json")
+	s := newJSONStore[int](path, 3, "counts")
+
+	entries, err := s.load()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, s.update(func(entries map[string]int) bool {
+		entries["a"] = 1
+		return true
+	}))
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"version\": 3,\n  \"counts\": {\n    \"a\": 1\n  }\n}", string(raw))
+
+	// The file is not written if fn returns false.
+	require.NoError(t, s.update(func(entries map[string]int) bool {
+		entries["b"] = 2
+		return false
+	}))
+	entries, err = s.load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1}, entries)
+}
+
+func TestJSONStoreIgnoresUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 2, "counts": {"a": 1}}`), 0o600))
+
+	entries, err := newJSONStore[int](path, 1, "counts").load()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestJSONStoreConcurrentStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+
+	// Stores of different processes only share the lock file.
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			s := newJSONStore[int](path, 1, "counts")
+			assert.NoError(t, s.update(func(entries map[string]int) bool {
+				entries[fmt.Sprintf("key-%d", i)] = i
+				return true
+			}))
+		})
+	}
+	wg.Wait()
+
+	entries, err := newJSONStore[int](path, 1, "counts").load()
+	require.NoError(t, err)
+	assert.Len(t, entries, 10)
+}
+
+func TestJSONStoreLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	s := newJSONStore[int](path, 1, "counts")
+	set := func(entries map[string]int) bool {
+		entries["a"] = 1
+		return true
+	}
+
+	// A lock held by another process makes the update fail.
+	require.NoError(t, os.WriteFile(path+".lock", nil, 0o600))
+	assert.ErrorContains(t, s.update(set), "held by another process")
+	assert.ErrorContains(t, s.remove(), "held by another process")
+
+	// A stale lock is removed.
+	stale := time.Now().Add(-time.Minute)
+	require.NoError(t, os.Chtimes(path+".lock", stale, stale))
+	require.NoError(t, s.update(set))
+	assert.NoFileExists(t, path+".lock")
+
+	entries, err := s.load()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"a": 1}, entries)
+
+	require.NoError(t, s.remove())
+	assert.NoFileExists(t, path)
+	require.NoError(t, s.remove())
+}
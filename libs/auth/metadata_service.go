@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// metadataServiceTimeout matches the timeout of the SDK for token requests to
+// the metadata service.
+const metadataServiceTimeout = 10 * time.Second
+
+// MetadataServiceProbe is the result of requesting a token from the metadata
+// service configured for metadata-service auth. It never contains the token.
+type MetadataServiceProbe struct {
+	URL  string `json:"url"`
+	Host string `json:"host"`
+
+	// StatusCode is the HTTP status code of the response, or zero if the
+	// request failed.
+	StatusCode int `json:"status_code,omitempty"`
+
+	TokenType string `json:"token_type,omitempty"`
+
+	// Expiry is when the returned token expires.
+	Expiry time.Time `json:"expiry,omitzero"`
+
+	// Error explains why no usable token was returned.
+	Error string `json:"error,omitempty"`
+}
+
+// metadataServiceResponse is the response of the metadata service.
+type metadataServiceResponse struct {
+	AccessToken string      `json:"access_token"`
+	TokenType   string      `json:"token_type"`
+	ExpiresOn   json.Number `json:"expires_on"`
+}
+
+// ProbeMetadataService requests a token for the host of cfg from the metadata
+// service at cfg.MetadataServiceURL, the same way metadata-service auth does.
+// Failures of the request are reported in the probe. An error is returned only
+// if cfg does not configure a metadata service.
+func ProbeMetadataService(ctx context.Context, cfg *config.Config) (*MetadataServiceProbe, error) {
+	if cfg.MetadataServiceURL == "" {
+		return nil, errors.New("no metadata service is configured; set DATABRICKS_METADATA_SERVICE_URL or metadata_service_url in your profile")
+	}
+	probe := &MetadataServiceProbe{
+		URL:  cfg.MetadataServiceURL,
+		Host: cfg.Host,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, metadataServiceTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, cfg.MetadataServiceURL, nil)
+	if err != nil {
+		probe.Error = fmt.Sprintf("invalid metadata service URL: %v", err)
+		return probe, nil
+	}
+	req.Header.Set(config.MetadataServiceVersionHeader, config.MetadataServiceVersion)
+	req.Header.Set(config.MetadataServiceHostHeader, cfg.Host)
+
+	client := &http.Client{Transport: cfg.HTTPTransport}
+	resp, err := client.Do(req)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe, nil
+	}
+	defer resp.Body.Close()
+
+	probe.StatusCode = resp.StatusCode
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		probe.Error = fmt.Sprintf("cannot read response: %v", err)
+		return probe, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		probe.Error = fmt.Sprintf("unexpected response %s", resp.Status)
+		return probe, nil
+	}
+
+	var token metadataServiceResponse
+	err = json.Unmarshal(body, &token)
+	if err != nil {
+		probe.Error = fmt.Sprintf("cannot parse response: %v", err)
+		return probe, nil
+	}
+	probe.TokenType = token.TokenType
+	if token.AccessToken == "" {
+		probe.Error = "response does not contain an access token"
+		return probe, nil
+	}
+	epoch, err := token.ExpiresOn.Int64()
+	if err != nil {
+		probe.Error = fmt.Sprintf("invalid token expiry %q", token.ExpiresOn)
+		return probe, nil
+	}
+	probe.Expiry = time.Unix(epoch, 0)
+	return probe, nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProbeMetadataService(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		body       string
+		wantStatus int
+		wantType   string
+		wantExpiry time.Time
+		wantError  string
+	}{
+		{
+			name:       "token",
+			status:     http.StatusOK,
+			body:       `{"access_token": "abc", "token_type": "Bearer", "expires_on": 1767225600}`,
+			wantStatus: http.StatusOK,
+			wantType:   "Bearer",
+			wantExpiry: time.Unix(1767225600, 0),
+		},
+		{
+			name:       "error status",
+			status:     http.StatusUnauthorized,
+			body:       `{"error": "unknown host"}`,
+			wantStatus: http.StatusUnauthorized,
+			wantError:  "unexpected response 401 Unauthorized",
+		},
+		{
+			name:       "no token",
+			status:     http.StatusOK,
+			body:       `{"token_type": "Bearer", "expires_on": 1767225600}`,
+			wantStatus: http.StatusOK,
+			wantType:   "Bearer",
+			wantError:  "response does not contain an access token",
+		},
+		{
+			name:       "fractional expiry",
+			status:     http.StatusOK,
+			body:       `{"access_token": "abc", "token_type": "Bearer", "expires_on": 1767225600.5}`,
+			wantStatus: http.StatusOK,
+			wantType:   "Bearer",
+			wantError:  `invalid token expiry "1767225600.5"`,
+		},
+		{
+			name:       "malformed response",
+			status:     http.StatusOK,
+			body:       `not json`,
+			wantStatus: http.StatusOK,
+			wantError:  "cannot parse response: invalid character 'o' in literal null (expecting 'u')",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, config.MetadataServiceVersion, r.Header.Get(config.MetadataServiceVersionHeader))
+				assert.Equal(t, "https://example.cloud.databricks.com", r.Header.Get(config.MetadataServiceHostHeader))
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cfg := &config.Config{
+				Host:               "https://example.cloud.databricks.com",
+				MetadataServiceURL: server.URL,
+			}
+			probe, err := ProbeMetadataService(t.Context(), cfg)
+			require.NoError(t, err)
+			assert.Equal(t, &MetadataServiceProbe{
+				URL:        server.URL,
+				Host:       cfg.Host,
+				StatusCode: tt.wantStatus,
+				TokenType:  tt.wantType,
+				Expiry:     tt.wantExpiry,
+				Error:      tt.wantError,
+			}, probe)
+		})
+	}
+}
+
+func TestProbeMetadataService_Unreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	probe, err := ProbeMetadataService(t.Context(), &config.Config{
+		Host:               "https://example.cloud.databricks.com",
+		MetadataServiceURL: server.URL,
+	})
+	require.NoError(t, err)
+	assert.Zero(t, probe.StatusCode)
+	assert.Contains(t, probe.Error, "connection refused")
+}
+
+func TestProbeMetadataService_NotConfigured(t *testing.T) {
+	_, err := ProbeMetadataService(t.Context(), &config.Config{Host: "https://example.cloud.databricks.com"})
+	assert.ErrorContains(t, err, "no metadata service is configured")
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// PinnedWorkspaceIDKey is the profile key that stores the ID of the workspace
+// a profile is pinned to. The SDK ignores unknown keys, so profiles with a
+// pin remain usable by other tools.
+const PinnedWorkspaceIDKey = "pinned_workspace_id"
+
+// WorkspaceIDGetter returns the ID of the workspace a client talks to.
+// It is implemented by [databricks.WorkspaceClient].
+type WorkspaceIDGetter interface {
+	CurrentWorkspaceID(ctx context.Context) (int64, error)
+}
+
+// WorkspacePinMismatchError is returned when the workspace behind a profile's
+// host is not the workspace the profile is pinned to. This happens if the
+// hostname now points at a different workspace, for example after the
+// workspace was re-created or the DNS record was taken over.
+type WorkspacePinMismatchError struct {
+	Profile           string
+	Host              string
+	PinnedWorkspaceID string
+	ActualWorkspaceID string
+}
+
+func (e *WorkspacePinMismatchError) Error() string {
+	return fmt.Sprintf("profile %q is pinned to workspace ID %s, but %s belongs to workspace ID %s. "+
+		"If the workspace was intentionally replaced, run `databricks auth login --profile %s --pin-workspace` to pin the new workspace",
+		e.Profile, e.PinnedWorkspaceID, e.Host, e.ActualWorkspaceID, e.Profile)
+}
+
+// FetchWorkspacePin returns the ID of the workspace behind w in the format
+// it is stored in the profile.
+func FetchWorkspacePin(ctx context.Context, w WorkspaceIDGetter) (string, error) {
+	id, err := w.CurrentWorkspaceID(ctx)
+	if err != nil {
+		return "", fmt.Errorf("cannot determine workspace ID: %w", err)
+	}
+	return strconv.FormatInt(id, 10), nil
+}
+
+// LoadWorkspacePin returns the workspace ID the profile in cfg is pinned to.
+// It returns an empty string if cfg does not use a profile or the profile is
+// not pinned. Profiles of included config files are considered as well. It
+// returns an error if the config file cannot be read, so that the check is
+// not skipped.
+func LoadWorkspacePin(ctx context.Context, cfg *config.Config) (string, error) {
+	if cfg.Profile == "" {
+		return "", nil
+	}
+	return databrickscfg.GetProfileValue(ctx, cfg.Profile, cfg.ConfigFile, PinnedWorkspaceIDKey)
+}
+
+// VerifyWorkspacePin checks that the workspace behind w is the pinned
+// workspace. Unpinned profiles (an empty pin) are not verified and no
+// request is made.
+func VerifyWorkspacePin(ctx context.Context, w WorkspaceIDGetter, cfg *config.Config, pinned string) error {
+	if pinned == "" {
+		return nil
+	}
+	actual, err := FetchWorkspacePin(ctx, w)
+	if err != nil {
+		return err
+	}
+	if actual != pinned {
+		return &WorkspacePinMismatchError{
+			Profile:           cfg.Profile,
+			Host:              cfg.Host,
+			PinnedWorkspaceID: pinned,
+			ActualWorkspaceID: actual,
+		}
+	}
+	return nil
+}
+
+// VerifyProfileWorkspacePin loads the pin of the profile cfg was resolved
+// from and verifies it against the workspace behind w.
+func VerifyProfileWorkspacePin(ctx context.Context, w WorkspaceIDGetter, cfg *config.Config) error {
+	pinned, err := LoadWorkspacePin(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	return VerifyWorkspacePin(ctx, w, cfg, pinned)
+}
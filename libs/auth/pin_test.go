@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeWorkspaceIDGetter struct {
+	id    int64
+	err   error
+	calls int
+}
+
+func (f *fakeWorkspaceIDGetter) CurrentWorkspaceID(ctx context.Context) (int64, error) {
+	f.calls++
+	return f.id, f.err
+}
+
+func TestFetchWorkspacePin(t *testing.T) {
+	id, err := FetchWorkspacePin(t.Context(), &fakeWorkspaceIDGetter{id: 1234567890})
+	require.NoError(t, err)
+	assert.Equal(t, "1234567890", id)
+
+	_, err = FetchWorkspacePin(t.Context(), &fakeWorkspaceIDGetter{err: errors.New("boom")})
+	assert.ErrorContains(t, err, "cannot determine workspace ID: boom")
+}
+
+func TestVerifyWorkspacePin(t *testing.T) {
+	cfg := &config.Config{Profile: "prod", Host: "https://prod.cloud.databricks.com"}
+
+	t.Run("match", func(t *testing.T) {
+		w := &fakeWorkspaceIDGetter{id: 123}
+		err := VerifyWorkspacePin(t.Context(), w, cfg, "123")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, w.calls)
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		w := &fakeWorkspaceIDGetter{id: 456}
+		err := VerifyWorkspacePin(t.Context(), w, cfg, "123")
+
+		var mismatch *WorkspacePinMismatchError
+		require.ErrorAs(t, err, &mismatch)
+		assert.Equal(t, &WorkspacePinMismatchError{
+			Profile:           "prod",
+			Host:              "https://prod.cloud.databricks.com",
+			PinnedWorkspaceID: "123",
+			ActualWorkspaceID: "456",
+		}, mismatch)
+		assert.ErrorContains(t, err, "pinned to workspace ID 123")
+		assert.ErrorContains(t, err, "belongs to workspace ID 456")
+	})
+
+	t.Run("unpinned", func(t *testing.T) {
+		w := &fakeWorkspaceIDGetter{err: errors.New("must not be called")}
+		err := VerifyWorkspacePin(t.Context(), w, cfg, "")
+		assert.NoError(t, err)
+		assert.Equal(t, 0, w.calls)
+	})
+
+	t.Run("lookup error", func(t *testing.T) {
+		w := &fakeWorkspaceIDGetter{err: errors.New("boom")}
+		err := VerifyWorkspacePin(t.Context(), w, cfg, "123")
+		assert.ErrorContains(t, err, "boom")
+	})
+}
+
+func TestLoadWorkspacePin(t *testing.T) {
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, ".databrickscfg")
+	err := os.WriteFile(configFile, []byte(`[DEFAULT]
+include = included.cfg
+
+[pinned]
+host = https://pinned.cloud.databricks.com
+pinned_workspace_id = 123
+
+[unpinned]
+host = https://unpinned.cloud.databricks.com
+`), 0o600)
+	require.NoError(t, err)
+	err = os.WriteFile(filepath.Join(dir, "included.cfg"), []byte(`[included]
+host = https://included.cloud.databricks.com
+pinned_workspace_id = 456
+`), 0o600)
+	require.NoError(t, err)
+	t.Setenv("TEST_PIN_CONFIG_DIR", dir)
+
+	cases := []struct {
+		name    string
+		profile string
+		file    string
+		want    string
+	}{
+		{name: "pinned profile", profile: "pinned", file: configFile, want: "123"},
+		{name: "unpinned profile", profile: "unpinned", file: configFile, want: ""},
+		{name: "unknown profile", profile: "unknown", file: configFile, want: ""},
+		{name: "no profile", profile: "", file: configFile, want: ""},
+		{name: "profile of included file", profile: "included", file: configFile, want: "456"},
+		{name: "path with environment variable", profile: "pinned", file: "$TEST_PIN_CONFIG_DIR/.databrickscfg", want: "123"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := LoadWorkspacePin(t.Context(), &config.Config{Profile: tc.profile, ConfigFile: tc.file})
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+
+	// A config file that cannot be read fails the check instead of skipping it.
+	_, err = LoadWorkspacePin(t.Context(), &config.Config{Profile: "pinned", ConfigFile: filepath.Join(dir, "missing")})
+	assert.ErrorContains(t, err, "cannot read config file")
+}
+
+func TestVerifyProfileWorkspacePin(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), ".databrickscfg")
+	err := os.WriteFile(configFile, []byte("[pinned]\npinned_workspace_id = 123\n"), 0o600)
+	require.NoError(t, err)
+
+	cfg := &config.Config{Profile: "pinned", ConfigFile: configFile}
+	assert.NoError(t, VerifyProfileWorkspacePin(t.Context(), &fakeWorkspaceIDGetter{id: 123}, cfg))
+
+	var mismatch *WorkspacePinMismatchError
+	err = VerifyProfileWorkspacePin(t.Context(), &fakeWorkspaceIDGetter{id: 456}, cfg)
+	assert.ErrorAs(t, err, &mismatch)
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
+)
+
+// profileUsageFilePath is the location of the profile usage file relative to
+// the home directory. Usage is kept out of .databrickscfg so that using a
+// profile doesn't rewrite the config file.
+const profileUsageFilePath = ".databricks/profile-usage.json"
+
+const profileUsageVersion = 1
+
+// profileUsageResolution is how much later than the recorded time a profile
+// must be used for the usage to be written again. Usage is recorded on every
+// command, and rewriting the file each time isn't worth the cost.
+const profileUsageResolution = time.Minute
+
+// ProfileUsageStore persists when profiles were last used, by profile name.
+// Like the token cache, it is keyed by profile name only.
+type ProfileUsageStore struct {
+	store *jsonStore[time.Time]
+}
+
+// NewProfileUsageStore returns a store backed by the file at path.
+func NewProfileUsageStore(path string) *ProfileUsageStore {
+	return &ProfileUsageStore{store: newJSONStore[time.Time](path, profileUsageVersion, "profiles")}
+}
+
+// DefaultProfileUsageStore returns the store next to the default token cache.
+func DefaultProfileUsageStore(ctx context.Context) (*ProfileUsageStore, error) {
+	home, err := env.UserHomeDir(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed loading home directory: %w", err)
+	}
+	return NewProfileUsageStore(filepath.Join(home, profileUsageFilePath)), nil
+}
+
+// LastUsed returns when each profile was last used. Profiles that were never
+// used are not included.
+func (s *ProfileUsageStore) LastUsed() (map[string]time.Time, error) {
+	return s.store.load()
+}
+
+// Record sets the time profile was last used to t. The file is not written if
+// t is less than [profileUsageResolution] after the recorded time.
+func (s *ProfileUsageStore) Record(profile string, t time.Time) error {
+	// Check without the lock first, so that most commands only read the file.
+	lastUsed, err := s.store.load()
+	if err != nil {
+		return err
+	}
+	if prev, ok := lastUsed[profile]; ok && t.Sub(prev) < profileUsageResolution {
+		return nil
+	}
+	return s.store.update(func(lastUsed map[string]time.Time) bool {
+		lastUsed[profile] = t
+		return true
+	})
+}
+
+// RecordProfileUsage records that profile is used now. Usage is
+// informational, so failures are logged and otherwise ignored.
+func RecordProfileUsage(ctx context.Context, profile string) {
+	if profile == "" {
+		return
+	}
+	s, err := DefaultProfileUsageStore(ctx)
+	if err == nil {
+		err = s.Record(profile, time.Now().UTC())
+	}
+	if err != nil {
+		log.Debugf(ctx, "Failed to record usage of profile %q: %v", profile, err)
+	}
+}
+
+// ProfilesLastUsed returns when each profile was last used. It returns an
+// empty map if usage cannot be read.
+func ProfilesLastUsed(ctx context.Context) map[string]time.Time {
+	s, err := DefaultProfileUsageStore(ctx)
+	if err != nil {
+		log.Debugf(ctx, "Failed to read profile usage: %v", err)
+		return map[string]time.Time{}
+	}
+	lastUsed, err := s.LastUsed()
+	if err != nil {
+		log.Debugf(ctx, "Failed to read profile usage: %v", err)
+		return map[string]time.Time{}
+	}
+	return lastUsed
+}
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileUsageStoreRoundTrip(t *testing.T) {
+	s := NewProfileUsageStore(filepath.Join(t.TempDir(), "nested", "usage.json"))
+
+	lastUsed, err := s.LastUsed()
+	require.NoError(t, err)
+	assert.Empty(t, lastUsed)
+
+	t1 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	t2 := t1.Add(time.Hour)
+	require.NoError(t, s.Record("dev", t1))
+	require.NoError(t, s.Record("prod", t1))
+	require.NoError(t, s.Record("dev", t2))
+
+	lastUsed, err = s.LastUsed()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"dev": t2, "prod": t1}, lastUsed)
+}
+
+func TestProfileUsageStoreIgnoresUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version": 2, "profiles": {"dev": "2026-01-02T03:04:05Z"}}`), 0o600))
+
+	lastUsed, err := NewProfileUsageStore(path).LastUsed()
+	require.NoError(t, err)
+	assert.Empty(t, lastUsed)
+}
+
+func TestProfileUsageStoreConcurrentRecords(t *testing.T) {
+	s := NewProfileUsageStore(filepath.Join(t.TempDir(), "usage.json"))
+	now := time.Now().UTC()
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Go(func() {
+			assert.NoError(t, s.Record(fmt.Sprintf("profile-%d", i), now))
+		})
+	}
+	wg.Wait()
+
+	lastUsed, err := s.LastUsed()
+	require.NoError(t, err)
+	assert.Len(t, lastUsed, 10)
+}
+
+func TestProfileUsageStoreSkipsRecentUsage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.json")
+	s := NewProfileUsageStore(path)
+
+	t1 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	require.NoError(t, s.Record("dev", t1))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	// Usage within the resolution doesn't rewrite the file.
+	require.NoError(t, os.Chtimes(path, t1, t1))
+	require.NoError(t, s.Record("dev", t1.Add(30*time.Second)))
+	after, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, t1, after.ModTime().UTC())
+	assert.Equal(t, info.Size(), after.Size())
+
+	t2 := t1.Add(2 * time.Minute)
+	require.NoError(t, s.Record("dev", t2))
+	lastUsed, err := s.LastUsed()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]time.Time{"dev": t2}, lastUsed)
+}
+
+func TestRecordProfileUsage(t *testing.T) {
+	home := t.TempDir()
+	ctx := env.WithUserHomeDir(t.Context(), home)
+
+	RecordProfileUsage(ctx, "dev")
+	RecordProfileUsage(ctx, "")
+
+	lastUsed := ProfilesLastUsed(ctx)
+	assert.Len(t, lastUsed, 1)
+	assert.WithinDuration(t, time.Now(), lastUsed["dev"], time.Minute)
+	assert.FileExists(t, filepath.Join(home, ".databricks", "profile-usage.json"))
+}
+
+func TestRecordProfileUsageIsBestEffort(t *testing.T) {
+	home := t.TempDir()
+	ctx := env.WithUserHomeDir(t.Context(), home)
+
+	// A file in place of the .databricks directory makes writes fail.
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".databricks"), nil, 0o600))
+
+	RecordProfileUsage(ctx, "dev")
+	assert.Empty(t, ProfilesLastUsed(ctx))
+}
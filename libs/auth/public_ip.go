@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/databricks/cli/libs/log"
+)
+
+// publicIPURL returns the public IP address of the caller as plain text.
+const publicIPURL = "https://checkip.amazonaws.com"
+
+// publicIPTimeout bounds the lookup. The public IP only adds context to an
+// error, so the lookup must not noticeably delay reporting it.
+const publicIPTimeout = time.Second
+
+type noNetworkDiagnosticsKey struct{}
+
+// WithoutNetworkDiagnostics returns a context in which errors are not
+// diagnosed with additional network requests, such as looking up the public
+// IP address of the caller or probing the reachability of a host.
+func WithoutNetworkDiagnostics(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noNetworkDiagnosticsKey{}, true)
+}
+
+func networkDiagnosticsEnabled(ctx context.Context) bool {
+	return ctx.Value(noNetworkDiagnosticsKey{}) == nil
+}
+
+// lookupPublicIP returns the public egress IP address of the caller. It is
+// replaced in tests.
+var lookupPublicIP = func(ctx context.Context) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, publicIPTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, publicIPURL, nil)
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected response %s", resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64))
+	if err != nil {
+		return "", err
+	}
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", errors.New("response is not an IP address")
+	}
+	return ip, nil
+}
+
+// publicIP returns the public IP address of the caller, or an empty string if
+// it cannot be determined, for example when offline.
+func publicIP(ctx context.Context) string {
+	if !networkDiagnosticsEnabled(ctx) {
+		return ""
+	}
+	// The request that failed may have used up the deadline of ctx.
+	ip, err := lookupPublicIP(context.WithoutCancel(ctx))
+	if err != nil {
+		log.Debugf(ctx, "Failed to look up the public IP address: %v", err)
+		return ""
+	}
+	return ip
+}
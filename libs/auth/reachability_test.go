@@ -0,0 +1,173 @@
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/apierr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestHostProber() *hostProber {
+	return &hostProber{
+		resolver: net.DefaultResolver,
+		dial:     (&net.Dialer{}).DialContext,
+		proxy:    func(*http.Request) (*url.URL, error) { return nil, nil },
+		timeout:  time.Second,
+	}
+}
+
+func requireDiagnosis(t *testing.T, err error, want Diagnosis) *HostUnreachableError {
+	var unreachable *HostUnreachableError
+	require.ErrorAs(t, err, &unreachable)
+	assert.Equal(t, want, unreachable.Diagnosis)
+	return unreachable
+}
+
+func TestValidateHostReachable_OK(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	p := newTestHostProber()
+	p.rootCAs = x509.NewCertPool()
+	p.rootCAs.AddCert(server.Certificate())
+
+	assert.NoError(t, p.validate(t.Context(), server.URL))
+}
+
+func TestValidateHostReachable_DNSFailure(t *testing.T) {
+	p := newTestHostProber()
+	p.resolver = &net.Resolver{
+		PreferGo: true,
+		Dial: func(context.Context, string, string) (net.Conn, error) {
+			return nil, errors.New("no DNS server")
+		},
+	}
+
+	err := p.validate(t.Context(), "https://my-workspace.cloud.databricks.com")
+	requireDiagnosis(t, err, DNSFailure)
+	assert.Equal(t, "cannot resolve my-workspace.cloud.databricks.com; check the host name and your DNS settings", err.Error())
+}
+
+func TestValidateHostReachable_ConnectTimeout(t *testing.T) {
+	p := newTestHostProber()
+	p.timeout = 10 * time.Millisecond
+	p.dial = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	err := p.validate(t.Context(), "https://127.0.0.1:8443")
+	requireDiagnosis(t, err, ConnectTimeout)
+	assert.Contains(t, err.Error(), "connecting to 127.0.0.1:8443 timed out")
+}
+
+func TestValidateHostReachable_ConnectFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	err = newTestHostProber().validate(t.Context(), "https://"+addr)
+	requireDiagnosis(t, err, ConnectFailure)
+}
+
+func TestValidateHostReachable_TLSInterception(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	// The certificate of the test server is not trusted by the system roots.
+	p := newTestHostProber()
+	p.rootCAs = x509.NewCertPool()
+
+	err := p.validate(t.Context(), server.URL)
+	unreachable := requireDiagnosis(t, err, TLSInterception)
+	assert.Equal(t, "Acme Co", unreachable.Issuer)
+	assert.Contains(t, err.Error(), `is issued by "Acme Co", which is not trusted`)
+}
+
+// startTestProxy starts a proxy that answers every CONNECT request with
+// status and returns its URL.
+func startTestProxy(t *testing.T, status int) *url.URL {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			req, err := http.ReadRequest(bufio.NewReader(conn))
+			if err == nil {
+				resp := &http.Response{StatusCode: status, ProtoMajor: 1, ProtoMinor: 1, Request: req}
+				_ = resp.Write(conn)
+			}
+			conn.Close()
+		}
+	}()
+	return &url.URL{Scheme: "http", Host: l.Addr().String()}
+}
+
+func TestValidateHostReachable_ProxyRequired(t *testing.T) {
+	proxyURL := startTestProxy(t, http.StatusProxyAuthRequired)
+	p := newTestHostProber()
+	p.proxy = func(*http.Request) (*url.URL, error) { return proxyURL, nil }
+
+	err := p.validate(t.Context(), "https://my-workspace.cloud.databricks.com")
+	unreachable := requireDiagnosis(t, err, ProxyRequired)
+	assert.Equal(t, proxyURL.Host, unreachable.Proxy)
+	assert.Equal(t, "my-workspace.cloud.databricks.com:443", unreachable.Addr)
+}
+
+func TestValidateHostReachable_ProxyRefused(t *testing.T) {
+	proxyURL := startTestProxy(t, http.StatusForbidden)
+	p := newTestHostProber()
+	p.proxy = func(*http.Request) (*url.URL, error) { return proxyURL, nil }
+
+	err := p.validate(t.Context(), "https://my-workspace.cloud.databricks.com")
+	requireDiagnosis(t, err, ConnectFailure)
+	assert.Contains(t, err.Error(), "through proxy "+proxyURL.Host)
+}
+
+func TestIsNetworkError(t *testing.T) {
+	dialErr := &url.Error{
+		Op:  "Get",
+		URL: "https://test.com/api/2.0/preview/scim/v2/Me",
+		Err: &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: network is unreachable")},
+	}
+	assert.True(t, IsNetworkError(dialErr))
+	assert.True(t, IsNetworkError(fmt.Errorf("failed request: %w", dialErr)))
+	assert.True(t, IsNetworkError(&net.DNSError{Err: "no such host", Name: "test.com"}))
+	assert.False(t, IsNetworkError(&apierr.APIError{StatusCode: 403, Message: "forbidden"}))
+	assert.False(t, IsNetworkError(errors.New("invalid config")))
+}
+
+func TestDiagnoseHostError_NotNetworkError(t *testing.T) {
+	original := errors.New("token refresh: invalid_grant")
+	assert.Equal(t, original, DiagnoseHostError(t.Context(), "https://my-workspace.cloud.databricks.com", original))
+}
+
+func TestDiagnoseHostError_NetworkError(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	original := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	err = DiagnoseHostError(t.Context(), "https://"+addr, original)
+	assert.ErrorIs(t, err, original)
+	requireDiagnosis(t, err, ConnectFailure)
+	assert.Contains(t, err.Error(), "\n\nNetwork diagnosis: cannot connect to "+addr)
+}
@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/httpclient"
+)
+
+const (
+	// refreshMaxAttempts is the total number of attempts made for a token
+	// request, including the first one.
+	refreshMaxAttempts = 3
+
+	// refreshInitialBackoff is the wait before the first retry. It doubles
+	// after every failed attempt.
+	refreshInitialBackoff = 500 * time.Millisecond
+
+	// refreshClientTimeout matches the default timeout of the HTTP client
+	// used by the SDK for OAuth requests when no HTTP client is provided.
+	refreshClientTimeout = 30 * time.Second
+)
+
+// Sleeper waits for the given duration. It returns early with the context's
+// error if the context is done before the duration elapses.
+type Sleeper func(ctx context.Context, d time.Duration) error
+
+// sleepContext is the default [Sleeper].
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// refreshRetryTransport retries requests to the OAuth token endpoint that fail
+// with a 5xx status that the SDK's ApiClient doesn't retry itself. A single 502
+// from the token endpoint would otherwise fail the token refresh outright.
+// Connection resets, 429 and 504 are retried by the ApiClient, so retrying them
+// here as well would multiply the attempts and the backoff.
+type refreshRetryTransport struct {
+	base  http.RoundTripper
+	sleep Sleeper
+}
+
+// NewRefreshRetryTransport wraps base with a bounded retry with exponential
+// backoff for transient failures. The backoff uses sleep, so that tests can
+// run deterministically without waiting.
+func NewRefreshRetryTransport(base http.RoundTripper, sleep Sleeper) http.RoundTripper {
+	return &refreshRetryTransport{base: base, sleep: sleep}
+}
+
+// NewRefreshHTTPClient returns the HTTP client used by the CLI to refresh
+// OAuth tokens. It uses the same transport as the SDK default and retries
+// transient failures.
+func NewRefreshHTTPClient() *http.Client {
+	return &http.Client{
+		Transport: NewRefreshRetryTransport(httpclient.NewApiClient(httpclient.ClientConfig{}), sleepContext),
+		Timeout:   refreshClientTimeout,
+	}
+}
+
+// RoundTrip implements [http.RoundTripper].
+func (t *refreshRetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	backoff := refreshInitialBackoff
+	for attempt := 1; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if attempt == refreshMaxAttempts || !isTransientRefreshFailure(ctx, resp, err) {
+			return resp, err
+		}
+
+		// The request body was consumed by the failed attempt. Requests
+		// without a way to recreate the body cannot be retried.
+		next, ok := rewindRequest(req)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+
+		log.Debugf(ctx, "Token request failed (attempt %d/%d), retrying in %v: %v", attempt, refreshMaxAttempts, backoff, describeRefreshFailure(resp, err))
+		if sleepErr := t.sleep(ctx, backoff); sleepErr != nil {
+			return nil, sleepErr
+		}
+		backoff *= 2
+		req = next
+	}
+}
+
+// isTransientRefreshFailure reports whether a token request failed with a
+// 5xx status that the SDK's ApiClient doesn't retry.
+func isTransientRefreshFailure(ctx context.Context, resp *http.Response, err error) bool {
+	if err == nil {
+		return resp.StatusCode >= http.StatusInternalServerError && resp.StatusCode != http.StatusGatewayTimeout
+	}
+	// The SDK's ApiClient returns non-2xx responses as errors.
+	var httpErr *httpclient.HttpError
+	if errors.As(err, &httpErr) && httpErr.Response != nil {
+		return httpErr.StatusCode >= http.StatusInternalServerError && !httpclient.DefaultErrorRetriable(ctx, err)
+	}
+	return false
+}
+
+// rewindRequest returns a copy of req with a fresh body.
+func rewindRequest(req *http.Request) (*http.Request, bool) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, true
+	}
+	if req.GetBody == nil {
+		return nil, false
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, false
+	}
+	next := req.Clone(req.Context())
+	next.Body = body
+	return next, true
+}
+
+func describeRefreshFailure(resp *http.Response, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return resp.Status
+}
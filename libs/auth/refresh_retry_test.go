@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/httpclient"
+	"github.com/databricks/databricks-sdk-go/httpclient/fixtures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingSleeper records the requested waits without sleeping.
+type recordingSleeper struct {
+	waits []time.Duration
+	err   error
+}
+
+func (s *recordingSleeper) sleep(ctx context.Context, d time.Duration) error {
+	s.waits = append(s.waits, d)
+	return s.err
+}
+
+var badGateway = fixtures.HTTPFixture{
+	MatchAny: true,
+	Status:   502,
+	Response: `{"error": "temporarily_unavailable"}`,
+}
+
+var tokenOK = fixtures.HTTPFixture{
+	MatchAny: true,
+	Status:   200,
+	Response: `{"access_token": "abc"}`,
+}
+
+func postToken(t *testing.T, rt http.RoundTripper) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(t.Context(), "POST", "https://example.com/oidc/v1/token", strings.NewReader("grant_type=refresh_token"))
+	require.NoError(t, err)
+	return rt.RoundTrip(req)
+}
+
+func TestRefreshRetryTransport(t *testing.T) {
+	cases := []struct {
+		name       string
+		fixtures   fixtures.SliceTransport
+		wantStatus int
+		wantWaits  []time.Duration
+	}{
+		{
+			name:       "succeeds without retry",
+			fixtures:   fixtures.SliceTransport{tokenOK},
+			wantStatus: 200,
+		},
+		{
+			name:       "502 then 200",
+			fixtures:   fixtures.SliceTransport{badGateway, tokenOK},
+			wantStatus: 200,
+			wantWaits:  []time.Duration{500 * time.Millisecond},
+		},
+		{
+			name:       "gives up after three attempts",
+			fixtures:   fixtures.SliceTransport{badGateway, badGateway, badGateway, tokenOK},
+			wantStatus: 502,
+			wantWaits:  []time.Duration{500 * time.Millisecond, time.Second},
+		},
+		{
+			name: "does not retry 504, which the ApiClient retries",
+			fixtures: fixtures.SliceTransport{
+				{MatchAny: true, Status: 504, Response: `{"error": "timeout"}`},
+				tokenOK,
+			},
+			wantStatus: 504,
+		},
+		{
+			name: "does not retry 4xx",
+			fixtures: fixtures.SliceTransport{
+				{MatchAny: true, Status: 401, Response: `{"error": "invalid_grant"}`},
+				tokenOK,
+			},
+			wantStatus: 401,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := &recordingSleeper{}
+			resp, err := postToken(t, NewRefreshRetryTransport(tc.fixtures, s.sleep))
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantStatus, resp.StatusCode)
+			assert.Equal(t, tc.wantWaits, s.waits)
+		})
+	}
+}
+
+func TestRefreshRetryTransport_ResendsBody(t *testing.T) {
+	var bodies []string
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			return badGateway.Reply(req)
+		}
+		return tokenOK.Reply(req)
+	})
+
+	s := &recordingSleeper{}
+	resp, err := postToken(t, NewRefreshRetryTransport(base, s.sleep))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+
+	// The request body is sent again on retry.
+	assert.Equal(t, []string{"grant_type=refresh_token", "grant_type=refresh_token"}, bodies)
+}
+
+func TestRefreshRetryTransport_DoesNotRetryConnectionReset(t *testing.T) {
+	// The ApiClient retries connection resets already.
+	attempts := 0
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, syscall.ECONNRESET
+	})
+
+	s := &recordingSleeper{}
+	_, err := postToken(t, NewRefreshRetryTransport(base, s.sleep))
+	assert.ErrorIs(t, err, syscall.ECONNRESET)
+	assert.Equal(t, 1, attempts)
+	assert.Empty(t, s.waits)
+}
+
+func TestRefreshRetryTransport_DoesNotRetryOtherErrors(t *testing.T) {
+	attempts := 0
+	base := roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, errors.New("no such host")
+	})
+
+	s := &recordingSleeper{}
+	_, err := postToken(t, NewRefreshRetryTransport(base, s.sleep))
+	assert.EqualError(t, err, "no such host")
+	assert.Equal(t, 1, attempts)
+	assert.Empty(t, s.waits)
+}
+
+func TestRefreshRetryTransport_StopsWhenSleepFails(t *testing.T) {
+	s := &recordingSleeper{err: context.DeadlineExceeded}
+	_, err := postToken(t, NewRefreshRetryTransport(fixtures.SliceTransport{badGateway, tokenOK}, s.sleep))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Len(t, s.waits, 1)
+}
+
+func TestSleepContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	err := sleepContext(ctx, time.Hour)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	assert.NoError(t, sleepContext(t.Context(), time.Millisecond))
+}
+
+func TestRefreshRetryTransport_ApiClientError(t *testing.T) {
+	// The SDK's ApiClient reports non-2xx responses as errors instead of responses.
+	base := httpclient.NewApiClient(httpclient.ClientConfig{
+		Transport: fixtures.SliceTransport{badGateway, tokenOK},
+	})
+
+	s := &recordingSleeper{}
+	resp, err := postToken(t, NewRefreshRetryTransport(base, s.sleep))
+	require.NoError(t, err)
+	assert.Equal(t, 200, resp.StatusCode)
+	assert.Len(t, s.waits, 1)
+}
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m"
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
+	"golang.org/x/oauth2"
+)
+
+// tokenTimesFilePath is the location of the token timestamps file relative to
+// the home directory. It sits next to the SDK's token cache. The timestamps
+// are kept in a separate file because the SDK rewrites the token cache and
+// would drop unknown fields.
+const tokenTimesFilePath = ".databricks/token-cache-times.json"
+
+const tokenTimesVersion = 1
+
+// TokenTimes records when the token stored under a cache key was obtained.
+// Entries written before timestamps were recorded have zero values.
+type TokenTimes struct {
+	// Login is when the token was obtained through an interactive login.
+	Login time.Time `json:"login,omitzero"`
+
+	// LastRefresh is when the refresh token was last used successfully.
+	LastRefresh time.Time `json:"last_refresh,omitzero"`
+}
+
+// TokenTimesStore persists [TokenTimes] by token cache key.
+type TokenTimesStore struct {
+	store *jsonStore[*TokenTimes]
+}
+
+// NewTokenTimesStore returns a store backed by the file at path.
+func NewTokenTimesStore(path string) *TokenTimesStore {
+	return &TokenTimesStore{store: newJSONStore[*TokenTimes](path, tokenTimesVersion, "tokens")}
+}
+
+// DefaultTokenTimesStore returns the store next to the default token cache.
+func DefaultTokenTimesStore() (*TokenTimesStore, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, fmt.Errorf("failed loading home directory: %w", err)
+	}
+	return NewTokenTimesStore(filepath.Join(home, tokenTimesFilePath)), nil
+}
+
+// Lookup returns the timestamps recorded for key. It returns zero values if
+// nothing was recorded.
+func (s *TokenTimesStore) Lookup(key string) (TokenTimes, error) {
+	tokens, err := s.store.load()
+	if err != nil {
+		return TokenTimes{}, err
+	}
+	if t, ok := tokens[key]; ok && t != nil {
+		return *t, nil
+	}
+	return TokenTimes{}, nil
+}
+
+// update applies fn to the timestamps of key and writes the result. If fn
+// returns nil, the entry is removed.
+func (s *TokenTimesStore) update(key string, fn func(TokenTimes) *TokenTimes) error {
+	return s.store.update(func(tokens map[string]*TokenTimes) bool {
+		var current TokenTimes
+		if t, ok := tokens[key]; ok && t != nil {
+			current = *t
+		}
+		if next := fn(current); next != nil {
+			tokens[key] = next
+		} else {
+			delete(tokens, key)
+		}
+		return true
+	})
+}
+
+// TokenEvent describes how a token written to the cache was obtained.
+type TokenEvent int
+
+const (
+	// TokenLogin is a token obtained through an interactive login.
+	TokenLogin TokenEvent = iota
+
+	// TokenRefresh is a token obtained with a refresh token.
+	TokenRefresh
+)
+
+// RecordTokenTimes wraps c so that every token it stores records the time
+// of the given event in s. A nil c or s is replaced by the default token
+// cache or store when first used, so that wrapping the defaults does not
+// touch the file system until a token is read or written.
+func RecordTokenTimes(ctx context.Context, c cache.TokenCache, s *TokenTimesStore, event TokenEvent) cache.TokenCache {
+	openCache := func() (cache.TokenCache, error) { return c, nil }
+	if c == nil {
+		openCache = func() (cache.TokenCache, error) { return cache.NewFileTokenCache() }
+	}
+	openStore := func() (*TokenTimesStore, error) { return s, nil }
+	if s == nil {
+		openStore = DefaultTokenTimesStore
+	}
+	return &timesRecordingTokenCache{
+		ctx:       ctx,
+		openCache: sync.OnceValues(openCache),
+		openStore: sync.OnceValues(openStore),
+		event:     event,
+		now:       time.Now,
+	}
+}
+
+// WithTokenTimes returns a persistent auth option that uses the default token
+// cache and records the time of event for every token written to it.
+func WithTokenTimes(ctx context.Context, event TokenEvent) u2m.PersistentAuthOption {
+	return u2m.WithTokenCache(RecordTokenTimes(ctx, nil, nil, event))
+}
+
+type timesRecordingTokenCache struct {
+	ctx       context.Context
+	openCache func() (cache.TokenCache, error)
+	openStore func() (*TokenTimesStore, error)
+	event     TokenEvent
+	now       func() time.Time
+}
+
+// Lookup implements [cache.TokenCache].
+func (c *timesRecordingTokenCache) Lookup(key string) (*oauth2.Token, error) {
+	tc, err := c.openCache()
+	if err != nil {
+		return nil, err
+	}
+	return tc.Lookup(key)
+}
+
+// Store implements [cache.TokenCache].
+func (c *timesRecordingTokenCache) Store(key string, t *oauth2.Token) error {
+	tc, err := c.openCache()
+	if err != nil {
+		return err
+	}
+	err = tc.Store(key, t)
+	if err != nil {
+		return err
+	}
+
+	// The timestamps are informational. Failing to record them must not
+	// fail the token write.
+	err = c.record(key, t)
+	if err != nil {
+		log.Debugf(c.ctx, "Failed to record token timestamps for %q: %v", key, err)
+	}
+	return nil
+}
+
+func (c *timesRecordingTokenCache) record(key string, t *oauth2.Token) error {
+	store, err := c.openStore()
+	if err != nil {
+		return err
+	}
+	now := c.now()
+	return store.update(key, func(times TokenTimes) *TokenTimes {
+		switch {
+		case t == nil:
+			return nil
+		case c.event == TokenLogin:
+			return &TokenTimes{Login: now}
+		default:
+			times.LastRefresh = now
+			return &times
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/databricks/databricks-sdk-go/credentials/u2m/cache"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/oauth2"
+)
+
+func newTestTokenTimesCache(t *testing.T, event TokenEvent, now time.Time) (cache.TokenCache, *TokenTimesStore) {
+	dir := t.TempDir()
+	tokenCache, err := cache.NewFileTokenCache(cache.WithFileLocation(filepath.Join(dir, "token-cache.json")))
+	require.NoError(t, err)
+	store := NewTokenTimesStore(filepath.Join(dir, "token-cache-times.json"))
+	c := RecordTokenTimes(t.Context(), tokenCache, store, event)
+	c.(*timesRecordingTokenCache).now = func() time.Time { return now }
+	return c, store
+}
+
+func TestTokenTimesStoreLookupMissingFile(t *testing.T) {
+	store := NewTokenTimesStore(filepath.Join(t.TempDir(), "token-cache-times.json"))
+	times, err := store.Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTimes{}, times)
+}
+
+func TestTokenTimesStoreLookupEntryWithoutTimes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token-cache-times.json")
+	err := os.WriteFile(path, []byte(`{"version": 1, "tokens": {"profile": {}}}`), 0o600)
+	require.NoError(t, err)
+
+	times, err := NewTokenTimesStore(path).Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTimes{}, times)
+}
+
+func TestTokenTimesStoreLookupUnknownVersion(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token-cache-times.json")
+	err := os.WriteFile(path, []byte(`{"version": 2, "tokens": {"profile": {"login": "2025-01-01T00:00:00Z"}}}`), 0o600)
+	require.NoError(t, err)
+
+	times, err := NewTokenTimesStore(path).Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTimes{}, times)
+}
+
+func TestRecordTokenTimesLogin(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	c, store := newTestTokenTimesCache(t, TokenLogin, now)
+
+	err := c.Store("profile", &oauth2.Token{AccessToken: "a", RefreshToken: "r"})
+	require.NoError(t, err)
+
+	tok, err := c.Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, "a", tok.AccessToken)
+
+	times, err := store.Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTimes{Login: now}, times)
+}
+
+func TestRecordTokenTimesRefreshKeepsLogin(t *testing.T) {
+	login := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	refresh := login.Add(time.Hour)
+
+	loginCache, store := newTestTokenTimesCache(t, TokenLogin, login)
+	require.NoError(t, loginCache.Store("profile", &oauth2.Token{AccessToken: "a"}))
+
+	refreshCache := RecordTokenTimes(t.Context(), loginCache, store, TokenRefresh)
+	refreshCache.(*timesRecordingTokenCache).now = func() time.Time { return refresh }
+	require.NoError(t, refreshCache.Store("profile", &oauth2.Token{AccessToken: "b"}))
+
+	times, err := store.Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTimes{Login: login, LastRefresh: refresh}, times)
+
+	// A new login resets the refresh time.
+	require.NoError(t, loginCache.Store("profile", &oauth2.Token{AccessToken: "c"}))
+	times, err = store.Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTimes{Login: login}, times)
+}
+
+func TestRecordTokenTimesDelete(t *testing.T) {
+	now := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	c, store := newTestTokenTimesCache(t, TokenLogin, now)
+	require.NoError(t, c.Store("profile", &oauth2.Token{AccessToken: "a"}))
+	require.NoError(t, c.Store("profile", nil))
+
+	_, err := c.Lookup("profile")
+	assert.ErrorIs(t, err, cache.ErrNotFound)
+
+	times, err := store.Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, TokenTimes{}, times)
+}
+
+func TestRecordTokenTimesIgnoresStoreErrors(t *testing.T) {
+	dir := t.TempDir()
+	tokenCache, err := cache.NewFileTokenCache(cache.WithFileLocation(filepath.Join(dir, "token-cache.json")))
+	require.NoError(t, err)
+
+	// A corrupt timestamps file must not fail the token write.
+	path := filepath.Join(dir, "token-cache-times.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	c := RecordTokenTimes(t.Context(), tokenCache, NewTokenTimesStore(path), TokenRefresh)
+	require.NoError(t, c.Store("profile", &oauth2.Token{AccessToken: "a"}))
+
+	tok, err := tokenCache.Lookup("profile")
+	require.NoError(t, err)
+	assert.Equal(t, "a", tok.AccessToken)
+}
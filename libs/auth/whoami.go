@@ -0,0 +1,194 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/databricks/cli/libs/iamutil"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/listing"
+	"github.com/databricks/databricks-sdk-go/service/iam"
+)
+
+// Identity describes the principal that a config authenticates as.
+type Identity struct {
+	UserName string `json:"user_name,omitempty"`
+	UserID   string `json:"user_id,omitempty"`
+
+	// IsServicePrincipal is true if the principal is a service principal.
+	IsServicePrincipal bool `json:"is_service_principal,omitempty"`
+
+	WorkspaceID string `json:"workspace_id,omitempty"`
+	AccountID   string `json:"account_id,omitempty"`
+}
+
+// identityResolver looks up and memoizes identities. The client constructors
+// are replaced in tests.
+type identityResolver struct {
+	mu         sync.Mutex
+	identities map[string]*Identity
+
+	workspaceClient func(cfg *config.Config) (*databricks.WorkspaceClient, error)
+	accountClient   func(cfg *config.Config) (*databricks.AccountClient, error)
+}
+
+var defaultIdentityResolver = &identityResolver{
+	identities: map[string]*Identity{},
+	workspaceClient: func(cfg *config.Config) (*databricks.WorkspaceClient, error) {
+		return databricks.NewWorkspaceClient((*databricks.Config)(cfg))
+	},
+	accountClient: func(cfg *config.Config) (*databricks.AccountClient, error) {
+		return databricks.NewAccountClient((*databricks.Config)(cfg))
+	},
+}
+
+// WhoAmI returns the identity that cfg authenticates as. It uses the SCIM Me
+// API on workspace hosts and the account users API on account hosts.
+//
+// Identities are memoized per host and credentials for the lifetime of the
+// process. Errors are enriched with [EnrichAuthError].
+func WhoAmI(ctx context.Context, cfg *config.Config) (*Identity, error) {
+	return defaultIdentityResolver.whoAmI(ctx, cfg)
+}
+
+// WorkspaceClientWhoAmI is like [WhoAmI] for the config of w, and looks up
+// the identity with w.
+func WorkspaceClientWhoAmI(ctx context.Context, w *databricks.WorkspaceClient) (*Identity, error) {
+	return defaultIdentityResolver.resolve(ctx, w.Config, func() (*Identity, error) {
+		return workspaceIdentity(ctx, w, w.Config)
+	})
+}
+
+func (r *identityResolver) whoAmI(ctx context.Context, cfg *config.Config) (*Identity, error) {
+	return r.resolve(ctx, cfg, func() (*Identity, error) {
+		if ResolveConfigType(cfg) == config.AccountConfig {
+			a, err := r.accountClient(cfg)
+			if err != nil {
+				return nil, err
+			}
+			return accountIdentity(ctx, a, cfg)
+		}
+		w, err := r.workspaceClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return workspaceIdentity(ctx, w, cfg)
+	})
+}
+
+// resolve returns the memoized identity for cfg, or looks it up with lookup.
+func (r *identityResolver) resolve(ctx context.Context, cfg *config.Config, lookup func() (*Identity, error)) (*Identity, error) {
+	key, err := identityKey(cfg)
+	if err != nil {
+		return nil, EnrichAuthError(ctx, cfg, err)
+	}
+
+	r.mu.Lock()
+	id, ok := r.identities[key]
+	r.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	id, err = lookup()
+	if err != nil {
+		return nil, EnrichAuthError(ctx, cfg, err)
+	}
+
+	r.mu.Lock()
+	r.identities[key] = id
+	r.mu.Unlock()
+	return id, nil
+}
+
+// identityKey returns the key under which the identity for cfg is memoized:
+// the host and a hash of the credentials it authenticates requests with.
+func identityKey(cfg *config.Config) (string, error) {
+	req, err := http.NewRequest(http.MethodGet, cfg.Host, nil)
+	if err != nil {
+		return "", err
+	}
+	err = cfg.Authenticate(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(req.Header.Get("Authorization")))
+	return cfg.CanonicalHostName() + " " + hex.EncodeToString(sum[:]), nil
+}
+
+func workspaceIdentity(ctx context.Context, w *databricks.WorkspaceClient, cfg *config.Config) (*Identity, error) {
+	me, err := w.CurrentUser.Me(ctx)
+	if err != nil {
+		return nil, err
+	}
+	id := &Identity{
+		UserName:           me.UserName,
+		UserID:             me.Id,
+		IsServicePrincipal: iamutil.IsServicePrincipal(me),
+		WorkspaceID:        cfg.WorkspaceID,
+		AccountID:          cfg.AccountID,
+	}
+	if id.WorkspaceID != "" {
+		return id, nil
+	}
+
+	// The workspace ID is informational. Not knowing it must not fail the
+	// lookup.
+	workspaceID, err := w.CurrentWorkspaceID(ctx)
+	if err != nil {
+		log.Debugf(ctx, "Failed to determine the workspace ID: %v", err)
+	} else {
+		id.WorkspaceID = strconv.FormatInt(workspaceID, 10)
+	}
+	return id, nil
+}
+
+// accountIdentity looks up the principal by the client ID or user name in
+// cfg. Accounts have no API that returns the calling principal, so with other
+// credentials only the account ID is known.
+func accountIdentity(ctx context.Context, a *databricks.AccountClient, cfg *config.Config) (*Identity, error) {
+	id := &Identity{AccountID: cfg.AccountID}
+
+	switch {
+	case cfg.ClientID != "":
+		sps, err := a.ServicePrincipals.ListAll(ctx, iam.ListAccountServicePrincipalsRequest{
+			Filter: fmt.Sprintf("applicationId eq %q", cfg.ClientID),
+		})
+		if err != nil {
+			return nil, err
+		}
+		id.UserName = cfg.ClientID
+		id.IsServicePrincipal = true
+		if len(sps) > 0 {
+			id.UserID = sps[0].Id
+		}
+
+	case cfg.Username != "":
+		users, err := a.Users.ListAll(ctx, iam.ListAccountUsersRequest{
+			Filter: fmt.Sprintf("userName eq %q", cfg.Username),
+		})
+		if err != nil {
+			return nil, err
+		}
+		id.UserName = cfg.Username
+		if len(users) > 0 {
+			id.UserID = users[0].Id
+		}
+
+	default:
+		// Verify the credentials with the cheapest account users request.
+		_, err := a.Users.List(ctx, iam.ListAccountUsersRequest{Count: 1, Attributes: "id"}).Next(ctx)
+		if err != nil && !errors.Is(err, listing.ErrNoMoreItems) {
+			return nil, err
+		}
+	}
+	return id, nil
+}
@@ -0,0 +1,190 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go"
+	"github.com/databricks/databricks-sdk-go/apierr"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/databricks-sdk-go/experimental/mocks"
+	"github.com/databricks/databricks-sdk-go/listing"
+	"github.com/databricks/databricks-sdk-go/service/iam"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("no network in tests")
+}
+
+// newWhoAmITestConfig returns a resolved config that authenticates with
+// token without loading the environment or fetching host metadata.
+func newWhoAmITestConfig(cfg *config.Config, token string) *config.Config {
+	cfg.Credentials = &fakeStrategy{name: "pat", token: token}
+	cfg.AuthType = "pat"
+	cfg.Loaders = []config.Loader{noopLoader{}}
+	cfg.HTTPTransport = failingTransport{}
+	return cfg
+}
+
+func newTestIdentityResolver(w *mocks.MockWorkspaceClient, a *mocks.MockAccountClient) *identityResolver {
+	r := &identityResolver{identities: map[string]*Identity{}}
+	if w != nil {
+		r.workspaceClient = func(*config.Config) (*databricks.WorkspaceClient, error) { return w.WorkspaceClient, nil }
+	}
+	if a != nil {
+		r.accountClient = func(*config.Config) (*databricks.AccountClient, error) { return a.AccountClient, nil }
+	}
+	return r
+}
+
+func TestWhoAmIWorkspace(t *testing.T) {
+	w := mocks.NewMockWorkspaceClient(t)
+	w.GetMockCurrentUserAPI().EXPECT().Me(mock.Anything).Return(&iam.User{
+		Id:       "123",
+		UserName: "user@example.com",
+	}, nil).Once()
+	r := newTestIdentityResolver(w, nil)
+	cfg := newWhoAmITestConfig(&config.Config{
+		Host:        "https://my-workspace.cloud.databricks.com",
+		WorkspaceID: "456",
+		AccountID:   "abc",
+	}, "token")
+
+	id, err := r.whoAmI(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{
+		UserName:    "user@example.com",
+		UserID:      "123",
+		WorkspaceID: "456",
+		AccountID:   "abc",
+	}, id)
+
+	// The identity is memoized for the same host and credentials.
+	again, err := r.whoAmI(t.Context(), cfg)
+	require.NoError(t, err)
+	assert.Same(t, id, again)
+}
+
+func TestWhoAmIWorkspaceServicePrincipal(t *testing.T) {
+	w := mocks.NewMockWorkspaceClient(t)
+	w.GetMockCurrentUserAPI().EXPECT().Me(mock.Anything).Return(&iam.User{
+		Id:       "123",
+		UserName: "a9a2b3c4-1234-5678-9abc-def012345678",
+	}, nil).Twice()
+	r := newTestIdentityResolver(w, nil)
+
+	for _, token := range []string{"token-a", "token-b"} {
+		cfg := newWhoAmITestConfig(&config.Config{
+			Host:        "https://my-workspace.cloud.databricks.com",
+			WorkspaceID: "456",
+		}, token)
+		id, err := r.whoAmI(t.Context(), cfg)
+		require.NoError(t, err)
+		assert.True(t, id.IsServicePrincipal)
+	}
+}
+
+func TestWorkspaceClientWhoAmI(t *testing.T) {
+	w := mocks.NewMockWorkspaceClient(t)
+	w.GetMockCurrentUserAPI().EXPECT().Me(mock.Anything).Return(&iam.User{
+		Id:       "123",
+		UserName: "user@example.com",
+	}, nil).Once()
+	w.WorkspaceClient.Config = newWhoAmITestConfig(&config.Config{
+		Host:        "https://client-whoami.cloud.databricks.com",
+		WorkspaceID: "456",
+	}, "token")
+
+	id, err := WorkspaceClientWhoAmI(t.Context(), w.WorkspaceClient)
+	require.NoError(t, err)
+	assert.Equal(t, &Identity{
+		UserName:    "user@example.com",
+		UserID:      "123",
+		WorkspaceID: "456",
+	}, id)
+
+	// The identity is memoized with the identities looked up by [WhoAmI].
+	again, err := WhoAmI(t.Context(), w.WorkspaceClient.Config)
+	require.NoError(t, err)
+	assert.Same(t, id, again)
+}
+
+func TestWhoAmIWorkspaceError(t *testing.T) {
+	w := mocks.NewMockWorkspaceClient(t)
+	w.GetMockCurrentUserAPI().EXPECT().Me(mock.Anything).Return(nil, &apierr.APIError{
+		StatusCode: 403,
+		ErrorCode:  "PERMISSION_DENIED",
+		Message:    "test error message",
+	})
+	r := newTestIdentityResolver(w, nil)
+	cfg := newWhoAmITestConfig(&config.Config{
+		Host:        "https://my-workspace.cloud.databricks.com",
+		WorkspaceID: "456",
+	}, "token")
+
+	_, err := r.whoAmI(t.Context(), cfg)
+	var apiErr *apierr.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Contains(t, err.Error(), "Next steps:")
+}
+
+func TestWhoAmIAccount(t *testing.T) {
+	tests := []struct {
+		name  string
+		cfg   *config.Config
+		setup func(a *mocks.MockAccountClient)
+		want  *Identity
+	}{
+		{
+			name: "service principal",
+			cfg:  &config.Config{ClientID: "client-id"},
+			setup: func(a *mocks.MockAccountClient) {
+				a.GetMockAccountServicePrincipalsAPI().EXPECT().ListAll(mock.Anything, iam.ListAccountServicePrincipalsRequest{
+					Filter: `applicationId eq "client-id"`,
+				}).Return([]iam.ServicePrincipal{{Id: "sp-1"}}, nil)
+			},
+			want: &Identity{UserName: "client-id", UserID: "sp-1", IsServicePrincipal: true, AccountID: "abc"},
+		},
+		{
+			name: "user",
+			cfg:  &config.Config{Username: "user@example.com"},
+			setup: func(a *mocks.MockAccountClient) {
+				a.GetMockAccountUsersAPI().EXPECT().ListAll(mock.Anything, iam.ListAccountUsersRequest{
+					Filter: `userName eq "user@example.com"`,
+				}).Return([]iam.User{{Id: "user-1"}}, nil)
+			},
+			want: &Identity{UserName: "user@example.com", UserID: "user-1", AccountID: "abc"},
+		},
+		{
+			name: "unknown principal",
+			cfg:  &config.Config{},
+			setup: func(a *mocks.MockAccountClient) {
+				a.GetMockAccountUsersAPI().EXPECT().List(mock.Anything, iam.ListAccountUsersRequest{
+					Count:      1,
+					Attributes: "id",
+				}).Return(&listing.SliceIterator[iam.User]{{Id: "user-1"}})
+			},
+			want: &Identity{AccountID: "abc"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := mocks.NewMockAccountClient(t)
+			tt.setup(a)
+			r := newTestIdentityResolver(nil, a)
+			tt.cfg.Host = "https://accounts.cloud.databricks.com"
+			tt.cfg.AccountID = "abc"
+
+			id, err := r.whoAmI(t.Context(), newWhoAmITestConfig(tt.cfg, "token"))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, id)
+		})
+	}
+}
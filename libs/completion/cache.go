@@ -0,0 +1,154 @@
+package completion
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/databricks/cli/libs/fileutil"
+)
+
+// The shims for bash, zsh, fish and powershell source a completion script that
+// is cached in the home directory instead of generating it on every shell
+// start, which runs the CLI and is noticeably slow on some machines. The
+// version file of the cached script gets the modification time of the CLI
+// binary that generated it. If the binary on the PATH has another modification
+// time, the shim runs "databricks completion cache" in the background to
+// regenerate the script.
+
+// UsesCache reports whether the shim for the shell sources a cached script.
+// Nushell and elvish scripts query the CLI at runtime and aren't cached.
+func (s Shell) UsesCache() bool {
+	switch s {
+	case Bash, Zsh, Fish, PowerShell, PowerShell5:
+		return true
+	default:
+		return false
+	}
+}
+
+// CachePath returns the file that caches the completion script for the shell.
+func CachePath(shell Shell, homeDir string) string {
+	return filepath.Join(homeDir, ".databricks", "completions", string(shell))
+}
+
+// cacheVersionPath returns the file that records the CLI version that
+// generated the cached script, and whether the script includes descriptions.
+func cacheVersionPath(shell Shell, homeDir string) string {
+	return CachePath(shell, homeDir) + ".version"
+}
+
+// cacheStamp returns what is recorded in the version file for a script
+// generated by version, with or without descriptions.
+func cacheStamp(version string, includeDesc bool) string {
+	if includeDesc {
+		return version
+	}
+	return version + " " + noDescriptionsFlag
+}
+
+// CacheStale reports whether the cached completion script for the shell is
+// missing, was generated by a CLI version other than version, or differs in
+// whether it includes descriptions. Unless executable is empty, the script is
+// also stale if it was generated by a CLI binary with another modification
+// time, because the shim would otherwise keep refreshing it.
+func CacheStale(shell Shell, homeDir, version string, includeDesc bool, executable string) bool {
+	if _, err := os.Stat(CachePath(shell, homeDir)); err != nil {
+		return true
+	}
+	stamp := cacheVersionPath(shell, homeDir)
+	recorded, err := os.ReadFile(stamp)
+	if err != nil {
+		return true
+	}
+	if executable != "" {
+		exe, err := os.Stat(executable)
+		if err != nil {
+			return true
+		}
+		info, err := os.Stat(stamp)
+		if err != nil || !info.ModTime().Equal(exe.ModTime()) {
+			return true
+		}
+	}
+	return strings.TrimSpace(string(recorded)) != cacheStamp(version, includeDesc)
+}
+
+// WriteCache caches the completion script written by generate and records
+// version as the CLI version that generated it, along with whether it includes
+// descriptions. The version file gets the modification time of executable, the
+// CLI binary, unless it is empty. The files are replaced atomically, because a
+// shell may source the script while it is regenerated.
+func WriteCache(shell Shell, homeDir, version string, includeDesc bool, executable string, generate func(w io.Writer) error) error {
+	if !shell.UsesCache() {
+		return errors.New("completions for " + string(shell) + " are not cached")
+	}
+
+	path := CachePath(shell, homeDir)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	if err := writeCacheFile(path, time.Time{}, generate); err != nil {
+		return err
+	}
+
+	var modTime time.Time
+	if executable != "" {
+		info, err := os.Stat(executable)
+		if err != nil {
+			return err
+		}
+		modTime = info.ModTime()
+	}
+	return writeCacheFile(cacheVersionPath(shell, homeDir), modTime, func(w io.Writer) error {
+		_, err := io.WriteString(w, cacheStamp(version, includeDesc)+"\n")
+		return err
+	})
+}
+
+// writeCacheFile replaces the file at path with the content written by
+// generate, see [fileutil.WriteFile]. If modTime is not zero, it is set as the
+// modification time of the file.
+func writeCacheFile(path string, modTime time.Time, generate func(w io.Writer) error) error {
+	if err := fileutil.WriteFile(path, 0o644, generate); err != nil {
+		return err
+	}
+	if modTime.IsZero() {
+		return nil
+	}
+	return os.Chtimes(path, modTime, modTime)
+}
+
+// removeCache removes the cached completion script for the shell, if any.
+func removeCache(shell Shell, homeDir string) error {
+	for _, path := range []string{CachePath(shell, homeDir), cacheVersionPath(shell, homeDir)} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// RemoveCaches removes the directories with the cached completion scripts and
+// the cached resources used for completions. It reports whether any of them
+// existed.
+func RemoveCaches(homeDir string) (bool, error) {
+	removed := false
+	for _, dir := range []string{
+		filepath.Dir(CachePath(Bash, homeDir)),
+		filepath.Join(homeDir, ".databricks", "completion-cache"),
+	} {
+		if !exists(dir) {
+			continue
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, err
+		}
+		removed = true
+	}
+	return removed, nil
+}
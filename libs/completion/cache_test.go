@@ -0,0 +1,135 @@
+package completion
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// generator returns a generate function for WriteCache that writes script.
+func generator(script string) func(io.Writer) error {
+	return func(w io.Writer) error {
+		_, err := io.WriteString(w, script)
+		return err
+	}
+}
+
+func TestCachePath(t *testing.T) {
+	home := t.TempDir()
+	assert.Equal(t, filepath.Join(home, ".databricks", "completions", "zsh"), CachePath(Zsh, home))
+}
+
+func TestCacheRegeneratedOnVersionChange(t *testing.T) {
+	home := t.TempDir()
+	assert.True(t, CacheStale(Zsh, home, "0.1.0", true, ""))
+
+	require.NoError(t, WriteCache(Zsh, home, "0.1.0", true, "", generator("# v0.1.0\n")))
+	assert.False(t, CacheStale(Zsh, home, "0.1.0", true, ""))
+	assert.True(t, CacheStale(Zsh, home, "0.2.0", true, ""))
+
+	require.NoError(t, WriteCache(Zsh, home, "0.2.0", true, "", generator("# v0.2.0\n")))
+	assert.False(t, CacheStale(Zsh, home, "0.2.0", true, ""))
+
+	content, err := os.ReadFile(CachePath(Zsh, home))
+	require.NoError(t, err)
+	assert.Equal(t, "# v0.2.0\n", string(content))
+}
+
+func TestCacheStaleWithoutVersion(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, WriteCache(Bash, home, "0.1.0", true, "", generator("# script\n")))
+	require.NoError(t, os.Remove(cacheVersionPath(Bash, home)))
+	assert.True(t, CacheStale(Bash, home, "0.1.0", true, ""))
+}
+
+func TestWriteCacheGenerateError(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, WriteCache(Zsh, home, "0.1.0", true, "", generator("# v0.1.0\n")))
+
+	err := WriteCache(Zsh, home, "0.2.0", true, "", func(io.Writer) error {
+		return errors.New("generate failed")
+	})
+	assert.ErrorContains(t, err, "generate failed")
+
+	// The previous script and version are kept, and no temporary files remain.
+	content, err := os.ReadFile(CachePath(Zsh, home))
+	require.NoError(t, err)
+	assert.Equal(t, "# v0.1.0\n", string(content))
+	assert.True(t, CacheStale(Zsh, home, "0.2.0", true, ""))
+	entries, err := os.ReadDir(filepath.Dir(CachePath(Zsh, home)))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestWriteCacheNotCached(t *testing.T) {
+	err := WriteCache(Nushell, t.TempDir(), "0.1.0", true, "", generator(""))
+	assert.ErrorContains(t, err, "completions for nushell are not cached")
+}
+
+func TestUninstallRemovesCache(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	require.NoError(t, WriteCache(Zsh, home, "0.1.0", true, "", generator("# script\n")))
+	_, _, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+
+	_, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+
+	_, err = os.Stat(CachePath(Zsh, home))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+	_, err = os.Stat(cacheVersionPath(Zsh, home))
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestRemoveCaches(t *testing.T) {
+	home := t.TempDir()
+	removed, err := RemoveCaches(home)
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	require.NoError(t, WriteCache(Zsh, home, "1.0.0", true, "", generator("script")))
+	require.NoError(t, WriteResourceCache(ResourceCachePath(home, "https://example.com", "jobs"), nil, time.Now()))
+
+	removed, err = RemoveCaches(home)
+	require.NoError(t, err)
+	assert.True(t, removed)
+	assert.NoDirExists(t, filepath.Join(home, ".databricks", "completions"))
+	assert.NoDirExists(t, filepath.Join(home, ".databricks", "completion-cache"))
+}
+
+func TestCacheStaleDescriptions(t *testing.T) {
+	home := t.TempDir()
+	require.NoError(t, WriteCache(Zsh, home, "1.0.0", false, "", generator("script")))
+	assert.False(t, CacheStale(Zsh, home, "1.0.0", false, ""))
+	assert.True(t, CacheStale(Zsh, home, "1.0.0", true, ""))
+}
+
+func TestCacheStaleExecutable(t *testing.T) {
+	home := t.TempDir()
+	executable := filepath.Join(t.TempDir(), "databricks")
+	require.NoError(t, os.WriteFile(executable, nil, 0o755))
+
+	require.NoError(t, WriteCache(Zsh, home, "1.0.0", true, executable, generator("script")))
+	assert.False(t, CacheStale(Zsh, home, "1.0.0", true, executable))
+
+	// The version file has the modification time of the binary.
+	exe, err := os.Stat(executable)
+	require.NoError(t, err)
+	stamp, err := os.Stat(cacheVersionPath(Zsh, home))
+	require.NoError(t, err)
+	assert.True(t, stamp.ModTime().Equal(exe.ModTime()))
+
+	// A reinstalled binary of the same version makes the cache stale.
+	later := exe.ModTime().Add(time.Hour)
+	require.NoError(t, os.Chtimes(executable, later, later))
+	assert.True(t, CacheStale(Zsh, home, "1.0.0", true, executable))
+}
@@ -0,0 +1,280 @@
+package completion
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/databricks/cli/libs/env"
+)
+
+// compinitLine initializes the zsh completion system.
+const compinitLine = "autoload -U compinit && compinit"
+
+// Check is a named diagnostic of the completion setup for a shell.
+type Check struct {
+	Name string
+
+	// Run returns nil if the check doesn't apply to the shell.
+	Run func(ctx context.Context, shell Shell, homeDir string) *CheckResult
+}
+
+// CheckResult is the outcome of a [Check].
+type CheckResult struct {
+	Passed bool
+
+	// Problem describes why the check failed.
+	Problem string
+
+	// Fix suggests how to fix the problem.
+	Fix string
+
+	// Apply fixes the problem. It is nil if the problem can't be fixed safely
+	// without the user's judgement.
+	Apply func() error
+}
+
+// Checks lists the checks run by "databricks completion doctor", in order.
+var Checks = []Check{
+	{Name: "completions are installed", Run: checkInstalled},
+	{Name: "shell configuration contains one completion block", Run: checkDuplicateBlocks},
+	{Name: "zsh completion system is initialized", Run: checkCompinit},
+	{Name: "bash-completion package is installed", Run: checkBashCompletion},
+	{Name: "no conflicting Homebrew completions", Run: checkHomebrewConflict},
+	{Name: "zsh completion dump is up to date", Run: checkCompdump},
+}
+
+func passed() *CheckResult {
+	return &CheckResult{Passed: true}
+}
+
+func checkInstalled(ctx context.Context, shell Shell, homeDir string) *CheckResult {
+	status, err := Status(ctx, shell, homeDir)
+	if err != nil {
+		return &CheckResult{Problem: err.Error()}
+	}
+	if status.Installed {
+		return passed()
+	}
+	return &CheckResult{
+		Problem: fmt.Sprintf("Completions are not installed in %s.", filepath.ToSlash(status.FilePath)),
+		Fix:     "Run 'databricks completion install --shell " + string(shell) + "'.",
+		Apply: func() error {
+			_, _, err := Install(ctx, shell, homeDir, true)
+			return err
+		},
+	}
+}
+
+func checkDuplicateBlocks(ctx context.Context, shell Shell, homeDir string) *CheckResult {
+	if usesFileDrop(ctx, shell, homeDir) {
+		return nil
+	}
+	filePath := TargetFilePath(ctx, shell, homeDir)
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil
+	}
+
+	blocks, corrupted := findBlocks(string(content))
+	if len(corrupted) > 0 {
+		return &CheckResult{
+			Problem: corruptedBlocksError(filePath, corrupted).Error(),
+			Fix:     "Remove the incomplete completion block from " + filepath.ToSlash(filePath) + " by hand.",
+		}
+	}
+	if len(blocks) <= 1 {
+		return passed()
+	}
+	return &CheckResult{
+		Problem: fmt.Sprintf("%s contains %d completion blocks, so completions are loaded more than once.", filepath.ToSlash(filePath), len(blocks)),
+		Fix:     "Remove all but the first completion block, or run 'databricks completion install --shell " + string(shell) + "'.",
+		Apply: func() error {
+			return dedupeRC(filePath)
+		},
+	}
+}
+
+// CompinitMissing reports whether shell is zsh and the user's .zshrc, whose
+// path it returns, does not call compinit. Oh-my-zsh calls compinit itself.
+func CompinitMissing(ctx context.Context, shell Shell, homeDir string) (string, bool) {
+	if shell != Zsh || OhMyZshDir(ctx, homeDir) != "" {
+		return "", false
+	}
+	rcPath := zshrcPath(ctx, homeDir)
+	content, err := os.ReadFile(rcPath)
+	if err != nil {
+		return "", false
+	}
+	return rcPath, !strings.Contains(string(content), "compinit")
+}
+
+func checkCompinit(ctx context.Context, shell Shell, homeDir string) *CheckResult {
+	if shell != Zsh || OhMyZshDir(ctx, homeDir) != "" {
+		return nil
+	}
+	rcPath, missing := CompinitMissing(ctx, shell, homeDir)
+	if !missing {
+		return passed()
+	}
+	return &CheckResult{
+		Problem: filepath.ToSlash(rcPath) + " does not initialize the completion system, so no completions are loaded.",
+		Fix:     "Add '" + compinitLine + "' to " + filepath.ToSlash(rcPath) + " before the completion block.",
+		Apply: func() error {
+			return insertCompinit(rcPath)
+		},
+	}
+}
+
+// insertCompinit adds a call to compinit to the .zshrc at rcPath, before our
+// completion block if there is one, because the shim needs compdef.
+func insertCompinit(rcPath string) error {
+	return updateFile(rcPath, func(content []byte) ([]byte, error) {
+		text := string(content)
+		if i := strings.Index(text, BeginMarker); i >= 0 {
+			return []byte(text[:i] + compinitLine + "\n" + text[i:]), nil
+		}
+		if text != "" && !strings.HasSuffix(text, "\n") {
+			text += "\n"
+		}
+		return []byte(text + compinitLine + "\n"), nil
+	})
+}
+
+// bashCompletionScripts are the locations of the bash-completion package on
+// common Linux distributions.
+var bashCompletionScripts = []string{
+	"/usr/share/bash-completion/bash_completion",
+	"/usr/local/share/bash-completion/bash_completion",
+	"/etc/bash_completion",
+}
+
+// findBashCompletion returns the path of the bash-completion package, or an
+// empty string if it isn't found. Homebrew installs version 2 to
+// etc/profile.d and version 1 to etc/bash_completion.
+func findBashCompletion(ctx context.Context) string {
+	paths := bashCompletionScripts
+	if prefix := homebrewPrefix(ctx); prefix != "" {
+		paths = append([]string{
+			filepath.Join(prefix, "etc", "profile.d", "bash_completion.sh"),
+			filepath.Join(prefix, "etc", "bash_completion"),
+		}, paths...)
+	}
+	for _, path := range paths {
+		if exists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+func checkBashCompletion(ctx context.Context, shell Shell, homeDir string) *CheckResult {
+	if shell != Bash {
+		return nil
+	}
+	if findBashCompletion(ctx) != "" {
+		return passed()
+	}
+	return &CheckResult{
+		Problem: "The bash-completion package was not found. The completion script requires it.",
+		Fix:     "Install bash-completion with your package manager, for example 'apt install bash-completion' or 'brew install bash-completion@2', and source it from your .bashrc.",
+	}
+}
+
+func checkHomebrewConflict(ctx context.Context, shell Shell, homeDir string) *CheckResult {
+	if shell != Zsh {
+		return nil
+	}
+	prefix := homebrewPrefix(ctx)
+	if prefix == "" || !exists(homebrewCompletionPath(prefix)) {
+		return nil
+	}
+
+	filePath := TargetFilePath(ctx, shell, homeDir)
+	content, err := os.ReadFile(filePath)
+	if err != nil || !strings.Contains(string(content), BeginMarker) {
+		return passed()
+	}
+	return &CheckResult{
+		Problem: fmt.Sprintf(
+			"Completions are provided both by Homebrew in %s and by %s. Which of them is used depends on the order of $fpath.",
+			filepath.ToSlash(homebrewCompletionPath(prefix)),
+			filepath.ToSlash(filePath),
+		),
+		Fix: "Run 'databricks completion uninstall --shell zsh' to use the completions from Homebrew, or 'brew unlink databricks' if you don't use the CLI from Homebrew.",
+	}
+}
+
+// fpathCompletionFile returns the completion file for zsh that is loaded
+// from $fpath, and therefore recorded in the completion dump. It returns an
+// empty string if completions aren't loaded from $fpath.
+func fpathCompletionFile(ctx context.Context, homeDir string) string {
+	status, err := Status(ctx, Zsh, homeDir)
+	if err != nil || !status.Installed {
+		return ""
+	}
+	switch status.Method {
+	case "file", "system":
+		return status.FilePath
+	case "marker":
+		if usesFileDrop(ctx, Zsh, homeDir) {
+			return status.FilePath
+		}
+	case "homebrew":
+		return homebrewCompletionPath(homebrewPrefix(ctx))
+	}
+	return ""
+}
+
+// compdumpFiles returns the completion dumps written by compinit.
+func compdumpFiles(ctx context.Context, homeDir string) []string {
+	files, _ := filepath.Glob(filepath.Join(zshConfigDir(ctx, homeDir), ".zcompdump*"))
+	if dump := env.Get(ctx, "ZSH_COMPDUMP"); dump != "" && exists(dump) {
+		files = append(files, dump)
+	}
+	return files
+}
+
+func checkCompdump(ctx context.Context, shell Shell, homeDir string) *CheckResult {
+	if shell != Zsh {
+		return nil
+	}
+	completionFile := fpathCompletionFile(ctx, homeDir)
+	if completionFile == "" {
+		return nil
+	}
+	info, err := os.Stat(completionFile)
+	if err != nil {
+		return nil
+	}
+
+	var stale []string
+	for _, dump := range compdumpFiles(ctx, homeDir) {
+		dumpInfo, err := os.Stat(dump)
+		if err == nil && dumpInfo.ModTime().Before(info.ModTime()) {
+			stale = append(stale, dump)
+		}
+	}
+	if len(stale) == 0 {
+		return passed()
+	}
+
+	names := make([]string, len(stale))
+	for i, dump := range stale {
+		names[i] = filepath.ToSlash(dump)
+	}
+	return &CheckResult{
+		Problem: fmt.Sprintf("The completion dump %s is older than %s, so zsh may not know about the completions.", strings.Join(names, ", "), filepath.ToSlash(completionFile)),
+		Fix:     "Remove the completion dump and start a new shell. compinit recreates it.",
+		Apply: func() error {
+			for _, dump := range stale {
+				if err := os.Remove(dump); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,190 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDoctor returns a home directory without oh-my-zsh or Homebrew, with
+// the given .zshrc.
+func setupDoctor(t *testing.T, zshrc string) string {
+	t.Setenv("ZSH", "")
+	t.Setenv("ZSH_CUSTOM", "")
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("ZSH_COMPDUMP", "")
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	home := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), []byte(zshrc), 0o644))
+	return home
+}
+
+func TestCheckInstalled(t *testing.T) {
+	home := setupDoctor(t, "")
+
+	result := checkInstalled(t.Context(), Zsh, home)
+	require.NotNil(t, result)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Fix, "databricks completion install --shell zsh")
+	require.NotNil(t, result.Apply)
+
+	require.NoError(t, result.Apply())
+	assert.True(t, checkInstalled(t.Context(), Zsh, home).Passed)
+}
+
+func TestCheckDuplicateBlocks(t *testing.T) {
+	home := setupDoctor(t, "# before\n"+ShimContent(Zsh, true)+"# after\n"+ShimContent(Zsh, true))
+
+	result := checkDuplicateBlocks(t.Context(), Zsh, home)
+	require.NotNil(t, result)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Problem, "contains 2 completion blocks")
+	require.NotNil(t, result.Apply)
+
+	require.NoError(t, result.Apply())
+	assert.True(t, checkDuplicateBlocks(t.Context(), Zsh, home).Passed)
+}
+
+func TestCheckDuplicateBlocksCorrupted(t *testing.T) {
+	home := setupDoctor(t, BeginMarker+"\nsource something\n")
+
+	result := checkDuplicateBlocks(t.Context(), Zsh, home)
+	require.NotNil(t, result)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Problem, "missing end marker")
+	assert.Nil(t, result.Apply)
+}
+
+func TestCheckDuplicateBlocksNotApplicable(t *testing.T) {
+	home := setupDoctor(t, "")
+	assert.Nil(t, checkDuplicateBlocks(t.Context(), Fish, home))
+	assert.Nil(t, checkDuplicateBlocks(t.Context(), Bash, home))
+}
+
+func TestCheckCompinit(t *testing.T) {
+	home := setupDoctor(t, "# my config\n"+ShimContent(Zsh, true))
+
+	result := checkCompinit(t.Context(), Zsh, home)
+	require.NotNil(t, result)
+	assert.False(t, result.Passed)
+	require.NotNil(t, result.Apply)
+
+	// compinit must run before the shim, which calls compdef.
+	require.NoError(t, result.Apply())
+	content, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, "# my config\n"+compinitLine+"\n"+ShimContent(Zsh, true), string(content))
+	assert.True(t, checkCompinit(t.Context(), Zsh, home).Passed)
+}
+
+func TestCheckCompinitWithoutBlock(t *testing.T) {
+	home := setupDoctor(t, "# my config")
+
+	result := checkCompinit(t.Context(), Zsh, home)
+	require.NotNil(t, result)
+	require.NoError(t, result.Apply())
+
+	content, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, "# my config\n"+compinitLine+"\n", string(content))
+}
+
+func TestCheckCompinitNotApplicable(t *testing.T) {
+	home := setupDoctor(t, "")
+	assert.Nil(t, checkCompinit(t.Context(), Bash, home))
+
+	// Oh-my-zsh calls compinit itself.
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".oh-my-zsh"), 0o755))
+	assert.Nil(t, checkCompinit(t.Context(), Zsh, home))
+}
+
+func TestCheckBashCompletion(t *testing.T) {
+	home := setupDoctor(t, "")
+	orig := bashCompletionScripts
+	t.Cleanup(func() { bashCompletionScripts = orig })
+
+	script := filepath.Join(t.TempDir(), "bash_completion")
+	bashCompletionScripts = []string{script}
+
+	result := checkBashCompletion(t.Context(), Bash, home)
+	require.NotNil(t, result)
+	assert.False(t, result.Passed)
+	assert.Nil(t, result.Apply)
+
+	require.NoError(t, os.WriteFile(script, nil, 0o644))
+	assert.True(t, checkBashCompletion(t.Context(), Bash, home).Passed)
+
+	assert.Nil(t, checkBashCompletion(t.Context(), Zsh, home))
+}
+
+func TestCheckBashCompletionHomebrew(t *testing.T) {
+	home := setupDoctor(t, "")
+	orig := bashCompletionScripts
+	t.Cleanup(func() { bashCompletionScripts = orig })
+	bashCompletionScripts = nil
+
+	prefix := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+	require.NoError(t, os.MkdirAll(filepath.Join(prefix, "etc", "profile.d"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(prefix, "etc", "profile.d", "bash_completion.sh"), nil, 0o644))
+
+	assert.True(t, checkBashCompletion(t.Context(), Bash, home).Passed)
+}
+
+func TestCheckHomebrewConflict(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("homebrew is not available on windows")
+	}
+	home := setupDoctor(t, ShimContent(Zsh, true))
+	assert.Nil(t, checkHomebrewConflict(t.Context(), Zsh, home))
+
+	prefix := t.TempDir()
+	fakeHomebrew(t, prefix, "0.250.0")
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+
+	result := checkHomebrewConflict(t.Context(), Zsh, home)
+	require.NotNil(t, result)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Problem, "_databricks")
+	assert.Nil(t, result.Apply)
+
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), nil, 0o644))
+	assert.True(t, checkHomebrewConflict(t.Context(), Zsh, home).Passed)
+}
+
+func TestCheckCompdump(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("homebrew is not available on windows")
+	}
+	home := setupDoctor(t, "")
+
+	// Completions from the shim aren't recorded in the dump.
+	assert.Nil(t, checkCompdump(t.Context(), Zsh, home))
+
+	prefix := t.TempDir()
+	fakeHomebrew(t, prefix, "0.250.0")
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+
+	dump := filepath.Join(home, ".zcompdump")
+	require.NoError(t, os.WriteFile(dump, nil, 0o644))
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(dump, old, old))
+
+	result := checkCompdump(t.Context(), Zsh, home)
+	require.NotNil(t, result)
+	assert.False(t, result.Passed)
+	require.NotNil(t, result.Apply)
+
+	require.NoError(t, result.Apply())
+	assert.NoFileExists(t, dump)
+	assert.True(t, checkCompdump(t.Context(), Zsh, home).Passed)
+
+	// A dump that is newer than the completion file is up to date.
+	require.NoError(t, os.WriteFile(dump, nil, 0o644))
+	assert.True(t, checkCompdump(t.Context(), Zsh, home).Passed)
+}
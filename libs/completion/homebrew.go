@@ -0,0 +1,72 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/databricks/cli/libs/env"
+)
+
+// homebrewPrefixes are the default Homebrew prefixes on macOS (Apple Silicon
+// and Intel) and Linux. See: https://docs.brew.sh/Installation
+var homebrewPrefixes = []string{
+	"/opt/homebrew",
+	"/usr/local",
+	"/home/linuxbrew/.linuxbrew",
+}
+
+// homebrewPrefix returns the Homebrew prefix, or an empty string if Homebrew
+// is not found. It checks $HOMEBREW_PREFIX, the default prefixes, a Linuxbrew
+// installation in the home directory, and as a last resort asks brew itself.
+func homebrewPrefix(ctx context.Context) string {
+	if prefix := env.Get(ctx, "HOMEBREW_PREFIX"); prefix != "" {
+		return prefix
+	}
+
+	prefixes := homebrewPrefixes
+	if home, err := env.UserHomeDir(ctx); err == nil {
+		prefixes = append(prefixes[:len(prefixes):len(prefixes)], filepath.Join(home, ".linuxbrew"))
+	}
+	for _, p := range prefixes {
+		if _, err := os.Stat(filepath.Join(p, "bin", "brew")); err == nil {
+			return p
+		}
+	}
+
+	if _, err := exec.LookPath("brew"); err != nil {
+		return ""
+	}
+	out, err := exec.CommandContext(ctx, "brew", "--prefix").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// homebrewCompletionPath returns the path to Homebrew-installed zsh completions
+// for databricks, or empty string if not found.
+func homebrewCompletionPath(prefix string) string {
+	return filepath.Join(prefix, "share", "zsh", "site-functions", "_databricks")
+}
+
+// homebrewBinary returns the resolved path of the databricks binary that
+// Homebrew manages under prefix, and the version of its formula. Homebrew
+// links the binary to the keg of the installed version, for example
+// <prefix>/Cellar/databricks/0.250.0/bin/databricks. It returns empty strings
+// if Homebrew doesn't manage a databricks binary.
+func homebrewBinary(prefix string) (path, version string) {
+	path, err := filepath.EvalSymlinks(filepath.Join(prefix, "bin", "databricks"))
+	if err != nil {
+		return "", ""
+	}
+
+	// The version is the name of the keg directory.
+	keg := filepath.Dir(filepath.Dir(path))
+	if filepath.Base(filepath.Dir(keg)) == "databricks" {
+		version = filepath.Base(keg)
+	}
+	return path, version
+}
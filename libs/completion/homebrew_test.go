@@ -0,0 +1,107 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHomebrew creates a Homebrew installation at prefix with databricks
+// version installed from its formula, including zsh completions.
+func fakeHomebrew(t *testing.T, prefix, version string) {
+	require.NoError(t, os.MkdirAll(filepath.Join(prefix, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(prefix, "bin", "brew"), nil, 0o755))
+
+	keg := filepath.Join(prefix, "Cellar", "databricks", version)
+	require.NoError(t, os.MkdirAll(filepath.Join(keg, "bin"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(keg, "bin", "databricks"), nil, 0o755))
+	require.NoError(t, os.Symlink(filepath.Join(keg, "bin", "databricks"), filepath.Join(prefix, "bin", "databricks")))
+
+	completionDir := filepath.Join(prefix, "share", "zsh", "site-functions")
+	require.NoError(t, os.MkdirAll(completionDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(completionDir, "_databricks"), []byte("#compdef databricks\n"), 0o644))
+}
+
+// useHomebrewPrefixes replaces the default Homebrew prefixes for the test.
+func useHomebrewPrefixes(t *testing.T, prefixes ...string) {
+	orig := homebrewPrefixes
+	t.Cleanup(func() { homebrewPrefixes = orig })
+	homebrewPrefixes = prefixes
+
+	t.Setenv("HOMEBREW_PREFIX", "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("PATH", t.TempDir())
+}
+
+func TestStatusHomebrewLayouts(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("homebrew is not available on windows")
+	}
+
+	tests := []struct {
+		name   string
+		prefix string
+	}{
+		{"macos", filepath.Join("opt", "homebrew")},
+		{"linuxbrew", filepath.Join("home", "linuxbrew", ".linuxbrew")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			prefix := filepath.Join(root, tt.prefix)
+			fakeHomebrew(t, prefix, "0.250.0")
+			useHomebrewPrefixes(t, filepath.Join(root, "usr", "local"), prefix)
+
+			result, err := Status(t.Context(), Zsh, t.TempDir())
+			require.NoError(t, err)
+			assert.True(t, result.Installed)
+			assert.Equal(t, "homebrew", result.Method)
+			assert.Equal(t, "0.250.0", result.HomebrewVersion)
+
+			want, err := filepath.EvalSymlinks(filepath.Join(prefix, "Cellar", "databricks", "0.250.0", "bin", "databricks"))
+			require.NoError(t, err)
+			assert.Equal(t, want, result.HomebrewBinary)
+		})
+	}
+}
+
+func TestHomebrewPrefixLinuxbrewInHome(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("homebrew is not available on windows")
+	}
+	useHomebrewPrefixes(t)
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	fakeHomebrew(t, filepath.Join(home, ".linuxbrew"), "0.250.0")
+
+	assert.Equal(t, filepath.Join(home, ".linuxbrew"), homebrewPrefix(t.Context()))
+}
+
+func TestHomebrewPrefixFromBrew(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("homebrew is not available on windows")
+	}
+	useHomebrewPrefixes(t)
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "brew"), []byte("#!/bin/sh\necho /custom/brew\n"), 0o755))
+	t.Setenv("PATH", bin)
+
+	assert.Equal(t, "/custom/brew", homebrewPrefix(t.Context()))
+}
+
+func TestHomebrewPrefixNotFound(t *testing.T) {
+	useHomebrewPrefixes(t, filepath.Join(t.TempDir(), "opt", "homebrew"))
+	assert.Empty(t, homebrewPrefix(t.Context()))
+}
+
+func TestHomebrewBinaryWithoutFormula(t *testing.T) {
+	prefix := t.TempDir()
+	path, version := homebrewBinary(prefix)
+	assert.Empty(t, path)
+	assert.Empty(t, version)
+}
@@ -2,84 +2,196 @@ package completion
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // Install configures shell completion for the given shell. homeDir is used
 // as the base for RC file resolution (typically env.UserHomeDir()).
 // Returns the file path modified and whether it was already installed.
-func Install(ctx context.Context, shell Shell, homeDir string) (filePath string, alreadyInstalled bool, err error) {
+//
+// If our completions are installed, but differ from the ones that would be
+// installed now, for example because includeDesc changed, they are updated in
+// place and alreadyInstalled is false.
+func Install(ctx context.Context, shell Shell, homeDir string, includeDesc bool) (filePath string, alreadyInstalled bool, err error) {
 	status, err := Status(ctx, shell, homeDir)
 	if err != nil {
-		return TargetFilePath(shell, homeDir), false, err
+		return TargetFilePath(ctx, shell, homeDir), false, err
 	}
 	filePath = status.FilePath
 
-	// For fish, any existing file counts as "already installed" — we don't
-	// overwrite files that may have been installed by a package manager.
-	// For RC-based shells, only our marker block counts.
-	if shell == Fish && status.Installed {
+	// For fish, nushell, elvish and oh-my-zsh, a file without our marker counts
+	// as "already installed" — we don't overwrite files that may have been
+	// installed by a package manager. For RC-based shells, only our marker
+	// block counts.
+	fileDrop := usesFileDrop(ctx, shell, homeDir)
+	content := fileContent(shell, fileDrop, includeDesc)
+	if fileDrop && status.Method == "marker" {
+		existing, err := os.ReadFile(filePath)
+		if err != nil {
+			return filePath, false, err
+		}
+		if string(existing) == content {
+			return filePath, true, nil
+		}
+		return installFile(filePath, content)
+	}
+	if fileDrop && status.Installed {
 		return filePath, true, nil
 	}
 	if status.Method == "marker" {
-		return filePath, true, nil
+		changed, err := updateRC(filePath, content)
+		return filePath, !changed, err
 	}
 
-	if shell == Fish {
-		return installFish(filePath, shell)
+	if fileDrop {
+		return installFile(filePath, content)
 	}
-	return installRC(filePath, shell)
+	return installRC(filePath, content)
 }
 
-// installFish handles the file-drop model for fish completions.
+// UpToDate reports whether our completions are installed for the given shell
+// exactly as Install would install them, so that Install wouldn't modify
+// any file.
+func UpToDate(ctx context.Context, shell Shell, homeDir string, includeDesc bool) bool {
+	content, err := os.ReadFile(TargetFilePath(ctx, shell, homeDir))
+	if err != nil {
+		return false
+	}
+	fileDrop := usesFileDrop(ctx, shell, homeDir)
+	want := fileContent(shell, fileDrop, includeDesc)
+	if fileDrop {
+		return string(content) == want
+	}
+
+	text := string(content)
+	blocks, corrupted := findBlocks(text)
+	if len(blocks) != 1 || len(corrupted) > 0 {
+		return false
+	}
+	return strings.TrimSuffix(text[blocks[0].start:blocks[0].end], "\n") == strings.TrimSuffix(want, "\n")
+}
+
+// InstallContent returns what Install writes for the given shell: the content
+// of the completion file of a file-drop shell, or the block it adds to the RC
+// file of other shells.
+func InstallContent(ctx context.Context, shell Shell, homeDir string, includeDesc bool) string {
+	return fileContent(shell, usesFileDrop(ctx, shell, homeDir), includeDesc)
+}
+
+// fileContent returns the content of the completion file of a file-drop shell,
+// or the block to add to the RC file of other shells.
+func fileContent(shell Shell, fileDrop, includeDesc bool) string {
+	if shell == Zsh && fileDrop {
+		return ohMyZshPluginContent(includeDesc)
+	}
+	return ShimContent(shell, includeDesc)
+}
+
+// installFile handles the file-drop model for fish, nushell, elvish and
+// oh-my-zsh completions.
 // The caller must check Status before calling this — existence checks are not
 // repeated here.
-func installFish(filePath string, shell Shell) (string, bool, error) {
+func installFile(filePath, content string) (string, bool, error) {
 	dir := filepath.Dir(filePath)
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return filePath, false, err
 	}
 
-	return filePath, false, os.WriteFile(filePath, []byte(ShimContent(shell)), 0o644)
+	return filePath, false, os.WriteFile(filePath, []byte(content), 0o644)
 }
 
-// installRC handles the RC file model for bash, zsh, and powershell.
-// The caller must check Status before calling this — marker checks are not
-// repeated here.
-func installRC(filePath string, shell Shell) (string, bool, error) {
-	var content []byte
-	var perm os.FileMode = 0o644
+// dedupeRC removes all but the first marker block from an RC file, so that a
+// shim copied by hand or installed by several CLI versions is evaluated once.
+func dedupeRC(filePath string) error {
+	return updateFile(filePath, func(content []byte) ([]byte, error) {
+		text := string(content)
+		blocks, corrupted := findBlocks(text)
+		if len(corrupted) > 0 {
+			return nil, corruptedBlocksError(filePath, corrupted)
+		}
+		if len(blocks) <= 1 {
+			return content, nil
+		}
+		return []byte(removeBlocks(text, blocks[1:])), nil
+	})
+}
 
-	if info, err := os.Stat(filePath); err == nil {
-		perm = info.Mode()
-		content, err = os.ReadFile(filePath)
-		if err != nil {
-			return filePath, false, err
+// updateRC replaces the first marker block in an RC file with shim, and
+// removes the others like dedupeRC. It reports whether the first block
+// differed from shim.
+func updateRC(filePath, shim string) (bool, error) {
+	changed := false
+	err := updateFile(filePath, func(content []byte) ([]byte, error) {
+		text := string(content)
+		blocks, corrupted := findBlocks(text)
+		if len(corrupted) > 0 {
+			return nil, corruptedBlocksError(filePath, corrupted)
 		}
-	}
+		if len(blocks) == 0 {
+			return content, nil
+		}
+		first := blocks[0]
+		rest := text[first.end:]
+		if len(blocks) > 1 {
+			rest = removeBlocks(rest, shiftBlocks(blocks[1:], -first.end))
+		}
+		changed = strings.TrimSuffix(text[first.start:first.end], "\n") != strings.TrimSuffix(shim, "\n")
+		updated := text[:first.start] + shim + rest
+		return []byte(updated), nil
+	})
+	return changed, err
+}
 
-	// Create parent directory if needed (e.g. for PowerShell profiles).
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0o755); err != nil {
-		return filePath, false, err
+// shiftBlocks returns blocks with their positions moved by offset.
+func shiftBlocks(blocks []block, offset int) []block {
+	shifted := make([]block, len(blocks))
+	for i, b := range blocks {
+		shifted[i] = block{start: b.start + offset, end: b.end + offset}
 	}
+	return shifted
+}
 
-	// Ensure a leading newline before the block if the file doesn't end with one.
-	shim := ShimContent(shell)
-	if len(content) > 0 && content[len(content)-1] != '\n' {
-		shim = "\n" + shim
-	}
+// installRC handles the RC file model for bash, zsh, and powershell.
+// The caller must check Status before calling this — marker checks are not
+// repeated here.
+func installRC(filePath, shim string) (string, bool, error) {
+	err := updateFile(filePath, func(content []byte) ([]byte, error) {
+		// Ensure a leading newline before the block if the file doesn't end with one.
+		// The update can be retried, so shim itself is not modified.
+		block := shim
+		if len(content) > 0 && content[len(content)-1] != '\n' {
+			block = "\n" + block
+		}
+		return append(content, block...), nil
+	})
+	return filePath, false, err
+}
 
-	f, err := os.OpenFile(filePath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, perm)
+// ReplaceForeign replaces a completion file of a file-drop shell that wasn't
+// installed by the CLI, for example a stale file from an old package, with
+// our shim. The file is moved to a backup first, whose path is returned.
+func ReplaceForeign(ctx context.Context, shell Shell, homeDir string, includeDesc bool) (filePath, backupPath string, err error) {
+	status, err := Status(ctx, shell, homeDir)
 	if err != nil {
-		return filePath, false, err
+		return "", "", err
+	}
+	filePath = status.FilePath
+	if !usesFileDrop(ctx, shell, homeDir) || status.Method != "file" {
+		return filePath, "", fmt.Errorf("%s is not a completion file that was installed by another tool", filePath)
 	}
-	defer f.Close()
 
-	if _, err := f.WriteString(shim); err != nil {
-		return filePath, false, err
+	// Don't overwrite backups from earlier replacements.
+	backupPath = filePath + ".bak"
+	for i := 1; exists(backupPath); i++ {
+		backupPath = fmt.Sprintf("%s.bak.%d", filePath, i)
+	}
+	if err := os.Rename(filePath, backupPath); err != nil {
+		return filePath, "", err
 	}
 
-	return filePath, false, nil
+	_, _, err = installFile(filePath, fileContent(shell, true, includeDesc))
+	return filePath, backupPath, err
 }
@@ -1,6 +1,8 @@
 package completion
 
 import (
+	"errors"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -15,7 +17,7 @@ import (
 func TestInstallFreshZsh(t *testing.T) {
 	home := t.TempDir()
 
-	filePath, alreadyInstalled, err := Install(t.Context(), Zsh, home)
+	filePath, alreadyInstalled, err := Install(t.Context(), Zsh, home, true)
 	require.NoError(t, err)
 	assert.False(t, alreadyInstalled)
 	assert.Equal(t, filepath.Join(home, ".zshrc"), filePath)
@@ -24,16 +26,16 @@ func TestInstallFreshZsh(t *testing.T) {
 	require.NoError(t, err)
 	assert.Contains(t, string(content), BeginMarker)
 	assert.Contains(t, string(content), EndMarker)
-	assert.Contains(t, string(content), `eval "$(databricks completion zsh)"`)
+	assert.Contains(t, string(content), "databricks completion cache --shell zsh")
 }
 
 func TestInstallIdempotent(t *testing.T) {
 	home := t.TempDir()
 
-	_, _, err := Install(t.Context(), Zsh, home)
+	_, _, err := Install(t.Context(), Zsh, home, true)
 	require.NoError(t, err)
 
-	filePath, alreadyInstalled, err := Install(t.Context(), Zsh, home)
+	filePath, alreadyInstalled, err := Install(t.Context(), Zsh, home, true)
 	require.NoError(t, err)
 	assert.True(t, alreadyInstalled)
 
@@ -47,7 +49,7 @@ func TestInstallAppendsToExistingFile(t *testing.T) {
 	rcPath := filepath.Join(home, ".zshrc")
 	require.NoError(t, os.WriteFile(rcPath, []byte("# existing config\n"), 0o644))
 
-	_, _, err := Install(t.Context(), Zsh, home)
+	_, _, err := Install(t.Context(), Zsh, home, true)
 	require.NoError(t, err)
 
 	content, err := os.ReadFile(rcPath)
@@ -61,7 +63,7 @@ func TestInstallAddsNewlineIfMissing(t *testing.T) {
 	rcPath := filepath.Join(home, ".zshrc")
 	require.NoError(t, os.WriteFile(rcPath, []byte("# no trailing newline"), 0o644))
 
-	_, _, err := Install(t.Context(), Zsh, home)
+	_, _, err := Install(t.Context(), Zsh, home, true)
 	require.NoError(t, err)
 
 	content, err := os.ReadFile(rcPath)
@@ -74,7 +76,7 @@ func TestInstallPreservesPermissions(t *testing.T) {
 	rcPath := filepath.Join(home, ".zshrc")
 	require.NoError(t, os.WriteFile(rcPath, []byte(""), 0o600))
 
-	_, _, err := Install(t.Context(), Zsh, home)
+	_, _, err := Install(t.Context(), Zsh, home, true)
 	require.NoError(t, err)
 
 	info, err := os.Stat(rcPath)
@@ -89,17 +91,48 @@ func TestInstallPreservesPermissions(t *testing.T) {
 	}
 }
 
+func TestInstallRemovesDuplicatedBlocks(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	rcPath := filepath.Join(home, ".zshrc")
+	content := "# before\n" + ShimContent(Zsh, true) + "# middle\n" + ShimContent(Zsh, true) + "# after\n"
+	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
+
+	_, alreadyInstalled, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+	assert.True(t, alreadyInstalled)
+
+	result, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# before\n"+ShimContent(Zsh, true)+"# middle\n# after\n", string(result))
+}
+
+func TestInstallCorruptedBlock(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	rcPath := filepath.Join(home, ".zshrc")
+	content := ShimContent(Zsh, true) + BeginMarker + "\neval something\n"
+	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
+
+	_, _, err := Install(t.Context(), Zsh, home, true)
+	assert.ErrorContains(t, err, fmt.Sprintf("line %d", strings.Count(ShimContent(Zsh, true), "\n")+1))
+
+	result, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Equal(t, content, string(result))
+}
+
 func TestInstallFish(t *testing.T) {
 	home := t.TempDir()
 
-	filePath, alreadyInstalled, err := Install(t.Context(), Fish, home)
+	filePath, alreadyInstalled, err := Install(t.Context(), Fish, home, true)
 	require.NoError(t, err)
 	assert.False(t, alreadyInstalled)
 	assert.Equal(t, filepath.Join(home, ".config", "fish", "completions", "databricks.fish"), filePath)
 
 	content, err := os.ReadFile(filePath)
 	require.NoError(t, err)
-	assert.Contains(t, string(content), "databricks completion fish | source")
+	assert.Contains(t, string(content), "databricks completion cache --shell fish")
 }
 
 func TestInstallFishForeignFilePreserved(t *testing.T) {
@@ -110,7 +143,7 @@ func TestInstallFishForeignFilePreserved(t *testing.T) {
 	original := "# fish completion from package manager\n"
 	require.NoError(t, os.WriteFile(filePath, []byte(original), 0o644))
 
-	gotPath, alreadyInstalled, err := Install(t.Context(), Fish, home)
+	gotPath, alreadyInstalled, err := Install(t.Context(), Fish, home, true)
 	require.NoError(t, err)
 	assert.True(t, alreadyInstalled)
 	assert.Equal(t, filePath, gotPath)
@@ -118,15 +151,52 @@ func TestInstallFishForeignFilePreserved(t *testing.T) {
 	content, err := os.ReadFile(filePath)
 	require.NoError(t, err)
 	assert.Equal(t, original, string(content))
+
+	// Once confirmed or forced, the file is backed up and replaced.
+	gotPath, backupPath, err := ReplaceForeign(t.Context(), Fish, home, true)
+	require.NoError(t, err)
+	assert.Equal(t, filePath, gotPath)
+	assert.Equal(t, filePath+".bak", backupPath)
+
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, ShimContent(Fish, true), string(content))
+	content, err = os.ReadFile(backupPath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+
+	status, err := Status(t.Context(), Fish, home)
+	require.NoError(t, err)
+	assert.Equal(t, "marker", status.Method)
+
+	// Our own file is not replaced.
+	_, _, err = ReplaceForeign(t.Context(), Fish, home, true)
+	assert.ErrorContains(t, err, "is not a completion file that was installed by another tool")
+}
+
+func TestReplaceForeignKeepsEarlierBackups(t *testing.T) {
+	home := t.TempDir()
+	filePath := filepath.Join(home, ".config", "fish", "completions", "databricks.fish")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+	require.NoError(t, os.WriteFile(filePath, []byte("# new package\n"), 0o644))
+	require.NoError(t, os.WriteFile(filePath+".bak", []byte("# old package\n"), 0o644))
+
+	_, backupPath, err := ReplaceForeign(t.Context(), Fish, home, true)
+	require.NoError(t, err)
+	assert.Equal(t, filePath+".bak.1", backupPath)
+
+	content, err := os.ReadFile(filePath + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, "# old package\n", string(content))
 }
 
 func TestInstallFishIdempotent(t *testing.T) {
 	home := t.TempDir()
 
-	_, _, err := Install(t.Context(), Fish, home)
+	_, _, err := Install(t.Context(), Fish, home, true)
 	require.NoError(t, err)
 
-	_, alreadyInstalled, err := Install(t.Context(), Fish, home)
+	_, alreadyInstalled, err := Install(t.Context(), Fish, home, true)
 	require.NoError(t, err)
 	assert.True(t, alreadyInstalled)
 }
@@ -138,17 +208,65 @@ func TestInstallFishCreatesDirectory(t *testing.T) {
 	_, err := os.Stat(fishDir)
 	assert.ErrorIs(t, err, fs.ErrNotExist)
 
-	_, _, err = Install(t.Context(), Fish, home)
+	_, _, err = Install(t.Context(), Fish, home, true)
 	require.NoError(t, err)
 
 	_, err = os.Stat(fishDir)
 	assert.NoError(t, err)
 }
 
+func TestInstallNushell(t *testing.T) {
+	home := t.TempDir()
+
+	filePath, alreadyInstalled, err := Install(t.Context(), Nushell, home, true)
+	require.NoError(t, err)
+	assert.False(t, alreadyInstalled)
+	assert.Equal(t, filepath.Join(home, ".config", "nushell", "autoload", "databricks.nu"), filePath)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, ShimContent(Nushell, true), string(content))
+
+	_, alreadyInstalled, err = Install(t.Context(), Nushell, home, true)
+	require.NoError(t, err)
+	assert.True(t, alreadyInstalled)
+}
+
+func TestInstallElvish(t *testing.T) {
+	home := t.TempDir()
+
+	filePath, alreadyInstalled, err := Install(t.Context(), Elvish, home, true)
+	require.NoError(t, err)
+	assert.False(t, alreadyInstalled)
+	assert.Equal(t, filepath.Join(home, ".elvish", "lib", "databricks.elv"), filePath)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, ShimContent(Elvish, true), string(content))
+}
+
+func TestInstallElvishForeignFilePreserved(t *testing.T) {
+	home := t.TempDir()
+	filePath := filepath.Join(home, ".elvish", "lib", "databricks.elv")
+	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+
+	original := "# elvish completion written by hand\n"
+	require.NoError(t, os.WriteFile(filePath, []byte(original), 0o644))
+
+	_, alreadyInstalled, err := Install(t.Context(), Elvish, home, true)
+	require.NoError(t, err)
+	assert.True(t, alreadyInstalled)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, original, string(content))
+}
+
 func TestInstallPowerShellCreatesDirectory(t *testing.T) {
+	fakePowerShell(t, "", errors.New("not found"))
 	home := t.TempDir()
 
-	filePath, _, err := Install(t.Context(), PowerShell, home)
+	filePath, _, err := Install(t.Context(), PowerShell, home, true)
 	require.NoError(t, err)
 
 	_, err = os.Stat(filepath.Dir(filePath))
@@ -156,19 +274,19 @@ func TestInstallPowerShellCreatesDirectory(t *testing.T) {
 
 	content, err := os.ReadFile(filePath)
 	require.NoError(t, err)
-	assert.Contains(t, string(content), "databricks completion powershell | Out-String | Invoke-Expression")
+	assert.Contains(t, string(content), "databricks completion cache --shell powershell")
 }
 
 func TestInstallBashShimContent(t *testing.T) {
 	home := t.TempDir()
 
-	_, _, err := Install(t.Context(), Bash, home)
+	_, _, err := Install(t.Context(), Bash, home, true)
 	require.NoError(t, err)
 
-	filePath := TargetFilePath(Bash, home)
+	filePath := TargetFilePath(t.Context(), Bash, home)
 	content, err := os.ReadFile(filePath)
 	require.NoError(t, err)
-	assert.Contains(t, string(content), `eval "$(databricks completion bash)"`)
+	assert.Contains(t, string(content), "databricks completion cache --shell bash")
 }
 
 func TestInstallEmptyFile(t *testing.T) {
@@ -176,10 +294,175 @@ func TestInstallEmptyFile(t *testing.T) {
 	rcPath := filepath.Join(home, ".zshrc")
 	require.NoError(t, os.WriteFile(rcPath, []byte(""), 0o644))
 
-	_, _, err := Install(t.Context(), Zsh, home)
+	_, _, err := Install(t.Context(), Zsh, home, true)
 	require.NoError(t, err)
 
 	content, err := os.ReadFile(rcPath)
 	require.NoError(t, err)
 	assert.Contains(t, string(content), BeginMarker)
 }
+
+func TestInstallStatusUninstallHonorZdotdir(t *testing.T) {
+	home := t.TempDir()
+	zdotdir := t.TempDir()
+	t.Setenv("ZDOTDIR", zdotdir)
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	rcPath := filepath.Join(zdotdir, ".zshrc")
+
+	filePath, _, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+	assert.Equal(t, rcPath, filePath)
+	assert.NoFileExists(t, filepath.Join(home, ".zshrc"))
+
+	status, err := Status(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, status.Installed)
+	assert.Equal(t, rcPath, status.FilePath)
+
+	filePath, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+	assert.Equal(t, rcPath, filePath)
+}
+
+func TestInstallTogglesDescriptions(t *testing.T) {
+	for _, shell := range []Shell{Zsh, Fish, Nushell} {
+		t.Run(string(shell), func(t *testing.T) {
+			home := t.TempDir()
+			t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+			filePath := TargetFilePath(t.Context(), shell, home)
+			require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+			if shell == Zsh {
+				require.NoError(t, os.WriteFile(filePath, []byte("# before\n"), 0o644))
+			}
+
+			_, alreadyInstalled, err := Install(t.Context(), shell, home, true)
+			require.NoError(t, err)
+			assert.False(t, alreadyInstalled)
+
+			// Disable descriptions.
+			_, alreadyInstalled, err = Install(t.Context(), shell, home, false)
+			require.NoError(t, err)
+			assert.False(t, alreadyInstalled)
+			status, err := Status(t.Context(), shell, home)
+			require.NoError(t, err)
+			assert.False(t, status.Descriptions)
+
+			_, alreadyInstalled, err = Install(t.Context(), shell, home, false)
+			require.NoError(t, err)
+			assert.True(t, alreadyInstalled)
+
+			// Enable them again.
+			_, alreadyInstalled, err = Install(t.Context(), shell, home, true)
+			require.NoError(t, err)
+			assert.False(t, alreadyInstalled)
+			status, err = Status(t.Context(), shell, home)
+			require.NoError(t, err)
+			assert.True(t, status.Descriptions)
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			if shell == Zsh {
+				assert.Equal(t, "# before\n"+ShimContent(shell, true), string(content))
+			} else {
+				assert.Equal(t, ShimContent(shell, true), string(content))
+			}
+		})
+	}
+}
+
+func TestInstallUpdatesBlockInPlace(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	rcPath := filepath.Join(home, ".zshrc")
+	content := "# before\n" + ShimContent(Zsh, true) + "# after\n" + ShimContent(Zsh, true)
+	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
+
+	_, alreadyInstalled, err := Install(t.Context(), Zsh, home, false)
+	require.NoError(t, err)
+	assert.False(t, alreadyInstalled)
+
+	// The first block is updated and the duplicate is removed.
+	got, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# before\n"+ShimContent(Zsh, false)+"# after\n", string(got))
+}
+
+func TestShimContentWithoutDescriptions(t *testing.T) {
+	assert.Contains(t, ShimContent(Bash, false), "databricks completion cache --shell bash --no-descriptions")
+	assert.Contains(t, ShimContent(Fish, false), "databricks completion cache --shell fish --no-descriptions")
+	assert.Contains(t, ShimContent(PowerShell, false), "databricks completion cache --shell powershell --no-descriptions")
+	assert.Contains(t, ShimContent(Nushell, false), "__completeNoDesc")
+	assert.NotContains(t, ShimContent(Zsh, true), "--no-descriptions")
+}
+
+func TestInstallThroughSymlinkedRC(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on windows")
+	}
+	home := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	target, err := filepath.EvalSymlinks(home)
+	require.NoError(t, err)
+	target = filepath.Join(target, "dotfiles", "zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	require.NoError(t, os.WriteFile(target, []byte("# mine\n"), 0o644))
+	link := filepath.Join(home, ".zshrc")
+	require.NoError(t, os.Symlink(target, link))
+
+	status, err := Status(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.Equal(t, target, status.LinkTarget)
+	assert.False(t, UpToDate(t.Context(), Zsh, home, true))
+
+	filePath, _, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+	assert.Equal(t, link, filePath)
+	assert.True(t, UpToDate(t.Context(), Zsh, home, true))
+	assert.False(t, UpToDate(t.Context(), Zsh, home, false))
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\n"+ShimContent(Zsh, true), string(content))
+
+	// Uninstall edits the same file.
+	_, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+	info, err = os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+	content, err = os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\n", string(content))
+}
+
+func TestInstallThroughSymlinkedCompletionFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on windows")
+	}
+	home := t.TempDir()
+	target := filepath.Join(home, "dotfiles", "databricks.fish")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	link := TargetFilePath(t.Context(), Fish, home)
+	require.NoError(t, os.MkdirAll(filepath.Dir(link), 0o755))
+	require.NoError(t, os.Symlink(target, link))
+
+	_, alreadyInstalled, err := Install(t.Context(), Fish, home, true)
+	require.NoError(t, err)
+	assert.False(t, alreadyInstalled)
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, ShimContent(Fish, true), string(content))
+
+	// Uninstall removes the file that was written along with the link.
+	_, wasInstalled, err := Uninstall(t.Context(), Fish, home)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+	assert.NoFileExists(t, target)
+	_, err = os.Lstat(link)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
@@ -0,0 +1,76 @@
+package completion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/libs/env"
+)
+
+// Oh-my-zsh initializes the completion system itself when .zshrc sources
+// oh-my-zsh.sh. A shim appended to .zshrc can run before that and fail, so
+// for oh-my-zsh users completions are installed as a custom plugin instead.
+// The plugin is a _databricks completion function that oh-my-zsh adds to
+// $fpath once "databricks" is listed in the plugins array in .zshrc.
+
+// OhMyZshDir returns the oh-my-zsh installation directory: $ZSH if it is set,
+// or ~/.oh-my-zsh if it exists. It returns an empty string if oh-my-zsh is
+// not detected.
+func OhMyZshDir(ctx context.Context, homeDir string) string {
+	if dir := env.Get(ctx, "ZSH"); dir != "" {
+		return dir
+	}
+	dir := filepath.Join(homeDir, ".oh-my-zsh")
+	if exists(dir) {
+		return dir
+	}
+	return ""
+}
+
+// ohMyZshPluginPath returns the completion function of the databricks plugin
+// in $ZSH_CUSTOM, which defaults to the custom directory of oh-my-zsh.
+func ohMyZshPluginPath(ctx context.Context, ohMyZshDir string) string {
+	custom := env.Get(ctx, "ZSH_CUSTOM")
+	if custom == "" {
+		custom = filepath.Join(ohMyZshDir, "custom")
+	}
+	return filepath.Join(custom, "plugins", "databricks", "_databricks")
+}
+
+// ohMyZshPluginContent returns the completion function of the databricks
+// plugin. It sources the cached script like the shim for zsh does. The script
+// redefines _databricks, which is then called to complete the command line.
+func ohMyZshPluginContent(includeDesc bool) string {
+	flags := "--shell zsh"
+	if !includeDesc {
+		flags += " " + noDescriptionsFlag
+	}
+	return "#compdef databricks\n" + BeginMarker + `
+if [ -f "$HOME/.databricks/completions/zsh" ]; then
+    source "$HOME/.databricks/completions/zsh"
+    _databricks "$@"
+fi
+(databricks completion cache ` + flags + ` >/dev/null 2>&1 &)
+` + EndMarker + "\n"
+}
+
+var ohMyZshPlugins = regexp.MustCompile(`(?m)^\s*plugins=\(([^)]*)\)`)
+
+// OhMyZshPluginEnabled reports whether the databricks plugin is listed in the
+// plugins array in .zshrc.
+func OhMyZshPluginEnabled(ctx context.Context, homeDir string) bool {
+	content, err := os.ReadFile(zshrcPath(ctx, homeDir))
+	if err != nil {
+		return false
+	}
+	for _, m := range ohMyZshPlugins.FindAllStringSubmatch(string(content), -1) {
+		if slices.Contains(strings.Fields(m[1]), "databricks") {
+			return true
+		}
+	}
+	return false
+}
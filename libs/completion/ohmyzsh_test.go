@@ -0,0 +1,128 @@
+package completion
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupOhMyZsh simulates an oh-my-zsh installation in home.
+func setupOhMyZsh(t *testing.T, home, zshrc string) {
+	t.Setenv("ZSH", "")
+	t.Setenv("ZSH_CUSTOM", "")
+	t.Setenv("ZDOTDIR", "")
+	t.Setenv("HOMEBREW_PREFIX", "/nonexistent")
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".oh-my-zsh", "custom", "plugins"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".zshrc"), []byte(zshrc), 0o644))
+}
+
+func TestOhMyZshDir(t *testing.T) {
+	t.Setenv("ZSH", "")
+	home := t.TempDir()
+	assert.Empty(t, OhMyZshDir(t.Context(), home))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".oh-my-zsh"), 0o755))
+	assert.Equal(t, filepath.Join(home, ".oh-my-zsh"), OhMyZshDir(t.Context(), home))
+
+	t.Setenv("ZSH", "/opt/oh-my-zsh")
+	assert.Equal(t, "/opt/oh-my-zsh", OhMyZshDir(t.Context(), home))
+}
+
+func TestTargetFilePathOhMyZsh(t *testing.T) {
+	home := t.TempDir()
+	setupOhMyZsh(t, home, "")
+	assert.Equal(t, filepath.Join(home, ".oh-my-zsh", "custom", "plugins", "databricks", "_databricks"), TargetFilePath(t.Context(), Zsh, home))
+
+	custom := t.TempDir()
+	t.Setenv("ZSH_CUSTOM", custom)
+	assert.Equal(t, filepath.Join(custom, "plugins", "databricks", "_databricks"), TargetFilePath(t.Context(), Zsh, home))
+}
+
+func TestInstallOhMyZsh(t *testing.T) {
+	home := t.TempDir()
+	zshrc := "export ZSH=\"$HOME/.oh-my-zsh\"\nplugins=(git)\nsource $ZSH/oh-my-zsh.sh\n"
+	setupOhMyZsh(t, home, zshrc)
+	pluginPath := filepath.Join(home, ".oh-my-zsh", "custom", "plugins", "databricks", "_databricks")
+
+	filePath, alreadyInstalled, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+	assert.False(t, alreadyInstalled)
+	assert.Equal(t, pluginPath, filePath)
+
+	content, err := os.ReadFile(pluginPath)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), "#compdef databricks\n"))
+	assert.Contains(t, string(content), BeginMarker)
+
+	// .zshrc is not modified.
+	got, err := os.ReadFile(filepath.Join(home, ".zshrc"))
+	require.NoError(t, err)
+	assert.Equal(t, zshrc, string(got))
+
+	status, err := Status(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, status.Installed)
+	assert.Equal(t, "marker", status.Method)
+
+	_, alreadyInstalled, err = Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+	assert.True(t, alreadyInstalled)
+
+	_, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+	_, err = os.Stat(pluginPath)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestInstallOhMyZshForeignPluginPreserved(t *testing.T) {
+	home := t.TempDir()
+	setupOhMyZsh(t, home, "")
+	pluginPath := filepath.Join(home, ".oh-my-zsh", "custom", "plugins", "databricks", "_databricks")
+	require.NoError(t, os.MkdirAll(filepath.Dir(pluginPath), 0o755))
+	require.NoError(t, os.WriteFile(pluginPath, []byte("#compdef databricks\n"), 0o644))
+
+	_, alreadyInstalled, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+	assert.True(t, alreadyInstalled)
+
+	status, err := Status(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.Equal(t, "file", status.Method)
+}
+
+func TestOhMyZshPluginEnabled(t *testing.T) {
+	tests := []struct {
+		name    string
+		zshrc   string
+		enabled bool
+	}{
+		{"no plugins", "source $ZSH/oh-my-zsh.sh\n", false},
+		{"other plugins", "plugins=(git docker)\n", false},
+		{"similar name", "plugins=(git databricks-cli)\n", false},
+		{"enabled", "plugins=(git databricks)\n", true},
+		{"multiline", "plugins=(\n  git\n  databricks\n)\n", true},
+		{"commented out", "# plugins=(databricks)\nplugins=(git)\n", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			setupOhMyZsh(t, home, tt.zshrc)
+			assert.Equal(t, tt.enabled, OhMyZshPluginEnabled(t.Context(), home))
+		})
+	}
+}
+
+func TestDetectShellsOhMyZsh(t *testing.T) {
+	home := t.TempDir()
+	setupOhMyZsh(t, home, "")
+	t.Setenv("SHELL", "")
+	t.Setenv("PATH", t.TempDir())
+	assert.Contains(t, DetectShells(t.Context(), home), Zsh)
+}
@@ -0,0 +1,43 @@
+package completion
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// parentProcessName returns the executable name of the parent process. It is
+// a variable so that tests can fake the lookup.
+var parentProcessName = func(ctx context.Context) (string, error) {
+	ppid := strconv.Itoa(os.Getppid())
+	switch runtime.GOOS {
+	case "linux":
+		comm, err := os.ReadFile("/proc/" + ppid + "/comm")
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(comm)), nil
+	case "darwin":
+		out, err := exec.CommandContext(ctx, "ps", "-o", "comm=", "-p", ppid).Output()
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(out)), nil
+	default:
+		return "", errors.New("looking up the parent process is not supported on " + runtime.GOOS)
+	}
+}
+
+// shellFromParentProcess returns the shell that started the CLI.
+func shellFromParentProcess(ctx context.Context) (Shell, error) {
+	name, err := parentProcessName(ctx)
+	if err != nil {
+		return "", err
+	}
+	// Login shells are reported with a leading dash, e.g. "-zsh".
+	return shellFromPath(strings.TrimPrefix(name, "-"))
+}
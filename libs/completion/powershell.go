@@ -0,0 +1,49 @@
+package completion
+
+import (
+	"context"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/databricks/cli/libs/log"
+)
+
+// powershellProfileTimeout bounds how long we wait for PowerShell to report
+// its profile path. Starting PowerShell can be slow, but it shouldn't block
+// the completion commands if it hangs.
+const powershellProfileTimeout = 5 * time.Second
+
+// runPowerShell runs a PowerShell executable and returns its output. It is a
+// variable so that tests can fake it.
+var runPowerShell = func(ctx context.Context, name string, args ...string) (string, error) {
+	out, err := exec.CommandContext(ctx, name, args...).Output()
+	return string(out), err
+}
+
+// queryPowerShellProfile asks PowerShell for the path of its profile. This
+// accounts for a Documents folder that is redirected, for example to OneDrive,
+// which the static paths miss. It returns an empty string if PowerShell isn't
+// available or doesn't report an absolute path.
+func queryPowerShellProfile(ctx context.Context, shell Shell) string {
+	name := "pwsh"
+	if shell == PowerShell5 {
+		name = "powershell.exe"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, powershellProfileTimeout)
+	defer cancel()
+	out, err := runPowerShell(ctx, name, "-NoProfile", "-NonInteractive", "-Command", "$PROFILE.CurrentUserAllHosts")
+	if err != nil {
+		log.Debugf(ctx, "Failed to query the profile path from %s: %s", name, err)
+		return ""
+	}
+
+	path := strings.TrimSpace(out)
+	if !filepath.IsAbs(path) {
+		log.Debugf(ctx, "Ignoring profile path %q reported by %s", path, name)
+		return ""
+	}
+	return path
+}
@@ -0,0 +1,116 @@
+package completion
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/databricks/cli/libs/fileutil"
+)
+
+// Completions for resource IDs are served from a cache in the home directory,
+// because listing resources takes too long to do on every TAB. Stale entries
+// are still returned while they are refreshed in the background.
+
+// ResourceCacheTTL is how long cached resources are considered fresh.
+const ResourceCacheTTL = 5 * time.Minute
+
+// resourceRefreshTimeout is how long a claim to refresh the cached resources
+// is honored. It guards against a refresh that never finished.
+const resourceRefreshTimeout = time.Minute
+
+type resourceCache struct {
+	FetchedAt time.Time  `json:"fetched_at"`
+	Resources []Resource `json:"resources"`
+}
+
+// ResourceCachePath returns the file that caches resources of the given kind
+// for the workspace at host.
+func ResourceCachePath(homeDir, host, kind string) string {
+	return filepath.Join(homeDir, ".databricks", "completion-cache", hostDir(host), kind+".json")
+}
+
+// hostDir returns a directory name for the workspace at host.
+func hostDir(host string) string {
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return strings.NewReplacer(":", "_", "/", "_", `\`, "_").Replace(host)
+}
+
+// ReadResourceCache returns the cached resources at path and whether they
+// were fetched less than [ResourceCacheTTL] before now. ok is false if
+// nothing is cached or the cache can't be read.
+func ReadResourceCache(path string, now time.Time) (resources []Resource, fresh, ok bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, false
+	}
+	var cache resourceCache
+	if err := json.Unmarshal(data, &cache); err != nil {
+		return nil, false, false
+	}
+	return cache.Resources, now.Sub(cache.FetchedAt) < ResourceCacheTTL, true
+}
+
+// WriteResourceCache caches resources at path as fetched at now. The file is
+// replaced atomically, because completions may read it concurrently.
+func WriteResourceCache(path string, resources []Resource, now time.Time) error {
+	data, err := json.Marshal(resourceCache{FetchedAt: now, Resources: resources})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return fileutil.WriteFile(path, 0o600, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// refreshMarkerPath returns the file that marks a refresh of the cache at path
+// as in progress.
+func refreshMarkerPath(path string) string {
+	return path + ".refresh"
+}
+
+// ClaimResourceRefresh reports whether the caller should refresh the cache at
+// path. It returns false if another refresh started less than a minute before
+// now, so that repeated completions don't each start a refresh. The claim is
+// released by [ReleaseResourceRefresh].
+func ClaimResourceRefresh(path string, now time.Time) bool {
+	marker := refreshMarkerPath(path)
+	if info, err := os.Stat(marker); err == nil {
+		if now.Sub(info.ModTime()) < resourceRefreshTimeout {
+			return false
+		}
+		_ = os.Remove(marker)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return false
+	}
+	f, err := os.OpenFile(marker, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return false
+	}
+	f.Close()
+	return os.Chtimes(marker, now, now) == nil
+}
+
+// ReleaseResourceRefresh releases a claim made by [ClaimResourceRefresh].
+func ReleaseResourceRefresh(path string) error {
+	err := os.Remove(refreshMarkerPath(path))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
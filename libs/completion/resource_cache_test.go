@@ -0,0 +1,82 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceCachePath(t *testing.T) {
+	home := t.TempDir()
+	expected := filepath.Join(home, ".databricks", "completion-cache", "adb-123.azuredatabricks.net", "clusters.json")
+	assert.Equal(t, expected, ResourceCachePath(home, "https://adb-123.azuredatabricks.net/", "clusters"))
+	assert.Equal(t, expected, ResourceCachePath(home, "adb-123.azuredatabricks.net", "clusters"))
+
+	got := ResourceCachePath(home, "http://localhost:8080", "jobs")
+	assert.Equal(t, filepath.Join(home, ".databricks", "completion-cache", "localhost_8080", "jobs.json"), got)
+}
+
+func TestResourceCacheRoundTrip(t *testing.T) {
+	path := ResourceCachePath(t.TempDir(), "https://example.com", "clusters")
+	now := time.Now()
+	resources := []Resource{{ID: "a", Name: "Cluster A"}, {ID: "b"}}
+
+	require.NoError(t, WriteResourceCache(path, resources, now))
+
+	got, fresh, ok := ReadResourceCache(path, now.Add(time.Minute))
+	require.True(t, ok)
+	assert.True(t, fresh)
+	assert.Equal(t, resources, got)
+
+	got, fresh, ok = ReadResourceCache(path, now.Add(ResourceCacheTTL))
+	require.True(t, ok)
+	assert.False(t, fresh)
+	assert.Equal(t, resources, got)
+}
+
+func TestReadResourceCacheMissingOrCorrupted(t *testing.T) {
+	path := ResourceCachePath(t.TempDir(), "https://example.com", "clusters")
+	_, _, ok := ReadResourceCache(path, time.Now())
+	assert.False(t, ok)
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+	_, _, ok = ReadResourceCache(path, time.Now())
+	assert.False(t, ok)
+}
+
+func TestWriteResourceCacheReplacesFile(t *testing.T) {
+	path := ResourceCachePath(t.TempDir(), "https://example.com", "jobs")
+	now := time.Now()
+	require.NoError(t, WriteResourceCache(path, []Resource{{ID: "1"}}, now))
+	require.NoError(t, WriteResourceCache(path, []Resource{{ID: "2"}}, now))
+
+	got, _, ok := ReadResourceCache(path, now)
+	require.True(t, ok)
+	assert.Equal(t, []Resource{{ID: "2"}}, got)
+
+	// No temporary files are left behind.
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestClaimResourceRefresh(t *testing.T) {
+	path := ResourceCachePath(t.TempDir(), "https://example.com", "warehouses")
+	now := time.Now()
+
+	assert.True(t, ClaimResourceRefresh(path, now))
+	assert.False(t, ClaimResourceRefresh(path, now.Add(time.Second)))
+
+	// A claim that was never released expires.
+	assert.True(t, ClaimResourceRefresh(path, now.Add(2*resourceRefreshTimeout)))
+
+	require.NoError(t, ReleaseResourceRefresh(path))
+	assert.True(t, ClaimResourceRefresh(path, now))
+	require.NoError(t, ReleaseResourceRefresh(path))
+	require.NoError(t, ReleaseResourceRefresh(path))
+}
@@ -0,0 +1,89 @@
+package completion
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/databricks/databricks-sdk-go"
+	"github.com/databricks/databricks-sdk-go/service/compute"
+	"github.com/databricks/databricks-sdk-go/service/jobs"
+	"github.com/databricks/databricks-sdk-go/service/sql"
+)
+
+// Resource is a workspace resource offered as a completion.
+type Resource struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// ResourceKind describes a kind of workspace resource whose IDs are completed.
+type ResourceKind struct {
+	// Name is used for the cache file and the refresh command.
+	Name string
+
+	// Flag is the name of the flags that take an ID of this kind.
+	Flag string
+
+	// Arg is the name of the positional arguments that take an ID of this kind.
+	Arg string
+
+	// List fetches all resources of this kind from the workspace.
+	List func(ctx context.Context, w *databricks.WorkspaceClient) ([]Resource, error)
+}
+
+// ResourceKinds lists the kinds of resources whose IDs are completed.
+var ResourceKinds = []ResourceKind{
+	{Name: "clusters", Flag: "cluster-id", Arg: "CLUSTER_ID", List: listClusters},
+	{Name: "warehouses", Flag: "warehouse-id", Arg: "WAREHOUSE_ID", List: listWarehouses},
+	{Name: "jobs", Flag: "job-id", Arg: "JOB_ID", List: listJobs},
+}
+
+// LookupResourceKind returns the resource kind with the given name.
+func LookupResourceKind(name string) (ResourceKind, bool) {
+	for _, kind := range ResourceKinds {
+		if kind.Name == name {
+			return kind, true
+		}
+	}
+	return ResourceKind{}, false
+}
+
+func listClusters(ctx context.Context, w *databricks.WorkspaceClient) ([]Resource, error) {
+	clusters, err := w.Clusters.ListAll(ctx, compute.ListClustersRequest{})
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]Resource, 0, len(clusters))
+	for _, c := range clusters {
+		resources = append(resources, Resource{ID: c.ClusterId, Name: c.ClusterName})
+	}
+	return resources, nil
+}
+
+func listWarehouses(ctx context.Context, w *databricks.WorkspaceClient) ([]Resource, error) {
+	warehouses, err := w.Warehouses.ListAll(ctx, sql.ListWarehousesRequest{})
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]Resource, 0, len(warehouses))
+	for _, wh := range warehouses {
+		resources = append(resources, Resource{ID: wh.Id, Name: wh.Name})
+	}
+	return resources, nil
+}
+
+func listJobs(ctx context.Context, w *databricks.WorkspaceClient) ([]Resource, error) {
+	all, err := w.Jobs.ListAll(ctx, jobs.ListJobsRequest{})
+	if err != nil {
+		return nil, err
+	}
+	resources := make([]Resource, 0, len(all))
+	for _, j := range all {
+		r := Resource{ID: strconv.FormatInt(j.JobId, 10)}
+		if j.Settings != nil {
+			r.Name = j.Settings.Name
+		}
+		resources = append(resources, r)
+	}
+	return resources, nil
+}
@@ -0,0 +1,75 @@
+package completion
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go/experimental/mocks"
+	"github.com/databricks/databricks-sdk-go/service/compute"
+	"github.com/databricks/databricks-sdk-go/service/jobs"
+	"github.com/databricks/databricks-sdk-go/service/sql"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListClusters(t *testing.T) {
+	m := mocks.NewMockWorkspaceClient(t)
+	m.GetMockClustersAPI().EXPECT().
+		ListAll(mock.Anything, compute.ListClustersRequest{}).
+		Return([]compute.ClusterDetails{
+			{ClusterId: "0123-abc", ClusterName: "Shared"},
+			{ClusterId: "0456-def", ClusterName: "Personal"},
+		}, nil)
+
+	kind, ok := LookupResourceKind("clusters")
+	require.True(t, ok)
+	got, err := kind.List(t.Context(), m.WorkspaceClient)
+	require.NoError(t, err)
+	assert.Equal(t, []Resource{{ID: "0123-abc", Name: "Shared"}, {ID: "0456-def", Name: "Personal"}}, got)
+}
+
+func TestListWarehouses(t *testing.T) {
+	m := mocks.NewMockWorkspaceClient(t)
+	m.GetMockWarehousesAPI().EXPECT().
+		ListAll(mock.Anything, sql.ListWarehousesRequest{}).
+		Return([]sql.EndpointInfo{{Id: "abc123", Name: "Starter Warehouse"}}, nil)
+
+	kind, ok := LookupResourceKind("warehouses")
+	require.True(t, ok)
+	got, err := kind.List(t.Context(), m.WorkspaceClient)
+	require.NoError(t, err)
+	assert.Equal(t, []Resource{{ID: "abc123", Name: "Starter Warehouse"}}, got)
+}
+
+func TestListJobs(t *testing.T) {
+	m := mocks.NewMockWorkspaceClient(t)
+	m.GetMockJobsAPI().EXPECT().
+		ListAll(mock.Anything, jobs.ListJobsRequest{}).
+		Return([]jobs.BaseJob{
+			{JobId: 42, Settings: &jobs.JobSettings{Name: "Nightly"}},
+			{JobId: 7},
+		}, nil)
+
+	kind, ok := LookupResourceKind("jobs")
+	require.True(t, ok)
+	got, err := kind.List(t.Context(), m.WorkspaceClient)
+	require.NoError(t, err)
+	assert.Equal(t, []Resource{{ID: "42", Name: "Nightly"}, {ID: "7"}}, got)
+}
+
+func TestListResourcesError(t *testing.T) {
+	m := mocks.NewMockWorkspaceClient(t)
+	m.GetMockClustersAPI().EXPECT().
+		ListAll(mock.Anything, mock.Anything).
+		Return(nil, errors.New("unauthenticated"))
+
+	kind, _ := LookupResourceKind("clusters")
+	_, err := kind.List(t.Context(), m.WorkspaceClient)
+	assert.EqualError(t, err, "unauthenticated")
+}
+
+func TestLookupResourceKindUnknown(t *testing.T) {
+	_, ok := LookupResourceKind("pipelines")
+	assert.False(t, ok)
+}
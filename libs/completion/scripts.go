@@ -0,0 +1,71 @@
+package completion
+
+import "fmt"
+
+// Cobra doesn't generate completion scripts for nushell and elvish. The
+// scripts below ask the CLI for candidates at runtime through Cobra's hidden
+// __complete command, the same way the generated scripts for other shells do.
+// Each line of its output is a candidate optionally followed by a tab and a
+// description, and the last line is a ":<directive>" that we ignore.
+
+const nushellScript = `# Nushell completions for the Databricks CLI.
+let databricks_completer = {|spans: list<string>|
+    ^databricks %s ...($spans | skip 1)
+    | complete
+    | get stdout
+    | lines
+    | where {|line| not ($line | str starts-with ":") }
+    | each {|line|
+        let parts = ($line | split row "\t")
+        {value: ($parts | first), description: ($parts | get 1? | default "")}
+    }
+}
+
+let previous_completer = $env.config.completions.external.completer?
+$env.config.completions.external.enable = true
+$env.config.completions.external.completer = {|spans: list<string>|
+    if ($spans | first) == "databricks" {
+        do $databricks_completer $spans
+    } else if $previous_completer != null {
+        do $previous_completer $spans
+    }
+}
+`
+
+const elvishScript = `# Elvish completions for the Databricks CLI.
+use os
+use str
+
+set edit:completion:arg-completer[databricks] = {|@words|
+    var args = $words[1..]
+    databricks %s $@args 2>$os:dev-null | from-lines | each {|line|
+        if (str:has-prefix $line :) {
+            continue
+        }
+        var parts = [(str:split "\t" $line)]
+        if (> (count $parts) 1) {
+            edit:complex-candidate $parts[0] &display=$parts[0]' ('$parts[1]')'
+        } else {
+            edit:complex-candidate $parts[0]
+        }
+    }
+}
+`
+
+// NushellScript returns the completion script for nushell.
+func NushellScript(includeDesc bool) string {
+	return fmt.Sprintf(nushellScript, completeCommand(includeDesc))
+}
+
+// ElvishScript returns the completion script for elvish.
+func ElvishScript(includeDesc bool) string {
+	return fmt.Sprintf(elvishScript, completeCommand(includeDesc))
+}
+
+// completeCommand returns the hidden Cobra command that prints candidates.
+func completeCommand(includeDesc bool) string {
+	if includeDesc {
+		return "__complete"
+	}
+	return "__completeNoDesc"
+}
@@ -0,0 +1,14 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScriptsDescriptions(t *testing.T) {
+	assert.Contains(t, NushellScript(true), "^databricks __complete ...")
+	assert.Contains(t, NushellScript(false), "^databricks __completeNoDesc ...")
+	assert.Contains(t, ElvishScript(true), "databricks __complete $@args")
+	assert.Contains(t, ElvishScript(false), "databricks __completeNoDesc $@args")
+}
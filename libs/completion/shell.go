@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
 )
 
 // Shell represents a supported shell type.
@@ -22,8 +23,19 @@ const (
 	Fish        Shell = "fish"
 	PowerShell  Shell = "powershell"
 	PowerShell5 Shell = "powershell5"
+	Nushell     Shell = "nushell"
+	Elvish      Shell = "elvish"
 )
 
+// noDescriptionsFlag disables descriptions in generated completion scripts.
+const noDescriptionsFlag = "--no-descriptions"
+
+// AllShells lists the supported shells.
+var AllShells = []Shell{Bash, Zsh, Fish, PowerShell, PowerShell5, Nushell, Elvish}
+
+// supportedShells lists the valid values of the --shell flag.
+const supportedShells = "bash, zsh, fish, powershell, powershell5, nushell, elvish"
+
 const (
 	// BeginMarker is the start of the completion block in RC files.
 	BeginMarker = "# BEGIN databricks-cli completion"
@@ -38,13 +50,29 @@ func (s Shell) DisplayName() string {
 		return "powershell (pwsh 7+)"
 	case PowerShell5:
 		return "powershell5 (Windows PowerShell 5.1)"
+	case Nushell:
+		return "nushell (nu)"
 	default:
 		return string(s)
 	}
 }
 
+// usesFileDrop reports whether completions for the shell are installed as a
+// file of their own rather than as a block in an RC file.
+func usesFileDrop(ctx context.Context, shell Shell, homeDir string) bool {
+	switch shell {
+	case Fish, Nushell, Elvish:
+		return true
+	case Zsh:
+		return OhMyZshDir(ctx, homeDir) != ""
+	default:
+		return false
+	}
+}
+
 // DetectShell returns the shell to use. If flagValue is non-empty, it validates
-// and returns it. Otherwise it auto-detects from the environment.
+// and returns it. Otherwise it auto-detects from the environment, falling back
+// to the parent process on Unix if $SHELL is not set.
 func DetectShell(ctx context.Context, flagValue string) (Shell, error) {
 	if flagValue != "" {
 		return validateShellFlag(flagValue)
@@ -52,6 +80,7 @@ func DetectShell(ctx context.Context, flagValue string) (Shell, error) {
 
 	shellEnv := env.Get(ctx, "SHELL")
 	if shellEnv != "" {
+		log.Debugf(ctx, "Detected shell from $SHELL: %s", shellEnv)
 		return shellFromPath(shellEnv)
 	}
 
@@ -59,7 +88,57 @@ func DetectShell(ctx context.Context, flagValue string) (Shell, error) {
 		return detectWindowsShell()
 	}
 
-	return "", errors.New("could not detect shell: $SHELL is not set. Use --shell to specify your shell")
+	shell, err := shellFromParentProcess(ctx)
+	if err != nil {
+		log.Debugf(ctx, "Failed to detect shell from the parent process: %s", err)
+		return "", errors.New("could not detect shell: $SHELL is not set and the parent process is not a supported shell. Use --shell to specify your shell")
+	}
+	log.Debugf(ctx, "Detected shell from the parent process: %s", shell)
+	return shell, nil
+}
+
+// DetectShells returns every shell that plausibly needs completions: the
+// shell from $SHELL, shells whose configuration exists in homeDir, and pwsh
+// if it is on PATH. Unsupported or unrecognized shells are ignored.
+func DetectShells(ctx context.Context, homeDir string) []Shell {
+	found := map[Shell]bool{}
+
+	if shellEnv := env.Get(ctx, "SHELL"); shellEnv != "" {
+		if shell, err := shellFromPath(shellEnv); err == nil {
+			found[shell] = true
+		}
+	}
+	if exists(filepath.Join(homeDir, ".bashrc")) || exists(filepath.Join(homeDir, ".bash_profile")) {
+		found[Bash] = true
+	}
+	if exists(zshrcPath(ctx, homeDir)) {
+		found[Zsh] = true
+	}
+	if exists(filepath.Join(configHome(ctx, homeDir), "fish")) {
+		found[Fish] = true
+	}
+	if exists(filepath.Join(configHome(ctx, homeDir), "nushell")) {
+		found[Nushell] = true
+	}
+	if exists(filepath.Join(homeDir, ".elvish")) {
+		found[Elvish] = true
+	}
+	if _, err := exec.LookPath("pwsh"); err == nil {
+		found[PowerShell] = true
+	}
+
+	var shells []Shell
+	for _, shell := range AllShells {
+		if found[shell] {
+			shells = append(shells, shell)
+		}
+	}
+	return shells
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
 }
 
 // validateShellFlag validates a user-provided --shell flag value.
@@ -67,9 +146,9 @@ func validateShellFlag(value string) (Shell, error) {
 	shell := Shell(strings.ToLower(value))
 
 	switch shell {
-	case Bash, Zsh, Fish, PowerShell, PowerShell5:
+	case Bash, Zsh, Fish, PowerShell, PowerShell5, Nushell, Elvish:
 	default:
-		return "", fmt.Errorf("unsupported shell %q: supported shells are bash, zsh, fish, powershell, powershell5", value)
+		return "", fmt.Errorf("unsupported shell %q: supported shells are %s", value, supportedShells)
 	}
 
 	if shell == PowerShell5 && runtime.GOOS != "windows" {
@@ -96,6 +175,10 @@ func shellFromPath(path string) (Shell, error) {
 		return Zsh, nil
 	case strings.Contains(name, "fish"):
 		return Fish, nil
+	case name == "nu", name == "nu.exe":
+		return Nushell, nil
+	case strings.Contains(name, "elvish"):
+		return Elvish, nil
 	case name == "pwsh", name == "pwsh.exe":
 		return PowerShell, nil
 	case name == "powershell", name == "powershell.exe":
@@ -104,7 +187,7 @@ func shellFromPath(path string) (Shell, error) {
 		}
 	}
 
-	return "", fmt.Errorf("unsupported shell %q: supported shells are bash, zsh, fish, powershell, powershell5", name)
+	return "", fmt.Errorf("unsupported shell %q: supported shells are %s", name, supportedShells)
 }
 
 // detectWindowsShell attempts to find PowerShell on Windows.
@@ -119,18 +202,33 @@ func detectWindowsShell() (Shell, error) {
 }
 
 // TargetFilePath returns the file that will be modified for the given shell.
-func TargetFilePath(shell Shell, homeDir string) string {
+// It honors $ZDOTDIR for zsh and $XDG_CONFIG_HOME for fish, nushell and pwsh
+// on non-Windows systems, the same way the shells themselves do. For zsh with
+// oh-my-zsh, it is the completion function of a custom plugin.
+func TargetFilePath(ctx context.Context, shell Shell, homeDir string) string {
 	switch shell {
 	case Bash:
 		return bashProfilePath(homeDir)
 	case Zsh:
-		return filepath.Join(homeDir, ".zshrc")
+		if dir := OhMyZshDir(ctx, homeDir); dir != "" {
+			return ohMyZshPluginPath(ctx, dir)
+		}
+		return zshrcPath(ctx, homeDir)
 	case Fish:
-		return filepath.Join(homeDir, ".config", "fish", "completions", "databricks.fish")
+		return filepath.Join(configHome(ctx, homeDir), "fish", "completions", "databricks.fish")
 	case PowerShell:
-		return powershellProfilePath(homeDir)
+		return powershellProfilePath(ctx, homeDir)
 	case PowerShell5:
+		if runtime.GOOS == "windows" {
+			if p := queryPowerShellProfile(ctx, PowerShell5); p != "" {
+				return p
+			}
+		}
 		return filepath.Join(homeDir, "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1")
+	case Nushell:
+		return filepath.Join(configHome(ctx, homeDir), "nushell", "autoload", "databricks.nu")
+	case Elvish:
+		return filepath.Join(homeDir, ".elvish", "lib", "databricks.elv")
 	default:
 		return ""
 	}
@@ -162,48 +260,89 @@ func bashProfilePath(homeDir string) string {
 	return primaryPath
 }
 
-// powershellProfilePath returns the pwsh 7+ profile path.
+// powershellProfilePath returns the pwsh 7+ profile path. On Windows, it is
+// the path reported by pwsh if it is available.
 // See: https://learn.microsoft.com/en-us/powershell/module/microsoft.powershell.core/about/about_profiles
-func powershellProfilePath(homeDir string) string {
+func powershellProfilePath(ctx context.Context, homeDir string) string {
 	if runtime.GOOS == "windows" {
+		if p := queryPowerShellProfile(ctx, PowerShell); p != "" {
+			return p
+		}
 		return filepath.Join(homeDir, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1")
 	}
-	return filepath.Join(homeDir, ".config", "powershell", "Microsoft.PowerShell_profile.ps1")
+	return filepath.Join(configHome(ctx, homeDir), "powershell", "Microsoft.PowerShell_profile.ps1")
 }
 
-// ShimContent returns the completion shim block for the given shell, including markers.
-func ShimContent(shell Shell) string {
-	var evalLine string
-	switch shell {
-	case Bash:
-		evalLine = `eval "$(databricks completion bash)"`
-	case Zsh:
-		evalLine = `eval "$(databricks completion zsh)"`
-	case Fish:
-		evalLine = "databricks completion fish | source"
-	case PowerShell, PowerShell5:
-		evalLine = "databricks completion powershell | Out-String | Invoke-Expression"
+// zshrcPath returns the path of .zshrc.
+func zshrcPath(ctx context.Context, homeDir string) string {
+	return filepath.Join(zshConfigDir(ctx, homeDir), ".zshrc")
+}
+
+// zshConfigDir returns the directory zsh reads .zshrc from: $ZDOTDIR if it
+// is set, and the home directory otherwise.
+func zshConfigDir(ctx context.Context, homeDir string) string {
+	if dir := env.Get(ctx, "ZDOTDIR"); dir != "" {
+		return dir
 	}
+	return homeDir
+}
 
-	return BeginMarker + "\n" + evalLine + "\n" + EndMarker + "\n"
+// configHome returns $XDG_CONFIG_HOME, or ~/.config if it is not set. Relative
+// paths are ignored as required by the XDG Base Directory Specification.
+func configHome(ctx context.Context, homeDir string) string {
+	if dir := env.Get(ctx, "XDG_CONFIG_HOME"); dir != "" && filepath.IsAbs(dir) {
+		return dir
+	}
+	return filepath.Join(homeDir, ".config")
 }
 
-// homebrewCompletionPath returns the path to Homebrew-installed zsh completions
-// for databricks, or empty string if not found.
-func homebrewCompletionPath(ctx context.Context) string {
-	prefix := env.Get(ctx, "HOMEBREW_PREFIX")
-	if prefix == "" {
-		// Check common defaults.
-		// See: https://docs.brew.sh/Installation
-		for _, p := range []string{"/opt/homebrew", "/usr/local"} {
-			if _, err := os.Stat(filepath.Join(p, "bin/brew")); err == nil {
-				prefix = p
-				break
-			}
-		}
+// ShimContent returns the completion shim block for the given shell, including markers.
+// The shim sources the cached completion script and refreshes the cache in the
+// background if the CLI binary has changed since the script was generated, see
+// [WriteCache]. Nushell and elvish can't evaluate generated code
+// at startup, so their block contains the completion script itself; it queries
+// the CLI at runtime. The choice to include descriptions is recorded in the
+// block, so that the cache is generated accordingly.
+func ShimContent(shell Shell, includeDesc bool) string {
+	flags := "--shell " + string(shell)
+	if !includeDesc {
+		flags += " " + noDescriptionsFlag
 	}
-	if prefix == "" {
-		return ""
+
+	var body string
+	switch shell {
+	case Bash, Zsh:
+		body = fmt.Sprintf(`if command -v databricks >/dev/null 2>&1; then
+  if [ -f "$HOME/.databricks/completions/%[1]s" ]; then source "$HOME/.databricks/completions/%[1]s"; fi
+  __databricks_bin="$(command -v databricks)"
+  __databricks_stamp="$HOME/.databricks/completions/%[1]s.version"
+  if [ ! -f "$__databricks_stamp" ] || [ "$__databricks_bin" -nt "$__databricks_stamp" ] || [ "$__databricks_bin" -ot "$__databricks_stamp" ]; then
+    (databricks completion cache %[2]s >/dev/null 2>&1 &)
+  fi
+  unset __databricks_bin __databricks_stamp
+fi`, shell, flags)
+	case Fish:
+		body = fmt.Sprintf(`if command -q databricks
+  test -f "$HOME/.databricks/completions/fish"; and source "$HOME/.databricks/completions/fish"
+  set -l stamp "$HOME/.databricks/completions/fish.version"
+  # The path builtin was added in fish 3.5. Older versions refresh the cache on every start.
+  if not test -f "$stamp"; or not builtin -q path; or test (path mtime -- (command -s databricks)) -ne (path mtime -- "$stamp")
+    command databricks completion cache %[1]s >/dev/null 2>&1 &; disown
+  end
+end`, flags)
+	case PowerShell, PowerShell5:
+		body = fmt.Sprintf(`$databricksCompletions = Join-Path $HOME '.databricks/completions/%[1]s'
+if (Test-Path $databricksCompletions) { . $databricksCompletions }
+$databricksCommand = Get-Command databricks -CommandType Application -ErrorAction SilentlyContinue | Select-Object -First 1
+$databricksStamp = "$databricksCompletions.version"
+if ($databricksCommand -and (-not (Test-Path $databricksStamp) -or (Get-Item $databricksCommand.Source).LastWriteTimeUtc -ne (Get-Item $databricksStamp).LastWriteTimeUtc)) {
+  $null = Start-Job { databricks completion cache %[2]s }
+}`, shell, flags)
+	case Nushell:
+		body = strings.TrimSuffix(NushellScript(includeDesc), "\n")
+	case Elvish:
+		body = strings.TrimSuffix(ElvishScript(includeDesc), "\n")
 	}
-	return filepath.Join(prefix, "share/zsh/site-functions/_databricks")
+
+	return BeginMarker + "\n" + body + "\n" + EndMarker + "\n"
 }
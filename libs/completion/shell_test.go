@@ -1,10 +1,15 @@
 package completion
 
 import (
+	"context"
+	"errors"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -21,6 +26,8 @@ func TestDetectShellFromEnv(t *testing.T) {
 		{"zsh from /bin/zsh", "/bin/zsh", Zsh},
 		{"zsh from /usr/bin/zsh", "/usr/bin/zsh", Zsh},
 		{"fish from /usr/bin/fish", "/usr/bin/fish", Fish},
+		{"nushell from /usr/bin/nu", "/usr/bin/nu", Nushell},
+		{"elvish from /usr/local/bin/elvish", "/usr/local/bin/elvish", Elvish},
 		{"pwsh from path", "/usr/local/bin/pwsh", PowerShell},
 		{"pwsh.exe from path", "/usr/local/bin/pwsh.exe", PowerShell},
 	}
@@ -67,10 +74,66 @@ func TestDetectShellEmptyOnUnix(t *testing.T) {
 		t.Skip("unix-only test")
 	}
 	t.Setenv("SHELL", "")
+	fakeParentProcessName(t, "make", nil)
 	_, err := DetectShell(t.Context(), "")
 	assert.ErrorContains(t, err, "$SHELL is not set")
 }
 
+// fakeParentProcessName makes the parent process lookup return name and err
+// for the duration of the test.
+func fakeParentProcessName(t *testing.T, name string, err error) {
+	orig := parentProcessName
+	t.Cleanup(func() { parentProcessName = orig })
+	parentProcessName = func(context.Context) (string, error) {
+		return name, err
+	}
+}
+
+func TestDetectShellFromParentProcess(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix-only test")
+	}
+	t.Setenv("SHELL", "")
+
+	tests := []struct {
+		name     string
+		comm     string
+		expected Shell
+	}{
+		{"bash", "bash", Bash},
+		{"login zsh", "-zsh", Zsh},
+		{"full path", "/usr/local/bin/fish", Fish},
+		{"pwsh", "pwsh", PowerShell},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeParentProcessName(t, tt.comm, nil)
+			got, err := DetectShell(t.Context(), "")
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, got)
+		})
+	}
+}
+
+func TestDetectShellParentProcessLookupFails(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix-only test")
+	}
+	t.Setenv("SHELL", "")
+	fakeParentProcessName(t, "", errors.New("no such process"))
+	_, err := DetectShell(t.Context(), "")
+	assert.ErrorContains(t, err, "Use --shell to specify your shell")
+}
+
+func TestDetectShellPrefersEnvOverParentProcess(t *testing.T) {
+	t.Setenv("SHELL", "/bin/zsh")
+	fakeParentProcessName(t, "bash", nil)
+	got, err := DetectShell(t.Context(), "")
+	require.NoError(t, err)
+	assert.Equal(t, Zsh, got)
+}
+
 func TestDetectShellFlagOverride(t *testing.T) {
 	t.Setenv("SHELL", "/bin/zsh")
 
@@ -99,17 +162,17 @@ func TestTargetFilePathBashDarwin(t *testing.T) {
 
 	home := t.TempDir()
 	// Neither file exists — should return primary (bash_profile on darwin).
-	got := TargetFilePath(Bash, home)
+	got := TargetFilePath(t.Context(), Bash, home)
 	assert.Equal(t, filepath.Join(home, ".bash_profile"), got)
 
 	// Create .bashrc — should fall back to it since .bash_profile doesn't exist.
 	require.NoError(t, os.WriteFile(filepath.Join(home, ".bashrc"), nil, 0o644))
-	got = TargetFilePath(Bash, home)
+	got = TargetFilePath(t.Context(), Bash, home)
 	assert.Equal(t, filepath.Join(home, ".bashrc"), got)
 
 	// Create .bash_profile — should prefer it.
 	require.NoError(t, os.WriteFile(filepath.Join(home, ".bash_profile"), nil, 0o644))
-	got = TargetFilePath(Bash, home)
+	got = TargetFilePath(t.Context(), Bash, home)
 	assert.Equal(t, filepath.Join(home, ".bash_profile"), got)
 }
 
@@ -119,34 +182,76 @@ func TestTargetFilePathBashLinux(t *testing.T) {
 	}
 
 	home := t.TempDir()
-	got := TargetFilePath(Bash, home)
+	got := TargetFilePath(t.Context(), Bash, home)
 	assert.Equal(t, filepath.Join(home, ".bashrc"), got)
 }
 
 func TestTargetFilePathZsh(t *testing.T) {
 	home := t.TempDir()
-	got := TargetFilePath(Zsh, home)
+	got := TargetFilePath(t.Context(), Zsh, home)
 	assert.Equal(t, filepath.Join(home, ".zshrc"), got)
 }
 
 func TestTargetFilePathFish(t *testing.T) {
 	home := t.TempDir()
-	got := TargetFilePath(Fish, home)
+	got := TargetFilePath(t.Context(), Fish, home)
 	assert.Equal(t, filepath.Join(home, ".config", "fish", "completions", "databricks.fish"), got)
 }
 
+func TestTargetFilePathNushell(t *testing.T) {
+	home := t.TempDir()
+	got := TargetFilePath(t.Context(), Nushell, home)
+	assert.Equal(t, filepath.Join(home, ".config", "nushell", "autoload", "databricks.nu"), got)
+
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	got = TargetFilePath(t.Context(), Nushell, home)
+	assert.Equal(t, filepath.Join(configHome, "nushell", "autoload", "databricks.nu"), got)
+}
+
+func TestTargetFilePathElvish(t *testing.T) {
+	home := t.TempDir()
+	got := TargetFilePath(t.Context(), Elvish, home)
+	assert.Equal(t, filepath.Join(home, ".elvish", "lib", "databricks.elv"), got)
+}
+
 func TestTargetFilePathPowerShellUnix(t *testing.T) {
 	if runtime.GOOS == "windows" {
 		t.Skip("unix-only test")
 	}
 	home := t.TempDir()
-	got := TargetFilePath(PowerShell, home)
+	got := TargetFilePath(t.Context(), PowerShell, home)
 	assert.Equal(t, filepath.Join(home, ".config", "powershell", "Microsoft.PowerShell_profile.ps1"), got)
 }
 
+func TestTargetFilePathZdotdir(t *testing.T) {
+	home := t.TempDir()
+	zdotdir := t.TempDir()
+	t.Setenv("ZDOTDIR", zdotdir)
+	assert.Equal(t, filepath.Join(zdotdir, ".zshrc"), TargetFilePath(t.Context(), Zsh, home))
+
+	t.Setenv("ZDOTDIR", "")
+	assert.Equal(t, filepath.Join(home, ".zshrc"), TargetFilePath(t.Context(), Zsh, home))
+}
+
+func TestTargetFilePathXDGConfigHome(t *testing.T) {
+	home := t.TempDir()
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	assert.Equal(t, filepath.Join(configHome, "fish", "completions", "databricks.fish"), TargetFilePath(t.Context(), Fish, home))
+	if runtime.GOOS != "windows" {
+		assert.Equal(t, filepath.Join(configHome, "powershell", "Microsoft.PowerShell_profile.ps1"), TargetFilePath(t.Context(), PowerShell, home))
+	}
+
+	// Relative paths are ignored.
+	t.Setenv("XDG_CONFIG_HOME", "relative")
+	assert.Equal(t, filepath.Join(home, ".config", "fish", "completions", "databricks.fish"), TargetFilePath(t.Context(), Fish, home))
+}
+
 func TestTargetFilePathPowerShell5(t *testing.T) {
+	fakePowerShell(t, "", errors.New("not found"))
 	home := t.TempDir()
-	got := TargetFilePath(PowerShell5, home)
+	got := TargetFilePath(t.Context(), PowerShell5, home)
 	assert.Equal(t, filepath.Join(home, "Documents", "WindowsPowerShell", "Microsoft.PowerShell_profile.ps1"), got)
 }
 
@@ -155,16 +260,18 @@ func TestShimContent(t *testing.T) {
 		shell    Shell
 		contains string
 	}{
-		{Bash, `eval "$(databricks completion bash)"`},
-		{Zsh, `eval "$(databricks completion zsh)"`},
-		{Fish, "databricks completion fish | source"},
-		{PowerShell, "databricks completion powershell | Out-String | Invoke-Expression"},
-		{PowerShell5, "databricks completion powershell | Out-String | Invoke-Expression"},
+		{Bash, "databricks completion cache --shell bash"},
+		{Zsh, "databricks completion cache --shell zsh"},
+		{Fish, "databricks completion cache --shell fish"},
+		{Nushell, "^databricks __complete"},
+		{Elvish, "set edit:completion:arg-completer[databricks]"},
+		{PowerShell, "databricks completion cache --shell powershell"},
+		{PowerShell5, "databricks completion cache --shell powershell5"},
 	}
 
 	for _, tt := range tests {
 		t.Run(string(tt.shell), func(t *testing.T) {
-			content := ShimContent(tt.shell)
+			content := ShimContent(tt.shell, true)
 			assert.Contains(t, content, BeginMarker)
 			assert.Contains(t, content, EndMarker)
 			assert.Contains(t, content, tt.contains)
@@ -172,6 +279,82 @@ func TestShimContent(t *testing.T) {
 	}
 }
 
+func TestShimContentGuardsMissingBinary(t *testing.T) {
+	assert.Contains(t, ShimContent(Bash, true), "if command -v databricks >/dev/null 2>&1; then\n")
+	assert.Contains(t, ShimContent(Zsh, true), "if command -v databricks >/dev/null 2>&1; then\n")
+	assert.Contains(t, ShimContent(Fish, true), "if command -q databricks\n")
+}
+
+func TestShimContentGuardsFishPathBuiltin(t *testing.T) {
+	// Fish before 3.5 has no path builtin, and must not call it.
+	assert.Contains(t, ShimContent(Fish, true), `or not builtin -q path; or test (path mtime`)
+}
+
+func TestShimSilentWithoutBinary(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix-only test")
+	}
+	for _, shell := range []Shell{Bash, Zsh, Fish} {
+		t.Run(string(shell), func(t *testing.T) {
+			path, err := exec.LookPath(string(shell))
+			if err != nil {
+				t.Skipf("%s is not installed", shell)
+			}
+
+			// The shell can't find the CLI on an empty PATH.
+			cmd := exec.Command(path, "-c", ShimContent(shell, true))
+			cmd.Env = []string{"HOME=" + t.TempDir(), "PATH=" + t.TempDir()}
+			out, err := cmd.CombinedOutput()
+			require.NoError(t, err)
+			assert.Empty(t, string(out))
+		})
+	}
+}
+
+func TestShimRefreshesStaleCache(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("unix-only test")
+	}
+	for _, shell := range []Shell{Bash, Zsh, Fish} {
+		t.Run(string(shell), func(t *testing.T) {
+			path, err := exec.LookPath(string(shell))
+			if err != nil {
+				t.Skipf("%s is not installed", shell)
+			}
+
+			// The fake CLI records that the shim ran it.
+			home := t.TempDir()
+			bin := t.TempDir()
+			called := filepath.Join(home, "called")
+			executable := filepath.Join(bin, "databricks")
+			require.NoError(t, os.WriteFile(executable, []byte("#!/bin/sh\ntouch \"$HOME/called\"\n"), 0o755))
+
+			runShim := func() bool {
+				_ = os.Remove(called)
+				cmd := exec.Command(path, "-c", ShimContent(shell, true))
+				cmd.Env = []string{"HOME=" + home, "PATH=" + bin + ":/usr/bin:/bin"}
+				out, err := cmd.CombinedOutput()
+				require.NoError(t, err, string(out))
+				// The refresh runs in the background.
+				time.Sleep(200 * time.Millisecond)
+				_, err = os.Stat(called)
+				return err == nil
+			}
+
+			// There is no cache yet.
+			assert.True(t, runShim())
+
+			require.NoError(t, WriteCache(shell, home, "1.0.0", true, executable, func(w io.Writer) error { return nil }))
+			assert.False(t, runShim())
+
+			// The binary was replaced.
+			later := time.Now().Add(time.Hour)
+			require.NoError(t, os.Chtimes(executable, later, later))
+			assert.True(t, runShim())
+		})
+	}
+}
+
 func TestDisplayName(t *testing.T) {
 	tests := []struct {
 		shell    Shell
@@ -180,6 +363,8 @@ func TestDisplayName(t *testing.T) {
 		{Bash, "bash"},
 		{Zsh, "zsh"},
 		{Fish, "fish"},
+		{Nushell, "nushell (nu)"},
+		{Elvish, "elvish"},
 		{PowerShell, "powershell (pwsh 7+)"},
 		{PowerShell5, "powershell5 (Windows PowerShell 5.1)"},
 	}
@@ -190,3 +375,84 @@ func TestDisplayName(t *testing.T) {
 		})
 	}
 }
+
+func TestDetectShells(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("non-windows test")
+	}
+	t.Setenv("PATH", t.TempDir())
+
+	home := t.TempDir()
+	t.Setenv("SHELL", "")
+	assert.Empty(t, DetectShells(t.Context(), home))
+
+	t.Setenv("SHELL", "/bin/zsh")
+	assert.Equal(t, []Shell{Zsh}, DetectShells(t.Context(), home))
+
+	require.NoError(t, os.WriteFile(filepath.Join(home, ".bashrc"), nil, 0o644))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config", "fish"), 0o755))
+	assert.Equal(t, []Shell{Bash, Zsh, Fish}, DetectShells(t.Context(), home))
+
+	bin := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "pwsh"), []byte("#!/bin/sh\n"), 0o755))
+	t.Setenv("PATH", bin)
+	t.Setenv("SHELL", "/bin/csh")
+	assert.Equal(t, []Shell{Bash, Fish, PowerShell}, DetectShells(t.Context(), home))
+
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".config", "nushell"), 0o755))
+	require.NoError(t, os.MkdirAll(filepath.Join(home, ".elvish"), 0o755))
+	assert.Equal(t, []Shell{Bash, Fish, PowerShell, Nushell, Elvish}, DetectShells(t.Context(), home))
+}
+
+func TestDetectShellFlagNushellElvish(t *testing.T) {
+	got, err := DetectShell(t.Context(), "nushell")
+	require.NoError(t, err)
+	assert.Equal(t, Nushell, got)
+
+	got, err = DetectShell(t.Context(), "Elvish")
+	require.NoError(t, err)
+	assert.Equal(t, Elvish, got)
+}
+
+func fakePowerShell(t *testing.T, out string, err error) *[]string {
+	orig := runPowerShell
+	t.Cleanup(func() { runPowerShell = orig })
+	var calls []string
+	runPowerShell = func(_ context.Context, name string, args ...string) (string, error) {
+		calls = append(calls, name)
+		return out, err
+	}
+	return &calls
+}
+
+func TestQueryPowerShellProfile(t *testing.T) {
+	profile := filepath.Join(t.TempDir(), "OneDrive", "Documents", "PowerShell", "profile.ps1")
+	calls := fakePowerShell(t, profile+"\r\n", nil)
+
+	assert.Equal(t, profile, queryPowerShellProfile(t.Context(), PowerShell))
+	assert.Equal(t, profile, queryPowerShellProfile(t.Context(), PowerShell5))
+	assert.Equal(t, []string{"pwsh", "powershell.exe"}, *calls)
+}
+
+func TestQueryPowerShellProfileFallback(t *testing.T) {
+	fakePowerShell(t, "", errors.New("executable file not found"))
+	assert.Empty(t, queryPowerShellProfile(t.Context(), PowerShell))
+
+	fakePowerShell(t, "relative\\profile.ps1\n", nil)
+	assert.Empty(t, queryPowerShellProfile(t.Context(), PowerShell))
+}
+
+func TestTargetFilePathPowerShellWindowsUsesProfile(t *testing.T) {
+	if runtime.GOOS != "windows" {
+		t.Skip("windows-only test")
+	}
+	home := t.TempDir()
+	profile := filepath.Join(home, "OneDrive", "Documents", "PowerShell", "profile.ps1")
+	fakePowerShell(t, profile+"\r\n", nil)
+
+	assert.Equal(t, profile, TargetFilePath(t.Context(), PowerShell, home))
+	assert.Equal(t, profile, TargetFilePath(t.Context(), PowerShell5, home))
+
+	fakePowerShell(t, "", errors.New("not found"))
+	assert.Equal(t, filepath.Join(home, "Documents", "PowerShell", "Microsoft.PowerShell_profile.ps1"), TargetFilePath(t.Context(), PowerShell, home))
+}
@@ -9,26 +9,41 @@ import (
 // StatusResult describes the current completion installation state.
 type StatusResult struct {
 	Installed bool   // true if completions are available by any method
-	Method    string // "marker" | "homebrew" | "file" | ""
-	FilePath  string // the file that is/would be modified
+	Method    string // "marker" | "system" | "homebrew" | "file" | ""
+	FilePath  string // the file that is/would be modified, or the system file
+
+	// LinkTarget is the file that FilePath points to if it is a symlink. It
+	// is the file that is actually modified.
+	LinkTarget string
+
+	// Descriptions is false if our completions were installed with
+	// --no-descriptions. It is only meaningful for the "marker" method.
+	Descriptions bool
+
+	// For completions provided by Homebrew, the resolved path of the
+	// databricks binary that Homebrew manages and the version of its formula.
+	// The completions match the commands of that binary.
+	HomebrewBinary  string
+	HomebrewVersion string
 }
 
 // Status checks whether shell completion is currently available.
 func Status(ctx context.Context, shell Shell, homeDir string) (*StatusResult, error) {
-	filePath := TargetFilePath(shell, homeDir)
-	result := &StatusResult{FilePath: filePath}
+	filePath := TargetFilePath(ctx, shell, homeDir)
+	result := &StatusResult{FilePath: filePath, LinkTarget: LinkTarget(filePath)}
 
 	// Check for our marker block in the target file.
 	if content, err := os.ReadFile(filePath); err == nil {
 		if strings.Contains(string(content), BeginMarker) {
 			result.Installed = true
 			result.Method = "marker"
+			result.Descriptions = includesDescriptions(string(content))
 			return result, nil
 		}
 	}
 
-	// For fish: check if the file exists at all (could be installed by a package manager).
-	if shell == Fish {
+	// For file-drop shells: check if the file exists at all (could be installed by a package manager).
+	if usesFileDrop(ctx, shell, homeDir) {
 		if _, err := os.Stat(filePath); err == nil {
 			result.Installed = true
 			result.Method = "file"
@@ -36,12 +51,25 @@ func Status(ctx context.Context, shell Shell, homeDir string) (*StatusResult, er
 		}
 	}
 
+	// For bash and zsh: check completions installed system-wide with --system.
+	if dir := SystemDir(ctx, shell); dir != "" {
+		systemPath := SystemFilePath(shell, dir)
+		if ok, _ := IsSystemFile(systemPath); ok {
+			result.Installed = true
+			result.Method = "system"
+			result.FilePath = systemPath
+			result.LinkTarget = ""
+			return result, nil
+		}
+	}
+
 	// For zsh: check Homebrew completions.
 	if shell == Zsh {
-		if p := homebrewCompletionPath(ctx); p != "" {
-			if _, err := os.Stat(p); err == nil {
+		if prefix := homebrewPrefix(ctx); prefix != "" {
+			if _, err := os.Stat(homebrewCompletionPath(prefix)); err == nil {
 				result.Installed = true
 				result.Method = "homebrew"
+				result.HomebrewBinary, result.HomebrewVersion = homebrewBinary(prefix)
 				return result, nil
 			}
 		}
@@ -49,3 +77,14 @@ func Status(ctx context.Context, shell Shell, homeDir string) (*StatusResult, er
 
 	return result, nil
 }
+
+// includesDescriptions reports whether the first marker block in content
+// generates completions with descriptions.
+func includesDescriptions(content string) bool {
+	blocks, _ := findBlocks(content)
+	if len(blocks) == 0 {
+		return true
+	}
+	block := content[blocks[0].start:blocks[0].end]
+	return !strings.Contains(block, noDescriptionsFlag) && !strings.Contains(block, "__completeNoDesc")
+}
@@ -1,6 +1,7 @@
 package completion
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -24,7 +25,7 @@ func TestStatusNotInstalled(t *testing.T) {
 func TestStatusInstalledViaMarker(t *testing.T) {
 	home := t.TempDir()
 	rcPath := filepath.Join(home, ".zshrc")
-	require.NoError(t, os.WriteFile(rcPath, []byte(ShimContent(Zsh)), 0o644))
+	require.NoError(t, os.WriteFile(rcPath, []byte(ShimContent(Zsh, true)), 0o644))
 
 	result, err := Status(t.Context(), Zsh, home)
 	require.NoError(t, err)
@@ -49,7 +50,7 @@ func TestStatusFishWithMarker(t *testing.T) {
 	home := t.TempDir()
 	fishPath := filepath.Join(home, ".config", "fish", "completions", "databricks.fish")
 	require.NoError(t, os.MkdirAll(filepath.Dir(fishPath), 0o755))
-	require.NoError(t, os.WriteFile(fishPath, []byte(ShimContent(Fish)), 0o644))
+	require.NoError(t, os.WriteFile(fishPath, []byte(ShimContent(Fish, true)), 0o644))
 
 	result, err := Status(t.Context(), Fish, home)
 	require.NoError(t, err)
@@ -57,6 +58,30 @@ func TestStatusFishWithMarker(t *testing.T) {
 	assert.Equal(t, "marker", result.Method)
 }
 
+func TestStatusNushellFileExists(t *testing.T) {
+	home := t.TempDir()
+	nuPath := filepath.Join(home, ".config", "nushell", "autoload", "databricks.nu")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nuPath), 0o755))
+	require.NoError(t, os.WriteFile(nuPath, []byte("# package manager completions\n"), 0o644))
+
+	result, err := Status(t.Context(), Nushell, home)
+	require.NoError(t, err)
+	assert.True(t, result.Installed)
+	assert.Equal(t, "file", result.Method)
+}
+
+func TestStatusElvishWithMarker(t *testing.T) {
+	home := t.TempDir()
+	elvPath := filepath.Join(home, ".elvish", "lib", "databricks.elv")
+	require.NoError(t, os.MkdirAll(filepath.Dir(elvPath), 0o755))
+	require.NoError(t, os.WriteFile(elvPath, []byte(ShimContent(Elvish, true)), 0o644))
+
+	result, err := Status(t.Context(), Elvish, home)
+	require.NoError(t, err)
+	assert.True(t, result.Installed)
+	assert.Equal(t, "marker", result.Method)
+}
+
 func TestStatusHomebrewZsh(t *testing.T) {
 	home := t.TempDir()
 	brewPrefix := t.TempDir()
@@ -92,7 +117,7 @@ func TestStatusMarkerTakesPrecedenceOverHomebrew(t *testing.T) {
 
 	// Also install via marker.
 	rcPath := filepath.Join(home, ".zshrc")
-	require.NoError(t, os.WriteFile(rcPath, []byte(ShimContent(Zsh)), 0o644))
+	require.NoError(t, os.WriteFile(rcPath, []byte(ShimContent(Zsh, true)), 0o644))
 
 	result, err := Status(t.Context(), Zsh, home)
 	require.NoError(t, err)
@@ -102,8 +127,8 @@ func TestStatusMarkerTakesPrecedenceOverHomebrew(t *testing.T) {
 
 func TestStatusBash(t *testing.T) {
 	home := t.TempDir()
-	filePath := TargetFilePath(Bash, home)
-	require.NoError(t, os.WriteFile(filePath, []byte(ShimContent(Bash)), 0o644))
+	filePath := TargetFilePath(t.Context(), Bash, home)
+	require.NoError(t, os.WriteFile(filePath, []byte(ShimContent(Bash, true)), 0o644))
 
 	result, err := Status(t.Context(), Bash, home)
 	require.NoError(t, err)
@@ -112,10 +137,11 @@ func TestStatusBash(t *testing.T) {
 }
 
 func TestStatusPowerShell(t *testing.T) {
+	fakePowerShell(t, "", errors.New("not found"))
 	home := t.TempDir()
-	filePath := TargetFilePath(PowerShell, home)
+	filePath := TargetFilePath(t.Context(), PowerShell, home)
 	require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
-	require.NoError(t, os.WriteFile(filePath, []byte(ShimContent(PowerShell)), 0o644))
+	require.NoError(t, os.WriteFile(filePath, []byte(ShimContent(PowerShell, true)), 0o644))
 
 	result, err := Status(t.Context(), PowerShell, home)
 	require.NoError(t, err)
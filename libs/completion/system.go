@@ -0,0 +1,141 @@
+package completion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// SystemMarker identifies completion scripts that were installed system-wide
+// by "databricks completion install --system". Only files that contain it are
+// overwritten or removed.
+const SystemMarker = "# Installed by databricks-cli: databricks completion install --system"
+
+// systemDirs are the system completion directories for shells if Homebrew is
+// not installed.
+var systemDirs = map[Shell]string{
+	Bash: "/etc/bash_completion.d",
+	Zsh:  "/usr/local/share/zsh/site-functions",
+}
+
+// SupportsSystemInstall reports whether completions for the shell can be
+// installed system-wide.
+func SupportsSystemInstall(shell Shell) bool {
+	_, ok := systemDirs[shell]
+	return ok
+}
+
+// SystemDir returns the system completion directory for the shell: the one of
+// Homebrew if it is installed, and the one used by the OS package manager
+// otherwise.
+func SystemDir(ctx context.Context, shell Shell) string {
+	if !SupportsSystemInstall(shell) {
+		return ""
+	}
+	if prefix := homebrewPrefix(ctx); prefix != "" {
+		switch shell {
+		case Bash:
+			return filepath.Join(prefix, "etc", "bash_completion.d")
+		case Zsh:
+			return filepath.Join(prefix, "share", "zsh", "site-functions")
+		}
+	}
+	return systemDirs[shell]
+}
+
+// SystemFilePath returns the completion script for the shell in the system
+// completion directory dir.
+func SystemFilePath(shell Shell, dir string) string {
+	if shell == Zsh {
+		return filepath.Join(dir, "_databricks")
+	}
+	return filepath.Join(dir, "databricks")
+}
+
+// IsSystemFile reports whether the file at path was installed by InstallSystem.
+func IsSystemFile(path string) (bool, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	return strings.Contains(string(content), SystemMarker), nil
+}
+
+// InstallSystem writes the completion script written by generate to the
+// system completion directory dir. It replaces a script that it installed
+// before, in which case alreadyInstalled is true, but refuses to overwrite
+// any other file.
+func InstallSystem(shell Shell, dir string, generate func(w io.Writer) error) (filePath string, alreadyInstalled bool, err error) {
+	if !SupportsSystemInstall(shell) {
+		return "", false, fmt.Errorf("system-wide completions are not supported for %s", shell)
+	}
+	filePath = SystemFilePath(shell, dir)
+
+	ours, err := IsSystemFile(filePath)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return filePath, false, permissionHint(filePath, err)
+	case !ours:
+		return filePath, false, fmt.Errorf("%s already exists and was not installed by the Databricks CLI", filePath)
+	default:
+		alreadyInstalled = true
+	}
+
+	var b strings.Builder
+	if err := generate(&b); err != nil {
+		return filePath, false, err
+	}
+
+	// The marker goes after the #compdef line, which zsh requires to be first.
+	script := b.String()
+	header := SystemMarker + "\n"
+	if strings.HasPrefix(script, "#compdef") {
+		first, rest, _ := strings.Cut(script, "\n")
+		script = first + "\n" + header + rest
+	} else {
+		script = header + script
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return filePath, false, permissionHint(filePath, err)
+	}
+	if err := os.WriteFile(filePath, []byte(script), 0o644); err != nil {
+		return filePath, false, permissionHint(filePath, err)
+	}
+	return filePath, alreadyInstalled, nil
+}
+
+// UninstallSystem removes the completion script from the system completion
+// directory dir if it was installed by InstallSystem.
+func UninstallSystem(shell Shell, dir string) (filePath string, wasInstalled bool, err error) {
+	filePath = SystemFilePath(shell, dir)
+	ours, err := IsSystemFile(filePath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return filePath, false, nil
+	}
+	if err != nil {
+		return filePath, false, permissionHint(filePath, err)
+	}
+	if !ours {
+		return filePath, false, nil
+	}
+	if err := os.Remove(filePath); err != nil {
+		return filePath, false, permissionHint(filePath, err)
+	}
+	return filePath, true, nil
+}
+
+// permissionHint adds a hint to permission errors for system directories,
+// which usually require root.
+func permissionHint(filePath string, err error) error {
+	if errors.Is(err, fs.ErrPermission) {
+		return fmt.Errorf("no permission to write %s. Run the command with sudo, or use --dir to choose another directory: %w", filePath, err)
+	}
+	return err
+}
@@ -0,0 +1,134 @@
+package completion
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSystemDir(t *testing.T) {
+	orig := systemDirs
+	t.Cleanup(func() { systemDirs = orig })
+	systemDirs = map[Shell]string{Bash: "/system/bash", Zsh: "/system/zsh"}
+
+	useHomebrewPrefixes(t)
+	assert.Equal(t, "/system/bash", SystemDir(t.Context(), Bash))
+	assert.Equal(t, "/system/zsh", SystemDir(t.Context(), Zsh))
+	assert.Empty(t, SystemDir(t.Context(), Fish))
+
+	prefix := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+	assert.Equal(t, filepath.Join(prefix, "etc", "bash_completion.d"), SystemDir(t.Context(), Bash))
+	assert.Equal(t, filepath.Join(prefix, "share", "zsh", "site-functions"), SystemDir(t.Context(), Zsh))
+}
+
+func TestInstallSystemBash(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "bash_completion.d")
+
+	filePath, alreadyInstalled, err := InstallSystem(Bash, dir, generator("# bash completion\n"))
+	require.NoError(t, err)
+	assert.False(t, alreadyInstalled)
+	assert.Equal(t, filepath.Join(dir, "databricks"), filePath)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, SystemMarker+"\n# bash completion\n", string(content))
+
+	// Installing again replaces the script.
+	_, alreadyInstalled, err = InstallSystem(Bash, dir, generator("# new bash completion\n"))
+	require.NoError(t, err)
+	assert.True(t, alreadyInstalled)
+	content, err = os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, SystemMarker+"\n# new bash completion\n", string(content))
+}
+
+func TestInstallSystemZshKeepsCompdefFirst(t *testing.T) {
+	dir := t.TempDir()
+
+	filePath, _, err := InstallSystem(Zsh, dir, generator("#compdef databricks\ncompdef _databricks databricks\n"))
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "_databricks"), filePath)
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "#compdef databricks\n"+SystemMarker+"\ncompdef _databricks databricks\n", string(content))
+}
+
+func TestInstallSystemForeignFilePreserved(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "databricks")
+	require.NoError(t, os.WriteFile(filePath, []byte("# from the package manager\n"), 0o644))
+
+	_, _, err := InstallSystem(Bash, dir, generator("# bash completion\n"))
+	assert.ErrorContains(t, err, "was not installed by the Databricks CLI")
+
+	content, err := os.ReadFile(filePath)
+	require.NoError(t, err)
+	assert.Equal(t, "# from the package manager\n", string(content))
+}
+
+func TestInstallSystemUnsupportedShell(t *testing.T) {
+	_, _, err := InstallSystem(Fish, t.TempDir(), generator(""))
+	assert.ErrorContains(t, err, "not supported for fish")
+}
+
+func TestInstallSystemPermissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Getuid() == 0 {
+		t.Skip("requires file permissions to be enforced")
+	}
+	dir := t.TempDir()
+	require.NoError(t, os.Chmod(dir, 0o555))
+	t.Cleanup(func() { _ = os.Chmod(dir, 0o755) })
+
+	_, _, err := InstallSystem(Bash, dir, generator("# bash completion\n"))
+	assert.ErrorContains(t, err, "Run the command with sudo, or use --dir")
+	assert.ErrorIs(t, err, fs.ErrPermission)
+}
+
+func TestUninstallSystem(t *testing.T) {
+	dir := t.TempDir()
+	_, _, err := InstallSystem(Bash, dir, generator("# bash completion\n"))
+	require.NoError(t, err)
+
+	filePath, wasInstalled, err := UninstallSystem(Bash, dir)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+	assert.NoFileExists(t, filePath)
+
+	_, wasInstalled, err = UninstallSystem(Bash, dir)
+	require.NoError(t, err)
+	assert.False(t, wasInstalled)
+}
+
+func TestUninstallSystemForeignFile(t *testing.T) {
+	dir := t.TempDir()
+	filePath := filepath.Join(dir, "_databricks")
+	require.NoError(t, os.WriteFile(filePath, []byte("#compdef databricks\n"), 0o644))
+
+	_, wasInstalled, err := UninstallSystem(Zsh, dir)
+	require.NoError(t, err)
+	assert.False(t, wasInstalled)
+	assert.FileExists(t, filePath)
+}
+
+func TestStatusSystem(t *testing.T) {
+	prefix := t.TempDir()
+	t.Setenv("HOMEBREW_PREFIX", prefix)
+	home := t.TempDir()
+
+	dir := SystemDir(t.Context(), Bash)
+	_, _, err := InstallSystem(Bash, dir, generator("# bash completion\n"))
+	require.NoError(t, err)
+
+	result, err := Status(t.Context(), Bash, home)
+	require.NoError(t, err)
+	assert.True(t, result.Installed)
+	assert.Equal(t, "system", result.Method)
+	assert.Equal(t, filepath.Join(dir, "databricks"), result.FilePath)
+}
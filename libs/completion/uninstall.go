@@ -1,31 +1,41 @@
 package completion
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
 )
 
 var multiBlankLine = regexp.MustCompile(`\n{3,}`)
 
-// Uninstall removes shell completion config. Returns the file path that was
-// modified and whether it was actually installed.
-func Uninstall(shell Shell, homeDir string) (filePath string, wasInstalled bool, err error) {
-	filePath = TargetFilePath(shell, homeDir)
+// Uninstall removes shell completion config and the cached completion script.
+// Returns the file path that was modified and whether it was actually installed.
+func Uninstall(ctx context.Context, shell Shell, homeDir string) (filePath string, wasInstalled bool, err error) {
+	filePath = TargetFilePath(ctx, shell, homeDir)
 
-	if shell == Fish {
-		return uninstallFish(filePath)
+	if usesFileDrop(ctx, shell, homeDir) {
+		filePath, wasInstalled, err = uninstallFile(filePath)
+	} else {
+		filePath, wasInstalled, err = uninstallRC(filePath)
 	}
-	return uninstallRC(filePath)
+	if err != nil || !wasInstalled {
+		return filePath, wasInstalled, err
+	}
+	return filePath, wasInstalled, removeCache(shell, homeDir)
 }
 
-// uninstallFish handles the file-drop model: remove the file only if it
+// uninstallFile handles the file-drop model: remove the file only if it
 // contains our marker. This avoids deleting completions installed by a package
 // manager or created by the user.
-func uninstallFish(filePath string) (string, bool, error) {
+//
+// If the file is a symlink, the file it points to was written by Install, so
+// it is removed along with the link.
+func uninstallFile(filePath string) (string, bool, error) {
 	content, err := os.ReadFile(filePath)
 	if errors.Is(err, fs.ErrNotExist) {
 		return filePath, false, nil
@@ -38,6 +48,11 @@ func uninstallFish(filePath string) (string, bool, error) {
 		return filePath, false, nil
 	}
 
+	if target := LinkTarget(filePath); target != "" {
+		if err := os.Remove(target); err != nil {
+			return filePath, false, err
+		}
+	}
 	if err := os.Remove(filePath); err != nil {
 		return filePath, false, err
 	}
@@ -46,48 +61,98 @@ func uninstallFish(filePath string) (string, bool, error) {
 
 // uninstallRC handles the RC file model: find and remove the marker block.
 func uninstallRC(filePath string) (string, bool, error) {
-	info, err := os.Stat(filePath)
-	if errors.Is(err, fs.ErrNotExist) {
+	if _, err := os.Stat(filePath); errors.Is(err, fs.ErrNotExist) {
 		return filePath, false, nil
 	}
+
+	wasInstalled := false
+	err := updateFile(filePath, func(content []byte) ([]byte, error) {
+		// Remove every block. An RC file can contain more than one if the shim
+		// was installed by several CLI versions or copied by hand.
+		text := string(content)
+		blocks, corrupted := findBlocks(text)
+		if len(corrupted) > 0 {
+			return nil, corruptedBlocksError(filePath, corrupted)
+		}
+		wasInstalled = len(blocks) > 0
+		if !wasInstalled {
+			return content, nil
+		}
+		return []byte(removeBlocks(text, blocks)), nil
+	})
 	if err != nil {
 		return filePath, false, err
 	}
+	return filePath, wasInstalled, nil
+}
 
-	content, err := os.ReadFile(filePath)
-	if err != nil {
-		return filePath, false, err
+// block is the position of a marker block in an RC file. The end includes
+// the newline after the END marker, if any.
+type block struct {
+	start int
+	end   int
+}
+
+// findBlocks returns the marker blocks in text, and the line numbers of BEGIN
+// markers that are not followed by an END marker before the next block.
+func findBlocks(text string) (blocks []block, corrupted []int) {
+	offset := 0
+	for {
+		beginIdx := strings.Index(text[offset:], BeginMarker)
+		if beginIdx == -1 {
+			return blocks, corrupted
+		}
+		start := offset + beginIdx
+		afterBegin := text[start+len(BeginMarker):]
+
+		// Look for END marker after BEGIN, but before the next BEGIN.
+		endIdx := strings.Index(afterBegin, EndMarker)
+		nextIdx := strings.Index(afterBegin, BeginMarker)
+		if endIdx == -1 || (nextIdx != -1 && nextIdx < endIdx) {
+			corrupted = append(corrupted, strings.Count(text[:start], "\n")+1)
+			offset = start + len(BeginMarker)
+			continue
+		}
+
+		// Calculate absolute end position (after the END marker line including newline).
+		end := start + len(BeginMarker) + endIdx + len(EndMarker)
+		if end < len(text) && text[end] == '\n' {
+			end++
+		}
+		blocks = append(blocks, block{start: start, end: end})
+		offset = end
 	}
+}
 
-	text := string(content)
-	beginIdx := strings.Index(text, BeginMarker)
-	if beginIdx == -1 {
-		return filePath, false, nil
+// removeBlocks returns text without blocks, which must be in order.
+func removeBlocks(text string, blocks []block) string {
+	var b strings.Builder
+	prev := 0
+	for _, blk := range blocks {
+		b.WriteString(text[prev:blk.start])
+		prev = blk.end
 	}
+	b.WriteString(text[prev:])
 
-	// Find the line number of BEGIN for error reporting.
-	beginLine := strings.Count(text[:beginIdx], "\n") + 1
+	// Collapse double blank lines left by removal.
+	return multiBlankLine.ReplaceAllString(b.String(), "\n\n")
+}
 
-	// Look for END marker after BEGIN.
-	afterBegin := text[beginIdx:]
-	endIdx := strings.Index(afterBegin, EndMarker)
-	if endIdx == -1 {
-		return filePath, false, fmt.Errorf(
+// corruptedBlocksError returns the error for BEGIN markers on lines that have
+// no matching END marker.
+func corruptedBlocksError(filePath string, lines []int) error {
+	if len(lines) == 1 {
+		return fmt.Errorf(
 			"found corrupted completion block in %s: missing end marker. Please remove the block starting at line %d manually",
-			filePath, beginLine,
+			filePath, lines[0],
 		)
 	}
-
-	// Calculate absolute end position (after the END marker line including newline).
-	blockEnd := beginIdx + endIdx + len(EndMarker)
-	if blockEnd < len(text) && text[blockEnd] == '\n' {
-		blockEnd++
+	var strs []string
+	for _, line := range lines {
+		strs = append(strs, strconv.Itoa(line))
 	}
-
-	result := text[:beginIdx] + text[blockEnd:]
-
-	// Collapse double blank lines left by removal.
-	result = multiBlankLine.ReplaceAllString(result, "\n\n")
-
-	return filePath, true, os.WriteFile(filePath, []byte(result), info.Mode())
+	return fmt.Errorf(
+		"found corrupted completion blocks in %s: missing end markers. Please remove the blocks starting at lines %s manually",
+		filePath, strings.Join(strs, ", "),
+	)
 }
@@ -1,10 +1,12 @@
 package completion
 
 import (
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -14,10 +16,10 @@ import (
 func TestUninstallRemovesBlock(t *testing.T) {
 	home := t.TempDir()
 	rcPath := filepath.Join(home, ".zshrc")
-	content := "# before\n" + ShimContent(Zsh) + "# after\n"
+	content := "# before\n" + ShimContent(Zsh, true) + "# after\n"
 	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
 
-	filePath, wasInstalled, err := Uninstall(Zsh, home)
+	filePath, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
 	require.NoError(t, err)
 	assert.True(t, wasInstalled)
 	assert.Equal(t, rcPath, filePath)
@@ -33,7 +35,7 @@ func TestUninstallNotInstalled(t *testing.T) {
 	rcPath := filepath.Join(home, ".zshrc")
 	require.NoError(t, os.WriteFile(rcPath, []byte("# no completion here\n"), 0o644))
 
-	_, wasInstalled, err := Uninstall(Zsh, home)
+	_, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
 	require.NoError(t, err)
 	assert.False(t, wasInstalled)
 }
@@ -41,7 +43,7 @@ func TestUninstallNotInstalled(t *testing.T) {
 func TestUninstallFileDoesNotExist(t *testing.T) {
 	home := t.TempDir()
 
-	_, wasInstalled, err := Uninstall(Zsh, home)
+	_, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
 	require.NoError(t, err)
 	assert.False(t, wasInstalled)
 }
@@ -52,7 +54,7 @@ func TestUninstallCorruptedMissingEnd(t *testing.T) {
 	content := "# before\n" + BeginMarker + "\neval something\n"
 	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
 
-	_, _, err := Uninstall(Zsh, home)
+	_, _, err := Uninstall(t.Context(), Zsh, home)
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "corrupted completion block")
 	assert.ErrorContains(t, err, "missing end marker")
@@ -64,13 +66,60 @@ func TestUninstallCorruptedMissingEnd(t *testing.T) {
 	assert.Equal(t, content, string(result))
 }
 
+func TestUninstallRemovesDuplicatedBlocks(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".zshrc")
+	content := "# before\n" + ShimContent(Zsh, true) + "# middle\n" + ShimContent(Zsh, true) + "# after\n"
+	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
+
+	_, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+
+	result, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# before\n# middle\n# after\n", string(result))
+
+	status, err := Status(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.False(t, status.Installed)
+}
+
+func TestUninstallGoodAndCorruptedBlocks(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".zshrc")
+	content := "# before\n" + ShimContent(Zsh, true) + "# middle\n" + BeginMarker + "\neval something\n"
+	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
+
+	_, _, err := Uninstall(t.Context(), Zsh, home)
+	assert.ErrorContains(t, err, "missing end marker")
+	assert.ErrorContains(t, err, fmt.Sprintf("line %d", strings.Count(ShimContent(Zsh, true), "\n")+3))
+
+	// Verify file is unchanged.
+	result, readErr := os.ReadFile(rcPath)
+	require.NoError(t, readErr)
+	assert.Equal(t, content, string(result))
+}
+
+func TestUninstallReportsEachCorruptedBlock(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".zshrc")
+	// The first BEGIN is followed by another BEGIN before any END.
+	content := BeginMarker + "\neval something\n" + BeginMarker + "\n" + BeginMarker + "\n"
+	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
+
+	_, _, err := Uninstall(t.Context(), Zsh, home)
+	assert.ErrorContains(t, err, "found corrupted completion blocks")
+	assert.ErrorContains(t, err, "lines 1, 3, 4")
+}
+
 func TestUninstallCollapsesDoubleBlankLines(t *testing.T) {
 	home := t.TempDir()
 	rcPath := filepath.Join(home, ".zshrc")
-	content := "# before\n\n" + ShimContent(Zsh) + "\n# after\n"
+	content := "# before\n\n" + ShimContent(Zsh, true) + "\n# after\n"
 	require.NoError(t, os.WriteFile(rcPath, []byte(content), 0o644))
 
-	_, _, err := Uninstall(Zsh, home)
+	_, _, err := Uninstall(t.Context(), Zsh, home)
 	require.NoError(t, err)
 
 	result, err := os.ReadFile(rcPath)
@@ -81,9 +130,9 @@ func TestUninstallCollapsesDoubleBlankLines(t *testing.T) {
 func TestUninstallPreservesPermissions(t *testing.T) {
 	home := t.TempDir()
 	rcPath := filepath.Join(home, ".zshrc")
-	require.NoError(t, os.WriteFile(rcPath, []byte(ShimContent(Zsh)), 0o600))
+	require.NoError(t, os.WriteFile(rcPath, []byte(ShimContent(Zsh, true)), 0o600))
 
-	_, _, err := Uninstall(Zsh, home)
+	_, _, err := Uninstall(t.Context(), Zsh, home)
 	require.NoError(t, err)
 
 	info, err := os.Stat(rcPath)
@@ -103,9 +152,9 @@ func TestUninstallFish(t *testing.T) {
 	fishPath := filepath.Join(home, ".config", "fish", "completions", "databricks.fish")
 	require.NoError(t, os.MkdirAll(filepath.Dir(fishPath), 0o755))
 	// Write content that includes our marker (simulating a CLI-managed file).
-	require.NoError(t, os.WriteFile(fishPath, []byte(ShimContent(Fish)), 0o644))
+	require.NoError(t, os.WriteFile(fishPath, []byte(ShimContent(Fish, true)), 0o644))
 
-	filePath, wasInstalled, err := Uninstall(Fish, home)
+	filePath, wasInstalled, err := Uninstall(t.Context(), Fish, home)
 	require.NoError(t, err)
 	assert.True(t, wasInstalled)
 	assert.Equal(t, fishPath, filePath)
@@ -121,7 +170,7 @@ func TestUninstallFishForeignFile(t *testing.T) {
 	// Write content without our marker (e.g. installed by a package manager).
 	require.NoError(t, os.WriteFile(fishPath, []byte("# fish completions from homebrew\n"), 0o644))
 
-	_, wasInstalled, err := Uninstall(Fish, home)
+	_, wasInstalled, err := Uninstall(t.Context(), Fish, home)
 	require.NoError(t, err)
 	assert.False(t, wasInstalled)
 
@@ -133,28 +182,70 @@ func TestUninstallFishForeignFile(t *testing.T) {
 func TestUninstallFishNotPresent(t *testing.T) {
 	home := t.TempDir()
 
-	_, wasInstalled, err := Uninstall(Fish, home)
+	_, wasInstalled, err := Uninstall(t.Context(), Fish, home)
 	require.NoError(t, err)
 	assert.False(t, wasInstalled)
 }
 
-func TestInstallThenUninstallRoundTrip(t *testing.T) {
+func TestUninstallNushell(t *testing.T) {
 	home := t.TempDir()
-	rcPath := filepath.Join(home, ".zshrc")
-	original := "# my zsh config\nexport FOO=bar\n"
-	require.NoError(t, os.WriteFile(rcPath, []byte(original), 0o644))
+	nuPath := filepath.Join(home, ".config", "nushell", "autoload", "databricks.nu")
+	require.NoError(t, os.MkdirAll(filepath.Dir(nuPath), 0o755))
+	require.NoError(t, os.WriteFile(nuPath, []byte(ShimContent(Nushell, true)), 0o644))
 
-	_, _, err := Install(t.Context(), Zsh, home)
+	filePath, wasInstalled, err := Uninstall(t.Context(), Nushell, home)
 	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+	assert.Equal(t, nuPath, filePath)
 
-	content, err := os.ReadFile(rcPath)
-	require.NoError(t, err)
-	assert.Contains(t, string(content), BeginMarker)
+	_, err = os.Stat(nuPath)
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
 
-	_, _, err = Uninstall(Zsh, home)
-	require.NoError(t, err)
+func TestUninstallElvishForeignFile(t *testing.T) {
+	home := t.TempDir()
+	elvPath := filepath.Join(home, ".elvish", "lib", "databricks.elv")
+	require.NoError(t, os.MkdirAll(filepath.Dir(elvPath), 0o755))
+	require.NoError(t, os.WriteFile(elvPath, []byte("# elvish completion written by hand\n"), 0o644))
 
-	result, err := os.ReadFile(rcPath)
+	_, wasInstalled, err := Uninstall(t.Context(), Elvish, home)
 	require.NoError(t, err)
-	assert.Equal(t, original, string(result))
+	assert.False(t, wasInstalled)
+
+	_, err = os.Stat(elvPath)
+	assert.NoError(t, err)
+}
+
+func TestInstallThenUninstallRoundTrip(t *testing.T) {
+	for _, shell := range []Shell{Bash, Zsh, Fish} {
+		t.Run(string(shell), func(t *testing.T) {
+			home := t.TempDir()
+			filePath := TargetFilePath(t.Context(), shell, home)
+			require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0o755))
+			original := ""
+			if shell != Fish {
+				original = "# my config\nexport FOO=bar\n"
+				require.NoError(t, os.WriteFile(filePath, []byte(original), 0o644))
+			}
+
+			_, _, err := Install(t.Context(), shell, home, true)
+			require.NoError(t, err)
+
+			content, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, original+ShimContent(shell, true), string(content))
+
+			_, wasInstalled, err := Uninstall(t.Context(), shell, home)
+			require.NoError(t, err)
+			assert.True(t, wasInstalled)
+
+			if shell == Fish {
+				assert.NoFileExists(t, filePath)
+				return
+			}
+			result, err := os.ReadFile(filePath)
+			require.NoError(t, err)
+			assert.Equal(t, original, string(result))
+		})
+	}
 }
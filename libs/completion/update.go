@@ -0,0 +1,120 @@
+package completion
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/databricks/cli/libs/fileutil"
+)
+
+// errFileChanged is returned by tryUpdateFile if another process modified the
+// file after it was read.
+var errFileChanged = errors.New("file was modified while it was updated")
+
+// beforeReplace is called after a file was read and its new content was
+// written to a temporary file, right before the file is replaced. Tests use
+// it to simulate concurrent edits.
+var beforeReplace = func(path string) {}
+
+// updateFile replaces the content of the file at path with the result of
+// update, which is called with the current content, or nil if the file
+// doesn't exist.
+//
+// Shell configuration files are edited by other tools too, such as dotfile
+// managers and other installers. To not lose their changes, the new content
+// is written to a temporary file that replaces the file only if it wasn't
+// modified since it was read. Otherwise, the update is retried once with the
+// new content. The mode and owner of an existing file are preserved, and a
+// symlink is followed rather than replaced.
+func updateFile(path string, update func(content []byte) ([]byte, error)) error {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	} else if target := LinkTarget(path); target != "" {
+		path = target
+	}
+
+	err := tryUpdateFile(path, update)
+	if errors.Is(err, errFileChanged) {
+		err = tryUpdateFile(path, update)
+	}
+	if errors.Is(err, errFileChanged) {
+		return fmt.Errorf("%s was modified by another program while it was updated. Please try again", filepath.ToSlash(path))
+	}
+	return err
+}
+
+func tryUpdateFile(path string, update func(content []byte) ([]byte, error)) error {
+	var content []byte
+	before, err := os.Stat(path)
+	switch {
+	case err == nil:
+		content, err = os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+	case errors.Is(err, fs.ErrNotExist):
+		before = nil
+	default:
+		return err
+	}
+
+	updated, err := update(content)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(content, updated) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	write := func(w io.Writer) error {
+		_, err := w.Write(updated)
+		return err
+	}
+	return fileutil.ReplaceFile(path, 0o644, write, func() error {
+		beforeReplace(path)
+
+		after, err := os.Stat(path)
+		switch {
+		case before == nil && err == nil:
+			return errFileChanged
+		case before == nil && errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			return errFileChanged
+		case !after.ModTime().Equal(before.ModTime()) || after.Size() != before.Size():
+			return errFileChanged
+		}
+		return nil
+	})
+}
+
+// LinkTarget returns the path of the file that the symlink at path points to,
+// following all links, or "" if path is not a symlink. Shell configuration
+// files are often symlinks into a repository of a dotfiles manager such as
+// chezmoi or stow; updateFile modifies the file they point to.
+func LinkTarget(path string) string {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&fs.ModeSymlink == 0 {
+		return ""
+	}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+
+	// The link is dangling, so the file it points to doesn't exist yet.
+	target, err := os.Readlink(path)
+	if err != nil {
+		return ""
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(path), target)
+	}
+	return target
+}
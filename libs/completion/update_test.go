@@ -0,0 +1,210 @@
+package completion
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// onBeforeReplace calls fn for the first n replacements in the test.
+func onBeforeReplace(t *testing.T, n int, fn func(path string)) {
+	orig := beforeReplace
+	t.Cleanup(func() { beforeReplace = orig })
+	calls := 0
+	beforeReplace = func(path string) {
+		calls++
+		if calls <= n {
+			fn(path)
+		}
+	}
+}
+
+// editConcurrently appends line to the file at path and makes sure that its
+// modification time changes, even on file systems with a coarse resolution.
+func editConcurrently(t *testing.T, path, line string) {
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, append(content, line...), 0o644))
+	mtime := info.ModTime().Add(time.Second)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+}
+
+func TestInstallKeepsConcurrentEdit(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".zshrc")
+	require.NoError(t, os.WriteFile(rcPath, []byte("# mine\n"), 0o644))
+	onBeforeReplace(t, 1, func(path string) {
+		editConcurrently(t, path, "# from another tool\n")
+	})
+
+	_, _, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\n# from another tool\n"+ShimContent(Zsh, true), string(content))
+}
+
+func TestInstallRetryAddsOneNewline(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".zshrc")
+	require.NoError(t, os.WriteFile(rcPath, []byte("# mine"), 0o644))
+	onBeforeReplace(t, 1, func(path string) {
+		editConcurrently(t, path, " # from another tool")
+	})
+
+	_, _, err := Install(t.Context(), Zsh, home, true)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine # from another tool\n"+ShimContent(Zsh, true), string(content))
+}
+
+func TestUninstallKeepsConcurrentEdit(t *testing.T) {
+	home := t.TempDir()
+	rcPath := filepath.Join(home, ".zshrc")
+	require.NoError(t, os.WriteFile(rcPath, []byte("# mine\n"+ShimContent(Zsh, true)), 0o644))
+	onBeforeReplace(t, 1, func(path string) {
+		editConcurrently(t, path, "# from another tool\n")
+	})
+
+	_, wasInstalled, err := Uninstall(t.Context(), Zsh, home)
+	require.NoError(t, err)
+	assert.True(t, wasInstalled)
+
+	content, err := os.ReadFile(rcPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\n# from another tool\n", string(content))
+}
+
+func TestUpdateFileGivesUpAfterRetry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	require.NoError(t, os.WriteFile(path, []byte("# mine\n"), 0o644))
+	onBeforeReplace(t, 2, func(path string) {
+		editConcurrently(t, path, "# from another tool\n")
+	})
+
+	err := updateFile(path, func(content []byte) ([]byte, error) {
+		return append(content, "# ours\n"...), nil
+	})
+	assert.ErrorContains(t, err, "was modified by another program while it was updated")
+
+	// The concurrent edits are kept and no temporary files are left behind.
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\n# from another tool\n# from another tool\n", string(content))
+	entries, err := os.ReadDir(filepath.Dir(path))
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestUpdateFileDetectsConcurrentCreate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	onBeforeReplace(t, 1, func(path string) {
+		require.NoError(t, os.WriteFile(path, []byte("# from another tool\n"), 0o644))
+	})
+
+	err := updateFile(path, func(content []byte) ([]byte, error) {
+		return append(content, "# ours\n"...), nil
+	})
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "# from another tool\n# ours\n", string(content))
+}
+
+func TestUpdateFileFollowsSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on windows")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	require.NoError(t, os.WriteFile(target, []byte("# mine\n"), 0o600))
+	link := filepath.Join(dir, ".zshrc")
+	require.NoError(t, os.Symlink(target, link))
+
+	err := updateFile(link, func(content []byte) ([]byte, error) {
+		return append(content, "# ours\n"...), nil
+	})
+	require.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "# mine\n# ours\n", string(content))
+	info, err = os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestUpdateFileUnchanged(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".bashrc")
+	err := updateFile(path, func(content []byte) ([]byte, error) {
+		return content, nil
+	})
+	require.NoError(t, err)
+	assert.NoFileExists(t, path)
+}
+
+func TestLinkTarget(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on windows")
+	}
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	require.NoError(t, err)
+	target := filepath.Join(dir, "dotfiles", "zshrc")
+	require.NoError(t, os.MkdirAll(filepath.Dir(target), 0o755))
+	require.NoError(t, os.WriteFile(target, []byte("# mine\n"), 0o644))
+
+	link := filepath.Join(dir, ".zshrc")
+	require.NoError(t, os.Symlink(filepath.Join("dotfiles", "zshrc"), link))
+	assert.Equal(t, target, LinkTarget(link))
+
+	// A link to a link resolves to the final file.
+	link2 := filepath.Join(dir, ".zshrc2")
+	require.NoError(t, os.Symlink(link, link2))
+	assert.Equal(t, target, LinkTarget(link2))
+
+	// A dangling link resolves to the file it would create.
+	dangling := filepath.Join(dir, ".bashrc")
+	require.NoError(t, os.Symlink(filepath.Join("dotfiles", "bashrc"), dangling))
+	assert.Equal(t, filepath.Join(dir, "dotfiles", "bashrc"), LinkTarget(dangling))
+
+	assert.Equal(t, "", LinkTarget(target))
+	assert.Equal(t, "", LinkTarget(filepath.Join(dir, "missing")))
+}
+
+func TestUpdateFileCreatesTargetOfDanglingSymlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("symlinks require extra privileges on windows")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles", "zshrc")
+	link := filepath.Join(dir, ".zshrc")
+	require.NoError(t, os.Symlink(target, link))
+
+	err := updateFile(link, func(content []byte) ([]byte, error) {
+		return append(content, "# ours\n"...), nil
+	})
+	require.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+	content, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "# ours\n", string(content))
+}
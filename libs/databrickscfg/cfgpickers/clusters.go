@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"slices"
 	"strings"
 
 	"github.com/databricks/cli/libs/cmdio"
@@ -128,6 +129,58 @@ func WithoutSystemClusters() func(*compute.ClusterDetails, *iam.User) bool {
 	}
 }
 
+// UnityCatalogAccessModes are the access modes of clusters that can access
+// Unity Catalog.
+var UnityCatalogAccessModes = []compute.DataSecurityMode{
+	compute.DataSecurityModeSingleUser,
+	compute.DataSecurityModeUserIsolation,
+	compute.DataSecurityModeDataSecurityModeDedicated,
+	compute.DataSecurityModeDataSecurityModeStandard,
+}
+
+// WithMinSparkVersion keeps only clusters with a Databricks Runtime of at
+// least minVersion, for example "13.3".
+func WithMinSparkVersion(minVersion string) func(*compute.ClusterDetails, *iam.User) bool {
+	minVersion = canonicalVersion(minVersion)
+	return func(cluster *compute.ClusterDetails, me *iam.User) bool {
+		runtimeVersion, ok := GetRuntimeVersion(*cluster)
+		if !ok {
+			return false
+		}
+		return semver.Compare(canonicalVersion(runtimeVersion), minVersion) >= 0
+	}
+}
+
+// WithAccessMode keeps only clusters with one of the given access modes. Use
+// [UnityCatalogAccessModes] to keep only clusters that can access Unity Catalog.
+func WithAccessMode(modes ...compute.DataSecurityMode) func(*compute.ClusterDetails, *iam.User) bool {
+	return func(cluster *compute.ClusterDetails, me *iam.User) bool {
+		return slices.Contains(modes, cluster.DataSecurityMode)
+	}
+}
+
+// WithCanAttach keeps only clusters the current user can attach to, which
+// excludes clusters assigned to other users.
+func WithCanAttach() func(*compute.ClusterDetails, *iam.User) bool {
+	return func(cluster *compute.ClusterDetails, me *iam.User) bool {
+		return cluster.SingleUserName == "" || cluster.SingleUserName == me.UserName
+	}
+}
+
+// WithSearch keeps only clusters that match the text as if it was typed into
+// the search of the cluster picker. An empty text matches all clusters.
+func WithSearch(text string) func(*compute.ClusterDetails, *iam.User) bool {
+	return func(cluster *compute.ClusterDetails, me *iam.User) bool {
+		return clusterMatches(cluster, text)
+	}
+}
+
+// clusterMatches reports whether the name of the cluster contains input,
+// ignoring case.
+func clusterMatches(cluster *compute.ClusterDetails, input string) bool {
+	return strings.Contains(strings.ToLower(cluster.ClusterName), strings.ToLower(input))
+}
+
 func loadInteractiveClusters(ctx context.Context, w *databricks.WorkspaceClient, filters []clusterFilter) ([]compatibleCluster, error) {
 	sp := cmdio.NewSpinner(ctx)
 	sp.Update("Loading list of clusters to select from")
@@ -193,8 +246,7 @@ func AskForCluster(ctx context.Context, w *databricks.WorkspaceClient, filters .
 		Label: "Choose compatible cluster",
 		Items: compatible,
 		Searcher: func(input string, idx int) bool {
-			lower := strings.ToLower(compatible[idx].ClusterName)
-			return strings.Contains(lower, strings.ToLower(input))
+			return clusterMatches(&compatible[idx].ClusterDetails, input)
 		},
 		StartInSearchMode: true,
 		Templates: &promptui.SelectTemplates{
@@ -5,10 +5,12 @@ import (
 
 	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/databricks-sdk-go"
+	"github.com/databricks/databricks-sdk-go/experimental/mocks"
 	"github.com/databricks/databricks-sdk-go/qa"
 	"github.com/databricks/databricks-sdk-go/service/compute"
 	"github.com/databricks/databricks-sdk-go/service/iam"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -164,3 +166,92 @@ func TestNoCompatibleClusters(t *testing.T) {
 	_, err := AskForCluster(ctx, w, WithDatabricksConnect("13.1"))
 	require.Equal(t, ErrNoCompatibleClusters, err)
 }
+
+func newClustersMock(t *testing.T) *mocks.MockWorkspaceClient {
+	m := mocks.NewMockWorkspaceClient(t)
+	m.GetMockClustersAPI().EXPECT().
+		ListAll(mock.Anything, mock.Anything).
+		Return([]compute.ClusterDetails{
+			{ClusterId: "shared-id", ClusterName: "Shared Analytics", DataSecurityMode: compute.DataSecurityModeUserIsolation, SparkVersion: "14.3.x-scala2.12"},
+			{ClusterId: "mine-id", ClusterName: "Serge's Cluster", DataSecurityMode: compute.DataSecurityModeSingleUser, SparkVersion: "13.3.x-scala2.12", SingleUserName: "serge"},
+			{ClusterId: "other-id", ClusterName: "Alice's Cluster", DataSecurityMode: compute.DataSecurityModeSingleUser, SparkVersion: "15.4.x-scala2.12", SingleUserName: "alice"},
+			{ClusterId: "legacy-id", ClusterName: "Legacy Analytics", DataSecurityMode: compute.DataSecurityModeNone, SparkVersion: "11.3.x-scala2.12"},
+			{ClusterId: "snapshot-id", ClusterName: "Snapshot", DataSecurityMode: compute.DataSecurityModeDataSecurityModeStandard, SparkVersion: "16.x-snapshot-scala2.12"},
+		}, nil)
+	m.GetMockCurrentUserAPI().EXPECT().
+		Me(mock.Anything).
+		Return(&iam.User{UserName: "serge"}, nil)
+	m.GetMockClustersAPI().EXPECT().
+		SparkVersions(mock.Anything).
+		Return(&compute.GetSparkVersionsResponse{}, nil)
+	return m
+}
+
+func TestClusterFilters(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		filters []clusterFilter
+		want    []string
+	}{
+		{
+			name: "no filters",
+			want: []string{"shared-id", "mine-id", "other-id", "legacy-id", "snapshot-id"},
+		},
+		{
+			name:    "min spark version",
+			filters: []clusterFilter{WithMinSparkVersion("14.3")},
+			want:    []string{"shared-id", "other-id", "snapshot-id"},
+		},
+		{
+			name:    "unity catalog access modes",
+			filters: []clusterFilter{WithAccessMode(UnityCatalogAccessModes...)},
+			want:    []string{"shared-id", "mine-id", "other-id", "snapshot-id"},
+		},
+		{
+			name:    "can attach",
+			filters: []clusterFilter{WithCanAttach()},
+			want:    []string{"shared-id", "mine-id", "legacy-id", "snapshot-id"},
+		},
+		{
+			name:    "search ignores case",
+			filters: []clusterFilter{WithSearch("analytics")},
+			want:    []string{"shared-id", "legacy-id"},
+		},
+		{
+			name:    "empty search",
+			filters: []clusterFilter{WithSearch("")},
+			want:    []string{"shared-id", "mine-id", "other-id", "legacy-id", "snapshot-id"},
+		},
+		{
+			name: "combined",
+			filters: []clusterFilter{
+				WithMinSparkVersion("13.3"),
+				WithAccessMode(UnityCatalogAccessModes...),
+				WithCanAttach(),
+				WithSearch("s"),
+			},
+			want: []string{"shared-id", "mine-id", "snapshot-id"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newClustersMock(t)
+
+			clusters, err := loadInteractiveClusters(cmdio.MockDiscard(t.Context()), m.WorkspaceClient, tc.filters)
+			require.NoError(t, err)
+
+			var ids []string
+			for _, c := range clusters {
+				ids = append(ids, c.ClusterId)
+			}
+			assert.Equal(t, tc.want, ids)
+		})
+	}
+}
+
+func TestAskForClusterWithSearch(t *testing.T) {
+	m := newClustersMock(t)
+
+	clusterID, err := AskForCluster(cmdio.MockDiscard(t.Context()), m.WorkspaceClient, WithSearch("alice"))
+	require.NoError(t, err)
+	assert.Equal(t, "other-id", clusterID)
+}
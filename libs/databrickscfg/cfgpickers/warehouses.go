@@ -22,53 +22,146 @@ var ErrNoCompatibleWarehouses = errors.New("no compatible warehouses")
 
 type warehouseFilter func(sql.EndpointInfo) bool
 
-func WithWarehouseTypes(types ...sql.EndpointInfoWarehouseType) func(sql.EndpointInfo) bool {
+type warehouseOptions struct {
+	filters          []warehouseFilter
+	preferServerless bool
+}
+
+// WarehouseOption filters or orders the warehouses that [AskForWarehouse] and
+// [SelectWarehouse] offer.
+type WarehouseOption func(*warehouseOptions)
+
+func newWarehouseOptions(opts []WarehouseOption) warehouseOptions {
+	var o warehouseOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// apply returns the warehouses that pass all filters, sorted by state and
+// name. Serverless warehouses come first if they are preferred.
+func (o warehouseOptions) apply(all []sql.EndpointInfo) []sql.EndpointInfo {
+	var warehouses []sql.EndpointInfo
+	for _, wh := range sortWarehousesByState(all) {
+		if !slices.ContainsFunc(o.filters, func(filter warehouseFilter) bool { return !filter(wh) }) {
+			warehouses = append(warehouses, wh)
+		}
+	}
+	if o.preferServerless {
+		slices.SortStableFunc(warehouses, func(a, b sql.EndpointInfo) int {
+			switch {
+			case a.EnableServerlessCompute == b.EnableServerlessCompute:
+				return 0
+			case a.EnableServerlessCompute:
+				return -1
+			default:
+				return 1
+			}
+		})
+	}
+	return warehouses
+}
+
+// WithWarehouseTypes keeps only warehouses of the given types.
+func WithWarehouseTypes(types ...sql.EndpointInfoWarehouseType) WarehouseOption {
 	allowed := map[sql.EndpointInfoWarehouseType]bool{}
 	for _, v := range types {
 		allowed[v] = true
 	}
-	return func(ei sql.EndpointInfo) bool {
-		return allowed[ei.WarehouseType]
+	return func(o *warehouseOptions) {
+		o.filters = append(o.filters, func(ei sql.EndpointInfo) bool {
+			return allowed[ei.WarehouseType]
+		})
+	}
+}
+
+// WithRunningOnly keeps only running warehouses.
+func WithRunningOnly() WarehouseOption {
+	return func(o *warehouseOptions) {
+		o.filters = append(o.filters, func(ei sql.EndpointInfo) bool {
+			return ei.State == sql.StateRunning
+		})
+	}
+}
+
+// WithServerlessPreferred lists serverless warehouses before other warehouses.
+func WithServerlessPreferred() WarehouseOption {
+	return func(o *warehouseOptions) {
+		o.preferServerless = true
 	}
 }
 
-func AskForWarehouse(ctx context.Context, w *databricks.WorkspaceClient, filters ...warehouseFilter) (string, error) {
+type compatibleWarehouse struct {
+	sql.EndpointInfo
+}
+
+func (v compatibleWarehouse) State() string {
+	state := v.EndpointInfo.State
+	switch state {
+	case sql.StateRunning:
+		return color.GreenString(state.String())
+	case sql.StateStopped, sql.StateDeleted, sql.StateStopping, sql.StateDeleting:
+		return color.RedString(state.String())
+	default:
+		return color.BlueString(state.String())
+	}
+}
+
+func (v compatibleWarehouse) Type() string {
+	if v.EnableServerlessCompute {
+		return "serverless"
+	}
+	return strings.ToLower(string(v.WarehouseType))
+}
+
+// AskForWarehouse prompts the user to choose one of the SQL warehouses that
+// pass the filters in opts and returns its ID. If only one warehouse passes,
+// it is returned without prompting. If prompts are not supported, the error
+// lists the warehouses to choose from.
+func AskForWarehouse(ctx context.Context, w *databricks.WorkspaceClient, opts ...WarehouseOption) (string, error) {
 	all, err := w.Warehouses.ListAll(ctx, sql.ListWarehousesRequest{})
 	if err != nil {
 		return "", fmt.Errorf("list warehouses: %w", err)
 	}
-	var lastWarehouseID string
-	names := map[string]string{}
-	for _, warehouse := range all {
-		var skip bool
-		for _, filter := range filters {
-			if !filter(warehouse) {
-				skip = true
-			}
-		}
-		if skip {
-			continue
-		}
-		var state string
-		switch warehouse.State {
-		case sql.StateRunning:
-			state = color.GreenString(warehouse.State.String())
-		case sql.StateStopped, sql.StateDeleted, sql.StateStopping, sql.StateDeleting:
-			state = color.RedString(warehouse.State.String())
-		default:
-			state = color.BlueString(warehouse.State.String())
+	warehouses := newWarehouseOptions(opts).apply(all)
+	if len(warehouses) == 0 {
+		return "", ErrNoCompatibleWarehouses
+	}
+	if len(warehouses) == 1 {
+		return warehouses[0].Id, nil
+	}
+	if !cmdio.IsPromptSupported(ctx) {
+		var names []string
+		for _, wh := range warehouses {
+			names = append(names, fmt.Sprintf("%s (%s)", wh.Name, wh.Id))
 		}
-		visibleTouser := fmt.Sprintf("%s (%s %s)", warehouse.Name, state, warehouse.WarehouseType)
-		names[visibleTouser] = warehouse.Id
-		lastWarehouseID = warehouse.Id
+		return "", fmt.Errorf("cannot choose a SQL warehouse in non-interactive mode, available warehouses: %s", strings.Join(names, ", "))
 	}
-	if len(names) == 0 {
-		return "", ErrNoCompatibleWarehouses
+
+	compatible := make([]compatibleWarehouse, len(warehouses))
+	for i, wh := range warehouses {
+		compatible[i] = compatibleWarehouse{wh}
 	}
-	if len(names) == 1 {
-		return lastWarehouseID, nil
+	i, _, err := cmdio.RunSelect(ctx, &promptui.Select{
+		Label: "Choose SQL Warehouse",
+		Items: compatible,
+		Searcher: func(input string, idx int) bool {
+			lower := strings.ToLower(compatible[idx].Name)
+			return strings.Contains(lower, strings.ToLower(input))
+		},
+		StartInSearchMode: true,
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{.Name | faint}}",
+			Active:   `{{.Name | bold}} ({{.State}} {{.ClusterSize}} {{.Type}}) ({{.Id | faint}})`,
+			Inactive: `{{.Name}} ({{.State}} {{.ClusterSize}} {{.Type}})`,
+			Selected: `{{ "Configured warehouse" | faint }}: {{ .Name | bold }} ({{.Id | faint}})`,
+		},
+	})
+	if err != nil {
+		return "", err
 	}
-	return cmdio.Select(ctx, names, "Choose SQL Warehouse")
+	return compatible[i].Id, nil
 }
 
 // sortWarehousesByState sorts warehouses by state priority (running first), then alphabetically by name.
@@ -151,29 +244,13 @@ func listUsableWarehouses(ctx context.Context, w *databricks.WorkspaceClient) ([
 // Warehouses are sorted by state (running first) so the default selection is the best available.
 // In non-interactive mode, returns the first (best) warehouse automatically.
 // The description parameter is shown before the picker (if non-empty).
-func SelectWarehouse(ctx context.Context, w *databricks.WorkspaceClient, description string, filters ...warehouseFilter) (string, error) {
+func SelectWarehouse(ctx context.Context, w *databricks.WorkspaceClient, description string, opts ...WarehouseOption) (string, error) {
 	all, err := w.Warehouses.ListAll(ctx, sql.ListWarehousesRequest{})
 	if err != nil {
 		return "", fmt.Errorf("list warehouses: %w", err)
 	}
 
-	warehouses := sortWarehousesByState(all)
-
-	// Apply filters
-	var filtered []sql.EndpointInfo
-	for _, wh := range warehouses {
-		skip := false
-		for _, filter := range filters {
-			if !filter(wh) {
-				skip = true
-				break
-			}
-		}
-		if !skip {
-			filtered = append(filtered, wh)
-		}
-	}
-	warehouses = filtered
+	warehouses := newWarehouseOptions(opts).apply(all)
 
 	if len(warehouses) == 0 {
 		return "", ErrNoCompatibleWarehouses
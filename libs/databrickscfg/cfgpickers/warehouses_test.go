@@ -1,12 +1,18 @@
 package cfgpickers
 
 import (
+	"context"
+	"io"
 	"testing"
+	"time"
 
+	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/databricks-sdk-go"
+	"github.com/databricks/databricks-sdk-go/experimental/mocks"
 	"github.com/databricks/databricks-sdk-go/qa"
 	"github.com/databricks/databricks-sdk-go/service/sql"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
 
@@ -63,3 +69,87 @@ func TestNoCompatibleWarehouses(t *testing.T) {
 	_, err := AskForWarehouse(ctx, w, WithWarehouseTypes(sql.EndpointInfoWarehouseTypePro))
 	assert.Equal(t, ErrNoCompatibleWarehouses, err)
 }
+
+func newWarehousesMock(t *testing.T) *mocks.MockWorkspaceClient {
+	m := mocks.NewMockWorkspaceClient(t)
+	m.GetMockWarehousesAPI().EXPECT().
+		ListAll(mock.Anything, sql.ListWarehousesRequest{}).
+		Return([]sql.EndpointInfo{
+			{Id: "stopped-id", Name: "Stopped", State: sql.StateStopped, ClusterSize: "Small", WarehouseType: sql.EndpointInfoWarehouseTypePro},
+			{Id: "deleted-id", Name: "Deleted", State: sql.StateDeleted, ClusterSize: "Small", WarehouseType: sql.EndpointInfoWarehouseTypePro},
+			{Id: "classic-id", Name: "Classic", State: sql.StateRunning, ClusterSize: "Medium", WarehouseType: sql.EndpointInfoWarehouseTypeClassic},
+			{Id: "serverless-id", Name: "Serverless", State: sql.StateStopped, ClusterSize: "2X-Small", WarehouseType: sql.EndpointInfoWarehouseTypePro, EnableServerlessCompute: true},
+		}, nil)
+	return m
+}
+
+func TestAskForWarehouseWithRunningOnly(t *testing.T) {
+	m := newWarehousesMock(t)
+
+	id, err := AskForWarehouse(t.Context(), m.WorkspaceClient, WithRunningOnly())
+	require.NoError(t, err)
+	assert.Equal(t, "classic-id", id)
+}
+
+func TestAskForWarehouseNonInteractive(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		opts    []WarehouseOption
+		wantErr string
+	}{
+		{
+			name:    "running first",
+			wantErr: "cannot choose a SQL warehouse in non-interactive mode, available warehouses: Classic (classic-id), Serverless (serverless-id), Stopped (stopped-id)",
+		},
+		{
+			name:    "serverless preferred",
+			opts:    []WarehouseOption{WithServerlessPreferred()},
+			wantErr: "cannot choose a SQL warehouse in non-interactive mode, available warehouses: Serverless (serverless-id), Classic (classic-id), Stopped (stopped-id)",
+		},
+		{
+			name:    "filtered by type",
+			opts:    []WarehouseOption{WithWarehouseTypes(sql.EndpointInfoWarehouseTypePro), WithServerlessPreferred()},
+			wantErr: "cannot choose a SQL warehouse in non-interactive mode, available warehouses: Serverless (serverless-id), Stopped (stopped-id)",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newWarehousesMock(t)
+
+			_, err := AskForWarehouse(cmdio.MockDiscard(t.Context()), m.WorkspaceClient, tc.opts...)
+			assert.EqualError(t, err, tc.wantErr)
+		})
+	}
+}
+
+func TestAskForWarehouseInteractive(t *testing.T) {
+	m := newWarehousesMock(t)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 3*time.Second)
+	defer cancel()
+	ctx, testIO := cmdio.SetupTest(ctx, cmdio.TestOptions{PromptSupported: true})
+	defer testIO.Done()
+
+	// Drain the prompt output so that rendering it doesn't block.
+	go func() {
+		_, _ = testIO.Stderr.WriteTo(io.Discard)
+	}()
+
+	type result struct {
+		id  string
+		err error
+	}
+	results := make(chan result)
+	go func() {
+		id, err := AskForWarehouse(ctx, m.WorkspaceClient, WithServerlessPreferred())
+		results <- result{id, err}
+	}()
+
+	// Search for the stopped warehouse and select it.
+	_, err := testIO.Stdin.WriteString("Stopped\r")
+	require.NoError(t, err)
+	require.NoError(t, testIO.Stdin.Flush())
+
+	r := <-results
+	require.NoError(t, r.err)
+	assert.Equal(t, "stopped-id", r.id)
+}
@@ -1,22 +1,45 @@
 package databrickscfg
 
-import "net/url"
+import (
+	"net/url"
+	"strings"
+)
 
-// normalizeHost returns the string representation of only
-// the scheme and host part of the specified host.
-func normalizeHost(host string) string {
-	u, err := url.Parse(host)
-	if err != nil {
-		return host
+// defaultPorts maps a scheme to the port that is implied when a host doesn't
+// specify one.
+var defaultPorts = map[string]string{
+	"https": "443",
+	"http":  "80",
+}
+
+// NormalizeHost returns the canonical form of a host: only its scheme and host
+// name, in lower case. The default port of the scheme, such as :443 on GCP
+// hosts, is stripped, as are paths like /aad/auth on Azure hosts, query
+// strings and fragments. A host without scheme is assumed to use https.
+// Input that isn't an http or https URL with a host is returned with
+// surrounding whitespace trimmed.
+//
+// The configure command, profile matching in this package and the profile
+// package all use this function, so that a host saved by one matches in the
+// others.
+func NormalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	rawURL := host
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "https://" + rawURL
 	}
-	if u.Scheme == "" || u.Host == "" {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
 		return host
 	}
 
+	hostname := strings.ToLower(u.Host)
+	if port := u.Port(); port == "" || port == defaultPorts[u.Scheme] {
+		hostname = strings.TrimSuffix(hostname, ":"+port)
+	}
 	normalized := &url.URL{
 		Scheme: u.Scheme,
-		Host:   u.Host,
+		Host:   hostname,
 	}
-
 	return normalized.String()
 }
@@ -7,20 +7,47 @@ import (
 )
 
 func TestNormalizeHost(t *testing.T) {
-	assert.Equal(t, "invalid", normalizeHost("invalid"))
-
-	// With port.
-	assert.Equal(t, "http://foo:123", normalizeHost("http://foo:123"))
-
-	// With trailing slash.
-	assert.Equal(t, "http://foo", normalizeHost("http://foo/"))
-
-	// With path.
-	assert.Equal(t, "http://foo", normalizeHost("http://foo/bar"))
-
-	// With query string.
-	assert.Equal(t, "http://foo", normalizeHost("http://foo?bar"))
-
-	// With anchor.
-	assert.Equal(t, "http://foo", normalizeHost("http://foo#bar"))
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Empty input.
+		{"", ""},
+		{"   ", ""},
+		{"https://", "https://"},
+		{"ftp://foo", "ftp://foo"},
+
+		// Missing scheme defaults to https.
+		{"invalid", "https://invalid"},
+		{"  example.databricks.com  ", "https://example.databricks.com"},
+		{"example.databricks.com/", "https://example.databricks.com"},
+
+		// With port.
+		{"http://foo:123", "http://foo:123"},
+		{"localhost:8080", "https://localhost:8080"},
+		{"foo:443/bar", "https://foo"},
+		{"https://foo:port", "https://foo:port"},
+
+		// Default ports are stripped.
+		{"https://foo:443", "https://foo"},
+		{"http://foo:80", "http://foo"},
+		{"http://foo:443", "http://foo:443"},
+		{"https://foo:/", "https://foo"},
+
+		// Case is folded.
+		{"HTTPS://Foo.Cloud.Databricks.COM", "https://foo.cloud.databricks.com"},
+
+		// With trailing slash, path, query string or anchor.
+		{"http://foo/", "http://foo"},
+		{"http://foo/bar", "http://foo"},
+		{"https://adb-123.4.azuredatabricks.net/aad/auth", "https://adb-123.4.azuredatabricks.net"},
+		{"http://foo?bar", "http://foo"},
+		{"http://foo#bar", "http://foo"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			assert.Equal(t, test.expected, NormalizeHost(test.input))
+		})
+	}
 }
@@ -0,0 +1,294 @@
+package databrickscfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+	"gopkg.in/ini.v1"
+)
+
+// IncludeKey is the key of the DEFAULT section that lists config files whose
+// profiles are added to the profiles of the file. Multiple files are separated
+// by commas. Relative paths are relative to the directory of the file.
+const IncludeKey = "include"
+
+// includeEnvVar lists config files that are included by the primary config
+// file, separated by the OS path list separator.
+const includeEnvVar = "DATABRICKS_CONFIG_INCLUDE"
+
+// File is a config file together with the profiles of the files it includes.
+// Profiles of the including file take precedence over profiles with the same
+// name in included files, and profiles of files that are included later take
+// precedence over profiles of files that are included earlier.
+//
+// The DEFAULT and settings sections of included files are ignored. Included
+// files are only read; changes to profiles are always written to the primary
+// file.
+type File struct {
+	*config.File
+
+	// sources maps the names of profiles that are defined in included files
+	// to the paths of these files.
+	sources map[string]string
+
+	// included holds the paths of the included files that were loaded.
+	included []string
+}
+
+// Included returns the paths of the included files that were loaded.
+func (f *File) Included() []string {
+	return f.included
+}
+
+// Source returns the path of the file that defines the named profile.
+func (f *File) Source(name string) string {
+	if path, ok := f.sources[name]; ok {
+		return path
+	}
+	return f.Path()
+}
+
+// LoadFile loads the config file at path and merges the profiles of the files
+// it includes through the include key of its DEFAULT section and the
+// DATABRICKS_CONFIG_INCLUDE environment variable. Included files that don't
+// exist are skipped. It returns an error if files include each other.
+func LoadFile(ctx context.Context, path string) (*File, error) {
+	primary, err := config.LoadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Relative paths in the environment variable are relative to the working
+	// directory rather than to the primary file.
+	var extra []string
+	if v := env.Get(ctx, includeEnvVar); v != "" {
+		for _, p := range filepath.SplitList(v) {
+			path, err := ExpandConfigFilePath(ctx, p)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", includeEnvVar, err)
+			}
+			path, err = filepath.Abs(path)
+			if err != nil {
+				return nil, err
+			}
+			extra = append(extra, path)
+		}
+	}
+	abs, err := filepath.Abs(primary.Path())
+	if err != nil {
+		return nil, err
+	}
+	l := &includeLoader{ctx: ctx}
+	included, err := l.includes(primary, extra, []string{abs})
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{File: primary, sources: map[string]string{}, included: l.loaded}
+	for _, s := range included {
+		name := s.section.Name()
+		if _, ok := f.sources[name]; !ok && primary.HasSection(name) {
+			continue
+		}
+		primary.DeleteSection(name)
+		section, err := primary.NewSection(name)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range s.section.Keys() {
+			section.Key(key.Name()).SetValue(key.Value())
+		}
+		f.sources[name] = s.path
+	}
+	return f, nil
+}
+
+// includedSection is a profile of an included file.
+type includedSection struct {
+	section *ini.Section
+	path    string
+}
+
+type includeLoader struct {
+	ctx context.Context
+
+	// loaded holds the paths of the included files that were loaded.
+	loaded []string
+}
+
+// includes returns the profiles of the files included by file, followed by
+// the files in extra, in the order in which they take precedence. The stack
+// holds the absolute paths of the files that are being loaded, with the path
+// of file last, to detect cycles.
+func (l *includeLoader) includes(file *config.File, extra, stack []string) ([]includedSection, error) {
+	var paths []string
+	if key, err := file.Section(ini.DefaultSection).GetKey(IncludeKey); err == nil {
+		for _, p := range strings.Split(key.Value(), ",") {
+			if p = strings.TrimSpace(p); p != "" {
+				paths = append(paths, p)
+			}
+		}
+	}
+	paths = append(paths, extra...)
+
+	var result []includedSection
+	for _, p := range paths {
+		path, err := ExpandConfigFilePath(l.ctx, p)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.Path(), err)
+		}
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(filepath.Dir(stack[len(stack)-1]), path)
+		}
+		path = filepath.Clean(path)
+		if slices.Contains(stack, path) {
+			return nil, fmt.Errorf("cannot include %s: config files include each other: %s", path, strings.Join(slices.Concat(stack, []string{path}), " -> "))
+		}
+
+		included, err := config.LoadFile(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			log.Debugf(l.ctx, "Skipping config file %s included by %s: the file does not exist", path, file.Path())
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot load config file %s included by %s: %w", path, file.Path(), err)
+		}
+
+		l.loaded = append(l.loaded, path)
+		nested, err := l.includes(included, nil, slices.Concat(stack, []string{path}))
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, nested...)
+		for _, s := range included.Sections() {
+			if s.Name() == ini.DefaultSection || s.Name() == SettingsSection {
+				continue
+			}
+			result = append(result, includedSection{section: s, path: path})
+		}
+	}
+	return result, nil
+}
+
+// ConfigFile is a [config.Loader] like [config.ConfigFile] that also loads
+// profiles from the files included by the config file, and expands the
+// references to environment variables in their values.
+var ConfigFile = configFileLoader{}
+
+// Loaders returns the default loaders of the SDK with [ConfigFile] in place
+// of [config.ConfigFile], followed by [AllowMultipleAuth].
+func Loaders() []config.Loader {
+	return []config.Loader{config.ConfigAttributes, ConfigFile, AllowMultipleAuth}
+}
+
+type configFileLoader struct{}
+
+func (l configFileLoader) Name() string {
+	return "config-file"
+}
+
+func (l configFileLoader) Configure(cfg *config.Config) error {
+	ctx := context.Background() //nolint:gocritic // SDK interface does not accept context.
+
+	// Like the loader of the SDK, skip loading the config file if
+	// authentication is configured directly and no profile is specified.
+	// The auth type alone doesn't skip it, so that it can select the auth
+	// type of the DEFAULT profile.
+	if cfg.Profile == "" && (hasAuthAttribute(cfg) || cfg.Host != "" || cfg.AzureResourceID != "") {
+		return nil
+	}
+
+	path, err := resolveConfigFilePath(ctx, cfg.ConfigFile)
+	if err != nil {
+		return err
+	}
+	configFile, err := LoadFile(ctx, path)
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Debugf(ctx, "%s not found on current host", path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("cannot parse config file: %w", err)
+	}
+
+	name := cfg.Profile
+	isFallback := false
+	if name == "" {
+		name = GetConfiguredDefaultProfileFrom(configFile.File)
+	}
+	if name == "" {
+		name = ini.DefaultSection
+		isFallback = true
+	}
+	if name == SettingsSection {
+		return fmt.Errorf("%s: %s is a reserved section name and cannot be used as a profile", configFile.Path(), SettingsSection)
+	}
+
+	section, err := findProfileSection(ctx, configFile, name)
+	if err != nil {
+		return fmt.Errorf("%s: %w", configFile.Path(), err)
+	}
+	if section == nil || len(section.Keys()) == 0 {
+		if isFallback {
+			log.Debugf(ctx, "%s has no %s profile configured", configFile.Path(), name)
+			return nil
+		}
+		return fmt.Errorf("%s has no %s profile configured", configFile.Path(), name)
+	}
+
+	name = section.Name()
+	source := configFile.Source(name)
+	log.Debugf(ctx, "Loading %s profile from %s", name, source)
+	values, err := ExpandEnv(ctx, section)
+	if err != nil {
+		return fmt.Errorf("%s: %w", source, err)
+	}
+	err = config.ConfigAttributes.ResolveFromStringMapWithSource(cfg, values, config.Source{
+		Type: config.SourceFile,
+		Name: source,
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s profile: %w", source, name, err)
+	}
+	if !isFallback {
+		cfg.Profile = name
+	}
+	return nil
+}
+
+// findProfileSection returns the section of the named profile. If no section
+// has exactly that name, the only section whose name differs in case is used,
+// like [profile.Profiles.FindByName]. It returns an error if multiple sections
+// differ in case only, and nil if no section matches.
+func findProfileSection(ctx context.Context, configFile *File, name string) (*ini.Section, error) {
+	if section, err := configFile.GetSection(name); err == nil {
+		return section, nil
+	}
+	var folded []*ini.Section
+	for _, section := range configFile.Sections() {
+		if section.Name() != SettingsSection && strings.EqualFold(section.Name(), name) {
+			folded = append(folded, section)
+		}
+	}
+	switch len(folded) {
+	case 0:
+		return nil, nil
+	case 1:
+		log.Debugf(ctx, "Using profile %q for %q, the names differ in case only", folded[0].Name(), name)
+		return folded[0], nil
+	default:
+		names := make([]string, len(folded))
+		for i, section := range folded {
+			names[i] = section.Name()
+		}
+		return nil, fmt.Errorf("profile %q matches multiple profiles that differ in case only: %s. Use the exact profile name", name, strings.Join(names, ", "))
+	}
+}
@@ -0,0 +1,296 @@
+package databrickscfg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, path, contents string) {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, []byte(contents), fileMode))
+}
+
+func TestLoadFileWithIncludes(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	managed := filepath.Join(dir, "etc", "profiles.cfg")
+	writeConfig(t, managed, `[DEFAULT]
+include = nested.cfg
+host    = https://ignored
+
+[shared]
+host = https://managed-shared
+
+[managed]
+host = https://managed
+
+[__settings__]
+default_profile = managed
+`)
+	writeConfig(t, filepath.Join(dir, "etc", "nested.cfg"), `[managed]
+host = https://nested-managed
+
+[nested]
+host = https://nested
+`)
+	writeConfig(t, filepath.Join(dir, "team.cfg"), `[managed]
+host = https://team-managed
+`)
+	primary := filepath.Join(dir, ".databrickscfg")
+	writeConfig(t, primary, `[DEFAULT]
+include = `+managed+`, team.cfg, missing.cfg
+
+[shared]
+host = https://personal
+`)
+
+	file, err := LoadFile(ctx, primary)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEFAULT", "shared", "nested", "managed"}, file.SectionStrings())
+
+	// The including file wins over the files it includes, and files that
+	// are included later win over files that are included earlier.
+	assert.Equal(t, "https://personal", file.Section("shared").Key("host").String())
+	assert.Equal(t, "https://team-managed", file.Section("managed").Key("host").String())
+	assert.Equal(t, "https://nested", file.Section("nested").Key("host").String())
+	assert.False(t, file.Section("DEFAULT").HasKey("host"))
+
+	assert.Equal(t, primary, file.Path())
+	assert.Equal(t, primary, file.Source("shared"))
+	assert.Equal(t, filepath.Join(dir, "team.cfg"), file.Source("managed"))
+	assert.Equal(t, filepath.Join(dir, "etc", "nested.cfg"), file.Source("nested"))
+	assert.Equal(t, []string{managed, filepath.Join(dir, "etc", "nested.cfg"), filepath.Join(dir, "team.cfg")}, file.Included())
+}
+
+func TestLoadFileWithIncludeEnv(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, ".databrickscfg")
+	writeConfig(t, primary, "[personal]\nhost = https://personal\n")
+	first := filepath.Join(dir, "first.cfg")
+	writeConfig(t, first, "[first]\nhost = https://first\n")
+	second := filepath.Join(dir, "second.cfg")
+	writeConfig(t, second, "[second]\nhost = https://second\n")
+
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_INCLUDE", first+string(os.PathListSeparator)+second)
+	file, err := LoadFile(ctx, primary)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEFAULT", "personal", "first", "second"}, file.SectionStrings())
+	assert.Equal(t, second, file.Source("second"))
+}
+
+func TestLoadFileWithIncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, ".databrickscfg")
+	writeConfig(t, primary, "include = a.cfg\n")
+	writeConfig(t, filepath.Join(dir, "a.cfg"), "include = b.cfg\n")
+	writeConfig(t, filepath.Join(dir, "b.cfg"), "include = a.cfg\n")
+
+	_, err := LoadFile(t.Context(), primary)
+	assert.ErrorContains(t, err, "config files include each other")
+	assert.ErrorContains(t, err, filepath.Join(dir, "a.cfg")+" -> "+filepath.Join(dir, "b.cfg")+" -> "+filepath.Join(dir, "a.cfg"))
+}
+
+func TestLoadFileWithInvalidInclude(t *testing.T) {
+	dir := t.TempDir()
+	primary := filepath.Join(dir, ".databrickscfg")
+	writeConfig(t, primary, "include = bad.cfg\n")
+	writeConfig(t, filepath.Join(dir, "bad.cfg"), "[unclosed\n")
+
+	_, err := LoadFile(t.Context(), primary)
+	assert.ErrorContains(t, err, "cannot load config file "+filepath.Join(dir, "bad.cfg")+" included by "+primary)
+}
+
+func TestLoaderMatchingHostInIncludedFile(t *testing.T) {
+	dir := t.TempDir()
+	managed := filepath.Join(dir, "managed.cfg")
+	writeConfig(t, managed, `[managed]
+host  = https://managed
+token = managed-token
+
+[dup]
+host  = https://dup
+token = a
+`)
+	primary := filepath.Join(dir, ".databrickscfg")
+	writeConfig(t, primary, `[DEFAULT]
+include = managed.cfg
+
+[personal]
+host  = https://dup
+token = b
+`)
+
+	cfg := config.Config{
+		Loaders:    []config.Loader{ResolveProfileFromHost},
+		ConfigFile: primary,
+		Host:       "https://managed",
+	}
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "managed", cfg.Profile)
+	assert.Equal(t, "managed-token", cfg.Token)
+
+	cfg = config.Config{
+		Loaders:    []config.Loader{ResolveProfileFromHost},
+		ConfigFile: primary,
+		Host:       "https://dup",
+	}
+	err = cfg.EnsureResolved()
+	assert.ErrorContains(t, err, "multiple profiles matched: personal, dup (dup from "+managed+")")
+}
+
+func TestSaveToProfileKeepsIncludedFiles(t *testing.T) {
+	ctx := t.Context()
+	dir := t.TempDir()
+	managed := filepath.Join(dir, "managed.cfg")
+	writeConfig(t, managed, "[managed]\nhost = https://managed\n")
+	primary := filepath.Join(dir, ".databrickscfg")
+	writeConfig(t, primary, "[DEFAULT]\ninclude = managed.cfg\n")
+
+	err := SaveToProfile(ctx, &config.Config{
+		ConfigFile: primary,
+		Profile:    "managed",
+		Host:       "https://managed",
+		Token:      "token",
+	})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(managed)
+	require.NoError(t, err)
+	assert.Equal(t, "[managed]\nhost = https://managed\n", string(contents))
+
+	contents, err = os.ReadFile(primary)
+	require.NoError(t, err)
+	assert.Equal(t, `[DEFAULT]
+include = managed.cfg
+
+[managed]
+host  = https://managed
+token = token
+
+[__settings__]
+default_profile = managed
+`, string(contents))
+}
+
+func TestConfigFileLoaderWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	managed := filepath.Join(dir, "managed.cfg")
+	writeConfig(t, managed, "[managed]\nhost  = https://managed\ntoken = managed-token\n")
+	primary := filepath.Join(dir, ".databrickscfg")
+	writeConfig(t, primary, "[DEFAULT]\ninclude = managed.cfg\n")
+
+	cfg := config.Config{
+		Loaders:    []config.Loader{ConfigFile},
+		ConfigFile: primary,
+		Profile:    "managed",
+	}
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "https://managed", cfg.Host)
+	assert.Equal(t, "managed-token", cfg.Token)
+
+	cfg = config.Config{
+		Loaders:    []config.Loader{ConfigFile},
+		ConfigFile: primary,
+		Profile:    "missing",
+	}
+	err = cfg.EnsureResolved()
+	assert.ErrorContains(t, err, primary+" has no missing profile configured")
+}
+
+func TestConfigFileLoaderExpandsEnv(t *testing.T) {
+	primary := filepath.Join(t.TempDir(), ".databrickscfg")
+	writeConfig(t, primary, "[dev]\nhost  = ${env:TEST_LOADER_HOST}\ntoken = ${env:TEST_LOADER_TOKEN}\n")
+	t.Setenv("TEST_LOADER_HOST", "https://dev")
+	t.Setenv("TEST_LOADER_TOKEN", "dev-token")
+
+	cfg := config.Config{
+		Loaders:    []config.Loader{ConfigFile},
+		ConfigFile: primary,
+		Profile:    "dev",
+	}
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "https://dev", cfg.Host)
+	assert.Equal(t, "dev-token", cfg.Token)
+
+	os.Unsetenv("TEST_LOADER_TOKEN")
+	cfg = config.Config{
+		Loaders:    []config.Loader{ConfigFile},
+		ConfigFile: primary,
+		Profile:    "dev",
+	}
+	err = cfg.EnsureResolved()
+	assert.ErrorContains(t, err, `profile "dev": token references environment variable TEST_LOADER_TOKEN, which is not set`)
+}
+
+func TestConfigFileLoaderLoadsDefaultWithAuthType(t *testing.T) {
+	primary := filepath.Join(t.TempDir(), ".databrickscfg")
+	writeConfig(t, primary, "[DEFAULT]\nhost  = https://default\ntoken = default-token\n")
+
+	// Like the loader of the SDK, an auth type without credentials doesn't
+	// skip the DEFAULT profile.
+	cfg := config.Config{
+		Loaders:    []config.Loader{ConfigFile},
+		ConfigFile: primary,
+		AuthType:   "pat",
+	}
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "https://default", cfg.Host)
+	assert.Equal(t, "default-token", cfg.Token)
+
+	// Credentials that are set directly skip it.
+	cfg = config.Config{
+		Loaders:    []config.Loader{ConfigFile},
+		ConfigFile: primary,
+		Token:      "direct-token",
+	}
+	err = cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Empty(t, cfg.Host)
+	assert.Equal(t, "direct-token", cfg.Token)
+}
+
+func TestConfigFileLoaderProfileNameCase(t *testing.T) {
+	primary := filepath.Join(t.TempDir(), ".databrickscfg")
+	writeConfig(t, primary, "[dev]\nhost  = https://dev\ntoken = dev-token\n\n[prod]\nhost  = https://prod\n\n[PROD]\nhost  = https://PROD\n")
+
+	cases := []struct {
+		name        string
+		profile     string
+		wantProfile string
+		wantHost    string
+		wantErr     string
+	}{
+		{name: "exact", profile: "dev", wantProfile: "dev", wantHost: "https://dev"},
+		{name: "case-folded unique", profile: "Dev", wantProfile: "dev", wantHost: "https://dev"},
+		{name: "exact among case-folded", profile: "PROD", wantProfile: "PROD", wantHost: "https://PROD"},
+		{name: "ambiguous", profile: "Prod", wantErr: `profile "Prod" matches multiple profiles that differ in case only: prod, PROD`},
+		{name: "missing", profile: "test", wantErr: "has no test profile configured"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.Config{
+				Loaders:    []config.Loader{ConfigFile},
+				ConfigFile: primary,
+				Profile:    tc.profile,
+			}
+			err := cfg.EnsureResolved()
+			if tc.wantErr != "" {
+				assert.ErrorContains(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantProfile, cfg.Profile)
+			assert.Equal(t, tc.wantHost, cfg.Host)
+		})
+	}
+}
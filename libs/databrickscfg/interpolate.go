@@ -0,0 +1,47 @@
+package databrickscfg
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/databricks/cli/libs/env"
+	"gopkg.in/ini.v1"
+)
+
+// envReference matches references to environment variables in config values,
+// such as ${env:MY_DATABRICKS_HOST}. Values that contain $ in any other way
+// are taken literally.
+var envReference = regexp.MustCompile(`\$\{env:([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ExpandEnv returns the keys of section with references to environment
+// variables of the form ${env:NAME} replaced by their values. It returns an
+// error naming the profile and key if a referenced variable is not set.
+func ExpandEnv(ctx context.Context, section *ini.Section) (map[string]string, error) {
+	values := section.KeysHash()
+	for key, value := range values {
+		expanded, err := expandValue(ctx, section.Name(), key, value)
+		if err != nil {
+			return nil, err
+		}
+		values[key] = expanded
+	}
+	return values, nil
+}
+
+// expandValue replaces the references to environment variables in value.
+func expandValue(ctx context.Context, profile, key, value string) (string, error) {
+	var err error
+	expanded := envReference.ReplaceAllStringFunc(value, func(ref string) string {
+		name := envReference.FindStringSubmatch(ref)[1]
+		v, ok := env.Lookup(ctx, name)
+		if !ok && err == nil {
+			err = fmt.Errorf("profile %q: %s references environment variable %s, which is not set", profile, key, name)
+		}
+		return v
+	})
+	if err != nil {
+		return "", err
+	}
+	return expanded, nil
+}
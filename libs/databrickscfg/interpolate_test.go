@@ -0,0 +1,93 @@
+package databrickscfg
+
+import (
+	"testing"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/ini.v1"
+)
+
+func TestExpandValue(t *testing.T) {
+	ctx := env.Set(t.Context(), "MY_HOST", "https://my.cloud.databricks.com")
+	ctx = env.Set(ctx, "WS", "dbc-123")
+	ctx = env.Set(ctx, "EMPTY", "")
+
+	cases := []struct {
+		value   string
+		want    string
+		wantErr string
+	}{
+		{value: "${env:MY_HOST}", want: "https://my.cloud.databricks.com"},
+		{value: "https://${env:WS}.cloud.databricks.com", want: "https://dbc-123.cloud.databricks.com"},
+		{value: "${env:EMPTY}", want: ""},
+		{value: "pa$$word", want: "pa$$word"},
+		{value: "$MY_HOST", want: "$MY_HOST"},
+		{value: "${MY_HOST}", want: "${MY_HOST}"},
+		{value: "${env:}", want: "${env:}"},
+		{value: "${env:UNSET}", wantErr: `profile "dev": host references environment variable UNSET, which is not set`},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := expandValue(ctx, "dev", "host", tc.value)
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestExpandEnv(t *testing.T) {
+	file, err := ini.Load([]byte("[dev]\nhost = ${env:MY_HOST}\ntoken = literal$\n"))
+	require.NoError(t, err)
+
+	ctx := env.Set(t.Context(), "MY_HOST", "https://my.cloud.databricks.com")
+	values, err := ExpandEnv(ctx, file.Section("dev"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"host":  "https://my.cloud.databricks.com",
+		"token": "literal$",
+	}, values)
+}
+
+func TestLoaderExpandsEnv(t *testing.T) {
+	t.Setenv("MY_HOST", "https://foo")
+	t.Setenv("MY_TOKEN", "secret")
+
+	cfg := config.Config{
+		Loaders: []config.Loader{
+			ResolveProfileFromHost,
+		},
+		ConfigFile: writeLoaderConfig(t, `[dev]
+host = ${env:MY_HOST}
+token = ${env:MY_TOKEN}
+`),
+		Host: "https://foo",
+	}
+
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "dev", cfg.Profile)
+	assert.Equal(t, "secret", cfg.Token)
+}
+
+func TestLoaderErrorsOnUnsetEnv(t *testing.T) {
+	cfg := config.Config{
+		Loaders: []config.Loader{
+			ResolveProfileFromHost,
+		},
+		ConfigFile: writeLoaderConfig(t, `[dev]
+host = ${env:DATABRICKS_CLI_TEST_UNSET_HOST}
+`),
+		Host: "https://foo",
+	}
+
+	err := cfg.EnsureResolved()
+	assert.ErrorContains(t, err, `profile "dev": host references environment variable DATABRICKS_CLI_TEST_UNSET_HOST, which is not set`)
+}
@@ -1,10 +1,14 @@
 package databrickscfg
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
 	"io/fs"
+	"log/slog"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/databricks/cli/libs/log"
@@ -67,43 +71,140 @@ func (l profileFromHostLoader) Name() string {
 	return "resolve-profile-from-host"
 }
 
+// HostResolutionDecision describes the outcome of resolving a profile from a host.
+type HostResolutionDecision string
+
+const (
+	HostResolutionSkippedNoHost         HostResolutionDecision = "skipped-no-host"
+	HostResolutionSkippedAuthConfigured HostResolutionDecision = "skipped-auth-configured"
+	HostResolutionNoConfigFile          HostResolutionDecision = "no-config-file"
+	HostResolutionNoMatch               HostResolutionDecision = "no-match"
+	HostResolutionSelected              HostResolutionDecision = "selected"
+	HostResolutionMultipleMatches       HostResolutionDecision = "multiple-matches"
+)
+
+// HostResolutionTrace records which profiles were considered when resolving
+// a profile from a host and what the final decision was.
+type HostResolutionTrace struct {
+	ConfigFile      string                 `json:"config_file,omitempty"`
+	Host            string                 `json:"host,omitempty"`
+	ProfilesScanned int                    `json:"profiles_scanned"`
+	Matched         []string               `json:"matched,omitempty"`
+	Decision        HostResolutionDecision `json:"decision"`
+	Profile         string                 `json:"profile,omitempty"`
+}
+
+// TraceProfileFromHost returns the decision trace of [ResolveProfileFromHost]
+// for the given configuration without modifying it. The returned error is the
+// error the loader would return, if any.
+func TraceProfileFromHost(cfg *config.Config) (*HostResolutionTrace, error) {
+	ctx := context.Background() //nolint:gocritic // Mirrors the SDK loader, which does not accept context.
+	trace, _, _, err := ResolveProfileFromHost.resolve(ctx, cfg)
+	return trace, err
+}
+
 func (l profileFromHostLoader) Configure(cfg *config.Config) error {
+	ctx := context.Background() //nolint:gocritic // SDK interface does not accept context.
+	trace, match, configFile, err := l.resolve(ctx, cfg)
+	log.GetLogger(ctx).Debug("Resolved profile from host",
+		slog.String("config_file", trace.ConfigFile),
+		slog.String("host", trace.Host),
+		slog.Int("profiles_scanned", trace.ProfilesScanned),
+		slog.String("matched", strings.Join(trace.Matched, ",")),
+		slog.String("decision", string(trace.Decision)),
+		slog.String("profile", trace.Profile),
+	)
+	if err != nil || match == nil {
+		return err
+	}
+
+	values, err := ExpandEnv(ctx, match)
+	if err != nil {
+		return err
+	}
+	source := configFile.Source(match.Name())
+	err = config.ConfigAttributes.ResolveFromStringMapWithSource(cfg, values, config.Source{
+		Type: config.SourceFile,
+		Name: source,
+	})
+	if err != nil {
+		return fmt.Errorf("%s %s profile: %w", source, match.Name(), err)
+	}
+
+	cfg.Profile = match.Name()
+	return nil
+}
+
+// resolve looks up the profile matching the configured host. It returns a nil
+// section if no profile should be loaded.
+func (l profileFromHostLoader) resolve(ctx context.Context, cfg *config.Config) (*HostResolutionTrace, *ini.Section, *File, error) {
+	trace := &HostResolutionTrace{
+		ConfigFile: cfg.ConfigFile,
+	}
+
 	// Skip an attempt to resolve a profile from the host if any authentication
 	// is already configured (either directly, through environment variables, or
 	// if a profile was specified).
-	if cfg.Host == "" || l.isAnyAuthConfigured(cfg) {
-		return nil
+	if cfg.Host == "" {
+		trace.Decision = HostResolutionSkippedNoHost
+		return trace, nil, nil, nil
+	}
+	if l.isAnyAuthConfigured(cfg) {
+		trace.Decision = HostResolutionSkippedAuthConfigured
+		return trace, nil, nil, nil
 	}
 
-	ctx := context.Background() //nolint:gocritic // SDK interface does not accept context.
-	configFile, err := config.LoadFile(cfg.ConfigFile)
+	path, err := resolveConfigFilePath(ctx, cfg.ConfigFile)
+	if err != nil {
+		return trace, nil, nil, err
+	}
+	configFile, err := LoadFile(ctx, path)
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
-			return nil
+			trace.Decision = HostResolutionNoConfigFile
+			return trace, nil, nil, nil
 		}
-		return fmt.Errorf("cannot parse config file: %w", err)
+		return trace, nil, nil, fmt.Errorf("cannot parse config file: %w", err)
 	}
+	trace.ConfigFile = configFile.Path()
+
 	// Normalized version of the configured host.
-	host := normalizeHost(cfg.Host)
-	match, err := findMatchingProfile(configFile, func(s *ini.Section) bool {
+	host := NormalizeHost(cfg.Host)
+	trace.Host = host
+	var expandErr error
+	match, err := findMatchingProfile(configFile.File, func(s *ini.Section) bool {
+		trace.ProfilesScanned++
 		key, err := s.GetKey("host")
 		if err != nil {
 			log.Tracef(ctx, "section %s: %s", s.Name(), err)
 			return false
 		}
+		value, err := expandValue(ctx, s.Name(), key.Name(), key.Value())
+		if err != nil {
+			expandErr = cmp.Or(expandErr, err)
+			return false
+		}
 
 		// Check if this section matches the normalized host
-		return normalizeHost(key.Value()) == host
+		if NormalizeHost(value) != host {
+			return false
+		}
+		trace.Matched = append(trace.Matched, s.Name())
+		return true
 	})
+	if expandErr != nil {
+		return trace, nil, nil, expandErr
+	}
 	if err == errNoMatchingProfiles {
-		return nil
+		trace.Decision = HostResolutionNoMatch
+		return trace, nil, nil, nil
 	}
 
 	// If multiple profiles match the same host and we have a workspace_id,
 	// try to disambiguate by matching workspace_id.
 	if names, ok := AsMultipleProfiles(err); ok && cfg.WorkspaceID != "" {
 		originalErr := err
-		match, err = l.disambiguateByWorkspaceID(ctx, configFile, host, cfg.WorkspaceID, names)
+		match, err = l.disambiguateByWorkspaceID(ctx, configFile.File, host, cfg.WorkspaceID, names)
 		if err == errNoMatchingProfiles {
 			// workspace_id didn't match any of the host-matching profiles.
 			// Fall back to the original ambiguity error.
@@ -112,26 +213,42 @@ func (l profileFromHostLoader) Configure(cfg *config.Config) error {
 		}
 	}
 
-	if _, ok := AsMultipleProfiles(err); ok {
-		return fmt.Errorf(
-			"%s: %w: please set DATABRICKS_CONFIG_PROFILE or provide --profile flag to specify one",
-			host, err)
-	}
-	if err != nil {
-		return err
+	// Multiple profiles that can be used interchangeably are not ambiguous.
+	if names, ok := AsMultipleProfiles(err); ok {
+		if preferred := l.preferProfile(ctx, configFile.File, host, names); preferred != nil {
+			match, err = preferred, nil
+		}
 	}
 
-	log.Debugf(ctx, "Loading profile %s because of host match", match.Name())
-	err = config.ConfigAttributes.ResolveFromStringMapWithSource(cfg, match.KeysHash(), config.Source{
-		Type: config.SourceFile,
-		Name: configFile.Path(),
-	})
+	if names, ok := AsMultipleProfiles(err); ok {
+		trace.Decision = HostResolutionMultipleMatches
+		return trace, nil, nil, fmt.Errorf(
+			"%s: %w%s: please set DATABRICKS_CONFIG_PROFILE or provide --profile flag to specify one",
+			host, err, includedSources(configFile, names))
+	}
 	if err != nil {
-		return fmt.Errorf("%s %s profile: %w", configFile.Path(), match.Name(), err)
+		return trace, nil, nil, err
 	}
 
-	cfg.Profile = match.Name()
-	return nil
+	trace.Decision = HostResolutionSelected
+	trace.Profile = match.Name()
+	return trace, match, configFile, nil
+}
+
+// includedSources names the included files that define any of the named
+// profiles, so that ambiguity errors tell where to find each profile. It
+// returns an empty string if all profiles are defined in the primary file.
+func includedSources(configFile *File, names []string) string {
+	var sources []string
+	for _, name := range names {
+		if source := configFile.Source(name); source != configFile.Path() {
+			sources = append(sources, fmt.Sprintf("%s from %s", name, source))
+		}
+	}
+	if len(sources) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(sources, ", ") + ")"
 }
 
 // disambiguateByWorkspaceID filters the profiles that matched a host by workspace_id.
@@ -161,17 +278,59 @@ func (l profileFromHostLoader) disambiguateByWorkspaceID(
 	})
 }
 
-func (l profileFromHostLoader) isAnyAuthConfigured(cfg *config.Config) bool {
-	// If any of the auth-specific attributes are set, we can skip profile resolution.
-	for _, a := range config.ConfigAttributes {
-		if !a.HasAuthAttribute() {
-			continue
+// preferProfile picks one of multiple profiles that matched a host if the
+// choice doesn't matter for authentication. That is the case for the DEFAULT
+// profile, which is documented as the fallback, and for profiles that all have
+// the same credentials. It returns nil if the profiles are ambiguous.
+func (l profileFromHostLoader) preferProfile(
+	ctx context.Context,
+	configFile *config.File,
+	host string,
+	profileNames []string,
+) *ini.Section {
+	if slices.Contains(profileNames, ini.DefaultSection) {
+		log.Debugf(ctx, "Multiple profiles matched host %s, using the %s profile: %v", host, ini.DefaultSection, profileNames)
+		return configFile.Section(ini.DefaultSection)
+	}
+
+	credentials := credentialValues(configFile.Section(profileNames[0]))
+	for _, name := range profileNames[1:] {
+		if !maps.Equal(credentials, credentialValues(configFile.Section(name))) {
+			return nil
 		}
-		if !a.IsZero(cfg) {
-			return true
+	}
+	log.Debugf(ctx, "Multiple profiles matched host %s with identical credentials, using %s: %v", host, profileNames[0], profileNames)
+	return configFile.Section(profileNames[0])
+}
+
+// credentialValues returns the auth type and the credentials set in section.
+func credentialValues(section *ini.Section) map[string]string {
+	values := map[string]string{}
+	for _, key := range append(AuthCredentialKeys(), "auth_type") {
+		if section.HasKey(key) {
+			values[key] = section.Key(key).Value()
 		}
 	}
+	return values
+}
+
+func (l profileFromHostLoader) isAnyAuthConfigured(cfg *config.Config) bool {
+	// If any of the auth-specific attributes are set, we can skip profile resolution.
+	if hasAuthAttribute(cfg) {
+		return true
+	}
 	// If the auth type is set, we can skip profile resolution.
 	// For example, to force "azure-cli", only the host and the auth type will be set.
 	return cfg.AuthType != ""
 }
+
+// hasAuthAttribute reports whether any of the auth-specific attributes of cfg
+// are set. Unlike isAnyAuthConfigured, it ignores the auth type.
+func hasAuthAttribute(cfg *config.Config) bool {
+	for _, a := range config.ConfigAttributes {
+		if a.HasAuthAttribute() && !a.IsZero(cfg) {
+			return true
+		}
+	}
+	return false
+}
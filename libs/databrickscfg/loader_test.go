@@ -2,6 +2,8 @@ package databrickscfg
 
 import (
 	"errors"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/databricks/databricks-sdk-go/config"
@@ -243,3 +245,194 @@ func TestLoaderNoWorkspaceIDMatchFallsThrough(t *testing.T) {
 	require.Error(t, err)
 	assert.ErrorContains(t, err, "multiple profiles matched: spog-ws1, spog-ws2")
 }
+
+func TestTraceProfileFromHost(t *testing.T) {
+	const configFile = "profile/testdata/databrickscfg"
+
+	cases := []struct {
+		name    string
+		cfg     *config.Config
+		want    *HostResolutionTrace
+		wantErr string
+	}{
+		{
+			name: "no host",
+			cfg:  &config.Config{ConfigFile: configFile},
+			want: &HostResolutionTrace{
+				ConfigFile: configFile,
+				Decision:   HostResolutionSkippedNoHost,
+			},
+		},
+		{
+			name: "existing auth",
+			cfg:  &config.Config{ConfigFile: configFile, Host: "https://default", Token: "token"},
+			want: &HostResolutionTrace{
+				ConfigFile: configFile,
+				Decision:   HostResolutionSkippedAuthConfigured,
+			},
+		},
+		{
+			name: "no config file",
+			cfg:  &config.Config{ConfigFile: "idontexist", Host: "https://default"},
+			want: &HostResolutionTrace{
+				ConfigFile: "idontexist",
+				Decision:   HostResolutionNoConfigFile,
+			},
+		},
+		{
+			name: "no match",
+			cfg:  &config.Config{ConfigFile: configFile, Host: "https://noneofthehostsmatch"},
+			want: &HostResolutionTrace{
+				ConfigFile:      configFile,
+				Host:            "https://noneofthehostsmatch",
+				ProfilesScanned: 10,
+				Decision:        HostResolutionNoMatch,
+			},
+		},
+		{
+			name: "single match",
+			cfg:  &config.Config{ConfigFile: configFile, Host: "https://default"},
+			want: &HostResolutionTrace{
+				ConfigFile:      configFile,
+				Host:            "https://default",
+				ProfilesScanned: 10,
+				Matched:         []string{"DEFAULT"},
+				Decision:        HostResolutionSelected,
+				Profile:         "DEFAULT",
+			},
+		},
+		{
+			name: "multiple matches disambiguated by workspace_id",
+			cfg:  &config.Config{ConfigFile: configFile, Host: "https://spog.databricks.com", WorkspaceID: "222"},
+			want: &HostResolutionTrace{
+				ConfigFile:      configFile,
+				Host:            "https://spog.databricks.com",
+				ProfilesScanned: 10,
+				Matched:         []string{"spog-ws1", "spog-ws2"},
+				Decision:        HostResolutionSelected,
+				Profile:         "spog-ws2",
+			},
+		},
+		{
+			name: "multiple matches",
+			cfg:  &config.Config{ConfigFile: configFile, Host: "https://foo/bar"},
+			want: &HostResolutionTrace{
+				ConfigFile:      configFile,
+				Host:            "https://foo",
+				ProfilesScanned: 10,
+				Matched:         []string{"foo1", "foo2"},
+				Decision:        HostResolutionMultipleMatches,
+			},
+			wantErr: "https://foo: multiple profiles matched: foo1, foo2",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			trace, err := TraceProfileFromHost(c.cfg)
+			if c.wantErr != "" {
+				assert.ErrorContains(t, err, c.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, c.want, trace)
+		})
+	}
+}
+
+func writeLoaderConfig(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+	return path
+}
+
+func TestLoaderPrefersDefaultProfile(t *testing.T) {
+	cfg := config.Config{
+		Loaders: []config.Loader{
+			ResolveProfileFromHost,
+		},
+		ConfigFile: writeLoaderConfig(t, `[DEFAULT]
+host = https://foo
+token = default
+
+[other]
+host = https://foo
+token = other
+`),
+		Host: "https://foo",
+	}
+
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "DEFAULT", cfg.Profile)
+	assert.Equal(t, "default", cfg.Token)
+}
+
+func TestLoaderResolvesDuplicatesWithIdenticalCredentials(t *testing.T) {
+	cfg := config.Config{
+		Loaders: []config.Loader{
+			ResolveProfileFromHost,
+		},
+		ConfigFile: writeLoaderConfig(t, `[first]
+host = https://foo
+token = same
+
+[second]
+host = https://foo/
+token = same
+cluster_id = abc
+`),
+		Host: "https://foo",
+	}
+
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "first", cfg.Profile)
+	assert.Equal(t, "same", cfg.Token)
+}
+
+func TestLoaderErrorsOnDuplicatesWithDifferentAuthTypes(t *testing.T) {
+	cfg := config.Config{
+		Loaders: []config.Loader{
+			ResolveProfileFromHost,
+		},
+		ConfigFile: writeLoaderConfig(t, `[first]
+host = https://foo
+auth_type = databricks-cli
+
+[second]
+host = https://foo
+auth_type = azure-cli
+`),
+		Host: "https://foo",
+	}
+
+	err := cfg.EnsureResolved()
+	require.Error(t, err)
+	names, ok := AsMultipleProfiles(err)
+	assert.True(t, ok)
+	assert.Equal(t, []string{"first", "second"}, names)
+}
+
+func TestLoaderExpandsConfigFilePath(t *testing.T) {
+	path := writeLoaderConfig(t, `[foo]
+host = https://foo
+token = foo
+`)
+	// The loader does not receive a context, so it reads the process environment.
+	t.Setenv("DATABRICKS_TEST_CONFIG_DIR", filepath.Dir(path))
+
+	cfg := config.Config{
+		Loaders: []config.Loader{
+			ResolveProfileFromHost,
+		},
+		ConfigFile: "$DATABRICKS_TEST_CONFIG_DIR/.databrickscfg",
+		Host:       "https://foo",
+	}
+
+	err := cfg.EnsureResolved()
+	require.NoError(t, err)
+	assert.Equal(t, "foo", cfg.Profile)
+	assert.Equal(t, "foo", cfg.Token)
+}
@@ -0,0 +1,70 @@
+package databrickscfg
+
+import (
+	"context"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+)
+
+// AllowMultipleAuthEnv disables the check for conflicting credentials. The
+// credentials are then selected by the order of the credentials chain, as
+// they were before the check existed.
+const AllowMultipleAuthEnv = "DATABRICKS_ALLOW_MULTIPLE_AUTH"
+
+// ExclusiveAuthTypes lists the auth types whose credentials cannot be
+// combined, in the order of the credentials chain of the CLI. The credentials
+// of an auth type are present if all of its [CredentialKeysFor] are set.
+var ExclusiveAuthTypes = []string{"pat", "basic", "oauth-m2m"}
+
+// ConfiguredExclusiveAuthTypes returns the auth types of [ExclusiveAuthTypes]
+// whose credentials are all set in cfg, in order.
+func ConfiguredExclusiveAuthTypes(cfg *config.Config) []string {
+	var authTypes []string
+	for _, authType := range ExclusiveAuthTypes {
+		if hasAllAttributes(cfg, CredentialKeysFor(authType)) {
+			authTypes = append(authTypes, authType)
+		}
+	}
+	return authTypes
+}
+
+func hasAllAttributes(cfg *config.Config, names []string) bool {
+	for _, attr := range config.ConfigAttributes {
+		for _, name := range names {
+			if attr.Name == name && attr.IsZero(cfg) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// AllowMultipleAuth is a [config.Loader] that selects the first auth type of
+// [ExclusiveAuthTypes] with credentials if DATABRICKS_ALLOW_MULTIPLE_AUTH is
+// set and the configuration has credentials for several of them and no auth
+// type. It runs after the attributes are loaded, because the SDK rejects such
+// a configuration when it validates it.
+var AllowMultipleAuth = allowMultipleAuthLoader{}
+
+type allowMultipleAuthLoader struct{}
+
+func (l allowMultipleAuthLoader) Name() string {
+	return "allow-multiple-auth"
+}
+
+func (l allowMultipleAuthLoader) Configure(cfg *config.Config) error {
+	ctx := context.Background() //nolint:gocritic // SDK interface does not accept context.
+
+	if allow, _ := env.GetBool(ctx, AllowMultipleAuthEnv); !allow || cfg.AuthType != "" {
+		return nil
+	}
+	authTypes := ConfiguredExclusiveAuthTypes(cfg)
+	if len(authTypes) < 2 {
+		return nil
+	}
+	log.Debugf(ctx, "Using auth type %s of %v, because %s is set", authTypes[0], authTypes, AllowMultipleAuthEnv)
+	cfg.AuthType = authTypes[0]
+	return nil
+}
@@ -0,0 +1,50 @@
+package databrickscfg
+
+import (
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfiguredExclusiveAuthTypes(t *testing.T) {
+	cfg := &config.Config{Token: "dapi123", Username: "user", Password: "password", ClientID: "id"}
+	assert.Equal(t, []string{"pat", "basic"}, ConfiguredExclusiveAuthTypes(cfg))
+	assert.Empty(t, ConfiguredExclusiveAuthTypes(&config.Config{ClientID: "id"}))
+}
+
+func TestAllowMultipleAuthLoader(t *testing.T) {
+	newConfig := func() *config.Config {
+		return &config.Config{
+			Host:         "https://test.cloud.databricks.com",
+			Token:        "dapi123",
+			ClientID:     "id",
+			ClientSecret: "secret",
+			Loaders:      []config.Loader{AllowMultipleAuth},
+		}
+	}
+
+	// Without the environment variable, the SDK rejects the config.
+	cfg := newConfig()
+	require.ErrorContains(t, cfg.EnsureResolved(), "more than one authorization method configured")
+	assert.Empty(t, cfg.AuthType)
+
+	t.Setenv(AllowMultipleAuthEnv, "1")
+	cfg = newConfig()
+	require.NoError(t, cfg.EnsureResolved())
+	assert.Equal(t, "pat", cfg.AuthType)
+
+	// An auth type that is set is kept.
+	cfg = newConfig()
+	cfg.AuthType = "oauth-m2m"
+	require.NoError(t, cfg.EnsureResolved())
+	assert.Equal(t, "oauth-m2m", cfg.AuthType)
+
+	// A single auth type is left to the credentials chain.
+	cfg = newConfig()
+	cfg.ClientID = ""
+	cfg.ClientSecret = ""
+	require.NoError(t, cfg.EnsureResolved())
+	assert.Empty(t, cfg.AuthType)
+}
@@ -1,14 +1,19 @@
 package databrickscfg
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
+	"slices"
 	"strings"
 
+	"github.com/databricks/cli/libs/cmdio"
 	"github.com/databricks/cli/libs/env"
+	"github.com/databricks/cli/libs/fileutil"
 	"github.com/databricks/cli/libs/log"
 	"github.com/databricks/databricks-sdk-go/config"
 	"gopkg.in/ini.v1"
@@ -19,10 +24,37 @@ const fileMode = 0o600
 const defaultComment = "The profile defined in the DEFAULT section is to be used as a fallback when no profile is explicitly specified."
 
 const (
-	databricksSettingsSection = "__settings__"
-	defaultProfileKey         = "default_profile"
+	// originalBackupSuffix is the suffix of the backup of a config file before
+	// it was first modified. latestBackupSuffix is the suffix of the backup
+	// before the most recent modification.
+	originalBackupSuffix = ".bak"
+	latestBackupSuffix   = ".latest.bak"
+
+	// noBackupEnv disables backups of config files before they are modified.
+	noBackupEnv = "DATABRICKS_CONFIG_NO_BACKUP"
 )
 
+const (
+	// SettingsSection is the section that holds CLI settings rather than a
+	// profile.
+	SettingsSection = "__settings__"
+
+	defaultProfileKey = "default_profile"
+)
+
+// workspaceScopedKeys are the keys of a profile that refer to resources in
+// the workspace the profile points at. They are stale once the host of the
+// profile changes.
+var workspaceScopedKeys = []string{
+	"cluster_id",
+	"serverless_compute_id",
+	"warehouse_id",
+	"workspace_id",
+
+	// auth.PinnedWorkspaceIDKey; libs/auth imports this package.
+	"pinned_workspace_id",
+}
+
 // GetConfiguredDefaultProfile returns the explicitly configured default profile
 // by loading the config file at configFilePath.
 // Returns "" if the file doesn't exist or default_profile is not set.
@@ -41,8 +73,8 @@ func GetConfiguredDefaultProfile(ctx context.Context, configFilePath string) (st
 // [__settings__].default_profile, or "" when it is not set or when the value
 // is the reserved __settings__ section name itself.
 func GetConfiguredDefaultProfileFrom(configFile *config.File) string {
-	v := configFile.Section(databricksSettingsSection).Key(defaultProfileKey).String()
-	if v == databricksSettingsSection {
+	v := configFile.Section(SettingsSection).Key(defaultProfileKey).String()
+	if v == SettingsSection {
 		return ""
 	}
 	return v
@@ -79,19 +111,52 @@ func loadConfigFile(ctx context.Context, filename string) (*config.File, error)
 	return configFile, nil
 }
 
-// resolveConfigFilePath defaults to ~/.databrickscfg and expands ~ to the home directory.
+// GetProfileHost returns the host of the named profile in the config file at
+// configFilePath. It returns an empty string if the file or the profile
+// doesn't exist, or if the profile has no host.
+func GetProfileHost(ctx context.Context, profileName, configFilePath string) (string, error) {
+	configFile, err := loadConfigFile(ctx, configFilePath)
+	if err != nil || configFile == nil {
+		return "", err
+	}
+	section, err := configFile.GetSection(profileName)
+	if err != nil {
+		return "", nil
+	}
+	key, err := section.GetKey("host")
+	if err != nil {
+		return "", nil
+	}
+	return expandValue(ctx, section.Name(), key.Name(), key.Value())
+}
+
+// GetProfileValue returns the value of key in the named profile of the config
+// file at configFilePath, including the profiles of the files it includes. It
+// returns an empty string if the profile or the key doesn't exist, and an
+// error if the config file cannot be read.
+func GetProfileValue(ctx context.Context, profileName, configFilePath, key string) (string, error) {
+	path, err := resolveConfigFilePath(ctx, configFilePath)
+	if err != nil {
+		return "", err
+	}
+	file, err := LoadFile(ctx, path)
+	if err != nil {
+		return "", fmt.Errorf("cannot read config file: %w", err)
+	}
+	section, err := file.GetSection(profileName)
+	if err != nil {
+		return "", nil
+	}
+	return section.Key(key).String(), nil
+}
+
+// resolveConfigFilePath defaults to ~/.databrickscfg and expands ~ and
+// environment variables with [ExpandConfigFilePath].
 func resolveConfigFilePath(ctx context.Context, filename string) (string, error) {
 	if filename == "" {
 		filename = "~/.databrickscfg"
 	}
-	if strings.HasPrefix(filename, "~") {
-		homedir, err := env.UserHomeDir(ctx)
-		if err != nil {
-			return "", fmt.Errorf("cannot find homedir: %w", err)
-		}
-		filename = fmt.Sprintf("%s%s", homedir, filename[1:])
-	}
-	return filename, nil
+	return ExpandConfigFilePath(ctx, filename)
 }
 
 // GetDefaultProfileFrom returns the name of the default profile from an
@@ -111,7 +176,7 @@ func GetDefaultProfileFrom(configFile *config.File) string {
 	var profileNames []string
 	hasDefault := false
 	for _, s := range configFile.Sections() {
-		if s.Name() == databricksSettingsSection {
+		if s.Name() == SettingsSection {
 			continue
 		}
 		if !s.HasKey("host") {
@@ -139,7 +204,7 @@ func GetDefaultProfileFrom(configFile *config.File) string {
 // isFirstProfileInFile returns true if the config file has no profiles (sections with a "host" key) yet.
 func isFirstProfileInFile(configFile *config.File) bool {
 	for _, s := range configFile.Sections() {
-		if s.Name() == databricksSettingsSection {
+		if s.Name() == SettingsSection {
 			continue
 		}
 		if s.HasKey("host") {
@@ -151,8 +216,8 @@ func isFirstProfileInFile(configFile *config.File) bool {
 
 // SetDefaultProfile writes the default_profile key to the [__settings__] section.
 func SetDefaultProfile(ctx context.Context, profileName, configFilePath string) error {
-	if profileName == databricksSettingsSection {
-		return fmt.Errorf("profile name %q is reserved for internal use", databricksSettingsSection)
+	if profileName == SettingsSection {
+		return fmt.Errorf("profile name %q is reserved for internal use", SettingsSection)
 	}
 
 	configFile, err := loadOrCreateConfigFile(ctx, configFilePath)
@@ -160,12 +225,12 @@ func SetDefaultProfile(ctx context.Context, profileName, configFilePath string)
 		return err
 	}
 
-	section, err := configFile.GetSection(databricksSettingsSection)
+	section, err := configFile.GetSection(SettingsSection)
 	if err != nil {
 		// Section doesn't exist, create it.
-		section, err = configFile.NewSection(databricksSettingsSection)
+		section, err = configFile.NewSection(SettingsSection)
 		if err != nil {
-			return fmt.Errorf("cannot create %s section: %w", databricksSettingsSection, err)
+			return fmt.Errorf("cannot create %s section: %w", SettingsSection, err)
 		}
 	}
 
@@ -190,7 +255,7 @@ func ClearDefaultProfile(ctx context.Context, profileName, configFilePath string
 		return nil
 	}
 
-	section, err := configFile.GetSection(databricksSettingsSection)
+	section, err := configFile.GetSection(SettingsSection)
 	if err != nil {
 		// No settings section means no default to clear.
 		return nil
@@ -228,6 +293,13 @@ func matchOrCreateSection(ctx context.Context, configFile *config.File, cfg *con
 			return cfg.Profile == s.Name()
 		}
 		raw := s.KeysHash()
+		for key, value := range raw {
+			// Match on the expanded value, but don't fail on unset variables:
+			// the value is only used to find the profile.
+			if expanded, err := expandValue(ctx, s.Name(), key, value); err == nil {
+				raw[key] = expanded
+			}
+		}
 		if cfg.AccountID != "" {
 			// here we rely on map zerovals for matching with accounts:
 			// if profile has no account id, the raw["account_id"] will be empty
@@ -242,7 +314,7 @@ func matchOrCreateSection(ctx context.Context, configFile *config.File, cfg *con
 			return false
 		}
 		// Check if this section matches the normalized host
-		return normalizeHost(host) == normalizeHost(cfg.Host)
+		return NormalizeHost(host) == NormalizeHost(cfg.Host)
 	})
 	if err == errNoMatchingProfiles {
 		section, err = configFile.NewSection(cfg.Profile)
@@ -255,6 +327,72 @@ func matchOrCreateSection(ctx context.Context, configFile *config.File, cfg *con
 	return section, nil
 }
 
+type hostChangeConfirmedKey struct{}
+
+// WithHostChangeConfirmed returns a context in which [SaveToProfile] doesn't
+// ask to confirm that an existing profile is pointed at a different host,
+// because the caller already asked.
+func WithHostChangeConfirmed(ctx context.Context) context.Context {
+	return context.WithValue(ctx, hostChangeConfirmedKey{}, true)
+}
+
+func isHostChangeConfirmed(ctx context.Context) bool {
+	confirmed, _ := ctx.Value(hostChangeConfirmedKey{}).(bool)
+	return confirmed
+}
+
+// confirmHostChange checks whether saving host to the existing profile in
+// section points the profile at a different host. If it does, it returns the
+// workspace-scoped keys set in the profile, which must be cleared because
+// they refer to resources in the old workspace. Interactive sessions are
+// asked to confirm the change first; otherwise a warning is logged.
+func confirmHostChange(ctx context.Context, section *ini.Section, host string) ([]string, error) {
+	key, err := section.GetKey("host")
+	if err != nil || host == "" {
+		return nil, nil
+	}
+	oldHost := key.Value()
+	if expanded, err := expandValue(ctx, section.Name(), key.Name(), oldHost); err == nil {
+		oldHost = expanded
+	}
+	if oldHost == "" || NormalizeHost(oldHost) == NormalizeHost(host) {
+		return nil, nil
+	}
+
+	var staleKeys []string
+	for _, k := range workspaceScopedKeys {
+		if section.HasKey(k) {
+			staleKeys = append(staleKeys, k)
+		}
+	}
+
+	if isHostChangeConfirmed(ctx) {
+		return staleKeys, nil
+	}
+
+	if cmdio.IsPromptSupported(ctx) {
+		question := fmt.Sprintf("Profile %s currently points at %s; update to %s", section.Name(), oldHost, host)
+		if len(staleKeys) > 0 {
+			question += fmt.Sprintf(" and clear workspace-specific keys (%s)", strings.Join(staleKeys, ", "))
+		}
+		approved, err := cmdio.AskYesOrNo(ctx, question+"?")
+		if err != nil {
+			return nil, err
+		}
+		if !approved {
+			return nil, fmt.Errorf("profile %q was not updated: it still points at %s", section.Name(), oldHost)
+		}
+		return staleKeys, nil
+	}
+
+	if len(staleKeys) > 0 {
+		log.Warnf(ctx, "Profile %s currently points at %s; updating to %s and clearing workspace-specific keys (%s)", section.Name(), oldHost, host, strings.Join(staleKeys, ", "))
+	} else {
+		log.Warnf(ctx, "Profile %s currently points at %s; updating to %s", section.Name(), oldHost, host)
+	}
+	return staleKeys, nil
+}
+
 // AuthCredentialKeys returns the config file key names for all auth credential
 // fields from the SDK's ConfigAttributes. These are fields annotated with an
 // auth type (e.g. pat, basic, oauth, azure, google). Use this to clear stale
@@ -269,32 +407,132 @@ func AuthCredentialKeys() []string {
 	return keys
 }
 
+// authSettingKeys lists keys that select or tune authentication without
+// belonging to a single auth type. The SDK tags them as internal, so they
+// cannot be derived from ConfigAttributes.
+var authSettingKeys = []string{"auth_type", "scopes"}
+
+// CredentialKeysFor returns the config file key names of the fields that the
+// SDK uses for authType, as declared by the auth and auth_types tags of
+// ConfigAttributes. Fields that are tagged with a family of auth types, such
+// as azure, belong to every auth type of that family.
+func CredentialKeysFor(authType string) []string {
+	var keys []string
+	for _, attr := range config.ConfigAttributes {
+		for _, t := range attr.AuthTypes {
+			if t == authType || strings.HasPrefix(authType, t+"-") {
+				keys = append(keys, attr.Name)
+				break
+			}
+		}
+	}
+	return keys
+}
+
+// AllAuthRelatedKeys returns the config file key names of all fields that
+// hold credentials or select or tune an auth type. A profile without any of
+// these keys only determines where to connect to.
+func AllAuthRelatedKeys() []string {
+	var keys []string
+	for _, attr := range config.ConfigAttributes {
+		if attr.HasAuthAttribute() || len(attr.AuthTypes) > 0 || slices.Contains(authSettingKeys, attr.Name) {
+			keys = append(keys, attr.Name)
+		}
+	}
+	return keys
+}
+
+// OtherCredentialKeys returns the credential keys of all auth types except
+// authType. Use this to clear stale credentials when saving a profile for
+// authType, so that the profile doesn't configure multiple auth types.
+func OtherCredentialKeys(authType string) []string {
+	own := CredentialKeysFor(authType)
+	return slices.DeleteFunc(AuthCredentialKeys(), func(key string) bool {
+		return slices.Contains(own, key)
+	})
+}
+
+// writeConfigFile saves configFile. Existing files are edited in place so
+// that comments, blank lines and the order of sections are preserved. The file
+// is not written if its contents don't change.
 func writeConfigFile(ctx context.Context, configFile *config.File) error {
-	section := configFile.Section(ini.DefaultSection)
-	if len(section.Keys()) == 0 && section.Comment == "" {
-		section.Comment = defaultComment
+	return writeRenamedConfigFile(ctx, configFile, nil)
+}
+
+// writeRenamedConfigFile is like [writeConfigFile], but first renames the
+// sections of the existing file according to renames, which maps old section
+// names to new ones. Renamed sections keep their position and comments.
+func writeRenamedConfigFile(ctx context.Context, configFile *config.File, renames map[string]string) error {
+	path := configFile.Path()
+	orig, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Warnf(ctx, "Failed to read %s: %v. Proceeding to save", path, err)
+	}
+
+	// The file is written without byte order mark, and with the line ending
+	// that most of its lines use.
+	text := StripBOM(orig)
+	var data []byte
+	ok := false
+	if len(bytes.TrimSpace(text)) > 0 {
+		data, ok = patchConfigFile(renameSections(text, renames), configFile)
+		if !ok {
+			log.Debugf(ctx, "Cannot edit %s in place, rewriting it", path)
+		}
 	}
-	if err := backupConfigFile(ctx, configFile); err != nil {
+	if !ok {
+		section := configFile.Section(ini.DefaultSection)
+		if len(section.Keys()) == 0 && section.Comment == "" {
+			section.Comment = defaultComment
+		}
+		var buf bytes.Buffer
+		if _, err := configFile.WriteTo(&buf); err != nil {
+			return fmt.Errorf("cannot write %s: %w", path, err)
+		}
+		data = withLineEnding(buf.Bytes(), lineEnding(text))
+	}
+
+	if bytes.Equal(data, text) {
+		log.Debugf(ctx, "%s is unchanged", path)
+		return nil
+	}
+	if err := backupConfigFile(ctx, path, orig); err != nil {
 		return err
 	}
-	return configFile.SaveTo(configFile.Path())
+	return fileutil.WriteFile(path, fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
 }
 
-func backupConfigFile(ctx context.Context, configFile *config.File) error {
-	orig, backupErr := os.ReadFile(configFile.Path())
-	if len(orig) > 0 && backupErr == nil {
-		log.Infof(ctx, "Backing up in %s.bak", configFile.Path())
-		err := os.WriteFile(configFile.Path()+".bak", orig, fileMode)
-		if err != nil {
-			return fmt.Errorf("backup: %w", err)
-		}
-		log.Infof(ctx, "Overwriting %s", configFile.Path())
-	} else if backupErr != nil {
-		log.Warnf(ctx, "Failed to backup %s: %v. Proceeding to save",
-			configFile.Path(), backupErr)
-	} else {
-		log.Infof(ctx, "Saving %s", configFile.Path())
+// backupConfigFile saves orig, the contents of the config file at path before
+// it is modified. The first backup is written to path+".bak" and is never
+// overwritten, so that the file as it was before the CLI first modified it can
+// always be restored. Later backups overwrite path+".latest.bak".
+//
+// Set DATABRICKS_CONFIG_NO_BACKUP to skip the backup.
+func backupConfigFile(ctx context.Context, path string, orig []byte) error {
+	if len(orig) == 0 {
+		log.Infof(ctx, "Saving %s", path)
+		return nil
 	}
+	if skip, _ := env.GetBool(ctx, noBackupEnv); skip {
+		log.Infof(ctx, "Overwriting %s without backup", path)
+		return nil
+	}
+
+	bak := path + originalBackupSuffix
+	_, err := os.Stat(bak)
+	if err == nil {
+		bak = path + latestBackupSuffix
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("backup: %w", err)
+	}
+	log.Infof(ctx, "Backing up in %s", bak)
+	if err := os.WriteFile(bak, orig, fileMode); err != nil {
+		return fmt.Errorf("backup: %w", err)
+	}
+	log.Infof(ctx, "Overwriting %s", path)
 	return nil
 }
 
@@ -304,8 +542,8 @@ func backupConfigFile(ctx context.Context, configFile *config.File) error {
 // removed (use this for mutually exclusive fields like cluster_id vs
 // serverless_compute_id, or to drop stale auth credentials on auth-type switch).
 func SaveToProfile(ctx context.Context, cfg *config.Config, clearKeys ...string) error {
-	if cfg.Profile == databricksSettingsSection {
-		return fmt.Errorf("profile name %q is reserved for internal use", databricksSettingsSection)
+	if cfg.Profile == SettingsSection {
+		return fmt.Errorf("profile name %q is reserved for internal use", SettingsSection)
 	}
 
 	configFile, err := loadOrCreateConfigFile(ctx, cfg.ConfigFile)
@@ -322,6 +560,14 @@ func SaveToProfile(ctx context.Context, cfg *config.Config, clearKeys ...string)
 		return err
 	}
 
+	if profileName != "" {
+		staleKeys, err := confirmHostChange(ctx, section, cfg.Host)
+		if err != nil {
+			return err
+		}
+		clearKeys = append(clearKeys, staleKeys...)
+	}
+
 	// zeroval profile name before adding it to a section
 	cfg.Profile = ""
 	cfg.ConfigFile = ""
@@ -333,27 +579,68 @@ func SaveToProfile(ctx context.Context, cfg *config.Config, clearKeys ...string)
 
 	// Write non-zero fields from the new config. Iterates ConfigAttributes
 	// in declaration order for deterministic key ordering on new profiles.
+	writesSecrets := false
 	for _, attr := range config.ConfigAttributes {
 		if attr.IsZero(cfg) {
 			continue
 		}
+		writesSecrets = writesSecrets || attr.Sensitive
 		key := section.Key(attr.Name)
-		key.SetValue(attr.GetString(cfg))
+		value := attr.GetString(cfg)
+		// Keep references to environment variables that expand to the value.
+		if envReference.MatchString(key.Value()) {
+			expanded, err := expandValue(ctx, section.Name(), attr.Name, key.Value())
+			if err == nil && expanded == value {
+				continue
+			}
+		}
+		key.SetValue(value)
 	}
 
 	// Auto-set default profile when saving the first profile to the config file.
 	if firstProfile && profileName != "" {
-		settingsSection := configFile.Section(databricksSettingsSection)
+		settingsSection := configFile.Section(SettingsSection)
 		settingsSection.Key(defaultProfileKey).SetValue(profileName)
 		log.Debugf(ctx, "Auto-setting default profile to %q (first profile)", profileName)
 	}
 
+	if err := writeConfigFile(ctx, configFile); err != nil {
+		return err
+	}
+	if writesSecrets {
+		WarnInsecurePermissions(ctx, configFile.Path())
+	}
+	return nil
+}
+
+// SetProfileKey sets a single key in an existing profile. Use this for keys
+// that are not part of the SDK configuration and can therefore not be written
+// through SaveToProfile.
+func SetProfileKey(ctx context.Context, configFilePath, profileName, key, value string) error {
+	configFile, err := loadConfigFile(ctx, configFilePath)
+	if err != nil {
+		return err
+	}
+	if configFile == nil {
+		return fmt.Errorf("profile %q not found: config file does not exist", profileName)
+	}
+
+	section, err := configFile.GetSection(profileName)
+	if err != nil {
+		return fmt.Errorf("profile %q not found: %w", profileName, err)
+	}
+
+	section.Key(key).SetValue(value)
 	return writeConfigFile(ctx, configFile)
 }
 
 // DeleteProfile removes the named profile section from the databrickscfg file.
 // It creates a backup of the original file before modifying it.
 func DeleteProfile(ctx context.Context, profileName, configFilePath string) error {
+	configFilePath, err := resolveConfigFilePath(ctx, configFilePath)
+	if err != nil {
+		return err
+	}
 	configFile, err := config.LoadFile(configFilePath)
 	if err != nil {
 		return fmt.Errorf("cannot load config file %s: %w", configFilePath, err)
@@ -380,14 +667,122 @@ func DeleteProfile(ctx context.Context, profileName, configFilePath string) erro
 	return writeConfigFile(ctx, configFile)
 }
 
-func ValidateConfigAndProfileHost(cfg *config.Config, profile string) error {
-	configFile, err := config.LoadFile(cfg.ConfigFile)
+// CommentOutProfile comments out the lines of the named profile in the
+// databrickscfg file, so that the profile is no longer used but can be
+// restored by hand. It creates a backup of the original file before modifying
+// it.
+func CommentOutProfile(ctx context.Context, profileName, configFilePath string) error {
+	configFilePath, err := resolveConfigFilePath(ctx, configFilePath)
+	if err != nil {
+		return err
+	}
+	orig, err := os.ReadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("cannot read config file %s: %w", configFilePath, err)
+	}
+	configFile, err := config.LoadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("cannot load config file %s: %w", configFilePath, err)
+	}
+	if _, err := configFile.SectionsByName(profileName); err != nil {
+		return fmt.Errorf("profile %q not found: %w", profileName, err)
+	}
+
+	data, ok := commentOutSection(StripBOM(orig), profileName)
+	if !ok {
+		return fmt.Errorf("cannot comment out profile %q: the config file has values that span multiple lines", profileName)
+	}
+	if err := backupConfigFile(ctx, configFilePath, orig); err != nil {
+		return err
+	}
+	return fileutil.WriteFile(configFilePath, fileMode, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// RenameProfile renames the profile oldName to newName. The section of the
+// profile keeps its position and comments in the databrickscfg file. If
+// oldName is the configured default profile, the default is renamed as well.
+//
+// The DEFAULT section stays at the top of the file: renaming it moves its keys
+// to a new section, and a profile can only be renamed to DEFAULT if the DEFAULT
+// section has no keys.
+func RenameProfile(ctx context.Context, oldName, newName, configFilePath string) error {
+	for _, name := range []string{oldName, newName} {
+		if name == SettingsSection {
+			return fmt.Errorf("profile name %q is reserved for internal use", SettingsSection)
+		}
+	}
+	if newName == "" || strings.TrimSpace(newName) != newName || strings.ContainsAny(newName, "[]\r\n") {
+		return fmt.Errorf("invalid profile name %q", newName)
+	}
+
+	configFilePath, err := resolveConfigFilePath(ctx, configFilePath)
+	if err != nil {
+		return err
+	}
+	configFile, err := config.LoadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("cannot load config file %s: %w", configFilePath, err)
+	}
+
+	oldSection, err := configFile.GetSection(oldName)
+	if err != nil || (oldName == ini.DefaultSection && len(oldSection.Keys()) == 0) {
+		return fmt.Errorf("profile %q not found", oldName)
+	}
+	if section, err := configFile.GetSection(newName); err == nil {
+		if newName != ini.DefaultSection || len(section.Keys()) > 0 {
+			return fmt.Errorf("profile %q already exists", newName)
+		}
+	}
+
+	newSection, err := configFile.NewSection(newName)
+	if err != nil {
+		return fmt.Errorf("cannot create profile %q: %w", newName, err)
+	}
+	for _, key := range oldSection.Keys() {
+		newSection.Key(key.Name()).SetValue(key.Value())
+	}
+
+	var renames map[string]string
+	if oldName == ini.DefaultSection {
+		for _, key := range oldSection.Keys() {
+			oldSection.DeleteKey(key.Name())
+		}
+	} else {
+		newSection.Comment = oldSection.Comment
+		configFile.DeleteSection(oldName)
+		if newName != ini.DefaultSection {
+			renames = map[string]string{oldName: newName}
+		}
+	}
+
+	// Look up the settings without creating them as a side effect.
+	if settings, err := configFile.GetSection(SettingsSection); err == nil && settings.KeysHash()[defaultProfileKey] == oldName {
+		settings.Key(defaultProfileKey).SetValue(newName)
+	}
+
+	return writeRenamedConfigFile(ctx, configFile, renames)
+}
+
+// ValidateConfigAndProfileHost returns an error if the host of the named
+// profile doesn't match the host of cfg. Profiles of included files are
+// considered as well.
+func ValidateConfigAndProfileHost(ctx context.Context, cfg *config.Config, profile string) error {
+	path, err := resolveConfigFilePath(ctx, cfg.ConfigFile)
+	if err != nil {
+		return err
+	}
+	file, err := LoadFile(ctx, path)
 	if err != nil {
 		return fmt.Errorf("cannot parse config file: %w", err)
 	}
 
+	configFile := file.File
+
 	// Normalized version of the configured host.
-	host := normalizeHost(cfg.Host)
+	host := NormalizeHost(cfg.Host)
 	match, err := findMatchingProfile(configFile, func(s *ini.Section) bool {
 		return profile == s.Name()
 	})
@@ -395,11 +790,11 @@ func ValidateConfigAndProfileHost(cfg *config.Config, profile string) error {
 		return err
 	}
 
-	hostFromProfile := normalizeHost(match.Key("host").Value())
+	hostFromProfile := NormalizeHost(match.Key("host").Value())
 	if hostFromProfile != "" && host != "" && hostFromProfile != host {
 		// Try to find if there's a profile which uses the same host as the bundle and suggest in error message
 		match, err = findMatchingProfile(configFile, func(s *ini.Section) bool {
-			return normalizeHost(s.Key("host").Value()) == host
+			return NormalizeHost(s.Key("host").Value()) == host
 		})
 		if err == nil && match != nil {
 			profileName := match.Name()
@@ -1,10 +1,15 @@
 package databrickscfg
 
 import (
+	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/databricks/cli/libs/cmdio"
+	"github.com/databricks/cli/libs/env"
 	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -159,6 +164,32 @@ default_profile = abc
 `, string(contents))
 }
 
+func TestSaveToProfile_ExpandsConfigFilePath(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+	ctx := env.WithUserHomeDir(t.Context(), dir)
+	ctx = env.Set(ctx, "CONFIG_DIR", dir)
+
+	for _, configFile := range []string{"~/tilde.databrickscfg", "$CONFIG_DIR/unix.databrickscfg", "%CONFIG_DIR%/windows.databrickscfg"} {
+		err := SaveToProfile(ctx, &config.Config{
+			ConfigFile: configFile,
+			Profile:    "abc",
+			Host:       "https://foo",
+			Token:      "xyz",
+		})
+		require.NoError(t, err)
+	}
+
+	assert.FileExists(t, filepath.Join(dir, "tilde.databrickscfg"))
+	assert.FileExists(t, filepath.Join(dir, "unix.databrickscfg"))
+	assert.FileExists(t, filepath.Join(dir, "windows.databrickscfg"))
+
+	// No file or directory is created with the unexpanded name.
+	entries, err := os.ReadDir(".")
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
 func TestSaveToProfile_NewFileWithDefault(t *testing.T) {
 	ctx := t.Context()
 	path := filepath.Join(t.TempDir(), "databrickscfg")
@@ -452,6 +483,19 @@ func TestSaveToProfile_MergeSemantics(t *testing.T) {
 				"serverless_compute_id": "auto",
 			},
 		},
+		{
+			name:    "serverless clears cluster",
+			profile: "abc",
+			saves: []saveOp{
+				{cfg: &config.Config{Profile: "abc", Host: "https://foo", Token: "xyz", ClusterID: "cluster-123"}},
+				{cfg: &config.Config{Profile: "abc", Host: "https://foo", Token: "xyz", ServerlessComputeID: "auto"}, clearKeys: []string{"cluster_id"}},
+			},
+			wantKeys: map[string]string{
+				"host":                  "https://foo",
+				"token":                 "xyz",
+				"serverless_compute_id": "auto",
+			},
+		},
 		{
 			name:    "overwrites existing values",
 			profile: "abc",
@@ -463,6 +507,57 @@ func TestSaveToProfile_MergeSemantics(t *testing.T) {
 				"host": "https://new-host",
 			},
 		},
+		{
+			name:    "host change clears workspace-scoped keys",
+			profile: "abc",
+			saves: []saveOp{
+				{cfg: &config.Config{Profile: "abc", Host: "https://old-host", Token: "xyz", ClusterID: "cluster-123", WarehouseID: "warehouse-123", WorkspaceID: "123"}},
+				{cfg: &config.Config{Profile: "abc", Host: "https://new-host"}},
+			},
+			wantKeys: map[string]string{
+				"host":  "https://new-host",
+				"token": "xyz",
+			},
+		},
+		{
+			name:    "host change keeps workspace-scoped keys that are saved",
+			profile: "abc",
+			saves: []saveOp{
+				{cfg: &config.Config{Profile: "abc", Host: "https://old-host", ClusterID: "cluster-123", ServerlessComputeID: "auto"}},
+				{cfg: &config.Config{Profile: "abc", Host: "https://new-host", ClusterID: "cluster-456"}},
+			},
+			wantKeys: map[string]string{
+				"host":       "https://new-host",
+				"cluster_id": "cluster-456",
+			},
+		},
+		{
+			name:    "same host keeps workspace-scoped keys",
+			profile: "abc",
+			saves: []saveOp{
+				{cfg: &config.Config{Profile: "abc", Host: "https://foo", ClusterID: "cluster-123", WarehouseID: "warehouse-123"}},
+				{cfg: &config.Config{Profile: "abc", Host: "https://foo/?o=123", AuthType: "databricks-cli"}},
+			},
+			wantKeys: map[string]string{
+				"host":         "https://foo/?o=123",
+				"auth_type":    "databricks-cli",
+				"cluster_id":   "cluster-123",
+				"warehouse_id": "warehouse-123",
+			},
+		},
+		{
+			name:    "saving without host keeps workspace-scoped keys",
+			profile: "abc",
+			saves: []saveOp{
+				{cfg: &config.Config{Profile: "abc", Host: "https://foo", ClusterID: "cluster-123"}},
+				{cfg: &config.Config{Profile: "abc", Token: "xyz"}},
+			},
+			wantKeys: map[string]string{
+				"host":       "https://foo",
+				"token":      "xyz",
+				"cluster_id": "cluster-123",
+			},
+		},
 		{
 			name:    "clear nonexistent key is noop",
 			profile: "abc",
@@ -491,7 +586,7 @@ func TestSaveToProfile_MergeSemantics(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ctx := t.Context()
+			ctx := cmdio.MockDiscard(t.Context())
 			path := filepath.Join(t.TempDir(), "databrickscfg")
 
 			for _, save := range tc.saves {
@@ -515,6 +610,157 @@ func TestSaveToProfile_MergeSemantics(t *testing.T) {
 	}
 }
 
+func TestSaveToProfile_HostChangePrompt(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		answer   string
+		wantErr  string
+		wantKeys map[string]string
+	}{
+		{
+			name:   "approved",
+			answer: "y",
+			wantKeys: map[string]string{
+				"host":  "https://new-host",
+				"token": "xyz",
+			},
+		},
+		{
+			name:    "declined",
+			answer:  "n",
+			wantErr: `profile "abc" was not updated: it still points at https://old-host`,
+			wantKeys: map[string]string{
+				"host":       "https://old-host",
+				"token":      "xyz",
+				"cluster_id": "cluster-123",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "databrickscfg")
+			err := SaveToProfile(cmdio.MockDiscard(t.Context()), &config.Config{
+				Profile:    "abc",
+				Host:       "https://old-host",
+				Token:      "xyz",
+				ClusterID:  "cluster-123",
+				ConfigFile: path,
+			})
+			require.NoError(t, err)
+
+			ctx, cancel := context.WithTimeout(t.Context(), 3*time.Second)
+			defer cancel()
+			ctx, testIO := cmdio.SetupTest(ctx, cmdio.TestOptions{PromptSupported: true})
+			defer testIO.Done()
+
+			prompt := make(chan string)
+			go func() {
+				line, _ := testIO.Stderr.ReadString('?')
+				prompt <- line
+				_, _ = testIO.Stderr.WriteTo(io.Discard)
+			}()
+
+			errc := make(chan error)
+			go func() {
+				errc <- SaveToProfile(ctx, &config.Config{
+					Profile:    "abc",
+					Host:       "https://new-host",
+					ConfigFile: path,
+				})
+			}()
+
+			assert.Equal(t, "Profile abc currently points at https://old-host; update to https://new-host and clear workspace-specific keys (cluster_id)?", <-prompt)
+			_, err = testIO.Stdin.WriteString(tc.answer + "\n")
+			require.NoError(t, err)
+			require.NoError(t, testIO.Stdin.Flush())
+
+			err = <-errc
+			if tc.wantErr != "" {
+				assert.EqualError(t, err, tc.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			file, err := loadOrCreateConfigFile(t.Context(), path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.wantKeys, file.Section("abc").KeysHash())
+		})
+	}
+}
+
+func TestSaveToProfile_HostChangeConfirmed(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "databrickscfg")
+	err := SaveToProfile(cmdio.MockDiscard(t.Context()), &config.Config{
+		Profile:    "abc",
+		Host:       "https://old-host",
+		ClusterID:  "cluster-123",
+		ConfigFile: path,
+	})
+	require.NoError(t, err)
+
+	// The caller already confirmed the change, so there is no prompt to answer.
+	ctx, cancel := context.WithTimeout(t.Context(), 3*time.Second)
+	defer cancel()
+	ctx, testIO := cmdio.SetupTest(ctx, cmdio.TestOptions{PromptSupported: true})
+	defer testIO.Done()
+
+	err = SaveToProfile(WithHostChangeConfirmed(ctx), &config.Config{
+		Profile:    "abc",
+		Host:       "https://new-host",
+		ConfigFile: path,
+	})
+	require.NoError(t, err)
+
+	file, err := loadOrCreateConfigFile(t.Context(), path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "https://new-host"}, file.Section("abc").KeysHash())
+}
+
+func TestSaveToProfile_HostChangeClearsPinnedWorkspace(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "databrickscfg")
+	ctx := cmdio.MockDiscard(t.Context())
+	err := SaveToProfile(ctx, &config.Config{
+		Profile:    "abc",
+		Host:       "https://old-host",
+		ConfigFile: path,
+	})
+	require.NoError(t, err)
+	require.NoError(t, SetProfileKey(ctx, path, "abc", "pinned_workspace_id", "123"))
+
+	err = SaveToProfile(WithHostChangeConfirmed(ctx), &config.Config{
+		Profile:    "abc",
+		Host:       "https://new-host",
+		ConfigFile: path,
+	})
+	require.NoError(t, err)
+
+	file, err := loadOrCreateConfigFile(t.Context(), path)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"host": "https://new-host"}, file.Section("abc").KeysHash())
+}
+
+func TestGetProfileHost(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "databrickscfg")
+	err := os.WriteFile(path, []byte("[abc]\nhost = https://${env:HOST_SUFFIX}\n\n[def]\ntoken = xyz\n"), 0o600)
+	require.NoError(t, err)
+	ctx := env.Set(t.Context(), "HOST_SUFFIX", "abc.cloud.databricks.com")
+
+	host, err := GetProfileHost(ctx, "abc", path)
+	require.NoError(t, err)
+	assert.Equal(t, "https://abc.cloud.databricks.com", host)
+
+	host, err = GetProfileHost(ctx, "def", path)
+	require.NoError(t, err)
+	assert.Empty(t, host)
+
+	host, err = GetProfileHost(ctx, "ghi", path)
+	require.NoError(t, err)
+	assert.Empty(t, host)
+
+	host, err = GetProfileHost(ctx, "abc", filepath.Join(t.TempDir(), "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, host)
+}
+
 func TestDeleteProfile(t *testing.T) {
 	cfg := func(body string) string {
 		return "; " + defaultComment + "\n" + body
@@ -662,3 +908,611 @@ func TestDeleteProfile_NotFound(t *testing.T) {
 	require.Error(t, err)
 	assert.ErrorContains(t, err, `profile "not-found" not found`)
 }
+
+func TestSetProfileKey(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[first]\nhost = https://first.cloud.databricks.com\n"), fileMode))
+
+	err := SetProfileKey(ctx, path, "first", "pinned_workspace_id", "123")
+	require.NoError(t, err)
+
+	file, err := loadConfigFile(ctx, path)
+	require.NoError(t, err)
+	section, err := file.GetSection("first")
+	require.NoError(t, err)
+	assert.Equal(t, "https://first.cloud.databricks.com", section.Key("host").String())
+	assert.Equal(t, "123", section.Key("pinned_workspace_id").String())
+}
+
+func TestSetProfileKey_NotFound(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(""), fileMode))
+
+	err := SetProfileKey(ctx, path, "not-found", "key", "value")
+	assert.ErrorContains(t, err, `profile "not-found" not found`)
+
+	err = SetProfileKey(ctx, filepath.Join(t.TempDir(), "missing"), "first", "key", "value")
+	assert.ErrorContains(t, err, "config file does not exist")
+}
+
+func TestSaveToProfile_PreservesComments(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`; My profiles.
+[DEFAULT]
+
+; dev - safe to experiment
+[dev]
+host=https://dev.cloud.databricks.com
+token=dev-token
+
+; prod - be careful
+[prod]
+host       = https://prod.cloud.databricks.com
+token      = old-token
+cluster_id = abc-123 ; shared cluster
+
+# staging is shared with the data team
+[staging]
+host = https://staging.cloud.databricks.com
+`), fileMode))
+
+	err := SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "prod",
+		Host:       "https://prod.cloud.databricks.com",
+		AuthType:   "databricks-cli",
+	}, "token")
+	require.NoError(t, err)
+
+	err = SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "dev",
+		Host:       "https://dev.cloud.databricks.com",
+		Token:      "new-dev-token",
+	})
+	require.NoError(t, err)
+
+	err = SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "new",
+		Host:       "https://new.cloud.databricks.com",
+		Token:      "new-token",
+	})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `; My profiles.
+[DEFAULT]
+
+; dev - safe to experiment
+[dev]
+host  = https://dev.cloud.databricks.com
+token = new-dev-token
+
+; prod - be careful
+[prod]
+host       = https://prod.cloud.databricks.com
+cluster_id = abc-123 ; shared cluster
+auth_type  = databricks-cli
+
+# staging is shared with the data team
+[staging]
+host = https://staging.cloud.databricks.com
+
+[new]
+host  = https://new.cloud.databricks.com
+token = new-token
+`, string(contents))
+}
+
+func TestDeleteProfile_PreservesComments(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`[DEFAULT]
+
+; first profile
+[first]
+host = https://first.cloud.databricks.com
+
+; second profile
+[second]
+host = https://second.cloud.databricks.com
+
+[__settings__]
+default_profile = second
+`), fileMode))
+
+	err := DeleteProfile(ctx, "second", path)
+	require.NoError(t, err)
+	err = SetDefaultProfile(ctx, "first", path)
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `[DEFAULT]
+
+; first profile
+[first]
+host = https://first.cloud.databricks.com
+
+[__settings__]
+default_profile = first
+`, string(contents))
+}
+
+func TestCommentOutProfile(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`[DEFAULT]
+host = https://default.cloud.databricks.com
+
+; first profile
+[first]
+host  = https://first.cloud.databricks.com
+token = first-token
+
+[second]
+host = https://second.cloud.databricks.com
+`), fileMode))
+
+	err := CommentOutProfile(ctx, "first", path)
+	require.NoError(t, err)
+	err = CommentOutProfile(ctx, "DEFAULT", path)
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `[DEFAULT]
+; host = https://default.cloud.databricks.com
+
+; first profile
+; [first]
+; host  = https://first.cloud.databricks.com
+; token = first-token
+
+[second]
+host = https://second.cloud.databricks.com
+`, string(contents))
+
+	file, err := loadConfigFile(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEFAULT", "second"}, file.SectionStrings())
+	assert.Empty(t, file.Section("DEFAULT").Keys())
+}
+
+func TestCommentOutProfile_NotFound(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[first]\nhost = https://first.cloud.databricks.com\n"), fileMode))
+
+	err := CommentOutProfile(ctx, "not-found", path)
+	assert.ErrorContains(t, err, `profile "not-found" not found`)
+}
+
+func TestRenameProfile(t *testing.T) {
+	cases := []struct {
+		name    string
+		seed    string
+		oldName string
+		newName string
+		want    string
+	}{
+		{
+			name: "rename in place",
+			seed: `[DEFAULT]
+
+; first profile
+[first]
+host = https://first.cloud.databricks.com
+
+; second profile
+[second]
+host = https://second.cloud.databricks.com
+`,
+			oldName: "first",
+			newName: "renamed",
+			want: `[DEFAULT]
+
+; first profile
+[renamed]
+host = https://first.cloud.databricks.com
+
+; second profile
+[second]
+host = https://second.cloud.databricks.com
+`,
+		},
+		{
+			name: "rename configured default",
+			seed: `[first]
+host = https://first.cloud.databricks.com
+
+[__settings__]
+default_profile = first
+`,
+			oldName: "first",
+			newName: "renamed",
+			want: `[renamed]
+host = https://first.cloud.databricks.com
+
+[__settings__]
+default_profile = renamed
+`,
+		},
+		{
+			name: "rename DEFAULT",
+			seed: `; The DEFAULT profile
+[DEFAULT]
+host = https://default.cloud.databricks.com
+
+[other]
+host = https://other.cloud.databricks.com
+`,
+			oldName: "DEFAULT",
+			newName: "renamed",
+			want: `; The DEFAULT profile
+[DEFAULT]
+
+[other]
+host = https://other.cloud.databricks.com
+
+[renamed]
+host = https://default.cloud.databricks.com
+`,
+		},
+		{
+			name: "rename to empty DEFAULT",
+			seed: `[DEFAULT]
+
+[first]
+host = https://first.cloud.databricks.com
+`,
+			oldName: "first",
+			newName: "DEFAULT",
+			want: `[DEFAULT]
+host = https://first.cloud.databricks.com
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := t.Context()
+			path := filepath.Join(t.TempDir(), ".databrickscfg")
+			require.NoError(t, os.WriteFile(path, []byte(tc.seed), fileMode))
+
+			err := RenameProfile(ctx, tc.oldName, tc.newName, path)
+			require.NoError(t, err)
+
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, string(contents))
+		})
+	}
+}
+
+func TestRenameProfile_Errors(t *testing.T) {
+	const seed = `[DEFAULT]
+host = https://default.cloud.databricks.com
+
+[first]
+host = https://first.cloud.databricks.com
+
+[second]
+host = https://second.cloud.databricks.com
+`
+
+	cases := []struct {
+		name    string
+		oldName string
+		newName string
+		wantErr string
+	}{
+		{
+			name:    "not found",
+			oldName: "missing",
+			newName: "renamed",
+			wantErr: `profile "missing" not found`,
+		},
+		{
+			name:    "collision",
+			oldName: "first",
+			newName: "second",
+			wantErr: `profile "second" already exists`,
+		},
+		{
+			name:    "collision with DEFAULT",
+			oldName: "first",
+			newName: "DEFAULT",
+			wantErr: `profile "DEFAULT" already exists`,
+		},
+		{
+			name:    "same name",
+			oldName: "first",
+			newName: "first",
+			wantErr: `profile "first" already exists`,
+		},
+		{
+			name:    "invalid name",
+			oldName: "first",
+			newName: "[bad]",
+			wantErr: `invalid profile name "[bad]"`,
+		},
+		{
+			name:    "reserved name",
+			oldName: "first",
+			newName: "__settings__",
+			wantErr: `profile name "__settings__" is reserved for internal use`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := t.Context()
+			path := filepath.Join(t.TempDir(), ".databrickscfg")
+			require.NoError(t, os.WriteFile(path, []byte(seed), fileMode))
+
+			err := RenameProfile(ctx, tc.oldName, tc.newName, path)
+			assert.ErrorContains(t, err, tc.wantErr)
+
+			contents, err := os.ReadFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, seed, string(contents))
+		})
+	}
+}
+
+func TestProfileOperations_ExpandConfigFilePath(t *testing.T) {
+	dir := t.TempDir()
+	ctx := env.WithUserHomeDir(t.Context(), dir)
+	ctx = env.Set(ctx, "CONFIG_DIR", dir)
+	path := filepath.Join(dir, ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`[first]
+host = https://first.cloud.databricks.com
+
+[second]
+host = https://second.cloud.databricks.com
+
+[third]
+host = https://third.cloud.databricks.com
+`), fileMode))
+
+	require.NoError(t, RenameProfile(ctx, "first", "renamed", "$CONFIG_DIR/.databrickscfg"))
+	require.NoError(t, DeleteProfile(ctx, "second", "~/.databrickscfg"))
+	require.NoError(t, CommentOutProfile(ctx, "third", "%CONFIG_DIR%/.databrickscfg"))
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `[renamed]
+host = https://first.cloud.databricks.com
+
+; [third]
+; host = https://third.cloud.databricks.com
+`, string(contents))
+}
+
+func TestSaveToProfile_KeepsEnvReferences(t *testing.T) {
+	ctx := env.Set(t.Context(), "MY_HOST", "https://my.cloud.databricks.com")
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`[dev]
+host       = ${env:MY_HOST}
+cluster_id = ${env:MY_CLUSTER}
+`), fileMode))
+
+	err := SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Host:       "https://my.cloud.databricks.com",
+		AuthType:   "databricks-cli",
+		ClusterID:  "abc",
+	})
+	require.NoError(t, err)
+
+	// The profile is matched by the expanded host. References that expand to
+	// the saved value are kept, others are replaced.
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, `[dev]
+host       = ${env:MY_HOST}
+cluster_id = abc
+auth_type  = databricks-cli
+`, string(contents))
+}
+
+func TestSaveToProfile_Backup(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	original := "[abc]\nhost = https://foo\n"
+	require.NoError(t, os.WriteFile(path, []byte(original), fileMode))
+
+	save := func(token string) {
+		err := SaveToProfile(ctx, &config.Config{
+			ConfigFile: path,
+			Profile:    "abc",
+			Host:       "https://foo",
+			Token:      token,
+		})
+		require.NoError(t, err)
+	}
+
+	// The first change backs up the original file.
+	save("first")
+	bak, err := os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, original, string(bak))
+	assert.NoFileExists(t, path+".latest.bak")
+
+	// Later changes preserve the original backup.
+	save("second")
+	bak, err = os.ReadFile(path + ".bak")
+	require.NoError(t, err)
+	assert.Equal(t, original, string(bak))
+	latest, err := os.ReadFile(path + ".latest.bak")
+	require.NoError(t, err)
+	assert.Equal(t, "[abc]\nhost  = https://foo\ntoken = first\n", string(latest))
+
+	// Saving the same values doesn't modify the file or its backups.
+	require.NoError(t, os.Remove(path+".latest.bak"))
+	save("second")
+	assert.NoFileExists(t, path+".latest.bak")
+}
+
+func TestSaveToProfile_NoBackup(t *testing.T) {
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_NO_BACKUP", "true")
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[abc]\nhost = https://foo\n"), fileMode))
+
+	err := SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "abc",
+		Host:       "https://foo",
+		Token:      "xyz",
+	})
+	require.NoError(t, err)
+	assert.NoFileExists(t, path+".bak")
+	assert.NoFileExists(t, path+".latest.bak")
+}
+
+// authKeyTypes categorizes every auth-related attribute of the SDK by the auth
+// types that use it. Attributes that hold credentials must be categorized so
+// that saving a profile for one auth type clears the credentials of the others.
+var authKeyTypes = map[string][]string{
+	"metadata_service_url":         {"metadata-service"},
+	"token":                        {"pat"},
+	"username":                     {"basic"},
+	"password":                     {"basic"},
+	"google_service_account":       {"google-id"},
+	"google_credentials":           {"google-credentials"},
+	"azure_workspace_resource_id":  {"azure-cli", "azure-msi"},
+	"azure_use_msi":                {"azure-msi"},
+	"azure_client_secret":          {"azure-client-secret"},
+	"azure_client_id":              {"azure-client-secret", "azure-msi"},
+	"azure_tenant_id":              {"azure-cli", "azure-client-secret"},
+	"azure_login_app_id":           {"azure-cli", "azure-client-secret", "azure-msi"},
+	"client_id":                    {"oauth-m2m"},
+	"client_secret":                {"oauth-m2m"},
+	"databricks_cli_path":          {"databricks-cli"},
+	"databricks_id_token_filepath": {"file-oidc"},
+	"oidc_token_env":               {"env-oidc"},
+}
+
+func TestAllAuthRelatedKeys(t *testing.T) {
+	var want []string
+	for key := range authKeyTypes {
+		want = append(want, key)
+	}
+	want = append(want, authSettingKeys...)
+
+	// A failure here means that the SDK added or removed an auth-related
+	// attribute. Add it to authKeyTypes and check that CredentialKeysFor
+	// assigns it to the right auth types.
+	assert.ElementsMatch(t, want, AllAuthRelatedKeys())
+}
+
+func TestCredentialKeysFor(t *testing.T) {
+	for key, authTypes := range authKeyTypes {
+		for _, authType := range authTypes {
+			assert.Contains(t, CredentialKeysFor(authType), key, "auth type %s", authType)
+		}
+	}
+
+	assert.Equal(t, []string{"token"}, CredentialKeysFor("pat"))
+	assert.Equal(t, []string{"databricks_cli_path"}, CredentialKeysFor("databricks-cli"))
+	assert.Equal(t, []string{"azure_workspace_resource_id", "azure_tenant_id", "azure_login_app_id"}, CredentialKeysFor("azure-cli"))
+	assert.Empty(t, CredentialKeysFor("unknown"))
+}
+
+func TestOtherCredentialKeys(t *testing.T) {
+	keys := OtherCredentialKeys("oauth-m2m")
+	assert.Contains(t, keys, "token")
+	assert.Contains(t, keys, "azure_client_secret")
+	assert.NotContains(t, keys, "client_id")
+	assert.NotContains(t, keys, "client_secret")
+	assert.ElementsMatch(t, AuthCredentialKeys(), OtherCredentialKeys("databricks-cli"))
+}
+
+// notepadConfig is a config file as saved by Notepad on Windows, with a byte
+// order mark and CRLF line endings.
+const notepadConfig = "\xef\xbb\xbf[dev]\r\n" +
+	"host  = https://dev\r\n" +
+	"token = dev-token\r\n" +
+	"\r\n" +
+	"; Production workspace.\r\n" +
+	"[prod]\r\n" +
+	"host = https://prod\r\n" +
+	"token=prod-token\r\n" +
+	"\r\n" +
+	"[staging]\r\n" +
+	"host = https://staging\r\n"
+
+func TestLoadFile_ByteOrderMark(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(notepadConfig), fileMode))
+
+	file, err := LoadFile(t.Context(), path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"DEFAULT", "dev", "prod", "staging"}, file.SectionStrings())
+	assert.Equal(t, "dev-token", file.Section("dev").Key("token").String())
+}
+
+func TestSaveToProfile_ByteOrderMarkAndCRLF(t *testing.T) {
+	ctx := t.Context()
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(notepadConfig), fileMode))
+
+	// Saving a profile without changes leaves the file alone.
+	err := SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "dev",
+		Host:       "https://dev",
+		Token:      "dev-token",
+	})
+	require.NoError(t, err)
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, notepadConfig, string(contents))
+
+	err = SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "staging",
+		Host:       "https://staging",
+		Token:      "staging-token",
+	})
+	require.NoError(t, err)
+
+	// The byte order mark is dropped, and the sections that didn't change are
+	// kept byte for byte.
+	contents, err = os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, notepadConfig[3:len(notepadConfig)-len("host = https://staging\r\n")]+
+		"host  = https://staging\r\n"+
+		"token = staging-token\r\n", string(contents))
+
+	file, err := LoadFile(ctx, path)
+	require.NoError(t, err)
+	assert.Equal(t, "staging-token", file.Section("staging").Key("token").String())
+}
+
+func TestSaveToProfile_RewriteKeepsCRLF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	// Files with multi-line values can't be edited in place and are rewritten.
+	orig := "\xef\xbb\xbf[dev]\r\nhost = https://dev\r\ndescription = \"\"\"first\r\nsecond\"\"\"\r\n"
+	require.NoError(t, os.WriteFile(path, []byte(orig), fileMode))
+
+	err := SaveToProfile(t.Context(), &config.Config{
+		ConfigFile: path,
+		Profile:    "dev",
+		Host:       "https://dev",
+		Token:      "xyz",
+	})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(contents), "\xef\xbb\xbf")
+	assert.Contains(t, string(contents), "[dev]\r\n")
+	assert.NotRegexp(t, "[^\r]\n", string(contents))
+}
@@ -0,0 +1,444 @@
+package databrickscfg
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/databricks/databricks-sdk-go/config"
+	"gopkg.in/ini.v1"
+)
+
+// SectionLines records where a section of a config file is defined. Lines
+// are 0-based indexes into the lines of the file.
+type SectionLines struct {
+	// Blocks holds the first and last line of every definition of the
+	// section. A block starts at the header and ends at the last key. The
+	// block of a DEFAULT section without header starts at -1.
+	Blocks [][2]int
+
+	// Keys maps key names to the lines that set them.
+	Keys map[string][]int
+}
+
+// Headers returns the lines of the headers of the section.
+func (s *SectionLines) Headers() []int {
+	var headers []int
+	for _, block := range s.Blocks {
+		if block[0] >= 0 {
+			headers = append(headers, block[0])
+		}
+	}
+	return headers
+}
+
+// last returns the line after which keys are added to the section.
+func (s *SectionLines) last() int {
+	return s.Blocks[len(s.Blocks)-1][1]
+}
+
+// SyntaxError is a line of a config file that can't be parsed.
+type SyntaxError struct {
+	// Section is the section that the line belongs to. It is empty for
+	// invalid section headers.
+	Section string
+
+	// Line is the 0-based index of the line, or -1 if the error is not
+	// caused by a single line.
+	Line int
+
+	Message string
+}
+
+// ConfigLines records where the sections and keys of a config file are
+// defined.
+type ConfigLines struct {
+	Sections map[string]*SectionLines
+
+	// Errors holds every syntax error of the file. The parser of the config
+	// file only reports the first one.
+	Errors []SyntaxError
+
+	// Editable is false if the file contains lines that can't be edited one
+	// by one, such as syntax errors, quoted keys and values that span
+	// multiple lines.
+	Editable bool
+}
+
+// patchConfigFile applies the contents of configFile to orig, the contents
+// of the file it was loaded from, as edits to individual lines. Comments,
+// blank lines and the lines of unchanged keys and sections are kept as is.
+// It returns false if the edits don't reproduce configFile, for example if
+// orig contains multi-line values, in which case the file must be rewritten.
+func patchConfigFile(orig []byte, configFile *config.File) ([]byte, bool) {
+	old, err := ini.LoadSources(ini.LoadOptions{SpaceBeforeInlineComment: true}, orig)
+	if err != nil {
+		return nil, false
+	}
+
+	eol := lineEnding(orig)
+	lines := strings.Split(strings.TrimSuffix(string(orig), "\n"), "\n")
+	scanned := ScanConfigLines(lines)
+	if !scanned.Editable {
+		return nil, false
+	}
+	sections := scanned.Sections
+
+	deleted := map[int]bool{}
+	replaced := map[int]string{}
+	inserted := map[int][]string{}
+	var appended []string
+
+	for _, oldSection := range old.Sections() {
+		if _, err := configFile.GetSection(oldSection.Name()); err == nil {
+			continue
+		}
+		s, ok := sections[oldSection.Name()]
+		if !ok {
+			continue
+		}
+		for _, block := range s.Blocks {
+			deleteLines(lines, deleted, block[0], block[1])
+		}
+	}
+
+	for _, section := range configFile.Sections() {
+		name := section.Name()
+		oldSection, err := old.GetSection(name)
+		if err != nil {
+			if len(appended) > 0 {
+				appended = append(appended, "")
+			}
+			appended = append(appended, formatSection(section)...)
+			continue
+		}
+
+		if maps.Equal(oldSection.KeysHash(), section.KeysHash()) {
+			continue
+		}
+		s, ok := sections[name]
+		if !ok {
+			// The DEFAULT section is empty and has no header.
+			s = &SectionLines{Blocks: [][2]int{{-1, -1}}, Keys: map[string][]int{}}
+		}
+		for _, key := range oldSection.Keys() {
+			if section.HasKey(key.Name()) {
+				continue
+			}
+			for _, i := range s.Keys[key.Name()] {
+				deleteLines(lines, deleted, i, i)
+			}
+		}
+
+		// The keys of the section are aligned like [ini.File.SaveTo] does.
+		var added []string
+		width := keyWidth(section)
+		for _, key := range section.Keys() {
+			if !oldSection.HasKey(key.Name()) {
+				added = append(added, formatKey(key.Name(), key.Value(), width))
+				continue
+			}
+			changed := oldSection.Key(key.Name()).Value() != key.Value()
+			for _, i := range s.Keys[key.Name()] {
+				if changed {
+					replaced[i] = formatKey(key.Name(), key.Value(), width)
+				} else {
+					replaced[i] = alignKey(lines[i], key.Name(), width)
+				}
+			}
+		}
+		if len(added) == 0 {
+			continue
+		}
+		last := s.last()
+		if last < 0 {
+			// Keys of a DEFAULT section without header go to the top of the file.
+			added = append(append([]string{"[" + ini.DefaultSection + "]"}, added...), "")
+		}
+		inserted[last] = append(inserted[last], added...)
+	}
+
+	var out []string
+	out = append(out, inserted[-1]...)
+	for i, line := range lines {
+		switch {
+		case deleted[i]:
+		case replaced[i] != "":
+			out = append(out, replaced[i])
+		default:
+			out = append(out, line)
+		}
+		out = append(out, inserted[i]...)
+	}
+	if len(appended) > 0 {
+		if len(out) > 0 && strings.TrimSpace(out[len(out)-1]) != "" {
+			out = append(out, "")
+		}
+		out = append(out, appended...)
+	}
+
+	result := withLineEnding([]byte(strings.Join(out, "\n")+"\n"), eol)
+	if !sameContents(result, configFile) {
+		return nil, false
+	}
+	return result, true
+}
+
+// utf8BOM is the byte order mark that editors like Notepad on Windows add to
+// the start of UTF-8 files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// StripBOM returns data without a leading UTF-8 byte order mark. The parser
+// of the config file ignores it, but it must not be mistaken for part of the
+// first line when editing the file line by line.
+func StripBOM(data []byte) []byte {
+	return bytes.TrimPrefix(data, utf8BOM)
+}
+
+// lineEnding returns the line ending of most lines of data: "\r\n" if more
+// lines end with CRLF than with LF alone, and "\n" otherwise.
+func lineEnding(data []byte) string {
+	crlf := bytes.Count(data, []byte("\r\n"))
+	if crlf > bytes.Count(data, []byte("\n"))-crlf {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// withLineEnding returns data with all line endings replaced by eol.
+func withLineEnding(data []byte, eol string) []byte {
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if eol == "\n" {
+		return data
+	}
+	return bytes.ReplaceAll(data, []byte("\n"), []byte(eol))
+}
+
+// renameSections renames the section headers of orig according to renames,
+// which maps old section names to new ones. Other lines are kept as is.
+func renameSections(orig []byte, renames map[string]string) []byte {
+	if len(renames) == 0 {
+		return orig
+	}
+	lines := strings.Split(string(orig), "\n")
+	for i, line := range lines {
+		t := strings.TrimSpace(line)
+		if t == "" || t[0] != '[' {
+			continue
+		}
+		start := strings.IndexByte(line, '[')
+		end := strings.LastIndexByte(line, ']')
+		if end < start {
+			continue
+		}
+		if name, ok := renames[line[start+1:end]]; ok {
+			lines[i] = line[:start+1] + name + line[end:]
+		}
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// commentOutSection comments out the lines that define the named section in
+// orig. Comments and blank lines are kept as is. The header of the DEFAULT
+// section is kept so that it stays at the top of the file. It returns false
+// if the section is not defined or orig can't be edited line by line.
+func commentOutSection(orig []byte, name string) ([]byte, bool) {
+	lines := strings.Split(string(orig), "\n")
+	scanned := ScanConfigLines(lines)
+	if !scanned.Editable {
+		return nil, false
+	}
+	s, ok := scanned.Sections[name]
+	if !ok {
+		return nil, false
+	}
+	for _, block := range s.Blocks {
+		for i := max(block[0], 0); i <= block[1]; i++ {
+			t := strings.TrimSpace(lines[i])
+			if t == "" || isComment(lines[i]) || (i == block[0] && name == ini.DefaultSection) {
+				continue
+			}
+			lines[i] = "; " + lines[i]
+		}
+	}
+	return []byte(strings.Join(lines, "\n")), true
+}
+
+// ScanConfigLines returns where the sections and keys of a config file with
+// the given lines are defined, together with its syntax errors.
+func ScanConfigLines(lines []string) *ConfigLines {
+	c := &ConfigLines{Sections: map[string]*SectionLines{}, Editable: true}
+	fail := func(section string, line int, message string) {
+		c.Errors = append(c.Errors, SyntaxError{Section: section, Line: line, Message: message})
+		c.Editable = false
+	}
+
+	name := ini.DefaultSection
+	var current *SectionLines
+	multiline := false
+	continued := false
+	for i, line := range lines {
+		t := strings.TrimSpace(line)
+		switch {
+		case multiline:
+			multiline = !strings.Contains(t, `"""`)
+			continue
+		case continued:
+			continued = strings.HasSuffix(t, `\`)
+			continue
+		case t == "" || t[0] == ';' || t[0] == '#':
+			continue
+		case t[0] == '[':
+			end := strings.LastIndexByte(t, ']')
+			if end < 0 {
+				fail("", i, "unclosed section header "+t)
+				continue
+			}
+			if end == 1 {
+				fail("", i, "empty section name")
+				continue
+			}
+			name = t[1:end]
+			current = c.Sections[name]
+			if current == nil {
+				current = &SectionLines{Keys: map[string][]int{}}
+				c.Sections[name] = current
+			}
+			current.Blocks = append(current.Blocks, [2]int{i, i})
+			continue
+		}
+
+		delim := strings.IndexAny(t, "=:")
+		if delim < 0 {
+			fail(name, i, fmt.Sprintf("line %q is not a section header, key = value pair or comment", t))
+			continue
+		}
+		key := strings.TrimSpace(t[:delim])
+		if key == "" {
+			fail(name, i, "key name is empty")
+			continue
+		}
+		if current == nil {
+			current = &SectionLines{Blocks: [][2]int{{-1, -1}}, Keys: map[string][]int{}}
+			c.Sections[ini.DefaultSection] = current
+		}
+		current.Keys[key] = append(current.Keys[key], i)
+		current.Blocks[len(current.Blocks)-1][1] = i
+
+		value := strings.TrimSpace(t[delim+1:])
+		multiline = strings.HasPrefix(value, `"""`) && !strings.Contains(value[3:], `"""`)
+		continued = strings.HasSuffix(value, `\`)
+		if t[0] == '"' || t[0] == '`' || strings.Contains(t, `"""`) || continued {
+			c.Editable = false
+		}
+	}
+
+	if multiline {
+		fail(name, -1, `multi-line value is not closed with """`)
+	}
+	return c
+}
+
+// deleteLines marks the lines from first to last for deletion, together with
+// the comments directly above them, which describe the deleted lines.
+func deleteLines(lines []string, deleted map[int]bool, first, last int) {
+	if first < 0 {
+		first = 0
+	}
+	for first > 0 && isComment(lines[first-1]) {
+		first--
+	}
+	for i := first; i <= last; i++ {
+		deleted[i] = true
+	}
+	// Don't leave blank lines at the end of the file.
+	if strings.TrimSpace(strings.Join(lines[last+1:], "")) == "" {
+		for i := first - 1; i >= 0 && strings.TrimSpace(lines[i]) == ""; i-- {
+			deleted[i] = true
+		}
+	}
+	// Don't leave two blank lines behind.
+	if first == 0 || strings.TrimSpace(lines[first-1]) == "" {
+		for i := last + 1; i < len(lines) && strings.TrimSpace(lines[i]) == ""; i++ {
+			deleted[i] = true
+		}
+	}
+}
+
+func isComment(line string) bool {
+	t := strings.TrimSpace(line)
+	return t != "" && (t[0] == ';' || t[0] == '#')
+}
+
+// alignKey returns line with its key padded to width. The value and any
+// inline comment are kept.
+func alignKey(line, name string, width int) string {
+	rest := strings.TrimLeft(line[strings.IndexAny(line, "=:")+1:], " \t")
+	return fmt.Sprintf("%-*s = %s", width, name, rest)
+}
+
+// formatSection formats a section the same way [ini.File.SaveTo] does.
+func formatSection(section *ini.Section) []string {
+	var lines []string
+	if section.Comment != "" {
+		for _, c := range strings.Split(section.Comment, "\n") {
+			if c == "" || (c[0] != ';' && c[0] != '#') {
+				c = "; " + c
+			}
+			lines = append(lines, c)
+		}
+	}
+	lines = append(lines, "["+section.Name()+"]")
+	width := keyWidth(section)
+	for _, key := range section.Keys() {
+		lines = append(lines, formatKey(key.Name(), key.Value(), width))
+	}
+	return lines
+}
+
+// keyWidth returns the length of the longest key of section, to which keys
+// are aligned.
+func keyWidth(section *ini.Section) int {
+	width := 0
+	for _, name := range section.KeyStrings() {
+		width = max(width, len(name))
+	}
+	return width
+}
+
+func formatKey(name, value string, width int) string {
+	return fmt.Sprintf("%-*s = %s", width, name, formatValue(value))
+}
+
+// formatValue quotes value the same way [ini.File.SaveTo] does.
+func formatValue(value string) string {
+	switch {
+	case strings.ContainsAny(value, "\n`"):
+		return `"""` + value + `"""`
+	case strings.ContainsAny(value, "#;"):
+		return "`" + value + "`"
+	case len(strings.TrimSpace(value)) != len(value):
+		return `"` + value + `"`
+	}
+	return value
+}
+
+// sameContents returns true if data has the same sections and keys as
+// configFile.
+func sameContents(data []byte, configFile *config.File) bool {
+	got, err := ini.LoadSources(ini.LoadOptions{SpaceBeforeInlineComment: true}, data)
+	if err != nil {
+		return false
+	}
+	if len(got.Sections()) != len(configFile.Sections()) {
+		return false
+	}
+	for _, want := range configFile.Sections() {
+		section, err := got.GetSection(want.Name())
+		if err != nil || !maps.Equal(section.KeysHash(), want.KeysHash()) {
+			return false
+		}
+	}
+	return true
+}
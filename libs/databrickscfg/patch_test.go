@@ -0,0 +1,136 @@
+package databrickscfg
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPatchConfigFile(t *testing.T) {
+	cases := []struct {
+		name   string
+		orig   string
+		update func(f *config.File)
+		want   string
+	}{
+		{
+			name: "key in DEFAULT section without header",
+			orig: "host = https://default\n\n[dev]\nhost = https://dev\n",
+			update: func(f *config.File) {
+				f.Section("DEFAULT").Key("token").SetValue("xyz")
+			},
+			want: "host  = https://default\ntoken = xyz\n\n[dev]\nhost = https://dev\n",
+		},
+		{
+			name: "key in empty DEFAULT section without header",
+			orig: "[dev]\nhost = https://dev\n",
+			update: func(f *config.File) {
+				f.Section("DEFAULT").Key("host").SetValue("https://default")
+			},
+			want: "[DEFAULT]\nhost = https://default\n\n[dev]\nhost = https://dev\n",
+		},
+		{
+			name: "deleted key takes its comment",
+			orig: "[dev]\nhost = https://dev\n; expires in March\ntoken = xyz\n",
+			update: func(f *config.File) {
+				f.Section("dev").DeleteKey("token")
+			},
+			want: "[dev]\nhost = https://dev\n",
+		},
+		{
+			name: "value that needs quoting",
+			orig: "[dev]\nhost = https://dev\npassword = old\n",
+			update: func(f *config.File) {
+				f.Section("dev").Key("password").SetValue("a;b")
+			},
+			want: "[dev]\nhost     = https://dev\npassword = `a;b`\n",
+		},
+		{
+			name: "CRLF line endings",
+			orig: "[dev]\r\nhost = https://dev\r\n",
+			update: func(f *config.File) {
+				f.Section("dev").Key("token").SetValue("xyz")
+			},
+			want: "[dev]\r\nhost  = https://dev\r\ntoken = xyz\r\n",
+		},
+		{
+			name: "mostly CRLF line endings",
+			orig: "[dev]\r\nhost = https://dev\r\n\n[prod]\r\nhost = https://prod\r\n",
+			update: func(f *config.File) {
+				f.Section("prod").Key("token").SetValue("xyz")
+			},
+			want: "[dev]\r\nhost = https://dev\r\n\r\n[prod]\r\nhost  = https://prod\r\ntoken = xyz\r\n",
+		},
+		{
+			name: "mostly LF line endings",
+			orig: "[dev]\r\nhost = https://dev\n\n[prod]\nhost = https://prod\n",
+			update: func(f *config.File) {
+				f.Section("prod").Key("token").SetValue("xyz")
+			},
+			want: "[dev]\nhost = https://dev\n\n[prod]\nhost  = https://prod\ntoken = xyz\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".databrickscfg")
+			require.NoError(t, os.WriteFile(path, []byte(tc.orig), fileMode))
+			f, err := config.LoadFile(path)
+			require.NoError(t, err)
+
+			tc.update(f)
+			got, ok := patchConfigFile([]byte(tc.orig), f)
+			require.True(t, ok)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestPatchConfigFile_MultiLineValue(t *testing.T) {
+	orig := "[dev]\nhost = https://dev\ndescription = \"\"\"first\nsecond\"\"\"\n"
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(orig), fileMode))
+	f, err := config.LoadFile(path)
+	require.NoError(t, err)
+
+	f.Section("dev").Key("token").SetValue("xyz")
+	_, ok := patchConfigFile([]byte(orig), f)
+	assert.False(t, ok)
+}
+
+func TestScanConfigLines(t *testing.T) {
+	lines := strings.Split(`key = default
+[dev]
+host = https://dev
+; comment
+token = abc
+
+[dev]
+host = https://other
+[broken
+no delimiter
+ = value
+description = """
+multi-line`, "\n")
+
+	scanned := ScanConfigLines(lines)
+	assert.False(t, scanned.Editable)
+	assert.Equal(t, &SectionLines{Blocks: [][2]int{{-1, 0}}, Keys: map[string][]int{"key": {0}}}, scanned.Sections["DEFAULT"])
+	assert.Equal(t, []int{1, 6}, scanned.Sections["dev"].Headers())
+	assert.Equal(t, map[string][]int{"host": {2, 7}, "token": {4}, "description": {11}}, scanned.Sections["dev"].Keys)
+	assert.Equal(t, []SyntaxError{
+		{Line: 8, Message: "unclosed section header [broken"},
+		{Section: "dev", Line: 9, Message: `line "no delimiter" is not a section header, key = value pair or comment`},
+		{Section: "dev", Line: 10, Message: "key name is empty"},
+		{Section: "dev", Line: -1, Message: `multi-line value is not closed with """`},
+	}, scanned.Errors)
+
+	scanned = ScanConfigLines(lines[:8])
+	assert.True(t, scanned.Editable)
+	assert.Empty(t, scanned.Errors)
+}
@@ -0,0 +1,49 @@
+package databrickscfg
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/databricks/cli/libs/env"
+)
+
+// pathEnvReference matches references to environment variables in the path
+// of a config file: $VAR and ${VAR} as in Unix shells, and %VAR% as on Windows.
+var pathEnvReference = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}|\$([A-Za-z_][A-Za-z0-9_]*)|%([A-Za-z_][A-Za-z0-9_()]*)%`)
+
+// ExpandConfigFilePath expands a leading ~ to the home directory and replaces
+// references to environment variables in the path of a config file, such as
+// the value of DATABRICKS_CONFIG_FILE. Paths like ~user that refer to the home
+// directory of another user are not supported.
+func ExpandConfigFilePath(ctx context.Context, path string) (string, error) {
+	if strings.HasPrefix(path, "~") {
+		rest := path[1:]
+		if rest != "" && rest[0] != '/' && rest[0] != '\\' {
+			return "", fmt.Errorf("cannot expand %q: only ~ is supported to refer to the home directory, use the full path to the home directory of other users", path)
+		}
+		homedir, err := env.UserHomeDir(ctx)
+		if err != nil {
+			return "", fmt.Errorf("cannot find homedir: %w", err)
+		}
+		path = homedir + rest
+	}
+
+	var err error
+	path = pathEnvReference.ReplaceAllStringFunc(path, func(ref string) string {
+		m := pathEnvReference.FindStringSubmatch(ref)
+		name := cmp.Or(m[1], m[2], m[3])
+		value, ok := env.Lookup(ctx, name)
+		if !ok {
+			err = cmp.Or(err, fmt.Errorf("cannot expand %q: environment variable %s is not set", path, name))
+			return ref
+		}
+		return value
+	})
+	if err != nil {
+		return "", err
+	}
+	return path, nil
+}
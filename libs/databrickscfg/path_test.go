@@ -0,0 +1,87 @@
+package databrickscfg
+
+import (
+	"testing"
+
+	"github.com/databricks/cli/libs/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExpandConfigFilePath(t *testing.T) {
+	ctx := env.WithUserHomeDir(t.Context(), "/home/user")
+	ctx = env.Set(ctx, "CONFIG_DIR", "/etc/databricks")
+	ctx = env.Set(ctx, "APPDATA", `C:\Users\user\AppData\Roaming`)
+
+	cases := []struct {
+		name string
+		path string
+		want string
+	}{
+		{
+			name: "plain path",
+			path: "/tmp/databrickscfg",
+			want: "/tmp/databrickscfg",
+		},
+		{
+			name: "tilde",
+			path: "~/work/databrickscfg",
+			want: "/home/user/work/databrickscfg",
+		},
+		{
+			name: "tilde only",
+			path: "~",
+			want: "/home/user",
+		},
+		{
+			name: "tilde with backslash",
+			path: `~\work\databrickscfg`,
+			want: `/home/user\work\databrickscfg`,
+		},
+		{
+			name: "unix variable",
+			path: "$CONFIG_DIR/databrickscfg",
+			want: "/etc/databricks/databrickscfg",
+		},
+		{
+			name: "unix variable with braces",
+			path: "${CONFIG_DIR}.d/databrickscfg",
+			want: "/etc/databricks.d/databrickscfg",
+		},
+		{
+			name: "windows variable",
+			path: `%APPDATA%\databricks\databrickscfg`,
+			want: `C:\Users\user\AppData\Roaming\databricks\databrickscfg`,
+		},
+		{
+			name: "tilde and variable",
+			path: "~/$CONFIG_DIR",
+			want: "/home/user//etc/databricks",
+		},
+		{
+			name: "single percent sign",
+			path: "/tmp/100%/databrickscfg",
+			want: "/tmp/100%/databrickscfg",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ExpandConfigFilePath(ctx, c.path)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestExpandConfigFilePath_Errors(t *testing.T) {
+	ctx := env.WithUserHomeDir(t.Context(), "/home/user")
+
+	_, err := ExpandConfigFilePath(ctx, "~other/databrickscfg")
+	assert.EqualError(t, err, `cannot expand "~other/databrickscfg": only ~ is supported to refer to the home directory, use the full path to the home directory of other users`)
+
+	_, err = ExpandConfigFilePath(ctx, "$DATABRICKS_TEST_UNSET_DIR/databrickscfg")
+	assert.EqualError(t, err, `cannot expand "$DATABRICKS_TEST_UNSET_DIR/databrickscfg": environment variable DATABRICKS_TEST_UNSET_DIR is not set`)
+
+	_, err = ExpandConfigFilePath(ctx, `%DATABRICKS_TEST_UNSET_DIR%\databrickscfg`)
+	assert.EqualError(t, err, `cannot expand "%DATABRICKS_TEST_UNSET_DIR%\\databrickscfg": environment variable DATABRICKS_TEST_UNSET_DIR is not set`)
+}
@@ -0,0 +1,67 @@
+package databrickscfg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"github.com/databricks/cli/libs/log"
+)
+
+// FixPermissionsCommand restricts access to the config file to its owner.
+const FixPermissionsCommand = "databricks configure fix-permissions"
+
+// PermissionsWarning returns a warning if the config file at path can be
+// accessed by users other than its owner, and an empty string otherwise. The
+// config file often contains personal access tokens and other secrets. Missing
+// files and files on Windows, where access is not controlled by mode bits, are
+// not reported.
+func PermissionsWarning(ctx context.Context, path string) string {
+	path, err := resolveConfigFilePath(ctx, path)
+	if err != nil {
+		return ""
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	if insecureMode(info) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s can be accessed by other users (mode %04o). Run %q to restrict access to its owner", path, info.Mode().Perm(), FixPermissionsCommand)
+}
+
+// WarnInsecurePermissions logs [PermissionsWarning] for the config file at
+// path, if any.
+func WarnInsecurePermissions(ctx context.Context, path string) {
+	if warning := PermissionsWarning(ctx, path); warning != "" {
+		log.Warnf(ctx, "%s", warning)
+	}
+}
+
+// FixPermissions removes the permissions of the config file at path that let
+// users other than its owner access it. It returns the path of the file and
+// whether its permissions were changed.
+func FixPermissions(ctx context.Context, path string) (string, bool, error) {
+	path, err := resolveConfigFilePath(ctx, path)
+	if err != nil {
+		return "", false, err
+	}
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return path, false, fmt.Errorf("config file %s does not exist", path)
+	}
+	if err != nil {
+		return path, false, err
+	}
+	insecure := insecureMode(info)
+	if insecure == 0 {
+		return path, false, nil
+	}
+	if err := os.Chmod(path, info.Mode().Perm()&^insecure); err != nil {
+		return path, false, err
+	}
+	return path, true, nil
+}
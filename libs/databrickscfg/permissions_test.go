@@ -0,0 +1,110 @@
+package databrickscfg
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/databricks/cli/libs/log"
+	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveToProfile_CreatesFileForOwnerOnly(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	err := SaveToProfile(t.Context(), &config.Config{
+		ConfigFile: path,
+		Profile:    "abc",
+		Host:       "https://foo",
+		Token:      "xyz",
+	})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.Empty(t, PermissionsWarning(t.Context(), path))
+}
+
+func TestPermissionsWarning(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	assert.Empty(t, PermissionsWarning(t.Context(), path))
+
+	require.NoError(t, os.WriteFile(path, []byte("[abc]\n"), fileMode))
+	require.NoError(t, os.Chmod(path, 0o644))
+	assert.Equal(t, path+` can be accessed by other users (mode 0644). Run "databricks configure fix-permissions" to restrict access to its owner`, PermissionsWarning(t.Context(), path))
+}
+
+func TestSaveToProfile_WarnsAboutInsecurePermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[abc]\nhost = https://foo\n"), fileMode))
+	require.NoError(t, os.Chmod(path, 0o640))
+
+	var buf bytes.Buffer
+	ctx := log.NewContext(t.Context(), slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelWarn})))
+
+	// Profiles without secrets don't trigger the warning.
+	err := SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "abc",
+		Host:       "https://foo",
+		AuthType:   "databricks-cli",
+	})
+	require.NoError(t, err)
+	assert.Empty(t, buf.String())
+
+	err = SaveToProfile(ctx, &config.Config{
+		ConfigFile: path,
+		Profile:    "abc",
+		Host:       "https://foo",
+		Token:      "xyz",
+	})
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), "can be accessed by other users (mode 0640)")
+}
+
+func TestFixPermissions(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[abc]\n"), fileMode))
+	require.NoError(t, os.Chmod(path, 0o664))
+
+	got, changed, err := FixPermissions(t.Context(), path)
+	require.NoError(t, err)
+	assert.Equal(t, path, got)
+	assert.True(t, changed)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	assert.Empty(t, PermissionsWarning(t.Context(), path))
+
+	_, changed, err = FixPermissions(t.Context(), path)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestFixPermissions_NotFound(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	_, _, err := FixPermissions(t.Context(), path)
+	assert.ErrorContains(t, err, "config file "+path+" does not exist")
+}
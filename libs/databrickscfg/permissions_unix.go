@@ -0,0 +1,11 @@
+//go:build !windows
+
+package databrickscfg
+
+import "io/fs"
+
+// insecureMode returns the permission bits of a file that give users other
+// than its owner access to it.
+func insecureMode(info fs.FileInfo) fs.FileMode {
+	return info.Mode().Perm() & 0o077
+}
@@ -0,0 +1,12 @@
+//go:build windows
+
+package databrickscfg
+
+import "io/fs"
+
+// insecureMode returns the permission bits of a file that give users other
+// than its owner access to it. Access to files on Windows is controlled by
+// ACLs rather than mode bits, so no bits are reported.
+func insecureMode(info fs.FileInfo) fs.FileMode {
+	return 0
+}
@@ -0,0 +1,100 @@
+package profile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+)
+
+// CachedProfiler is a [Profiler] that parses the .databrickscfg file once and
+// reuses it across calls to LoadProfiles. The parsed file is cached by path and
+// reloaded when the file or one of the files it includes is replaced or its
+// modification time or size change, or when DATABRICKS_CONFIG_INCLUDE changes.
+//
+// It is safe for concurrent use. The zero value is ready to use.
+type CachedProfiler struct {
+	FileProfilerImpl
+
+	mu    sync.Mutex
+	files map[string]cachedFile
+}
+
+type cachedFile struct {
+	info os.FileInfo
+	file *databrickscfg.File
+
+	// include is the value of DATABRICKS_CONFIG_INCLUDE when the file was
+	// parsed, and included holds the state of the included files.
+	include  string
+	included []os.FileInfo
+}
+
+// unchanged reports whether the file was parsed from its current state.
+func (c cachedFile) unchanged(ctx context.Context, info os.FileInfo) bool {
+	if !sameFileInfo(c.info, info) || c.include != env.Get(ctx, "DATABRICKS_CONFIG_INCLUDE") {
+		return false
+	}
+	for i, path := range c.file.Included() {
+		info, err := os.Stat(path)
+		if err != nil || !sameFileInfo(c.included[i], info) {
+			return false
+		}
+	}
+	return true
+}
+
+func sameFileInfo(a, b os.FileInfo) bool {
+	return os.SameFile(a, b) && a.ModTime().Equal(b.ModTime()) && a.Size() == b.Size()
+}
+
+func (c *CachedProfiler) LoadProfiles(ctx context.Context, fn ProfileMatchFunction) (Profiles, error) {
+	path, err := c.resolvePath(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load Databricks config file: %w", err)
+	}
+
+	// Read the file directly if it cannot be checked for changes. This also
+	// reports a missing file the same way as [FileProfilerImpl].
+	info, err := os.Stat(path)
+	if err != nil {
+		return c.FileProfilerImpl.LoadProfiles(ctx, fn)
+	}
+
+	file, err := c.load(ctx, path, info)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load Databricks config file: %w", err)
+	}
+	return matchProfiles(ctx, file, fn)
+}
+
+// load returns the parsed file at path, parsing it only if it changed since
+// it was last parsed.
+func (c *CachedProfiler) load(ctx context.Context, path string, info os.FileInfo) (*databrickscfg.File, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached, ok := c.files[path]
+	if ok && cached.unchanged(ctx, info) {
+		return cached.file, nil
+	}
+
+	file, err := loadFile(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	cached = cachedFile{info: info, file: file, include: env.Get(ctx, "DATABRICKS_CONFIG_INCLUDE")}
+	for _, included := range file.Included() {
+		// A file that can't be checked for changes is parsed again next time.
+		info, _ := os.Stat(included)
+		cached.included = append(cached.included, info)
+	}
+	if c.files == nil {
+		c.files = map[string]cachedFile{}
+	}
+	c.files[path] = cached
+	return file, nil
+}
@@ -0,0 +1,140 @@
+package profile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/databricks/cli/libs/databrickscfg"
+	"github.com/databricks/cli/libs/env"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countLoads counts the number of times a config file is parsed.
+func countLoads(t *testing.T) *atomic.Int32 {
+	var n atomic.Int32
+	orig := loadFile
+	t.Cleanup(func() { loadFile = orig })
+	loadFile = func(ctx context.Context, path string) (*databrickscfg.File, error) {
+		n.Add(1)
+		return orig(ctx, path)
+	}
+	return &n
+}
+
+func TestCachedProfilerParsesOnce(t *testing.T) {
+	loads := countLoads(t)
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", "./testdata/databrickscfg")
+	profiler := &CachedProfiler{}
+
+	for range 10 {
+		profiles, err := profiler.LoadProfiles(ctx, MatchAccountProfiles)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"acc"}, profiles.Names())
+	}
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func TestCachedProfilerReloadsChangedFile(t *testing.T) {
+	loads := countLoads(t)
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[a]\nhost = https://a\n"), 0o600))
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", path)
+	profiler := &CachedProfiler{}
+
+	profiles, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, profiles.Names())
+
+	require.NoError(t, os.WriteFile(path, []byte("[a]\nhost = https://a\n\n[b]\nhost = https://b\n"), 0o600))
+	profiles, err = profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, profiles.Names())
+
+	// Same size, different modification time.
+	require.NoError(t, os.WriteFile(path, []byte("[a]\nhost = https://a\n\n[c]\nhost = https://c\n"), 0o600))
+	mtime := time.Now().Add(time.Hour)
+	require.NoError(t, os.Chtimes(path, mtime, mtime))
+	profiles, err = profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c"}, profiles.Names())
+
+	assert.Equal(t, int32(3), loads.Load())
+}
+
+func TestCachedProfilerReloadsChangedInclude(t *testing.T) {
+	loads := countLoads(t)
+	dir := t.TempDir()
+	included := filepath.Join(dir, "included.cfg")
+	require.NoError(t, os.WriteFile(included, []byte("[a]\nhost = https://a\n"), 0o600))
+	path := filepath.Join(dir, ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("include = included.cfg\n"), 0o600))
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", path)
+	profiler := &CachedProfiler{}
+
+	profiles, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, profiles.Names())
+
+	require.NoError(t, os.WriteFile(included, []byte("[a]\nhost = https://a\n\n[b]\nhost = https://b\n"), 0o600))
+	profiles, err = profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, profiles.Names())
+
+	// Changing the included files through the environment reloads as well.
+	other := filepath.Join(dir, "other.cfg")
+	require.NoError(t, os.WriteFile(other, []byte("[c]\nhost = https://c\n"), 0o600))
+	ctx = env.Set(ctx, "DATABRICKS_CONFIG_INCLUDE", other)
+	profiles, err = profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, profiles.Names())
+
+	assert.Equal(t, int32(3), loads.Load())
+}
+
+func TestCachedProfilerMissingFile(t *testing.T) {
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", filepath.Join(t.TempDir(), "missing"))
+	profiler := &CachedProfiler{}
+	_, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+	assert.ErrorIs(t, err, ErrNoConfiguration)
+}
+
+func TestCachedProfilerConcurrent(t *testing.T) {
+	loads := countLoads(t)
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", "./testdata/databrickscfg")
+	profiler := &CachedProfiler{}
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			profiles, err := profiler.LoadProfiles(ctx, MatchAccountProfiles)
+			assert.NoError(t, err)
+			assert.Equal(t, []string{"acc"}, profiles.Names())
+		})
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), loads.Load())
+}
+
+func BenchmarkCachedProfiler(b *testing.B) {
+	ctx := env.Set(b.Context(), "DATABRICKS_CONFIG_FILE", "./testdata/databrickscfg")
+	profiler := &CachedProfiler{}
+	for b.Loop() {
+		_, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+		require.NoError(b, err)
+	}
+}
+
+func BenchmarkFileProfiler(b *testing.B) {
+	ctx := env.Set(b.Context(), "DATABRICKS_CONFIG_FILE", "./testdata/databrickscfg")
+	profiler := FileProfilerImpl{}
+	for b.Loop() {
+		_, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+		require.NoError(b, err)
+	}
+}
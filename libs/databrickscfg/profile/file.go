@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/databricks/cli/libs/databrickscfg"
 	"github.com/databricks/cli/libs/env"
 	"github.com/databricks/databricks-sdk-go/config"
 	"github.com/spf13/cobra"
@@ -20,6 +21,10 @@ func (f FileProfilerImpl) getPath(ctx context.Context, replaceHomeDirWithTilde b
 	if configFile == "" {
 		configFile = "~/.databrickscfg"
 	}
+	configFile, err := databrickscfg.ExpandConfigFilePath(ctx, configFile)
+	if err != nil {
+		return "", err
+	}
 	if !replaceHomeDirWithTilde {
 		return configFile, nil
 	}
@@ -42,19 +47,35 @@ func (f FileProfilerImpl) GetPath(ctx context.Context) (string, error) {
 
 var ErrNoConfiguration = errors.New("no configuration file found")
 
-func (f FileProfilerImpl) Get(ctx context.Context) (*config.File, error) {
+// loadFile parses a config file and the files it includes. It is replaced in
+// tests.
+var loadFile = databrickscfg.LoadFile
+
+// resolvePath returns the path to the .databrickscfg file with ~ and
+// environment variables expanded.
+func (f FileProfilerImpl) resolvePath(ctx context.Context) (string, error) {
 	path, err := f.getPath(ctx, false)
 	if err != nil {
-		return nil, fmt.Errorf("cannot determine Databricks config file path: %w", err)
+		return "", fmt.Errorf("cannot determine Databricks config file path: %w", err)
 	}
-	if strings.HasPrefix(path, "~") {
-		homedir, err := env.UserHomeDir(ctx)
-		if err != nil {
-			return nil, err
-		}
-		path = filepath.Join(homedir, path[1:])
+	return path, nil
+}
+
+// Get returns the config file with the profiles of the files it includes.
+func (f FileProfilerImpl) Get(ctx context.Context) (*config.File, error) {
+	file, err := f.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return file.File, nil
+}
+
+func (f FileProfilerImpl) load(ctx context.Context) (*databrickscfg.File, error) {
+	path, err := f.resolvePath(ctx)
+	if err != nil {
+		return nil, err
 	}
-	configFile, err := config.LoadFile(path)
+	configFile, err := loadFile(ctx, path)
 	if errors.Is(err, fs.ErrNotExist) {
 		// downstreams depend on ErrNoConfiguration. TODO: expose this error through SDK
 		return nil, fmt.Errorf("%w at %s; please create one by running 'databricks auth login'", ErrNoConfiguration, path)
@@ -65,14 +86,21 @@ func (f FileProfilerImpl) Get(ctx context.Context) (*config.File, error) {
 }
 
 func (f FileProfilerImpl) LoadProfiles(ctx context.Context, fn ProfileMatchFunction) (profiles Profiles, err error) {
-	file, err := f.Get(ctx)
+	file, err := f.load(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("cannot load Databricks config file: %w", err)
 	}
+	return matchProfiles(ctx, file, fn)
+}
 
+// matchProfiles returns the profiles of file that match fn.
+func matchProfiles(ctx context.Context, file *databrickscfg.File, fn ProfileMatchFunction) (profiles Profiles, err error) {
 	// Iterate over sections and collect matching profiles.
 	for _, v := range file.Sections() {
-		all := v.KeysHash()
+		all, err := databrickscfg.ExpandEnv(ctx, v)
+		if err != nil {
+			return nil, err
+		}
 		host, ok := all["host"]
 		if !ok {
 			// invalid profile
@@ -85,11 +113,15 @@ func (f FileProfilerImpl) LoadProfiles(ctx context.Context, fn ProfileMatchFunct
 			WorkspaceID:          all["workspace_id"],
 			IsUnifiedHost:        all["experimental_is_unified_host"] == "true",
 			ClusterID:            all["cluster_id"],
+			WarehouseID:          all["warehouse_id"],
 			ServerlessComputeID:  all["serverless_compute_id"],
 			HasClientCredentials: all["client_id"] != "" && all["client_secret"] != "",
 			Scopes:               all["scopes"],
 			AuthType:             all["auth_type"],
 		}
+		if source := file.Source(v.Name()); source != file.Path() {
+			profile.Path = source
+		}
 		if fn(profile) {
 			profiles = append(profiles, profile)
 		}
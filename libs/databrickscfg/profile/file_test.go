@@ -1,6 +1,7 @@
 package profile
 
 import (
+	"os"
 	"path/filepath"
 	"testing"
 
@@ -56,6 +57,30 @@ func TestLoadProfilesReturnsHomedirAsTildeDefaultFile(t *testing.T) {
 	require.Equal(t, filepath.Clean("~/.databrickscfg"), file)
 }
 
+func TestLoadProfilesExpandsConfigFilePath(t *testing.T) {
+	ctx := t.Context()
+	ctx = env.WithUserHomeDir(ctx, "testdata")
+	ctx = env.Set(ctx, "CONFIG_DIR", "testdata")
+	ctx = env.Set(ctx, "DATABRICKS_CONFIG_FILE", "$CONFIG_DIR/databrickscfg")
+	profiler := FileProfilerImpl{}
+	file, err := profiler.GetPath(ctx)
+	require.NoError(t, err)
+	require.Equal(t, filepath.Clean("~/databrickscfg"), file)
+
+	profiles, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.NotEmpty(t, profiles)
+}
+
+func TestLoadProfilesRejectsTildeUser(t *testing.T) {
+	ctx := t.Context()
+	ctx = env.WithUserHomeDir(ctx, "testdata")
+	ctx = env.Set(ctx, "DATABRICKS_CONFIG_FILE", "~other/databrickscfg")
+	profiler := FileProfilerImpl{}
+	_, err := profiler.GetPath(ctx)
+	assert.ErrorContains(t, err, "only ~ is supported to refer to the home directory")
+}
+
 func TestLoadProfilesNoConfiguration(t *testing.T) {
 	ctx := t.Context()
 	ctx = env.WithUserHomeDir(ctx, "testdata")
@@ -81,3 +106,67 @@ func TestLoadProfilesMatchAccount(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, []string{"acc"}, profiles.Names())
 }
+
+func TestLoadProfilesComputeIDs(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`[compute]
+host = https://compute
+cluster_id = cluster
+warehouse_id = warehouse
+serverless_compute_id = auto
+`), 0o600))
+
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", path)
+	profiler := FileProfilerImpl{}
+	profiles, err := profiler.LoadProfiles(ctx, WithName("compute"))
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "cluster", profiles[0].ClusterID)
+	assert.Equal(t, "warehouse", profiles[0].WarehouseID)
+	assert.Equal(t, "auto", profiles[0].ServerlessComputeID)
+}
+
+func TestLoadProfilesExpandsEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte(`[dev]
+host = ${env:MY_HOST}
+account_id = literal$value
+`), 0o600))
+
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", path)
+	ctx = env.Set(ctx, "MY_HOST", "https://my.cloud.databricks.com")
+	profiler := FileProfilerImpl{}
+	profiles, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	require.Len(t, profiles, 1)
+	assert.Equal(t, "https://my.cloud.databricks.com", profiles[0].Host)
+	assert.Equal(t, "literal$value", profiles[0].AccountID)
+}
+
+func TestLoadProfilesUnsetEnv(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[dev]\nhost = ${env:DATABRICKS_CLI_TEST_UNSET_HOST}\n"), 0o600))
+
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", path)
+	profiler := FileProfilerImpl{}
+	_, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+	assert.EqualError(t, err, `profile "dev": host references environment variable DATABRICKS_CLI_TEST_UNSET_HOST, which is not set`)
+}
+
+func TestLoadProfilesWithIncludes(t *testing.T) {
+	dir := t.TempDir()
+	managed := filepath.Join(dir, "managed.cfg")
+	require.NoError(t, os.WriteFile(managed, []byte("[managed]\nhost = https://managed\n"), 0o600))
+	path := filepath.Join(dir, ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[DEFAULT]\ninclude = managed.cfg\n\n[personal]\nhost = https://personal\n"), 0o600))
+
+	ctx := env.Set(t.Context(), "DATABRICKS_CONFIG_FILE", path)
+	profiler := FileProfilerImpl{}
+	profiles, err := profiler.LoadProfiles(ctx, MatchAllProfiles)
+	require.NoError(t, err)
+	assert.Equal(t, Profiles{
+		{Name: "personal", Host: "https://personal"},
+		{Name: "managed", Host: "https://managed", Path: managed},
+	}, profiles)
+	assert.Equal(t, []string{"personal", "managed (from " + managed + ")"}, profiles.NamesWithPaths())
+}
@@ -1,7 +1,13 @@
 package profile
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"strings"
+	"time"
+
+	"github.com/databricks/cli/libs/log"
 
 	"github.com/databricks/databricks-sdk-go/config"
 )
@@ -16,10 +22,15 @@ type Profile struct {
 	WorkspaceID          string
 	IsUnifiedHost        bool
 	ClusterID            string
+	WarehouseID          string
 	ServerlessComputeID  string
 	HasClientCredentials bool
 	Scopes               string
 	AuthType             string
+
+	// Path is the path of the included config file that defines the profile.
+	// It is empty for profiles of the primary config file.
+	Path string
 }
 
 func (p Profile) Cloud() string {
@@ -40,12 +51,9 @@ type Profiles []Profile
 
 // SearchCaseInsensitive implements the promptui.Searcher interface.
 // This allows the user to immediately starting typing to narrow down the list.
+// It matches the name, the host and the account ID of the profile.
 func (p Profiles) SearchCaseInsensitive(input string, index int) bool {
-	input = strings.ToLower(input)
-	name := strings.ToLower(p[index].Name)
-	host := strings.ToLower(p[index].Host)
-	accountID := strings.ToLower(p[index].AccountID)
-	return strings.Contains(name, input) || strings.Contains(host, input) || strings.Contains(accountID, input)
+	return p[index].matchesSearch(input, false)
 }
 
 func (p Profiles) Names() []string {
@@ -55,3 +63,51 @@ func (p Profiles) Names() []string {
 	}
 	return names
 }
+
+// NamesWithPaths returns the names of the profiles. Profiles of included
+// config files are followed by the path of the file that defines them, so that
+// messages about multiple matching profiles tell where to find each of them.
+func (p Profiles) NamesWithPaths() []string {
+	names := make([]string, len(p))
+	for i, v := range p {
+		names[i] = v.Name
+		if v.Path != "" {
+			names[i] += " (from " + v.Path + ")"
+		}
+	}
+	return names
+}
+
+// FindByName returns the profile with the given name. If no profile has
+// exactly that name, the only profile whose name differs in case is used, so
+// that --profile Dev selects a profile named dev. It returns an error if
+// multiple profiles differ in case only, and nil if no profile matches.
+func (p Profiles) FindByName(ctx context.Context, name string) (*Profile, error) {
+	var folded Profiles
+	for i := range p {
+		if p[i].Name == name {
+			return &p[i], nil
+		}
+		if strings.EqualFold(p[i].Name, name) {
+			folded = append(folded, p[i])
+		}
+	}
+	switch len(folded) {
+	case 0:
+		return nil, nil
+	case 1:
+		log.Debugf(ctx, "Using profile %q for %q, the names differ in case only", folded[0].Name, name)
+		return &folded[0], nil
+	default:
+		return nil, fmt.Errorf("profile %q matches multiple profiles that differ in case only: %s. Use the exact profile name", name, strings.Join(folded.Names(), ", "))
+	}
+}
+
+// SortByLastUsed orders the profiles by when they were last used, most
+// recently used first. Profiles that were never used follow in their original
+// order.
+func (p Profiles) SortByLastUsed(lastUsed map[string]time.Time) {
+	slices.SortStableFunc(p, func(a, b Profile) int {
+		return lastUsed[b.Name].Compare(lastUsed[a.Name])
+	})
+}
@@ -2,9 +2,12 @@ package profile
 
 import (
 	"context"
+	"fmt"
+	"path"
+	"strings"
 
 	"github.com/databricks/cli/libs/auth"
-	"github.com/databricks/databricks-sdk-go/config"
+	"github.com/databricks/cli/libs/databrickscfg"
 )
 
 type ProfileMatchFunction func(Profile) bool
@@ -40,9 +43,12 @@ func MatchProfileNames(names ...string) ProfileMatchFunction {
 	}
 }
 
+// WithName returns a ProfileMatchFunction that matches profiles by name,
+// ignoring case. Use [Profiles.FindByName] to pick the profile to use among
+// the matches.
 func WithName(name string) ProfileMatchFunction {
 	return func(p Profile) bool {
-		return p.Name == name
+		return strings.EqualFold(p.Name, name)
 	}
 }
 
@@ -75,9 +81,50 @@ func WithHostAccountIDAndWorkspaceID(host, accountID, workspaceID string) Profil
 	}
 }
 
-// canonicalizeHost normalizes a host using the SDK's canonical host logic.
+// WithHostPattern returns a ProfileMatchFunction that matches profiles whose
+// canonical host matches the glob pattern, for example *.azuredatabricks.net.
+// The pattern uses the syntax of [path.Match] and is matched against the host
+// without scheme, ignoring case. A scheme and trailing slashes in the pattern
+// are ignored as well. It returns an error if the pattern is malformed.
+func WithHostPattern(pattern string) (ProfileMatchFunction, error) {
+	pattern = normalizeSearchHost(pattern)
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid host pattern %q: %w", pattern, err)
+	}
+	return func(p Profile) bool {
+		if p.Host == "" {
+			return false
+		}
+		ok, _ := path.Match(pattern, normalizeSearchHost(canonicalizeHost(p.Host)))
+		return ok
+	}, nil
+}
+
+// WithAccountID returns a ProfileMatchFunction that matches profiles by
+// account ID.
+func WithAccountID(accountID string) ProfileMatchFunction {
+	return func(p Profile) bool {
+		return p.AccountID == accountID
+	}
+}
+
+// MatchAll returns a ProfileMatchFunction that matches profiles that are
+// matched by all of fns.
+func MatchAll(fns ...ProfileMatchFunction) ProfileMatchFunction {
+	return func(p Profile) bool {
+		for _, fn := range fns {
+			if !fn(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// canonicalizeHost normalizes a host the same way configure does when it
+// saves a profile, so that profiles match regardless of how the host was typed.
 func canonicalizeHost(host string) string {
-	return (&config.Config{Host: host}).CanonicalHostName()
+	return databrickscfg.NormalizeHost(host)
 }
 
 type Profiler interface {
@@ -85,4 +132,4 @@ type Profiler interface {
 	GetPath(context.Context) (string, error)
 }
 
-var DefaultProfiler = FileProfilerImpl{}
+var DefaultProfiler = &CachedProfiler{}
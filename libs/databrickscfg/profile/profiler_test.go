@@ -2,8 +2,10 @@ package profile
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestWithHost(t *testing.T) {
@@ -289,3 +291,179 @@ func TestMatchAccountProfiles(t *testing.T) {
 		})
 	}
 }
+
+func TestProfilesFindByName(t *testing.T) {
+	profiles := Profiles{
+		{Name: "dev"},
+		{Name: "Prod"},
+		{Name: "staging"},
+		{Name: "Staging"},
+		{Name: "STAGING"},
+		{Name: "test"},
+		{Name: "Test"},
+	}
+	cases := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr string
+	}{
+		{
+			name:  "exact match",
+			input: "dev",
+			want:  "dev",
+		},
+		{
+			name:  "exact match preferred over case-folded matches",
+			input: "Staging",
+			want:  "Staging",
+		},
+		{
+			name:  "unique case-folded match",
+			input: "Dev",
+			want:  "dev",
+		},
+		{
+			name:  "unique case-folded match of mixed-case profile",
+			input: "prod",
+			want:  "Prod",
+		},
+		{
+			name:    "ambiguous case-folded matches",
+			input:   "TEST",
+			wantErr: `profile "TEST" matches multiple profiles that differ in case only: test, Test. Use the exact profile name`,
+		},
+		{
+			name:  "no match",
+			input: "other",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p, err := profiles.FindByName(t.Context(), c.input)
+			if c.wantErr != "" {
+				assert.EqualError(t, err, c.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			if c.want == "" {
+				assert.Nil(t, p)
+				return
+			}
+			if assert.NotNil(t, p) {
+				assert.Equal(t, c.want, p.Name)
+			}
+		})
+	}
+}
+
+func TestWithName(t *testing.T) {
+	fn := WithName("Dev")
+	assert.True(t, fn(Profile{Name: "Dev"}))
+	assert.True(t, fn(Profile{Name: "dev"}))
+	assert.False(t, fn(Profile{Name: "dev2"}))
+}
+
+func TestProfilesSortByLastUsed(t *testing.T) {
+	now := time.Now()
+	profiles := Profiles{
+		{Name: "never-a"},
+		{Name: "old"},
+		{Name: "never-b"},
+		{Name: "recent"},
+	}
+	profiles.SortByLastUsed(map[string]time.Time{
+		"old":    now.Add(-time.Hour),
+		"recent": now,
+	})
+	assert.Equal(t, []string{"recent", "old", "never-a", "never-b"}, profiles.Names())
+}
+
+func TestWithHostPattern(t *testing.T) {
+	cases := []struct {
+		name        string
+		pattern     string
+		profileHost string
+		want        bool
+	}{
+		{
+			name:        "suffix wildcard",
+			pattern:     "*.azuredatabricks.net",
+			profileHost: "https://adb-123.4.azuredatabricks.net",
+			want:        true,
+		},
+		{
+			name:        "scheme-less pattern matches profile with scheme",
+			pattern:     "adb-123.4.azuredatabricks.net",
+			profileHost: "https://adb-123.4.azuredatabricks.net",
+			want:        true,
+		},
+		{
+			name:        "pattern with scheme",
+			pattern:     "https://*.cloud.databricks.com",
+			profileHost: "https://dev.cloud.databricks.com",
+			want:        true,
+		},
+		{
+			name:        "trailing slash on pattern",
+			pattern:     "*.cloud.databricks.com/",
+			profileHost: "https://dev.cloud.databricks.com",
+			want:        true,
+		},
+		{
+			name:        "trailing slash on profile host",
+			pattern:     "*.cloud.databricks.com",
+			profileHost: "https://dev.cloud.databricks.com/",
+			want:        true,
+		},
+		{
+			name:        "case insensitive",
+			pattern:     "DEV.*",
+			profileHost: "https://dev.cloud.databricks.com",
+			want:        true,
+		},
+		{
+			name:        "wildcard in the middle",
+			pattern:     "dev-*.cloud.databricks.com",
+			profileHost: "https://dev-eu.cloud.databricks.com",
+			want:        true,
+		},
+		{
+			name:        "pattern must match the whole host",
+			pattern:     "dev",
+			profileHost: "https://dev.cloud.databricks.com",
+			want:        false,
+		},
+		{
+			name:        "different domain",
+			pattern:     "*.azuredatabricks.net",
+			profileHost: "https://dev.cloud.databricks.com",
+			want:        false,
+		},
+		{
+			name:        "empty host on profile skipped",
+			pattern:     "*",
+			profileHost: "",
+			want:        false,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			fn, err := WithHostPattern(c.pattern)
+			require.NoError(t, err)
+			assert.Equal(t, c.want, fn(Profile{Host: c.profileHost}))
+		})
+	}
+}
+
+func TestWithHostPatternInvalid(t *testing.T) {
+	_, err := WithHostPattern("[dev")
+	assert.ErrorContains(t, err, `invalid host pattern "[dev"`)
+}
+
+func TestMatchAll(t *testing.T) {
+	p := Profile{Name: "dev", Host: "https://accounts.cloud.databricks.com", AccountID: "abc"}
+	assert.True(t, MatchAll()(p))
+	assert.True(t, MatchAll(WithAccountID("abc"), MatchAccountProfiles)(p))
+	assert.False(t, MatchAll(WithAccountID("abc"), MatchWorkspaceProfiles)(p))
+}
@@ -0,0 +1,45 @@
+package profile
+
+import "strings"
+
+// matchesSearch reports whether input matches the profile. The input matches
+// if the name, the host or the account ID of the profile contains it, ignoring
+// case. Hosts are compared without scheme and trailing slashes, so that a
+// workspace URL copied from the browser matches as well. If fuzzy is set,
+// the input also matches if its characters appear in order in the name or the
+// host.
+func (p Profile) matchesSearch(input string, fuzzy bool) bool {
+	input = strings.ToLower(input)
+	name := strings.ToLower(p.Name)
+	host := normalizeSearchHost(p.Host)
+
+	if strings.Contains(name, input) ||
+		(p.Host != "" && strings.Contains(host, normalizeSearchHost(input))) ||
+		strings.Contains(strings.ToLower(p.AccountID), input) {
+		return true
+	}
+	return fuzzy && (isSubsequence(input, name) || isSubsequence(normalizeSearchHost(input), host))
+}
+
+// normalizeSearchHost lowercases host and strips its scheme and trailing
+// slashes.
+func normalizeSearchHost(host string) string {
+	host = strings.ToLower(host)
+	if _, rest, ok := strings.Cut(host, "://"); ok {
+		host = rest
+	}
+	return strings.TrimRight(host, "/")
+}
+
+// isSubsequence reports whether the characters of s appear in t in the same
+// order, not necessarily next to each other.
+func isSubsequence(s, t string) bool {
+	for _, r := range s {
+		i := strings.IndexRune(t, r)
+		if i < 0 {
+			return false
+		}
+		t = t[i+len(string(r)):]
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileMatchesSearch(t *testing.T) {
+	profiles := Profiles{
+		{Name: "dev", Host: "https://dev-workspace.cloud.databricks.com/"},
+		{Name: "prod", Host: "https://adb-1234567890.12.azuredatabricks.net"},
+		{Name: "account", Host: "https://accounts.cloud.databricks.com", AccountID: "ABC-12345-def"},
+		{Name: "workspace", Host: "https://other.cloud.databricks.com"},
+	}
+
+	cases := []struct {
+		name  string
+		input string
+		fuzzy bool
+		want  []string
+	}{
+		{
+			name:  "name fragment",
+			input: "PRO",
+			want:  []string{"prod"},
+		},
+		{
+			name:  "host fragment",
+			input: "adb-1234",
+			want:  []string{"prod"},
+		},
+		{
+			name:  "host with scheme and trailing slash",
+			input: "https://dev-workspace.cloud.databricks.com/",
+			want:  []string{"dev"},
+		},
+		{
+			name:  "host without scheme",
+			input: "dev-workspace.cloud.databricks.com",
+			want:  []string{"dev"},
+		},
+		{
+			name:  "host with trailing slash",
+			input: "adb-1234567890.12.azuredatabricks.net/",
+			want:  []string{"prod"},
+		},
+		{
+			name:  "account ID",
+			input: "12345-DEF",
+			want:  []string{"account"},
+		},
+		{
+			name:  "name and host fragment",
+			input: "workspace",
+			want:  []string{"dev", "workspace"},
+		},
+		{
+			name:  "no fuzzy matching by default",
+			input: "dvws",
+			want:  []string{},
+		},
+		{
+			name:  "fuzzy host match",
+			input: "dvws",
+			fuzzy: true,
+			want:  []string{"dev"},
+		},
+		{
+			name:  "fuzzy name match",
+			input: "od",
+			fuzzy: true,
+			want:  []string{"dev", "prod", "account", "workspace"},
+		},
+		{
+			name:  "no match",
+			input: "staging",
+			fuzzy: true,
+			want:  []string{},
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := []string{}
+			for _, p := range profiles {
+				if p.matchesSearch(tc.input, tc.fuzzy) {
+					got = append(got, p.Name)
+				}
+			}
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
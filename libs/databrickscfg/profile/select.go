@@ -4,8 +4,9 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"strings"
+	"slices"
 
+	"github.com/databricks/cli/libs/auth"
 	"github.com/databricks/cli/libs/cmdio"
 	"github.com/manifoldco/promptui"
 )
@@ -26,6 +27,10 @@ type SelectConfig struct {
 
 	StartInSearchMode bool
 
+	// FuzzySearch also matches profiles if the characters of the search input
+	// appear in order in their name or host.
+	FuzzySearch bool
+
 	// Go template strings for rendering items. Templates have access to all
 	// [Profile] fields, a Cloud method, and a PaddedName field that is the
 	// profile name right-padded to align with the longest name in the list.
@@ -52,15 +57,19 @@ func SelectProfile(ctx context.Context, cfg SelectConfig) (string, error) {
 		return "", errors.New("no profiles configured. Run 'databricks auth login' to create a profile")
 	}
 
+	// Recently used profiles float to the top.
+	profiles := slices.Clone(cfg.Profiles)
+	profiles.SortByLastUsed(auth.ProfilesLastUsed(ctx))
+
 	maxNameLen := 0
-	for _, p := range cfg.Profiles {
+	for _, p := range profiles {
 		if len(p.Name) > maxNameLen {
 			maxNameLen = len(p.Name)
 		}
 	}
 
-	items := make([]selectItem, len(cfg.Profiles))
-	for i, p := range cfg.Profiles {
+	items := make([]selectItem, len(profiles))
+	for i, p := range profiles {
 		items[i] = selectItem{
 			Profile:    p,
 			PaddedName: fmt.Sprintf("%-*s", maxNameLen, p.Name),
@@ -81,11 +90,7 @@ func SelectProfile(ctx context.Context, cfg SelectConfig) (string, error) {
 	// to the Items list passed to promptui (rather than the original Profiles
 	// slice which could diverge if items were ever filtered or reordered).
 	searcher := func(input string, index int) bool {
-		input = strings.ToLower(input)
-		p := items[index].Profile
-		return strings.Contains(strings.ToLower(p.Name), input) ||
-			strings.Contains(strings.ToLower(p.Host), input) ||
-			strings.Contains(strings.ToLower(p.AccountID), input)
+		return items[index].matchesSearch(input, cfg.FuzzySearch)
 	}
 
 	i, _, err := cmdio.RunSelect(ctx, &promptui.Select{
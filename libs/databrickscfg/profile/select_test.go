@@ -0,0 +1,33 @@
+package profile
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfilesSearchCaseInsensitiveAccountProfile(t *testing.T) {
+	p := Profile{
+		Name:      "acc-prod",
+		Host:      "https://accounts.cloud.databricks.com",
+		AccountID: "ABC-12345-def",
+		AuthType:  "databricks-cli",
+	}
+
+	cases := []struct {
+		input string
+		want  bool
+	}{
+		{input: "prod", want: true},
+		{input: "accounts.cloud", want: true},
+		{input: "12345", want: true},
+		{input: "abc-123", want: true},
+		{input: "45-DEF", want: true},
+		{input: "67890", want: false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.input, func(t *testing.T) {
+			assert.Equal(t, tc.want, Profiles{p}.SearchCaseInsensitive(tc.input, 0))
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Package fileutil provides helpers to write files in the home directory that
+// other processes may read or modify concurrently.
+package fileutil
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// WriteFile writes the output of write to path. The output is written to a
+// temporary file in the same directory, which is synced to disk and then
+// renamed to path, so that readers never observe a partially written file and
+// a process that is killed while writing leaves the file at path untouched.
+// The mode and owner of an existing file at path are preserved; new files are
+// created with perm. If path is a symlink, its target is written. The
+// directory of path must exist.
+func WriteFile(path string, perm fs.FileMode, write func(w io.Writer) error) error {
+	return ReplaceFile(path, perm, write, nil)
+}
+
+// ReplaceFile is like [WriteFile], but calls check right before path is
+// replaced. If check returns an error, path is left untouched and the error is
+// returned. Callers use it to detect that another process modified the file
+// while it was written.
+func ReplaceFile(path string, perm fs.FileMode, write func(w io.Writer) error, check func() error) error {
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+	}
+	info, err := os.Stat(path)
+	if err == nil {
+		perm = info.Mode().Perm()
+	} else if errors.Is(err, fs.ErrNotExist) {
+		info = nil
+	} else {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Chmod(perm); err != nil {
+		tmp.Close()
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if info != nil {
+		if err := preserveOwner(tmp.Name(), info); err != nil {
+			return err
+		}
+	}
+
+	if check != nil {
+		if err := check(); err != nil {
+			return err
+		}
+	}
+	return Rename(tmp.Name(), path)
+}
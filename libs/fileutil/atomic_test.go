@@ -0,0 +1,122 @@
+package fileutil
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".databrickscfg")
+
+	err := WriteFile(path, 0o600, func(w io.Writer) error {
+		_, err := w.Write([]byte("[abc]\nhost = https://foo\n"))
+		return err
+	})
+	require.NoError(t, err)
+
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[abc]\nhost = https://foo\n", string(contents))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFile_PartialWrite(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[abc]\nhost = https://foo\n"), 0o600))
+
+	err := WriteFile(path, 0o600, func(w io.Writer) error {
+		_, err := w.Write([]byte("[abc\nho"))
+		require.NoError(t, err)
+		return errors.New("disk full")
+	})
+	assert.ErrorContains(t, err, "write temp file: disk full")
+
+	// The original file is untouched and the temporary file is removed.
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[abc]\nhost = https://foo\n", string(contents))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestWriteFile_PreservesMode(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes are not supported on Windows")
+	}
+
+	path := filepath.Join(t.TempDir(), ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[abc]\n"), 0o640))
+	require.NoError(t, os.Chmod(path, 0o640))
+
+	err := WriteFile(path, 0o600, func(w io.Writer) error {
+		_, err := w.Write([]byte("[def]\n"))
+		return err
+	})
+	require.NoError(t, err)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o640), info.Mode().Perm())
+}
+
+func TestWriteFile_Symlink(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("creating symlinks requires privileges on Windows")
+	}
+
+	dir := t.TempDir()
+	target := filepath.Join(dir, "dotfiles.cfg")
+	link := filepath.Join(dir, ".databrickscfg")
+	require.NoError(t, os.WriteFile(target, []byte("[abc]\n"), 0o600))
+	require.NoError(t, os.Symlink(target, link))
+
+	err := WriteFile(link, 0o600, func(w io.Writer) error {
+		_, err := w.Write([]byte("[def]\n"))
+		return err
+	})
+	require.NoError(t, err)
+
+	info, err := os.Lstat(link)
+	require.NoError(t, err)
+	assert.Equal(t, os.ModeSymlink, info.Mode().Type())
+	contents, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "[def]\n", string(contents))
+}
+
+func TestReplaceFile_CheckFails(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, ".databrickscfg")
+	require.NoError(t, os.WriteFile(path, []byte("[abc]\n"), 0o600))
+
+	err := ReplaceFile(path, 0o600, func(w io.Writer) error {
+		_, err := w.Write([]byte("[def]\n"))
+		return err
+	}, func() error {
+		return errors.New("modified")
+	})
+	assert.EqualError(t, err, "modified")
+
+	// The file is untouched and the temporary file is removed.
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "[abc]\n", string(contents))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+
+package fileutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"syscall"
+)
+
+// Rename atomically replaces newpath with oldpath.
+func Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// preserveOwner gives the file at path the owner and group of the file
+// described by info, for example when root updates a file in the home
+// directory of a user. Users other than root can't change the owner, so this
+// is best effort.
+func preserveOwner(path string, info fs.FileInfo) error {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	err := os.Lchown(path, int(stat.Uid), int(stat.Gid))
+	if errors.Is(err, fs.ErrPermission) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,44 @@
+//go:build windows
+
+package fileutil
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+// renameAttempts is the number of times Rename tries to replace a file that
+// is in use.
+const renameAttempts = 5
+
+// Rename replaces newpath with oldpath. os.Rename replaces existing files on
+// Windows, but fails while another process, such as an editor or a virus
+// scanner, has newpath open. These failures are transient, so the rename is
+// retried.
+func Rename(oldpath, newpath string) error {
+	var err error
+	for i := range renameAttempts {
+		err = os.Rename(oldpath, newpath)
+		if err == nil || !inUse(err) {
+			return err
+		}
+		time.Sleep(time.Duration(i+1) * 50 * time.Millisecond)
+	}
+	return err
+}
+
+func inUse(err error) bool {
+	return errors.Is(err, windows.ERROR_ACCESS_DENIED) ||
+		errors.Is(err, windows.ERROR_SHARING_VIOLATION) ||
+		errors.Is(err, windows.ERROR_LOCK_VIOLATION)
+}
+
+// preserveOwner is a no-op on Windows, where a new file inherits the
+// permissions of its directory.
+func preserveOwner(path string, info fs.FileInfo) error {
+	return nil
+}